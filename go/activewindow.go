@@ -0,0 +1,191 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// disableActiveWindow desactiva get_active_window, ya que los títulos de las
+// ventanas pueden contener datos sensibles (configurable con
+// --disable-active-window)
+var disableActiveWindow bool
+
+// errActiveWindowDisabled es el error de get_active_window cuando está desactivada
+var errActiveWindowDisabled = errors.New("get_active_window está desactivada en este servidor (--disable-active-window) porque los títulos de las ventanas pueden contener datos sensibles")
+
+type ActiveWindowOutput struct {
+	App   string `json:"app" jsonschema:"Nombre de la aplicación o del proceso de la ventana activa"`
+	PID   int    `json:"pid" jsonschema:"Identificador del proceso (0 si no se puede saber)"`
+	Title string `json:"title" jsonschema:"Título de la ventana activa"`
+}
+
+// getActiveWindow devuelve la aplicación y la ventana que tienen el foco
+func getActiveWindow() (ActiveWindowOutput, error) {
+	if disableActiveWindow {
+		return ActiveWindowOutput{}, errActiveWindowDisabled
+	}
+	switch currentPlatform() {
+	case platformWindows, platformWSL:
+		output, err := runPowerShell(`Add-Type -Name Win -Namespace ActiveWindow -MemberDefinition '[DllImport("user32.dll")] public static extern System.IntPtr GetForegroundWindow(); [DllImport("user32.dll")] public static extern uint GetWindowThreadProcessId(System.IntPtr hWnd, out uint pid); [DllImport("user32.dll", CharSet = CharSet.Unicode)] public static extern int GetWindowText(System.IntPtr hWnd, System.Text.StringBuilder text, int count);'
+$h = [ActiveWindow.Win]::GetForegroundWindow()
+if ($h -eq [IntPtr]::Zero) { exit }
+$procId = 0
+[ActiveWindow.Win]::GetWindowThreadProcessId($h, [ref]$procId) | Out-Null
+$text = New-Object System.Text.StringBuilder 512
+[ActiveWindow.Win]::GetWindowText($h, $text, 512) | Out-Null
+"$((Get-Process -Id $procId -ErrorAction SilentlyContinue).ProcessName)|$procId|$text"`)
+		if err != nil {
+			return ActiveWindowOutput{}, fmt.Errorf("error al consultar la ventana activa: %w", err)
+		}
+		return parseActiveWindow(output)
+	case platformMac:
+		output, err := runAppleScript(`tell application "System Events"
+	set p to first application process whose frontmost is true
+	set t to ""
+	try
+		set t to name of front window of p
+	end try
+	return (name of p) & "|" & (unix id of p) & "|" & t
+end tell`)
+		if err != nil {
+			return ActiveWindowOutput{}, fmt.Errorf("AppleScript: %w", err)
+		}
+		return parseActiveWindow(output)
+	}
+
+	if session := detectLinuxSession(); session.Wayland {
+		return activeWindowGNOME(session)
+	}
+	if err := checkGraphicalSession(); err != nil {
+		return ActiveWindowOutput{}, err
+	}
+	return activeWindowX11()
+}
+
+// parseActiveWindow interpreta el "app|pid|título" de los scripts; el
+// título va al final porque puede contener '|'
+func parseActiveWindow(output string) (ActiveWindowOutput, error) {
+	fields := strings.SplitN(output, "|", 3)
+	if len(fields) < 3 {
+		return ActiveWindowOutput{}, errors.New("no hay ninguna ventana activa")
+	}
+	pid, _ := strconv.Atoi(fields[1])
+	return ActiveWindowOutput{App: fields[0], PID: pid, Title: fields[2]}, nil
+}
+
+// activeWindowX11 consulta la ventana activa con xdotool o, si no está, con
+// xprop (_NET_ACTIVE_WINDOW, _NET_WM_NAME y _NET_WM_PID)
+func activeWindowX11() (ActiveWindowOutput, error) {
+	var window ActiveWindowOutput
+	switch {
+	case commandExists("xdotool")():
+		id, err := exec.Command("xdotool", "getactivewindow").Output()
+		if err != nil {
+			return window, fmt.Errorf("error al ejecutar xdotool getactivewindow: %w", err)
+		}
+		wid := strings.TrimSpace(string(id))
+		title, _ := exec.Command("xdotool", "getwindowname", wid).Output()
+		pid, _ := exec.Command("xdotool", "getwindowpid", wid).Output()
+		window.Title = strings.TrimSpace(string(title))
+		window.PID, _ = strconv.Atoi(strings.TrimSpace(string(pid)))
+	case commandExists("xprop")():
+		// "_NET_ACTIVE_WINDOW(WINDOW): window id # 0x3a00003"
+		output, err := exec.Command("xprop", "-root", "_NET_ACTIVE_WINDOW").Output()
+		if err != nil {
+			return window, fmt.Errorf("error al ejecutar xprop: %w", err)
+		}
+		fields := strings.Fields(string(output))
+		if len(fields) == 0 || fields[len(fields)-1] == "0x0" {
+			return window, errors.New("no hay ninguna ventana activa")
+		}
+		// `_NET_WM_NAME(UTF8_STRING) = "Título"` y `_NET_WM_PID(CARDINAL) = 12345`
+		props, err := exec.Command("xprop", "-id", fields[len(fields)-1], "_NET_WM_NAME", "_NET_WM_PID").Output()
+		if err != nil {
+			return window, fmt.Errorf("error al ejecutar xprop: %w", err)
+		}
+		for _, line := range strings.Split(string(props), "\n") {
+			name, value, ok := strings.Cut(line, " = ")
+			switch {
+			case !ok:
+			case strings.HasPrefix(name, "_NET_WM_NAME"):
+				if title, err := strconv.Unquote(value); err == nil {
+					window.Title = title
+				} else {
+					window.Title = strings.Trim(value, `"`)
+				}
+			case strings.HasPrefix(name, "_NET_WM_PID"):
+				window.PID, _ = strconv.Atoi(value)
+			}
+		}
+	default:
+		return window, errors.New("se necesita xdotool o xprop para consultar la ventana activa")
+	}
+	if window.PID > 0 {
+		window.App = processName(strconv.Itoa(window.PID))
+	}
+	return window, nil
+}
+
+// gnomeActiveWindowScript devuelve la ventana con el foco desde dentro de
+// GNOME Shell
+const gnomeActiveWindowScript = `(() => { const w = global.display.focus_window; return w ? { app: w.get_wm_class() || "", pid: w.get_pid(), title: w.get_title() || "" } : null; })()`
+
+// activeWindowGNOME consulta la ventana activa a GNOME Shell con Eval. Los
+// compositores Wayland no exponen la ventana activa a otras aplicaciones, y
+// GNOME solo permite Eval en modo no seguro (desde GNOME 41), así que en el
+// resto de casos se devuelve un error.
+func activeWindowGNOME(session linuxSession) (ActiveWindowOutput, error) {
+	unavailable := fmt.Errorf("la ventana activa no se puede consultar en Wayland (compositor: %s)", session.Compositor())
+	if !session.IsDesktop("GNOME") || !commandExists("gdbus")() {
+		return ActiveWindowOutput{}, unavailable
+	}
+	output, err := exec.Command("gdbus", "call", "--session", "--dest", "org.gnome.Shell",
+		"--object-path", "/org/gnome/Shell", "--method", "org.gnome.Shell.Eval", gnomeActiveWindowScript).Output()
+	// "(true, '{"app":"firefox","pid":1234,"title":"..."}')"; (false, '') si Eval está desactivado
+	result, ok := strings.CutPrefix(strings.TrimSpace(string(output)), "(true, ")
+	if err != nil || !ok {
+		return ActiveWindowOutput{}, fmt.Errorf("%w: GNOME Shell no permite Eval fuera del modo no seguro", unavailable)
+	}
+	result = strings.TrimSuffix(result, ")")
+	if len(result) >= 2 {
+		result = result[1 : len(result)-1]
+	}
+	result = strings.NewReplacer(`\'`, `'`, `\"`, `"`, `\\`, `\`).Replace(result)
+	if result == "null" {
+		return ActiveWindowOutput{}, errors.New("no hay ninguna ventana activa")
+	}
+	var window ActiveWindowOutput
+	if err := json.Unmarshal([]byte(result), &window); err != nil {
+		return ActiveWindowOutput{}, fmt.Errorf("respuesta inesperada de GNOME Shell: %w", err)
+	}
+	if name := processName(strconv.Itoa(window.PID)); window.PID > 0 && name != "" {
+		window.App = name
+	}
+	return window, nil
+}
+
+func HandleGetActiveWindow(ctx context.Context, req *mcp.CallToolRequest, input struct{}) (*mcp.CallToolResult, ActiveWindowOutput, error) {
+	window, err := getActiveWindow()
+	if err != nil {
+		return nil, ActiveWindowOutput{}, fmt.Errorf("❌ Error al obtener la ventana activa: %w", err)
+	}
+	result := fmt.Sprintf("🪟 Ventana activa: %s", window.Title)
+	switch {
+	case window.App != "" && window.PID > 0:
+		result += fmt.Sprintf(" (%s, pid %d)", window.App, window.PID)
+	case window.PID > 0:
+		result += fmt.Sprintf(" (pid %d)", window.PID)
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: result},
+		},
+	}, window, nil
+}
@@ -0,0 +1,307 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// radioSwitch es una radio que el modo avión apaga
+type radioSwitch struct {
+	Name        string // clave en serverState.AirplaneRadios
+	Label       string
+	Enabled     bool
+	HardBlocked bool
+	set         func(on bool) error
+}
+
+// radioLabels son los nombres legibles de las radios, por clave
+var radioLabels = map[string]string{
+	"wifi":            "Wi-Fi",
+	"bluetooth":       "Bluetooth",
+	"wwan":            "datos móviles",
+	"MobileBroadband": "datos móviles",
+	"nfc":             "NFC",
+	"gps":             "GPS",
+	"fm":              "radio FM",
+	"FM":              "radio FM",
+	"uwb":             "UWB",
+}
+
+func radioLabel(name string) string {
+	if label, ok := radioLabels[name]; ok {
+		return label
+	}
+	return name
+}
+
+// errAirplaneSettingsUI indica que esta versión de Windows no ofrece la API
+// de radios, así que el modo avión solo se puede cambiar desde Configuración
+var errAirplaneSettingsUI = errors.New("la API de radios no está disponible en esta versión de Windows: el modo avión requiere la interfaz de Configuración (Configuración > Red e Internet > Modo avión)")
+
+// listRadioSwitches devuelve las radios del equipo y su estado
+func listRadioSwitches() ([]radioSwitch, error) {
+	switch currentPlatform() {
+	case platformWindows, platformWSL:
+		return listRadioSwitchesWindows()
+	}
+
+	// macOS no tiene modo avión: se compone con la Wi-Fi y el Bluetooth. En
+	// Linux se añaden las demás radios con interruptor rfkill (datos
+	// móviles, NFC...).
+	var radios []radioSwitch
+	if wifi, err := readWiFiState(); err == nil {
+		radios = append(radios, radioSwitch{Name: "wifi", Enabled: wifi.Enabled, HardBlocked: wifi.HardBlocked, set: func(on bool) error {
+			return writeWiFiState(on, wifi)
+		}})
+	}
+	if bt, err := readBluetoothState(); err == nil {
+		radios = append(radios, radioSwitch{Name: "bluetooth", Enabled: bt.Enabled, HardBlocked: bt.HardBlocked, set: func(on bool) error {
+			return writeBluetoothState(on, bt)
+		}})
+	}
+	if currentPlatform() == platformLinux {
+		dirs, _ := filepath.Glob("/sys/class/rfkill/rfkill*")
+		for _, dir := range dirs {
+			kind := readTrimmedFile(filepath.Join(dir, "type"))
+			if kind == "" || kind == "wlan" || kind == "bluetooth" || slices.ContainsFunc(radios, func(r radioSwitch) bool { return r.Name == kind }) {
+				continue
+			}
+			soft, hard, _ := linuxRfkill(kind)
+			radios = append(radios, radioSwitch{Name: kind, Enabled: !soft && !hard, HardBlocked: hard, set: func(on bool) error {
+				return setRfkill(kind, on)
+			}})
+		}
+	}
+	for i := range radios {
+		radios[i].Label = radioLabel(radios[i].Name)
+	}
+	return radios, nil
+}
+
+// setRfkill quita o pone el bloqueo por software de rfkill de un tipo de
+// radio ("all" para todas)
+func setRfkill(kind string, on bool) error {
+	rfkill, ok := sbinCommand("rfkill")
+	if !ok {
+		return errors.New("no se encontró la orden rfkill")
+	}
+	action := "block"
+	if on {
+		action = "unblock"
+	}
+	_, err := runRadioCommand(rfkill, action, kind)
+	return err
+}
+
+// windowsRadiosScript enumera las radios como "Kind=State"
+const windowsRadiosScript = windowsRadioPrelude + `
+$radios | ForEach-Object { '{0}={1}' -f $_.Kind, $_.State }
+`
+
+func listRadioSwitchesWindows() ([]radioSwitch, error) {
+	output, err := runPowerShell(windowsRadiosScript)
+	if err != nil {
+		return nil, fmt.Errorf("error al consultar las radios: %w", err)
+	}
+	if output == "" {
+		return nil, errAirplaneSettingsUI
+	}
+	var radios []radioSwitch
+	for _, line := range strings.Split(strings.ReplaceAll(output, "\r", ""), "\n") {
+		kind, state, ok := strings.Cut(strings.TrimSpace(line), "=")
+		if !ok || slices.ContainsFunc(radios, func(r radioSwitch) bool { return r.Name == kind }) {
+			continue
+		}
+		label := radioLabel(kind)
+		radios = append(radios, radioSwitch{Name: kind, Label: label, Enabled: state == "On", HardBlocked: state == "Disabled", set: func(on bool) error {
+			return setWindowsRadio(kind, label, on)
+		}})
+	}
+	return radios, nil
+}
+
+type SetAirplaneModeInput struct {
+	State string `json:"state" jsonschema:"on (activar el modo avión, apagando todas las radios) u off (desactivarlo, encendiendo las que estaban encendidas antes)"`
+}
+
+type AirplaneRadio struct {
+	Name    string `json:"name" jsonschema:"Radio (wifi, bluetooth, wwan...; en Windows el tipo de la API de radios)"`
+	Enabled bool   `json:"enabled" jsonschema:"La radio está encendida tras el cambio"`
+}
+
+type SetAirplaneModeOutput struct {
+	AirplaneMode bool            `json:"airplane_mode" jsonschema:"El modo avión quedó activado"`
+	TurnedOff    []string        `json:"turned_off" jsonschema:"Radios que se apagaron"`
+	TurnedOn     []string        `json:"turned_on" jsonschema:"Radios que se encendieron"`
+	Radios       []AirplaneRadio `json:"radios" jsonschema:"Estado de cada radio leído tras el cambio"`
+	Note         string          `json:"note,omitempty" jsonschema:"Aclaración sobre el resultado"`
+}
+
+// setAirplaneMode apaga todas las radios recordando cuáles estaban
+// encendidas, o vuelve a encender esas. La instantánea se guarda en
+// serverStateFile para que sobreviva a un reinicio del servidor.
+func setAirplaneMode(state string) (SetAirplaneModeOutput, error) {
+	state = strings.ToLower(strings.TrimSpace(state))
+	if state != "on" && state != "off" {
+		return SetAirplaneModeOutput{}, fmt.Errorf("estado no válido %q: usa on u off", state)
+	}
+	radios, err := listRadioSwitches()
+	if err != nil {
+		return SetAirplaneModeOutput{}, err
+	}
+	if len(radios) == 0 {
+		return SetAirplaneModeOutput{}, errors.New("no se encontró ninguna radio (Wi-Fi, Bluetooth...) que apagar")
+	}
+	saved, err := loadServerState()
+	if err != nil {
+		return SetAirplaneModeOutput{}, err
+	}
+
+	airplane := state == "on"
+	out := SetAirplaneModeOutput{AirplaneMode: airplane, TurnedOff: []string{}, TurnedOn: []string{}}
+	switch currentPlatform() {
+	case platformMac:
+		out.Note = "macOS no tiene modo avión: se apagan y encienden la Wi-Fi y el Bluetooth por separado"
+	case platformWSL:
+		out.Note = "Son las radios del Windows anfitrión, de las que también depende la red de WSL"
+	}
+
+	// Radios a cambiar. Al desactivar solo se encienden las que estaban
+	// encendidas antes; sin instantánea (modo avión activado fuera del
+	// servidor) solo la Wi-Fi.
+	targets := map[string]bool{}
+	var snapshot []string
+	if airplane {
+		for _, r := range radios {
+			targets[r.Name] = false
+			if r.Enabled {
+				snapshot = append(snapshot, r.Name)
+			}
+		}
+		// Repetir la orden con todo ya apagado no debe perder la instantánea
+		if len(snapshot) == 0 && saved.AirplaneRadios != nil {
+			snapshot = saved.AirplaneRadios
+		}
+		if snapshot == nil {
+			snapshot = []string{}
+		}
+		if err := updateServerState(func(s *serverState) { s.AirplaneRadios = snapshot }); err != nil {
+			return SetAirplaneModeOutput{}, err
+		}
+	} else {
+		restore := saved.AirplaneRadios
+		if restore == nil {
+			restore = []string{"wifi", "WiFi"}
+			out.Note = strings.TrimSpace(out.Note + " No había constancia de qué radios estaban encendidas antes del modo avión, así que solo se enciende la Wi-Fi.")
+		}
+		for _, r := range radios {
+			if slices.Contains(restore, r.Name) {
+				targets[r.Name] = true
+			}
+		}
+	}
+
+	// Estado de partida, para enumerar después qué radios cambiaron
+	before := map[string]bool{}
+	for _, r := range radios {
+		before[r.Name] = r.Enabled
+	}
+
+	// En Linux se apagan todas las radios de una vez, también las que no
+	// gestiona NetworkManager ni BlueZ; lo que quede se apaga una a una
+	var failures []string
+	if airplane && currentPlatform() == platformLinux {
+		if setRfkill("all", false) != nil && commandExists("nmcli")() {
+			if _, err := runRadioCommand("nmcli", "radio", "all", "off"); err != nil {
+				failures = append(failures, fmt.Sprintf("nmcli radio all off: %v", err))
+			}
+		}
+		if after, err := listRadioSwitches(); err == nil {
+			radios = after
+		}
+	}
+
+	failed := map[string]bool{}
+	for _, r := range radios {
+		want, ok := targets[r.Name]
+		if !ok || r.Enabled == want {
+			continue
+		}
+		if want && r.HardBlocked {
+			failures = append(failures, r.Label+": bloqueada por hardware (interruptor físico)")
+			continue
+		}
+		if err := r.set(want); err != nil {
+			failed[r.Name] = true
+			failures = append(failures, fmt.Sprintf("%s: %v", r.Label, err))
+		}
+	}
+
+	// Solo cuenta el estado que se lee después
+	final := waitForRadioState(true, radios, listRadioSwitches, func(list []radioSwitch) bool {
+		return !slices.ContainsFunc(list, func(r radioSwitch) bool {
+			want, ok := targets[r.Name]
+			return ok && r.Enabled != want && !(want && r.HardBlocked)
+		})
+	})
+	for _, r := range final {
+		out.Radios = append(out.Radios, AirplaneRadio{Name: r.Name, Enabled: r.Enabled})
+		switch was := before[r.Name]; {
+		case was && !r.Enabled:
+			out.TurnedOff = append(out.TurnedOff, r.Label)
+		case !was && r.Enabled:
+			out.TurnedOn = append(out.TurnedOn, r.Label)
+		}
+		if want, ok := targets[r.Name]; ok && r.Enabled != want && !r.HardBlocked && !failed[r.Name] {
+			failures = append(failures, fmt.Sprintf("%s: el sistema aceptó el cambio, pero sigue %s", r.Label, onOff(r.Enabled)))
+		}
+	}
+	if len(failures) > 0 {
+		done := ""
+		if changed := append(slices.Clone(out.TurnedOff), out.TurnedOn...); len(changed) > 0 {
+			done = " (sí cambiaron: " + strings.Join(changed, ", ") + ")"
+		}
+		return SetAirplaneModeOutput{}, fmt.Errorf("no se pudieron cambiar todas las radios%s: %s", done, strings.Join(failures, "; "))
+	}
+	if !airplane {
+		if err := updateServerState(func(s *serverState) { s.AirplaneRadios = nil }); err != nil {
+			return SetAirplaneModeOutput{}, err
+		}
+	}
+	return out, nil
+}
+
+func HandleSetAirplaneMode(ctx context.Context, req *mcp.CallToolRequest, input SetAirplaneModeInput) (*mcp.CallToolResult, SetAirplaneModeOutput, error) {
+	out, err := setAirplaneMode(input.State)
+	if err != nil {
+		return nil, SetAirplaneModeOutput{}, fmt.Errorf("❌ Error al cambiar el modo avión: %w", err)
+	}
+
+	line := "✈️ Modo avión activado"
+	if !out.AirplaneMode {
+		line = "📡 Modo avión desactivado"
+	}
+	switch {
+	case len(out.TurnedOff) > 0:
+		line += ". Apagadas: " + strings.Join(out.TurnedOff, ", ")
+	case len(out.TurnedOn) > 0:
+		line += ". Encendidas: " + strings.Join(out.TurnedOn, ", ")
+	default:
+		line += ". Ninguna radio cambió"
+	}
+	lines := []string{line}
+	if out.Note != "" {
+		lines = append(lines, "ℹ️ "+out.Note)
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: strings.Join(lines, "\n")},
+		},
+	}, out, nil
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// allowedAppsFile es el fichero de configuración con la lista JSON de
+// aplicaciones que open_app y close_app pueden usar. Admite comodines
+// ("Visual*", "*.exe"); sin fichero o con la lista vacía se permiten todas.
+const allowedAppsFile = "allowed_apps.json"
+
+// errAppNotAllowed indica que la aplicación no está en allowedAppsFile. Su
+// mensaje no dice si la aplicación existe.
+var errAppNotAllowed = errors.New("la aplicación no está en la lista de aplicaciones permitidas")
+
+// loadAllowedApps lee los patrones de allowedAppsFile; nil significa que se
+// permiten todas las aplicaciones
+func loadAllowedApps() ([]string, error) {
+	var patterns []string
+	if err := loadJSONConfig(allowedAppsFile, &patterns); err != nil {
+		return nil, fmt.Errorf("error al leer %s: %w", allowedAppsFile, err)
+	}
+	return patterns, nil
+}
+
+// appAllowed indica si alguno de los nombres de una aplicación (el pedido,
+// el visible o el ejecutable) encaja con algún patrón, sin distinguir
+// mayúsculas. Con la lista vacía todo está permitido.
+func appAllowed(patterns []string, names ...string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, name := range names {
+		if name == "" {
+			continue
+		}
+		candidates := []string{strings.ToLower(name)}
+		// El ejecutable sin argumentos: "/usr/bin/code --new-window" → "code"
+		if fields := strings.Fields(name); len(fields) > 0 {
+			if exe, ok := allowedExecutableName(fields[0]); ok {
+				candidates = append(candidates, strings.ToLower(exe))
+			}
+		}
+		for _, pattern := range patterns {
+			for _, candidate := range candidates {
+				matched, err := path.Match(strings.ToLower(pattern), candidate)
+				if err != nil {
+					log.Printf("⚠️ %s: patrón no válido %q: %v", allowedAppsFile, pattern, err)
+					break
+				}
+				if matched {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// allowedExecutableName devuelve el nombre con el que se compara el
+// ejecutable exe con la lista. Una ruta solo cuenta por su nombre base si es
+// la misma que encuentra el PATH para ese nombre, como en
+// resolveAllowedCommand: si no, "/tmp/evil/firefox" pasaría por "firefox".
+func allowedExecutableName(exe string) (string, bool) {
+	base := filepath.Base(exe)
+	if base == exe {
+		return exe, true
+	}
+	found, err := exec.LookPath(base)
+	if err != nil {
+		return "", false
+	}
+	found, err = filepath.Abs(found)
+	if err != nil {
+		return "", false
+	}
+	if exe, err = filepath.Abs(exe); err != nil {
+		return "", false
+	}
+	// Las rutas de Windows no distinguen mayúsculas
+	if found == exe || (osType == "windows" && strings.EqualFold(found, exe)) {
+		return base, true
+	}
+	return "", false
+}
+
+// filterAllowedApps devuelve las aplicaciones instaladas permitidas, para
+// que las sugerencias no muestren las demás
+func filterAllowedApps(patterns []string, apps []InstalledApp) []InstalledApp {
+	if len(patterns) == 0 {
+		return apps
+	}
+	var allowed []InstalledApp
+	for _, app := range apps {
+		if appAllowed(patterns, app.Name, app.LaunchID) {
+			allowed = append(allowed, app)
+		}
+	}
+	return allowed
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAppAllowed(t *testing.T) {
+	fakeCommands(t, "firefox")
+	firefox := filepath.Join(os.Getenv("PATH"), "firefox")
+	patterns := []string{"Visual*", "firefox", "org.gnome.*", "notepad*"}
+	tests := []struct {
+		name  string
+		names []string
+		want  bool
+	}{
+		{"glob", []string{"Visual Studio Code"}, true},
+		{"glob sin sufijo", []string{"Visual"}, true},
+		{"glob en minúsculas", []string{"visual studio code"}, true},
+		{"glob con punto", []string{"org.gnome.Calculator"}, true},
+		{"exacto", []string{"firefox"}, true},
+		{"exacto en mayúsculas", []string{"FireFox"}, true},
+		{"ejecutable del PATH con ruta y argumentos", []string{firefox + " --private-window"}, true},
+		{"ejecutable con el mismo nombre fuera del PATH", []string{"/tmp/evil/firefox"}, false},
+		{"ruta relativa con el mismo nombre", []string{"./firefox --private-window"}, false},
+		{"ruta de Windows con el mismo nombre", []string{`C:\x\notepad.exe`}, false},
+		{"segundo nombre", []string{"Navegador", "firefox"}, true},
+		{"prefijo de un nombre exacto", []string{"firefox-esr"}, false},
+		{"parte de un nombre exacto", []string{"fire"}, false},
+		{"glob al final del nombre", []string{"Microsoft Visual Studio"}, false},
+		{"otra aplicación", []string{"gnome-terminal"}, false},
+		{"vacío", []string{""}, false},
+	}
+	for _, tc := range tests {
+		if got := appAllowed(patterns, tc.names...); got != tc.want {
+			t.Errorf("%s: appAllowed(%q) = %v; se esperaba %v", tc.name, tc.names, got, tc.want)
+		}
+	}
+}
+
+func TestAppAllowedEmptyList(t *testing.T) {
+	// Sin allowed_apps.json no hay restricciones
+	if !appAllowed(nil, "cualquier-cosa") {
+		t.Error("appAllowed sin patrones = false; se esperaba true")
+	}
+}
+
+func TestAppAllowedInvalidPattern(t *testing.T) {
+	// Un patrón mal formado no coincide con nada, pero no impide usar el resto
+	if appAllowed([]string{"[firefox"}, "firefox") {
+		t.Error("appAllowed con un patrón no válido = true; se esperaba false")
+	}
+	if !appAllowed([]string{"[firefox", "code"}, "code") {
+		t.Error("appAllowed con un patrón no válido y otro válido = false; se esperaba true")
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+)
+
+// errAppNotFound indica que no existe ninguna aplicación con el nombre
+// exacto pedido, y que merece la pena buscar una parecida
+var errAppNotFound = errors.New("aplicación no encontrada")
+
+// maxAppSuggestions limita las sugerencias cuando varias aplicaciones se
+// parecen al nombre pedido
+const maxAppSuggestions = 5
+
+// levenshtein calcula la distancia de edición entre a y b
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+// appMatchScore puntúa lo bien que app coincide con query (en minúsculas):
+// 3 si coincide el nombre o el ejecutable, 2 si lo contiene, 1 si está a
+// pocas letras de distancia y 0 si no se parece
+func appMatchScore(app InstalledApp, query string) int {
+	name := strings.ToLower(app.Name)
+	binary := strings.ToLower(filepath.Base(strings.Fields(app.LaunchID + " ")[0]))
+	switch {
+	case name == query || binary == query || strings.ToLower(app.LaunchID) == query:
+		return 3
+	case len([]rune(query)) >= 3 && (strings.Contains(name, query) || strings.Contains(binary, query)):
+		return 2
+	}
+	// Se tolera una errata cada cuatro letras
+	limit := max(1, len([]rune(query))/4)
+	if levenshtein(name, query) <= limit || levenshtein(binary, query) <= limit {
+		return 1
+	}
+	return 0
+}
+
+// matchInstalledApp busca en apps la aplicación que el usuario probablemente
+// quería decir con query. Devuelve la coincidencia si solo hay una con la
+// mejor puntuación; si hay varias, las devuelve como sugerencias.
+func matchInstalledApp(query string, apps []InstalledApp) (*InstalledApp, []InstalledApp) {
+	query = strings.ToLower(strings.TrimSpace(query))
+	best := 0
+	var candidates []InstalledApp
+	for _, app := range apps {
+		score := appMatchScore(app, query)
+		switch {
+		case score == 0 || score < best:
+		case score > best:
+			best = score
+			candidates = []InstalledApp{app}
+		default:
+			candidates = append(candidates, app)
+		}
+	}
+	if len(candidates) == 1 {
+		return &candidates[0], nil
+	}
+	if len(candidates) > maxAppSuggestions {
+		candidates = candidates[:maxAppSuggestions]
+	}
+	return nil, candidates
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+var testInstalledApps = []InstalledApp{
+	{Name: "Firefox", LaunchID: "firefox"},
+	{Name: "Visual Studio Code", LaunchID: "code"},
+	{Name: "Google Chrome", LaunchID: "google-chrome"},
+	{Name: "Chromium", LaunchID: "chromium"},
+	{Name: "Spotify", LaunchID: "/usr/bin/spotify --uri=%U"},
+	{Name: "GNOME Terminal", LaunchID: "org.gnome.Terminal"},
+	{Name: "Calculator", LaunchID: "org.gnome.Calculator"},
+}
+
+func TestMatchInstalledAppUnique(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		// Coincidencia exacta del nombre, del ejecutable o del launch id
+		{"Firefox", "Firefox"},
+		{"visual studio code", "Visual Studio Code"},
+		{"spotify", "Spotify"},
+		{"org.gnome.calculator", "Calculator"},
+		// "code" también está dentro de "Visual Studio Code", pero gana el ejecutable exacto
+		{"code", "Visual Studio Code"},
+		// Parecidas, con una sola candidata
+		{"firefx", "Firefox"},
+		{"  Spotfy ", "Spotify"},
+		{"studio", "Visual Studio Code"},
+		{"terminal", "GNOME Terminal"},
+	}
+	for _, tc := range tests {
+		match, suggestions := matchInstalledApp(tc.query, testInstalledApps)
+		if match == nil {
+			t.Errorf("matchInstalledApp(%q) = nil, %v; se esperaba %s", tc.query, suggestions, tc.want)
+			continue
+		}
+		if match.Name != tc.want || suggestions != nil {
+			t.Errorf("matchInstalledApp(%q) = %s, %v; se esperaba %s", tc.query, match.Name, suggestions, tc.want)
+		}
+	}
+}
+
+func TestMatchInstalledAppAmbiguous(t *testing.T) {
+	match, suggestions := matchInstalledApp("chrom", testInstalledApps)
+	if match != nil {
+		t.Fatalf("matchInstalledApp(\"chrom\") = %s; se esperaban sugerencias", match.Name)
+	}
+	if len(suggestions) != 2 || suggestions[0].Name != "Google Chrome" || suggestions[1].Name != "Chromium" {
+		t.Errorf("sugerencias para \"chrom\" = %v; se esperaban Google Chrome y Chromium", suggestions)
+	}
+
+	// Las sugerencias se limitan a maxAppSuggestions
+	var many []InstalledApp
+	for i := range maxAppSuggestions + 2 {
+		many = append(many, InstalledApp{Name: fmt.Sprintf("Editor %d", i), LaunchID: fmt.Sprintf("editor%d", i)})
+	}
+	if match, suggestions := matchInstalledApp("editor", many); match != nil || len(suggestions) != maxAppSuggestions {
+		t.Errorf("matchInstalledApp(\"editor\") = %v, %d sugerencias; se esperaban %d", match, len(suggestions), maxAppSuggestions)
+	}
+}
+
+func TestMatchInstalledAppNone(t *testing.T) {
+	for _, query := range []string{"photoshop", "xyz", ""} {
+		if match, suggestions := matchInstalledApp(query, testInstalledApps); match != nil || len(suggestions) != 0 {
+			t.Errorf("matchInstalledApp(%q) = %v, %v; no se esperaba ninguna coincidencia", query, match, suggestions)
+		}
+	}
+}
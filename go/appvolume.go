@@ -0,0 +1,289 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// psAudioSessions define la clase [AppAudio] en PowerShell para enumerar las
+// sesiones de audio del dispositivo de salida por defecto y cambiar su volumen
+// (IAudioSessionManager2 + ISimpleAudioVolume)
+const psAudioSessions = `Add-Type -TypeDefinition @'
+using System;
+using System.Collections.Generic;
+using System.Runtime.InteropServices;
+[Guid("87CE5498-68D6-44E5-9215-6F4BB6BE7577"), InterfaceType(ComInterfaceType.InterfaceIsIUnknown)]
+interface ISimpleAudioVolume {
+  int SetMasterVolume(float fLevel, ref Guid eventContext);
+  int GetMasterVolume(out float pfLevel);
+}
+[Guid("BFB7FF88-7239-4FC9-8FA2-07C950BE9C6D"), InterfaceType(ComInterfaceType.InterfaceIsIUnknown)]
+interface IAudioSessionControl2 {
+  int a(); int b(); int c(); int d(); int e(); int f(); int g(); int h(); int i();
+  int GetSessionIdentifier([MarshalAs(UnmanagedType.LPWStr)] out string id);
+  int GetSessionInstanceIdentifier([MarshalAs(UnmanagedType.LPWStr)] out string id);
+  int GetProcessId(out uint pid);
+  [PreserveSig] int IsSystemSoundsSession();
+}
+[Guid("E2F5BB11-0570-40CA-ACDD-3AA01277DEE8"), InterfaceType(ComInterfaceType.InterfaceIsIUnknown)]
+interface IAudioSessionEnumerator {
+  int GetCount(out int count);
+  int GetSession(int index, out IAudioSessionControl2 session);
+}
+[Guid("77AA99A0-1BD6-484F-8BC7-2C654C9A9B6F"), InterfaceType(ComInterfaceType.InterfaceIsIUnknown)]
+interface IAudioSessionManager2 {
+  int a(); int b();
+  int GetSessionEnumerator(out IAudioSessionEnumerator sessionEnum);
+}
+[Guid("D666063F-1587-4E43-81F1-B948E807363F"), InterfaceType(ComInterfaceType.InterfaceIsIUnknown)]
+interface IMMDevice {
+  int Activate(ref Guid id, int clsCtx, IntPtr activationParams, [MarshalAs(UnmanagedType.IUnknown)] out object iface);
+}
+[Guid("A95664D2-9614-4F35-A746-DE8DB63617E6"), InterfaceType(ComInterfaceType.InterfaceIsIUnknown)]
+interface IMMDeviceEnumerator {
+  int f();
+  int GetDefaultAudioEndpoint(int dataFlow, int role, out IMMDevice endpoint);
+}
+[ComImport, Guid("BCDE0395-E52F-467C-8E3D-C4579291692E")] class MMDeviceEnumeratorComObject { }
+public class AppAudio {
+  static List<IAudioSessionControl2> Sessions() {
+    var enumerator = new MMDeviceEnumeratorComObject() as IMMDeviceEnumerator;
+    IMMDevice dev = null;
+    Marshal.ThrowExceptionForHR(enumerator.GetDefaultAudioEndpoint(0, 1, out dev));
+    var iid = typeof(IAudioSessionManager2).GUID;
+    object o = null;
+    Marshal.ThrowExceptionForHR(dev.Activate(ref iid, 23, IntPtr.Zero, out o));
+    IAudioSessionEnumerator sessions = null;
+    Marshal.ThrowExceptionForHR(((IAudioSessionManager2)o).GetSessionEnumerator(out sessions));
+    int count;
+    Marshal.ThrowExceptionForHR(sessions.GetCount(out count));
+    var list = new List<IAudioSessionControl2>();
+    for (int i = 0; i < count; i++) {
+      IAudioSessionControl2 s = null;
+      Marshal.ThrowExceptionForHR(sessions.GetSession(i, out s));
+      list.Add(s);
+    }
+    return list;
+  }
+  // Una línea "indice|nombre del proceso|volumen" por sesión
+  public static string[] List() {
+    var lines = new List<string>();
+    var sessions = Sessions();
+    for (int i = 0; i < sessions.Count; i++) {
+      string name = "System Sounds";
+      if (sessions[i].IsSystemSoundsSession() != 0) {
+        uint pid;
+        sessions[i].GetProcessId(out pid);
+        try { name = System.Diagnostics.Process.GetProcessById((int)pid).ProcessName; } catch { continue; }
+      }
+      float level;
+      ((ISimpleAudioVolume)sessions[i]).GetMasterVolume(out level);
+      lines.Add(i + "|" + name + "|" + (int)Math.Round(level * 100));
+    }
+    return lines.ToArray();
+  }
+  public static void SetVolume(int[] indexes, float level) {
+    var sessions = Sessions();
+    var ctx = Guid.Empty;
+    foreach (int i in indexes) {
+      Marshal.ThrowExceptionForHR(((ISimpleAudioVolume)sessions[i]).SetMasterVolume(level, ref ctx));
+    }
+  }
+}
+'@
+`
+
+// appStream es un flujo de audio de una aplicación: un sink-input de
+// PulseAudio/PipeWire o una sesión de audio de Windows
+type appStream struct {
+	ID     string
+	App    string
+	Binary string
+	Volume int
+}
+
+// listAppStreams devuelve los flujos de audio de las aplicaciones
+func listAppStreams() ([]appStream, error) {
+	switch currentPlatform() {
+	case platformWindows, platformWSL:
+		output, err := runPowerShell(psAudioSessions + "[AppAudio]::List()")
+		if err != nil {
+			return nil, fmt.Errorf("error al consultar las sesiones de audio: %w", err)
+		}
+		var streams []appStream
+		for _, line := range strings.Split(strings.ReplaceAll(output, "\r\n", "\n"), "\n") {
+			fields := strings.SplitN(strings.TrimSpace(line), "|", 3)
+			if len(fields) != 3 {
+				continue
+			}
+			volume, _ := strconv.Atoi(fields[2])
+			streams = append(streams, appStream{ID: fields[0], App: fields[1], Volume: volume})
+		}
+		return streams, nil
+	case platformMac:
+		return nil, errors.New("macOS no permite ajustar el volumen de cada aplicación por separado; hace falta software adicional como Background Music o SoundSource")
+	}
+
+	if !commandExists("pactl")() {
+		return nil, errors.New("pactl no está instalado (hace falta PulseAudio o pipewire-pulse)")
+	}
+	output, err := exec.Command("pactl", "list", "sink-inputs").Output()
+	if err != nil {
+		return nil, fmt.Errorf("error al ejecutar pactl list sink-inputs: %w", err)
+	}
+	return parsePactlStreams(string(output)), nil
+}
+
+// parsePactlStreams interpreta `pactl list sink-inputs` o `source-outputs`:
+//
+//	Sink Input #42
+//		Volume: front-left: 65536 / 100% / 0,00 dB,   front-right: ...
+//		Properties:
+//			application.name = "Spotify"
+//			application.process.binary = "spotify"
+func parsePactlStreams(output string) []appStream {
+	var streams []appStream
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if kind, id, ok := strings.Cut(line, " #"); ok && (kind == "Sink Input" || kind == "Source Output") {
+			streams = append(streams, appStream{ID: id, Volume: -1})
+			continue
+		}
+		if len(streams) == 0 {
+			continue
+		}
+		s := &streams[len(streams)-1]
+		if strings.HasPrefix(line, "Volume:") {
+			if match := percentPattern.FindStringSubmatch(line); match != nil {
+				s.Volume, _ = strconv.Atoi(match[1])
+			}
+			continue
+		}
+		key, value, ok := strings.Cut(line, " = ")
+		if !ok {
+			continue
+		}
+		value = strings.Trim(value, `"`)
+		switch key {
+		case "application.name":
+			s.App = value
+		case "application.process.binary":
+			s.Binary = value
+		}
+	}
+	return streams
+}
+
+// matchAppStreams devuelve los flujos de la aplicación pedida. Si alguno
+// coincide exactamente con el nombre se usan solo esos; si no, los que lo
+// contienen, sin distinguir mayúsculas.
+func matchAppStreams(streams []appStream, app string) []appStream {
+	var exact, partial []appStream
+	q := strings.ToLower(app)
+	for _, s := range streams {
+		name, binary := strings.ToLower(s.App), strings.ToLower(s.Binary)
+		switch {
+		case name == q || binary == q:
+			exact = append(exact, s)
+		case strings.Contains(name, q) || (binary != "" && strings.Contains(binary, q)):
+			partial = append(partial, s)
+		}
+	}
+	if len(exact) > 0 {
+		return exact
+	}
+	return partial
+}
+
+// appNames devuelve los nombres distintos de las aplicaciones con audio
+func appNames(streams []appStream) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, s := range streams {
+		name := s.App
+		if name == "" {
+			name = s.Binary
+		}
+		if name != "" && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// setAppVolume ajusta el volumen (0-100) de todos los flujos de una aplicación
+func setAppVolume(app string, level int) (string, error) {
+	app = strings.TrimSpace(app)
+	if app == "" {
+		return "", errors.New("indica el nombre de la aplicación")
+	}
+	if level < 0 || level > 100 {
+		return "", fmt.Errorf("nivel %d fuera de rango (0-100)", level)
+	}
+
+	streams, err := listAppStreams()
+	if err != nil {
+		return "", err
+	}
+	if len(streams) == 0 {
+		return "", errors.New("ninguna aplicación está reproduciendo audio ahora mismo")
+	}
+	matches := matchAppStreams(streams, app)
+	if len(matches) == 0 {
+		return "", fmt.Errorf("%s no está reproduciendo audio (aplicaciones con audio: %s)", app, strings.Join(appNames(streams), ", "))
+	}
+
+	switch currentPlatform() {
+	case platformWindows, platformWSL:
+		ids := make([]string, len(matches))
+		for i, s := range matches {
+			ids[i] = s.ID
+		}
+		if _, err := runPowerShell(psAudioSessions + fmt.Sprintf("[AppAudio]::SetVolume(@(%s), %.2f)", strings.Join(ids, ","), float64(level)/100)); err != nil {
+			return "", fmt.Errorf("error al cambiar el volumen de la sesión: %w", err)
+		}
+	default:
+		for _, s := range matches {
+			if err := exec.Command("pactl", "set-sink-input-volume", s.ID, fmt.Sprintf("%d%%", level)).Run(); err != nil {
+				return "", fmt.Errorf("error al cambiar el volumen del flujo %s: %w", s.ID, err)
+			}
+		}
+	}
+
+	names := strings.Join(appNames(matches), ", ")
+	if len(matches) == 1 && matches[0].Volume >= 0 {
+		return fmt.Sprintf("🔊 Volumen de %s ajustado a %d%% (antes %d%%)", names, level, matches[0].Volume), nil
+	}
+	if len(matches) == 1 {
+		return fmt.Sprintf("🔊 Volumen de %s ajustado a %d%%", names, level), nil
+	}
+	return fmt.Sprintf("🔊 Volumen de %s ajustado a %d%% en %d flujos de audio", names, level, len(matches)), nil
+}
+
+type SetAppVolumeInput struct {
+	AppName string `json:"app_name" jsonschema:"Nombre de la aplicación (ej: 'Spotify', 'firefox'). Basta con parte del nombre"`
+	Level   int    `json:"level" jsonschema:"Volumen de la aplicación (0-100), relativo al volumen del sistema"`
+}
+
+func HandleSetAppVolume(ctx context.Context, req *mcp.CallToolRequest, input SetAppVolumeInput) (*mcp.CallToolResult, any, error) {
+	result, err := setAppVolume(input.AppName, input.Level)
+	if err != nil {
+		return nil, nil, fmt.Errorf("❌ Error al ajustar el volumen de la aplicación: %w", err)
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: result},
+		},
+	}, nil, nil
+}
@@ -0,0 +1,354 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Direcciones de los dispositivos de audio
+const (
+	audioOutput = "output"
+	audioInput  = "input"
+)
+
+// AudioDevice es un dispositivo de reproducción o de captura
+type AudioDevice struct {
+	ID        string `json:"id" jsonschema:"Identificador del dispositivo en el sistema de audio"`
+	Name      string `json:"name" jsonschema:"Nombre legible del dispositivo"`
+	Direction string `json:"direction" jsonschema:"output (salida) o input (entrada)"`
+	Default   bool   `json:"default" jsonschema:"Es el dispositivo por defecto para su dirección"`
+}
+
+// errAudioDeviceCmdlets explica cómo habilitar el cambio de dispositivo en Windows
+var errAudioDeviceCmdlets = errors.New("hace falta el módulo de PowerShell AudioDeviceCmdlets (Install-Module AudioDeviceCmdlets -Scope CurrentUser)")
+
+// listAudioDevices enumera los dispositivos de una dirección, o de ambas si
+// direction está vacío
+func listAudioDevices(direction string) ([]AudioDevice, error) {
+	directions := []string{audioOutput, audioInput}
+	if direction != "" {
+		directions = []string{direction}
+	}
+
+	var devices []AudioDevice
+	for _, d := range directions {
+		var list []AudioDevice
+		var err error
+		switch currentPlatform() {
+		case platformWindows, platformWSL:
+			list, err = listAudioDevicesWindows(d)
+		case platformMac:
+			list, err = listAudioDevicesMac(d)
+		default:
+			list, err = listAudioDevicesPulse(d)
+		}
+		if err != nil {
+			return nil, err
+		}
+		devices = append(devices, list...)
+	}
+	return devices, nil
+}
+
+// listAudioDevicesPulse usa `pactl list sinks|sources` (PulseAudio o
+// PipeWire con pipewire-pulse). Las fuentes ".monitor" de las salidas se omiten.
+func listAudioDevicesPulse(direction string) ([]AudioDevice, error) {
+	if !commandExists("pactl")() {
+		return nil, errors.New("pactl no está instalado (hace falta PulseAudio o pipewire-pulse)")
+	}
+	kind, defaultKey := "sinks", "Default Sink: "
+	if direction == audioInput {
+		kind, defaultKey = "sources", "Default Source: "
+	}
+
+	info, err := exec.Command("pactl", "info").Output()
+	if err != nil {
+		return nil, fmt.Errorf("error al ejecutar pactl info: %w", err)
+	}
+	var defaultName string
+	for _, line := range strings.Split(string(info), "\n") {
+		if strings.HasPrefix(line, defaultKey) {
+			defaultName = strings.TrimSpace(strings.TrimPrefix(line, defaultKey))
+		}
+	}
+
+	output, err := exec.Command("pactl", "list", kind).Output()
+	if err != nil {
+		return nil, fmt.Errorf("error al ejecutar pactl list %s: %w", kind, err)
+	}
+	var devices []AudioDevice
+	for _, d := range parsePactlList(string(output)) {
+		if direction == audioInput && strings.HasSuffix(d.ID, ".monitor") {
+			continue
+		}
+		d.Direction = direction
+		d.Default = d.ID == defaultName
+		devices = append(devices, d)
+	}
+	return devices, nil
+}
+
+// parsePactlList interpreta los bloques de `pactl list sinks` o `sources`:
+//
+//	Sink #0
+//		State: RUNNING
+//		Name: alsa_output.pci-0000_00_1f.3.analog-stereo
+//		Description: Built-in Audio Analog Stereo
+func parsePactlList(output string) []AudioDevice {
+	var devices []AudioDevice
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case strings.HasPrefix(line, "Sink #") || strings.HasPrefix(line, "Source #"):
+			devices = append(devices, AudioDevice{})
+		case len(devices) == 0:
+			continue
+		case strings.HasPrefix(line, "Name: "):
+			devices[len(devices)-1].ID = strings.TrimPrefix(line, "Name: ")
+		case strings.HasPrefix(line, "Description: "):
+			devices[len(devices)-1].Name = strings.TrimPrefix(line, "Description: ")
+		}
+	}
+	return devices
+}
+
+// listAudioDevicesMac usa SwitchAudioSource (brew install switchaudio-osx),
+// que identifica los dispositivos por su nombre
+func listAudioDevicesMac(direction string) ([]AudioDevice, error) {
+	if !commandExists("SwitchAudioSource")() {
+		return nil, errors.New("SwitchAudioSource no está instalado (brew install switchaudio-osx)")
+	}
+	output, err := exec.Command("SwitchAudioSource", "-a", "-t", direction).Output()
+	if err != nil {
+		return nil, fmt.Errorf("error al listar dispositivos: %w", err)
+	}
+	current, err := exec.Command("SwitchAudioSource", "-c", "-t", direction).Output()
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener el dispositivo actual: %w", err)
+	}
+
+	var devices []AudioDevice
+	for _, line := range strings.Split(string(output), "\n") {
+		name := strings.TrimSpace(line)
+		if name == "" {
+			continue
+		}
+		devices = append(devices, AudioDevice{
+			ID:        name,
+			Name:      name,
+			Direction: direction,
+			Default:   name == strings.TrimSpace(string(current)),
+		})
+	}
+	return devices, nil
+}
+
+// runAudioDeviceCmdlets ejecuta script con el módulo AudioDeviceCmdlets
+// cargado, o devuelve errAudioDeviceCmdlets si no está instalado
+func runAudioDeviceCmdlets(script string) (string, error) {
+	output, err := runPowerShell("if (-not (Get-Module -ListAvailable AudioDeviceCmdlets)) { 'NO_MODULE'; exit }; Import-Module AudioDeviceCmdlets; " + script)
+	if err != nil {
+		return "", err
+	}
+	if output == "NO_MODULE" {
+		return "", errAudioDeviceCmdlets
+	}
+	return output, nil
+}
+
+// listAudioDevicesWindows usa Get-AudioDevice del módulo AudioDeviceCmdlets
+func listAudioDevicesWindows(direction string) ([]AudioDevice, error) {
+	kind := "Playback"
+	if direction == audioInput {
+		kind = "Recording"
+	}
+	output, err := runAudioDeviceCmdlets(fmt.Sprintf("Get-AudioDevice -List | Where-Object Type -eq '%s' | ForEach-Object { \"$($_.ID)|$($_.Default)|$($_.Name)\" }", kind))
+	if err != nil {
+		return nil, err
+	}
+
+	var devices []AudioDevice
+	for _, line := range strings.Split(strings.ReplaceAll(output, "\r\n", "\n"), "\n") {
+		fields := strings.SplitN(strings.TrimSpace(line), "|", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		devices = append(devices, AudioDevice{
+			ID:        fields[0],
+			Name:      fields[2],
+			Direction: direction,
+			Default:   strings.EqualFold(fields[1], "True"),
+		})
+	}
+	return devices, nil
+}
+
+// findAudioDevice busca un dispositivo por id o nombre exactos y, si no,
+// por coincidencia parcial del nombre o del id sin distinguir mayúsculas, de
+// forma que "monitor" encuentre "DELL U2720Q HDMI Audio (Monitor)"
+func findAudioDevice(devices []AudioDevice, query string) (AudioDevice, error) {
+	names := make([]string, len(devices))
+	for i, d := range devices {
+		names[i] = d.Name
+		if strings.EqualFold(d.ID, query) || strings.EqualFold(d.Name, query) {
+			return d, nil
+		}
+	}
+
+	q := strings.ToLower(query)
+	var matches []AudioDevice
+	for _, d := range devices {
+		if strings.Contains(strings.ToLower(d.Name), q) || strings.Contains(strings.ToLower(d.ID), q) {
+			matches = append(matches, d)
+		}
+	}
+	switch len(matches) {
+	case 1:
+		return matches[0], nil
+	case 0:
+		if len(devices) == 0 {
+			return AudioDevice{}, errors.New("no se encontró ningún dispositivo")
+		}
+		return AudioDevice{}, fmt.Errorf("no se encontró el dispositivo %q (disponibles: %s)", query, strings.Join(names, ", "))
+	}
+	ambiguous := make([]string, len(matches))
+	for i, d := range matches {
+		ambiguous[i] = d.Name
+	}
+	return AudioDevice{}, fmt.Errorf("%q coincide con varios dispositivos: %s", query, strings.Join(ambiguous, ", "))
+}
+
+// setDefaultAudioDevice convierte en predeterminado el dispositivo de la
+// dirección indicada que coincide con query
+func setDefaultAudioDevice(direction, query string) (AudioDevice, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return AudioDevice{}, errors.New("indica el nombre o id del dispositivo")
+	}
+	devices, err := listAudioDevices(direction)
+	if err != nil {
+		return AudioDevice{}, err
+	}
+	device, err := findAudioDevice(devices, query)
+	if err != nil {
+		return AudioDevice{}, err
+	}
+
+	switch currentPlatform() {
+	case platformWindows, platformWSL:
+		_, err = runAudioDeviceCmdlets(fmt.Sprintf("Set-AudioDevice -ID '%s' | Out-Null", strings.ReplaceAll(device.ID, "'", "''")))
+	case platformMac:
+		err = exec.Command("SwitchAudioSource", "-t", direction, "-s", device.ID).Run()
+	default:
+		err = setDefaultPulseDevice(direction, device.ID)
+	}
+	if err != nil {
+		return AudioDevice{}, fmt.Errorf("error al cambiar a %s: %w", device.Name, err)
+	}
+	device.Default = true
+	return device, nil
+}
+
+// setDefaultPulseDevice cambia el sink o source por defecto y mueve a él los
+// flujos activos, que si no seguirían sonando por el dispositivo anterior
+func setDefaultPulseDevice(direction, name string) error {
+	setCmd, streams, moveCmd := "set-default-sink", "sink-inputs", "move-sink-input"
+	if direction == audioInput {
+		setCmd, streams, moveCmd = "set-default-source", "source-outputs", "move-source-output"
+	}
+	if err := exec.Command("pactl", setCmd, name).Run(); err != nil {
+		return err
+	}
+	output, err := exec.Command("pactl", "list", "short", streams).Output()
+	if err != nil {
+		return nil
+	}
+	for _, line := range strings.Split(string(output), "\n") {
+		if fields := strings.Fields(line); len(fields) > 0 {
+			// Un flujo que termina mientras tanto no es un error
+			exec.Command("pactl", moveCmd, fields[0], name).Run()
+		}
+	}
+	return nil
+}
+
+// describeAudioDevices lista los dispositivos marcando el predeterminado
+func describeAudioDevices(devices []AudioDevice) string {
+	if len(devices) == 0 {
+		return "🎧 No se encontraron dispositivos de audio"
+	}
+	labels := map[string]string{audioOutput: "Salidas", audioInput: "Entradas"}
+	var lines []string
+	current := ""
+	for _, d := range devices {
+		if d.Direction != current {
+			current = d.Direction
+			lines = append(lines, fmt.Sprintf("🎧 %s de audio:", labels[current]))
+		}
+		marker := ""
+		if d.Default {
+			marker = " ⭐ (por defecto)"
+		}
+		lines = append(lines, fmt.Sprintf("  - %s [%s]%s", d.Name, d.ID, marker))
+	}
+	return strings.Join(lines, "\n")
+}
+
+type ListAudioDevicesInput struct {
+	Direction string `json:"direction,omitempty" jsonschema:"output (salidas), input (entradas) o vacío para ambas"`
+}
+
+type ListAudioDevicesOutput struct {
+	Devices []AudioDevice `json:"devices,omitempty" jsonschema:"Dispositivos de audio"`
+}
+
+type SetAudioDeviceInput struct {
+	Device string `json:"device" jsonschema:"Nombre o id del dispositivo. Basta con parte del nombre, sin distinguir mayúsculas (ej: 'monitor', 'headset')"`
+}
+
+func HandleListAudioDevices(ctx context.Context, req *mcp.CallToolRequest, input ListAudioDevicesInput) (*mcp.CallToolResult, ListAudioDevicesOutput, error) {
+	direction := strings.ToLower(strings.TrimSpace(input.Direction))
+	if direction != "" && direction != audioOutput && direction != audioInput {
+		return nil, ListAudioDevicesOutput{}, fmt.Errorf("❌ Dirección no válida %q: usa output, input o déjala vacía", input.Direction)
+	}
+	devices, err := listAudioDevices(direction)
+	if err != nil {
+		return nil, ListAudioDevicesOutput{}, fmt.Errorf("❌ Error al listar dispositivos de audio: %w", err)
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: describeAudioDevices(devices)},
+		},
+	}, ListAudioDevicesOutput{Devices: devices}, nil
+}
+
+func HandleSetAudioOutput(ctx context.Context, req *mcp.CallToolRequest, input SetAudioDeviceInput) (*mcp.CallToolResult, any, error) {
+	device, err := setDefaultAudioDevice(audioOutput, input.Device)
+	if err != nil {
+		return nil, nil, fmt.Errorf("❌ Error al cambiar la salida de audio: %w", err)
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: fmt.Sprintf("🔊 Salida de audio cambiada a %s", device.Name)},
+		},
+	}, nil, nil
+}
+
+func HandleSetAudioInput(ctx context.Context, req *mcp.CallToolRequest, input SetAudioDeviceInput) (*mcp.CallToolResult, any, error) {
+	device, err := setDefaultAudioDevice(audioInput, input.Device)
+	if err != nil {
+		return nil, nil, fmt.Errorf("❌ Error al cambiar la entrada de audio: %w", err)
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: fmt.Sprintf("🎙️ Entrada de audio cambiada a %s", device.Name)},
+		},
+	}, nil, nil
+}
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// audioServers son los procesos que abren el micrófono en nombre de las
+// aplicaciones; no se listan como responsables del uso
+var audioServers = map[string]bool{"pipewire": true, "pulseaudio": true, "wireplumber": true}
+
+// AVInUseOutput indica si el micrófono y la cámara están en uso. Un estado
+// null significa que en esta plataforma no se puede saber.
+type AVInUseOutput struct {
+	Microphone          *bool    `json:"microphone_in_use" jsonschema:"Algún proceso está usando el micrófono (null si no se puede saber)"`
+	Camera              *bool    `json:"camera_in_use" jsonschema:"Algún proceso está usando la cámara (null si no se puede saber)"`
+	MicrophoneProcesses []string `json:"microphone_processes,omitempty" jsonschema:"Procesos que usan el micrófono, cuando se pueden determinar"`
+	CameraProcesses     []string `json:"camera_processes,omitempty" jsonschema:"Procesos que usan la cámara, cuando se pueden determinar"`
+}
+
+// avInUse comprueba si el micrófono o la cámara están en uso
+func avInUse() (AVInUseOutput, error) {
+	switch currentPlatform() {
+	case platformWindows, platformWSL:
+		return avInUseWindows()
+	case platformMac:
+		return avInUseMac()
+	}
+	return avInUseLinux(), nil
+}
+
+// avInUseLinux mira los subdispositivos de captura ALSA en ejecución y los
+// flujos de grabación de PulseAudio/PipeWire para el micrófono, y quién
+// tiene abierto /dev/video* para la cámara
+func avInUseLinux() AVInUseOutput {
+	var mic []string
+	micInUse := false
+
+	// "state: RUNNING" y "owner_pid : 1234" en /proc/asound/card0/pcm0c/sub0/status
+	statuses, _ := filepath.Glob("/proc/asound/card*/pcm*c/sub*/status")
+	for _, path := range statuses {
+		data, err := os.ReadFile(path)
+		if err != nil || !strings.Contains(string(data), "state: RUNNING") {
+			continue
+		}
+		micInUse = true
+		for _, line := range strings.Split(string(data), "\n") {
+			if key, value, ok := strings.Cut(line, ":"); ok && strings.TrimSpace(key) == "owner_pid" {
+				if name := processName(strings.TrimSpace(value)); name != "" && !audioServers[name] {
+					mic = append(mic, name)
+				}
+			}
+		}
+	}
+	if commandExists("pactl")() {
+		if output, err := exec.Command("pactl", "list", "source-outputs").Output(); err == nil {
+			if streams := parsePactlStreams(string(output)); len(streams) > 0 {
+				micInUse = true
+				mic = append(mic, appNames(streams)...)
+			}
+		}
+	}
+
+	camera := videoDeviceUsers()
+	return AVInUseOutput{
+		Microphone:          &micInUse,
+		Camera:              boolPtr(len(camera) > 0),
+		MicrophoneProcesses: uniqueSorted(mic),
+		CameraProcesses:     camera,
+	}
+}
+
+// videoDeviceUsers devuelve los procesos que tienen abierto algún
+// /dev/video*, con lsof o, si no está, recorriendo /proc/*/fd. En ambos
+// casos solo se ven los procesos del propio usuario salvo que se sea root.
+func videoDeviceUsers() []string {
+	devices, _ := filepath.Glob("/dev/video*")
+	if len(devices) == 0 {
+		return nil
+	}
+	var names []string
+	if commandExists("lsof")() {
+		// -F c escribe una línea "c<comando>" por proceso. lsof sale con
+		// código 1 si algún fichero no está abierto, así que se ignora el error.
+		output, _ := exec.Command("lsof", append([]string{"-F", "c"}, devices...)...).Output()
+		for _, line := range strings.Split(string(output), "\n") {
+			if name, ok := strings.CutPrefix(line, "c"); ok {
+				names = append(names, name)
+			}
+		}
+		return uniqueSorted(names)
+	}
+
+	fds, _ := filepath.Glob("/proc/[0-9]*/fd/*")
+	for _, fd := range fds {
+		if target, err := os.Readlink(fd); err == nil && strings.HasPrefix(target, "/dev/video") {
+			names = append(names, processName(strings.Split(fd, "/")[2]))
+		}
+	}
+	return uniqueSorted(names)
+}
+
+// processName devuelve el nombre del proceso con ese pid según /proc
+func processName(pid string) string {
+	if _, err := strconv.Atoi(pid); err != nil {
+		return ""
+	}
+	data, err := os.ReadFile(filepath.Join("/proc", pid, "comm"))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// avInUseMac detecta la cámara con el estado de streaming que publica su
+// driver en ioreg. macOS no expone si el micrófono está grabando sin usar
+// CoreAudio directamente, así que ese estado queda como desconocido.
+func avInUseMac() (AVInUseOutput, error) {
+	output, err := exec.Command("ioreg", "-l", "-w0").Output()
+	if err != nil {
+		return AVInUseOutput{}, fmt.Errorf("error al ejecutar ioreg: %w", err)
+	}
+	camera := false
+	scanner := bufio.NewScanner(strings.NewReader(string(output)))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		// "FrontCameraStreaming" = Yes (Apple Silicon) o "FrontCameraActive" = Yes
+		if strings.Contains(line, `CameraStreaming" = Yes`) || strings.Contains(line, `CameraActive" = Yes`) {
+			camera = true
+			break
+		}
+	}
+	return AVInUseOutput{Camera: &camera}, nil
+}
+
+// avInUseWindows consulta el registro del gestor de privacidad: una
+// aplicación está usando el dispositivo mientras su LastUsedTimeStop es 0
+func avInUseWindows() (AVInUseOutput, error) {
+	script := `foreach ($cap in 'microphone','webcam') {
+  $root = "HKCU:\Software\Microsoft\Windows\CurrentVersion\CapabilityAccessManager\ConsentStore\$cap"
+  Get-ChildItem $root -Recurse -ErrorAction SilentlyContinue | ForEach-Object {
+    $p = Get-ItemProperty $_.PSPath
+    if ($p.LastUsedTimeStart -and $p.LastUsedTimeStop -eq 0) { "$cap|$($_.PSChildName)" }
+  }
+}`
+	output, err := runPowerShell(script)
+	if err != nil {
+		return AVInUseOutput{}, fmt.Errorf("error al consultar el registro: %w", err)
+	}
+	var mic, camera []string
+	for _, line := range strings.Split(strings.ReplaceAll(output, "\r\n", "\n"), "\n") {
+		capability, key, ok := strings.Cut(strings.TrimSpace(line), "|")
+		if !ok {
+			continue
+		}
+		// Las aplicaciones de escritorio usan la ruta con '#' como separador
+		// (C:#Program Files#Zoom#bin#Zoom.exe); las de la Store, su nombre de paquete
+		name := key
+		if i := strings.LastIndex(key, "#"); i >= 0 {
+			name = key[i+1:]
+		}
+		if capability == "microphone" {
+			mic = append(mic, name)
+		} else {
+			camera = append(camera, name)
+		}
+	}
+	return AVInUseOutput{
+		Microphone:          boolPtr(len(mic) > 0),
+		Camera:              boolPtr(len(camera) > 0),
+		MicrophoneProcesses: uniqueSorted(mic),
+		CameraProcesses:     uniqueSorted(camera),
+	}, nil
+}
+
+// boolPtr devuelve un puntero a b, para los campos que admiten null
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+// uniqueSorted elimina duplicados y ordena
+func uniqueSorted(values []string) []string {
+	seen := make(map[string]bool)
+	var result []string
+	for _, v := range values {
+		if v != "" && !seen[v] {
+			seen[v] = true
+			result = append(result, v)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// describeAVUse describe el estado de un dispositivo con sus procesos
+func describeAVUse(label string, inUse *bool, processes []string) string {
+	switch {
+	case inUse == nil:
+		return label + ": no se puede saber en este sistema"
+	case !*inUse:
+		return label + ": libre"
+	case len(processes) > 0:
+		return fmt.Sprintf("%s: en uso (%s)", label, strings.Join(processes, ", "))
+	}
+	return label + ": en uso"
+}
+
+func HandleAVInUse(ctx context.Context, req *mcp.CallToolRequest, input struct{}) (*mcp.CallToolResult, AVInUseOutput, error) {
+	state, err := avInUse()
+	if err != nil {
+		return nil, AVInUseOutput{}, fmt.Errorf("❌ Error al comprobar el micrófono y la cámara: %w", err)
+	}
+	summary := "🟢 Ni el micrófono ni la cámara están en uso"
+	if (state.Microphone != nil && *state.Microphone) || (state.Camera != nil && *state.Camera) {
+		summary = "🔴 El micrófono o la cámara están en uso (posible reunión en curso)"
+	} else if state.Microphone == nil || state.Camera == nil {
+		summary = "🟡 No se detecta uso, aunque no se pudo comprobar todo"
+	}
+	result := strings.Join([]string{
+		summary,
+		describeAVUse("🎙️ Micrófono", state.Microphone, state.MicrophoneProcesses),
+		describeAVUse("📷 Cámara", state.Camera, state.CameraProcesses),
+	}, "\n")
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: result},
+		},
+	}, state, nil
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// backlightDir es donde el kernel de Linux expone los controladores de retroiluminación
+const backlightDir = "/sys/class/backlight"
+
+// backlightDevice representa un dispositivo de /sys/class/backlight
+type backlightDevice struct {
+	Name          string
+	Brightness    int
+	MaxBrightness int
+}
+
+// Percent devuelve el brillo del dispositivo en porcentaje (0-100)
+func (d backlightDevice) Percent() int {
+	if d.MaxBrightness <= 0 {
+		return 0
+	}
+	return (d.Brightness*100 + d.MaxBrightness/2) / d.MaxBrightness
+}
+
+// readSysfsInt lee un fichero de sysfs que contiene un único entero
+func readSysfsInt(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(strings.TrimSpace(string(data)))
+}
+
+// listBacklights enumera los dispositivos de retroiluminación disponibles.
+// Devuelve una lista vacía (sin error) si el equipo no tiene ninguno.
+func listBacklights() ([]backlightDevice, error) {
+	entries, err := os.ReadDir(backlightDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var devices []backlightDevice
+	for _, entry := range entries {
+		dir := filepath.Join(backlightDir, entry.Name())
+		brightness, err := readSysfsInt(filepath.Join(dir, "brightness"))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", entry.Name(), err)
+		}
+		maxBrightness, err := readSysfsInt(filepath.Join(dir, "max_brightness"))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", entry.Name(), err)
+		}
+		devices = append(devices, backlightDevice{
+			Name:          entry.Name(),
+			Brightness:    brightness,
+			MaxBrightness: maxBrightness,
+		})
+	}
+	return devices, nil
+}
+
+// preferredBacklight elige el dispositivo con mayor max_brightness, que suele
+// ser el controlador nativo del panel (p. ej. intel_backlight frente a acpi_video0)
+func preferredBacklight(devices []backlightDevice) backlightDevice {
+	best := devices[0]
+	for _, d := range devices[1:] {
+		if d.MaxBrightness > best.MaxBrightness {
+			best = d
+		}
+	}
+	return best
+}
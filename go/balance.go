@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// balanceLevels reparte el volumen base entre los canales izquierdo y
+// derecho: el lado hacia el que se desplaza el balance queda al volumen base
+// y el otro se atenúa en proporción (-100 silencia el derecho, 100 el izquierdo)
+func balanceLevels(base, balance int) (left, right int) {
+	left, right = base, base
+	if balance > 0 {
+		left = base * (100 - balance) / 100
+	} else if balance < 0 {
+		right = base * (100 + balance) / 100
+	}
+	return left, right
+}
+
+// channelLevels lee el volumen (0-100) de cada canal de la salida por defecto
+func channelLevels() ([]int, error) {
+	switch currentPlatform() {
+	case platformWindows, platformWSL:
+		output, err := runPowerShell(psCoreAudio + "0..([Audio]::Channels - 1) | ForEach-Object { [Math]::Round([Audio]::GetChannel($_) * 100) }")
+		if err != nil {
+			return nil, fmt.Errorf("error al consultar CoreAudio: %w", err)
+		}
+		var levels []int
+		for _, field := range strings.Fields(output) {
+			level, err := strconv.Atoi(field)
+			if err != nil {
+				return nil, fmt.Errorf("salida inesperada de CoreAudio: %q", output)
+			}
+			levels = append(levels, level)
+		}
+		return levels, nil
+	case platformMac:
+		return nil, errors.New("macOS no permite ajustar el balance desde la línea de comandos (ni osascript ni SwitchAudioSource lo exponen); usa Ajustes del Sistema → Sonido")
+	}
+
+	if !commandExists("pactl")() {
+		return nil, errors.New("pactl no está instalado (hace falta PulseAudio o pipewire-pulse)")
+	}
+	// "Volume: front-left: 32768 /  50% / -18,06 dB,   front-right: 32768 /  50% / -18,06 dB"
+	output, err := exec.Command("pactl", "get-sink-volume", "@DEFAULT_SINK@").Output()
+	if err != nil {
+		return nil, fmt.Errorf("error al consultar pactl: %w", err)
+	}
+	line, _, _ := strings.Cut(string(output), "\n")
+	var levels []int
+	for _, match := range percentPattern.FindAllStringSubmatch(line, -1) {
+		level, _ := strconv.Atoi(match[1])
+		levels = append(levels, level)
+	}
+	if len(levels) == 0 {
+		return nil, fmt.Errorf("no se pudo interpretar la salida de pactl: %q", string(output))
+	}
+	return levels, nil
+}
+
+// setAudioBalance desplaza el balance de la salida por defecto (-100 todo a
+// la izquierda, 100 todo a la derecha) sin cambiar el volumen del canal más
+// alto, que se toma como volumen base
+func setAudioBalance(balance int) (string, error) {
+	if balance < -100 || balance > 100 {
+		return "", fmt.Errorf("balance %d fuera de rango (-100 a 100)", balance)
+	}
+	levels, err := channelLevels()
+	if err != nil {
+		return "", err
+	}
+	if len(levels) != 2 {
+		return "", fmt.Errorf("la salida tiene %d canales: el balance solo se puede ajustar en salidas estéreo", len(levels))
+	}
+	base := max(levels[0], levels[1])
+	left, right := balanceLevels(base, balance)
+
+	switch currentPlatform() {
+	case platformWindows, platformWSL:
+		script := psCoreAudio + fmt.Sprintf("[Audio]::SetChannel(0, %.2f); [Audio]::SetChannel(1, %.2f)", float64(left)/100, float64(right)/100)
+		_, err = runPowerShell(script)
+	default:
+		err = exec.Command("pactl", "set-sink-volume", "@DEFAULT_SINK@", fmt.Sprintf("%d%%", left), fmt.Sprintf("%d%%", right)).Run()
+	}
+	if err != nil {
+		return "", fmt.Errorf("error al ajustar el balance: %w", err)
+	}
+
+	// Confirmar los valores finales leyéndolos de nuevo cuando sea posible
+	if final, err := channelLevels(); err == nil && len(final) == 2 {
+		left, right = final[0], final[1]
+	}
+	var position string
+	switch {
+	case balance < 0:
+		position = fmt.Sprintf("%d a la izquierda", -balance)
+	case balance > 0:
+		position = fmt.Sprintf("%d a la derecha", balance)
+	default:
+		position = "centrado"
+	}
+	return fmt.Sprintf("🎚️ Balance %s: izquierda %d%%, derecha %d%%", position, left, right), nil
+}
+
+type SetAudioBalanceInput struct {
+	Balance int  `json:"balance,omitempty" jsonschema:"Balance de -100 (todo a la izquierda) a 100 (todo a la derecha); 0 es centrado"`
+	Reset   bool `json:"reset,omitempty" jsonschema:"Si es true centra el balance e ignora balance"`
+}
+
+func HandleSetAudioBalance(ctx context.Context, req *mcp.CallToolRequest, input SetAudioBalanceInput) (*mcp.CallToolResult, any, error) {
+	balance := input.Balance
+	if input.Reset {
+		balance = 0
+	}
+	result, err := setAudioBalance(balance)
+	if err != nil {
+		return nil, nil, fmt.Errorf("❌ Error al ajustar el balance: %w", err)
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: result},
+		},
+	}, nil, nil
+}
@@ -0,0 +1,266 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// batteryWatchThreshold es el porcentaje de batería por debajo del cual se
+// avisa al cliente (configurable con --battery-watch; 0 lo desactiva) y
+// batteryWatchSound si además se reproduce el sonido "alert"
+// (--battery-watch-sound)
+var (
+	batteryWatchThreshold int
+	batteryWatchSound     bool
+)
+
+// batteryWatchInterval es cada cuánto se consulta la batería
+const batteryWatchInterval = time.Minute
+
+// batteryStatus es el nivel de la batería y si el equipo está conectado a la
+// corriente
+type batteryStatus struct {
+	Percent int
+	OnAC    bool
+}
+
+// readBattery consulta el nivel de la batería del sistema
+func readBattery() (batteryStatus, error) {
+	switch currentPlatform() {
+	case platformWindows, platformWSL:
+		return readBatteryWindows()
+	case platformMac:
+		return readBatteryMac()
+	}
+	return readBatteryLinux()
+}
+
+// readBatteryLinux promedia la capacidad de las baterías del sistema en
+// /sys/class/power_supply, sin contar las de los periféricos (scope Device)
+func readBatteryLinux() (batteryStatus, error) {
+	supplies, _ := filepath.Glob("/sys/class/power_supply/*")
+	var total, count int
+	for _, supply := range supplies {
+		kind, _ := os.ReadFile(filepath.Join(supply, "type"))
+		scope, _ := os.ReadFile(filepath.Join(supply, "scope"))
+		if strings.TrimSpace(string(kind)) != "Battery" || strings.TrimSpace(string(scope)) == "Device" {
+			continue
+		}
+		if capacity, err := readSysfsInt(filepath.Join(supply, "capacity")); err == nil {
+			total += capacity
+			count++
+		}
+	}
+	if count == 0 {
+		return batteryStatus{}, errors.New("no se encontró ninguna batería en /sys/class/power_supply")
+	}
+	onAC, err := onACLinux()
+	if err != nil {
+		return batteryStatus{}, err
+	}
+	return batteryStatus{Percent: total / count, OnAC: onAC}, nil
+}
+
+// macBatteryRegexp reconoce el nivel en `pmset -g batt`:
+// " -InternalBattery-0 (id=1234)	85%; discharging; 3:12 remaining"
+var macBatteryRegexp = regexp.MustCompile(`(\d+)%;`)
+
+func readBatteryMac() (batteryStatus, error) {
+	output, err := exec.Command("pmset", "-g", "batt").Output()
+	if err != nil {
+		return batteryStatus{}, fmt.Errorf("error al ejecutar pmset -g batt: %w", err)
+	}
+	m := macBatteryRegexp.FindStringSubmatch(string(output))
+	if m == nil {
+		return batteryStatus{}, errors.New("este Mac no tiene batería")
+	}
+	percent, _ := strconv.Atoi(m[1])
+	return batteryStatus{Percent: percent, OnAC: strings.Contains(string(output), "'AC Power'")}, nil
+}
+
+func readBatteryWindows() (batteryStatus, error) {
+	output, err := runPowerShell(`Add-Type -AssemblyName System.Windows.Forms
+$s = [System.Windows.Forms.SystemInformation]::PowerStatus
+"$([int]($s.BatteryLifePercent * 100))|$($s.PowerLineStatus)|$($s.BatteryChargeStatus)"`)
+	if err != nil {
+		return batteryStatus{}, fmt.Errorf("error al consultar la batería: %w", err)
+	}
+	fields := strings.Split(output, "|")
+	if len(fields) != 3 {
+		return batteryStatus{}, fmt.Errorf("respuesta de PowerShell no válida: %q", output)
+	}
+	if strings.Contains(fields[2], "NoSystemBattery") || strings.Contains(fields[2], "Unknown") {
+		return batteryStatus{}, errors.New("el equipo no tiene batería")
+	}
+	percent, _ := strconv.Atoi(fields[0])
+	return batteryStatus{Percent: percent, OnAC: fields[1] == "Online"}, nil
+}
+
+// batteryWatchMu protege el estado del vigilante de batería, que consulta
+// get_battery_watch
+var (
+	batteryWatchMu       sync.Mutex
+	batteryWatchLast     time.Time
+	batteryWatchStatus   *batteryStatus
+	batteryWatchErr      error
+	batteryWatchNotified bool
+)
+
+// startBatteryWatch vigila la batería en segundo plano si --battery-watch
+// está activo. Avisa una sola vez por descarga, cuando el nivel baja del
+// umbral sin estar conectado a la corriente, y vuelve a armarse al
+// conectarlo. La primera consulta se hace al cabo de un intervalo, cuando el
+// cliente ya ha podido conectarse. La función devuelta detiene el vigilante
+// y espera a que termine.
+func startBatteryWatch(ctx context.Context, server *mcp.Server) func() {
+	if batteryWatchThreshold == 0 {
+		return func() {}
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(batteryWatchInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			checkBattery(ctx, server)
+		}
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+// checkBattery consulta la batería una vez y avisa si acaba de bajar del umbral
+func checkBattery(ctx context.Context, server *mcp.Server) {
+	status, err := readBattery()
+
+	batteryWatchMu.Lock()
+	previousErr := batteryWatchErr
+	batteryWatchLast, batteryWatchErr = time.Now(), err
+	notify := false
+	if err == nil {
+		batteryWatchStatus = &status
+		switch {
+		case status.OnAC:
+			batteryWatchNotified = false
+		case status.Percent <= batteryWatchThreshold && !batteryWatchNotified:
+			batteryWatchNotified, notify = true, true
+		}
+	}
+	batteryWatchMu.Unlock()
+
+	if err != nil {
+		// Solo se registra cuando cambia, para no repetirlo cada minuto
+		if previousErr == nil || previousErr.Error() != err.Error() {
+			log.Printf("⚠️ Vigilancia de batería: %v", err)
+		}
+		return
+	}
+	if !notify {
+		return
+	}
+
+	message := fmt.Sprintf("🪫 Batería baja: %d%% (umbral %d%%). Conecta el equipo a la corriente", status.Percent, batteryWatchThreshold)
+	log.Println(message)
+	for session := range server.Sessions() {
+		err := session.Log(ctx, &mcp.LoggingMessageParams{
+			Level:  "warning",
+			Logger: "battery-watch",
+			Data:   map[string]any{"message": message, "percent": status.Percent, "threshold": batteryWatchThreshold},
+		})
+		if err != nil {
+			log.Printf("⚠️ No se pudo enviar el aviso de batería: %v", err)
+		}
+	}
+	if batteryWatchSound {
+		if result := playSystemSound(ctx, "alert", soundOptions{}); strings.HasPrefix(result, "❌") {
+			log.Println(result)
+		}
+	}
+}
+
+type BatteryWatchOutput struct {
+	Enabled         bool   `json:"enabled" jsonschema:"La vigilancia de batería está activa (--battery-watch)"`
+	Threshold       int    `json:"threshold,omitempty" jsonschema:"Porcentaje por debajo del cual se avisa"`
+	Sound           bool   `json:"sound,omitempty" jsonschema:"Se reproduce el sonido alert al avisar (--battery-watch-sound)"`
+	IntervalSeconds int    `json:"interval_seconds,omitempty" jsonschema:"Segundos entre consultas"`
+	LastCheck       string `json:"last_check,omitempty" jsonschema:"Hora de la última consulta (RFC 3339)"`
+	BatteryPercent  *int   `json:"battery_percent,omitempty" jsonschema:"Nivel de batería en la última consulta"`
+	OnAC            *bool  `json:"on_ac,omitempty" jsonschema:"Conectado a la corriente en la última consulta"`
+	Notified        bool   `json:"notified,omitempty" jsonschema:"Ya se avisó en esta descarga; se rearma al conectar la corriente"`
+	LastError       string `json:"last_error,omitempty" jsonschema:"Error de la última consulta"`
+}
+
+func HandleGetBatteryWatch(ctx context.Context, req *mcp.CallToolRequest, input struct{}) (*mcp.CallToolResult, BatteryWatchOutput, error) {
+	if batteryWatchThreshold == 0 {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: "🔋 La vigilancia de batería está desactivada (se activa arrancando el servidor con --battery-watch=<porcentaje>)"},
+			},
+		}, BatteryWatchOutput{}, nil
+	}
+
+	batteryWatchMu.Lock()
+	out := BatteryWatchOutput{
+		Enabled:         true,
+		Threshold:       batteryWatchThreshold,
+		Sound:           batteryWatchSound,
+		IntervalSeconds: int(batteryWatchInterval / time.Second),
+		Notified:        batteryWatchNotified,
+	}
+	if !batteryWatchLast.IsZero() {
+		out.LastCheck = batteryWatchLast.Format(time.RFC3339)
+	}
+	if batteryWatchStatus != nil {
+		percent, onAC := batteryWatchStatus.Percent, batteryWatchStatus.OnAC
+		out.BatteryPercent, out.OnAC = &percent, &onAC
+	}
+	if batteryWatchErr != nil {
+		out.LastError = batteryWatchErr.Error()
+	}
+	batteryWatchMu.Unlock()
+
+	result := fmt.Sprintf("🔋 Vigilancia de batería activa: aviso por debajo del %d%%, cada %v", out.Threshold, batteryWatchInterval)
+	if out.Sound {
+		result += " (con sonido)"
+	}
+	switch {
+	case out.LastCheck == "":
+		result += "\n⏳ Aún no se ha consultado la batería"
+	case out.LastError != "":
+		result += "\n⚠️ Última consulta: " + out.LastError
+	case out.BatteryPercent != nil:
+		source := "batería"
+		if *out.OnAC {
+			source = "corriente"
+		}
+		result += fmt.Sprintf("\n🔋 Última consulta: %d%% (%s)", *out.BatteryPercent, source)
+	}
+	if out.Notified {
+		result += "\n🪫 Ya se avisó en esta descarga"
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: result},
+		},
+	}, out, nil
+}
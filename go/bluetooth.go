@@ -0,0 +1,560 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// errNoBluetoothAdapter indica que el equipo no tiene adaptador Bluetooth, para
+// no confundirlo con un adaptador apagado o una herramienta que falta
+var errNoBluetoothAdapter = errors.New("el equipo no tiene ningún adaptador Bluetooth")
+
+// blueutilHint explica cómo instalar blueutil, necesario en macOS para
+// encender y apagar el Bluetooth
+const blueutilHint = "instala blueutil (brew install blueutil)"
+
+// bluetoothState es el estado del Bluetooth leído del sistema
+type bluetoothState struct {
+	Enabled     bool
+	HardBlocked bool   // interruptor físico, tecla de modo avión o firmware
+	Adapter     string // hci0, dirección del controlador o nombre del adaptador
+	Backend     string
+
+	// Solo en Linux: bloqueo por software de rfkill, que hay que quitar
+	// antes de encender con bluetoothctl
+	softBlocked bool
+	// Solo en Windows: estado de la radio ("On", "Off", "Disabled"; vacío
+	// si la API de radios no está disponible) y del adaptador
+	radio      string
+	instanceID string
+	deviceOK   bool
+}
+
+// readBluetoothState obtiene el estado del Bluetooth
+func readBluetoothState() (bluetoothState, error) {
+	switch currentPlatform() {
+	case platformWindows, platformWSL:
+		return readBluetoothWindows()
+	case platformMac:
+		return readBluetoothMac()
+	}
+	return readBluetoothLinux()
+}
+
+// writeBluetoothState enciende o apaga el Bluetooth
+func writeBluetoothState(on bool, current bluetoothState) error {
+	switch currentPlatform() {
+	case platformWindows, platformWSL:
+		return writeBluetoothWindows(on, current)
+	case platformMac:
+		if !commandExists("blueutil")() {
+			return errors.New("macOS no tiene una orden para encender o apagar el Bluetooth: " + blueutilHint)
+		}
+		power := "0"
+		if on {
+			power = "1"
+		}
+		_, err := runRadioCommand("blueutil", "--power", power)
+		return err
+	}
+	return writeBluetoothLinux(on, current)
+}
+
+// bluezPoweredRegexp reconoce "Powered: yes" de `bluetoothctl show`
+var bluezPoweredRegexp = regexp.MustCompile(`(?m)^\s*Powered:\s*(yes|no)\b`)
+
+// readBluetoothLinux usa bluetoothctl si bluetoothd está en marcha y si no
+// los interruptores rfkill de /sys
+func readBluetoothLinux() (bluetoothState, error) {
+	controllers, _ := filepath.Glob("/sys/class/bluetooth/hci*")
+	soft, hard, found := linuxRfkill("bluetooth")
+	if len(controllers) == 0 && !found {
+		return bluetoothState{}, errNoBluetoothAdapter
+	}
+	state := bluetoothState{HardBlocked: hard, softBlocked: soft}
+	if len(controllers) > 0 {
+		state.Adapter = filepath.Base(controllers[0])
+	}
+	if commandExists("bluetoothctl")() {
+		if output, err := runRadioCommand("bluetoothctl", "show"); err == nil {
+			if m := bluezPoweredRegexp.FindStringSubmatch(output); m != nil {
+				state.Enabled = m[1] == "yes" && !soft && !hard
+				state.Backend = "bluetoothctl"
+				return state, nil
+			}
+		}
+	}
+	if !found {
+		return bluetoothState{}, errors.New("bluetoothd no está en marcha (o falta bluetoothctl) y el adaptador no tiene interruptor rfkill")
+	}
+	state.Enabled = !soft && !hard
+	state.Backend = "rfkill"
+	return state, nil
+}
+
+// writeBluetoothLinux quita o pone el bloqueo de rfkill y enciende o apaga el
+// controlador con bluetoothctl. Con el bloqueo puesto BlueZ no puede
+// encenderlo (org.bluez.Error.Blocked).
+func writeBluetoothLinux(on bool, current bluetoothState) error {
+	rfkill, hasRfkill := sbinCommand("rfkill")
+	if on && current.softBlocked {
+		if !hasRfkill {
+			return errors.New("el Bluetooth está bloqueado con rfkill y no se encontró la orden rfkill")
+		}
+		if _, err := runRadioCommand(rfkill, "unblock", "bluetooth"); err != nil {
+			return err
+		}
+	}
+	if current.Backend == "bluetoothctl" {
+		power := "off"
+		if on {
+			power = "on"
+		}
+		_, err := runRadioCommand("bluetoothctl", "power", power)
+		return err
+	}
+	if on {
+		return nil
+	}
+	if !hasRfkill {
+		return errors.New("no se encontró bluetoothctl (BlueZ) ni rfkill")
+	}
+	_, err := runRadioCommand(rfkill, "block", "bluetooth")
+	return err
+}
+
+// macBluetoothProfile es la parte de `system_profiler SPBluetoothDataType
+// -json` que se usa (macOS 12 o posterior). Los dispositivos vienen como
+// listas de objetos de una sola clave, el nombre.
+type macBluetoothProfile struct {
+	SPBluetoothDataType []struct {
+		Controller *struct {
+			Address string `json:"controller_address"`
+			State   string `json:"controller_state"`
+		} `json:"controller_properties"`
+		Connected    []map[string]macBluetoothProfileDevice `json:"device_connected"`
+		NotConnected []map[string]macBluetoothProfileDevice `json:"device_not_connected"`
+	}
+}
+
+type macBluetoothProfileDevice struct {
+	Address string `json:"device_address"`
+}
+
+// readMacBluetoothProfile consulta system_profiler, que no necesita blueutil
+func readMacBluetoothProfile() (macBluetoothProfile, error) {
+	output, err := exec.Command("system_profiler", "SPBluetoothDataType", "-json").Output()
+	if err != nil {
+		return macBluetoothProfile{}, fmt.Errorf("error al consultar system_profiler: %w", err)
+	}
+	var profile macBluetoothProfile
+	if err := json.Unmarshal(output, &profile); err != nil {
+		return macBluetoothProfile{}, fmt.Errorf("respuesta inesperada de system_profiler: %w", err)
+	}
+	if len(profile.SPBluetoothDataType) == 0 || profile.SPBluetoothDataType[0].Controller == nil {
+		return macBluetoothProfile{}, errNoBluetoothAdapter
+	}
+	return profile, nil
+}
+
+// readBluetoothMac usa blueutil si está instalado y si no system_profiler
+func readBluetoothMac() (bluetoothState, error) {
+	profile, err := readMacBluetoothProfile()
+	if err != nil {
+		return bluetoothState{}, err
+	}
+	controller := profile.SPBluetoothDataType[0].Controller
+	state := bluetoothState{Adapter: controller.Address, Enabled: controller.State == "attrib_on", Backend: "system_profiler"}
+	if commandExists("blueutil")() {
+		if output, err := runRadioCommand("blueutil", "--power"); err == nil {
+			state.Enabled = output == "1"
+			state.Backend = "blueutil"
+		}
+	}
+	return state, nil
+}
+
+// windowsBluetoothStateScript devuelve en JSON el adaptador Bluetooth (el
+// dispositivo PnP de la clase Bluetooth que no es un servicio enumerado por
+// BTH ni un dispositivo de software) y el estado de su radio
+const windowsBluetoothStateScript = windowsRadioPrelude + `
+$btRadio = $radios | Where-Object { $_.Kind -eq 'Bluetooth' } | Select-Object -First 1
+$radio = ''
+if ($btRadio) { $radio = [string]$btRadio.State }
+$adapter = Get-PnpDevice -Class Bluetooth -PresentOnly -ErrorAction SilentlyContinue | Where-Object { $_.InstanceId -notmatch '^(BTH|SWD)' } | Select-Object -First 1
+if (-not $adapter -and -not $radio) { return }
+@{ name = [string]$adapter.FriendlyName; instance_id = [string]$adapter.InstanceId; ok = [bool]($adapter -and $adapter.Status -eq 'OK'); radio = $radio } | ConvertTo-Json -Compress
+`
+
+func readBluetoothWindows() (bluetoothState, error) {
+	output, err := runPowerShell(windowsBluetoothStateScript)
+	if err != nil {
+		return bluetoothState{}, fmt.Errorf("error al consultar el Bluetooth: %w", err)
+	}
+	if output == "" {
+		return bluetoothState{}, errNoBluetoothAdapter
+	}
+	var result struct {
+		Name       string `json:"name"`
+		InstanceID string `json:"instance_id"`
+		OK         bool   `json:"ok"`
+		Radio      string `json:"radio"`
+	}
+	if err := json.Unmarshal([]byte(output), &result); err != nil {
+		return bluetoothState{}, fmt.Errorf("respuesta inesperada de PowerShell: %w", err)
+	}
+	state := bluetoothState{Adapter: result.Name, Backend: "radio", radio: result.Radio, instanceID: result.InstanceID, deviceOK: result.OK}
+	switch {
+	case result.Radio == "":
+		// Sin API de radios (o con el adaptador deshabilitado, que la oculta)
+		state.Backend = "pnp"
+		state.Enabled = result.OK
+	default:
+		state.Enabled = result.Radio == "On" && (result.InstanceID == "" || result.OK)
+		state.HardBlocked = result.Radio == "Disabled"
+	}
+	return state, nil
+}
+
+// writeBluetoothWindows usa la API de radios, que no necesita privilegios.
+// Si el adaptador está deshabilitado (o la API no está disponible) hay que
+// habilitarlo o deshabilitarlo con los cmdlets PnP, que sí los necesitan.
+func writeBluetoothWindows(on bool, current bluetoothState) error {
+	if on && current.instanceID != "" && !current.deviceOK {
+		if err := setWindowsBluetoothDevice(current.instanceID, true); err != nil {
+			return err
+		}
+		// Al habilitar el adaptador su radio vuelve a aparecer, quizá apagada
+		if after, err := readBluetoothWindows(); err == nil && after.radio != "" && after.radio != "On" {
+			return setWindowsRadio("Bluetooth", "Bluetooth", true)
+		}
+		return nil
+	}
+	if current.radio != "" {
+		return setWindowsRadio("Bluetooth", "Bluetooth", on)
+	}
+	if current.instanceID == "" {
+		return errors.New("no se encontró el adaptador Bluetooth en el Administrador de dispositivos")
+	}
+	return setWindowsBluetoothDevice(current.instanceID, on)
+}
+
+// setWindowsBluetoothDevice habilita o deshabilita el adaptador con
+// Enable-PnpDevice o Disable-PnpDevice
+func setWindowsBluetoothDevice(instanceID string, enable bool) error {
+	cmdlet := "Disable-PnpDevice"
+	if enable {
+		cmdlet = "Enable-PnpDevice"
+	}
+	_, err := runPowerShell(fmt.Sprintf("%s -InstanceId '%s' -Confirm:$false -ErrorAction Stop", cmdlet, strings.ReplaceAll(instanceID, "'", "''")))
+	if err != nil {
+		if permissionDeniedRegexp.MatchString(err.Error()) {
+			return fmt.Errorf("%s: %w", cmdlet, errRadioRequiresAdmin)
+		}
+		return fmt.Errorf("%s no pudo cambiar el adaptador (requiere administrador): %w", cmdlet, err)
+	}
+	return nil
+}
+
+type SetBluetoothInput struct {
+	State string `json:"state" jsonschema:"Estado del Bluetooth: on (encender), off (apagar) o toggle (alternar)"`
+}
+
+type SetBluetoothOutput struct {
+	Enabled     bool   `json:"enabled" jsonschema:"El Bluetooth está encendido, según el estado leído tras el cambio"`
+	Previous    bool   `json:"previous" jsonschema:"Estado anterior del Bluetooth"`
+	Changed     bool   `json:"changed" jsonschema:"Se cambió el estado (false si ya estaba como se pedía)"`
+	Adapter     string `json:"adapter,omitempty" jsonschema:"Adaptador Bluetooth"`
+	Method      string `json:"method" jsonschema:"Método usado: bluetoothctl, rfkill, blueutil, system_profiler (solo lectura), radio (API de radios de Windows) o pnp"`
+	HardBlocked bool   `json:"hard_blocked,omitempty" jsonschema:"El Bluetooth está bloqueado por un interruptor físico o el modo avión del equipo"`
+	Note        string `json:"note,omitempty" jsonschema:"Aclaración sobre el resultado"`
+}
+
+// setBluetooth enciende ("on"), apaga ("off") o alterna ("toggle") el
+// Bluetooth y lee el estado resultante, que es el que se devuelve
+func setBluetooth(state string) (SetBluetoothOutput, error) {
+	state = strings.ToLower(strings.TrimSpace(state))
+	if state != "on" && state != "off" && state != "toggle" {
+		return SetBluetoothOutput{}, fmt.Errorf("estado no válido %q: usa on, off o toggle", state)
+	}
+	current, err := readBluetoothState()
+	if err != nil {
+		if errors.Is(err, errNoBluetoothAdapter) {
+			return SetBluetoothOutput{}, err
+		}
+		return SetBluetoothOutput{}, fmt.Errorf("no se pudo leer el estado del Bluetooth: %w", err)
+	}
+	want := state == "on"
+	if state == "toggle" {
+		want = !current.Enabled
+	}
+	out := SetBluetoothOutput{Enabled: current.Enabled, Previous: current.Enabled, Adapter: current.Adapter, Method: current.Backend, HardBlocked: current.HardBlocked}
+	if want && current.HardBlocked {
+		return SetBluetoothOutput{}, errors.New("el Bluetooth está bloqueado por hardware (interruptor físico o tecla de modo avión) y no se puede encender por software")
+	}
+	if current.Enabled == want {
+		return out, nil
+	}
+
+	if err := writeBluetoothState(want, current); err != nil {
+		if errors.Is(err, errRadioRequiresAdmin) {
+			return SetBluetoothOutput{}, fmt.Errorf("no se pudo dejar el Bluetooth %s (%s): %w. Ejecuta el servidor como administrador", onOffBluetooth(want), current.Backend, err)
+		}
+		return SetBluetoothOutput{}, fmt.Errorf("error al cambiar el Bluetooth (%s): %w", current.Backend, err)
+	}
+
+	final := waitForRadioState(want, current, readBluetoothState, func(s bluetoothState) bool { return s.Enabled })
+	if final.Enabled != want {
+		return SetBluetoothOutput{}, fmt.Errorf("el sistema aceptó el cambio, pero el Bluetooth sigue %s", onOffBluetooth(final.Enabled))
+	}
+	out.Enabled = final.Enabled
+	out.Changed = true
+	out.Method = final.Backend
+	out.HardBlocked = final.HardBlocked
+	if currentPlatform() == platformWSL {
+		out.Note = "Es el Bluetooth del Windows anfitrión"
+	}
+	return out, nil
+}
+
+// onOffBluetooth describe el estado del Bluetooth (o del punto de acceso)
+func onOffBluetooth(on bool) string {
+	if on {
+		return "encendido"
+	}
+	return "apagado"
+}
+
+func HandleSetBluetooth(ctx context.Context, req *mcp.CallToolRequest, input SetBluetoothInput) (*mcp.CallToolResult, SetBluetoothOutput, error) {
+	out, err := setBluetooth(input.State)
+	if err != nil {
+		return nil, SetBluetoothOutput{}, fmt.Errorf("❌ Error al cambiar el Bluetooth: %w", err)
+	}
+
+	line := "🔵 Bluetooth " + onOffBluetooth(out.Enabled)
+	if !out.Changed {
+		line += ": ya estaba así"
+	}
+	lines := []string{line}
+	if out.Note != "" {
+		lines = append(lines, "ℹ️ "+out.Note)
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: strings.Join(lines, "\n")},
+		},
+	}, out, nil
+}
+
+type BluetoothDevice struct {
+	Name      string `json:"name" jsonschema:"Nombre del dispositivo"`
+	Address   string `json:"address" jsonschema:"Dirección Bluetooth (AA:BB:CC:DD:EE:FF)"`
+	Connected bool   `json:"connected" jsonschema:"Está conectado ahora"`
+	Paired    bool   `json:"paired" jsonschema:"Está emparejado con este equipo"`
+}
+
+type ListBluetoothDevicesOutput struct {
+	Powered bool              `json:"powered" jsonschema:"El Bluetooth está encendido (si está apagado ningún dispositivo aparece conectado)"`
+	Devices []BluetoothDevice `json:"devices" jsonschema:"Dispositivos emparejados y los conectados sin emparejar"`
+	Note    string            `json:"note,omitempty" jsonschema:"Aclaración sobre el resultado"`
+}
+
+// normalizeBluetoothAddress pasa la dirección a mayúsculas separada por ':'
+// (blueutil usa '-' y Windows minúsculas)
+func normalizeBluetoothAddress(address string) string {
+	return strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(address), "-", ":"))
+}
+
+// bluezDeviceRegexp reconoce "Device AA:BB:CC:DD:EE:FF Nombre" de
+// `bluetoothctl devices`
+var bluezDeviceRegexp = regexp.MustCompile(`(?m)^Device ([0-9A-Fa-f:]{17}) ?(.*)$`)
+
+// bluezInfoRegexp reconoce las líneas "Clave: valor" de `bluetoothctl info`
+var bluezInfoRegexp = regexp.MustCompile(`(?m)^\s*(Alias|Paired|Connected):\s*(.*)$`)
+
+// listBluetoothLinux recorre los dispositivos que conoce BlueZ y consulta el
+// estado de cada uno con `bluetoothctl info`
+func listBluetoothLinux() ([]BluetoothDevice, error) {
+	if !commandExists("bluetoothctl")() {
+		return nil, errors.New("requiere bluetoothctl (BlueZ)")
+	}
+	output, err := runRadioCommand("bluetoothctl", "devices")
+	if err != nil {
+		return nil, fmt.Errorf("error al consultar bluetoothctl: %w", err)
+	}
+	var devices []BluetoothDevice
+	for _, m := range bluezDeviceRegexp.FindAllStringSubmatch(output, -1) {
+		device := BluetoothDevice{Name: strings.TrimSpace(m[2]), Address: normalizeBluetoothAddress(m[1])}
+		if info, err := runRadioCommand("bluetoothctl", "info", m[1]); err == nil {
+			for _, field := range bluezInfoRegexp.FindAllStringSubmatch(info, -1) {
+				value := strings.TrimSpace(field[2])
+				switch field[1] {
+				case "Alias":
+					device.Name = value
+				case "Paired":
+					device.Paired = value == "yes"
+				case "Connected":
+					device.Connected = value == "yes"
+				}
+			}
+		}
+		if device.Paired || device.Connected {
+			devices = append(devices, device)
+		}
+	}
+	return devices, nil
+}
+
+// listBluetoothMac usa blueutil si está instalado y si no system_profiler,
+// que lista los dispositivos emparejados
+func listBluetoothMac() ([]BluetoothDevice, error) {
+	if commandExists("blueutil")() {
+		var devices []BluetoothDevice
+		for _, filter := range []string{"--paired", "--connected"} {
+			output, err := exec.Command("blueutil", filter, "--format", "json").Output()
+			if err != nil {
+				return nil, fmt.Errorf("error al consultar blueutil: %w", err)
+			}
+			var entries []BluetoothDevice
+			if err := json.Unmarshal(output, &entries); err != nil {
+				return nil, fmt.Errorf("respuesta inesperada de blueutil: %w", err)
+			}
+			devices = append(devices, entries...)
+		}
+		return devices, nil
+	}
+
+	profile, err := readMacBluetoothProfile()
+	if err != nil {
+		return nil, err
+	}
+	var devices []BluetoothDevice
+	for _, list := range []struct {
+		entries   []map[string]macBluetoothProfileDevice
+		connected bool
+	}{
+		{profile.SPBluetoothDataType[0].Connected, true},
+		{profile.SPBluetoothDataType[0].NotConnected, false},
+	} {
+		for _, entry := range list.entries {
+			for name, device := range entry {
+				devices = append(devices, BluetoothDevice{Name: name, Address: device.Address, Connected: list.connected, Paired: true})
+			}
+		}
+	}
+	return devices, nil
+}
+
+// windowsBluetoothDevicesScript busca con la API de enumeración de WinRT los
+// dispositivos Bluetooth clásico y LE emparejados o conectados. Los GUID son
+// los identificadores de protocolo de AEP de cada uno.
+const windowsBluetoothDevicesScript = windowsRadioPrelude + `
+$ErrorActionPreference = 'Stop'
+[Windows.Devices.Enumeration.DeviceInformation,Windows.Devices.Enumeration,ContentType=WindowsRuntime] | Out-Null
+$props = [string[]]@('System.Devices.Aep.DeviceAddress', 'System.Devices.Aep.IsConnected', 'System.Devices.Aep.IsPaired')
+$devices = foreach ($protocol in '{e0cbf06c-cd8b-4647-bb8a-263b43f0f974}', '{bb7bb05e-5972-42b5-94fc-76eaa7084d49}') {
+	$aqs = "System.Devices.Aep.ProtocolId:=""$protocol"" AND (System.Devices.Aep.IsPaired:=System.StructuredQueryType.Boolean#True OR System.Devices.Aep.IsConnected:=System.StructuredQueryType.Boolean#True)"
+	$found = Await ([Windows.Devices.Enumeration.DeviceInformation]::FindAllAsync($aqs, $props, [Windows.Devices.Enumeration.DeviceInformationKind]::AssociationEndpoint)) ([Windows.Devices.Enumeration.DeviceInformationCollection])
+	foreach ($d in $found) {
+		[pscustomobject]@{ name = $d.Name; address = [string]$d.Properties['System.Devices.Aep.DeviceAddress']; connected = [bool]$d.Properties['System.Devices.Aep.IsConnected']; paired = [bool]$d.Properties['System.Devices.Aep.IsPaired'] }
+	}
+}
+ConvertTo-Json -InputObject @($devices) -Compress
+`
+
+func listBluetoothWindows() ([]BluetoothDevice, error) {
+	output, err := runPowerShell(windowsBluetoothDevicesScript)
+	if err != nil {
+		return nil, fmt.Errorf("error al enumerar los dispositivos Bluetooth: %w", err)
+	}
+	var devices []BluetoothDevice
+	if err := json.Unmarshal([]byte(output), &devices); err != nil {
+		return nil, fmt.Errorf("respuesta inesperada de PowerShell: %w", err)
+	}
+	return devices, nil
+}
+
+// listBluetoothDevices devuelve los dispositivos emparejados o conectados,
+// una vez cada uno (un dispositivo puede aparecer como clásico y como LE, o
+// en las dos consultas de blueutil) y ordenados por nombre
+func listBluetoothDevices() (ListBluetoothDevicesOutput, error) {
+	state, err := readBluetoothState()
+	if err != nil {
+		return ListBluetoothDevicesOutput{}, err
+	}
+	var devices []BluetoothDevice
+	switch currentPlatform() {
+	case platformWindows, platformWSL:
+		devices, err = listBluetoothWindows()
+	case platformMac:
+		devices, err = listBluetoothMac()
+	default:
+		devices, err = listBluetoothLinux()
+	}
+	if err != nil {
+		return ListBluetoothDevicesOutput{}, err
+	}
+
+	out := ListBluetoothDevicesOutput{Powered: state.Enabled, Devices: []BluetoothDevice{}}
+	index := map[string]int{}
+	for _, device := range devices {
+		device.Address = normalizeBluetoothAddress(device.Address)
+		if i, ok := index[device.Address]; ok && device.Address != "" {
+			out.Devices[i].Connected = out.Devices[i].Connected || device.Connected
+			out.Devices[i].Paired = out.Devices[i].Paired || device.Paired
+			continue
+		}
+		index[device.Address] = len(out.Devices)
+		out.Devices = append(out.Devices, device)
+	}
+	slices.SortFunc(out.Devices, func(a, b BluetoothDevice) int {
+		return strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+	})
+	if currentPlatform() == platformWSL {
+		out.Note = "Dispositivos del Bluetooth del Windows anfitrión"
+	}
+	return out, nil
+}
+
+func HandleListBluetoothDevices(ctx context.Context, req *mcp.CallToolRequest, input struct{}) (*mcp.CallToolResult, ListBluetoothDevicesOutput, error) {
+	out, err := listBluetoothDevices()
+	if err != nil {
+		return nil, ListBluetoothDevicesOutput{}, fmt.Errorf("❌ Error al listar los dispositivos Bluetooth: %w", err)
+	}
+
+	lines := []string{fmt.Sprintf("🔵 Bluetooth %s, %d dispositivos:", onOffBluetooth(out.Powered), len(out.Devices))}
+	if len(out.Devices) == 0 {
+		lines[0] = fmt.Sprintf("🔵 Bluetooth %s, sin dispositivos emparejados", onOffBluetooth(out.Powered))
+	}
+	for _, d := range out.Devices {
+		var flags []string
+		if d.Connected {
+			flags = append(flags, "conectado")
+		}
+		if !d.Paired {
+			flags = append(flags, "sin emparejar")
+		}
+		line := fmt.Sprintf("  - %s (%s)", d.Name, d.Address)
+		if len(flags) > 0 {
+			line += ": " + strings.Join(flags, ", ")
+		}
+		lines = append(lines, line)
+	}
+	if out.Note != "" {
+		lines = append(lines, "ℹ️ "+out.Note)
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: strings.Join(lines, "\n")},
+		},
+	}, out, nil
+}
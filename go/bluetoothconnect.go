@@ -0,0 +1,293 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Límites de connect_bluetooth_device
+const (
+	defaultBluetoothConnectTimeout = 15 * time.Second
+	maxBluetoothConnectTimeout     = 60 * time.Second
+	// bluetoothAudioTimeout es lo que se espera a que el dispositivo
+	// conectado aparezca como salida de audio (PipeWire crea la salida
+	// cuando se negocia el perfil, algo después de la conexión)
+	bluetoothAudioTimeout = 8 * time.Second
+)
+
+// Acciones de connect_bluetooth_device
+const (
+	bluetoothConnect    = "connect"
+	bluetoothDisconnect = "disconnect"
+)
+
+// findBluetoothDevice busca un dispositivo emparejado por dirección o nombre
+// exactos y, si no, por coincidencia parcial del nombre sin distinguir
+// mayúsculas. Si hay varias coincidencias el error las enumera.
+func findBluetoothDevice(devices []BluetoothDevice, query string) (BluetoothDevice, error) {
+	var paired []BluetoothDevice
+	for _, d := range devices {
+		if d.Paired {
+			paired = append(paired, d)
+		}
+	}
+	names := make([]string, len(paired))
+	for i, d := range paired {
+		names[i] = d.Name
+		if strings.EqualFold(d.Address, normalizeBluetoothAddress(query)) || strings.EqualFold(d.Name, query) {
+			return d, nil
+		}
+	}
+
+	q := strings.ToLower(query)
+	var matches []BluetoothDevice
+	for _, d := range paired {
+		if strings.Contains(strings.ToLower(d.Name), q) {
+			matches = append(matches, d)
+		}
+	}
+	switch len(matches) {
+	case 1:
+		return matches[0], nil
+	case 0:
+		if len(paired) == 0 {
+			return BluetoothDevice{}, errors.New("no hay dispositivos Bluetooth emparejados: empareja el dispositivo desde los ajustes del sistema")
+		}
+		return BluetoothDevice{}, fmt.Errorf("no hay ningún dispositivo emparejado que coincida con %q (emparejados: %s)", query, strings.Join(names, ", "))
+	}
+	ambiguous := make([]string, len(matches))
+	for i, d := range matches {
+		ambiguous[i] = fmt.Sprintf("%s (%s)", d.Name, d.Address)
+	}
+	return BluetoothDevice{}, fmt.Errorf("%q coincide con varios dispositivos: %s. Usa el nombre completo o la dirección", query, strings.Join(ambiguous, ", "))
+}
+
+// windowsBluetoothDeviceScript habilita o deshabilita los dispositivos PnP
+// de un dispositivo Bluetooth (sus servicios BTHENUM o el dispositivo BTHLE),
+// que llevan la dirección sin separadores en el InstanceId. Windows no tiene
+// una orden para conectar un dispositivo emparejado: al habilitarlos vuelve
+// a conectarlo, y deshabilitados queda desconectado.
+const windowsBluetoothDeviceScript = `
+$ErrorActionPreference = 'Stop'
+$devices = Get-PnpDevice -PresentOnly | Where-Object { $_.InstanceId -match '^BTH(ENUM|LE)\\' -and $_.InstanceId -like '*%s*' }
+if (-not $devices) { throw 'No se encontraron los dispositivos PnP del dispositivo Bluetooth' }
+%s
+`
+
+// setWindowsBluetoothDeviceState conecta (habilitando, tras deshabilitar
+// para forzar la reconexión si ya lo estaba) o desconecta el dispositivo
+func setWindowsBluetoothDeviceState(address string, connect bool) error {
+	action := `$devices | Disable-PnpDevice -Confirm:$false`
+	if connect {
+		action = `$devices | Where-Object { $_.Status -eq 'OK' } | Disable-PnpDevice -Confirm:$false
+$devices | Enable-PnpDevice -Confirm:$false`
+	}
+	_, err := runPowerShell(fmt.Sprintf(windowsBluetoothDeviceScript, strings.ReplaceAll(address, ":", ""), action))
+	if err != nil {
+		if permissionDeniedRegexp.MatchString(err.Error()) {
+			return fmt.Errorf("los cmdlets PnP: %w", errRadioRequiresAdmin)
+		}
+		return fmt.Errorf("%w (en Windows se usan Enable-PnpDevice y Disable-PnpDevice, que requieren administrador)", err)
+	}
+	return nil
+}
+
+// requestBluetoothConnection pide al sistema que conecte o desconecte el
+// dispositivo. En Linux y macOS la orden puede volver antes de que la
+// conexión se complete, así que el resultado se confirma después.
+func requestBluetoothConnection(address string, connect bool) error {
+	switch currentPlatform() {
+	case platformWindows, platformWSL:
+		return setWindowsBluetoothDeviceState(address, connect)
+	case platformMac:
+		if !commandExists("blueutil")() {
+			return errors.New("macOS no tiene una orden para conectar dispositivos Bluetooth: " + blueutilHint)
+		}
+		flag := "--disconnect"
+		if connect {
+			flag = "--connect"
+		}
+		_, err := runRadioCommand("blueutil", flag, address)
+		return err
+	}
+	if !commandExists("bluetoothctl")() {
+		return errors.New("requiere bluetoothctl (BlueZ)")
+	}
+	command := "disconnect"
+	if connect {
+		command = "connect"
+	}
+	_, err := runRadioCommand("bluetoothctl", command, address)
+	return err
+}
+
+// waitForBluetoothConnection consulta el dispositivo hasta que su estado de
+// conexión es el pedido o se agota timeout, y devuelve el último leído
+func waitForBluetoothConnection(ctx context.Context, device BluetoothDevice, connected bool, timeout time.Duration) (BluetoothDevice, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		if list, err := listBluetoothDevices(); err == nil {
+			for _, d := range list.Devices {
+				if d.Address == device.Address {
+					device = d
+				}
+			}
+		}
+		if device.Connected == connected || time.Now().After(deadline) {
+			return device, nil
+		}
+		select {
+		case <-ctx.Done():
+			return device, ctx.Err()
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+// setBluetoothAudioOutput espera a que el dispositivo aparezca como salida de
+// audio y la convierte en la predeterminada. Se reconoce por el nombre o,
+// con PulseAudio/PipeWire, por la dirección en el id (bluez_output.AA_BB_...).
+func setBluetoothAudioOutput(ctx context.Context, device BluetoothDevice) (AudioDevice, error) {
+	addressID := strings.ReplaceAll(device.Address, ":", "_")
+	deadline := time.Now().Add(bluetoothAudioTimeout)
+	for {
+		outputs, err := listAudioDevices(audioOutput)
+		if err != nil {
+			return AudioDevice{}, err
+		}
+		for _, output := range outputs {
+			if strings.Contains(strings.ToUpper(output.ID), addressID) || device.Name != "" && strings.Contains(strings.ToLower(output.Name), strings.ToLower(device.Name)) {
+				return setDefaultAudioDevice(audioOutput, output.ID)
+			}
+		}
+		if time.Now().After(deadline) {
+			return AudioDevice{}, fmt.Errorf("%s no aparece como salida de audio tras %s", device.Name, bluetoothAudioTimeout)
+		}
+		select {
+		case <-ctx.Done():
+			return AudioDevice{}, ctx.Err()
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+type ConnectBluetoothDeviceInput struct {
+	Device           string `json:"device" jsonschema:"Nombre (o parte del nombre) o dirección del dispositivo emparejado (ej: 'auriculares', 'AA:BB:CC:DD:EE:FF')"`
+	Action           string `json:"action,omitempty" jsonschema:"connect (por defecto) o disconnect"`
+	SetAsAudioOutput bool   `json:"set_as_audio_output,omitempty" jsonschema:"Al conectar, usar el dispositivo como salida de audio predeterminada (auriculares, altavoces)"`
+	TimeoutSeconds   int    `json:"timeout_seconds,omitempty" jsonschema:"Tiempo máximo de espera de la conexión en segundos (por defecto 15, hasta 60)"`
+}
+
+type ConnectBluetoothDeviceOutput struct {
+	Device      BluetoothDevice `json:"device" jsonschema:"Dispositivo, con el estado de conexión leído tras la acción"`
+	Action      string          `json:"action" jsonschema:"connect o disconnect"`
+	Changed     bool            `json:"changed" jsonschema:"Se cambió el estado (false si ya estaba como se pedía)"`
+	AudioOutput string          `json:"audio_output,omitempty" jsonschema:"Salida de audio predeterminada tras conectar, si se pidió set_as_audio_output"`
+	Note        string          `json:"note,omitempty" jsonschema:"Aclaración sobre el resultado"`
+}
+
+// connectBluetoothDevice conecta o desconecta un dispositivo emparejado y
+// espera a que el cambio se confirme
+func connectBluetoothDevice(ctx context.Context, input ConnectBluetoothDeviceInput) (ConnectBluetoothDeviceOutput, error) {
+	query := strings.TrimSpace(input.Device)
+	if query == "" {
+		return ConnectBluetoothDeviceOutput{}, errors.New("indica el nombre o la dirección del dispositivo")
+	}
+	action := strings.ToLower(strings.TrimSpace(input.Action))
+	if action == "" {
+		action = bluetoothConnect
+	}
+	if action != bluetoothConnect && action != bluetoothDisconnect {
+		return ConnectBluetoothDeviceOutput{}, fmt.Errorf("acción no válida %q: usa connect o disconnect", input.Action)
+	}
+	timeout := time.Duration(input.TimeoutSeconds) * time.Second
+	if timeout <= 0 {
+		timeout = defaultBluetoothConnectTimeout
+	}
+
+	list, err := listBluetoothDevices()
+	if err != nil {
+		return ConnectBluetoothDeviceOutput{}, err
+	}
+	if !list.Powered {
+		return ConnectBluetoothDeviceOutput{}, errors.New("el Bluetooth está apagado: enciéndelo primero (set_bluetooth)")
+	}
+	device, err := findBluetoothDevice(list.Devices, query)
+	if err != nil {
+		return ConnectBluetoothDeviceOutput{}, err
+	}
+	connect := action == bluetoothConnect
+	out := ConnectBluetoothDeviceOutput{Device: device, Action: action}
+
+	if device.Connected != connect {
+		if err := requestBluetoothConnection(device.Address, connect); err != nil {
+			if errors.Is(err, errRadioRequiresAdmin) {
+				return ConnectBluetoothDeviceOutput{}, fmt.Errorf("no se pudo cambiar la conexión de %s: %w. Ejecuta el servidor como administrador", device.Name, err)
+			}
+			return ConnectBluetoothDeviceOutput{}, fmt.Errorf("error al cambiar la conexión de %s: %w", device.Name, err)
+		}
+		// La orden puede terminar antes que la conexión: solo cuenta el
+		// estado que se lee después
+		device, err = waitForBluetoothConnection(ctx, device, connect, timeout)
+		if err != nil {
+			return ConnectBluetoothDeviceOutput{}, err
+		}
+		if device.Connected != connect {
+			if connect {
+				return ConnectBluetoothDeviceOutput{}, fmt.Errorf("%s no se conectó en %s: comprueba que esté encendido, cerca y no conectado a otro equipo", device.Name, timeout)
+			}
+			return ConnectBluetoothDeviceOutput{}, fmt.Errorf("%s sigue conectado tras %s", device.Name, timeout)
+		}
+		out.Device = device
+		out.Changed = true
+	}
+
+	if connect && input.SetAsAudioOutput {
+		if audio, err := setBluetoothAudioOutput(ctx, device); err != nil {
+			// La conexión ya está hecha: el fallo del audio no la deshace
+			out.Note = "Conectado, pero no se pudo usar como salida de audio: " + err.Error()
+		} else {
+			out.AudioOutput = audio.Name
+		}
+	}
+	if !connect && out.Changed && (currentPlatform() == platformWindows || currentPlatform() == platformWSL) {
+		out.Note = "En Windows el dispositivo queda deshabilitado en el Administrador de dispositivos hasta que se vuelva a conectar con esta herramienta"
+	}
+	return out, nil
+}
+
+func HandleConnectBluetoothDevice(ctx context.Context, req *mcp.CallToolRequest, input ConnectBluetoothDeviceInput) (*mcp.CallToolResult, ConnectBluetoothDeviceOutput, error) {
+	out, err := connectBluetoothDevice(ctx, input)
+	if err != nil {
+		return nil, ConnectBluetoothDeviceOutput{}, fmt.Errorf("❌ Error al cambiar la conexión Bluetooth: %w", err)
+	}
+
+	var line string
+	switch {
+	case out.Action == bluetoothConnect && out.Changed:
+		line = fmt.Sprintf("🔵 %s conectado (%s)", out.Device.Name, out.Device.Address)
+	case out.Action == bluetoothConnect:
+		line = fmt.Sprintf("🔵 %s ya estaba conectado (%s)", out.Device.Name, out.Device.Address)
+	case out.Changed:
+		line = fmt.Sprintf("🔵 %s desconectado (%s)", out.Device.Name, out.Device.Address)
+	default:
+		line = fmt.Sprintf("🔵 %s ya estaba desconectado (%s)", out.Device.Name, out.Device.Address)
+	}
+	lines := []string{line}
+	if out.AudioOutput != "" {
+		lines = append(lines, "🔊 Salida de audio cambiada a "+out.AudioOutput)
+	}
+	if out.Note != "" {
+		lines = append(lines, "ℹ️ "+out.Note)
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: strings.Join(lines, "\n")},
+		},
+	}, out, nil
+}
@@ -0,0 +1,583 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// minBrightness es el brillo mínimo que se aplica salvo que se pida force.
+// Evita que un nivel 0 deje la pantalla ilegible (configurable con --min-brightness).
+var minBrightness = 5
+
+// applyBrightnessFloor eleva level hasta minBrightness salvo que force sea
+// true. Devuelve el nivel resultante y una nota si se ha modificado.
+func applyBrightnessFloor(level int, force bool) (int, string) {
+	if force || level >= minBrightness {
+		return level, ""
+	}
+	return minBrightness, fmt.Sprintf("⚠️ Se pidió %d%%, elevado a %d%% por seguridad (usa force=true para permitirlo)", level, minBrightness)
+}
+
+// setBrightness ajusta el brillo de la pantalla (0-100), respetando el
+// mínimo de seguridad salvo que force sea true
+func setBrightness(level int, display string, force bool) string {
+	if level < 0 || level > 100 {
+		clamped := min(max(level, 0), 100)
+		return fmt.Sprintf("⚠️ Se pidió %d%%, limitado a %d%% (rango 0-100)\n%s", level, clamped, setBrightness(clamped, display, force))
+	}
+
+	level, note := applyBrightnessFloor(level, force)
+	if note != "" {
+		return note + "\n" + setBrightness(level, display, true)
+	}
+
+	switch {
+	case osType == "windows" || isWSL():
+		return setBrightnessWindows(level, display)
+	case osType == "darwin":
+		return setBrightnessMac(level, display)
+	default:
+		return setBrightnessLinux(level, display)
+	}
+}
+
+// setBrightnessLinux ajusta el brillo en Linux. Usa DDC/CI para monitores
+// externos cuando es posible y, si no, los métodos de setBrightnessBacklight.
+// Sin display se usa la salida predeterminada según xrandr (defaultXrandrOutputs).
+func setBrightnessLinux(level int, display string) string {
+	display, note := resolveDisplayName(display)
+
+	var notes []string
+	if note != "" {
+		notes = append(notes, note)
+	}
+	var results []string
+	var ddcConnectors []string
+	if hasDDCUtil() && !isInternalOutput(display) {
+		handled, note := setBrightnessDDC(level, display)
+		if note != "" {
+			notes = append(notes, note)
+		}
+		for _, m := range handled {
+			results = append(results, fmt.Sprintf("✅ Brillo ajustado a %d%% en el monitor %d %s (DDC/CI vía ddcutil)", level, m.Number, m.Model))
+			ddcConnectors = append(ddcConnectors, m.Connector)
+		}
+		if len(handled) > 0 && !strings.EqualFold(display, "all") {
+			return strings.Join(results, "\n")
+		}
+	}
+
+	results = append(results, setBrightnessBacklight(level, display, ddcConnectors))
+	return strings.Join(append(notes, results...), "\n")
+}
+
+// setBrightnessDDC intenta ajustar el brillo con ddcutil y devuelve los
+// monitores ajustados. Si no se pide un monitor concreto solo se usa DDC/CI
+// cuando no hay panel interno, que se controla mejor por retroiluminación.
+// La nota explica por qué hay que recurrir a otro método cuando no se ajustó nada.
+func setBrightnessDDC(level int, display string) (handled []ddcMonitor, note string) {
+	if display == "" {
+		if devices, err := listBacklights(); err == nil && len(devices) > 0 {
+			return nil, ""
+		}
+	}
+
+	monitors, err := listDDCMonitors()
+	if err != nil {
+		return nil, fmt.Sprintf("⚠️ No se pudieron detectar monitores DDC/CI: %v", err)
+	}
+
+	var targets []ddcMonitor
+	switch {
+	case strings.EqualFold(display, "all"):
+		targets = validDDCMonitors(monitors)
+		if len(targets) == 0 {
+			return nil, ""
+		}
+	case display != "":
+		m, found := findDDCMonitor(monitors, display)
+		if !found {
+			return nil, fmt.Sprintf("⚠️ No se encontró el monitor %q entre los detectados por ddcutil", display)
+		}
+		if !m.Valid {
+			return nil, fmt.Sprintf("⚠️ El monitor %q no soporta DDC/CI, por eso no cambia su brillo por hardware", display)
+		}
+		targets = []ddcMonitor{m}
+	default:
+		valid := validDDCMonitors(monitors)
+		if len(valid) == 0 {
+			if len(monitors) > 0 {
+				return nil, "⚠️ Ningún monitor conectado soporta DDC/CI"
+			}
+			return nil, ""
+		}
+		targets = valid[:1]
+	}
+
+	for _, m := range targets {
+		if err := setDDCBrightness(m.Number, level); err != nil {
+			note = fmt.Sprintf("⚠️ ddcutil no pudo ajustar el monitor %d: %v", m.Number, err)
+			continue
+		}
+		handled = append(handled, m)
+	}
+	return handled, note
+}
+
+// setBrightnessBacklight ajusta el brillo en Linux con la cadena de backends
+// aplicable a la sesión gráfica actual (X11 o Wayland). Los conectores de
+// exclude ya se ajustaron por DDC/CI.
+func setBrightnessBacklight(level int, display string, exclude []string) string {
+	b := newLinuxBrightness()
+	b.Exclude = exclude
+	backend, err := b.Set(level, display)
+	if err != nil {
+		return fmt.Sprintf("❌ Error al ajustar brillo: %v", err)
+	}
+	return fmt.Sprintf("✅ Brillo ajustado a %d%% (%s)", level, backend)
+}
+
+// DisplayBrightness es el brillo de una pantalla concreta
+type DisplayBrightness struct {
+	Name    string `json:"name" jsonschema:"Nombre de la pantalla (conector, instancia WMI o índice de display)"`
+	Percent int    `json:"percent" jsonschema:"Brillo en porcentaje (0-100)"`
+	Source  string `json:"source,omitempty" jsonschema:"Origen de la lectura cuando es relevante"`
+}
+
+// listDisplayBrightness obtiene el brillo de cada pantalla que permite leerlo
+func listDisplayBrightness() ([]DisplayBrightness, error) {
+	switch {
+	case osType == "windows" || isWSL():
+		return listDisplayBrightnessWindows()
+	case osType == "darwin":
+		return listDisplayBrightnessMac()
+	default:
+		// Linux - /sys/class/backlight para el panel interno y VCP 10 con ddcutil
+		var displays []DisplayBrightness
+		devices, err := listBacklights()
+		if err != nil {
+			return nil, err
+		}
+		if len(devices) > 0 {
+			device := preferredBacklight(devices)
+			reading := DisplayBrightness{Name: device.Name, Percent: device.Percent(), Source: device.Name}
+			// En GNOME el nivel de gnome-settings-daemon es el que ve el usuario
+			if detectLinuxSession().IsDesktop("GNOME") && commandExists("gdbus")() {
+				if level, err := getGNOMEBrightness(); err == nil {
+					reading.Percent = level
+					reading.Source = "GNOME Settings Daemon vía DBus"
+				}
+			}
+			displays = append(displays, reading)
+		}
+		if hasDDCUtil() {
+			monitors, err := listDDCMonitors()
+			if err != nil {
+				return nil, err
+			}
+			for _, m := range validDDCMonitors(monitors) {
+				percent, err := getDDCBrightness(m.Number)
+				if err != nil {
+					continue
+				}
+				name := m.Connector
+				if name == "" {
+					name = fmt.Sprintf("ddc-%d", m.Number)
+				}
+				displays = append(displays, DisplayBrightness{
+					Name:    name,
+					Percent: percent,
+					Source:  fmt.Sprintf("monitor %d %s, DDC/CI", m.Number, m.Model),
+				})
+			}
+		}
+		return displays, nil
+	}
+}
+
+// getBrightness obtiene el brillo actual de cada pantalla (o solo de la
+// indicada por nombre o índice) y lo describe en texto
+func getBrightness(display string) (string, []DisplayBrightness) {
+	displays, err := listDisplayBrightness()
+	if err != nil {
+		return fmt.Sprintf("❌ Error al obtener brillo: %v", err), nil
+	}
+	if len(displays) == 0 {
+		if osType == "linux" {
+			// Equipos de sobremesa sin retroiluminación ni monitores DDC/CI
+			return "⚠️ Obtener brillo no implementado en Linux (usa xrandr manualmente)", nil
+		}
+		return "❌ No se encontró ninguna pantalla que informe de su brillo", nil
+	}
+
+	if display != "" {
+		selected, ok := findDisplayBrightness(displays, display)
+		if !ok {
+			names := make([]string, len(displays))
+			for i, d := range displays {
+				names[i] = d.Name
+			}
+			return fmt.Sprintf("❌ No se encontró el display %q (disponibles: %s)", display, strings.Join(names, ", ")), nil
+		}
+		displays = []DisplayBrightness{selected}
+	}
+
+	if len(displays) == 1 {
+		d := displays[0]
+		if d.Source != "" {
+			return fmt.Sprintf("💡 Brillo actual: %d%% (%s)", d.Percent, d.Source), displays
+		}
+		return fmt.Sprintf("💡 Brillo actual: %d%%", d.Percent), displays
+	}
+
+	lines := []string{"💡 Brillo actual por pantalla:"}
+	for _, d := range displays {
+		if d.Source != "" && d.Source != d.Name {
+			lines = append(lines, fmt.Sprintf("  - %s: %d%% (%s)", d.Name, d.Percent, d.Source))
+		} else {
+			lines = append(lines, fmt.Sprintf("  - %s: %d%%", d.Name, d.Percent))
+		}
+	}
+	return strings.Join(lines, "\n"), displays
+}
+
+// findDisplayBrightness busca una pantalla por nombre o por índice en la lista
+func findDisplayBrightness(displays []DisplayBrightness, display string) (DisplayBrightness, bool) {
+	for _, d := range displays {
+		if strings.EqualFold(d.Name, display) {
+			return d, true
+		}
+	}
+	if index, err := strconv.Atoi(display); err == nil && index >= 0 && index < len(displays) {
+		return displays[index], true
+	}
+	return DisplayBrightness{}, false
+}
+
+// currentBrightness lee el brillo actual de una pantalla (la primera que
+// informa de su brillo si display está vacío)
+func currentBrightness(display string) (int, error) {
+	displays, err := listDisplayBrightness()
+	if err != nil {
+		return 0, fmt.Errorf("no se pudo leer el brillo actual: %w", err)
+	}
+	if len(displays) == 0 {
+		return 0, errors.New("no se puede leer el brillo actual en este equipo")
+	}
+	if display == "" {
+		return displays[0].Percent, nil
+	}
+	current, ok := findDisplayBrightness(displays, display)
+	if !ok {
+		return 0, fmt.Errorf("no se pudo leer el brillo actual del display %q", display)
+	}
+	return current.Percent, nil
+}
+
+// adjustBrightness cambia el brillo de forma relativa (delta positivo o
+// negativo) partiendo del valor actual, que debe poder leerse
+func adjustBrightness(delta int, display string) (string, error) {
+	current, err := currentBrightness(display)
+	if err != nil {
+		return "", fmt.Errorf("%w; usa set_brightness con un valor absoluto", err)
+	}
+
+	target := min(max(current+delta, 0), 100)
+
+	result := setBrightness(target, display, false)
+	if strings.HasPrefix(result, "❌") {
+		return "", errors.New(strings.TrimSpace(strings.TrimPrefix(result, "❌")))
+	}
+	pushBrightnessHistory(display, current)
+	if target != current+delta {
+		return fmt.Sprintf("🔆 Brillo: %d%% → %d%% (se pidió %+d, limitado al rango 0-100)\n%s", current, target, delta, result), nil
+	}
+	return fmt.Sprintf("🔆 Brillo: %d%% → %d%%\n%s", current, target, result), nil
+}
+
+const (
+	// maxFadeDuration limita la duración de una transición de brillo o volumen
+	maxFadeDuration = 30 * time.Second
+	// minFadeStep es el intervalo mínimo entre pasos, ya que cada paso lanza
+	// un proceso externo (xrandr, ddcutil, PowerShell...)
+	minFadeStep = 100 * time.Millisecond
+)
+
+// fadeMu protege fadeCancel, que cancela la transición de brillo en curso
+// cuando llega una nueva petición, y fadeRunning, el número de transiciones
+// que aún no han terminado (display_off no apaga la pantalla mientras tanto)
+var (
+	fadeMu      sync.Mutex
+	fadeCancel  context.CancelFunc
+	fadeRunning int
+)
+
+// fadeInProgress indica si hay una transición de brillo en curso
+func fadeInProgress() bool {
+	fadeMu.Lock()
+	defer fadeMu.Unlock()
+	return fadeRunning > 0
+}
+
+// fadeBrightness lleva el brillo al nivel indicado de forma gradual durante
+// duration. Se detiene si se cancela ctx o si empieza otra transición.
+func fadeBrightness(ctx context.Context, level int, display string, duration time.Duration, force bool) string {
+	if level < 0 {
+		level = 0
+	}
+	if level > 100 {
+		level = 100
+	}
+	if duration > maxFadeDuration {
+		duration = maxFadeDuration
+	}
+
+	start, err := currentBrightness(display)
+	if err != nil {
+		// Sin lectura del valor actual no hay desde dónde empezar: salto directo
+		return fmt.Sprintf("⚠️ Sin transición (%v)\n%s", err, setBrightness(level, display, force))
+	}
+
+	level, note := applyBrightnessFloor(level, force)
+	if note != "" {
+		note += "\n"
+	}
+	pushBrightnessHistory(display, start)
+
+	// Cancelar la transición anterior y registrar esta
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	fadeMu.Lock()
+	if fadeCancel != nil {
+		fadeCancel()
+	}
+	fadeCancel = cancel
+	fadeRunning++
+	fadeMu.Unlock()
+	defer func() {
+		fadeMu.Lock()
+		fadeRunning--
+		fadeMu.Unlock()
+	}()
+
+	diff := level - start
+	steps := diff
+	if steps < 0 {
+		steps = -steps
+	}
+	if maxSteps := int(duration / minFadeStep); steps > maxSteps {
+		steps = maxSteps
+	}
+	if steps < 1 {
+		steps = 1
+	}
+	interval := duration / time.Duration(steps)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	current := start
+	for i := 1; i < steps; i++ {
+		select {
+		case <-ctx.Done():
+			return fmt.Sprintf("⏹️ Transición de brillo cancelada en %d%% (objetivo %d%%)", current, level)
+		case <-ticker.C:
+		}
+		current = start + diff*i/steps
+		if result := setBrightness(current, display, true); strings.HasPrefix(result, "❌") {
+			return result
+		}
+	}
+
+	select {
+	case <-ctx.Done():
+		return fmt.Sprintf("⏹️ Transición de brillo cancelada en %d%% (objetivo %d%%)", current, level)
+	case <-ticker.C:
+	}
+	result := setBrightness(level, display, true)
+	if strings.HasPrefix(result, "❌") {
+		return result
+	}
+	return fmt.Sprintf("%s%s\n🌗 Transición de %d%% a %d%% en %v (%d pasos cada %v)", note, result, start, level, duration, steps, interval)
+}
+
+// brightnessHistorySize es el número de valores anteriores que se recuerdan por pantalla
+const brightnessHistorySize = 5
+
+// brightnessHistory guarda, por pantalla, los niveles previos a cada cambio
+// para poder deshacerlos con restore_brightness. Cada entrada tiene el nivel
+// de cada pantalla afectada: una sola salvo con display "all".
+var (
+	historyMu         sync.Mutex
+	brightnessHistory = map[string][][]DisplayBrightness{}
+)
+
+// pushBrightnessHistory apila el nivel previo a un cambio en una pantalla
+func pushBrightnessHistory(display string, level int) {
+	pushBrightnessLevels(display, []DisplayBrightness{{Name: display, Percent: level}})
+}
+
+// pushBrightnessLevels apila los niveles previos de las pantallas que
+// cambiaron al ajustar display
+func pushBrightnessLevels(display string, levels []DisplayBrightness) {
+	key := strings.ToLower(display)
+	historyMu.Lock()
+	defer historyMu.Unlock()
+	stack := append(brightnessHistory[key], levels)
+	if len(stack) > brightnessHistorySize {
+		stack = stack[len(stack)-brightnessHistorySize:]
+	}
+	brightnessHistory[key] = stack
+}
+
+// popBrightnessHistory desapila los últimos niveles previos de una pantalla
+func popBrightnessHistory(display string) ([]DisplayBrightness, bool) {
+	key := strings.ToLower(display)
+	historyMu.Lock()
+	defer historyMu.Unlock()
+	stack := brightnessHistory[key]
+	if len(stack) == 0 {
+		return nil, false
+	}
+	levels := stack[len(stack)-1]
+	brightnessHistory[key] = stack[:len(stack)-1]
+	return levels, true
+}
+
+// previousBrightness lee el brillo de las pantallas que va a cambiar
+// display: todas las que informan de su brillo con "all"
+func previousBrightness(display string) ([]DisplayBrightness, error) {
+	if !strings.EqualFold(display, "all") {
+		level, err := currentBrightness(display)
+		if err != nil {
+			return nil, err
+		}
+		return []DisplayBrightness{{Name: display, Percent: level}}, nil
+	}
+	displays, err := listDisplayBrightness()
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo leer el brillo actual: %w", err)
+	}
+	if len(displays) == 0 {
+		return nil, errors.New("no se puede leer el brillo actual en este equipo")
+	}
+	return displays, nil
+}
+
+// setBrightnessWithHistory lee el brillo actual, aplica el nuevo y, si el
+// cambio funciona, recuerda el valor anterior para poder restaurarlo
+func setBrightnessWithHistory(level int, display string, force bool) string {
+	previous, err := previousBrightness(display)
+	result := setBrightness(level, display, force)
+	if err == nil && !strings.HasPrefix(result, "❌") {
+		pushBrightnessLevels(display, previous)
+	}
+	return result
+}
+
+// restoreBrightness vuelve al brillo que había antes del último cambio, en
+// cada pantalla que cambió
+func restoreBrightness(display string) (string, error) {
+	levels, ok := popBrightnessHistory(display)
+	if !ok {
+		if display != "" {
+			return "", fmt.Errorf("no hay ningún brillo anterior que restaurar para el display %q", display)
+		}
+		return "", errors.New("no hay ningún brillo anterior que restaurar")
+	}
+	results := []string{"↩️ Brillo restaurado"}
+	for _, previous := range levels {
+		// Es un nivel que ya tuvo la pantalla, aunque esté por debajo del
+		// mínimo de seguridad: restaurarlo no debe cambiarlo
+		result := setBrightness(previous.Percent, previous.Name, true)
+		if strings.HasPrefix(result, "❌") {
+			// Devolver los valores a la pila para poder reintentarlo
+			pushBrightnessLevels(display, levels)
+			return "", errors.New(strings.TrimSpace(strings.TrimPrefix(result, "❌")))
+		}
+		results = append(results, result)
+	}
+	return strings.Join(results, "\n"), nil
+}
+
+type SetBrightnessInput struct {
+	Level      int    `json:"level" jsonschema:"Nivel de brillo (0-100). 0=mínimo, 100=máximo"`
+	Display    string `json:"display,omitempty" jsonschema:"Monitor a ajustar: nombre del conector (eDP-1, HDMI-2), índice (0, 1...) o 'all'. Por defecto la pantalla principal"`
+	DurationMs int    `json:"duration_ms,omitempty" jsonschema:"Duración de una transición gradual en milisegundos (opcional, máximo 30000)"`
+	Force      bool   `json:"force,omitempty" jsonschema:"Permite niveles por debajo del mínimo de seguridad (por defecto 5%)"`
+	Refresh    bool   `json:"refresh,omitempty" jsonschema:"Vuelve a detectar las pantallas en lugar de usar la lista en caché (útil tras conectar un monitor)"`
+}
+
+type GetBrightnessInput struct {
+	Display string `json:"display,omitempty" jsonschema:"Pantalla a consultar por nombre o índice (opcional, por defecto todas)"`
+}
+
+type AdjustBrightnessInput struct {
+	Delta   int    `json:"delta" jsonschema:"Cambio relativo de brillo en puntos porcentuales (ej: -10 más oscuro, 15 más claro)"`
+	Display string `json:"display,omitempty" jsonschema:"Pantalla a ajustar por nombre o índice (opcional, por defecto la principal)"`
+}
+
+type RestoreBrightnessInput struct {
+	Display string `json:"display,omitempty" jsonschema:"Pantalla a restaurar (opcional, la misma que se usó al cambiar el brillo)"`
+}
+
+type GetBrightnessOutput struct {
+	Displays []DisplayBrightness `json:"displays,omitempty" jsonschema:"Brillo de cada pantalla"`
+}
+
+func HandleSetBrightness(ctx context.Context, req *mcp.CallToolRequest, input SetBrightnessInput) (*mcp.CallToolResult, any, error) {
+	if input.Refresh {
+		invalidateXrandrCache()
+	}
+
+	var result string
+	if input.DurationMs > 0 {
+		result = fadeBrightness(ctx, input.Level, input.Display, time.Duration(input.DurationMs)*time.Millisecond, input.Force)
+	} else {
+		result = setBrightnessWithHistory(input.Level, input.Display, input.Force)
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: result},
+		},
+	}, nil, nil
+}
+
+func HandleGetBrightness(ctx context.Context, req *mcp.CallToolRequest, input GetBrightnessInput) (*mcp.CallToolResult, GetBrightnessOutput, error) {
+	result, displays := getBrightness(input.Display)
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: result},
+		},
+	}, GetBrightnessOutput{Displays: displays}, nil
+}
+
+func HandleAdjustBrightness(ctx context.Context, req *mcp.CallToolRequest, input AdjustBrightnessInput) (*mcp.CallToolResult, any, error) {
+	result, err := adjustBrightness(input.Delta, input.Display)
+	if err != nil {
+		return nil, nil, fmt.Errorf("❌ Error al ajustar brillo: %w", err)
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: result},
+		},
+	}, nil, nil
+}
+
+func HandleRestoreBrightness(ctx context.Context, req *mcp.CallToolRequest, input RestoreBrightnessInput) (*mcp.CallToolResult, any, error) {
+	result, err := restoreBrightness(input.Display)
+	if err != nil {
+		return nil, nil, fmt.Errorf("❌ Error al restaurar brillo: %w", err)
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: result},
+		},
+	}, nil, nil
+}
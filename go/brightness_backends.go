@@ -0,0 +1,376 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// linuxSession describe la sesión gráfica en la que se ejecuta el servidor
+type linuxSession struct {
+	Wayland bool   // true en sesiones Wayland
+	Desktop string // Valor de XDG_CURRENT_DESKTOP, p. ej. "GNOME" o "KDE"
+}
+
+// detectLinuxSession detecta si la sesión es Wayland y el escritorio en uso
+func detectLinuxSession() linuxSession {
+	return linuxSession{
+		Wayland: strings.EqualFold(os.Getenv("XDG_SESSION_TYPE"), "wayland") || os.Getenv("WAYLAND_DISPLAY") != "",
+		Desktop: os.Getenv("XDG_CURRENT_DESKTOP"),
+	}
+}
+
+// IsDesktop indica si XDG_CURRENT_DESKTOP contiene el escritorio indicado
+// (puede ser una lista separada por ':' como "ubuntu:GNOME")
+func (s linuxSession) IsDesktop(name string) bool {
+	for _, d := range strings.Split(s.Desktop, ":") {
+		if strings.EqualFold(d, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// Compositor devuelve un nombre legible del compositor para los mensajes
+func (s linuxSession) Compositor() string {
+	if s.Desktop == "" {
+		return "desconocido"
+	}
+	return s.Desktop
+}
+
+// linuxBrightnessBackend es un mecanismo concreto para ajustar el brillo en Linux
+type linuxBrightnessBackend struct {
+	Name      string // Descripción para el usuario, p. ej. "gamma por software vía xrandr"
+	Internal  bool   // Solo actúa sobre la retroiluminación del panel interno
+	Available func() bool
+	Set       func(level int, display string) error
+}
+
+// linuxBrightness elige entre los backends de brillo aplicables a la sesión
+// actual y los prueba en orden hasta que uno funciona
+type linuxBrightness struct {
+	Session  linuxSession
+	Backends []linuxBrightnessBackend
+
+	// Exclude son conectores que ya se ajustaron por otra vía (p. ej. DDC/CI)
+	// y que no deben recibir además la gamma de xrandr con display="all"
+	Exclude []string
+
+	internalDone bool
+}
+
+// errNoPendingOutputs indica que no quedaba ninguna salida por ajustar
+var errNoPendingOutputs = errors.New("no se encontraron displays conectados")
+
+// targetsInternal indica si display incluye el panel interno del portátil
+func targetsInternal(display string) bool {
+	return display == "" || strings.EqualFold(display, "all") || isInternalOutput(display)
+}
+
+// newLinuxBrightness construye la cadena de backends según la sesión. En
+// Wayland xrandr no tiene efecto, así que solo se usan métodos que actúan
+// sobre la retroiluminación o sobre el propio escritorio.
+func newLinuxBrightness() *linuxBrightness {
+	session := detectLinuxSession()
+	b := &linuxBrightness{Session: session}
+
+	// En GNOME se prefiere gnome-settings-daemon: muestra el OSD y mantiene
+	// sincronizado su nivel de brillo, al contrario que escribir en sysfs
+	if session.IsDesktop("GNOME") {
+		b.Backends = append(b.Backends, gnomeBrightnessBackend())
+	}
+	// logind permite ajustar la retroiluminación sin root desde la sesión activa
+	b.Backends = append(b.Backends, logindBrightnessBackend())
+
+	if session.Wayland {
+		b.Backends = append(b.Backends,
+			sysfsBrightnessBackend(),
+			brightnessctlBackend(),
+			lightBackend(),
+		)
+		if session.IsDesktop("KDE") {
+			b.Backends = append(b.Backends, kdeBrightnessBackend())
+		}
+		return b
+	}
+
+	b.Backends = append(b.Backends,
+		brightnessctlBackend(),
+		lightBackend(),
+		b.xrandrBackend(),
+	)
+	return b
+}
+
+// Set aplica el brillo con el primer backend disponible que funcione y
+// devuelve el nombre del mecanismo usado. Con display="all" se ajusta el
+// panel interno por retroiluminación y el resto de salidas con xrandr.
+func (b *linuxBrightness) Set(level int, display string) (string, error) {
+	var used []string
+	var lastErr error
+	for _, backend := range b.Backends {
+		if !backend.Available() {
+			continue
+		}
+		if backend.Internal && (b.internalDone || !targetsInternal(display)) {
+			continue
+		}
+		err := backend.Set(level, display)
+		if errors.Is(err, errNoPendingOutputs) && (len(used) > 0 || len(b.Exclude) > 0) {
+			// Todas las salidas ya se ajustaron por otra vía
+			break
+		}
+		if err != nil {
+			// Normalmente falta de permisos: probar el siguiente
+			log.Printf("⚠️ %s falló, probando otro método: %v", backend.Name, err)
+			lastErr = err
+			continue
+		}
+		used = append(used, backend.Name)
+		if backend.Internal && strings.EqualFold(display, "all") {
+			// Seguir con las salidas externas
+			b.internalDone = true
+			continue
+		}
+		break
+	}
+	if len(used) > 0 {
+		return strings.Join(used, " + "), nil
+	}
+	if err := checkGraphicalSession(); err != nil {
+		// Sin escritorio solo sirven los métodos de retroiluminación
+		return "", fmt.Errorf("%w y no hay retroiluminación controlable", err)
+	}
+
+	if b.Session.Wayland {
+		if lastErr != nil {
+			return "", fmt.Errorf("ningún método de brillo funcionó en la sesión Wayland (compositor: %s): %w", b.Session.Compositor(), lastErr)
+		}
+		return "", fmt.Errorf("no hay ningún método de brillo aplicable en la sesión Wayland (compositor: %s)", b.Session.Compositor())
+	}
+	if lastErr != nil {
+		return "", lastErr
+	}
+	return "", errors.New("no hay ningún método de brillo disponible")
+}
+
+// commandExists indica si un ejecutable está en el PATH
+func commandExists(name string) func() bool {
+	return func() bool {
+		_, err := exec.LookPath(name)
+		return err == nil
+	}
+}
+
+// sysfsBrightnessBackend escribe directamente en /sys/class/backlight
+// (requiere root o una regla de udev que dé permisos de escritura)
+func sysfsBrightnessBackend() linuxBrightnessBackend {
+	return linuxBrightnessBackend{
+		Name:     "retroiluminación por hardware vía /sys/class/backlight",
+		Internal: true,
+		Available: func() bool {
+			devices, err := listBacklights()
+			return err == nil && len(devices) > 0
+		},
+		Set: func(level int, _ string) error {
+			devices, err := listBacklights()
+			if err != nil {
+				return err
+			}
+			if len(devices) == 0 {
+				return errors.New("no hay dispositivos de retroiluminación")
+			}
+			device := preferredBacklight(devices)
+			raw := (level*device.MaxBrightness + 50) / 100
+			path := filepath.Join(backlightDir, device.Name, "brightness")
+			return os.WriteFile(path, []byte(strconv.Itoa(raw)), 0644)
+		},
+	}
+}
+
+// logindBrightnessBackend usa org.freedesktop.login1.Session.SetBrightness,
+// que systemd-logind permite a la sesión activa sin privilegios. Trabaja con
+// valores en bruto del dispositivo, así que se escala con max_brightness.
+func logindBrightnessBackend() linuxBrightnessBackend {
+	name := "retroiluminación vía systemd-logind"
+	devices, err := listBacklights()
+	if err == nil && len(devices) > 0 {
+		name = fmt.Sprintf("%s (%s)", name, preferredBacklight(devices).Name)
+	}
+	return linuxBrightnessBackend{
+		Name:     name,
+		Internal: true,
+		Available: func() bool {
+			return err == nil && len(devices) > 0 && commandExists("busctl")()
+		},
+		Set: func(level int, _ string) error {
+			device := preferredBacklight(devices)
+			raw := (level*device.MaxBrightness + 50) / 100
+			output, err := exec.Command("busctl", "call", "org.freedesktop.login1",
+				"/org/freedesktop/login1/session/auto", "org.freedesktop.login1.Session",
+				"SetBrightness", "ssu", "backlight", device.Name, strconv.Itoa(raw)).CombinedOutput()
+			if err != nil {
+				if msg := strings.TrimSpace(string(output)); msg != "" {
+					return fmt.Errorf("%w: %s", err, msg)
+				}
+				return err
+			}
+			return nil
+		},
+	}
+}
+
+func brightnessctlBackend() linuxBrightnessBackend {
+	return linuxBrightnessBackend{
+		Name:      "retroiluminación por hardware vía brightnessctl",
+		Internal:  true,
+		Available: commandExists("brightnessctl"),
+		Set: func(level int, _ string) error {
+			return exec.Command("brightnessctl", "set", fmt.Sprintf("%d%%", level)).Run()
+		},
+	}
+}
+
+func lightBackend() linuxBrightnessBackend {
+	return linuxBrightnessBackend{
+		Name:      "retroiluminación por hardware vía light",
+		Internal:  true,
+		Available: commandExists("light"),
+		Set: func(level int, _ string) error {
+			return exec.Command("light", "-S", strconv.Itoa(level)).Run()
+		},
+	}
+}
+
+// runGDBus ejecuta gdbus con los argumentos indicados y devuelve su salida.
+// Es una variable para poder sustituirla al probar las llamadas DBus.
+var runGDBus = func(args ...string) (string, error) {
+	output, err := exec.Command("gdbus", args...).Output()
+	return string(output), err
+}
+
+// Interfaz DBus de brillo de gnome-settings-daemon
+const (
+	gnomePowerDest  = "org.gnome.SettingsDaemon.Power"
+	gnomePowerPath  = "/org/gnome/SettingsDaemon/Power"
+	gnomePowerIface = "org.gnome.SettingsDaemon.Power.Screen"
+)
+
+// gnomeBrightnessBackend usa la interfaz DBus de gnome-settings-daemon
+func gnomeBrightnessBackend() linuxBrightnessBackend {
+	return linuxBrightnessBackend{
+		Name:      "GNOME Settings Daemon vía DBus",
+		Internal:  true,
+		Available: commandExists("gdbus"),
+		Set: func(level int, _ string) error {
+			_, err := runGDBus("call", "--session",
+				"--dest", gnomePowerDest,
+				"--object-path", gnomePowerPath,
+				"--method", "org.freedesktop.DBus.Properties.Set",
+				gnomePowerIface, "Brightness",
+				fmt.Sprintf("<int32 %d>", level),
+			)
+			return err
+		},
+	}
+}
+
+// getGNOMEBrightness lee la propiedad Brightness de gnome-settings-daemon,
+// que responde con "(<int32 75>,)". El valor -1 indica que no hay panel con
+// retroiluminación controlable.
+func getGNOMEBrightness() (int, error) {
+	output, err := runGDBus("call", "--session",
+		"--dest", gnomePowerDest,
+		"--object-path", gnomePowerPath,
+		"--method", "org.freedesktop.DBus.Properties.Get",
+		gnomePowerIface, "Brightness",
+	)
+	if err != nil {
+		return 0, err
+	}
+	match := gdbusIntPattern.FindStringSubmatch(output)
+	if match == nil {
+		return 0, fmt.Errorf("respuesta inesperada de gnome-settings-daemon: %q", output)
+	}
+	level, _ := strconv.Atoi(match[1])
+	if level < 0 {
+		return 0, errors.New("gnome-settings-daemon no tiene ninguna pantalla con brillo controlable")
+	}
+	return level, nil
+}
+
+// gdbusIntPattern extrae el entero de respuestas de gdbus como "(int32 19393,)"
+var gdbusIntPattern = regexp.MustCompile(`int32 (-?\d+)`)
+
+// kdeBrightnessBackend usa la interfaz DBus de PowerDevil (KDE Plasma), que
+// trabaja con valores en bruto entre 0 y brightnessMax
+func kdeBrightnessBackend() linuxBrightnessBackend {
+	const (
+		dest  = "org.kde.Solid.PowerManagement"
+		path  = "/org/kde/Solid/PowerManagement/Actions/BrightnessControl"
+		iface = "org.kde.Solid.PowerManagement.Actions.BrightnessControl"
+	)
+	return linuxBrightnessBackend{
+		Name:      "KDE PowerDevil vía DBus",
+		Internal:  true,
+		Available: commandExists("gdbus"),
+		Set: func(level int, _ string) error {
+			output, err := exec.Command("gdbus", "call", "--session", "--dest", dest,
+				"--object-path", path, "--method", iface+".brightnessMax").Output()
+			if err != nil {
+				return err
+			}
+			match := gdbusIntPattern.FindStringSubmatch(string(output))
+			if match == nil {
+				return fmt.Errorf("respuesta inesperada de PowerDevil: %q", string(output))
+			}
+			maximum, _ := strconv.Atoi(match[1])
+			raw := (level*maximum + 50) / 100
+			return exec.Command("gdbus", "call", "--session", "--dest", dest,
+				"--object-path", path, "--method", iface+".setBrightness", strconv.Itoa(raw)).Run()
+		},
+	}
+}
+
+// xrandrBackend aplica un multiplicador de gamma con xrandr. Solo oscurece la
+// imagen, no la retroiluminación, y solo funciona en X11.
+func (b *linuxBrightness) xrandrBackend() linuxBrightnessBackend {
+	return linuxBrightnessBackend{
+		Name:      "gamma por software vía xrandr",
+		Available: commandExists("xrandr"),
+		Set: func(level int, display string) error {
+			outputs, err := listXrandrOutputs()
+			if err != nil {
+				return err
+			}
+			targets, err := selectXrandrOutputs(outputs, display)
+			if err != nil {
+				return err
+			}
+			brightness := fmt.Sprintf("%.2f", float64(level)/100.0)
+			applied := 0
+			for _, o := range targets {
+				if slices.Contains(b.Exclude, o.Name) || (b.internalDone && isInternalOutput(o.Name)) {
+					continue
+				}
+				if err := exec.Command("xrandr", "--output", o.Name, "--brightness", brightness).Run(); err != nil {
+					invalidateXrandrCache()
+					return fmt.Errorf("%s: %w", o.Name, err)
+				}
+				applied++
+			}
+			if applied == 0 {
+				return errNoPendingOutputs
+			}
+			return nil
+		},
+	}
+}
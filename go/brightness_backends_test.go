@@ -0,0 +1,74 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// stubGDBus sustituye runGDBus durante el test: guarda los argumentos de
+// cada llamada y responde con output y err
+func stubGDBus(t *testing.T, output string, err error) *[][]string {
+	t.Helper()
+	var calls [][]string
+	previous := runGDBus
+	runGDBus = func(args ...string) (string, error) {
+		calls = append(calls, args)
+		return output, err
+	}
+	t.Cleanup(func() { runGDBus = previous })
+	return &calls
+}
+
+func TestGNOMEBrightnessSet(t *testing.T) {
+	calls := stubGDBus(t, "()\n", nil)
+	if err := gnomeBrightnessBackend().Set(42, ""); err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{{"call", "--session",
+		"--dest", "org.gnome.SettingsDaemon.Power",
+		"--object-path", "/org/gnome/SettingsDaemon/Power",
+		"--method", "org.freedesktop.DBus.Properties.Set",
+		"org.gnome.SettingsDaemon.Power.Screen", "Brightness",
+		"<int32 42>",
+	}}
+	if !reflect.DeepEqual(*calls, want) {
+		t.Errorf("argumentos de gdbus = %q; se esperaba %q", *calls, want)
+	}
+}
+
+func TestGetGNOMEBrightness(t *testing.T) {
+	calls := stubGDBus(t, "(<int32 75>,)\n", nil)
+	level, err := getGNOMEBrightness()
+	if err != nil || level != 75 {
+		t.Errorf("getGNOMEBrightness() = %d, %v; se esperaba 75", level, err)
+	}
+	want := [][]string{{"call", "--session",
+		"--dest", "org.gnome.SettingsDaemon.Power",
+		"--object-path", "/org/gnome/SettingsDaemon/Power",
+		"--method", "org.freedesktop.DBus.Properties.Get",
+		"org.gnome.SettingsDaemon.Power.Screen", "Brightness",
+	}}
+	if !reflect.DeepEqual(*calls, want) {
+		t.Errorf("argumentos de gdbus = %q; se esperaba %q", *calls, want)
+	}
+}
+
+func TestGetGNOMEBrightnessErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		err    error
+	}{
+		// -1: no hay panel con retroiluminación
+		{"sin panel", "(<int32 -1>,)\n", nil},
+		{"respuesta inesperada", "(<'75'>,)\n", nil},
+		{"gdbus falla", "", errors.New("exit status 1")},
+	}
+	for _, tc := range tests {
+		stubGDBus(t, tc.output, tc.err)
+		if level, err := getGNOMEBrightness(); err == nil {
+			t.Errorf("%s: getGNOMEBrightness() = %d, nil; se esperaba un error", tc.name, level)
+		}
+	}
+}
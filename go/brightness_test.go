@@ -0,0 +1,62 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeWMIBrightness pone en el PATH un powershell falso con dos monitores
+// WMI, A al 40% y B al 70%, que acepta cualquier cambio de brillo. Devuelve
+// una función que lee los scripts de cambio recibidos.
+func fakeWMIBrightness(t *testing.T) func() []string {
+	t.Helper()
+	dir := t.TempDir()
+	logFile := filepath.Join(dir, "set.log")
+	fake := `#!/bin/sh
+case "$4" in
+*WmiSetBrightness*) printf '%s\n\0' "$4" >> ` + logFile + `; echo 'A|0' ;;
+*WmiMonitorBrightness*) printf 'A|40\nB|70\n' ;;
+esac
+`
+	if err := os.WriteFile(filepath.Join(dir, "powershell"), []byte(fake), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir)
+	return func() []string {
+		data, err := os.ReadFile(logFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return strings.Split(strings.TrimSuffix(string(data), "\n\x00"), "\n\x00")
+	}
+}
+
+func TestRestoreBrightnessAll(t *testing.T) {
+	setOSType(t, "windows")
+	setScripts := fakeWMIBrightness(t)
+	t.Cleanup(func() { brightnessHistory = map[string][][]DisplayBrightness{} })
+
+	if result := setBrightnessWithHistory(80, "all", false); strings.HasPrefix(result, "❌") {
+		t.Fatalf("setBrightnessWithHistory(80, all) = %q", result)
+	}
+	if _, err := restoreBrightness("all"); err != nil {
+		t.Fatalf("restoreBrightness(all) = %v", err)
+	}
+	// Un cambio para todas y después cada pantalla a su nivel anterior
+	scripts := setScripts()
+	if len(scripts) != 3 {
+		t.Fatalf("se esperaban 3 cambios de brillo; hubo %d: %q", len(scripts), scripts)
+	}
+	for i, want := range []string{"$targets = $m;", "$name = 'A'", "$name = 'B'"} {
+		if !strings.Contains(scripts[i], want) {
+			t.Errorf("cambio %d sin %q: %s", i, want, scripts[i])
+		}
+	}
+	for i, want := range []string{"WmiSetBrightness(1,80)", "WmiSetBrightness(1,40)", "WmiSetBrightness(1,70)"} {
+		if !strings.Contains(scripts[i], want) {
+			t.Errorf("cambio %d sin %q: %s", i, want, scripts[i])
+		}
+	}
+}
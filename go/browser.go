@@ -0,0 +1,206 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// errDefaultBrowserNeedsUI es el error de set_default_browser en macOS y
+// Windows, que solo permiten cambiar el navegador predeterminado con la
+// confirmación del usuario
+var errDefaultBrowserNeedsUI = errors.New("cambiar el navegador predeterminado requiere la confirmación del usuario en la interfaz del sistema")
+
+type DefaultBrowserOutput struct {
+	Name string `json:"name" jsonschema:"Nombre del navegador (ej: 'Firefox')"`
+	Path string `json:"path,omitempty" jsonschema:"Ruta del ejecutable del navegador, si se pudo determinar"`
+	ID   string `json:"id" jsonschema:"Identificador del sistema: fichero .desktop en Linux, bundle id en macOS, ProgId en Windows"`
+}
+
+// getDefaultBrowser devuelve el navegador con el que se abren las URLs http(s)
+func getDefaultBrowser() (DefaultBrowserOutput, error) {
+	switch currentPlatform() {
+	case platformWindows, platformWSL:
+		return defaultBrowserWindows()
+	case platformMac:
+		return defaultBrowserMac()
+	}
+	return defaultBrowserLinux()
+}
+
+// defaultBrowserLinux consulta el navegador con xdg-settings o, si no está,
+// con el manejador de x-scheme-handler/https de xdg-mime, y toma el nombre y
+// el ejecutable de su .desktop
+func defaultBrowserLinux() (DefaultBrowserOutput, error) {
+	var output []byte
+	var err error
+	if commandExists("xdg-settings")() {
+		output, err = exec.Command("xdg-settings", "get", "default-web-browser").Output()
+	} else {
+		output, err = exec.Command("xdg-mime", "query", "default", "x-scheme-handler/https").Output()
+	}
+	if err != nil {
+		return DefaultBrowserOutput{}, fmt.Errorf("error al consultar el navegador predeterminado: %w", err)
+	}
+	id := strings.TrimSpace(string(output))
+	if id == "" {
+		return DefaultBrowserOutput{}, errors.New("no hay ningún navegador predeterminado configurado")
+	}
+
+	browser := DefaultBrowserOutput{Name: strings.TrimSuffix(id, ".desktop"), ID: id}
+	if _, entry := findDesktopEntry(id); entry != nil {
+		browser.Name = entry["Name"]
+		if argv := desktopExecArgs(entry["Exec"]); len(argv) > 0 {
+			if path, err := exec.LookPath(argv[0]); err == nil {
+				browser.Path = path
+			}
+		}
+	}
+	return browser, nil
+}
+
+// defaultBrowserMac lee el manejador del esquema https en las preferencias
+// de LaunchServices (Safari si no hay ninguno) y busca la aplicación con
+// Spotlight
+func defaultBrowserMac() (DefaultBrowserOutput, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return DefaultBrowserOutput{}, err
+	}
+	plist := filepath.Join(home, "Library", "Preferences", "com.apple.LaunchServices", "com.apple.launchservices.secure.plist")
+	bundleID := "com.apple.safari"
+	if output, err := exec.Command("plutil", "-convert", "json", "-o", "-", plist).Output(); err == nil {
+		var prefs struct {
+			LSHandlers []struct {
+				LSHandlerURLScheme string
+				LSHandlerRoleAll   string
+			}
+		}
+		if err := json.Unmarshal(output, &prefs); err != nil {
+			return DefaultBrowserOutput{}, fmt.Errorf("error al leer las preferencias de LaunchServices: %w", err)
+		}
+		for _, handler := range prefs.LSHandlers {
+			if handler.LSHandlerURLScheme == "https" && handler.LSHandlerRoleAll != "" {
+				bundleID = handler.LSHandlerRoleAll
+				break
+			}
+		}
+	}
+
+	browser := DefaultBrowserOutput{Name: bundleID, ID: bundleID}
+	output, err := exec.Command("mdfind", fmt.Sprintf("kMDItemCFBundleIdentifier == '%s'", bundleID)).Output()
+	if err != nil {
+		return browser, nil
+	}
+	app, _, _ := strings.Cut(strings.TrimSpace(string(output)), "\n")
+	if app == "" {
+		return browser, nil
+	}
+	browser.Name = strings.TrimSuffix(filepath.Base(app), ".app")
+	browser.Path = app
+	if exe, err := exec.Command("defaults", "read", filepath.Join(app, "Contents", "Info"), "CFBundleExecutable").Output(); err == nil {
+		browser.Path = filepath.Join(app, "Contents", "MacOS", strings.TrimSpace(string(exe)))
+	}
+	return browser, nil
+}
+
+// defaultBrowserWindows lee el ProgId de UserChoice para https y, de su
+// clave en HKEY_CLASSES_ROOT, el nombre de la aplicación y la orden de
+// apertura
+func defaultBrowserWindows() (DefaultBrowserOutput, error) {
+	script := `$progId = (Get-ItemProperty 'HKCU:\Software\Microsoft\Windows\Shell\Associations\UrlAssociations\https\UserChoice' -ErrorAction SilentlyContinue).ProgId
+if (-not $progId) { exit }
+$root = "Registry::HKEY_CLASSES_ROOT\$progId"
+$command = (Get-ItemProperty "$root\shell\open\command" -ErrorAction SilentlyContinue).'(default)'
+if ($command -match '^"([^"]+)"') { $exe = $Matches[1] } else { $exe = "$command".Split(' ')[0] }
+$name = (Get-ItemProperty "$root\Application" -ErrorAction SilentlyContinue).ApplicationName
+if ((-not $name -or $name.StartsWith('@')) -and $exe -and (Test-Path $exe)) { $name = (Get-Item $exe).VersionInfo.FileDescription }
+if (-not $name) { $name = $progId }
+ConvertTo-Json -Compress @{ name = "$name"; path = "$exe"; id = $progId }`
+	output, err := runPowerShell(script)
+	if err != nil {
+		return DefaultBrowserOutput{}, fmt.Errorf("error al consultar el navegador predeterminado: %w", err)
+	}
+	if output == "" {
+		return DefaultBrowserOutput{}, errors.New("no hay ningún navegador predeterminado configurado")
+	}
+	var browser DefaultBrowserOutput
+	if err := json.Unmarshal([]byte(output), &browser); err != nil {
+		return DefaultBrowserOutput{}, fmt.Errorf("respuesta de PowerShell no válida: %w", err)
+	}
+	return browser, nil
+}
+
+// setDefaultBrowser cambia el navegador predeterminado con xdg-settings.
+// browser es el identificador del .desktop o su nombre visible, y tiene que
+// ser un navegador (categoría WebBrowser o manejador de http).
+func setDefaultBrowser(browser string) (DefaultBrowserOutput, error) {
+	switch currentPlatform() {
+	case platformWindows, platformWSL:
+		return DefaultBrowserOutput{}, fmt.Errorf("%w (Configuración > Aplicaciones > Aplicaciones predeterminadas)", errDefaultBrowserNeedsUI)
+	case platformMac:
+		return DefaultBrowserOutput{}, fmt.Errorf("%w (Ajustes del Sistema > Escritorio y Dock > Navegador web por omisión)", errDefaultBrowserNeedsUI)
+	}
+
+	browser = strings.TrimSpace(browser)
+	if browser == "" {
+		return DefaultBrowserOutput{}, errors.New("indica el navegador")
+	}
+	if !commandExists("xdg-settings")() {
+		return DefaultBrowserOutput{}, errors.New("xdg-settings no está instalado (paquete xdg-utils)")
+	}
+	file, entry := findDesktopEntry(browser)
+	if file == "" || !strings.Contains(entry["Categories"], "WebBrowser") && !strings.Contains(entry["MimeType"], "x-scheme-handler/http") {
+		return DefaultBrowserOutput{}, fmt.Errorf("no se encontró ningún navegador '%s' (usa list_installed_apps para ver los instalados)", browser)
+	}
+	if output, err := exec.Command("xdg-settings", "set", "default-web-browser", filepath.Base(file)).CombinedOutput(); err != nil {
+		if msg := strings.TrimSpace(string(output)); msg != "" {
+			return DefaultBrowserOutput{}, fmt.Errorf("error al ejecutar xdg-settings: %w: %s", err, msg)
+		}
+		return DefaultBrowserOutput{}, fmt.Errorf("error al ejecutar xdg-settings: %w", err)
+	}
+	return defaultBrowserLinux()
+}
+
+// describeBrowser resume el navegador para el mensaje de resultado
+func describeBrowser(browser DefaultBrowserOutput) string {
+	if browser.Path == "" {
+		return fmt.Sprintf("%s (%s)", browser.Name, browser.ID)
+	}
+	return fmt.Sprintf("%s (%s)\n💻 %s", browser.Name, browser.ID, browser.Path)
+}
+
+func HandleGetDefaultBrowser(ctx context.Context, req *mcp.CallToolRequest, input struct{}) (*mcp.CallToolResult, DefaultBrowserOutput, error) {
+	browser, err := getDefaultBrowser()
+	if err != nil {
+		return nil, DefaultBrowserOutput{}, fmt.Errorf("❌ Error al obtener el navegador predeterminado: %w", err)
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: "🌐 Navegador predeterminado: " + describeBrowser(browser)},
+		},
+	}, browser, nil
+}
+
+type SetDefaultBrowserInput struct {
+	Browser string `json:"browser" jsonschema:"Navegador: identificador del .desktop (ej: 'firefox', 'org.mozilla.firefox') o nombre visible (ej: 'Google Chrome'). Solo Linux"`
+}
+
+func HandleSetDefaultBrowser(ctx context.Context, req *mcp.CallToolRequest, input SetDefaultBrowserInput) (*mcp.CallToolResult, DefaultBrowserOutput, error) {
+	browser, err := setDefaultBrowser(input.Browser)
+	if err != nil {
+		return nil, DefaultBrowserOutput{}, fmt.Errorf("❌ Error al cambiar el navegador predeterminado: %w", err)
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: "🌐 Navegador predeterminado cambiado a " + describeBrowser(browser)},
+		},
+	}, browser, nil
+}
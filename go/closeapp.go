@@ -0,0 +1,338 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// closeAppGrace es lo que se espera a que una aplicación se cierre sola
+// antes de forzarla o de informar de que sigue abierta
+const closeAppGrace = 3 * time.Second
+
+// protectedProcessesFile es el fichero de configuración con nombres de
+// procesos que close_app tampoco puede cerrar, además de los integrados
+const protectedProcessesFile = "protected_processes.json"
+
+// builtinProtectedProcesses son procesos del sistema que close_app no cierra
+// ni con force, porque cerrarlos cuelga o termina la sesión. La
+// configuración puede ampliar la lista, pero no quitar ninguno.
+var builtinProtectedProcesses = []string{
+	// Windows
+	"explorer", "winlogon", "wininit", "csrss", "smss", "lsass", "services", "dwm",
+	// macOS
+	"finder", "loginwindow", "launchd", "windowserver", "dock",
+	// Linux
+	"systemd", "init",
+}
+
+// errProcessProtected indica que close_app se negó a cerrar un proceso protegido
+var errProcessProtected = errors.New("es un proceso protegido y no se puede cerrar")
+
+// psProtectedMarker es la marca con la que el script de closeAppWindows
+// indica que alguno de los procesos encontrados está protegido
+const psProtectedMarker = "PROTECTED"
+
+// processBaseName es el nombre base del ejecutable, sin ruta ni .exe y en
+// minúsculas, que es como se comparan los procesos protegidos
+func processBaseName(name string) string {
+	name = filepath.Base(strings.ReplaceAll(strings.TrimSpace(name), `\`, "/"))
+	return strings.ToLower(strings.TrimSuffix(name, filepath.Ext(name)))
+}
+
+// protectedProcessNames devuelve builtinProtectedProcesses y los de
+// protectedProcessesFile, con processBaseName
+func protectedProcessNames() ([]string, error) {
+	var extra []string
+	if err := loadJSONConfig(protectedProcessesFile, &extra); err != nil {
+		return nil, fmt.Errorf("error al leer %s: %w", protectedProcessesFile, err)
+	}
+	names := slices.Concat(builtinProtectedProcesses, extra)
+	for i, n := range names {
+		names[i] = processBaseName(n)
+	}
+	return names, nil
+}
+
+// protectedProcess indica si name está protegido, comparando su nombre base
+// con protectedProcessNames
+func protectedProcess(name string) (bool, error) {
+	names, err := protectedProcessNames()
+	if err != nil {
+		return false, err
+	}
+	return slices.Contains(names, processBaseName(name)), nil
+}
+
+// protectedPID indica si pid es el propio servidor o el proceso que lo
+// inició (normalmente el cliente MCP)
+func protectedPID(pid int) bool {
+	return pid == os.Getpid() || pid == os.Getppid()
+}
+
+// closeApp cierra una aplicación, primero de forma ordenada y, si force,
+// matándola si no se ha cerrado al cabo de closeAppGrace
+func closeApp(appName string, force bool) (string, error) {
+	if err := validateAppName(appName); err != nil {
+		return "", err
+	}
+	appName = strings.TrimSpace(appName)
+	allowedApps, err := loadAllowedApps()
+	if err != nil {
+		return "", err
+	}
+	if !appAllowed(allowedApps, appName) {
+		return "", fmt.Errorf("%w (%s)", errAppNotAllowed, allowedAppsFile)
+	}
+	protected, err := protectedProcess(appName)
+	if err != nil {
+		return "", err
+	}
+	if protected {
+		return "", fmt.Errorf("'%s' %w", appName, errProcessProtected)
+	}
+
+	switch currentPlatform() {
+	case platformWindows, platformWSL:
+		return closeAppWindows(appName, force)
+	case platformMac:
+		// Las aplicaciones con interfaz se cierran con AppleScript, como al
+		// elegir Salir; el resto de procesos, con señales
+		out, err := exec.Command("osascript", "-e", fmt.Sprintf("application %q is running", appName)).Output()
+		if err == nil && strings.TrimSpace(string(out)) == "true" {
+			return closeAppMac(appName, force)
+		}
+	}
+	return closeAppSignals(appName, force)
+}
+
+// closeAppWindows usa taskkill, que pide a las ventanas del proceso que se
+// cierren, y taskkill /F si hay que forzarlo
+func closeAppWindows(appName string, force bool) (string, error) {
+	name := strings.TrimSuffix(appName, filepath.Ext(appName))
+	// En WSL los pids del servidor no son de Windows y no hay nada que excluir
+	self, parent := os.Getpid(), os.Getppid()
+	if currentPlatform() == platformWSL {
+		self, parent = -1, -1
+	}
+	protected, err := protectedProcessNames()
+	if err != nil {
+		return "", err
+	}
+	quoted := make([]string, len(protected))
+	for i, p := range protected {
+		quoted[i] = "'" + strings.ReplaceAll(p, "'", "''") + "'"
+	}
+	// Get-Process -Name admite comodines: con "*" cerraría explorer o csrss.
+	// Se compara el nombre exacto y se revisa cada proceso encontrado contra
+	// la lista de protegidos antes de enviar nada.
+	script := fmt.Sprintf(`$name = '%[1]s'
+$protected = @(%[6]s)
+$p = @(Get-Process -ErrorAction SilentlyContinue | Where-Object { $_.ProcessName -eq $name -and $_.Id -ne %[4]d -and $_.Id -ne %[5]d })
+if ($p.Count -eq 0) { exit }
+$blocked = @($p | Where-Object { $protected -contains $_.ProcessName.ToLower() } | ForEach-Object { $_.ProcessName } | Select-Object -Unique)
+if ($blocked.Count -gt 0) { '%[7]s|' + ($blocked -join ', '); exit }
+$p | ForEach-Object { taskkill /PID $_.Id 2>&1 | Out-Null }
+$left = @()
+for ($i = 0; $i -lt %[2]d; $i++) {
+  Start-Sleep -Milliseconds 100
+  $left = @($p | Where-Object { -not $_.HasExited })
+  if ($left.Count -eq 0) { break }
+}
+if ($left.Count -gt 0 -and $%[3]t) { $left | ForEach-Object { taskkill /F /PID $_.Id 2>&1 | Out-Null } }
+"$($p.Count)|$($left.Count)"`, strings.ReplaceAll(name, "'", "''"), closeAppGrace.Milliseconds()/100, force, self, parent,
+		strings.Join(quoted, ","), psProtectedMarker)
+	output, err := runPowerShell(script)
+	if err != nil {
+		return "", fmt.Errorf("error al ejecutar taskkill: %w", err)
+	}
+	total, left, ok := strings.Cut(output, "|")
+	if ok && total == psProtectedMarker {
+		return "", fmt.Errorf("%w: %s", errProcessProtected, left)
+	}
+	if !ok {
+		return "", fmt.Errorf("no hay ningún proceso %s.exe en ejecución", name)
+	}
+	signaled, _ := strconv.Atoi(total)
+	remaining, _ := strconv.Atoi(left)
+	return describeClose(appName, signaled, remaining, force, "taskkill /F"), nil
+}
+
+// closeAppMac pide a la aplicación que se cierre con AppleScript y, si force
+// y sigue abierta, mata sus procesos
+func closeAppMac(appName string, force bool) (string, error) {
+	if _, err := runAppleScript(fmt.Sprintf("tell application %q to quit", appName)); err != nil {
+		return "", fmt.Errorf("AppleScript (%s): %w", appName, err)
+	}
+	running := func() bool {
+		out, err := exec.Command("osascript", "-e", fmt.Sprintf("application %q is running", appName)).Output()
+		return err == nil && strings.TrimSpace(string(out)) == "true"
+	}
+	for deadline := time.Now().Add(closeAppGrace); running() && time.Now().Before(deadline); {
+		time.Sleep(100 * time.Millisecond)
+	}
+	if !running() {
+		return fmt.Sprintf("🛑 Aplicación '%s' cerrada", appName), nil
+	}
+	if !force {
+		return fmt.Sprintf("⚠️ Se pidió a '%s' que se cerrara, pero sigue abierta (puede estar esperando a que guardes algo); usa force=true para forzar el cierre", appName), nil
+	}
+	pids, err := findProcesses(appName)
+	if err != nil {
+		return "", err
+	}
+	pids = slices.DeleteFunc(pids, protectedPID)
+	for _, pid := range pids {
+		if p, err := os.FindProcess(pid); err == nil {
+			p.Kill()
+		}
+	}
+	return fmt.Sprintf("🛑 Aplicación '%s' cerrada a la fuerza (SIGKILL a %d procesos)", appName, len(pids)), nil
+}
+
+// closeAppSignals envía SIGTERM a los procesos con ese nombre y SIGKILL a
+// los que sigan vivos tras closeAppGrace si force
+func closeAppSignals(appName string, force bool) (string, error) {
+	pids, err := findProcesses(appName)
+	if err != nil {
+		return "", err
+	}
+	if len(pids) == 0 {
+		return "", fmt.Errorf("no hay ningún proceso llamado %s en ejecución", appName)
+	}
+	if pids = slices.DeleteFunc(pids, protectedPID); len(pids) == 0 {
+		return "", fmt.Errorf("'%s' %w: es el propio servidor o el cliente que lo inició", appName, errProcessProtected)
+	}
+	// findProcesses también compara argv[0]: se revisa el nombre real de cada
+	// proceso encontrado, no solo el que se pidió
+	protected, err := protectedProcessNames()
+	if err != nil {
+		return "", err
+	}
+	for _, pid := range pids {
+		if name := processName(strconv.Itoa(pid)); name != "" && slices.Contains(protected, processBaseName(name)) {
+			return "", fmt.Errorf("'%s' (%d, %s) %w", appName, pid, name, errProcessProtected)
+		}
+	}
+	var procs []*os.Process
+	for _, pid := range pids {
+		p, err := os.FindProcess(pid)
+		if err != nil {
+			continue
+		}
+		if err := p.Signal(syscall.SIGTERM); err != nil {
+			if errors.Is(err, os.ErrProcessDone) {
+				continue
+			}
+			return "", fmt.Errorf("error al enviar SIGTERM a %d: %w", pid, err)
+		}
+		procs = append(procs, p)
+	}
+
+	var alive []*os.Process
+	for deadline := time.Now().Add(closeAppGrace); ; time.Sleep(100 * time.Millisecond) {
+		alive = alive[:0]
+		for _, p := range procs {
+			// La señal 0 solo comprueba si el proceso existe
+			if p.Signal(syscall.Signal(0)) == nil {
+				alive = append(alive, p)
+			}
+		}
+		if len(alive) == 0 || time.Now().After(deadline) {
+			break
+		}
+	}
+	if force {
+		for _, p := range alive {
+			p.Kill()
+		}
+	}
+	return describeClose(appName, len(procs), len(alive), force, "SIGKILL"), nil
+}
+
+// describeClose resume el cierre: cuántos procesos recibieron la petición y
+// cuántos seguían abiertos al terminar la espera
+func describeClose(appName string, signaled, remaining int, force bool, forceMethod string) string {
+	result := fmt.Sprintf("🛑 Cierre de '%s' solicitado a %d procesos", appName, signaled)
+	switch {
+	case remaining == 0:
+		result += "; todos se han cerrado"
+	case force:
+		result += fmt.Sprintf("; %d no respondían y se cerraron a la fuerza (%s)", remaining, forceMethod)
+	default:
+		result += fmt.Sprintf("\n⚠️ %d siguen abiertos; usa force=true para forzar el cierre", remaining)
+	}
+	return result
+}
+
+// findProcesses devuelve los pids de los procesos cuyo nombre coincide con
+// name sin distinguir mayúsculas, salvo el propio servidor. En Linux se
+// recorre /proc comparando con comm (recortado a 15 caracteres por el
+// kernel) y con el ejecutable de argv[0]; en macOS se usa ps.
+func findProcesses(name string) ([]int, error) {
+	self := os.Getpid()
+	var pids []int
+	if currentPlatform() == platformMac {
+		output, err := exec.Command("ps", "-axo", "pid=,comm=").Output()
+		if err != nil {
+			return nil, fmt.Errorf("error al ejecutar ps: %w", err)
+		}
+		for _, line := range strings.Split(string(output), "\n") {
+			pidField, comm, ok := strings.Cut(strings.TrimSpace(line), " ")
+			pid, err := strconv.Atoi(pidField)
+			if ok && err == nil && pid != self && strings.EqualFold(filepath.Base(strings.TrimSpace(comm)), name) {
+				pids = append(pids, pid)
+			}
+		}
+		return pids, nil
+	}
+
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return nil, fmt.Errorf("error al leer /proc: %w", err)
+	}
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil || pid == self {
+			continue
+		}
+		match := strings.EqualFold(processName(entry.Name()), name)
+		if !match {
+			if cmdline, err := os.ReadFile(filepath.Join("/proc", entry.Name(), "cmdline")); err == nil {
+				argv0, _, _ := strings.Cut(string(cmdline), "\x00")
+				match = argv0 != "" && strings.EqualFold(filepath.Base(argv0), name)
+			}
+		}
+		if match {
+			pids = append(pids, pid)
+		}
+	}
+	return pids, nil
+}
+
+type CloseAppInput struct {
+	AppName string `json:"app_name" jsonschema:"Nombre de la aplicación o del proceso (ej: 'Spotify', 'firefox'; en Windows el ejecutable, con o sin .exe)"`
+	Force   bool   `json:"force,omitempty" jsonschema:"Si es true, fuerza el cierre de los procesos que no se cierren solos en unos segundos"`
+}
+
+func HandleCloseApp(ctx context.Context, req *mcp.CallToolRequest, input CloseAppInput) (*mcp.CallToolResult, any, error) {
+	result, err := closeApp(input.AppName, input.Force)
+	if err != nil {
+		return nil, nil, fmt.Errorf("❌ Error al cerrar la aplicación: %w", err)
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: result},
+		},
+	}, nil, nil
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// closeCommands son las órdenes con las que close_app busca o cierra procesos
+var closeCommands = []string{"powershell", "powershell.exe", "taskkill", "osascript", "ps", "kill", "pkill", "killall"}
+
+func TestProtectedProcessBuiltin(t *testing.T) {
+	fakeCommands(t)
+	for _, name := range builtinProtectedProcesses {
+		// Con ruta, extensión y otras mayúsculas sigue siendo el mismo proceso
+		for _, variant := range []string{name, strings.ToUpper(name), name + ".exe", "/usr/bin/" + name, `C:\Windows\` + name + ".exe"} {
+			protected, err := protectedProcess(variant)
+			if err != nil || !protected {
+				t.Errorf("protectedProcess(%q) = %v, %v; se esperaba true", variant, protected, err)
+			}
+		}
+	}
+	for _, name := range []string{"firefox", "explorer2", "systemd-resolved", "docker"} {
+		if protected, err := protectedProcess(name); err != nil || protected {
+			t.Errorf("protectedProcess(%q) = %v, %v; se esperaba false", name, protected, err)
+		}
+	}
+}
+
+func TestProtectedProcessConfig(t *testing.T) {
+	fakeCommands(t)
+	writeTestConfig(t, protectedProcessesFile, `["sshd", "Xorg.exe"]`)
+	for _, name := range []string{"sshd", "xorg", "explorer"} {
+		if protected, err := protectedProcess(name); err != nil || !protected {
+			t.Errorf("protectedProcess(%q) = %v, %v; se esperaba true", name, protected, err)
+		}
+	}
+}
+
+// En Linux closeApp enviaría señales sin ejecutar nada, así que se prueba en
+// Windows y macOS, donde cualquier cierre pasa por una orden externa
+func TestCloseAppRefusesProtectedBeforeKill(t *testing.T) {
+	for _, goos := range []string{"windows", "darwin"} {
+		setOSType(t, goos)
+		executed := fakeCommands(t, closeCommands...)
+		for _, name := range builtinProtectedProcesses {
+			for _, force := range []bool{false, true} {
+				_, err := closeApp(name, force)
+				if !errors.Is(err, errProcessProtected) {
+					t.Errorf("%s: closeApp(%q, %v) = %v; se esperaba errProcessProtected", goos, name, force, err)
+				}
+			}
+		}
+		if commands := executed(); len(commands) > 0 {
+			t.Errorf("%s: se ejecutaron órdenes con procesos protegidos: %q", goos, commands)
+		}
+	}
+}
+
+func TestCloseAppWindowsWildcard(t *testing.T) {
+	setOSType(t, "windows")
+	fakeCommands(t)
+	script := fakePowerShell(t)
+	// Get-Process -Name '*' cerraría todos los procesos; el nombre se compara
+	// con -eq y llega como literal
+	for _, name := range []string{"*", "explor*", "?sass"} {
+		closeAppWindows(name, true)
+		got := script()
+		if strings.Contains(got, "-Name") || !strings.Contains(got, "$_.ProcessName -eq $name") {
+			t.Errorf("closeAppWindows(%q) no compara el nombre exacto: %s", name, got)
+		}
+		checkPSLiteral(t, got, name)
+		for _, p := range builtinProtectedProcesses {
+			if literals, _ := psSingleQuoted(got); !slices.Contains(literals, p) {
+				t.Errorf("closeAppWindows(%q) no comprueba el proceso protegido %s: %s", name, p, got)
+			}
+		}
+	}
+
+	// Si alguno de los procesos encontrados está protegido no se cierra nada
+	t.Setenv("FAKE_POWERSHELL_OUTPUT", psProtectedMarker+"|explorer")
+	if _, err := closeAppWindows("*", true); !errors.Is(err, errProcessProtected) || !strings.Contains(err.Error(), "explorer") {
+		t.Errorf("closeAppWindows con un proceso protegido = %v; se esperaba errProcessProtected con explorer", err)
+	}
+}
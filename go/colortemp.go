@@ -0,0 +1,225 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Rango admitido de temperatura de color y valor neutro (luz de día)
+const (
+	minColorTemperature     = 1000
+	maxColorTemperature     = 10000
+	neutralColorTemperature = 6500
+)
+
+// kelvinToRGB convierte una temperatura de color en factores de gamma (0-1)
+// por canal con la aproximación de Tanner Helland del cuerpo negro, válida
+// entre 1000 K y 40000 K
+func kelvinToRGB(kelvin int) (r, g, b float64) {
+	temp := float64(kelvin) / 100
+
+	if temp <= 66 {
+		r = 255
+		g = 99.4708025861*math.Log(temp) - 161.1195681661
+	} else {
+		r = 329.698727446 * math.Pow(temp-60, -0.1332047592)
+		g = 288.1221695283 * math.Pow(temp-60, -0.0755148492)
+	}
+	switch {
+	case temp >= 66:
+		b = 255
+	case temp <= 19:
+		b = 0
+	default:
+		b = 138.5177312231*math.Log(temp-10) - 305.0447927307
+	}
+
+	clamp := func(v float64) float64 {
+		return math.Max(0, math.Min(255, v)) / 255
+	}
+	return clamp(r), clamp(g), clamp(b)
+}
+
+// gammaForKelvin devuelve el valor de `xrandr --gamma` para una temperatura.
+// xrandr no admite gamma 0, así que cada canal se limita a un mínimo de 0.1.
+func gammaForKelvin(kelvin int) string {
+	if kelvin == neutralColorTemperature {
+		return "1:1:1"
+	}
+	r, g, b := kelvinToRGB(kelvin)
+	channel := func(v float64) string {
+		return strconv.FormatFloat(math.Max(v, 0.1), 'f', 2, 64)
+	}
+	return channel(r) + ":" + channel(g) + ":" + channel(b)
+}
+
+// setColorTemperature aplica una temperatura de color a todas las pantallas
+// y devuelve una descripción con el método usado
+func setColorTemperature(kelvin int) (string, error) {
+	if kelvin < minColorTemperature || kelvin > maxColorTemperature {
+		return "", fmt.Errorf("temperatura %dK fuera de rango (%d-%d)", kelvin, minColorTemperature, maxColorTemperature)
+	}
+	if err := checkGraphicalSession(); err != nil {
+		return "", err
+	}
+
+	var method string
+	var err error
+	switch {
+	case osType == "windows" || isWSL():
+		method, err = setColorTemperatureWindows(kelvin)
+	case osType == "darwin":
+		method, err = setColorTemperatureMac(kelvin)
+	default:
+		method, err = setColorTemperatureLinux(kelvin)
+	}
+	if err != nil {
+		return "", err
+	}
+	if kelvin == neutralColorTemperature {
+		return fmt.Sprintf("✅ Temperatura de color restablecida a %dK (%s)", kelvin, method), nil
+	}
+	return fmt.Sprintf("✅ Temperatura de color ajustada a %dK (%s)", kelvin, method), nil
+}
+
+// setColorTemperatureLinux prueba gammastep y redshift, que gestionan la
+// rampa de gamma por sí mismos, y recurre a xrandr --gamma en X11
+func setColorTemperatureLinux(kelvin int) (string, error) {
+	wayland := detectLinuxSession().Wayland
+	var failures []string
+	for _, tool := range []string{"gammastep", "redshift"} {
+		if tool == "redshift" && wayland {
+			// redshift solo funciona en X11
+			continue
+		}
+		if !commandExists(tool)() {
+			continue
+		}
+		args := []string{"-P", "-O", strconv.Itoa(kelvin)}
+		if kelvin == neutralColorTemperature {
+			args = []string{"-x"}
+		}
+		if err := exec.Command(tool, args...).Run(); err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", tool, err))
+			continue
+		}
+		return tool, nil
+	}
+
+	if !wayland && commandExists("xrandr")() {
+		outputs, err := listXrandrOutputs()
+		if err != nil {
+			return "", err
+		}
+		if len(outputs) == 0 {
+			return "", errors.New("no se encontraron displays conectados")
+		}
+		gamma := gammaForKelvin(kelvin)
+		for _, o := range outputs {
+			if err := exec.Command("xrandr", "--output", o.Name, "--gamma", gamma).Run(); err != nil {
+				invalidateXrandrCache()
+				return "", fmt.Errorf("xrandr en %s: %w", o.Name, err)
+			}
+		}
+		return fmt.Sprintf("gamma %s vía xrandr", gamma), nil
+	}
+
+	if len(failures) > 0 {
+		return "", errors.New(strings.Join(failures, "; "))
+	}
+	if wayland {
+		return "", fmt.Errorf("no hay ningún método de temperatura de color para Wayland (compositor: %s); instala gammastep", detectLinuxSession().Compositor())
+	}
+	return "", errors.New("no hay ningún método de temperatura de color disponible; instala gammastep, redshift o xrandr")
+}
+
+// setColorTemperatureMac controla Night Shift con la herramienta nightlight
+// (brew install smudge/smudge/nightlight). Night Shift solo calienta la
+// imagen: su intensidad 0-100 se reparte linealmente entre 6500K y 2700K.
+func setColorTemperatureMac(kelvin int) (string, error) {
+	if !commandExists("nightlight")() {
+		return "", errors.New("Night Shift no tiene API de AppleScript; instala la herramienta nightlight (brew install smudge/smudge/nightlight)")
+	}
+	if kelvin >= neutralColorTemperature {
+		if err := exec.Command("nightlight", "off").Run(); err != nil {
+			return "", fmt.Errorf("error al desactivar Night Shift: %w", err)
+		}
+		return "Night Shift desactivado", nil
+	}
+	warmth := (neutralColorTemperature - max(kelvin, 2700)) * 100 / (neutralColorTemperature - 2700)
+	if err := exec.Command("nightlight", "temp", strconv.Itoa(warmth)).Run(); err != nil {
+		return "", fmt.Errorf("error al ajustar Night Shift: %w", err)
+	}
+	if err := exec.Command("nightlight", "on").Run(); err != nil {
+		return "", fmt.Errorf("error al activar Night Shift: %w", err)
+	}
+	return fmt.Sprintf("Night Shift al %d%%", warmth), nil
+}
+
+// psGammaRamp define la clase [GammaRamp] para aplicar una rampa de gamma
+// por canal con SetDeviceGammaRamp sobre el escritorio
+const psGammaRamp = `Add-Type -TypeDefinition @'
+using System;
+using System.Runtime.InteropServices;
+public class GammaRamp {
+  [DllImport("user32.dll")] static extern IntPtr GetDC(IntPtr hWnd);
+  [DllImport("user32.dll")] static extern int ReleaseDC(IntPtr hWnd, IntPtr hdc);
+  [DllImport("gdi32.dll")] static extern bool SetDeviceGammaRamp(IntPtr hdc, ushort[] ramp);
+  public static bool Apply(double r, double g, double b) {
+    var ramp = new ushort[768];
+    for (int i = 0; i < 256; i++) {
+      ramp[i] = (ushort)Math.Min(65535, i * 256 * r);
+      ramp[256 + i] = (ushort)Math.Min(65535, i * 256 * g);
+      ramp[512 + i] = (ushort)Math.Min(65535, i * 256 * b);
+    }
+    IntPtr hdc = GetDC(IntPtr.Zero);
+    bool ok = SetDeviceGammaRamp(hdc, ramp);
+    ReleaseDC(IntPtr.Zero, hdc);
+    return ok;
+  }
+}
+'@
+`
+
+// setColorTemperatureWindows aplica la temperatura con una rampa de gamma.
+// La luz nocturna de Windows guarda su estado en un blob binario del
+// registro sin API pública, así que se ajusta la gamma directamente.
+func setColorTemperatureWindows(kelvin int) (string, error) {
+	r, g, b := 1.0, 1.0, 1.0
+	if kelvin != neutralColorTemperature {
+		r, g, b = kelvinToRGB(kelvin)
+	}
+	script := psGammaRamp + fmt.Sprintf("if (-not [GammaRamp]::Apply(%.3f, %.3f, %.3f)) { throw 'SetDeviceGammaRamp rechazó la rampa (Windows limita las temperaturas extremas)' }", r, g, b)
+	if _, err := runPowerShell(script); err != nil {
+		return "", err
+	}
+	return "rampa de gamma vía SetDeviceGammaRamp", nil
+}
+
+type SetColorTemperatureInput struct {
+	Kelvin int  `json:"kelvin,omitempty" jsonschema:"Temperatura de color en Kelvin (1000-10000). 6500 es neutra, 3400 es cálida (modo nocturno)"`
+	Reset  bool `json:"reset,omitempty" jsonschema:"Si es true vuelve a la temperatura neutra (6500K) e ignora kelvin"`
+}
+
+func HandleSetColorTemperature(ctx context.Context, req *mcp.CallToolRequest, input SetColorTemperatureInput) (*mcp.CallToolResult, any, error) {
+	kelvin := input.Kelvin
+	if input.Reset {
+		kelvin = neutralColorTemperature
+	}
+	result, err := setColorTemperature(kelvin)
+	if err != nil {
+		return nil, nil, fmt.Errorf("❌ Error al ajustar la temperatura de color: %w", err)
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: result},
+		},
+	}, nil, nil
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestKelvinToRGB(t *testing.T) {
+	// Valores de referencia de la tabla de Tanner Helland, en 0-255
+	tests := []struct {
+		kelvin  int
+		r, g, b float64
+	}{
+		{1000, 255, 68, 0},
+		{2700, 255, 167, 87},
+		{6500, 255, 254, 250},
+		{10000, 202, 218, 255},
+	}
+	for _, tc := range tests {
+		r, g, b := kelvinToRGB(tc.kelvin)
+		for _, c := range []struct {
+			name      string
+			got, want float64
+		}{{"r", r, tc.r / 255}, {"g", g, tc.g / 255}, {"b", b, tc.b / 255}} {
+			if math.Abs(c.got-c.want) > 0.01 {
+				t.Errorf("kelvinToRGB(%d).%s = %.3f; se esperaba %.3f", tc.kelvin, c.name, c.got, c.want)
+			}
+		}
+	}
+}
+
+func TestKelvinToRGBClamp(t *testing.T) {
+	// Por debajo de 1000 K el verde de la fórmula es negativo y se queda en 0
+	if _, g, b := kelvinToRGB(500); g != 0 || b != 0 {
+		t.Errorf("kelvinToRGB(500) = g %v, b %v; se esperaba 0 y 0", g, b)
+	}
+	// Hasta 1900 K no hay azul, y desde 6600 K el rojo deja de ser el máximo
+	if _, _, b := kelvinToRGB(1900); b != 0 {
+		t.Errorf("kelvinToRGB(1900).b = %v; se esperaba 0", b)
+	}
+	if r, g, b := kelvinToRGB(6600); r != 1 || g != 1 || b != 1 {
+		t.Errorf("kelvinToRGB(6600) = %v, %v, %v; se esperaba 1, 1, 1", r, g, b)
+	}
+	for kelvin := 100; kelvin <= 40000; kelvin += 100 {
+		r, g, b := kelvinToRGB(kelvin)
+		for _, v := range []float64{r, g, b} {
+			if v < 0 || v > 1 || math.IsNaN(v) {
+				t.Fatalf("kelvinToRGB(%d) = %v, %v, %v; fuera de [0, 1]", kelvin, r, g, b)
+			}
+		}
+	}
+}
+
+func TestGammaForKelvin(t *testing.T) {
+	tests := []struct {
+		kelvin int
+		want   string
+	}{
+		{neutralColorTemperature, "1:1:1"},
+		// xrandr no admite gamma 0: el azul se queda en 0.10
+		{minColorTemperature, "1.00:0.27:0.10"},
+		{2700, "1.00:0.65:0.34"},
+		{maxColorTemperature, "0.79:0.86:1.00"},
+	}
+	for _, tc := range tests {
+		if got := gammaForKelvin(tc.kelvin); got != tc.want {
+			t.Errorf("gammaForKelvin(%d) = %q; se esperaba %q", tc.kelvin, got, tc.want)
+		}
+	}
+}
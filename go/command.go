@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// errRequiresAdmin indica que el sistema rechazó una orden por falta de
+// privilegios, para distinguirlo de un fallo de la propia orden
+var errRequiresAdmin = errors.New("la orden requiere privilegios de administrador")
+
+// permissionDeniedRegexp reconoce los mensajes de falta de privilegios de
+// nmcli, bluetoothctl, rfkill, networksetup, netsh, los cmdlets PnP y el
+// resto de órdenes del sistema
+var permissionDeniedRegexp = regexp.MustCompile(`(?i)not authorized|permission denied|operation not permitted|requires elevation|run as administrator|access is denied|0x80070005|org\.bluez\.Error\.NotPermitted`)
+
+// systemCommandTimeout limita cada orden del sistema, para que una
+// herramienta que se queda esperando no bloquee la llamada
+const systemCommandTimeout = 10 * time.Second
+
+// runSystemCommand ejecuta una orden con la configuración regional C y
+// devuelve su salida combinada. Si falla por falta de privilegios el error
+// envuelve errRequiresAdmin.
+func runSystemCommand(name string, args ...string) (string, error) {
+	return runSystemCommandTimeout(systemCommandTimeout, name, args...)
+}
+
+// runSystemCommandTimeout es runSystemCommand con otro límite de tiempo,
+// para órdenes que esperan a una conexión (VPN, punto de acceso)
+func runSystemCommandTimeout(timeout time.Duration, name string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.WaitDelay = time.Second
+	cmd.Env = append(os.Environ(), "LC_ALL=C")
+	output, err := cmd.CombinedOutput()
+	text := strings.TrimSpace(string(output))
+	if permissionDeniedRegexp.MatchString(text) {
+		return text, fmt.Errorf("%s: %w", filepath.Base(name), errRequiresAdmin)
+	}
+	if ctx.Err() != nil {
+		return text, fmt.Errorf("%s no respondió en %s", filepath.Base(name), timeout)
+	}
+	if err != nil && text != "" {
+		return text, fmt.Errorf("%w: %s", err, text)
+	}
+	return text, err
+}
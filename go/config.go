@@ -0,0 +1,55 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// configDirName es el subdirectorio de os.UserConfigDir donde el servidor
+// guarda su configuración y estado (p. ej. ~/.config/hardware-control)
+const configDirName = "hardware-control"
+
+// configPath devuelve la ruta de un fichero dentro del directorio de configuración
+func configPath(name string) (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, configDirName, name), nil
+}
+
+// loadJSONConfig lee un fichero JSON del directorio de configuración en v.
+// Si el fichero no existe v se deja sin modificar y no se devuelve error.
+func loadJSONConfig(name string, v any) error {
+	path, err := configPath(name)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// saveJSONConfig escribe v como JSON en el directorio de configuración,
+// creándolo si hace falta
+func saveJSONConfig(name string, v any) error {
+	path, err := configPath(name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
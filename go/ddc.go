@@ -0,0 +1,146 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// ddcMonitor representa un monitor detectado por ddcutil
+type ddcMonitor struct {
+	Number    int    // Número de display de ddcutil (0 si no soporta DDC/CI)
+	Bus       string // Bus I2C, p. ej. /dev/i2c-4
+	Connector string // Conector DRM sin el prefijo de tarjeta, p. ej. DP-1
+	Model     string // Fabricante:modelo:serie
+	Valid     bool   // false para las entradas "Invalid display"
+}
+
+// hasDDCUtil indica si ddcutil está instalado
+func hasDDCUtil() bool {
+	_, err := exec.LookPath("ddcutil")
+	return err == nil
+}
+
+// listDDCMonitors enumera los monitores con `ddcutil detect --brief`, incluidos
+// los que no soportan DDC/CI (marcados como Valid=false)
+func listDDCMonitors() ([]ddcMonitor, error) {
+	output, err := exec.Command("ddcutil", "detect", "--brief").Output()
+	if err != nil {
+		return nil, fmt.Errorf("error al ejecutar ddcutil detect: %w", err)
+	}
+	return parseDDCDetect(string(output)), nil
+}
+
+// parseDDCDetect interpreta la salida de `ddcutil detect --brief`:
+//
+//	Display 1
+//	   I2C bus:  /dev/i2c-4
+//	   DRM connector:  card0-DP-1
+//	   Monitor:  DEL:DELL U2720Q:ABC123
+//
+//	Invalid display
+//	   I2C bus:  /dev/i2c-5
+func parseDDCDetect(output string) []ddcMonitor {
+	var monitors []ddcMonitor
+	var current *ddcMonitor
+
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case strings.HasPrefix(line, "Display "):
+			n, _ := strconv.Atoi(strings.TrimPrefix(line, "Display "))
+			monitors = append(monitors, ddcMonitor{Number: n, Valid: true})
+			current = &monitors[len(monitors)-1]
+		case strings.HasPrefix(line, "Invalid display"):
+			monitors = append(monitors, ddcMonitor{})
+			current = &monitors[len(monitors)-1]
+		case current == nil:
+			continue
+		case strings.HasPrefix(line, "I2C bus:"):
+			current.Bus = strings.TrimSpace(strings.TrimPrefix(line, "I2C bus:"))
+		case strings.HasPrefix(line, "DRM connector:"):
+			connector := strings.TrimSpace(strings.TrimPrefix(line, "DRM connector:"))
+			if _, name, ok := strings.Cut(connector, "-"); ok && strings.HasPrefix(connector, "card") {
+				connector = name
+			}
+			current.Connector = connector
+		case strings.HasPrefix(line, "Monitor:"):
+			current.Model = strings.TrimSpace(strings.TrimPrefix(line, "Monitor:"))
+		}
+	}
+	return monitors
+}
+
+// readDDCBrightness lee la característica VCP 10 (brillo) de un monitor y
+// devuelve el valor actual y el máximo. La salida breve tiene la forma
+// "VCP 10 C 50 100".
+func readDDCBrightness(display int) (current, maximum int, err error) {
+	output, err := exec.Command("ddcutil", "--display", strconv.Itoa(display), "getvcp", "10", "--brief").Output()
+	if err != nil {
+		return 0, 0, fmt.Errorf("error al leer VCP 10: %w", err)
+	}
+	fields := strings.Fields(string(output))
+	if len(fields) < 5 || fields[0] != "VCP" {
+		return 0, 0, fmt.Errorf("salida inesperada de ddcutil: %q", string(output))
+	}
+	current, err = strconv.Atoi(fields[3])
+	if err != nil {
+		return 0, 0, fmt.Errorf("valor actual no válido en %q", string(output))
+	}
+	maximum, err = strconv.Atoi(fields[4])
+	if err != nil || maximum <= 0 {
+		return 0, 0, fmt.Errorf("valor máximo no válido en %q", string(output))
+	}
+	return current, maximum, nil
+}
+
+// getDDCBrightness devuelve el brillo de un monitor DDC/CI en porcentaje
+func getDDCBrightness(display int) (int, error) {
+	current, maximum, err := readDDCBrightness(display)
+	if err != nil {
+		return 0, err
+	}
+	return (current*100 + maximum/2) / maximum, nil
+}
+
+// setDDCBrightness ajusta el brillo (0-100) de un monitor DDC/CI, escalado al
+// rango que declara el propio monitor
+func setDDCBrightness(display, level int) error {
+	_, maximum, err := readDDCBrightness(display)
+	if err != nil {
+		return err
+	}
+	raw := (level*maximum + 50) / 100
+	return exec.Command("ddcutil", "--display", strconv.Itoa(display), "setvcp", "10", strconv.Itoa(raw)).Run()
+}
+
+// findDDCMonitor busca un monitor por su conector DRM o bus I2C. Un índice
+// numérico se interpreta como posición entre los monitores con DDC/CI.
+func findDDCMonitor(monitors []ddcMonitor, display string) (ddcMonitor, bool) {
+	for _, m := range monitors {
+		if strings.EqualFold(m.Connector, display) || m.Bus == display {
+			return m, true
+		}
+	}
+	if index, err := strconv.Atoi(display); err == nil {
+		valid := validDDCMonitors(monitors)
+		if index >= 0 && index < len(valid) {
+			return valid[index], true
+		}
+	}
+	return ddcMonitor{}, false
+}
+
+// validDDCMonitors filtra los monitores que soportan DDC/CI
+func validDDCMonitors(monitors []ddcMonitor) []ddcMonitor {
+	var valid []ddcMonitor
+	for _, m := range monitors {
+		if m.Valid {
+			valid = append(valid, m)
+		}
+	}
+	return valid
+}
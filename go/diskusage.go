@@ -0,0 +1,281 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// DiskUsage es el espacio de un sistema de ficheros montado o de una unidad
+type DiskUsage struct {
+	Mount       string  `json:"mount" jsonschema:"Punto de montaje o letra de unidad (ej: '/', 'C:')"`
+	Device      string  `json:"device,omitempty" jsonschema:"Dispositivo o etiqueta del volumen"`
+	FSType      string  `json:"fs_type,omitempty" jsonschema:"Tipo de sistema de ficheros"`
+	TotalKB     int64   `json:"total_kb" jsonschema:"Tamaño total en KB"`
+	UsedKB      int64   `json:"used_kb" jsonschema:"Espacio en uso en KB"`
+	FreeKB      int64   `json:"free_kb" jsonschema:"Espacio disponible en KB"`
+	UsedPercent float64 `json:"used_percent" jsonschema:"Porcentaje en uso"`
+}
+
+type DiskUsageOutput struct {
+	Disks []DiskUsage `json:"disks,omitempty" jsonschema:"Sistemas de ficheros, del más lleno al más vacío"`
+}
+
+// pseudoFilesystems son los tipos de sistema de ficheros que no ocupan
+// espacio en disco y que get_disk_usage omite salvo con include_all
+var pseudoFilesystems = map[string]bool{
+	"autofs": true, "binfmt_misc": true, "bpf": true, "cgroup": true, "cgroup2": true,
+	"configfs": true, "debugfs": true, "devpts": true, "devtmpfs": true, "efivarfs": true,
+	"fusectl": true, "hugetlbfs": true, "mqueue": true, "nsfs": true, "proc": true,
+	"pstore": true, "ramfs": true, "rpc_pipefs": true, "securityfs": true, "squashfs": true,
+	"sysfs": true, "tmpfs": true, "tracefs": true, "fuse.gvfsd-fuse": true, "fuse.portal": true,
+	"devfs": true,
+}
+
+// newDiskUsage calcula el uso como df: el porcentaje es sobre el espacio
+// usado más el disponible, sin contar el reservado para root
+func newDiskUsage(mount, device, fsType string, total, free, avail uint64) DiskUsage {
+	disk := DiskUsage{
+		Mount:   mount,
+		Device:  device,
+		FSType:  fsType,
+		TotalKB: int64(total / 1024),
+		UsedKB:  int64((total - free) / 1024),
+		FreeKB:  int64(avail / 1024),
+	}
+	if disk.UsedKB+disk.FreeKB > 0 {
+		disk.UsedPercent = 100 * float64(disk.UsedKB) / float64(disk.UsedKB+disk.FreeKB)
+	}
+	return disk
+}
+
+// getDiskUsage devuelve el espacio de los sistemas de ficheros montados o,
+// con path, solo el del que contiene esa ruta
+func getDiskUsage(path string, includeAll bool) ([]DiskUsage, error) {
+	path = strings.TrimSpace(path)
+	var disks []DiskUsage
+	var err error
+	switch currentPlatform() {
+	case platformWindows:
+		disks, err = diskUsageWindows(path)
+	case platformMac:
+		disks, err = diskUsageMac(path, includeAll)
+	default:
+		disks, err = diskUsageLinux(path, includeAll)
+	}
+	if err != nil {
+		return nil, err
+	}
+	slices.SortStableFunc(disks, func(a, b DiskUsage) int {
+		switch {
+		case a.UsedPercent > b.UsedPercent:
+			return -1
+		case a.UsedPercent < b.UsedPercent:
+			return 1
+		}
+		return 0
+	})
+	return disks, nil
+}
+
+// unescapeMountField deshace los escapes octales de /proc/mounts ("\040"
+// para los espacios)
+func unescapeMountField(field string) string {
+	if !strings.Contains(field, `\`) {
+		return field
+	}
+	var b strings.Builder
+	for i := 0; i < len(field); i++ {
+		if field[i] == '\\' && i+3 < len(field) {
+			if c, err := strconv.ParseUint(field[i+1:i+4], 8, 8); err == nil {
+				b.WriteByte(byte(c))
+				i += 3
+				continue
+			}
+		}
+		b.WriteByte(field[i])
+	}
+	return b.String()
+}
+
+// diskUsageLinux recorre /proc/mounts y consulta cada sistema de ficheros
+// con statfs. Sin include_all se omiten los pseudo sistemas de ficheros, los
+// montajes de snap y los que aparecen varias veces (bind mounts).
+func diskUsageLinux(path string, includeAll bool) ([]DiskUsage, error) {
+	file, err := os.Open("/proc/mounts")
+	if err != nil {
+		return nil, fmt.Errorf("error al leer /proc/mounts: %w", err)
+	}
+	defer file.Close()
+
+	type mount struct{ Device, Point, FSType string }
+	var mounts []mount
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		// "/dev/nvme0n1p2 / ext4 rw,relatime 0 0"
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 3 {
+			continue
+		}
+		mounts = append(mounts, mount{Device: unescapeMountField(fields[0]), Point: unescapeMountField(fields[1]), FSType: fields[2]})
+	}
+
+	if path != "" {
+		// El montaje más largo que contiene la ruta; el último si se repite
+		resolved, err := filepath.EvalSymlinks(expandHome(path))
+		if err != nil {
+			return nil, fmt.Errorf("no se puede acceder a %s: %w", path, err)
+		}
+		best := -1
+		for i, m := range mounts {
+			if resolved == m.Point || strings.HasPrefix(resolved, strings.TrimSuffix(m.Point, "/")+"/") {
+				if best < 0 || len(m.Point) >= len(mounts[best].Point) {
+					best = i
+				}
+			}
+		}
+		if best < 0 {
+			return nil, fmt.Errorf("no se encontró el sistema de ficheros de %s", path)
+		}
+		m := mounts[best]
+		total, free, avail, err := statfsUsage(resolved)
+		if err != nil {
+			return nil, fmt.Errorf("error al consultar %s: %w", m.Point, err)
+		}
+		return []DiskUsage{newDiskUsage(m.Point, m.Device, m.FSType, total, free, avail)}, nil
+	}
+
+	var disks []DiskUsage
+	seen := map[string]bool{}
+	for _, m := range mounts {
+		if !includeAll {
+			if pseudoFilesystems[m.FSType] || strings.HasPrefix(m.Point, "/snap/") || strings.HasPrefix(m.Device, "/dev/loop") {
+				continue
+			}
+			if strings.HasPrefix(m.Device, "/dev/") {
+				if seen[m.Device] {
+					continue
+				}
+				seen[m.Device] = true
+			}
+		}
+		total, free, avail, err := statfsUsage(m.Point)
+		if err != nil || total == 0 && !includeAll {
+			continue
+		}
+		disks = append(disks, newDiskUsage(m.Point, m.Device, m.FSType, total, free, avail))
+	}
+	return disks, nil
+}
+
+// diskUsageMac interpreta `df -kP`, que usa getmntinfo:
+//
+//	Filesystem     1024-blocks      Used Available Capacity  Mounted on
+//	/dev/disk3s1s1   482797652  10243968 223311740     5%    /
+//
+// Sin include_all se omiten devfs, los montajes automáticos ("map ...") y
+// los volúmenes internos de APFS bajo /System/Volumes, salvo el de datos.
+func diskUsageMac(path string, includeAll bool) ([]DiskUsage, error) {
+	args := []string{"-kP"}
+	if path != "" {
+		args = append(args, expandHome(path))
+	}
+	output, err := exec.Command("df", args...).CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("error al ejecutar df: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+	var disks []DiskUsage
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	for _, line := range lines[1:] {
+		fields := strings.Fields(line)
+		if len(fields) < 6 {
+			continue
+		}
+		// El dispositivo también puede tener espacios ("map auto_home")
+		i := len(fields) - 1
+		for i > 4 && !strings.HasSuffix(fields[i-1], "%") {
+			i--
+		}
+		device := strings.Join(fields[:i-4], " ")
+		mount := strings.Join(fields[i:], " ")
+		total, _ := strconv.ParseUint(fields[i-4], 10, 64)
+		used, _ := strconv.ParseUint(fields[i-3], 10, 64)
+		avail, _ := strconv.ParseUint(fields[i-2], 10, 64)
+		if path == "" && !includeAll {
+			if device == "devfs" || strings.HasPrefix(device, "map ") || total == 0 ||
+				strings.HasPrefix(mount, "/System/Volumes/") && mount != "/System/Volumes/Data" {
+				continue
+			}
+		}
+		disks = append(disks, newDiskUsage(mount, device, "", total*1024, (total-used)*1024, avail*1024))
+	}
+	return disks, nil
+}
+
+// diskUsageWindows consulta las unidades locales, extraíbles y de red con
+// Win32_LogicalDisk; con path, solo la de su letra de unidad
+func diskUsageWindows(path string) ([]DiskUsage, error) {
+	filter := "DriveType=2 OR DriveType=3 OR DriveType=4"
+	if path != "" {
+		volume := filepath.VolumeName(path)
+		if len(volume) != 2 || volume[1] != ':' {
+			return nil, fmt.Errorf("la ruta %s no empieza por una letra de unidad", path)
+		}
+		filter = fmt.Sprintf("DeviceID='%s'", strings.ToUpper(volume))
+	}
+	output, err := runPowerShell(fmt.Sprintf(`ConvertTo-Json -Compress @(Get-CimInstance Win32_LogicalDisk -Filter "%s" | Where-Object { $_.Size } | ForEach-Object {
+	@{ mount = $_.DeviceID; device = "$($_.VolumeName)"; fs = "$($_.FileSystem)"; size = [long]$_.Size; free = [long]$_.FreeSpace }
+})`, filter))
+	if err != nil {
+		return nil, fmt.Errorf("error al consultar las unidades: %w", err)
+	}
+	var drives []struct {
+		Mount, Device, FS string
+		Size, Free        uint64
+	}
+	if err := json.Unmarshal([]byte(output), &drives); err != nil {
+		return nil, fmt.Errorf("respuesta de PowerShell no válida: %w", err)
+	}
+	if path != "" && len(drives) == 0 {
+		return nil, errors.New("no se encontró la unidad de " + path)
+	}
+	var disks []DiskUsage
+	for _, d := range drives {
+		disks = append(disks, newDiskUsage(d.Mount, d.Device, d.FS, d.Size, d.Free, d.Free))
+	}
+	return disks, nil
+}
+
+type GetDiskUsageInput struct {
+	Path       string `json:"path,omitempty" jsonschema:"Ruta de un fichero o directorio: solo se devuelve el sistema de ficheros que la contiene"`
+	IncludeAll bool   `json:"include_all,omitempty" jsonschema:"Incluir también los pseudo sistemas de ficheros (tmpfs, montajes de snap...)"`
+}
+
+func HandleGetDiskUsage(ctx context.Context, req *mcp.CallToolRequest, input GetDiskUsageInput) (*mcp.CallToolResult, DiskUsageOutput, error) {
+	disks, err := getDiskUsage(input.Path, input.IncludeAll)
+	if err != nil {
+		return nil, DiskUsageOutput{}, fmt.Errorf("❌ Error al obtener el uso del disco: %w", err)
+	}
+	if len(disks) == 0 {
+		return nil, DiskUsageOutput{}, errors.New("❌ No se encontró ningún sistema de ficheros")
+	}
+	lines := []string{"💽 Uso del disco:"}
+	for _, d := range disks {
+		lines = append(lines, fmt.Sprintf("  - %s: %s libres de %s (%.0f%% en uso)", d.Mount, formatKB(d.FreeKB), formatKB(d.TotalKB), d.UsedPercent))
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: strings.Join(lines, "\n")},
+		},
+	}, DiskUsageOutput{Disks: disks}, nil
+}
@@ -0,0 +1,624 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// DisplayInfo describe una pantalla conectada. El id es estable mientras no
+// cambie la conexión y se puede pasar como display a set_brightness y
+// set_resolution.
+type DisplayInfo struct {
+	ID          string   `json:"id" jsonschema:"Identificador estable: conector en Linux (ej: 'eDP-1'), ID de pantalla en macOS, dispositivo GDI en Windows (ej: 'DISPLAY1')"`
+	Name        string   `json:"name,omitempty" jsonschema:"Nombre o modelo del monitor"`
+	Width       int      `json:"width,omitempty" jsonschema:"Resolución horizontal en píxeles"`
+	Height      int      `json:"height,omitempty" jsonschema:"Resolución vertical en píxeles"`
+	RefreshRate *float64 `json:"refresh_rate_hz" jsonschema:"Frecuencia de refresco en Hz (null si no se puede saber)"`
+	Scale       *float64 `json:"scale" jsonschema:"Factor de escala (ej: 2 en pantallas Retina; null si no se puede saber)"`
+	Primary     bool     `json:"primary" jsonschema:"Es la pantalla principal"`
+	Internal    bool     `json:"internal" jsonschema:"Es el panel interno del portátil"`
+	Connection  string   `json:"connection,omitempty" jsonschema:"Tipo de conexión (HDMI, DisplayPort, eDP...)"`
+
+	// modes son los modos que admite la pantalla, con los que set_resolution
+	// valida el que se pide, y orientation su giro (normal, left, inverted o
+	// right; vacío si no se sabe). En macOS los da displayplacer.
+	modes       []DisplayMode
+	orientation string
+}
+
+// DisplayMode es una resolución con su frecuencia de refresco
+type DisplayMode struct {
+	Width       int     `json:"width" jsonschema:"Resolución horizontal en píxeles"`
+	Height      int     `json:"height" jsonschema:"Resolución vertical en píxeles"`
+	RefreshRate float64 `json:"refresh_rate_hz,omitempty" jsonschema:"Frecuencia de refresco en Hz"`
+
+	// name identifica el modo ante la herramienta que lo aplica: el nombre
+	// de xrandr o el número de modo de displayplacer
+	name string
+}
+
+type DisplaysOutput struct {
+	Displays []DisplayInfo `json:"displays,omitempty" jsonschema:"Pantallas conectadas, en el orden de los índices de display"`
+}
+
+// optionalFloat devuelve un puntero a value, o nil si es cero (desconocido)
+func optionalFloat(value float64) *float64 {
+	if value == 0 {
+		return nil
+	}
+	return &value
+}
+
+// connectorTypes traduce el prefijo de los conectores DRM y de xrandr
+var connectorTypes = []struct{ Prefix, Type string }{
+	{"EDP", "eDP"}, {"LVDS", "LVDS"}, {"DSI", "DSI"}, {"HDMI", "HDMI"},
+	{"DP", "DisplayPort"}, {"DISPLAYPORT", "DisplayPort"}, {"DVI", "DVI"},
+	{"VGA", "VGA"}, {"USB-C", "USB-C"}, {"VIRTUAL", "virtual"},
+}
+
+// connectorType deduce el tipo de conexión del nombre del conector ("HDMI-1")
+func connectorType(name string) string {
+	upper := strings.ToUpper(name)
+	for _, c := range connectorTypes {
+		if strings.HasPrefix(upper, c.Prefix) {
+			return c.Type
+		}
+	}
+	return ""
+}
+
+// listDisplays enumera las pantallas conectadas. Es la misma enumeración que
+// usa set_brightness para resolver los índices y los id de display.
+func listDisplays() ([]DisplayInfo, error) {
+	switch currentPlatform() {
+	case platformWindows, platformWSL:
+		return listDisplaysWindows()
+	case platformMac:
+		return listDisplaysMac()
+	}
+	return listDisplaysLinux()
+}
+
+// listDisplaysLinux usa xrandr en X11 (la lista en caché que comparten el
+// brillo y manage_window) y, en Wayland, la interfaz DisplayConfig de Mutter
+// en GNOME, kscreen-doctor en KDE o wlr-randr en los compositores wlroots
+func listDisplaysLinux() ([]DisplayInfo, error) {
+	if err := checkGraphicalSession(); err != nil {
+		return nil, err
+	}
+	session := detectLinuxSession()
+	switch {
+	case !session.Wayland:
+		outputs, err := listXrandrOutputs()
+		if err != nil {
+			return nil, err
+		}
+		displays := make([]DisplayInfo, len(outputs))
+		for i, o := range outputs {
+			displays[i] = DisplayInfo{
+				ID:          o.Name,
+				Width:       o.Width,
+				Height:      o.Height,
+				RefreshRate: optionalFloat(o.Refresh),
+				Primary:     o.Primary,
+				Internal:    isInternalOutput(o.Name),
+				Connection:  connectorType(o.Name),
+				modes:       o.Modes,
+				orientation: o.Rotation,
+			}
+		}
+		return displays, nil
+	case session.IsDesktop("GNOME") && commandExists("gdbus")():
+		return listDisplaysMutter()
+	case session.IsDesktop("KDE") && commandExists("kscreen-doctor")():
+		return listDisplaysKScreen()
+	case commandExists("wlr-randr")():
+		return listDisplaysWlrRandr()
+	}
+	return nil, fmt.Errorf("no se pueden enumerar las pantallas en la sesión Wayland (compositor: %s): instala wlr-randr", session.Compositor())
+}
+
+var (
+	// Inicio de cada monitor en GetCurrentState: (('eDP-1', 'BOE', '0x0812', '0x00000000'), [modos...], {propiedades})
+	mutterMonitorRegexp = regexp.MustCompile(`\(\('([^']*)', '([^']*)', '([^']*)', '([^']*)'\), \[`)
+	// ('1920x1080@60.000', 1920, 1080, 60.0, 1.0, [1.0, 2.0], {'is-current': <true>})
+	mutterModeRegexp = regexp.MustCompile(`\('[^']*', (\d+), (\d+), ([\d.]+), [\d.]+, \[[^\]]*\], \{([^}]*)\}\)`)
+	// Monitor lógico: (0, 0, 1.25, uint32 0, true, [('eDP-1', 'BOE', '0x0812', '0x00000000')], @a{sv} {})
+	mutterLogicalRegexp     = regexp.MustCompile(`\((-?\d+), (-?\d+), ([\d.]+), uint32 \d+, (true|false), \[([^\]]*)\]`)
+	mutterConnectorRegexp   = regexp.MustCompile(`\('([^']*)', '[^']*', '[^']*', '[^']*'\)`)
+	mutterDisplayNameRegexp = regexp.MustCompile(`'display-name': <'([^']*)'>`)
+)
+
+// listDisplaysMutter interpreta org.gnome.Mutter.DisplayConfig.GetCurrentState,
+// que gdbus escribe como texto GVariant: primero los monitores físicos con sus
+// modos y después los monitores lógicos, con la escala y cuál es el principal
+func listDisplaysMutter() ([]DisplayInfo, error) {
+	output, err := runGDBus("call", "--session",
+		"--dest", "org.gnome.Mutter.DisplayConfig",
+		"--object-path", "/org/gnome/Mutter/DisplayConfig",
+		"--method", "org.gnome.Mutter.DisplayConfig.GetCurrentState")
+	if err != nil {
+		return nil, fmt.Errorf("error al consultar DisplayConfig de Mutter: %w", err)
+	}
+
+	type logical struct {
+		Scale   float64
+		Primary bool
+	}
+	logicals := map[string]logical{}
+	for _, m := range mutterLogicalRegexp.FindAllStringSubmatch(output, -1) {
+		scale, _ := strconv.ParseFloat(m[3], 64)
+		for _, c := range mutterConnectorRegexp.FindAllStringSubmatch(m[5], -1) {
+			logicals[c[1]] = logical{Scale: scale, Primary: m[4] == "true"}
+		}
+	}
+
+	var displays []DisplayInfo
+	starts := mutterMonitorRegexp.FindAllStringSubmatchIndex(output, -1)
+	for i, start := range starts {
+		end := len(output)
+		if i+1 < len(starts) {
+			end = starts[i+1][0]
+		}
+		segment := output[start[0]:end]
+		connector := output[start[2]:start[3]]
+		display := DisplayInfo{
+			ID:         connector,
+			Name:       strings.TrimSpace(output[start[4]:start[5]] + " " + output[start[6]:start[7]]),
+			Internal:   strings.Contains(segment, "'is-builtin': <true>") || isInternalOutput(connector),
+			Connection: connectorType(connector),
+		}
+		if m := mutterDisplayNameRegexp.FindStringSubmatch(segment); m != nil {
+			display.Name = m[1]
+		}
+		for _, mode := range mutterModeRegexp.FindAllStringSubmatch(segment, -1) {
+			width, _ := strconv.Atoi(mode[1])
+			height, _ := strconv.Atoi(mode[2])
+			rate, _ := strconv.ParseFloat(mode[3], 64)
+			display.modes = append(display.modes, DisplayMode{Width: width, Height: height, RefreshRate: rate})
+			if strings.Contains(mode[4], "'is-current': <true>") {
+				display.Width, display.Height = width, height
+				display.RefreshRate = optionalFloat(rate)
+			}
+		}
+		if l, ok := logicals[connector]; ok {
+			display.Scale = optionalFloat(l.Scale)
+			display.Primary = l.Primary
+		}
+		displays = append(displays, display)
+	}
+	if len(displays) == 0 {
+		return nil, fmt.Errorf("respuesta inesperada de DisplayConfig de Mutter: %q", output)
+	}
+	return displays, nil
+}
+
+// kscreenPanelType es el tipo KScreen::Output::Panel, el panel interno
+const kscreenPanelType = 7
+
+// listDisplaysKScreen interpreta `kscreen-doctor -j` (KDE Plasma)
+func listDisplaysKScreen() ([]DisplayInfo, error) {
+	output, err := exec.Command("kscreen-doctor", "-j").Output()
+	if err != nil {
+		return nil, fmt.Errorf("error al ejecutar kscreen-doctor: %w", err)
+	}
+	var result struct {
+		Outputs []struct {
+			Name          string
+			Connected     bool
+			Enabled       bool
+			Primary       bool
+			Priority      int
+			Scale         float64
+			Type          int
+			CurrentModeID any `json:"currentModeId"`
+			Modes         []struct {
+				ID          any
+				RefreshRate float64 `json:"refreshRate"`
+				Size        struct{ Width, Height int }
+			}
+		}
+	}
+	if err := json.Unmarshal(output, &result); err != nil {
+		return nil, fmt.Errorf("respuesta de kscreen-doctor no válida: %w", err)
+	}
+	var displays []DisplayInfo
+	for _, o := range result.Outputs {
+		if !o.Connected {
+			continue
+		}
+		display := DisplayInfo{
+			ID: o.Name,
+			// Plasma 5.27 sustituyó primary por priority (1 = principal)
+			Primary:    o.Primary || o.Priority == 1,
+			Internal:   o.Type == kscreenPanelType || isInternalOutput(o.Name),
+			Connection: connectorType(o.Name),
+		}
+		for _, mode := range o.Modes {
+			display.modes = append(display.modes, DisplayMode{Width: mode.Size.Width, Height: mode.Size.Height, RefreshRate: mode.RefreshRate})
+		}
+		if o.Enabled {
+			display.Scale = optionalFloat(o.Scale)
+			for _, mode := range o.Modes {
+				if fmt.Sprint(mode.ID) == fmt.Sprint(o.CurrentModeID) {
+					display.Width, display.Height = mode.Size.Width, mode.Size.Height
+					display.RefreshRate = optionalFloat(mode.RefreshRate)
+				}
+			}
+		}
+		displays = append(displays, display)
+	}
+	return displays, nil
+}
+
+// listDisplaysWlrRandr interpreta `wlr-randr --json` (sway, Hyprland y otros
+// compositores wlroots, que no tienen pantalla principal)
+func listDisplaysWlrRandr() ([]DisplayInfo, error) {
+	output, err := exec.Command("wlr-randr", "--json").Output()
+	if err != nil {
+		return nil, fmt.Errorf("error al ejecutar wlr-randr: %w", err)
+	}
+	var outputs []struct {
+		Name, Make, Model string
+		Enabled           bool
+		Scale             float64
+		Transform         string
+		Modes             []struct {
+			Width, Height int
+			Refresh       float64
+			Current       bool
+		}
+	}
+	if err := json.Unmarshal(output, &outputs); err != nil {
+		return nil, fmt.Errorf("respuesta de wlr-randr no válida: %w", err)
+	}
+	var displays []DisplayInfo
+	for _, o := range outputs {
+		display := DisplayInfo{
+			ID:          o.Name,
+			Name:        strings.TrimSpace(o.Make + " " + o.Model),
+			Internal:    isInternalOutput(o.Name),
+			Connection:  connectorType(o.Name),
+			orientation: wlrTransformOrientations[o.Transform],
+		}
+		for _, mode := range o.Modes {
+			display.modes = append(display.modes, DisplayMode{Width: mode.Width, Height: mode.Height, RefreshRate: mode.Refresh})
+		}
+		if o.Enabled {
+			display.Scale = optionalFloat(o.Scale)
+			for _, mode := range o.Modes {
+				if mode.Current {
+					display.Width, display.Height = mode.Width, mode.Height
+					display.RefreshRate = optionalFloat(mode.Refresh)
+				}
+			}
+		}
+		displays = append(displays, display)
+	}
+	return displays, nil
+}
+
+// macResolutionRegexp reconoce las resoluciones de system_profiler:
+// "3024 x 1964" o "1512 x 982 @ 120.00Hz"
+var macResolutionRegexp = regexp.MustCompile(`(\d+) x (\d+)(?:\s*@\s*([\d.]+)\s*Hz)?`)
+
+// listDisplaysMac interpreta `system_profiler SPDisplaysDataType -json`, que
+// lista las pantallas de cada GPU en spdisplays_ndrvs. La resolución es la
+// física (_spdisplays_pixels) y la escala, la relación con la resolución
+// aparente (_spdisplays_resolution, "parece 1512 x 982").
+func listDisplaysMac() ([]DisplayInfo, error) {
+	output, err := exec.Command("system_profiler", "SPDisplaysDataType", "-json").Output()
+	if err != nil {
+		return nil, fmt.Errorf("error al ejecutar system_profiler: %w", err)
+	}
+	var result struct {
+		SPDisplaysDataType []struct {
+			Displays []map[string]any `json:"spdisplays_ndrvs"`
+		}
+	}
+	if err := json.Unmarshal(output, &result); err != nil {
+		return nil, fmt.Errorf("respuesta de system_profiler no válida: %w", err)
+	}
+	var displays []DisplayInfo
+	for _, gpu := range result.SPDisplaysDataType {
+		for _, item := range gpu.Displays {
+			value := func(key string) string {
+				s, _ := item[key].(string)
+				return s
+			}
+			connection := strings.TrimPrefix(value("spdisplays_connection_type"), "spdisplays_")
+			display := DisplayInfo{
+				ID:       value("_spdisplays_displayID"),
+				Name:     value("_name"),
+				Primary:  value("spdisplays_main") == "spdisplays_yes",
+				Internal: connection == "internal" || strings.Contains(value("spdisplays_display_type"), "built-in"),
+			}
+			if !display.Internal {
+				display.Connection = connection
+			}
+			looks := macResolutionRegexp.FindStringSubmatch(value("_spdisplays_resolution"))
+			if looks != nil {
+				display.Width, _ = strconv.Atoi(looks[1])
+				display.Height, _ = strconv.Atoi(looks[2])
+				rate, _ := strconv.ParseFloat(looks[3], 64)
+				display.RefreshRate = optionalFloat(rate)
+			}
+			if pixels := macResolutionRegexp.FindStringSubmatch(value("_spdisplays_pixels")); pixels != nil {
+				width, _ := strconv.Atoi(pixels[1])
+				height, _ := strconv.Atoi(pixels[2])
+				if display.Width > 0 {
+					display.Scale = optionalFloat(float64(width) / float64(display.Width))
+				}
+				display.Width, display.Height = width, height
+			}
+			displays = append(displays, display)
+		}
+	}
+	return displays, nil
+}
+
+// windowsDisplaysScript enumera los monitores del escritorio con
+// EnumDisplayDevices y EnumDisplaySettings, que dan la resolución y la
+// frecuencia de cada uno (Win32_VideoController solo informa de un modo por
+// adaptador). La escala sale de GetDpiForMonitor, para lo que el proceso
+// debe declararse consciente del DPI de cada monitor. El tipo de conexión es
+// el VideoOutputTechnology de WmiMonitorConnectionParams, cuya instancia se
+// obtiene de la ruta del monitor:
+// "\\?\DISPLAY#BOE0812#4&2a1b&0&UID8388688#{...}" -> "DISPLAY\BOE0812\4&2a1b&0&UID8388688_0"
+const windowsDisplaysScript = `Add-Type -TypeDefinition @'
+using System;
+using System.Collections.Generic;
+using System.Runtime.InteropServices;
+public class DisplayList {
+	[StructLayout(LayoutKind.Sequential, CharSet = CharSet.Unicode)]
+	public struct DISPLAY_DEVICE {
+		public int cb;
+		[MarshalAs(UnmanagedType.ByValTStr, SizeConst = 32)] public string DeviceName;
+		[MarshalAs(UnmanagedType.ByValTStr, SizeConst = 128)] public string DeviceString;
+		public int StateFlags;
+		[MarshalAs(UnmanagedType.ByValTStr, SizeConst = 128)] public string DeviceID;
+		[MarshalAs(UnmanagedType.ByValTStr, SizeConst = 128)] public string DeviceKey;
+	}
+	[StructLayout(LayoutKind.Sequential, CharSet = CharSet.Unicode)]
+	public struct DEVMODE {
+		[MarshalAs(UnmanagedType.ByValTStr, SizeConst = 32)] public string dmDeviceName;
+		public short dmSpecVersion, dmDriverVersion, dmSize, dmDriverExtra;
+		public int dmFields, dmPositionX, dmPositionY, dmDisplayOrientation, dmDisplayFixedOutput;
+		public short dmColor, dmDuplex, dmYResolution, dmTTOption, dmCollate;
+		[MarshalAs(UnmanagedType.ByValTStr, SizeConst = 32)] public string dmFormName;
+		public short dmLogPixels;
+		public int dmBitsPerPel, dmPelsWidth, dmPelsHeight, dmDisplayFlags, dmDisplayFrequency;
+		public int dmICMMethod, dmICMIntent, dmMediaType, dmDitherType, dmReserved1, dmReserved2, dmPanningWidth, dmPanningHeight;
+	}
+	[StructLayout(LayoutKind.Sequential)] public struct POINT { public int X, Y; }
+	[DllImport("user32.dll", CharSet = CharSet.Unicode)] static extern bool EnumDisplayDevices(string device, uint index, ref DISPLAY_DEVICE info, uint flags);
+	[DllImport("user32.dll", CharSet = CharSet.Unicode)] static extern bool EnumDisplaySettings(string device, int mode, ref DEVMODE info);
+	[DllImport("user32.dll")] static extern IntPtr MonitorFromPoint(POINT pt, uint flags);
+	[DllImport("shcore.dll")] static extern int GetDpiForMonitor(IntPtr monitor, int type, out uint x, out uint y);
+	[DllImport("shcore.dll")] static extern int SetProcessDpiAwareness(int value);
+	public static List<object> List() {
+		try { SetProcessDpiAwareness(2); } catch { }
+		var result = new List<object>();
+		var adapter = new DISPLAY_DEVICE();
+		adapter.cb = Marshal.SizeOf(adapter);
+		for (uint i = 0; EnumDisplayDevices(null, i, ref adapter, 0); i++) {
+			var mode = new DEVMODE();
+			mode.dmSize = (short)Marshal.SizeOf(mode);
+			// DISPLAY_DEVICE_ATTACHED_TO_DESKTOP; -1 = ENUM_CURRENT_SETTINGS
+			if ((adapter.StateFlags & 1) != 0 && EnumDisplaySettings(adapter.DeviceName, -1, ref mode)) {
+				var monitor = new DISPLAY_DEVICE();
+				monitor.cb = Marshal.SizeOf(monitor);
+				// EDD_GET_DEVICE_INTERFACE_NAME: DeviceID es la ruta del monitor
+				bool found = EnumDisplayDevices(adapter.DeviceName, 0, ref monitor, 1);
+				uint dpiX = 0, dpiY = 0;
+				var point = new POINT { X = mode.dmPositionX, Y = mode.dmPositionY };
+				try { GetDpiForMonitor(MonitorFromPoint(point, 2), 0, out dpiX, out dpiY); } catch { }
+				// Los modos como "1920x1080@60" separados por comas, para que
+				// ConvertTo-Json no los corte por la profundidad
+				var modes = new List<string>();
+				var other = new DEVMODE();
+				other.dmSize = (short)Marshal.SizeOf(other);
+				for (int m = 0; EnumDisplaySettings(adapter.DeviceName, m, ref other); m++) {
+					var text = other.dmPelsWidth + "x" + other.dmPelsHeight + "@" + other.dmDisplayFrequency;
+					if (!modes.Contains(text)) { modes.Add(text); }
+				}
+				result.Add(new Dictionary<string, object> {
+					{ "device", adapter.DeviceName }, { "name", found ? monitor.DeviceString : "" }, { "path", found ? monitor.DeviceID : "" },
+					{ "width", mode.dmPelsWidth }, { "height", mode.dmPelsHeight }, { "refresh", mode.dmDisplayFrequency },
+					{ "dpi", dpiX }, { "primary", (adapter.StateFlags & 4) != 0 }, { "modes", string.Join(",", modes) },
+					{ "orientation", mode.dmDisplayOrientation }
+				});
+			}
+			adapter.cb = Marshal.SizeOf(adapter);
+		}
+		return result;
+	}
+}
+'@
+$tech = @{}
+Get-CimInstance -Namespace root/wmi -ClassName WmiMonitorConnectionParams -ErrorAction SilentlyContinue | ForEach-Object { $tech[$_.InstanceName] = [long]$_.VideoOutputTechnology }
+$names = @{}
+Get-CimInstance -Namespace root/wmi -ClassName WmiMonitorID -ErrorAction SilentlyContinue | ForEach-Object { $names[$_.InstanceName] = -join ($_.UserFriendlyName | Where-Object { $_ } | ForEach-Object { [char]$_ }) }
+ConvertTo-Json -Compress @([DisplayList]::List() | ForEach-Object {
+	$instance = ''
+	if ($_.path -match '^\\\\\?\\(DISPLAY#[^#]+#[^#]+)#') { $instance = ($Matches[1] -replace '#', '\') + '_0' }
+	$_.instance = $instance
+	$_.tech = if ($tech.ContainsKey($instance)) { $tech[$instance] } else { -2 }
+	if ($names[$instance]) { $_.name = $names[$instance] }
+	$_
+})`
+
+// windowsOutputTechnologies traduce VideoOutputTechnology
+// (D3DKMDT_VIDEO_OUTPUT_TECHNOLOGY). Las marcadas como internas son el
+// panel del portátil.
+var windowsOutputTechnologies = map[int64]struct {
+	Name     string
+	Internal bool
+}{
+	0: {"VGA", false}, 4: {"DVI", false}, 5: {"HDMI", false}, 6: {"LVDS", true},
+	10: {"DisplayPort", false}, 11: {"eDP", true}, 12: {"UDI", false}, 13: {"UDI", true},
+	15: {"Miracast", false}, 16: {"indirecta", false}, 0x80000000: {"interna", true},
+}
+
+// windowsDisplay es un monitor según windowsDisplaysScript
+type windowsDisplay struct {
+	Device, Name, Instance string
+	Width, Height, Refresh int
+	DPI                    int
+	Primary                bool
+	Tech                   int64
+	Modes                  string // "1920x1080@60,1280x720@60"
+	Orientation            int    // dmDisplayOrientation: DMDO_DEFAULT, DMDO_90...
+}
+
+func queryWindowsDisplays() ([]windowsDisplay, error) {
+	output, err := runPowerShell(windowsDisplaysScript)
+	if err != nil {
+		return nil, fmt.Errorf("error al enumerar las pantallas: %w", err)
+	}
+	var displays []windowsDisplay
+	if err := json.Unmarshal([]byte(output), &displays); err != nil {
+		return nil, fmt.Errorf("respuesta de PowerShell no válida: %w", err)
+	}
+	return displays, nil
+}
+
+// listDisplaysWindows usa como id el nombre del dispositivo GDI sin el
+// prefijo "\\.\" ("DISPLAY1"), el mismo que acepta manage_window
+func listDisplaysWindows() ([]DisplayInfo, error) {
+	monitors, err := queryWindowsDisplays()
+	if err != nil {
+		return nil, err
+	}
+	displays := make([]DisplayInfo, len(monitors))
+	for i, m := range monitors {
+		tech := windowsOutputTechnologies[m.Tech]
+		displays[i] = DisplayInfo{
+			ID:          strings.TrimPrefix(m.Device, `\\.\`),
+			Name:        m.Name,
+			Width:       m.Width,
+			Height:      m.Height,
+			RefreshRate: optionalFloat(float64(m.Refresh)),
+			Primary:     m.Primary,
+			Internal:    tech.Internal,
+			Connection:  tech.Name,
+		}
+		if m.Orientation >= 0 && m.Orientation < len(windowsOrientations) {
+			displays[i].orientation = windowsOrientations[m.Orientation]
+		}
+		if m.DPI > 0 {
+			displays[i].Scale = optionalFloat(float64(m.DPI) / 96)
+		}
+		for _, text := range strings.Split(m.Modes, ",") {
+			var mode DisplayMode
+			if _, err := fmt.Sscanf(text, "%dx%d@%g", &mode.Width, &mode.Height, &mode.RefreshRate); err == nil {
+				displays[i].modes = append(displays[i].modes, mode)
+			}
+		}
+	}
+	return displays, nil
+}
+
+// windowsDisplayInstance traduce un id de list_displays ("DISPLAY2" o
+// "\\.\DISPLAY2") a la instancia WMI del monitor, con la que se ajusta su
+// brillo. Devuelve ok=false si display no es un id de list_displays.
+func windowsDisplayInstance(display string) (instance string, ok bool, err error) {
+	name := strings.ToUpper(strings.TrimPrefix(display, `\\.\`))
+	if !strings.HasPrefix(name, "DISPLAY") {
+		return "", false, nil
+	}
+	if _, err := strconv.Atoi(strings.TrimPrefix(name, "DISPLAY")); err != nil {
+		return "", false, nil
+	}
+	monitors, err := queryWindowsDisplays()
+	if err != nil {
+		return "", true, err
+	}
+	for _, m := range monitors {
+		if strings.EqualFold(strings.TrimPrefix(m.Device, `\\.\`), name) {
+			if m.Instance == "" {
+				return "", true, fmt.Errorf("no se pudo identificar el monitor de %s", name)
+			}
+			return m.Instance, true, nil
+		}
+	}
+	return "", true, fmt.Errorf("no se encontró la pantalla %s (usa list_displays)", name)
+}
+
+// macBrightnessIDRegexp reconoce el ID de pantalla en `brightness -l`:
+// "display 1: main, active, awake, online, external, ID 0x1e6d5b4c"
+var macBrightnessIDRegexp = regexp.MustCompile(`(?m)^display (\d+): .*ID 0x([0-9a-fA-F]+)`)
+
+// macDisplayIndex traduce un id de list_displays al índice de pantalla de
+// `brightness -l`. system_profiler da el ID de CoreGraphics en hexadecimal,
+// el mismo que `brightness -l`.
+func macDisplayIndex(display string) (string, error) {
+	id, err := strconv.ParseUint(strings.TrimPrefix(strings.ToLower(display), "0x"), 16, 32)
+	if err != nil {
+		return "", fmt.Errorf("en macOS el display debe ser un índice numérico, 'all' o un id de list_displays, no %q", display)
+	}
+	output, err := exec.Command("brightness", "-l").Output()
+	if err != nil {
+		return "", fmt.Errorf("no se pudo relacionar el id %s con una pantalla (requiere brightness -l): %w", display, err)
+	}
+	for _, m := range macBrightnessIDRegexp.FindAllStringSubmatch(string(output), -1) {
+		if value, _ := strconv.ParseUint(m[2], 16, 32); value == id {
+			return m[1], nil
+		}
+	}
+	return "", fmt.Errorf("no se encontró la pantalla con id %s", display)
+}
+
+func HandleListDisplays(ctx context.Context, req *mcp.CallToolRequest, input struct{}) (*mcp.CallToolResult, DisplaysOutput, error) {
+	displays, err := listDisplays()
+	if err != nil {
+		return nil, DisplaysOutput{}, fmt.Errorf("❌ Error al enumerar las pantallas: %w", err)
+	}
+	if len(displays) == 0 {
+		return nil, DisplaysOutput{}, errors.New("❌ No se encontró ninguna pantalla conectada")
+	}
+	lines := []string{"🖥️ Pantallas conectadas:"}
+	for i, d := range displays {
+		line := fmt.Sprintf("  %d. %s", i, d.ID)
+		if d.Name != "" {
+			line += " (" + d.Name + ")"
+		}
+		var details []string
+		switch {
+		case d.Width == 0:
+			details = append(details, "apagada")
+		case d.RefreshRate != nil:
+			details = append(details, fmt.Sprintf("%dx%d a %.0f Hz", d.Width, d.Height, *d.RefreshRate))
+		default:
+			details = append(details, fmt.Sprintf("%dx%d", d.Width, d.Height))
+		}
+		if d.Scale != nil && *d.Scale != 1 {
+			details = append(details, fmt.Sprintf("escala %g", *d.Scale))
+		}
+		if d.Primary {
+			details = append(details, "principal")
+		}
+		if d.Internal {
+			details = append(details, "interna")
+		} else {
+			details = append(details, "externa")
+		}
+		if d.Connection != "" {
+			details = append(details, d.Connection)
+		}
+		lines = append(lines, line+": "+strings.Join(details, ", "))
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: strings.Join(lines, "\n")},
+		},
+	}, DisplaysOutput{Displays: displays}, nil
+}
@@ -0,0 +1,345 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// maxDisplayTimeout limita los minutos de set_display_timeout
+const maxDisplayTimeout = 24 * 60
+
+// displayTimeoutChange es un cambio del tiempo de apagado de la pantalla:
+// el método, el valor anterior en segundos (0: nunca) y cómo deshacerlo
+type displayTimeoutChange struct {
+	Method   string
+	Previous int
+	restore  func() error
+}
+
+// displayTimeoutMu protege savedDisplayTimeout, el cambio que deshace
+// restore_display_timeout. Se guarda el del primer set_display_timeout, así
+// que varios cambios seguidos se restauran al valor original.
+var (
+	displayTimeoutMu    sync.Mutex
+	savedDisplayTimeout *displayTimeoutChange
+)
+
+// runSettingCommand ejecuta una orden que cambia un ajuste, incluyendo su
+// salida en el error
+func runSettingCommand(name string, args ...string) error {
+	output, err := exec.Command(name, args...).CombinedOutput()
+	if err == nil {
+		return nil
+	}
+	if msg := strings.TrimSpace(string(output)); msg != "" {
+		return fmt.Errorf("error al ejecutar %s: %w: %s", name, err, msg)
+	}
+	return fmt.Errorf("error al ejecutar %s: %w", name, err)
+}
+
+// describeDisplayTimeout da un tiempo de apagado en segundos en formato legible
+func describeDisplayTimeout(seconds int) string {
+	if seconds == 0 {
+		return "nunca"
+	}
+	return humanizeDuration(time.Duration(seconds) * time.Second)
+}
+
+// setDisplayTimeout cambia los minutos de inactividad tras los que se apaga
+// la pantalla (0: nunca) y guarda el valor anterior para restaurarlo
+func setDisplayTimeout(minutes int) (displayTimeoutChange, error) {
+	if minutes < 0 || minutes > maxDisplayTimeout {
+		return displayTimeoutChange{}, fmt.Errorf("los minutos deben estar entre 0 y %d", maxDisplayTimeout)
+	}
+	displayTimeoutMu.Lock()
+	defer displayTimeoutMu.Unlock()
+
+	var change displayTimeoutChange
+	var err error
+	switch currentPlatform() {
+	case platformWindows, platformWSL:
+		change, err = displayTimeoutWindows(minutes)
+	case platformMac:
+		change, err = displayTimeoutMac(minutes)
+	default:
+		change, err = displayTimeoutLinux(minutes)
+	}
+	if err != nil {
+		return displayTimeoutChange{}, err
+	}
+	if savedDisplayTimeout == nil {
+		savedDisplayTimeout = &change
+	}
+	return change, nil
+}
+
+// restoreDisplayTimeout deshace los cambios de set_display_timeout y
+// devuelve el valor restaurado en segundos
+func restoreDisplayTimeout() (int, error) {
+	displayTimeoutMu.Lock()
+	defer displayTimeoutMu.Unlock()
+	if savedDisplayTimeout == nil {
+		return 0, errors.New("no hay ningún cambio de set_display_timeout que restaurar")
+	}
+	if err := savedDisplayTimeout.restore(); err != nil {
+		return 0, err
+	}
+	previous := savedDisplayTimeout.Previous
+	savedDisplayTimeout = nil
+	return previous, nil
+}
+
+// displayTimeoutLinux usa el idle-delay de GNOME (que además anula los
+// ajustes de xset) o, en X11, el salvapantallas y DPMS de xset
+func displayTimeoutLinux(minutes int) (displayTimeoutChange, error) {
+	session := detectLinuxSession()
+	if session.IsDesktop("GNOME") && commandExists("gsettings")() {
+		return displayTimeoutGNOME(minutes)
+	}
+	if session.Wayland {
+		return displayTimeoutChange{}, fmt.Errorf("el tiempo de apagado de la pantalla no se puede cambiar en Wayland (compositor: %s)", session.Compositor())
+	}
+	if err := checkGraphicalSession(); err != nil {
+		return displayTimeoutChange{}, err
+	}
+	if !commandExists("xset")() {
+		return displayTimeoutChange{}, errors.New("xset no está instalado")
+	}
+	return displayTimeoutX11(minutes)
+}
+
+func displayTimeoutGNOME(minutes int) (displayTimeoutChange, error) {
+	// "uint32 300"
+	output, err := exec.Command("gsettings", "get", "org.gnome.desktop.session", "idle-delay").Output()
+	if err != nil {
+		return displayTimeoutChange{}, fmt.Errorf("error al ejecutar gsettings: %w", err)
+	}
+	fields := strings.Fields(string(output))
+	if len(fields) == 0 {
+		return displayTimeoutChange{}, errors.New("gsettings no devolvió idle-delay")
+	}
+	previous, err := strconv.Atoi(fields[len(fields)-1])
+	if err != nil {
+		return displayTimeoutChange{}, fmt.Errorf("valor de idle-delay no válido: %q", strings.TrimSpace(string(output)))
+	}
+	set := func(seconds int) error {
+		return runSettingCommand("gsettings", "set", "org.gnome.desktop.session", "idle-delay", strconv.Itoa(seconds))
+	}
+	if err := set(minutes * 60); err != nil {
+		return displayTimeoutChange{}, err
+	}
+	return displayTimeoutChange{Method: "gsettings idle-delay", Previous: previous, restore: func() error { return set(previous) }}, nil
+}
+
+// xsetSettings son los tiempos del salvapantallas y de DPMS según `xset q`:
+//
+//	Screen Saver:
+//	  prefer blanking:  yes    allow exposures:  yes
+//	  timeout:  600    cycle:  600
+//	DPMS (Energy Star):
+//	  Standby: 600    Suspend: 600    Off: 600
+//	  DPMS is Enabled
+type xsetSettings struct {
+	Saver                 int
+	Standby, Suspend, Off int
+	DPMS                  bool
+}
+
+var (
+	xsetSaverRegexp = regexp.MustCompile(`timeout:\s+(\d+)`)
+	xsetDPMSRegexp  = regexp.MustCompile(`Standby:\s+(\d+)\s+Suspend:\s+(\d+)\s+Off:\s+(\d+)`)
+)
+
+func readXsetSettings() (xsetSettings, error) {
+	output, err := exec.Command("xset", "q").Output()
+	if err != nil {
+		return xsetSettings{}, fmt.Errorf("error al ejecutar xset q: %w", err)
+	}
+	var s xsetSettings
+	if m := xsetSaverRegexp.FindStringSubmatch(string(output)); m != nil {
+		s.Saver, _ = strconv.Atoi(m[1])
+	}
+	if m := xsetDPMSRegexp.FindStringSubmatch(string(output)); m != nil {
+		s.Standby, _ = strconv.Atoi(m[1])
+		s.Suspend, _ = strconv.Atoi(m[2])
+		s.Off, _ = strconv.Atoi(m[3])
+		s.DPMS = strings.Contains(string(output), "DPMS is Enabled")
+	}
+	return s, nil
+}
+
+// Blank es lo que tarda en apagarse la pantalla: el primero de los tiempos
+// activos (0: nunca)
+func (s xsetSettings) Blank() int {
+	blank := s.Saver
+	if s.DPMS {
+		for _, t := range []int{s.Standby, s.Suspend, s.Off} {
+			if t > 0 && (blank == 0 || t < blank) {
+				blank = t
+			}
+		}
+	}
+	return blank
+}
+
+func (s xsetSettings) apply() error {
+	saver := []string{"s", strconv.Itoa(s.Saver)}
+	if s.Saver == 0 {
+		saver = []string{"s", "off"}
+	}
+	if err := runSettingCommand("xset", saver...); err != nil {
+		return err
+	}
+	if !s.DPMS {
+		return runSettingCommand("xset", "-dpms")
+	}
+	if err := runSettingCommand("xset", "dpms", strconv.Itoa(s.Standby), strconv.Itoa(s.Suspend), strconv.Itoa(s.Off)); err != nil {
+		return err
+	}
+	return runSettingCommand("xset", "+dpms")
+}
+
+func displayTimeoutX11(minutes int) (displayTimeoutChange, error) {
+	previous, err := readXsetSettings()
+	if err != nil {
+		return displayTimeoutChange{}, err
+	}
+	seconds := minutes * 60
+	next := xsetSettings{Saver: seconds, Standby: seconds, Suspend: seconds, Off: seconds, DPMS: seconds > 0}
+	if err := next.apply(); err != nil {
+		return displayTimeoutChange{}, err
+	}
+	return displayTimeoutChange{Method: "xset s/dpms", Previous: previous.Blank(), restore: previous.apply}, nil
+}
+
+// macDisplaySleepRegexp reconoce el ajuste en `pmset -g`: " displaysleep  10"
+var macDisplaySleepRegexp = regexp.MustCompile(`(?m)^\s*displaysleep\s+(\d+)`)
+
+// displayTimeoutCaffeinate es el id en processes.go del caffeinate con el
+// que se impide el apagado de la pantalla sin ser root
+var displayTimeoutCaffeinate string
+
+// displayTimeoutMac cambia displaysleep con pmset, que solo se puede hacer
+// como root. Sin privilegios solo se puede impedir que la pantalla se apague
+// (minutes 0) manteniendo un caffeinate -d mientras dure la sesión.
+func displayTimeoutMac(minutes int) (displayTimeoutChange, error) {
+	output, err := exec.Command("pmset", "-g").Output()
+	if err != nil {
+		return displayTimeoutChange{}, fmt.Errorf("error al ejecutar pmset -g: %w", err)
+	}
+	m := macDisplaySleepRegexp.FindStringSubmatch(string(output))
+	if m == nil {
+		return displayTimeoutChange{}, errors.New("pmset no informa de displaysleep")
+	}
+	previous, _ := strconv.Atoi(m[1])
+
+	if os.Geteuid() == 0 {
+		set := func(minutes int) error {
+			return runSettingCommand("pmset", "-a", "displaysleep", strconv.Itoa(minutes))
+		}
+		if err := set(minutes); err != nil {
+			return displayTimeoutChange{}, err
+		}
+		return displayTimeoutChange{Method: "pmset displaysleep", Previous: previous * 60, restore: func() error { return set(previous) }}, nil
+	}
+
+	if minutes != 0 {
+		return displayTimeoutChange{}, errors.New("cambiar displaysleep requiere ejecutar el servidor con sudo; sin privilegios solo se puede impedir que la pantalla se apague (minutes=0)")
+	}
+	restore := func() error {
+		_, err := stopTracked("display_timeout", displayTimeoutCaffeinate)
+		displayTimeoutCaffeinate = ""
+		return err
+	}
+	if displayTimeoutCaffeinate == "" || !isTracked(displayTimeoutCaffeinate) {
+		id, err := startTracked("display_timeout", exec.Command("caffeinate", "-d", "-w", strconv.Itoa(os.Getpid())))
+		if err != nil {
+			return displayTimeoutChange{}, fmt.Errorf("error al ejecutar caffeinate: %w", err)
+		}
+		displayTimeoutCaffeinate = id
+	}
+	return displayTimeoutChange{Method: "caffeinate -d (sin sudo no se puede cambiar displaysleep)", Previous: previous * 60, restore: restore}, nil
+}
+
+// powercfgValueRegexp reconoce los valores de `powercfg /query`; los dos
+// últimos son los actuales con corriente (AC) y con batería (DC)
+var powercfgValueRegexp = regexp.MustCompile(`0x([0-9a-fA-F]{8})`)
+
+// displayTimeoutWindows cambia el apagado de la pantalla del plan activo,
+// con corriente y con batería
+func displayTimeoutWindows(minutes int) (displayTimeoutChange, error) {
+	powercfg := windowsExecutable("powercfg.exe")
+	output, err := exec.Command(powercfg, "/query", "SCHEME_CURRENT", "SUB_VIDEO", "VIDEOIDLE").Output()
+	if err != nil {
+		return displayTimeoutChange{}, fmt.Errorf("error al ejecutar powercfg /query: %w", err)
+	}
+	values := powercfgValueRegexp.FindAllStringSubmatch(string(output), -1)
+	if len(values) < 2 {
+		return displayTimeoutChange{}, errors.New("powercfg no devolvió el tiempo de apagado de la pantalla")
+	}
+	ac, _ := strconv.ParseUint(values[len(values)-2][1], 16, 32)
+	dc, _ := strconv.ParseUint(values[len(values)-1][1], 16, 32)
+
+	for _, setting := range []string{"monitor-timeout-ac", "monitor-timeout-dc"} {
+		if err := runSettingCommand(powercfg, "/change", setting, strconv.Itoa(minutes)); err != nil {
+			return displayTimeoutChange{}, err
+		}
+	}
+	// /change solo admite minutos: se restauran los segundos exactos
+	restore := func() error {
+		if err := runSettingCommand(powercfg, "/setacvalueindex", "SCHEME_CURRENT", "SUB_VIDEO", "VIDEOIDLE", strconv.FormatUint(ac, 10)); err != nil {
+			return err
+		}
+		if err := runSettingCommand(powercfg, "/setdcvalueindex", "SCHEME_CURRENT", "SUB_VIDEO", "VIDEOIDLE", strconv.FormatUint(dc, 10)); err != nil {
+			return err
+		}
+		return runSettingCommand(powercfg, "/setactive", "SCHEME_CURRENT")
+	}
+	return displayTimeoutChange{Method: "powercfg monitor-timeout", Previous: int(ac), restore: restore}, nil
+}
+
+type SetDisplayTimeoutInput struct {
+	Minutes int `json:"minutes" jsonschema:"Minutos de inactividad tras los que se apaga la pantalla; 0 para no apagarla nunca"`
+}
+
+type DisplayTimeoutOutput struct {
+	Minutes         int    `json:"minutes" jsonschema:"Nuevo tiempo de apagado en minutos (0: nunca)"`
+	PreviousSeconds int    `json:"previous_seconds" jsonschema:"Tiempo de apagado anterior en segundos (0: nunca)"`
+	Method          string `json:"method" jsonschema:"Cómo se cambió el ajuste"`
+}
+
+func HandleSetDisplayTimeout(ctx context.Context, req *mcp.CallToolRequest, input SetDisplayTimeoutInput) (*mcp.CallToolResult, DisplayTimeoutOutput, error) {
+	change, err := setDisplayTimeout(input.Minutes)
+	if err != nil {
+		return nil, DisplayTimeoutOutput{}, fmt.Errorf("❌ Error al cambiar el tiempo de apagado de la pantalla: %w", err)
+	}
+	out := DisplayTimeoutOutput{Minutes: input.Minutes, PreviousSeconds: change.Previous, Method: change.Method}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: fmt.Sprintf("🖥️ Apagado de la pantalla: %s → %s (%s)\n↩️ restore_display_timeout vuelve al valor original",
+				describeDisplayTimeout(change.Previous), describeDisplayTimeout(input.Minutes*60), change.Method)},
+		},
+	}, out, nil
+}
+
+func HandleRestoreDisplayTimeout(ctx context.Context, req *mcp.CallToolRequest, input struct{}) (*mcp.CallToolResult, any, error) {
+	previous, err := restoreDisplayTimeout()
+	if err != nil {
+		return nil, nil, fmt.Errorf("❌ Error al restaurar el tiempo de apagado de la pantalla: %w", err)
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: "↩️ Apagado de la pantalla restaurado: " + describeDisplayTimeout(previous)},
+		},
+	}, nil, nil
+}
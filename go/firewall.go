@@ -0,0 +1,385 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Estados de get_firewall_status. requires_admin significa que no se pudo
+// saber: la consulta necesita privilegios, no que el cortafuegos esté apagado.
+const (
+	firewallEnabled       = "enabled"
+	firewallDisabled      = "disabled"
+	firewallRequiresAdmin = "requires_admin"
+)
+
+// errFirewallRequiresAdmin indica que la herramienta del cortafuegos rechazó
+// la consulta por falta de privilegios
+var errFirewallRequiresAdmin = errors.New("la consulta requiere privilegios de administrador")
+
+type FirewallProfile struct {
+	Name            string `json:"name" jsonschema:"Perfil de Windows: Domain, Private o Public"`
+	Enabled         bool   `json:"enabled" jsonschema:"El cortafuegos está activo en este perfil"`
+	Active          bool   `json:"active" jsonschema:"El perfil se aplica a alguna red conectada"`
+	DefaultInbound  string `json:"default_inbound,omitempty" jsonschema:"Acción por defecto para las conexiones entrantes (allow, block)"`
+	DefaultOutbound string `json:"default_outbound,omitempty" jsonschema:"Acción por defecto para las conexiones salientes (allow, block)"`
+}
+
+type FirewallStatusOutput struct {
+	Status         string            `json:"status" jsonschema:"enabled, disabled o requires_admin (no se pudo consultar sin privilegios)"`
+	Enabled        *bool             `json:"enabled" jsonschema:"El cortafuegos está activo (null si requiere administrador)"`
+	DefaultInbound string            `json:"default_inbound,omitempty" jsonschema:"Política por defecto para las conexiones entrantes (allow, deny, reject, drop, block o per-app en macOS)"`
+	Source         string            `json:"source,omitempty" jsonschema:"Herramienta consultada (ufw, firewalld, nftables, socketfilterfw, Windows Defender Firewall)"`
+	Zone           string            `json:"zone,omitempty" jsonschema:"Zona por defecto de firewalld"`
+	Profiles       []FirewallProfile `json:"profiles,omitempty" jsonschema:"Perfiles de Windows, que pueden tener estados distintos"`
+	Note           string            `json:"note,omitempty" jsonschema:"Aclaración sobre el resultado"`
+}
+
+// firewallPermissionRegexp reconoce los mensajes de falta de privilegios de
+// ufw ("You need to be root"), nft ("Operation not permitted"), polkit en
+// firewalld ("Authorization failed") y Windows ("Access is denied")
+var firewallPermissionRegexp = regexp.MustCompile(`(?i)need to be root|operation not permitted|permission denied|authorization failed|not authorized|access is denied|0x80070005`)
+
+// sbinCommand busca una herramienta de administración en PATH o en /usr/sbin
+// y /sbin, que no siempre están en el PATH de los usuarios normales
+func sbinCommand(name string) (string, bool) {
+	if path, err := exec.LookPath(name); err == nil {
+		return path, true
+	}
+	for _, dir := range []string{"/usr/sbin", "/sbin"} {
+		path := filepath.Join(dir, name)
+		if info, err := os.Stat(path); err == nil && info.Mode()&0o111 != 0 {
+			return path, true
+		}
+	}
+	return name, false
+}
+
+// runFirewallCommand ejecuta una consulta con la configuración regional C y
+// devuelve su salida combinada. Si falla por falta de privilegios el error
+// envuelve errFirewallRequiresAdmin.
+func runFirewallCommand(name string, args ...string) (string, error) {
+	cmd := exec.Command(name, args...)
+	cmd.Env = append(os.Environ(), "LC_ALL=C")
+	output, err := cmd.CombinedOutput()
+	text := strings.TrimSpace(string(output))
+	if err != nil && firewallPermissionRegexp.MatchString(text) {
+		return text, fmt.Errorf("%s: %w", filepath.Base(name), errFirewallRequiresAdmin)
+	}
+	return text, err
+}
+
+// newFirewallStatus rellena Status y Enabled de forma coherente
+func newFirewallStatus(source string, enabled bool) FirewallStatusOutput {
+	status := firewallDisabled
+	if enabled {
+		status = firewallEnabled
+	}
+	return FirewallStatusOutput{Status: status, Enabled: &enabled, Source: source}
+}
+
+// firewallStatusLinux consulta ufw, firewalld y nftables, por ese orden, y
+// devuelve el primero que esté activo. Si ninguno lo está pero alguno no se
+// pudo consultar sin privilegios, el resultado es requires_admin y no disabled.
+func firewallStatusLinux() (FirewallStatusOutput, error) {
+	checks := []struct {
+		Name, Command string
+		Query         func(path string) (FirewallStatusOutput, error)
+	}{
+		{"ufw", "ufw", firewallStatusUFW},
+		{"firewalld", "firewall-cmd", firewallStatusFirewalld},
+		{"nftables", "nft", firewallStatusNft},
+	}
+	var checked, denied []string
+	var failures []string
+	for _, c := range checks {
+		path, ok := sbinCommand(c.Command)
+		if !ok {
+			continue
+		}
+		status, err := c.Query(path)
+		switch {
+		case errors.Is(err, errFirewallRequiresAdmin):
+			denied = append(denied, c.Name)
+		case err != nil:
+			failures = append(failures, fmt.Sprintf("%s: %v", c.Name, err))
+		case status.Status == firewallEnabled:
+			return status, nil
+		default:
+			checked = append(checked, c.Name)
+		}
+	}
+
+	switch {
+	case len(denied) > 0:
+		return FirewallStatusOutput{
+			Status: firewallRequiresAdmin,
+			Source: strings.Join(denied, ", "),
+			Note:   fmt.Sprintf("%s solo se puede consultar como root: ejecuta el servidor con privilegios para conocer su estado", strings.Join(denied, " y ")),
+		}, nil
+	case len(checked) > 0:
+		status := newFirewallStatus(strings.Join(checked, ", "), false)
+		status.DefaultInbound = "allow"
+		return status, nil
+	case len(failures) > 0:
+		return FirewallStatusOutput{}, errors.New(strings.Join(failures, "; "))
+	}
+	return FirewallStatusOutput{}, errors.New("no se encontró ufw, firewalld ni nft")
+}
+
+// ufwDefaultRegexp reconoce "Default: deny (incoming), allow (outgoing), disabled (routed)"
+var ufwDefaultRegexp = regexp.MustCompile(`Default: (\w+) \(incoming\)`)
+
+// firewallStatusUFW usa `ufw status verbose`, que solo puede ejecutar root
+func firewallStatusUFW(ufw string) (FirewallStatusOutput, error) {
+	output, err := runFirewallCommand(ufw, "status", "verbose")
+	if err != nil {
+		return FirewallStatusOutput{}, err
+	}
+	status := newFirewallStatus("ufw", strings.Contains(output, "Status: active"))
+	if m := ufwDefaultRegexp.FindStringSubmatch(output); m != nil {
+		status.DefaultInbound = m[1]
+	}
+	return status, nil
+}
+
+// firewalldTargets traduce el target de la zona al nombre de la política
+// ("default" rechaza lo que no permiten los servicios de la zona)
+var firewalldTargets = map[string]string{
+	"default": "reject", "%%REJECT%%": "reject", "REJECT": "reject",
+	"DROP": "drop", "ACCEPT": "allow",
+}
+
+// firewallStatusFirewalld usa firewall-cmd, que termina con código 252 y
+// "not running" si el servicio está parado
+func firewallStatusFirewalld(firewallCmd string) (FirewallStatusOutput, error) {
+	output, err := runFirewallCommand(firewallCmd, "--state")
+	if errors.Is(err, errFirewallRequiresAdmin) {
+		return FirewallStatusOutput{}, err
+	}
+	if output != "running" {
+		if strings.Contains(output, "not running") {
+			return newFirewallStatus("firewalld", false), nil
+		}
+		return FirewallStatusOutput{}, fmt.Errorf("respuesta inesperada de firewall-cmd: %q", output)
+	}
+	status := newFirewallStatus("firewalld", true)
+	if zone, err := runFirewallCommand(firewallCmd, "--get-default-zone"); err == nil {
+		status.Zone = zone
+		if target, err := runFirewallCommand(firewallCmd, "--permanent", "--zone="+zone, "--get-target"); err == nil {
+			status.DefaultInbound = firewalldTargets[target]
+		}
+	}
+	return status, nil
+}
+
+// nftInputPolicyRegexp reconoce la declaración de una cadena de entrada:
+// "type filter hook input priority filter; policy drop;"
+var nftInputPolicyRegexp = regexp.MustCompile(`hook input\b.*policy (\w+);`)
+
+// firewallStatusNft interpreta `nft list ruleset` (requiere root). Se
+// considera activo si alguna cadena de entrada descarta por defecto o tiene
+// reglas que descartan o rechazan tráfico.
+func firewallStatusNft(nft string) (FirewallStatusOutput, error) {
+	output, err := runFirewallCommand(nft, "list", "ruleset")
+	if err != nil {
+		return FirewallStatusOutput{}, err
+	}
+	policy := ""
+	blocking, inInput := false, false
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case strings.HasPrefix(line, "chain "):
+			inInput = false
+		case nftInputPolicyRegexp.MatchString(line):
+			inInput = true
+			if p := nftInputPolicyRegexp.FindStringSubmatch(line)[1]; p != "accept" || policy == "" {
+				policy = p
+			}
+		case inInput && (strings.Contains(" "+line+" ", " drop ") || strings.Contains(" "+line+" ", " reject ")):
+			blocking = true
+		}
+	}
+	status := newFirewallStatus("nftables", policy == "drop" || policy == "reject" || blocking)
+	if policy == "accept" {
+		policy = "allow"
+		if blocking {
+			status.Note = "La cadena de entrada acepta por defecto, pero tiene reglas que descartan o rechazan tráfico"
+		}
+	}
+	status.DefaultInbound = policy
+	return status, nil
+}
+
+// macFirewallStateRegexp reconoce "Firewall is enabled. (State = 1)"; el
+// estado 2 es "bloquear todas las conexiones entrantes"
+var macFirewallStateRegexp = regexp.MustCompile(`State = (\d)`)
+
+// macSocketFilterFW es la utilidad del cortafuegos de aplicaciones de macOS
+const macSocketFilterFW = "/usr/libexec/ApplicationFirewall/socketfilterfw"
+
+// firewallStatusMac consulta el cortafuegos de aplicaciones, que filtra por
+// aplicación: si no bloquea todo, la política de entrada es per-app
+func firewallStatusMac() (FirewallStatusOutput, error) {
+	output, err := runFirewallCommand(macSocketFilterFW, "--getglobalstate")
+	if err != nil {
+		return FirewallStatusOutput{}, fmt.Errorf("error al ejecutar socketfilterfw: %w", err)
+	}
+	m := macFirewallStateRegexp.FindStringSubmatch(output)
+	if m == nil {
+		switch {
+		case strings.Contains(output, "enabled"):
+			m = []string{"", "1"}
+		case strings.Contains(output, "disabled"):
+			m = []string{"", "0"}
+		default:
+			return FirewallStatusOutput{}, fmt.Errorf("respuesta inesperada de socketfilterfw: %q", output)
+		}
+	}
+	status := newFirewallStatus("socketfilterfw", m[1] != "0")
+	if !*status.Enabled {
+		status.DefaultInbound = "allow"
+		return status, nil
+	}
+	status.DefaultInbound = "per-app"
+	if m[1] == "2" {
+		status.DefaultInbound = "block"
+	} else if output, err := runFirewallCommand(macSocketFilterFW, "--getblockall"); err == nil && strings.Contains(output, "enabled") {
+		status.DefaultInbound = "block"
+	}
+	return status, nil
+}
+
+// windowsFirewallScript lee la configuración efectiva (ActiveStore) de los
+// tres perfiles y qué perfiles usan las redes conectadas
+const windowsFirewallScript = `$active = @(Get-NetConnectionProfile -ErrorAction SilentlyContinue | ForEach-Object { ([string]$_.NetworkCategory) -replace 'Authenticated$', '' })
+ConvertTo-Json -Compress @(Get-NetFirewallProfile -PolicyStore ActiveStore -ErrorAction Stop | ForEach-Object {
+	@{ name = [string]$_.Name; enabled = ([string]$_.Enabled -eq 'True'); active = ($active -contains [string]$_.Name)
+	   inbound = [string]$_.DefaultInboundAction; outbound = [string]$_.DefaultOutboundAction }
+})`
+
+// firewallStatusWindows resume los perfiles: el cortafuegos está activo si
+// lo está en todos los perfiles de las redes conectadas (o en todos si no
+// hay ninguna red)
+func firewallStatusWindows() (FirewallStatusOutput, error) {
+	output, err := runPowerShell(windowsFirewallScript)
+	if err != nil {
+		if firewallPermissionRegexp.MatchString(err.Error()) {
+			return FirewallStatusOutput{Status: firewallRequiresAdmin, Source: "Windows Defender Firewall",
+				Note: "Windows no permitió leer la configuración del cortafuegos sin privilegios de administrador"}, nil
+		}
+		return FirewallStatusOutput{}, fmt.Errorf("error al consultar el cortafuegos: %w", err)
+	}
+	var profiles []struct {
+		Name              string
+		Enabled, Active   bool
+		Inbound, Outbound string
+	}
+	if err := json.Unmarshal([]byte(output), &profiles); err != nil {
+		return FirewallStatusOutput{}, fmt.Errorf("respuesta de PowerShell no válida: %w", err)
+	}
+
+	// NotConfigured en ActiveStore equivale a los valores por defecto de Windows
+	action := func(value, fallback string) string {
+		if value == "" || value == "NotConfigured" {
+			return fallback
+		}
+		return strings.ToLower(value)
+	}
+	anyActive := false
+	for _, p := range profiles {
+		anyActive = anyActive || p.Active
+	}
+	var list []FirewallProfile
+	enabled, inbound := true, "block"
+	for _, p := range profiles {
+		profile := FirewallProfile{
+			Name:            p.Name,
+			Enabled:         p.Enabled,
+			Active:          p.Active,
+			DefaultInbound:  action(p.Inbound, "block"),
+			DefaultOutbound: action(p.Outbound, "allow"),
+		}
+		list = append(list, profile)
+		if p.Active || !anyActive {
+			enabled = enabled && p.Enabled
+			if !p.Enabled || profile.DefaultInbound == "allow" {
+				inbound = "allow"
+			}
+		}
+	}
+	if len(list) == 0 {
+		return FirewallStatusOutput{}, errors.New("Get-NetFirewallProfile no devolvió ningún perfil")
+	}
+	status := newFirewallStatus("Windows Defender Firewall", enabled)
+	status.DefaultInbound, status.Profiles = inbound, list
+	return status, nil
+}
+
+func getFirewallStatus() (FirewallStatusOutput, error) {
+	switch currentPlatform() {
+	case platformWindows:
+		return firewallStatusWindows()
+	case platformWSL:
+		status, err := firewallStatusWindows()
+		status.Note = strings.TrimSpace("Cortafuegos del Windows anfitrión. " + status.Note)
+		return status, err
+	case platformMac:
+		return firewallStatusMac()
+	}
+	return firewallStatusLinux()
+}
+
+func HandleGetFirewallStatus(ctx context.Context, req *mcp.CallToolRequest, input struct{}) (*mcp.CallToolResult, FirewallStatusOutput, error) {
+	status, err := getFirewallStatus()
+	if err != nil {
+		return nil, FirewallStatusOutput{}, fmt.Errorf("❌ Error al consultar el cortafuegos: %w", err)
+	}
+
+	var lines []string
+	switch status.Status {
+	case firewallEnabled:
+		lines = append(lines, fmt.Sprintf("🛡️ Cortafuegos activo (%s)", status.Source))
+	case firewallDisabled:
+		lines = append(lines, fmt.Sprintf("⚠️ Cortafuegos desactivado (%s)", status.Source))
+	default:
+		lines = append(lines, fmt.Sprintf("🔒 Se requieren privilegios de administrador para consultar el cortafuegos (%s)", status.Source))
+	}
+	if status.Zone != "" {
+		lines = append(lines, "🗺️ Zona por defecto: "+status.Zone)
+	}
+	if status.DefaultInbound != "" {
+		lines = append(lines, "📥 Conexiones entrantes por defecto: "+status.DefaultInbound)
+	}
+	for _, p := range status.Profiles {
+		state := "desactivado"
+		if p.Enabled {
+			state = "activo"
+		}
+		line := fmt.Sprintf("  - %s: %s, entrantes %s, salientes %s", p.Name, state, p.DefaultInbound, p.DefaultOutbound)
+		if p.Active {
+			line += " (en uso)"
+		}
+		lines = append(lines, line)
+	}
+	if status.Note != "" {
+		lines = append(lines, "ℹ️ "+status.Note)
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: strings.Join(lines, "\n")},
+		},
+	}, status, nil
+}
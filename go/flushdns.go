@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// DNSCacheStep es el resultado de vaciar una de las cachés DNS del sistema
+type DNSCacheStep struct {
+	Cache   string `json:"cache" jsonschema:"Caché (systemd-resolved, nscd, dnsmasq, Directory Services, mDNSResponder o DNS Client de Windows)"`
+	Command string `json:"command" jsonschema:"Orden ejecutada"`
+	Flushed bool   `json:"flushed" jsonschema:"La orden terminó correctamente"`
+	Error   string `json:"error,omitempty" jsonschema:"Motivo del fallo"`
+}
+
+type FlushDNSOutput struct {
+	Flushed []string       `json:"flushed" jsonschema:"Cachés vaciadas"`
+	Steps   []DNSCacheStep `json:"steps" jsonschema:"Cada paso con su orden y resultado"`
+	Note    string         `json:"note,omitempty" jsonschema:"Aclaración sobre el resultado"`
+}
+
+// dnsFlushCommand es una orden que vacía una caché, con el texto que se
+// muestra en el resultado
+type dnsFlushCommand struct {
+	cache   string
+	name    string
+	args    []string
+	display string
+	// requiresRoot hace que el paso falle sin ejecutarse si el servidor no
+	// corre como root, porque la orden no distingue el fallo del éxito
+	requiresRoot bool
+}
+
+// processRunning indica si hay un proceso con ese nombre exacto
+func processRunning(name string) bool {
+	return commandExists("pgrep")() && exec.Command("pgrep", "-x", name).Run() == nil
+}
+
+// linuxDNSFlushCommands devuelve las órdenes de las cachés presentes. glibc
+// no guarda caché: sin systemd-resolved, nscd ni dnsmasq no hay nada que
+// vaciar.
+func linuxDNSFlushCommands() []dnsFlushCommand {
+	var commands []dnsFlushCommand
+	// /run/systemd/resolve solo existe con systemd-resolved en marcha
+	if _, err := os.Stat("/run/systemd/resolve"); err == nil {
+		switch {
+		case commandExists("resolvectl")():
+			commands = append(commands, dnsFlushCommand{cache: "systemd-resolved", name: "resolvectl", args: []string{"flush-caches"}})
+		case commandExists("systemd-resolve")():
+			commands = append(commands, dnsFlushCommand{cache: "systemd-resolved", name: "systemd-resolve", args: []string{"--flush-caches"}})
+		}
+	}
+	if processRunning("nscd") {
+		if nscd, ok := sbinCommand("nscd"); ok {
+			commands = append(commands, dnsFlushCommand{cache: "nscd", name: nscd, args: []string{"-i", "hosts"}, display: "nscd -i hosts"})
+		}
+	}
+	// dnsmasq vacía su caché al recibir SIGHUP; NetworkManager puede
+	// lanzar el suyo propio
+	if processRunning("dnsmasq") {
+		commands = append(commands, dnsFlushCommand{cache: "dnsmasq", name: "pkill", args: []string{"-HUP", "-x", "dnsmasq"}})
+	}
+	return commands
+}
+
+// dnsFlushCommands devuelve las órdenes a ejecutar en esta plataforma, en
+// orden
+func dnsFlushCommands() []dnsFlushCommand {
+	switch currentPlatform() {
+	case platformWindows:
+		return []dnsFlushCommand{{cache: "DNS Client de Windows", name: "ipconfig", args: []string{"/flushdns"}}}
+	case platformWSL:
+		// La resolución pasa por Windows, pero WSL puede tener su propia
+		// caché si corre systemd-resolved
+		return append(linuxDNSFlushCommands(), dnsFlushCommand{cache: "DNS Client de Windows", name: windowsExecutable("ipconfig.exe"), args: []string{"/flushdns"}, display: "ipconfig.exe /flushdns"})
+	case platformMac:
+		// killall no puede enviar señales a mDNSResponder, que es de otro
+		// usuario, sin sudo
+		return []dnsFlushCommand{
+			{cache: "Directory Services", name: "dscacheutil", args: []string{"-flushcache"}},
+			{cache: "mDNSResponder", name: "killall", args: []string{"-HUP", "mDNSResponder"}, requiresRoot: true},
+		}
+	}
+	return linuxDNSFlushCommands()
+}
+
+// flushDNS ejecuta cada orden por separado y anota su resultado
+func flushDNS() (FlushDNSOutput, error) {
+	out := FlushDNSOutput{Flushed: []string{}, Steps: []DNSCacheStep{}}
+	commands := dnsFlushCommands()
+	if len(commands) == 0 {
+		out.Note = "No hay ninguna caché DNS local (systemd-resolved, nscd o dnsmasq): cada consulta ya va al servidor DNS"
+		return out, nil
+	}
+
+	var failures []string
+	for _, c := range commands {
+		step := DNSCacheStep{Cache: c.cache, Command: c.display}
+		if step.Command == "" {
+			step.Command = strings.Join(append([]string{c.name}, c.args...), " ")
+		}
+		var err error
+		if c.requiresRoot && os.Geteuid() != 0 {
+			err = fmt.Errorf("requiere sudo: ejecuta `sudo %s` o inicia el servidor con sudo", step.Command)
+		} else {
+			_, err = runSystemCommand(c.name, c.args...)
+		}
+		if err != nil {
+			if errors.Is(err, errRequiresAdmin) {
+				err = fmt.Errorf("requiere privilegios de administrador (%s)", step.Command)
+			}
+			step.Error = err.Error()
+			failures = append(failures, fmt.Sprintf("%s: %s", c.cache, step.Error))
+		} else {
+			step.Flushed = true
+			out.Flushed = append(out.Flushed, c.cache)
+		}
+		out.Steps = append(out.Steps, step)
+	}
+	if len(out.Flushed) == 0 {
+		return FlushDNSOutput{}, fmt.Errorf("no se pudo vaciar ninguna caché: %s", strings.Join(failures, "; "))
+	}
+	if currentPlatform() == platformWSL {
+		out.Note = "Las consultas de WSL las resuelve Windows, así que vaciar su caché es lo que cuenta"
+	}
+	return out, nil
+}
+
+func HandleFlushDNS(ctx context.Context, req *mcp.CallToolRequest, input struct{}) (*mcp.CallToolResult, FlushDNSOutput, error) {
+	out, err := flushDNS()
+	if err != nil {
+		return nil, FlushDNSOutput{}, fmt.Errorf("❌ Error al vaciar la caché DNS: %w", err)
+	}
+
+	var lines []string
+	if len(out.Flushed) > 0 {
+		lines = append(lines, "🧹 Caché DNS vaciada: "+strings.Join(out.Flushed, ", "))
+	} else {
+		lines = append(lines, "🧹 No había ninguna caché DNS que vaciar")
+	}
+	for _, step := range out.Steps {
+		if !step.Flushed {
+			lines = append(lines, fmt.Sprintf("⚠️ %s no se vació: %s", step.Cache, step.Error))
+		}
+	}
+	if out.Note != "" {
+		lines = append(lines, "ℹ️ "+out.Note)
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: strings.Join(lines, "\n")},
+		},
+	}, out, nil
+}
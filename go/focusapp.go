@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// focusApplication trae al frente la ventana de una aplicación que ya está
+// en ejecución. Devuelve false si no está abierta o si no se encontró una
+// ventana que activar, en cuyo caso hay que lanzarla.
+func focusApplication(appName string) (bool, error) {
+	switch currentPlatform() {
+	case platformWindows, platformWSL:
+		return focusApplicationWindows(appName)
+	case platformMac:
+		out, err := exec.Command("osascript", "-e", fmt.Sprintf("application %q is running", appName)).Output()
+		if err != nil || strings.TrimSpace(string(out)) != "true" {
+			return false, nil
+		}
+		if _, err := runAppleScript(fmt.Sprintf("tell application %q to activate", appName)); err != nil {
+			return false, fmt.Errorf("AppleScript (%s): %w", appName, err)
+		}
+		return true, nil
+	}
+	return focusApplicationLinux(strings.Fields(appName)[0])
+}
+
+// focusApplicationLinux busca una ventana de alguno de los procesos con ese
+// nombre y la activa con wmctrl o, si no está, con xdotool
+func focusApplicationLinux(name string) (bool, error) {
+	pids, err := findProcesses(filepath.Base(name))
+	if err != nil || len(pids) == 0 {
+		return false, err
+	}
+
+	if commandExists("wmctrl")() {
+		output, err := exec.Command("wmctrl", "-lp").Output()
+		if err != nil {
+			return false, fmt.Errorf("error al ejecutar wmctrl -lp: %w", err)
+		}
+		running := make(map[string]bool)
+		for _, pid := range pids {
+			running[strconv.Itoa(pid)] = true
+		}
+		// "0x03a00003  0 12345  host Título": id de ventana, escritorio y pid
+		for _, line := range strings.Split(string(output), "\n") {
+			if fields := strings.Fields(line); len(fields) >= 3 && running[fields[2]] {
+				if err := exec.Command("wmctrl", "-i", "-a", fields[0]).Run(); err != nil {
+					return false, fmt.Errorf("error al activar la ventana con wmctrl: %w", err)
+				}
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+
+	if commandExists("xdotool")() {
+		for _, pid := range pids {
+			output, err := exec.Command("xdotool", "search", "--onlyvisible", "--pid", strconv.Itoa(pid)).Output()
+			if err != nil {
+				continue
+			}
+			if windows := strings.Fields(string(output)); len(windows) > 0 {
+				if err := exec.Command("xdotool", "windowactivate", windows[0]).Run(); err != nil {
+					return false, fmt.Errorf("error al activar la ventana con xdotool: %w", err)
+				}
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// focusApplicationWindows restaura y pone en primer plano la ventana
+// principal del proceso con SetForegroundWindow
+func focusApplicationWindows(appName string) (bool, error) {
+	if strings.HasPrefix(strings.ToLower(appName), strings.ToLower(appsFolderPrefix)) {
+		// No hay forma sencilla de saber qué proceso corresponde a un AppUserModelID
+		return false, nil
+	}
+	name := strings.TrimSuffix(appName, filepath.Ext(appName))
+	script := fmt.Sprintf(`$p = Get-Process -Name '%s' -ErrorAction SilentlyContinue | Where-Object { $_.MainWindowHandle -ne 0 } | Select-Object -First 1
+if (-not $p) { exit }
+Add-Type -Name Win -Namespace Focus -MemberDefinition '[DllImport("user32.dll")] public static extern bool SetForegroundWindow(System.IntPtr hWnd); [DllImport("user32.dll")] public static extern bool ShowWindowAsync(System.IntPtr hWnd, int cmd); [DllImport("user32.dll")] public static extern bool IsIconic(System.IntPtr hWnd);'
+if ([Focus.Win]::IsIconic($p.MainWindowHandle)) { [Focus.Win]::ShowWindowAsync($p.MainWindowHandle, 9) | Out-Null }
+[Focus.Win]::SetForegroundWindow($p.MainWindowHandle) | Out-Null
+$p.Id`, name)
+	output, err := runPowerShell(script)
+	if err != nil {
+		return false, fmt.Errorf("error al activar la ventana: %w", err)
+	}
+	return output != "", nil
+}
@@ -0,0 +1,392 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// GPUInfo describe una tarjeta gráfica. El uso y la temperatura son null
+// cuando la herramienta del fabricante no está instalada.
+type GPUInfo struct {
+	Name               string   `json:"name" jsonschema:"Modelo de la GPU"`
+	Vendor             string   `json:"vendor,omitempty" jsonschema:"Fabricante"`
+	Driver             string   `json:"driver,omitempty" jsonschema:"Driver o versión del driver"`
+	VRAMMB             *int64   `json:"vram_mb" jsonschema:"Memoria de vídeo en MB (null si no se puede saber)"`
+	UtilizationPercent *float64 `json:"utilization_percent" jsonschema:"Uso de la GPU en porcentaje (null sin nvidia-smi, rocm-smi o sysfs)"`
+	TemperatureC       *float64 `json:"temperature_c" jsonschema:"Temperatura en grados Celsius (null si no se puede saber)"`
+	Source             string   `json:"source" jsonschema:"Herramienta que ha proporcionado los datos"`
+}
+
+type GPUInfoOutput struct {
+	GPUs []GPUInfo `json:"gpus,omitempty" jsonschema:"Tarjetas gráficas del equipo"`
+}
+
+// parseOptionalFloat interpreta un valor numérico de nvidia-smi o rocm-smi,
+// que escriben "[N/A]" o "N/A" cuando no lo conocen
+func parseOptionalFloat(value string) *float64 {
+	v, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil {
+		return nil
+	}
+	return &v
+}
+
+// getGPUInfo devuelve las GPUs del equipo. En WSL se consultan las de
+// Windows, porque lspci no ve la tarjeta real.
+func getGPUInfo() ([]GPUInfo, error) {
+	var gpus []GPUInfo
+	var err error
+	switch currentPlatform() {
+	case platformWindows, platformWSL:
+		gpus, err = gpuInfoWindows()
+	case platformMac:
+		gpus, err = gpuInfoMac()
+	default:
+		gpus, err = gpuInfoLinux()
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(gpus) == 0 {
+		return nil, errors.New("no se encontró ninguna GPU")
+	}
+	return gpus, nil
+}
+
+// gpuInfoNvidiaSMI consulta las GPUs de NVIDIA con nvidia-smi. Devuelve nil
+// sin error si nvidia-smi no está instalado.
+func gpuInfoNvidiaSMI() ([]GPUInfo, error) {
+	if !commandExists("nvidia-smi")() {
+		return nil, nil
+	}
+	// "NVIDIA GeForce RTX 3060, 550.54.14, 12288, 5, 45"
+	output, err := exec.Command("nvidia-smi",
+		"--query-gpu=name,driver_version,memory.total,utilization.gpu,temperature.gpu",
+		"--format=csv,noheader,nounits").Output()
+	if err != nil {
+		return nil, fmt.Errorf("error al ejecutar nvidia-smi: %w", err)
+	}
+	var gpus []GPUInfo
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		fields := strings.Split(line, ",")
+		if len(fields) != 5 {
+			continue
+		}
+		gpu := GPUInfo{
+			Name:               strings.TrimSpace(fields[0]),
+			Vendor:             "NVIDIA",
+			Driver:             strings.TrimSpace(fields[1]),
+			UtilizationPercent: parseOptionalFloat(fields[3]),
+			TemperatureC:       parseOptionalFloat(fields[4]),
+			Source:             "nvidia-smi",
+		}
+		if vram := parseOptionalFloat(fields[2]); vram != nil {
+			mb := int64(*vram)
+			gpu.VRAMMB = &mb
+		}
+		gpus = append(gpus, gpu)
+	}
+	return gpus, nil
+}
+
+// gpuInfoRocmSMI consulta las GPUs de AMD con rocm-smi. Devuelve nil sin
+// error si rocm-smi no está instalado.
+func gpuInfoRocmSMI() ([]GPUInfo, error) {
+	if !commandExists("rocm-smi")() {
+		return nil, nil
+	}
+	output, err := exec.Command("rocm-smi", "--showproductname", "--showuse", "--showtemp",
+		"--showmeminfo", "vram", "--showdriverversion", "--json").Output()
+	if err != nil {
+		return nil, fmt.Errorf("error al ejecutar rocm-smi: %w", err)
+	}
+	// {"card0": {"Card series": "...", "GPU use (%)": "3", ...}, "system": {"Driver version": "6.2.0"}}
+	var result map[string]map[string]any
+	if err := json.Unmarshal(output, &result); err != nil {
+		return nil, fmt.Errorf("respuesta de rocm-smi no válida: %w", err)
+	}
+	driver := ""
+	if v, ok := result["system"]["Driver version"]; ok {
+		driver = fmt.Sprint(v)
+	}
+	var cards []string
+	for card := range result {
+		if strings.HasPrefix(card, "card") {
+			cards = append(cards, card)
+		}
+	}
+	slices.Sort(cards)
+
+	var gpus []GPUInfo
+	for _, card := range cards {
+		values := result[card]
+		value := func(key string) string {
+			if v, ok := values[key]; ok {
+				return fmt.Sprint(v)
+			}
+			return ""
+		}
+		gpu := GPUInfo{
+			Name:               value("Card series"),
+			Vendor:             "AMD",
+			Driver:             driver,
+			UtilizationPercent: parseOptionalFloat(value("GPU use (%)")),
+			Source:             "rocm-smi",
+		}
+		if gpu.Name == "" {
+			gpu.Name = card
+		}
+		// El nombre del sensor varía: "Temperature (Sensor edge) (C)", "... junction ..."
+		for key := range values {
+			if strings.HasPrefix(key, "Temperature (Sensor edge)") {
+				gpu.TemperatureC = parseOptionalFloat(value(key))
+			}
+		}
+		if vram := parseOptionalFloat(value("VRAM Total Memory (B)")); vram != nil {
+			mb := int64(*vram) / (1024 * 1024)
+			gpu.VRAMMB = &mb
+		}
+		gpus = append(gpus, gpu)
+	}
+	return gpus, nil
+}
+
+// lspciGPUClasses son las clases de dispositivo PCI de las tarjetas gráficas
+var lspciGPUClasses = map[string]bool{
+	"VGA compatible controller": true,
+	"3D controller":             true,
+	"Display controller":        true,
+}
+
+// gpuInfoLinux usa nvidia-smi y rocm-smi cuando están instalados y lspci
+// para el resto de GPUs. Las que solo aparecen en lspci se completan con
+// sysfs si el driver lo expone (amdgpu: VRAM y uso; hwmon: temperatura).
+func gpuInfoLinux() ([]GPUInfo, error) {
+	var gpus []GPUInfo
+	var errs []error
+	nvidia, err := gpuInfoNvidiaSMI()
+	if err != nil {
+		errs = append(errs, err)
+	}
+	amd, err := gpuInfoRocmSMI()
+	if err != nil {
+		errs = append(errs, err)
+	}
+	gpus = append(gpus, nvidia...)
+	gpus = append(gpus, amd...)
+
+	if !commandExists("lspci")() {
+		if len(gpus) == 0 {
+			errs = append(errs, errors.New("lspci no está instalado (paquete pciutils)"))
+			return nil, errors.Join(errs...)
+		}
+		return gpus, nil
+	}
+	// Slot:	00:02.0
+	// Class:	VGA compatible controller
+	// Vendor:	Intel Corporation
+	// Device:	Alder Lake-P GT2 [Iris Xe Graphics]
+	// Driver:	i915
+	output, err := exec.Command("lspci", "-vmmk").Output()
+	if err != nil {
+		errs = append(errs, fmt.Errorf("error al ejecutar lspci: %w", err))
+		if len(gpus) == 0 {
+			return nil, errors.Join(errs...)
+		}
+		return gpus, nil
+	}
+	for _, block := range strings.Split(string(output), "\n\n") {
+		device := map[string]string{}
+		for _, line := range strings.Split(block, "\n") {
+			if key, value, ok := strings.Cut(line, ":"); ok {
+				device[key] = strings.TrimSpace(value)
+			}
+		}
+		if !lspciGPUClasses[device["Class"]] {
+			continue
+		}
+		vendor := device["Vendor"]
+		if len(nvidia) > 0 && strings.Contains(vendor, "NVIDIA") ||
+			len(amd) > 0 && (strings.Contains(vendor, "AMD") || strings.Contains(vendor, "ATI")) {
+			continue
+		}
+		gpu := GPUInfo{Name: device["Device"], Vendor: vendor, Driver: device["Driver"], Source: "lspci"}
+		slot := device["Slot"]
+		if strings.Count(slot, ":") == 1 {
+			slot = "0000:" + slot
+		}
+		if enrichGPUFromSysfs(&gpu, filepath.Join("/sys/bus/pci/devices", slot)) {
+			gpu.Source = "lspci + sysfs"
+		}
+		gpus = append(gpus, gpu)
+	}
+	if len(gpus) == 0 && len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return gpus, nil
+}
+
+// enrichGPUFromSysfs completa la VRAM, el uso y la temperatura de una GPU con
+// los ficheros que exponen algunos drivers. Devuelve si encontró alguno.
+func enrichGPUFromSysfs(gpu *GPUInfo, dir string) bool {
+	found := false
+	if data, err := os.ReadFile(filepath.Join(dir, "mem_info_vram_total")); err == nil {
+		if bytes, err := strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64); err == nil && bytes > 0 {
+			mb := bytes / (1024 * 1024)
+			gpu.VRAMMB, found = &mb, true
+		}
+	}
+	if busy, err := readSysfsInt(filepath.Join(dir, "gpu_busy_percent")); err == nil {
+		percent := float64(busy)
+		gpu.UtilizationPercent, found = &percent, true
+	}
+	inputs, _ := filepath.Glob(filepath.Join(dir, "hwmon", "hwmon*", "temp1_input"))
+	for _, input := range inputs {
+		if milli, err := readSysfsInt(input); err == nil {
+			celsius := float64(milli) / 1000
+			gpu.TemperatureC, found = &celsius, true
+			break
+		}
+	}
+	return found
+}
+
+// gpuInfoMac interpreta `system_profiler SPDisplaysDataType -json`. En los Mac
+// con Apple Silicon la memoria es compartida y no hay VRAM que informar.
+func gpuInfoMac() ([]GPUInfo, error) {
+	output, err := exec.Command("system_profiler", "SPDisplaysDataType", "-json").Output()
+	if err != nil {
+		return nil, fmt.Errorf("error al ejecutar system_profiler: %w", err)
+	}
+	var result struct {
+		SPDisplaysDataType []struct {
+			Name       string `json:"_name"`
+			Model      string `json:"sppci_model"`
+			Vendor     string `json:"spdisplays_vendor"`
+			VRAM       string `json:"spdisplays_vram"`
+			VRAMShared string `json:"spdisplays_vram_shared"`
+			Metal      string `json:"spdisplays_mtlgpufamilysupport"`
+		}
+	}
+	if err := json.Unmarshal(output, &result); err != nil {
+		return nil, fmt.Errorf("respuesta de system_profiler no válida: %w", err)
+	}
+	var gpus []GPUInfo
+	for _, item := range result.SPDisplaysDataType {
+		gpu := GPUInfo{
+			Name:   item.Model,
+			Vendor: strings.TrimPrefix(item.Vendor, "sppci_vendor_"),
+			Source: "system_profiler",
+		}
+		if gpu.Name == "" {
+			gpu.Name = item.Name
+		}
+		// "spdisplays_metal3" -> "Metal 3"
+		if metal := strings.TrimPrefix(item.Metal, "spdisplays_metal"); metal != item.Metal {
+			gpu.Driver = strings.TrimSpace("Metal " + metal)
+		}
+		// "1536 MB", "8 GB"
+		vram := item.VRAM
+		if vram == "" {
+			vram = item.VRAMShared
+		}
+		if value, unit, ok := strings.Cut(vram, " "); ok {
+			if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+				if unit == "GB" {
+					n *= 1024
+				}
+				gpu.VRAMMB = &n
+			}
+		}
+		gpus = append(gpus, gpu)
+	}
+	return gpus, nil
+}
+
+// windowsGPUScript lista las tarjetas con Win32_VideoController. AdapterRAM
+// es de 32 bits y se queda en 4 GB, así que se prefiere el tamaño que el
+// driver guarda en el registro (HardwareInformation.qwMemorySize).
+const windowsGPUScript = `$mem = @{}
+Get-ItemProperty 'HKLM:\SYSTEM\CurrentControlSet\Control\Class\{4d36e968-e325-11ce-bfc1-08002be10318}\0*' -ErrorAction SilentlyContinue | ForEach-Object {
+	$size = $_.'HardwareInformation.qwMemorySize'
+	if ($size) { $mem[$_.DriverDesc] = [long]$size }
+}
+ConvertTo-Json -Compress @(Get-CimInstance Win32_VideoController | ForEach-Object {
+	$vram = $mem[$_.Name]
+	if (-not $vram) { $vram = [long]$_.AdapterRAM }
+	@{ name = $_.Name; vendor = "$($_.AdapterCompatibility)"; driver = "$($_.DriverVersion)"; vram = [long]$vram }
+})`
+
+// gpuInfoWindows combina Win32_VideoController con nvidia-smi, que sustituye
+// a las tarjetas de NVIDIA porque además da el uso y la temperatura
+func gpuInfoWindows() ([]GPUInfo, error) {
+	output, err := runPowerShell(windowsGPUScript)
+	if err != nil {
+		return nil, fmt.Errorf("error al consultar las tarjetas gráficas: %w", err)
+	}
+	var controllers []struct {
+		Name, Vendor, Driver string
+		VRAM                 int64
+	}
+	if err := json.Unmarshal([]byte(output), &controllers); err != nil {
+		return nil, fmt.Errorf("respuesta de PowerShell no válida: %w", err)
+	}
+	// Si nvidia-smi falla se usan los datos de Win32_VideoController
+	nvidia, _ := gpuInfoNvidiaSMI()
+	gpus := nvidia
+	for _, c := range controllers {
+		if len(nvidia) > 0 && strings.Contains(strings.ToUpper(c.Vendor), "NVIDIA") {
+			continue
+		}
+		gpu := GPUInfo{Name: c.Name, Vendor: c.Vendor, Driver: c.Driver, Source: "Win32_VideoController"}
+		if c.VRAM > 0 {
+			mb := c.VRAM / (1024 * 1024)
+			gpu.VRAMMB = &mb
+		}
+		gpus = append(gpus, gpu)
+	}
+	return gpus, nil
+}
+
+func HandleGetGPUInfo(ctx context.Context, req *mcp.CallToolRequest, input struct{}) (*mcp.CallToolResult, GPUInfoOutput, error) {
+	gpus, err := getGPUInfo()
+	if err != nil {
+		return nil, GPUInfoOutput{}, fmt.Errorf("❌ Error al obtener la información de la GPU: %w", err)
+	}
+	var lines []string
+	for i, gpu := range gpus {
+		var details []string
+		if gpu.Driver != "" {
+			details = append(details, "driver "+gpu.Driver)
+		}
+		if gpu.VRAMMB != nil {
+			details = append(details, formatKB(*gpu.VRAMMB*1024)+" de VRAM")
+		}
+		if gpu.UtilizationPercent != nil {
+			details = append(details, fmt.Sprintf("%.0f%% en uso", *gpu.UtilizationPercent))
+		}
+		if gpu.TemperatureC != nil {
+			details = append(details, fmt.Sprintf("%.0f °C", *gpu.TemperatureC))
+		}
+		line := fmt.Sprintf("🎮 GPU %d: %s", i+1, gpu.Name)
+		if len(details) > 0 {
+			line += " — " + strings.Join(details, ", ")
+		}
+		lines = append(lines, line+" (fuente: "+gpu.Source+")")
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: strings.Join(lines, "\n")},
+		},
+	}, GPUInfoOutput{GPUs: gpus}, nil
+}
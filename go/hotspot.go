@@ -0,0 +1,324 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// hotspotTimeout es lo que se espera a que el punto de acceso quede
+// encendido o apagado
+const hotspotTimeout = 20 * time.Second
+
+// errHotspotNotConfigured indica que no hay un punto de acceso configurado.
+// set_hotspot nunca recibe el nombre ni la contraseña de la red.
+var errHotspotNotConfigured = errors.New("no hay ningún punto de acceso configurado: créalo una vez con `nmcli device wifi hotspot ssid <nombre> password <contraseña>` (queda guardado como el perfil Hotspot) o desde los ajustes de Wi-Fi, y vuelve a intentarlo")
+
+// hotspotState es el estado del punto de acceso
+type hotspotState struct {
+	Enabled bool
+	SSID    string
+	Profile string // perfil de NetworkManager
+	Method  string
+
+	uuid string
+}
+
+// linuxHotspotProfiles devuelve los perfiles Wi-Fi de NetworkManager en modo
+// punto de acceso (mode=ap), con su SSID; Enabled indica si están activos
+func linuxHotspotProfiles() ([]hotspotState, error) {
+	if !commandExists("nmcli")() {
+		return nil, errors.New("requiere nmcli (NetworkManager)")
+	}
+	output, err := runSystemCommand("nmcli", "-t", "-f", "NAME,UUID,TYPE", "connection", "show")
+	if err != nil {
+		return nil, fmt.Errorf("error al consultar NetworkManager: %w", err)
+	}
+	active, err := runSystemCommand("nmcli", "-t", "-f", "UUID,STATE", "connection", "show", "--active")
+	if err != nil {
+		return nil, fmt.Errorf("error al consultar NetworkManager: %w", err)
+	}
+	activated := map[string]bool{}
+	for _, line := range strings.Split(active, "\n") {
+		if fields := splitNmcliFields(line); len(fields) == 2 && fields[1] == "activated" {
+			activated[fields[0]] = true
+		}
+	}
+
+	var profiles []hotspotState
+	for _, line := range strings.Split(output, "\n") {
+		fields := splitNmcliFields(line)
+		if len(fields) != 3 || fields[2] != "802-11-wireless" {
+			continue
+		}
+		if mode, err := runSystemCommand("nmcli", "-g", "802-11-wireless.mode", "connection", "show", "uuid", fields[1]); err != nil || mode != "ap" {
+			continue
+		}
+		ssid, _ := runSystemCommand("nmcli", "-g", "802-11-wireless.ssid", "connection", "show", "uuid", fields[1])
+		profiles = append(profiles, hotspotState{
+			Enabled: activated[fields[1]],
+			// -g también escapa los ':'
+			SSID:    strings.Join(splitNmcliFields(ssid), ":"),
+			Profile: fields[0],
+			Method:  "nmcli",
+			uuid:    fields[1],
+		})
+	}
+	return profiles, nil
+}
+
+// readHotspotLinux devuelve el perfil activo o, si no hay ninguno, el que se
+// encendería: el indicado en profile o el único que hay
+func readHotspotLinux(profile string) (hotspotState, error) {
+	profiles, err := linuxHotspotProfiles()
+	if err != nil {
+		return hotspotState{}, err
+	}
+	if len(profiles) == 0 {
+		return hotspotState{}, errHotspotNotConfigured
+	}
+	names := make([]string, len(profiles))
+	for i, p := range profiles {
+		names[i] = p.Profile
+		if profile != "" && strings.EqualFold(p.Profile, profile) {
+			return p, nil
+		}
+	}
+	if profile != "" {
+		return hotspotState{}, fmt.Errorf("no hay ningún punto de acceso llamado %q (configurados: %s)", profile, strings.Join(names, ", "))
+	}
+	for _, p := range profiles {
+		if p.Enabled {
+			return p, nil
+		}
+	}
+	if len(profiles) > 1 {
+		return hotspotState{}, fmt.Errorf("hay varios puntos de acceso configurados: %s. Indica cuál con profile", strings.Join(names, ", "))
+	}
+	return profiles[0], nil
+}
+
+// windowsTetheringScript carga la Zona con cobertura inalámbrica móvil de
+// WinRT (NetworkOperatorTetheringManager) para la conexión a Internet
+// actual. El nombre y la contraseña de la red son los de Configuración.
+const windowsTetheringScript = `
+$ErrorActionPreference = 'Stop'
+` + windowsWinRTPrelude + `
+[Windows.Networking.Connectivity.NetworkInformation,Windows.Networking.Connectivity,ContentType=WindowsRuntime] | Out-Null
+[Windows.Networking.NetworkOperators.NetworkOperatorTetheringManager,Windows.Networking.NetworkOperators,ContentType=WindowsRuntime] | Out-Null
+$connection = [Windows.Networking.Connectivity.NetworkInformation]::GetInternetConnectionProfile()
+if (-not $connection) { throw 'no hay ninguna conexión a Internet que compartir' }
+$capability = [Windows.Networking.NetworkOperators.NetworkOperatorTetheringManager]::GetTetheringCapabilityFromConnectionProfile($connection)
+if ([string]$capability -ne 'Enabled') { throw "Windows no permite compartir esta conexión ($capability)" }
+$manager = [Windows.Networking.NetworkOperators.NetworkOperatorTetheringManager]::CreateFromConnectionProfile($connection)
+$result = $null
+%s
+ConvertTo-Json -Compress @{
+	state = [string]$manager.TetheringOperationalState
+	ssid = $manager.GetCurrentAccessPointConfiguration().Ssid
+	status = [string]$result.Status
+	message = [string]$result.AdditionalErrorMessage
+}`
+
+// windowsTethering ejecuta windowsTetheringScript; action es "" (solo
+// leer), "Start" o "Stop"
+func windowsTethering(action string) (hotspotState, error) {
+	operation := ""
+	if action != "" {
+		operation = fmt.Sprintf("$result = Await ($manager.%sTetheringAsync()) ([Windows.Networking.NetworkOperators.NetworkOperatorTetheringOperationResult])", action)
+	}
+	output, err := runPowerShell(fmt.Sprintf(windowsTetheringScript, operation))
+	if err != nil {
+		return hotspotState{}, err
+	}
+	var result struct {
+		State   string `json:"state"`
+		SSID    string `json:"ssid"`
+		Status  string `json:"status"`
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal([]byte(output), &result); err != nil {
+		return hotspotState{}, fmt.Errorf("respuesta inesperada de PowerShell: %w", err)
+	}
+	if action != "" && result.Status != "Success" {
+		if result.Message != "" {
+			return hotspotState{}, fmt.Errorf("%s: %s", result.Status, result.Message)
+		}
+		return hotspotState{}, errors.New(result.Status)
+	}
+	return hotspotState{Enabled: result.State == "On", SSID: result.SSID, Method: "Zona con cobertura inalámbrica móvil"}, nil
+}
+
+// netshHostedSSIDRegexp y netshHostedStatusRegexp reconocen el SSID y el
+// estado de `netsh wlan show hostednetwork` (solo con Windows en inglés; las
+// etiquetas están traducidas)
+var (
+	netshHostedSSIDRegexp   = regexp.MustCompile(`SSID name\s*:\s*"(.*)"`)
+	netshHostedStatusRegexp = regexp.MustCompile(`(?m)^\s*Status\s*:\s*(.+?)\s*$`)
+)
+
+// readHostedNetwork lee la red hospedada de netsh, la forma anterior a la
+// Zona con cobertura que muchos controladores actuales ya no admiten
+func readHostedNetwork() (hotspotState, error) {
+	output, err := runSystemCommand(windowsExecutable("netsh.exe"), "wlan", "show", "hostednetwork")
+	if err != nil {
+		return hotspotState{}, err
+	}
+	status := netshHostedStatusRegexp.FindStringSubmatch(output)
+	ssid := netshHostedSSIDRegexp.FindStringSubmatch(output)
+	if status == nil || ssid == nil || status[1] == "Not available" {
+		return hotspotState{}, errors.New("el controlador Wi-Fi no admite la red hospedada")
+	}
+	return hotspotState{Enabled: status[1] == "Started", SSID: ssid[1], Method: "netsh hostednetwork"}, nil
+}
+
+// readHotspotWindows prueba la Zona con cobertura y, si no está
+// disponible, la red hospedada de netsh
+func readHotspotWindows() (hotspotState, error) {
+	state, err := windowsTethering("")
+	if err == nil {
+		return state, nil
+	}
+	hosted, hostedErr := readHostedNetwork()
+	if hostedErr != nil {
+		return hotspotState{}, fmt.Errorf("no está disponible la Zona con cobertura inalámbrica móvil (%v) ni la red hospedada de netsh (%v)", err, hostedErr)
+	}
+	return hosted, nil
+}
+
+// readHotspotState obtiene el estado del punto de acceso
+func readHotspotState(profile string) (hotspotState, error) {
+	switch currentPlatform() {
+	case platformWindows, platformWSL:
+		return readHotspotWindows()
+	case platformMac:
+		// Compartir Internet no tiene una orden ni una API pública
+		return hotspotState{}, errors.New("macOS no tiene una orden para compartir la conexión: actívalo en Ajustes del Sistema > General > Compartir > Compartir Internet, eligiendo Wi-Fi en \"A los ordenadores que usen\"")
+	}
+	return readHotspotLinux(profile)
+}
+
+// writeHotspotState enciende o apaga el punto de acceso con el mismo método
+// con el que se leyó
+func writeHotspotState(on bool, current hotspotState) error {
+	switch current.Method {
+	case "nmcli":
+		action := "down"
+		if on {
+			action = "up"
+		}
+		_, err := runSystemCommandTimeout(hotspotTimeout, "nmcli", "--wait", fmt.Sprint(int(hotspotTimeout.Seconds())), "connection", action, "uuid", current.uuid)
+		return err
+	case "netsh hostednetwork":
+		action := "stop"
+		if on {
+			action = "start"
+		}
+		_, err := runSystemCommand(windowsExecutable("netsh.exe"), "wlan", action, "hostednetwork")
+		return err
+	}
+	action := "Stop"
+	if on {
+		action = "Start"
+	}
+	_, err := windowsTethering(action)
+	return err
+}
+
+type SetHotspotInput struct {
+	State   string `json:"state" jsonschema:"on para compartir la conexión o off para dejar de hacerlo"`
+	Profile string `json:"profile,omitempty" jsonschema:"Perfil de punto de acceso de NetworkManager, si hay varios (solo Linux)"`
+}
+
+type SetHotspotOutput struct {
+	Enabled  bool   `json:"enabled" jsonschema:"El punto de acceso está encendido tras el cambio"`
+	Previous bool   `json:"previous" jsonschema:"Estado anterior"`
+	Changed  bool   `json:"changed" jsonschema:"Se cambió el estado (false si ya estaba así)"`
+	SSID     string `json:"ssid,omitempty" jsonschema:"Nombre de la red que se emite"`
+	Profile  string `json:"profile,omitempty" jsonschema:"Perfil de NetworkManager usado"`
+	Method   string `json:"method" jsonschema:"Método usado"`
+	Note     string `json:"note,omitempty" jsonschema:"Aclaración sobre el resultado"`
+}
+
+// setHotspot enciende o apaga el punto de acceso y lee el estado después
+func setHotspot(input SetHotspotInput) (SetHotspotOutput, error) {
+	state := strings.ToLower(strings.TrimSpace(input.State))
+	if state != "on" && state != "off" {
+		return SetHotspotOutput{}, fmt.Errorf("estado no válido %q: usa on u off", input.State)
+	}
+	on := state == "on"
+	current, err := readHotspotState(strings.TrimSpace(input.Profile))
+	if err != nil {
+		return SetHotspotOutput{}, err
+	}
+	out := SetHotspotOutput{Enabled: current.Enabled, Previous: current.Enabled, SSID: current.SSID, Profile: current.Profile, Method: current.Method}
+	switch {
+	case currentPlatform() == platformWSL:
+		out.Note = "Es el punto de acceso del Windows anfitrión"
+	case on && current.Method == "nmcli":
+		out.Note = "Mientras el punto de acceso está encendido la Wi-Fi no puede estar conectada a otra red: se comparte la conexión por cable o de datos móviles"
+	}
+	if current.Enabled == on {
+		return out, nil
+	}
+
+	if err := writeHotspotState(on, current); err != nil {
+		if errors.Is(err, errRequiresAdmin) {
+			return SetHotspotOutput{}, fmt.Errorf("no se pudo cambiar el punto de acceso: %w", err)
+		}
+		return SetHotspotOutput{}, fmt.Errorf("error al cambiar el punto de acceso: %w", err)
+	}
+	// El punto de acceso tarda en levantarse: solo cuenta el estado que se
+	// lee después
+	after := current
+	deadline := time.Now().Add(hotspotTimeout)
+	for after.Enabled != on && time.Now().Before(deadline) {
+		time.Sleep(time.Second)
+		if read, err := readHotspotState(current.Profile); err == nil {
+			after = read
+		}
+	}
+	if after.Enabled != on {
+		return SetHotspotOutput{}, fmt.Errorf("el sistema aceptó el cambio, pero el punto de acceso sigue %s tras %s", onOffBluetooth(after.Enabled), hotspotTimeout)
+	}
+	out.Enabled = after.Enabled
+	out.Changed = true
+	if after.SSID != "" {
+		out.SSID = after.SSID
+	}
+	return out, nil
+}
+
+func HandleSetHotspot(ctx context.Context, req *mcp.CallToolRequest, input SetHotspotInput) (*mcp.CallToolResult, SetHotspotOutput, error) {
+	out, err := setHotspot(input)
+	if err != nil {
+		return nil, SetHotspotOutput{}, fmt.Errorf("❌ Error al cambiar el punto de acceso: %w", err)
+	}
+
+	var line string
+	switch {
+	case out.Enabled && out.Changed:
+		line = fmt.Sprintf("📡 Punto de acceso encendido: emitiendo %q", out.SSID)
+	case out.Enabled:
+		line = fmt.Sprintf("📡 El punto de acceso ya estaba encendido: emitiendo %q", out.SSID)
+	case out.Changed:
+		line = "📡 Punto de acceso apagado"
+	default:
+		line = "📡 El punto de acceso ya estaba apagado"
+	}
+	lines := []string{line + fmt.Sprintf(" (%s)", out.Method)}
+	if out.Note != "" {
+		lines = append(lines, "ℹ️ "+out.Note)
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: strings.Join(lines, "\n")},
+		},
+	}, out, nil
+}
@@ -0,0 +1,285 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// InstalledApp es una aplicación instalada. LaunchID es el valor que hay que
+// pasar como app_name a open_app para abrirla.
+type InstalledApp struct {
+	Name     string `json:"name" jsonschema:"Nombre visible de la aplicación"`
+	LaunchID string `json:"launch_id" jsonschema:"Valor de app_name que acepta open_app para abrirla"`
+}
+
+// ListInstalledAppsOutput es la salida estructurada de list_installed_apps
+type ListInstalledAppsOutput struct {
+	Apps []InstalledApp `json:"apps,omitempty" jsonschema:"Aplicaciones instaladas, ordenadas por nombre"`
+}
+
+// installedAppsCacheTTL es el tiempo durante el que se reutiliza la lista de
+// aplicaciones instaladas, porque recorrerla es lento
+const installedAppsCacheTTL = 5 * time.Minute
+
+var (
+	installedAppsCacheMu   sync.Mutex
+	installedAppsCache     []InstalledApp
+	installedAppsCacheTime time.Time
+)
+
+// listInstalledApps devuelve las aplicaciones instaladas cuyo nombre o
+// launch_id contiene filter, reutilizando la última enumeración durante
+// installedAppsCacheTTL salvo que se pida refresh
+func listInstalledApps(filter string, refresh bool) ([]InstalledApp, error) {
+	installedAppsCacheMu.Lock()
+	defer installedAppsCacheMu.Unlock()
+	if refresh || installedAppsCache == nil || time.Since(installedAppsCacheTime) >= installedAppsCacheTTL {
+		var apps []InstalledApp
+		var err error
+		// Se sigue a open_app, que en WSL abre aplicaciones de Linux
+		switch osType {
+		case "windows":
+			apps, err = installedAppsWindows()
+		case "darwin":
+			apps = installedAppsMac()
+		default:
+			apps = installedAppsLinux()
+		}
+		if err != nil {
+			return nil, err
+		}
+		sort.Slice(apps, func(i, j int) bool {
+			return strings.ToLower(apps[i].Name) < strings.ToLower(apps[j].Name)
+		})
+		installedAppsCache = apps
+		installedAppsCacheTime = time.Now()
+	}
+
+	filter = strings.ToLower(strings.TrimSpace(filter))
+	var result []InstalledApp
+	for _, app := range installedAppsCache {
+		if filter == "" || strings.Contains(strings.ToLower(app.Name), filter) || strings.Contains(strings.ToLower(app.LaunchID), filter) {
+			result = append(result, app)
+		}
+	}
+	return result, nil
+}
+
+// installedAppsMac enumera los paquetes .app de /Applications,
+// /System/Applications y ~/Applications; open -a los abre por su nombre
+func installedAppsMac() []InstalledApp {
+	dirs := []string{"/Applications", "/Applications/Utilities", "/System/Applications", "/System/Applications/Utilities"}
+	if home, err := os.UserHomeDir(); err == nil {
+		dirs = append(dirs, filepath.Join(home, "Applications"))
+	}
+	seen := make(map[string]bool)
+	var apps []InstalledApp
+	for _, dir := range dirs {
+		bundles, _ := filepath.Glob(filepath.Join(dir, "*.app"))
+		for _, bundle := range bundles {
+			name := strings.TrimSuffix(filepath.Base(bundle), ".app")
+			if !seen[name] {
+				seen[name] = true
+				apps = append(apps, InstalledApp{Name: name, LaunchID: name})
+			}
+		}
+	}
+	return apps
+}
+
+// xdgDataDirs devuelve los directorios de datos XDG por orden de prioridad
+func xdgDataDirs() []string {
+	var dirs []string
+	if home := os.Getenv("XDG_DATA_HOME"); home != "" {
+		dirs = append(dirs, home)
+	} else if home, err := os.UserHomeDir(); err == nil {
+		dirs = append(dirs, filepath.Join(home, ".local", "share"))
+	}
+	system := os.Getenv("XDG_DATA_DIRS")
+	if system == "" {
+		system = "/usr/local/share:/usr/share"
+	}
+	return append(dirs, filepath.SplitList(system)...)
+}
+
+// installedAppsLinux lee los .desktop de <datos XDG>/applications. Un mismo
+// identificador de fichero en un directorio de más prioridad oculta a los
+// demás, como hacen los menús de los escritorios.
+func installedAppsLinux() []InstalledApp {
+	seen := make(map[string]bool)
+	var apps []InstalledApp
+	for _, dir := range xdgDataDirs() {
+		files, _ := filepath.Glob(filepath.Join(dir, "applications", "*.desktop"))
+		for _, file := range files {
+			id := filepath.Base(file)
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+			if app, ok := parseDesktopFile(file); ok {
+				apps = append(apps, app)
+			}
+		}
+	}
+	return apps
+}
+
+// readDesktopEntry lee las claves de la sección [Desktop Entry] de un
+// fichero .desktop. Las claves localizadas (Name[es]) se guardan aparte y,
+// si una clave se repite, vale la primera.
+func readDesktopEntry(path string) (map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	entry := make(map[string]string)
+	inEntry := false
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "[") {
+			inEntry = line == "[Desktop Entry]"
+			continue
+		}
+		if key, value, ok := strings.Cut(line, "="); ok && inEntry {
+			key = strings.TrimSpace(key)
+			if _, dup := entry[key]; !dup {
+				entry[key] = strings.TrimSpace(value)
+			}
+		}
+	}
+	return entry, scanner.Err()
+}
+
+// launchableDesktopEntry indica si la entrada es una aplicación visible en
+// los menús
+func launchableDesktopEntry(entry map[string]string) bool {
+	return entry["Type"] == "Application" && entry["NoDisplay"] != "true" && entry["Hidden"] != "true" && entry["Name"] != "" && entry["Exec"] != ""
+}
+
+// parseDesktopFile lee Name y Exec de un .desktop. Se descartan las entradas
+// ocultas, las que no son aplicaciones y aquellas cuya orden open_app no
+// podría ejecutar.
+func parseDesktopFile(path string) (InstalledApp, bool) {
+	entry, err := readDesktopEntry(path)
+	if err != nil || !launchableDesktopEntry(entry) {
+		return InstalledApp{}, false
+	}
+	launchID := desktopExecCommand(entry["Exec"])
+	if launchID == "" || validateAppName(launchID) != nil {
+		return InstalledApp{}, false
+	}
+	return InstalledApp{Name: entry["Name"], LaunchID: launchID}, true
+}
+
+// desktopExecArgs separa la clave Exec de un .desktop en argumentos y quita
+// los códigos de campo (%U, %f...), que solo tienen sentido al abrir ficheros
+func desktopExecArgs(exec string) []string {
+	args, err := splitCommandLine(exec)
+	if err != nil {
+		return nil
+	}
+	var result []string
+	for _, arg := range args {
+		if len(arg) == 2 && arg[0] == '%' && arg[1] != '%' {
+			continue
+		}
+		result = append(result, strings.ReplaceAll(arg, "%%", "%"))
+	}
+	return result
+}
+
+// desktopExecCommand convierte la clave Exec de un .desktop en la orden que
+// acepta open_app: sin los códigos de campo ni el prefijo "env VAR=valor".
+// open_app separa por espacios, así que la orden se corta en el primer
+// argumento que los lleve.
+func desktopExecCommand(exec string) string {
+	args := desktopExecArgs(exec)
+	if len(args) > 0 && args[0] == "env" {
+		args = args[1:]
+		for len(args) > 0 && strings.Contains(args[0], "=") {
+			args = args[1:]
+		}
+	}
+	var command []string
+	for _, arg := range args {
+		if strings.ContainsAny(arg, " \t") {
+			break
+		}
+		command = append(command, arg)
+	}
+	return strings.Join(command, " ")
+}
+
+// installedAppsWindows combina las aplicaciones del menú Inicio
+// (Get-StartApps), que se abren por su AppUserModelID desde
+// shell:AppsFolder, con las registradas en App Paths, que start encuentra
+// por el nombre del ejecutable
+func installedAppsWindows() ([]InstalledApp, error) {
+	script := `$apps = @()
+Get-StartApps | ForEach-Object { $apps += [pscustomobject]@{ name = $_.Name; launch_id = 'shell:AppsFolder\' + $_.AppID } }
+foreach ($root in 'HKLM:\SOFTWARE\Microsoft\Windows\CurrentVersion\App Paths','HKCU:\SOFTWARE\Microsoft\Windows\CurrentVersion\App Paths') {
+  Get-ChildItem $root -ErrorAction SilentlyContinue | ForEach-Object {
+    $exe = [IO.Path]::GetFileNameWithoutExtension($_.PSChildName)
+    $apps += [pscustomobject]@{ name = $exe; launch_id = $exe }
+  }
+}
+ConvertTo-Json -InputObject @($apps) -Compress`
+	output, err := runPowerShell(script)
+	if err != nil {
+		return nil, fmt.Errorf("error al consultar las aplicaciones instaladas: %w", err)
+	}
+	var all []InstalledApp
+	if err := json.Unmarshal([]byte(output), &all); err != nil {
+		return nil, fmt.Errorf("respuesta de PowerShell no válida: %w", err)
+	}
+	seen := make(map[string]bool)
+	var apps []InstalledApp
+	for _, app := range all {
+		key := strings.ToLower(app.LaunchID)
+		if !seen[key] && validateAppName(app.LaunchID) == nil {
+			seen[key] = true
+			apps = append(apps, app)
+		}
+	}
+	return apps, nil
+}
+
+type ListInstalledAppsInput struct {
+	Filter  string `json:"filter,omitempty" jsonschema:"Texto que debe contener el nombre o el launch_id, sin distinguir mayúsculas (opcional)"`
+	Refresh bool   `json:"refresh,omitempty" jsonschema:"Si es true vuelve a enumerar las aplicaciones en lugar de usar la lista guardada (se guarda 5 minutos)"`
+}
+
+func HandleListInstalledApps(ctx context.Context, req *mcp.CallToolRequest, input ListInstalledAppsInput) (*mcp.CallToolResult, ListInstalledAppsOutput, error) {
+	apps, err := listInstalledApps(input.Filter, input.Refresh)
+	if err != nil {
+		return nil, ListInstalledAppsOutput{}, fmt.Errorf("❌ Error al listar las aplicaciones instaladas: %w", err)
+	}
+	var result string
+	if len(apps) == 0 {
+		result = "📦 No hay ninguna aplicación instalada que coincida"
+	} else {
+		lines := []string{fmt.Sprintf("📦 %d aplicaciones instaladas (usa launch_id con open_app):", len(apps))}
+		for _, app := range apps {
+			lines = append(lines, fmt.Sprintf("  - %s → %s", app.Name, app.LaunchID))
+		}
+		result = strings.Join(lines, "\n")
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: result},
+		},
+	}, ListInstalledAppsOutput{Apps: apps}, nil
+}
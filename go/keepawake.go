@@ -0,0 +1,177 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// maxKeepAwake limita la duración de keep_awake con duration_minutes
+const maxKeepAwake = 24 * time.Hour
+
+// keepAwakeMu protege el inhibidor de reposo activo: el id del proceso en
+// el registro de processes.go y hasta cuándo dura (cero si hasta cancelarlo)
+var (
+	keepAwakeMu    sync.Mutex
+	keepAwakeID    string
+	keepAwakeUntil time.Time
+)
+
+// keepAwakeCommand devuelve la orden que mantiene el equipo despierto
+// durante duration (o hasta que se mate si es cero) y una descripción. La
+// orden termina sola si termina el servidor, para no dejar inhibidores
+// huérfanos aunque el servidor muera sin limpiar.
+func keepAwakeCommand(duration time.Duration) (*exec.Cmd, string, error) {
+	self := strconv.Itoa(os.Getpid())
+	seconds := strconv.Itoa(int(duration.Seconds()))
+	switch currentPlatform() {
+	case platformWindows, platformWSL:
+		// En WSL el pid del servidor no es de Windows: solo cuenta la duración
+		watch := "$true"
+		if currentPlatform() == platformWindows {
+			watch = fmt.Sprintf("(Get-Process -Id %s -ErrorAction SilentlyContinue)", self)
+		}
+		end := "[DateTime]::MaxValue"
+		if duration > 0 {
+			end = fmt.Sprintf("(Get-Date).AddSeconds(%s)", seconds)
+		}
+		// ES_CONTINUOUS | ES_SYSTEM_REQUIRED | ES_DISPLAY_REQUIRED; el estado
+		// se pierde al terminar el proceso
+		script := fmt.Sprintf(`Add-Type -Name Power -Namespace KeepAwake -MemberDefinition '[DllImport("kernel32.dll")] public static extern uint SetThreadExecutionState(uint flags);'
+$end = %s
+while (%s -and (Get-Date) -lt $end) {
+  [KeepAwake.Power]::SetThreadExecutionState(0x80000003) | Out-Null
+  Start-Sleep -Seconds 30
+}`, end, watch)
+		return exec.Command(powershellCommand(), "-NoProfile", "-NonInteractive", "-Command", script), "SetThreadExecutionState", nil
+	case platformMac:
+		args := []string{"-dimsu", "-w", self}
+		if duration > 0 {
+			args = append(args, "-t", seconds)
+		}
+		return exec.Command("caffeinate", args...), "caffeinate", nil
+	}
+	if !commandExists("systemd-inhibit")() {
+		return nil, "", errors.New("systemd-inhibit no está disponible")
+	}
+	args := []string{"--what=idle:sleep", "--who=hardware-control", "--why=keep_awake", "--mode=block"}
+	if duration > 0 {
+		args = append(args, "sleep", seconds)
+	} else {
+		args = append(args, "tail", "--pid="+self, "-f", "/dev/null")
+	}
+	return exec.Command("systemd-inhibit", args...), "systemd-inhibit", nil
+}
+
+// keepAwakeRemainingLocked describe cuánto le queda al inhibidor activo, o
+// "" si no hay ninguno. Requiere tener keepAwakeMu.
+func keepAwakeRemainingLocked() string {
+	if keepAwakeID == "" || !isTracked(keepAwakeID) {
+		keepAwakeID = ""
+		return ""
+	}
+	if keepAwakeUntil.IsZero() {
+		return "hasta que se cancele"
+	}
+	return fmt.Sprintf("quedaban %v", time.Until(keepAwakeUntil).Round(time.Second))
+}
+
+// keepAwake impide que el equipo se suspenda o apague la pantalla durante
+// duration, o hasta release_keep_awake si es cero. Si ya había uno activo
+// se sustituye, indicando cuánto le quedaba.
+func keepAwake(duration time.Duration) (string, error) {
+	if duration < 0 || duration > maxKeepAwake {
+		return "", fmt.Errorf("la duración debe estar entre 1 y %d minutos", int(maxKeepAwake.Minutes()))
+	}
+	cmd, method, err := keepAwakeCommand(duration)
+	if err != nil {
+		return "", err
+	}
+
+	keepAwakeMu.Lock()
+	defer keepAwakeMu.Unlock()
+	var note string
+	if remaining := keepAwakeRemainingLocked(); remaining != "" {
+		if _, err := stopTracked("keep_awake", keepAwakeID); err != nil {
+			return "", err
+		}
+		note = fmt.Sprintf("\n🔁 Ya había un keep_awake activo (%s); se sustituye por este", remaining)
+	}
+	id, err := startTracked("keep_awake", cmd)
+	if err != nil {
+		keepAwakeID = ""
+		return "", fmt.Errorf("error al ejecutar %s: %w", method, err)
+	}
+	keepAwakeID = id
+	keepAwakeUntil = time.Time{}
+	until := "hasta que se cancele"
+	if duration > 0 {
+		keepAwakeUntil = time.Now().Add(duration)
+		until = fmt.Sprintf("durante %v (hasta las %s)", duration, keepAwakeUntil.Format("15:04"))
+	}
+	return fmt.Sprintf("☕ El equipo se mantendrá despierto %s con %s; usa release_keep_awake para terminar antes%s", until, method, note), nil
+}
+
+// releaseKeepAwake termina el inhibidor de reposo activo
+func releaseKeepAwake() (string, error) {
+	keepAwakeMu.Lock()
+	defer keepAwakeMu.Unlock()
+	remaining := keepAwakeRemainingLocked()
+	if remaining == "" {
+		return "", errors.New("no hay ningún keep_awake activo")
+	}
+	if _, err := stopTracked("keep_awake", keepAwakeID); err != nil {
+		return "", err
+	}
+	keepAwakeID = ""
+	return fmt.Sprintf("😴 keep_awake terminado (%s); el equipo ya puede suspenderse", remaining), nil
+}
+
+// stopKeepAwake termina el inhibidor al cerrar el servidor
+func stopKeepAwake() {
+	keepAwakeMu.Lock()
+	defer keepAwakeMu.Unlock()
+	if keepAwakeID != "" {
+		stopTracked("keep_awake", keepAwakeID)
+		keepAwakeID = ""
+	}
+}
+
+type KeepAwakeInput struct {
+	DurationMinutes int  `json:"duration_minutes,omitempty" jsonschema:"Minutos que el equipo debe seguir despierto (máximo 1440)"`
+	UntilCancelled  bool `json:"until_cancelled,omitempty" jsonschema:"Si es true, mantenerlo despierto hasta llamar a release_keep_awake (o hasta que termine el servidor)"`
+}
+
+func HandleKeepAwake(ctx context.Context, req *mcp.CallToolRequest, input KeepAwakeInput) (*mcp.CallToolResult, any, error) {
+	if (input.DurationMinutes > 0) == input.UntilCancelled {
+		return nil, nil, errors.New("❌ Error al mantener el equipo despierto: indica duration_minutes o until_cancelled, pero no ambos")
+	}
+	result, err := keepAwake(time.Duration(input.DurationMinutes) * time.Minute)
+	if err != nil {
+		return nil, nil, fmt.Errorf("❌ Error al mantener el equipo despierto: %w", err)
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: result},
+		},
+	}, nil, nil
+}
+
+func HandleReleaseKeepAwake(ctx context.Context, req *mcp.CallToolRequest, input struct{}) (*mcp.CallToolResult, any, error) {
+	result, err := releaseKeepAwake()
+	if err != nil {
+		return nil, nil, fmt.Errorf("❌ Error al terminar keep_awake: %w", err)
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: result},
+		},
+	}, nil, nil
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// findDesktopEntry busca un .desktop cuyo identificador ("firefox" o
+// "org.mozilla.firefox.desktop") o nombre visible ("Google Chrome")
+// coincida con name, sin distinguir mayúsculas. Los identificadores tienen
+// prioridad sobre los nombres, y los directorios XDG del usuario sobre los
+// del sistema.
+func findDesktopEntry(name string) (string, map[string]string) {
+	id := strings.ToLower(strings.TrimSuffix(name, ".desktop")) + ".desktop"
+	seen := make(map[string]bool)
+	var byName string
+	var byNameEntry map[string]string
+	for _, dir := range xdgDataDirs() {
+		files, _ := filepath.Glob(filepath.Join(dir, "applications", "*.desktop"))
+		for _, file := range files {
+			base := strings.ToLower(filepath.Base(file))
+			if seen[base] {
+				continue
+			}
+			seen[base] = true
+			entry, err := readDesktopEntry(file)
+			if err != nil || !launchableDesktopEntry(entry) {
+				continue
+			}
+			if base == id {
+				return file, entry
+			}
+			if byName == "" && strings.EqualFold(entry["Name"], name) {
+				byName, byNameEntry = file, entry
+			}
+		}
+	}
+	return byName, byNameEntry
+}
+
+// resolveLinuxApp decide cómo lanzar appName en Linux y devuelve la orden,
+// a la que se añaden después los argumentos, y el mecanismo usado. Prueba
+// por orden: los .desktop (por identificador o nombre visible), flatpak,
+// snap y el PATH. El nombre puede llevar argumentos separados por espacios
+// ("code --new-window") para las órdenes del PATH.
+func resolveLinuxApp(appName string) ([]string, string, error) {
+	if file, entry := findDesktopEntry(appName); file != "" {
+		if argv := desktopExecArgs(entry["Exec"]); len(argv) > 0 {
+			if path, err := exec.LookPath(argv[0]); err == nil {
+				return append([]string{path}, argv[1:]...), filepath.Base(file), nil
+			}
+		}
+	}
+
+	fields := strings.Fields(appName)
+	// Los identificadores de flatpak tienen al menos tres partes (org.gnome.Calculator)
+	if len(fields) == 1 && strings.Count(appName, ".") >= 2 && commandExists("flatpak")() {
+		if exec.Command("flatpak", "info", appName).Run() == nil {
+			return []string{"flatpak", "run", appName}, "flatpak", nil
+		}
+	}
+	if len(fields) == 1 && commandExists("snap")() {
+		if _, err := os.Stat(filepath.Join("/snap/bin", appName)); err == nil {
+			return []string{"snap", "run", appName}, "snap", nil
+		}
+	}
+
+	path, err := exec.LookPath(fields[0])
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: '%s'", errAppNotFound, fields[0])
+	}
+	return append([]string{path}, fields[1:]...), "PATH", nil
+}
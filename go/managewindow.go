@@ -0,0 +1,529 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// windowActions describe cada acción de manage_window para el mensaje de resultado
+var windowActions = map[string]string{
+	"minimize":        "minimizadas",
+	"maximize":        "maximizadas",
+	"restore":         "restauradas",
+	"fullscreen":      "a pantalla completa",
+	"move_to_display": "movidas",
+}
+
+// screenRect es el área útil de una pantalla en coordenadas del escritorio
+// (origen arriba a la izquierda de la pantalla principal)
+type screenRect struct {
+	Name                string
+	X, Y, Width, Height int
+}
+
+// selectScreen elige la pantalla de destino de move_to_display por índice
+// o por nombre (en Windows vale "DISPLAY2" por "\\.\DISPLAY2")
+func selectScreen(screens []screenRect, display string) (screenRect, error) {
+	names := make([]string, len(screens))
+	for i, s := range screens {
+		names[i] = fmt.Sprintf("%d: %s", i, s.Name)
+	}
+	available := strings.Join(names, ", ")
+	if len(screens) == 0 {
+		return screenRect{}, errors.New("no se encontraron pantallas")
+	}
+	if display == "" {
+		return screenRect{}, fmt.Errorf("indica la pantalla de destino en display (%s)", available)
+	}
+	if index, err := strconv.Atoi(display); err == nil {
+		if index < 0 || index >= len(screens) {
+			return screenRect{}, fmt.Errorf("índice de pantalla %d fuera de rango (%s)", index, available)
+		}
+		return screens[index], nil
+	}
+	for _, s := range screens {
+		if strings.EqualFold(s.Name, display) || strings.EqualFold(strings.TrimPrefix(s.Name, `\\.\`), display) {
+			return s, nil
+		}
+	}
+	return screenRect{}, fmt.Errorf("no se encontró la pantalla %q (%s)", display, available)
+}
+
+// manageWindow aplica action a las ventanas de appName o cuyo título
+// contiene title (o que cumplen ambas cosas). Sin index se aplica a todas
+// las que coinciden; con index, solo a esa (empezando en 0).
+func manageWindow(appName, title, action, display string, index *int) (string, error) {
+	appName, title = strings.TrimSpace(appName), strings.TrimSpace(title)
+	action = strings.ToLower(strings.TrimSpace(action))
+	done, ok := windowActions[action]
+	if !ok {
+		return "", fmt.Errorf("acción no válida %q: usa minimize, maximize, restore, fullscreen o move_to_display", action)
+	}
+	if appName == "" && title == "" {
+		return "", errors.New("indica app_name, title o ambos")
+	}
+	if appName != "" {
+		if err := validateAppName(appName); err != nil {
+			return "", err
+		}
+	}
+	selected := -1
+	if index != nil {
+		if *index < 0 {
+			return "", fmt.Errorf("índice de ventana %d no válido", *index)
+		}
+		selected = *index
+	}
+
+	var target screenRect
+	var applied, total int
+	var err error
+	switch currentPlatform() {
+	case platformWindows, platformWSL:
+		if action == "move_to_display" {
+			if target, err = windowsScreenForMove(display); err != nil {
+				return "", err
+			}
+		}
+		applied, total, err = manageWindowWindows(appName, title, action, selected, target)
+	case platformMac:
+		var screens []screenRect
+		if action == "maximize" || action == "move_to_display" {
+			if screens, err = macScreens(); err != nil {
+				return "", err
+			}
+		}
+		if action == "move_to_display" {
+			if target, err = selectScreen(screens, display); err != nil {
+				return "", err
+			}
+		}
+		applied, total, err = manageWindowMac(appName, title, action, selected, screens, target)
+	default:
+		applied, total, target, err = manageWindowLinux(appName, title, action, display, selected)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	var what []string
+	if appName != "" {
+		what = append(what, fmt.Sprintf("'%s'", appName))
+	}
+	if title != "" {
+		what = append(what, fmt.Sprintf("con título '%s'", title))
+	}
+	switch {
+	case total == 0:
+		return "", fmt.Errorf("no se encontró ninguna ventana %s", strings.Join(what, " "))
+	case applied == 0:
+		return "", fmt.Errorf("índice de ventana %d fuera de rango (hay %d ventanas %s)", selected, total, strings.Join(what, " "))
+	}
+	if action == "move_to_display" {
+		done += " a " + target.Name
+	}
+	return fmt.Sprintf("🪟 Ventanas %s: %d de %d (%s)", done, applied, total, strings.Join(what, " ")), nil
+}
+
+// linuxWindow es una ventana según `wmctrl -lpGx`
+type linuxWindow struct {
+	ID, Class, Title         string
+	PID, X, Y, Width, Height int
+}
+
+// listLinuxWindows lista las ventanas gestionadas por el gestor de ventanas,
+// salvo las fijas en todos los escritorios (paneles y docks)
+func listLinuxWindows() ([]linuxWindow, error) {
+	output, err := exec.Command("wmctrl", "-lpGx").Output()
+	if err != nil {
+		return nil, fmt.Errorf("error al ejecutar wmctrl -lpGx: %w", err)
+	}
+	var windows []linuxWindow
+	// "0x03a00003  0 12345  0 0 1920 1080  slack.Slack  host Título":
+	// id, escritorio, pid, geometría, WM_CLASS, equipo y título
+	for _, line := range strings.Split(string(output), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 9 || fields[1] == "-1" {
+			continue
+		}
+		w := linuxWindow{ID: fields[0], Class: fields[7], Title: strings.Join(fields[9:], " ")}
+		w.PID, _ = strconv.Atoi(fields[2])
+		w.X, _ = strconv.Atoi(fields[3])
+		w.Y, _ = strconv.Atoi(fields[4])
+		w.Width, _ = strconv.Atoi(fields[5])
+		w.Height, _ = strconv.Atoi(fields[6])
+		windows = append(windows, w)
+	}
+	return windows, nil
+}
+
+// manageWindowLinux actúa sobre las ventanas con wmctrl (y xdotool para
+// minimizar). Una ventana es de appName si la abrió un proceso con ese
+// nombre o si su WM_CLASS coincide, lo que cubre Flatpak y Electron.
+func manageWindowLinux(appName, title, action, display string, selected int) (int, int, screenRect, error) {
+	if session := detectLinuxSession(); session.Wayland {
+		return 0, 0, screenRect{}, fmt.Errorf("manage_window no está disponible en Wayland (compositor: %s): el compositor no deja que otras aplicaciones muevan sus ventanas", session.Compositor())
+	}
+	if err := checkGraphicalSession(); err != nil {
+		return 0, 0, screenRect{}, err
+	}
+	if !commandExists("wmctrl")() {
+		return 0, 0, screenRect{}, errors.New("wmctrl no está instalado")
+	}
+
+	var screens []screenRect
+	var target screenRect
+	if action == "move_to_display" {
+		outputs, err := listXrandrOutputs()
+		if err != nil {
+			return 0, 0, screenRect{}, err
+		}
+		for _, o := range outputs {
+			if o.Width > 0 {
+				screens = append(screens, screenRect{Name: o.Name, X: o.X, Y: o.Y, Width: o.Width, Height: o.Height})
+			}
+		}
+		if target, err = selectScreen(screens, display); err != nil {
+			return 0, 0, screenRect{}, err
+		}
+	}
+
+	windows, err := listLinuxWindows()
+	if err != nil {
+		return 0, 0, screenRect{}, err
+	}
+	var pids map[int]bool
+	var name string
+	if appName != "" {
+		name = filepath.Base(strings.Fields(appName)[0])
+		found, err := findProcesses(name)
+		if err != nil {
+			return 0, 0, screenRect{}, err
+		}
+		pids = make(map[int]bool)
+		for _, pid := range found {
+			pids[pid] = true
+		}
+	}
+	var matched []linuxWindow
+	for _, w := range windows {
+		instance, class, _ := strings.Cut(w.Class, ".")
+		if name != "" && !pids[w.PID] && !strings.EqualFold(instance, name) && !strings.EqualFold(class, name) {
+			continue
+		}
+		if title != "" && !strings.Contains(strings.ToLower(w.Title), strings.ToLower(title)) {
+			continue
+		}
+		matched = append(matched, w)
+	}
+
+	total := len(matched)
+	if selected >= 0 {
+		if selected >= total {
+			return 0, total, target, nil
+		}
+		matched = matched[selected : selected+1]
+	}
+	for _, w := range matched {
+		if err := linuxWindowAction(w, action, screens, target); err != nil {
+			return 0, total, target, fmt.Errorf("error al aplicar %s a la ventana %s: %w", action, w.ID, err)
+		}
+	}
+	return len(matched), total, target, nil
+}
+
+// linuxWindowAction aplica una acción a una ventana con wmctrl
+func linuxWindowAction(w linuxWindow, action string, screens []screenRect, target screenRect) error {
+	wmctrl := func(args ...string) error {
+		return exec.Command("wmctrl", append([]string{"-i", "-r", w.ID}, args...)...).Run()
+	}
+	switch action {
+	case "minimize":
+		if commandExists("xdotool")() {
+			return exec.Command("xdotool", "windowminimize", w.ID).Run()
+		}
+		return wmctrl("-b", "add,hidden")
+	case "maximize":
+		return wmctrl("-b", "add,maximized_vert,maximized_horz")
+	case "fullscreen":
+		return wmctrl("-b", "add,fullscreen")
+	case "restore":
+		// wmctrl -b admite como mucho dos propiedades por llamada
+		if err := wmctrl("-b", "remove,fullscreen"); err != nil {
+			return err
+		}
+		if err := wmctrl("-b", "remove,maximized_vert,maximized_horz"); err != nil {
+			return err
+		}
+		// Activarla la saca también de la barra de tareas si estaba minimizada
+		return exec.Command("wmctrl", "-i", "-a", w.ID).Run()
+	}
+
+	// move_to_display: se conserva la posición relativa a la pantalla en la
+	// que está y, si estaba maximizada, se vuelve a maximizar en la nueva
+	maximized := false
+	if commandExists("xprop")() {
+		state, _ := exec.Command("xprop", "-id", w.ID, "_NET_WM_STATE").Output()
+		maximized = strings.Contains(string(state), "_NET_WM_STATE_MAXIMIZED_HORZ")
+	}
+	if maximized {
+		if err := wmctrl("-b", "remove,maximized_vert,maximized_horz"); err != nil {
+			return err
+		}
+	}
+	source := screens[0]
+	centerX, centerY := w.X+w.Width/2, w.Y+w.Height/2
+	for _, s := range screens {
+		if centerX >= s.X && centerX < s.X+s.Width && centerY >= s.Y && centerY < s.Y+s.Height {
+			source = s
+			break
+		}
+	}
+	x := max(target.X, min(target.X+w.X-source.X, target.X+target.Width-w.Width))
+	y := max(target.Y, min(target.Y+w.Y-source.Y, target.Y+target.Height-w.Height))
+	if err := wmctrl("-e", fmt.Sprintf("0,%d,%d,-1,-1", x, y)); err != nil {
+		return err
+	}
+	if maximized {
+		return wmctrl("-b", "add,maximized_vert,maximized_horz")
+	}
+	return nil
+}
+
+// macScreens devuelve el área útil de cada pantalla (sin barra de menús ni
+// Dock) según NSScreen, pasada a coordenadas de System Events, que tienen el
+// origen arriba a la izquierda en lugar de abajo
+func macScreens() ([]screenRect, error) {
+	script := `ObjC.import('AppKit');
+var screens = $.NSScreen.screens, top = screens.objectAtIndex(0).frame.size.height, out = [];
+for (var i = 0; i < screens.count; i++) {
+  var s = screens.objectAtIndex(i), f = s.visibleFrame;
+  out.push([f.origin.x, top - f.origin.y - f.size.height, f.size.width, f.size.height, s.localizedName.js].join('|'));
+}
+out.join('\n')`
+	output, err := exec.Command("osascript", "-l", "JavaScript", "-e", script).Output()
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener las pantallas: %w", err)
+	}
+	var screens []screenRect
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		fields := strings.SplitN(line, "|", 5)
+		if len(fields) < 5 {
+			continue
+		}
+		var values [4]int
+		for i := range values {
+			f, _ := strconv.ParseFloat(fields[i], 64)
+			values[i] = int(f)
+		}
+		screens = append(screens, screenRect{Name: fields[4], X: values[0], Y: values[1], Width: values[2], Height: values[3]})
+	}
+	return screens, nil
+}
+
+// macManageWindowScript recorre las ventanas con System Events y aplica la
+// acción. Recibe las pantallas, la de destino, los procesos, la condición
+// sobre el título, el índice (-1 para todas) y la acción; devuelve
+// "aplicadas|coincidentes".
+const macManageWindowScript = `on screenFor(pos, frames)
+	repeat with s in frames
+		if (item 1 of pos) >= (item 1 of s) and (item 1 of pos) < (item 1 of s) + (item 3 of s) and (item 2 of pos) >= (item 2 of s) and (item 2 of pos) < (item 2 of s) + (item 4 of s) then return contents of s
+	end repeat
+	return item 1 of frames
+end screenFor
+
+on clampTo(v, low, high)
+	if v > high then set v to high
+	if v < low then set v to low
+	return v
+end clampTo
+
+set screenFrames to {%[1]s}
+set targetFrame to {%[2]s}
+set matched to {}
+tell application "System Events"
+	repeat with p in (%[3]s)
+		repeat with w in (windows of p)
+			if %[4]s then set end of matched to contents of w
+		end repeat
+	end repeat
+	set total to count of matched
+	set selected to matched
+	if %[5]d >= 0 then
+		if %[5]d >= total then return "0|" & total
+		set selected to {item (%[5]d + 1) of matched}
+	end if
+	repeat with w in selected
+%[6]s
+	end repeat
+	return ((count of selected) as text) & "|" & (total as text)
+end tell`
+
+// macWindowActions son las órdenes de AppleScript de cada acción sobre w
+var macWindowActions = map[string]string{
+	"minimize": `		set value of attribute "AXMinimized" of w to true`,
+	"restore": `		try
+			set value of attribute "AXFullScreen" of w to false
+		end try
+		set value of attribute "AXMinimized" of w to false`,
+	"fullscreen": `		set value of attribute "AXFullScreen" of w to true`,
+	"maximize": `		set s to my screenFor(position of w, screenFrames)
+		set position of w to {item 1 of s, item 2 of s}
+		set size of w to {item 3 of s, item 4 of s}`,
+	"move_to_display": `		set pos to position of w
+		set sz to size of w
+		set s to my screenFor(pos, screenFrames)
+		set x to my clampTo((item 1 of targetFrame) + (item 1 of pos) - (item 1 of s), item 1 of targetFrame, (item 1 of targetFrame) + (item 3 of targetFrame) - (item 1 of sz))
+		set y to my clampTo((item 2 of targetFrame) + (item 2 of pos) - (item 2 of s), item 2 of targetFrame, (item 2 of targetFrame) + (item 4 of targetFrame) - (item 2 of sz))
+		set position of w to {x, y}`,
+}
+
+// manageWindowMac actúa sobre las ventanas mediante los atributos de
+// accesibilidad de System Events (requiere permisos de Accesibilidad).
+// macOS no tiene un "maximizar" propiamente dicho: la ventana se ajusta al
+// área útil de su pantalla.
+func manageWindowMac(appName, title, action string, selected int, screens []screenRect, target screenRect) (int, int, error) {
+	frames := make([]string, len(screens))
+	for i, s := range screens {
+		frames[i] = fmt.Sprintf("{%d, %d, %d, %d}", s.X, s.Y, s.Width, s.Height)
+	}
+	processes := "processes whose background only is false"
+	if appName != "" {
+		processes = fmt.Sprintf("processes whose name is %q", appName)
+	}
+	condition := "true"
+	if title != "" {
+		condition = fmt.Sprintf("((name of w) as text) contains %q", title)
+	}
+	script := fmt.Sprintf(macManageWindowScript,
+		strings.Join(frames, ", "),
+		fmt.Sprintf("%d, %d, %d, %d", target.X, target.Y, target.Width, target.Height),
+		processes, condition, selected, macWindowActions[action])
+	output, err := runAppleScript(script)
+	if err != nil {
+		return 0, 0, fmt.Errorf("AppleScript: %w", err)
+	}
+	return parseWindowCounts(output)
+}
+
+// windowsScreenForMove obtiene las pantallas con System.Windows.Forms y
+// elige la de destino
+func windowsScreenForMove(display string) (screenRect, error) {
+	output, err := runPowerShell(`Add-Type -AssemblyName System.Windows.Forms
+[System.Windows.Forms.Screen]::AllScreens | ForEach-Object { '{0}|{1}|{2}|{3}|{4}' -f $_.DeviceName, $_.WorkingArea.X, $_.WorkingArea.Y, $_.WorkingArea.Width, $_.WorkingArea.Height }`)
+	if err != nil {
+		return screenRect{}, fmt.Errorf("error al obtener las pantallas: %w", err)
+	}
+	var screens []screenRect
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Split(strings.TrimSpace(line), "|")
+		if len(fields) != 5 {
+			continue
+		}
+		s := screenRect{Name: fields[0]}
+		s.X, _ = strconv.Atoi(fields[1])
+		s.Y, _ = strconv.Atoi(fields[2])
+		s.Width, _ = strconv.Atoi(fields[3])
+		s.Height, _ = strconv.Atoi(fields[4])
+		screens = append(screens, s)
+	}
+	return selectScreen(screens, display)
+}
+
+// psManageWindow recorre las ventanas principales de los procesos y aplica
+// la acción con ShowWindow/SetWindowPos. Recibe el filtro de Where-Object,
+// el índice (-1 para todas), el área de destino y la acción; devuelve
+// "aplicadas|coincidentes".
+const psManageWindow = `Add-Type -Name Win -Namespace ManageWindow -MemberDefinition '[DllImport("user32.dll")] public static extern bool ShowWindow(System.IntPtr hWnd, int cmd); [DllImport("user32.dll")] public static extern bool IsZoomed(System.IntPtr hWnd); [DllImport("user32.dll")] public static extern bool GetWindowRect(System.IntPtr hWnd, out RECT rect); [DllImport("user32.dll")] public static extern bool SetWindowPos(System.IntPtr hWnd, System.IntPtr after, int x, int y, int cx, int cy, uint flags); public struct RECT { public int Left, Top, Right, Bottom; }'
+Add-Type -AssemblyName System.Windows.Forms
+$windows = @(Get-Process | Where-Object { $_.MainWindowHandle -ne 0%[1]s })
+$total = $windows.Count
+if (%[2]d -ge 0) {
+  if (%[2]d -ge $total) { "0|$total"; exit }
+  $windows = @($windows[%[2]d])
+}
+$tx, $ty, $tw, $th = %[3]s
+foreach ($p in $windows) {
+  $h = $p.MainWindowHandle
+%[4]s
+}
+"$($windows.Count)|$total"`
+
+// psWindowActions son las órdenes de PowerShell de cada acción sobre $h.
+// Windows no tiene una pantalla completa genérica: la ventana se extiende
+// sobre todo el monitor, barra de tareas incluida.
+var psWindowActions = map[string]string{
+	"minimize": `  [ManageWindow.Win]::ShowWindow($h, 6) | Out-Null`,
+	"maximize": `  [ManageWindow.Win]::ShowWindow($h, 3) | Out-Null`,
+	"restore":  `  [ManageWindow.Win]::ShowWindow($h, 9) | Out-Null`,
+	"fullscreen": `  $b = [System.Windows.Forms.Screen]::FromHandle($h).Bounds
+  [ManageWindow.Win]::ShowWindow($h, 9) | Out-Null
+  [ManageWindow.Win]::SetWindowPos($h, [IntPtr]::Zero, $b.X, $b.Y, $b.Width, $b.Height, 0x40) | Out-Null`,
+	"move_to_display": `  $zoomed = [ManageWindow.Win]::IsZoomed($h)
+  $src = [System.Windows.Forms.Screen]::FromHandle($h).WorkingArea
+  [ManageWindow.Win]::ShowWindow($h, 9) | Out-Null
+  $r = New-Object ManageWindow.Win+RECT
+  [ManageWindow.Win]::GetWindowRect($h, [ref]$r) | Out-Null
+  $x = [Math]::Max($tx, [Math]::Min($tx + $r.Left - $src.X, $tx + $tw - ($r.Right - $r.Left)))
+  $y = [Math]::Max($ty, [Math]::Min($ty + $r.Top - $src.Y, $ty + $th - ($r.Bottom - $r.Top)))
+  [ManageWindow.Win]::SetWindowPos($h, [IntPtr]::Zero, $x, $y, 0, 0, 0x45) | Out-Null
+  if ($zoomed) { [ManageWindow.Win]::ShowWindow($h, 3) | Out-Null }`,
+}
+
+// manageWindowWindows actúa sobre la ventana principal de cada proceso
+// que coincide, por nombre del ejecutable o por título
+func manageWindowWindows(appName, title, action string, selected int, target screenRect) (int, int, error) {
+	var filter string
+	if appName != "" {
+		filter += fmt.Sprintf(" -and $_.ProcessName -eq '%s'", strings.TrimSuffix(appName, filepath.Ext(appName)))
+	}
+	if title != "" {
+		filter += fmt.Sprintf(" -and $_.MainWindowTitle.IndexOf('%s', [StringComparison]::OrdinalIgnoreCase) -ge 0", strings.ReplaceAll(title, "'", "''"))
+	}
+	script := fmt.Sprintf(psManageWindow, filter, selected,
+		fmt.Sprintf("%d, %d, %d, %d", target.X, target.Y, target.Width, target.Height),
+		psWindowActions[action])
+	output, err := runPowerShell(script)
+	if err != nil {
+		return 0, 0, fmt.Errorf("error al gestionar la ventana: %w", err)
+	}
+	return parseWindowCounts(output)
+}
+
+// parseWindowCounts interpreta el "aplicadas|coincidentes" de los scripts
+func parseWindowCounts(output string) (int, int, error) {
+	applied, total, ok := strings.Cut(strings.TrimSpace(output), "|")
+	if !ok {
+		return 0, 0, fmt.Errorf("respuesta inesperada: %q", output)
+	}
+	a, _ := strconv.Atoi(applied)
+	t, _ := strconv.Atoi(total)
+	return a, t, nil
+}
+
+type ManageWindowInput struct {
+	AppName string `json:"app_name,omitempty" jsonschema:"Aplicación cuyas ventanas se gestionan (ej: 'Slack', 'firefox'; en Windows el ejecutable)"`
+	Title   string `json:"title,omitempty" jsonschema:"Texto que debe contener el título de la ventana (sin distinguir mayúsculas). Se puede combinar con app_name"`
+	Action  string `json:"action" jsonschema:"Acción: minimize, maximize, restore, fullscreen o move_to_display"`
+	Display string `json:"display,omitempty" jsonschema:"Para move_to_display: pantalla de destino por índice (0, 1...) o nombre (HDMI-1, DISPLAY2...)"`
+	Index   *int   `json:"index,omitempty" jsonschema:"Si coinciden varias ventanas, actuar solo sobre esta (empezando en 0). Por defecto se actúa sobre todas"`
+}
+
+func HandleManageWindow(ctx context.Context, req *mcp.CallToolRequest, input ManageWindowInput) (*mcp.CallToolResult, any, error) {
+	result, err := manageWindow(input.AppName, input.Title, input.Action, input.Display, input.Index)
+	if err != nil {
+		return nil, nil, fmt.Errorf("❌ Error al gestionar la ventana: %w", err)
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: result},
+		},
+	}, nil, nil
+}
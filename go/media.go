@@ -0,0 +1,225 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// errNoActivePlayer indica que no hay ningún reproductor al que enviar la orden
+var errNoActivePlayer = errors.New("no hay ningún reproductor multimedia activo")
+
+// mediaActions describe cada acción de media_control para el mensaje de resultado
+var mediaActions = map[string]string{
+	"play":     "▶️ Reproducción iniciada",
+	"pause":    "⏸️ Reproducción en pausa",
+	"toggle":   "⏯️ Reproducción alternada",
+	"next":     "⏭️ Pista siguiente",
+	"previous": "⏮️ Pista anterior",
+	"stop":     "⏹️ Reproducción detenida",
+}
+
+// macMediaPlayers son las aplicaciones que se controlan con AppleScript en
+// macOS cuando no está nowplaying-cli, por orden de preferencia
+var macMediaPlayers = []string{"Spotify", "Music"}
+
+// nowPlaying es la pista en curso ("Artista - Título") y el estado del
+// reproductor; los campos quedan vacíos si el backend no los conoce
+type nowPlaying struct {
+	Track  string
+	Status string
+}
+
+// mediaControl envía una acción de reproducción al reproductor activo (o al
+// indicado en player) y devuelve la pista en curso cuando el backend la conoce
+func mediaControl(action, player string) (string, error) {
+	action = strings.ToLower(strings.TrimSpace(action))
+	message, ok := mediaActions[action]
+	if !ok {
+		return "", fmt.Errorf("acción no válida %q: usa play, pause, toggle, next, previous o stop", action)
+	}
+
+	var backend string
+	var current nowPlaying
+	var err error
+	switch currentPlatform() {
+	case platformWindows, platformWSL:
+		backend, err = mediaControlWindows(action)
+	case platformMac:
+		backend, current, err = mediaControlMac(action, player)
+	default:
+		backend, current, err = mediaControlLinux(action, player)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	result := fmt.Sprintf("%s (vía %s)", message, backend)
+	if current.Track != "" && action != "stop" {
+		result += "\n🎵 " + current.Track
+		if current.Status != "" {
+			result += " [" + current.Status + "]"
+		}
+	}
+	return result, nil
+}
+
+// mediaControlLinux usa playerctl, que habla con los reproductores por MPRIS
+func mediaControlLinux(action, player string) (string, nowPlaying, error) {
+	if !commandExists("playerctl")() {
+		return "", nowPlaying{}, errors.New("playerctl no está instalado (instala el paquete playerctl)")
+	}
+	var args []string
+	if player != "" {
+		args = append(args, "--player="+player)
+	}
+	command := action
+	if action == "toggle" {
+		command = "play-pause"
+	}
+
+	output, err := exec.Command("playerctl", append(args, command)...).CombinedOutput()
+	if err != nil {
+		if strings.Contains(string(output), "No players found") {
+			if player != "" {
+				return "", nowPlaying{}, fmt.Errorf("%w: %s no está abierto", errNoActivePlayer, player)
+			}
+			return "", nowPlaying{}, errNoActivePlayer
+		}
+		return "", nowPlaying{}, fmt.Errorf("playerctl %s: %w: %s", command, err, strings.TrimSpace(string(output)))
+	}
+
+	waitForTrackChange(action)
+	var current nowPlaying
+	if out, err := exec.Command("playerctl", append(args, "metadata", "--format", "{{playerName}}|{{artist}}|{{title}}")...).Output(); err == nil {
+		if fields := strings.SplitN(strings.TrimSpace(string(out)), "|", 3); len(fields) == 3 {
+			current.Track = formatTrack(fields[1], fields[2])
+			player = fields[0]
+		}
+	}
+	if out, err := exec.Command("playerctl", append(args, "status")...).Output(); err == nil {
+		current.Status = strings.ToLower(strings.TrimSpace(string(out)))
+	}
+	if player != "" {
+		return "playerctl: " + player, current, nil
+	}
+	return "playerctl", current, nil
+}
+
+// mediaControlMac usa nowplaying-cli si está instalado, que funciona con
+// cualquier reproductor, y si no AppleScript con Spotify o Música
+func mediaControlMac(action, player string) (string, nowPlaying, error) {
+	if player == "" && commandExists("nowplaying-cli")() {
+		command := map[string]string{
+			"play": "play", "pause": "pause", "toggle": "togglePlayPause",
+			"next": "next", "previous": "previous", "stop": "pause",
+		}[action]
+		if err := exec.Command("nowplaying-cli", command).Run(); err != nil {
+			return "", nowPlaying{}, fmt.Errorf("nowplaying-cli %s: %w", command, err)
+		}
+		waitForTrackChange(action)
+		var current nowPlaying
+		if out, err := exec.Command("nowplaying-cli", "get", "artist", "title").Output(); err == nil {
+			lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+			if len(lines) == 2 && lines[1] != "null" {
+				current.Track = formatTrack(strings.TrimSpace(lines[0]), strings.TrimSpace(lines[1]))
+			}
+		}
+		return "nowplaying-cli", current, nil
+	}
+
+	app := player
+	if app == "" {
+		for _, name := range macMediaPlayers {
+			// Comprobarlo antes evita que "tell application" abra la aplicación
+			out, err := exec.Command("osascript", "-e", fmt.Sprintf("application %q is running", name)).Output()
+			if err == nil && strings.TrimSpace(string(out)) == "true" {
+				app = name
+				break
+			}
+		}
+		if app == "" {
+			return "", nowPlaying{}, errNoActivePlayer
+		}
+	}
+	command := map[string]string{
+		"play": "play", "pause": "pause", "toggle": "playpause",
+		"next": "next track", "previous": "previous track", "stop": "pause",
+	}[action]
+	if app == "Music" && action == "stop" {
+		command = "stop"
+	}
+	if _, err := runAppleScript(fmt.Sprintf("tell application %q to %s", app, command)); err != nil {
+		return "", nowPlaying{}, fmt.Errorf("AppleScript (%s): %w", app, err)
+	}
+
+	waitForTrackChange(action)
+	var current nowPlaying
+	script := fmt.Sprintf("tell application %q to (artist of current track) & \"|\" & (name of current track) & \"|\" & (player state as string)", app)
+	if out, err := runAppleScript(script); err == nil {
+		if fields := strings.SplitN(out, "|", 3); len(fields) == 3 {
+			current = nowPlaying{Track: formatTrack(fields[0], fields[1]), Status: fields[2]}
+		}
+	}
+	return "AppleScript: " + app, current, nil
+}
+
+// mediaControlWindows simula las teclas multimedia con keybd_event. Windows
+// no distingue play de pause, así que ambas alternan la reproducción, y no
+// hay forma sencilla de saber si algún reproductor recibió la tecla.
+func mediaControlWindows(action string) (string, error) {
+	keys := map[string]int{
+		"play": 0xB3, "pause": 0xB3, "toggle": 0xB3, // VK_MEDIA_PLAY_PAUSE
+		"next":     0xB0, // VK_MEDIA_NEXT_TRACK
+		"previous": 0xB1, // VK_MEDIA_PREV_TRACK
+		"stop":     0xB2, // VK_MEDIA_STOP
+	}
+	script := fmt.Sprintf(`Add-Type -Name Keys -Namespace Media -MemberDefinition '[DllImport("user32.dll")] public static extern void keybd_event(byte vk, byte scan, uint flags, System.UIntPtr extra);'
+[Media.Keys]::keybd_event(%[1]d, 0, 1, [System.UIntPtr]::Zero)
+[Media.Keys]::keybd_event(%[1]d, 0, 3, [System.UIntPtr]::Zero)`, keys[action])
+	if _, err := runPowerShell(script); err != nil {
+		return "", fmt.Errorf("error al enviar la tecla multimedia: %w", err)
+	}
+	return "teclas multimedia", nil
+}
+
+// waitForTrackChange da tiempo al reproductor a cambiar de pista antes de
+// leer la pista en curso
+func waitForTrackChange(action string) {
+	if action == "next" || action == "previous" {
+		time.Sleep(300 * time.Millisecond)
+	}
+}
+
+// formatTrack une artista y título omitiendo los que falten
+func formatTrack(artist, title string) string {
+	switch {
+	case artist == "":
+		return title
+	case title == "":
+		return artist
+	}
+	return artist + " - " + title
+}
+
+type MediaControlInput struct {
+	Action string `json:"action" jsonschema:"Acción: play, pause, toggle, next, previous o stop"`
+	Player string `json:"player,omitempty" jsonschema:"Reproductor a controlar (opcional). En Linux el nombre MPRIS de playerctl -l (ej: 'spotify', 'firefox'); en macOS el nombre de la app (ej: 'Spotify', 'Music')"`
+}
+
+func HandleMediaControl(ctx context.Context, req *mcp.CallToolRequest, input MediaControlInput) (*mcp.CallToolResult, any, error) {
+	result, err := mediaControl(input.Action, input.Player)
+	if err != nil {
+		return nil, nil, fmt.Errorf("❌ Error al controlar la reproducción: %w", err)
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: result},
+		},
+	}, nil, nil
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Límites de play_melody
+const (
+	maxMelodyDuration   = 15 * time.Second
+	maxMelodyNotes      = 100
+	defaultNoteDuration = 250 * time.Millisecond
+)
+
+// melodyNote es una nota de una melodía; Frequency 0 es un silencio
+type melodyNote struct {
+	Frequency int
+	Duration  time.Duration
+}
+
+// noteSemitones son las notas naturales en semitonos desde Do
+var noteSemitones = map[string]int{"C": 0, "D": 2, "E": 4, "F": 5, "G": 7, "A": 9, "B": 11}
+
+// notePattern reconoce nombres de nota en notación anglosajona con
+// alteración y octava opcionales ("C5", "F#4", "Bb3", "A")
+var notePattern = regexp.MustCompile(`^([A-Ga-g])([#b]?)([0-8]?)$`)
+
+// noteFrequency convierte un nombre de nota en su frecuencia con afinación
+// La4 = 440 Hz. Sin octava se usa la 4; "R" o "rest" es un silencio.
+func noteFrequency(name string) (int, error) {
+	name = strings.TrimSpace(name)
+	if strings.EqualFold(name, "R") || strings.EqualFold(name, "rest") {
+		return 0, nil
+	}
+	match := notePattern.FindStringSubmatch(name)
+	if match == nil {
+		return 0, fmt.Errorf("nota no válida %q (usa por ejemplo C5, F#4, Bb3 o R para un silencio)", name)
+	}
+	semitone := noteSemitones[strings.ToUpper(match[1])]
+	switch match[2] {
+	case "#":
+		semitone++
+	case "b":
+		semitone--
+	}
+	octave := 4
+	if match[3] != "" {
+		octave, _ = strconv.Atoi(match[3])
+	}
+	midi := 12*(octave+1) + semitone
+	return int(math.Round(440 * math.Pow(2, float64(midi-69)/12))), nil
+}
+
+// parseMelody valida las notas pedidas y las convierte en frecuencias
+func parseMelody(input []MelodyNoteInput) ([]melodyNote, time.Duration, error) {
+	if len(input) == 0 {
+		return nil, 0, errors.New("la melodía no tiene notas")
+	}
+	if len(input) > maxMelodyNotes {
+		return nil, 0, fmt.Errorf("la melodía tiene %d notas (máximo %d)", len(input), maxMelodyNotes)
+	}
+	notes := make([]melodyNote, len(input))
+	var total time.Duration
+	for i, n := range input {
+		frequency := n.FrequencyHz
+		if n.Note != "" {
+			var err error
+			if frequency, err = noteFrequency(n.Note); err != nil {
+				return nil, 0, fmt.Errorf("nota %d: %w", i+1, err)
+			}
+		}
+		if frequency != 0 && (frequency < minToneFrequency || frequency > maxToneFrequency) {
+			return nil, 0, fmt.Errorf("nota %d: frecuencia %d Hz fuera de rango (%d-%d)", i+1, frequency, minToneFrequency, maxToneFrequency)
+		}
+		duration := defaultNoteDuration
+		if n.DurationMs > 0 {
+			duration = time.Duration(n.DurationMs) * time.Millisecond
+		}
+		total += duration
+		notes[i] = melodyNote{Frequency: frequency, Duration: duration}
+	}
+	if total > maxMelodyDuration {
+		return nil, 0, fmt.Errorf("la melodía dura %v (máximo %v)", total, maxMelodyDuration)
+	}
+	return notes, total, nil
+}
+
+// melodyCommand construye el comando que toca la melodía: en Windows y WSL
+// una sola invocación de PowerShell con [console]::beep por nota, para no
+// lanzar un proceso por nota; en el resto un WAV generado con todas las
+// notas. Devuelve también el fichero temporal a borrar, si lo hay.
+func melodyCommand(ctx context.Context, notes []melodyNote) (*exec.Cmd, string, string, error) {
+	if p := currentPlatform(); p == platformWindows || p == platformWSL {
+		steps := make([]string, len(notes))
+		for i, n := range notes {
+			if n.Frequency == 0 {
+				steps[i] = fmt.Sprintf("Start-Sleep -Milliseconds %d", n.Duration.Milliseconds())
+			} else {
+				steps[i] = fmt.Sprintf("[console]::beep(%d,%d)", n.Frequency, n.Duration.Milliseconds())
+			}
+		}
+		cmd := exec.CommandContext(ctx, powershellCommand(), "-NoProfile", "-Command", strings.Join(steps, "; "))
+		return cmd, "[console]::beep", "", nil
+	}
+
+	var player string
+	if currentPlatform() == platformMac {
+		player = "afplay"
+	} else {
+		for _, name := range []string{"paplay", "pw-play", "aplay", "play"} {
+			if commandExists(name)() {
+				player = name
+				break
+			}
+		}
+		if player == "" {
+			return nil, "", "", errors.New("no se encontró ningún reproductor (instala pulseaudio-utils, alsa-utils o sox)")
+		}
+	}
+
+	dir := filepath.Join(os.TempDir(), configDirName+"-tones")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, "", "", err
+	}
+	file, err := os.CreateTemp(dir, "melody-*.wav")
+	if err != nil {
+		return nil, "", "", err
+	}
+	_, err = file.Write(melodyWAV(notes))
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(file.Name())
+		return nil, "", "", err
+	}
+
+	args := []string{file.Name()}
+	switch player {
+	case "aplay", "play":
+		args = append([]string{"-q"}, args...)
+	}
+	return exec.CommandContext(ctx, player, args...), player, file.Name(), nil
+}
+
+// playMelody toca una secuencia de notas. Cancelar la petición detiene la
+// reproducción.
+func playMelody(ctx context.Context, input []MelodyNoteInput) (string, error) {
+	notes, total, err := parseMelody(input)
+	if err != nil {
+		return "", err
+	}
+	cmd, player, tmp, err := melodyCommand(ctx, notes)
+	if err != nil {
+		return "", err
+	}
+	if tmp != "" {
+		defer os.Remove(tmp)
+	}
+
+	if output, err := cmd.CombinedOutput(); err != nil {
+		if ctx.Err() != nil {
+			return "⏹️ Melodía cancelada", nil
+		}
+		if msg := strings.TrimSpace(string(output)); msg != "" {
+			return "", fmt.Errorf("%s: %w: %s", player, err, msg)
+		}
+		return "", fmt.Errorf("%s: %w", player, err)
+	}
+	return fmt.Sprintf("🎵 Melodía de %d notas reproducida (%.1f s, vía %s)", len(notes), total.Seconds(), player), nil
+}
+
+type MelodyNoteInput struct {
+	Note        string `json:"note,omitempty" jsonschema:"Nombre de la nota (ej: 'C5', 'F#4', 'Bb3'; 'R' para un silencio). Alternativa a frequency_hz"`
+	FrequencyHz int    `json:"frequency_hz,omitempty" jsonschema:"Frecuencia en Hz (37-32767; 0 para un silencio)"`
+	DurationMs  int    `json:"duration_ms,omitempty" jsonschema:"Duración de la nota en milisegundos (por defecto 250)"`
+}
+
+type PlayMelodyInput struct {
+	Notes []MelodyNoteInput `json:"notes" jsonschema:"Notas a tocar en orden (máximo 100 y 15 segundos en total)"`
+}
+
+func HandlePlayMelody(ctx context.Context, req *mcp.CallToolRequest, input PlayMelodyInput) (*mcp.CallToolResult, any, error) {
+	result, err := playMelody(ctx, input.Notes)
+	if err != nil {
+		return nil, nil, fmt.Errorf("❌ Error al tocar la melodía: %w", err)
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: result},
+		},
+	}, nil, nil
+}
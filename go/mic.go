@@ -0,0 +1,180 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// defaultMicVolume es el volumen de entrada que se restaura en macOS cuando
+// el micrófono ya estaba a 0 y no hay un volumen anterior guardado
+const defaultMicVolume = 75
+
+// macOS no tiene silencio de entrada: se silencia poniendo el volumen de
+// entrada a 0 y se guarda aquí el anterior para restaurarlo. Solo se conserva
+// en memoria mientras el servidor está en marcha.
+var (
+	micVolumeMu    sync.Mutex
+	savedMicVolume int
+)
+
+// micState es el estado del micrófono por defecto
+type micState struct {
+	Muted   bool
+	Volume  int // solo en macOS, -1 si no se conoce
+	Backend string
+}
+
+// readMicState obtiene si el micrófono por defecto está silenciado
+func readMicState() (micState, error) {
+	switch currentPlatform() {
+	case platformWindows, platformWSL:
+		output, err := runAudioDeviceCmdlets("Get-AudioDevice -RecordingMute")
+		if err != nil {
+			return micState{}, err
+		}
+		return micState{Muted: strings.EqualFold(output, "True"), Volume: -1, Backend: "AudioDeviceCmdlets"}, nil
+	case platformMac:
+		output, err := exec.Command("osascript", "-e", "input volume of (get volume settings)").Output()
+		if err != nil {
+			return micState{}, fmt.Errorf("error al consultar osascript: %w", err)
+		}
+		volume, err := strconv.Atoi(strings.TrimSpace(string(output)))
+		if err != nil {
+			// "missing value" cuando no hay ningún dispositivo de entrada
+			return micState{}, fmt.Errorf("el dispositivo de entrada no informa del volumen: %q", strings.TrimSpace(string(output)))
+		}
+		return micState{Muted: volume == 0, Volume: volume, Backend: "osascript"}, nil
+	}
+
+	// Linux - pactl (PulseAudio/PipeWire) o amixer (ALSA)
+	if _, err := exec.LookPath("pactl"); err == nil {
+		output, err := exec.Command("pactl", "get-source-mute", "@DEFAULT_SOURCE@").Output()
+		if err != nil {
+			return micState{}, fmt.Errorf("error al consultar pactl: %w", err)
+		}
+		return micState{Muted: strings.Contains(string(output), "yes"), Volume: -1, Backend: "pactl"}, nil
+	}
+	if _, err := exec.LookPath("amixer"); err == nil {
+		// "Front Left: Capture 65536 [100%] [off]"
+		output, err := exec.Command("amixer", "get", "Capture").Output()
+		if err != nil {
+			return micState{}, fmt.Errorf("error al consultar amixer: %w", err)
+		}
+		return micState{Muted: strings.Contains(string(output), "[off]"), Volume: -1, Backend: "amixer"}, nil
+	}
+	return micState{}, errors.New("no se encontró pactl ni amixer para controlar el micrófono")
+}
+
+// writeMicMute silencia o reactiva el micrófono por defecto
+func writeMicMute(mute bool, current micState) error {
+	switch currentPlatform() {
+	case platformWindows, platformWSL:
+		_, err := runAudioDeviceCmdlets(fmt.Sprintf("Set-AudioDevice -RecordingMute $%t | Out-Null", mute))
+		return err
+	case platformMac:
+		return setMacMicMute(mute, current)
+	}
+
+	if _, err := exec.LookPath("pactl"); err == nil {
+		value := "0"
+		if mute {
+			value = "1"
+		}
+		return exec.Command("pactl", "set-source-mute", "@DEFAULT_SOURCE@", value).Run()
+	}
+	action := "cap"
+	if mute {
+		action = "nocap"
+	}
+	return exec.Command("amixer", "-q", "set", "Capture", action).Run()
+}
+
+// setMacMicMute emula el silencio en macOS con el volumen de entrada,
+// guardando el anterior para restaurarlo al reactivar
+func setMacMicMute(mute bool, current micState) error {
+	micVolumeMu.Lock()
+	defer micVolumeMu.Unlock()
+
+	volume := 0
+	if mute {
+		// Silenciar dos veces no debe perder el volumen guardado
+		if current.Volume > 0 {
+			savedMicVolume = current.Volume
+		}
+	} else {
+		if current.Volume > 0 {
+			return nil
+		}
+		volume = savedMicVolume
+		if volume <= 0 {
+			volume = defaultMicVolume
+		}
+	}
+	if err := exec.Command("osascript", "-e", fmt.Sprintf("set volume input volume %d", volume)).Run(); err != nil {
+		return err
+	}
+	if !mute {
+		savedMicVolume = 0
+	}
+	return nil
+}
+
+// setMicMute silencia ("on"), reactiva ("off") o alterna ("toggle") el
+// micrófono por defecto y describe el estado final
+func setMicMute(state string) (string, error) {
+	state = strings.ToLower(strings.TrimSpace(state))
+	if state != "on" && state != "off" && state != "toggle" {
+		return "", fmt.Errorf("estado no válido %q: usa on, off o toggle", state)
+	}
+	// El estado actual hace falta para alternar y, en macOS, para guardar el volumen
+	current, err := readMicState()
+	if err != nil {
+		return "", fmt.Errorf("no se pudo leer el estado del micrófono: %w", err)
+	}
+	mute := state == "on"
+	if state == "toggle" {
+		mute = !current.Muted
+	}
+
+	if err := writeMicMute(mute, current); err != nil {
+		return "", fmt.Errorf("error al cambiar el silencio del micrófono (%s): %w", current.Backend, err)
+	}
+
+	// Confirmar el estado final leyéndolo de nuevo cuando sea posible
+	final := micState{Muted: mute, Volume: -1}
+	if after, err := readMicState(); err == nil {
+		final = after
+	}
+	switch {
+	case final.Muted && final.Volume >= 0:
+		return "🔇 Micrófono silenciado (volumen de entrada a 0%)", nil
+	case final.Muted:
+		return "🔇 Micrófono silenciado", nil
+	case final.Volume >= 0:
+		return fmt.Sprintf("🎙️ Micrófono activo (volumen de entrada %d%%)", final.Volume), nil
+	}
+	return "🎙️ Micrófono activo (sin silencio)", nil
+}
+
+type SetMicMuteInput struct {
+	State string `json:"state" jsonschema:"Estado de silencio del micrófono: on (silenciar), off (activar) o toggle (alternar)"`
+}
+
+func HandleSetMicMute(ctx context.Context, req *mcp.CallToolRequest, input SetMicMuteInput) (*mcp.CallToolResult, any, error) {
+	result, err := setMicMute(input.State)
+	if err != nil {
+		return nil, nil, fmt.Errorf("❌ Error al cambiar el silencio del micrófono: %w", err)
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: result},
+		},
+	}, nil, nil
+}
@@ -0,0 +1,369 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type NetworkInfoOutput struct {
+	Interface     string   `json:"interface" jsonschema:"Interfaz de red activa (la de la ruta por defecto)"`
+	IPv4          []string `json:"ipv4,omitempty" jsonschema:"Direcciones IPv4 de la interfaz"`
+	IPv6          []string `json:"ipv6,omitempty" jsonschema:"Direcciones IPv6 de la interfaz (sin las de enlace local)"`
+	Gateway       string   `json:"gateway,omitempty" jsonschema:"Puerta de enlace predeterminada"`
+	WiFi          bool     `json:"wifi" jsonschema:"La interfaz activa es Wi-Fi"`
+	SSID          *string  `json:"ssid" jsonschema:"Nombre de la red Wi-Fi (null si no es Wi-Fi o no se puede saber)"`
+	SSIDError     string   `json:"ssid_error,omitempty" jsonschema:"Motivo por el que no se conoce el SSID"`
+	SignalPercent *int     `json:"signal_percent,omitempty" jsonschema:"Calidad de la señal Wi-Fi en porcentaje"`
+	SignalDBm     *int     `json:"signal_dbm,omitempty" jsonschema:"Nivel de la señal Wi-Fi en dBm"`
+	Note          string   `json:"note,omitempty" jsonschema:"Aclaración sobre el origen de los datos (p. ej. en WSL)"`
+}
+
+// activeInterface devuelve la interfaz por la que sale la ruta por defecto.
+// Conectar un socket UDP no envía nada, solo hace que el sistema elija la
+// dirección de origen, que se busca después entre las interfaces.
+func activeInterface() (*net.Interface, error) {
+	var local net.IP
+	for _, target := range []struct{ network, address string }{{"udp4", "8.8.8.8:53"}, {"udp6", "[2001:4860:4860::8888]:53"}} {
+		conn, err := net.Dial(target.network, target.address)
+		if err != nil {
+			continue
+		}
+		local = conn.LocalAddr().(*net.UDPAddr).IP
+		conn.Close()
+		break
+	}
+	if local == nil {
+		return nil, errors.New("sin conexión de red: no hay ruta por defecto")
+	}
+	interfaces, err := net.Interfaces()
+	if err != nil {
+		return nil, fmt.Errorf("error al enumerar las interfaces: %w", err)
+	}
+	for _, iface := range interfaces {
+		addrs, _ := iface.Addrs()
+		for _, addr := range addrs {
+			if ipnet, ok := addr.(*net.IPNet); ok && ipnet.IP.Equal(local) {
+				return &iface, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("no se encontró la interfaz con la dirección %s", local)
+}
+
+// getNetworkInfo devuelve la interfaz activa con sus direcciones, la puerta
+// de enlace y, si es Wi-Fi, la red y la señal. El SSID, la señal y la puerta
+// de enlace se dejan vacíos si no se pueden obtener.
+func getNetworkInfo() (NetworkInfoOutput, error) {
+	iface, err := activeInterface()
+	if err != nil {
+		return NetworkInfoOutput{}, err
+	}
+	info := NetworkInfoOutput{Interface: iface.Name}
+	addrs, _ := iface.Addrs()
+	for _, addr := range addrs {
+		ipnet, ok := addr.(*net.IPNet)
+		if !ok || ipnet.IP.IsLinkLocalUnicast() {
+			continue
+		}
+		if ipnet.IP.To4() != nil {
+			info.IPv4 = append(info.IPv4, ipnet.IP.String())
+		} else {
+			info.IPv6 = append(info.IPv6, ipnet.IP.String())
+		}
+	}
+
+	switch currentPlatform() {
+	case platformWindows:
+		info.Gateway = gatewayWindows(iface.Name)
+		wifiInfoWindows(&info)
+	case platformMac:
+		info.Gateway = gatewayMac()
+		wifiInfoMac(&info)
+	case platformWSL:
+		info.Gateway = gatewayLinux(iface.Name)
+		info.Note = "Interfaz y direcciones de la máquina virtual de WSL; la Wi-Fi es la del Windows anfitrión"
+		wifiInfoWindows(&info)
+	default:
+		info.Gateway = gatewayLinux(iface.Name)
+		wifiInfoLinux(&info)
+	}
+	return info, nil
+}
+
+// gatewayLinux busca la ruta por defecto de la interfaz en /proc/net/route,
+// donde las direcciones están en hexadecimal y en orden little-endian:
+//
+//	Iface	Destination	Gateway 	Flags	...
+//	wlan0	00000000	0101A8C0	0003	...
+func gatewayLinux(iface string) string {
+	file, err := os.Open("/proc/net/route")
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 3 || fields[0] != iface || fields[1] != "00000000" {
+			continue
+		}
+		value, err := strconv.ParseUint(fields[2], 16, 32)
+		if err != nil {
+			continue
+		}
+		ip := make(net.IP, 4)
+		binary.LittleEndian.PutUint32(ip, uint32(value))
+		return ip.String()
+	}
+	return ""
+}
+
+// macGatewayRegexp reconoce la puerta de enlace en `route -n get default`
+var macGatewayRegexp = regexp.MustCompile(`gateway:\s*(\S+)`)
+
+func gatewayMac() string {
+	output, err := exec.Command("route", "-n", "get", "default").Output()
+	if err != nil {
+		return ""
+	}
+	if m := macGatewayRegexp.FindStringSubmatch(string(output)); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+func gatewayWindows(iface string) string {
+	output, err := runPowerShell(fmt.Sprintf(`(Get-NetRoute -DestinationPrefix '0.0.0.0/0' -InterfaceAlias '%s' -ErrorAction SilentlyContinue | Sort-Object RouteMetric | Select-Object -First 1).NextHop`,
+		strings.ReplaceAll(iface, "'", "''")))
+	if err != nil {
+		return ""
+	}
+	return output
+}
+
+// wifiInfoLinux detecta la Wi-Fi por /sys/class/net/<iface>/wireless y usa
+// nmcli, que da el SSID y la calidad de la señal, o si no iwgetid para el
+// SSID y /proc/net/wireless para el nivel en dBm
+func wifiInfoLinux(info *NetworkInfoOutput) {
+	if _, err := os.Stat(filepath.Join("/sys/class/net", info.Interface, "wireless")); err != nil {
+		return
+	}
+	info.WiFi = true
+
+	if commandExists("nmcli")() {
+		// "yes:Mi\: red:72" (nmcli escapa los ':' del SSID)
+		output, err := exec.Command("nmcli", "-t", "-f", "ACTIVE,SSID,SIGNAL,DEVICE", "device", "wifi", "list", "ifname", info.Interface, "--rescan", "no").Output()
+		if err == nil {
+			for _, line := range strings.Split(string(output), "\n") {
+				fields := splitNmcliFields(line)
+				if len(fields) == 4 && fields[0] == "yes" {
+					ssid := fields[1]
+					info.SSID = &ssid
+					if signal, err := strconv.Atoi(fields[2]); err == nil {
+						info.SignalPercent = &signal
+					}
+					break
+				}
+			}
+		}
+	}
+	if info.SSID == nil && commandExists("iwgetid")() {
+		if output, err := exec.Command("iwgetid", "-r", info.Interface).Output(); err == nil {
+			if ssid := strings.TrimSpace(string(output)); ssid != "" {
+				info.SSID = &ssid
+			}
+		}
+	}
+	if info.SSID == nil {
+		info.SSIDError = "no se pudo leer el SSID: requiere nmcli (NetworkManager) o iwgetid (wireless-tools)"
+	}
+
+	// "wlan0: 0000   54.  -56.  -256        0      0      0      0      0        0"
+	if data, err := os.ReadFile("/proc/net/wireless"); err == nil {
+		for _, line := range strings.Split(string(data), "\n") {
+			fields := strings.Fields(line)
+			if len(fields) >= 4 && fields[0] == info.Interface+":" {
+				if level, err := strconv.ParseFloat(strings.TrimSuffix(fields[3], "."), 64); err == nil {
+					dbm := int(level)
+					info.SignalDBm = &dbm
+				}
+			}
+		}
+	}
+}
+
+// splitNmcliFields separa una línea de `nmcli -t`, en la que los ':' de los
+// valores van escapados como '\:'
+func splitNmcliFields(line string) []string {
+	var fields []string
+	var field strings.Builder
+	for i := 0; i < len(line); i++ {
+		switch {
+		case line[i] == '\\' && i+1 < len(line):
+			i++
+			field.WriteByte(line[i])
+		case line[i] == ':':
+			fields = append(fields, field.String())
+			field.Reset()
+		default:
+			field.WriteByte(line[i])
+		}
+	}
+	return append(fields, field.String())
+}
+
+// macAirportPath es la utilidad airport, que Apple retiró en macOS 14.4
+const macAirportPath = "/System/Library/PrivateFrameworks/Apple80211.framework/Versions/Current/Resources/airport"
+
+var (
+	// "Hardware Port: Wi-Fi\nDevice: en0"
+	macWiFiPortRegexp = regexp.MustCompile(`Hardware Port: (?:Wi-Fi|AirPort)\s*\nDevice: (\S+)`)
+	// "  SSID : Mi red" en `ipconfig getsummary`
+	macSummarySSIDRegexp = regexp.MustCompile(`(?m)^\s+SSID : (.+)$`)
+	// "     agrCtlRSSI: -56" en `airport -I`
+	macAirportRSSIRegexp = regexp.MustCompile(`agrCtlRSSI: (-?\d+)`)
+	// "Signal / Noise: -56 dBm / -90 dBm" en `system_profiler SPAirPortDataType`
+	macProfilerSignalRegexp = regexp.MustCompile(`Signal / Noise: (-?\d+) dBm`)
+)
+
+// wifiInfoMac lee el SSID con `ipconfig getsummary`. Desde macOS 14 el SSID
+// sale como "<redacted>" si la aplicación no tiene permiso de localización;
+// en ese caso se explica en ssid_error en lugar de devolverlo vacío.
+func wifiInfoMac(info *NetworkInfoOutput) {
+	output, err := exec.Command("networksetup", "-listallhardwareports").Output()
+	if err != nil {
+		return
+	}
+	m := macWiFiPortRegexp.FindStringSubmatch(string(output))
+	if m == nil || m[1] != info.Interface {
+		return
+	}
+	info.WiFi = true
+
+	if output, err := exec.Command("ipconfig", "getsummary", info.Interface).Output(); err == nil {
+		if m := macSummarySSIDRegexp.FindStringSubmatch(string(output)); m != nil {
+			ssid := strings.TrimSpace(m[1])
+			if ssid == "<redacted>" {
+				info.SSIDError = "macOS oculta el SSID a las aplicaciones sin permiso de localización: concédelo a la aplicación que ejecuta el servidor (Terminal o el cliente MCP) en Ajustes del Sistema > Privacidad y seguridad > Localización"
+			} else {
+				info.SSID = &ssid
+			}
+		}
+	}
+	if info.SSID == nil && info.SSIDError == "" {
+		info.SSIDError = "no se pudo leer el SSID con ipconfig getsummary"
+	}
+
+	var signal []string
+	if output, err := exec.Command(macAirportPath, "-I").Output(); err == nil {
+		signal = macAirportRSSIRegexp.FindStringSubmatch(string(output))
+	} else if output, err := exec.Command("system_profiler", "SPAirPortDataType").Output(); err == nil {
+		signal = macProfilerSignalRegexp.FindStringSubmatch(string(output))
+	}
+	if signal != nil {
+		dbm, _ := strconv.Atoi(signal[1])
+		info.SignalDBm = &dbm
+	}
+}
+
+// wifiInfoWindows interpreta `netsh wlan show interfaces`, cuyas etiquetas
+// están traducidas al idioma del sistema: la interfaz se reconoce porque
+// uno de sus valores es su nombre, el SSID por la etiqueta "SSID" (igual en
+// todos los idiomas) y la señal por ser el único valor con '%'. En WSL se
+// usa la primera interfaz conectada del anfitrión.
+func wifiInfoWindows(info *NetworkInfoOutput) {
+	output, err := exec.Command(windowsExecutable("netsh.exe"), "wlan", "show", "interfaces").CombinedOutput()
+	if err != nil {
+		if strings.Contains(strings.ToLower(string(output)), "location") {
+			info.SSIDError = "Windows requiere permiso de ubicación para consultar la Wi-Fi: actívalo en Configuración > Privacidad y seguridad > Ubicación"
+		}
+		return
+	}
+	wsl := currentPlatform() == platformWSL
+	for _, block := range strings.Split(strings.ReplaceAll(string(output), "\r", ""), "\n\n") {
+		values := map[string]string{}
+		matches := false
+		for _, line := range strings.Split(block, "\n") {
+			key, value, ok := strings.Cut(line, " : ")
+			if !ok {
+				continue
+			}
+			key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+			values[key] = value
+			if value == info.Interface {
+				matches = true
+			}
+		}
+		ssid, connected := values["SSID"]
+		if !(matches || wsl && connected) {
+			continue
+		}
+		info.WiFi = matches || info.WiFi
+		if connected {
+			info.SSID = &ssid
+		}
+		for _, value := range values {
+			if percent, ok := strings.CutSuffix(value, "%"); ok {
+				if signal, err := strconv.Atoi(percent); err == nil {
+					info.SignalPercent = &signal
+				}
+			}
+		}
+		return
+	}
+}
+
+func HandleGetNetworkInfo(ctx context.Context, req *mcp.CallToolRequest, input struct{}) (*mcp.CallToolResult, NetworkInfoOutput, error) {
+	info, err := getNetworkInfo()
+	if err != nil {
+		return nil, NetworkInfoOutput{}, fmt.Errorf("❌ Error al obtener la información de red: %w", err)
+	}
+	lines := []string{"🌐 Interfaz activa: " + info.Interface}
+	if len(info.IPv4) > 0 {
+		lines = append(lines, "  - IPv4: "+strings.Join(info.IPv4, ", "))
+	}
+	if len(info.IPv6) > 0 {
+		lines = append(lines, "  - IPv6: "+strings.Join(info.IPv6, ", "))
+	}
+	if info.Gateway != "" {
+		lines = append(lines, "  - Puerta de enlace: "+info.Gateway)
+	}
+	if info.WiFi || info.SSID != nil {
+		wifi := "📶 Wi-Fi: "
+		if info.SSID != nil {
+			wifi += *info.SSID
+		} else {
+			wifi += "red desconocida"
+		}
+		switch {
+		case info.SignalPercent != nil:
+			wifi += fmt.Sprintf(" (señal %d%%)", *info.SignalPercent)
+		case info.SignalDBm != nil:
+			wifi += fmt.Sprintf(" (señal %d dBm)", *info.SignalDBm)
+		}
+		lines = append(lines, wifi)
+		if info.SSIDError != "" {
+			lines = append(lines, "⚠️ "+info.SSIDError)
+		}
+	}
+	if info.Note != "" {
+		lines = append(lines, "ℹ️ "+info.Note)
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: strings.Join(lines, "\n")},
+		},
+	}, info, nil
+}
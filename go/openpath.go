@@ -0,0 +1,167 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// openPathRoots son los directorios dentro de los que open_path puede abrir
+// ficheros, separados por el separador de listas de rutas del sistema (':'
+// o ';'; configurable con --open-path-roots). Vacío permite solo el
+// directorio personal.
+var openPathRoots string
+
+// allowedOpenRoots devuelve las raíces permitidas como rutas absolutas y
+// sin enlaces simbólicos
+func allowedOpenRoots() ([]string, error) {
+	list := openPathRoots
+	if list == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("no se pudo obtener el directorio personal: %w", err)
+		}
+		list = home
+	}
+	var roots []string
+	for _, root := range filepath.SplitList(list) {
+		if root = strings.TrimSpace(root); root == "" {
+			continue
+		}
+		abs, err := filepath.Abs(expandHome(root))
+		if err != nil {
+			return nil, err
+		}
+		if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+			abs = resolved
+		}
+		roots = append(roots, abs)
+	}
+	if len(roots) == 0 {
+		return nil, errors.New("--open-path-roots no contiene ningún directorio")
+	}
+	return roots, nil
+}
+
+// resolveOpenPath valida que path exista dentro de alguna raíz permitida y
+// devuelve su ruta absoluta. "~" se expande al directorio personal y las
+// rutas relativas se resuelven respecto a la primera raíz; los enlaces
+// simbólicos no pueden salir de las raíces.
+func resolveOpenPath(path string) (string, error) {
+	roots, err := allowedOpenRoots()
+	if err != nil {
+		return "", err
+	}
+	path = expandHome(strings.TrimSpace(path))
+	if path == "" {
+		return "", errors.New("la ruta no puede estar vacía")
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(roots[0], path)
+	}
+	path = filepath.Clean(path)
+
+	resolved, err := filepath.EvalSymlinks(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("%s no existe", path)
+	}
+	if err != nil {
+		return "", err
+	}
+	for _, root := range roots {
+		if rel, err := filepath.Rel(root, resolved); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return resolved, nil
+		}
+	}
+	return "", fmt.Errorf("%s está fuera de los directorios permitidos (%s; configurable con --open-path-roots)", path, strings.Join(roots, ", "))
+}
+
+// openPath abre un fichero o carpeta con la aplicación por defecto o, con
+// reveal, muestra la carpeta que lo contiene con el elemento seleccionado
+func openPath(path string, reveal bool) (string, error) {
+	path, err := resolveOpenPath(path)
+	if err != nil {
+		return "", err
+	}
+
+	var cmd *exec.Cmd
+	var backend string
+	switch currentPlatform() {
+	case platformWindows, platformWSL:
+		winPath, err := windowsPath(path)
+		if err != nil {
+			return "", err
+		}
+		backend = "explorer"
+		if isWSL() {
+			backend = "explorer.exe"
+		}
+		if reveal {
+			cmd = exec.Command(backend, "/select,"+winPath)
+		} else {
+			cmd = exec.Command(backend, winPath)
+		}
+	case platformMac:
+		backend = "open"
+		if reveal {
+			cmd = exec.Command("open", "-R", path)
+		} else {
+			cmd = exec.Command("open", path)
+		}
+	default:
+		if reveal && commandExists("dbus-send")() {
+			// Interfaz estándar de los gestores de ficheros (Nautilus, Dolphin, Nemo...)
+			uri := (&url.URL{Scheme: "file", Path: path}).String()
+			err := exec.Command("dbus-send", "--session", "--print-reply", "--dest=org.freedesktop.FileManager1",
+				"--type=method_call", "/org/freedesktop/FileManager1", "org.freedesktop.FileManager1.ShowItems",
+				"array:string:"+uri, "string:").Run()
+			if err == nil {
+				return fmt.Sprintf("📂 Mostrando %s en el gestor de ficheros (vía FileManager1)", path), nil
+			}
+		}
+		if !commandExists("xdg-open")() {
+			return "", errors.New("xdg-open no está instalado (instala el paquete xdg-utils)")
+		}
+		backend = "xdg-open"
+		target := path
+		if reveal {
+			// Sin FileManager1 no se puede seleccionar: se abre la carpeta
+			target = filepath.Dir(path)
+		}
+		cmd = exec.Command("xdg-open", target)
+	}
+	cmd.SysProcAttr = detachedProcAttr()
+
+	if err := cmd.Start(); err != nil {
+		return "", fmt.Errorf("%s: %w", backend, err)
+	}
+	go cmd.Wait()
+	if reveal {
+		return fmt.Sprintf("📂 Mostrando %s en el gestor de ficheros (vía %s)", path, backend), nil
+	}
+	return fmt.Sprintf("📂 Abierto %s (vía %s)", path, backend), nil
+}
+
+type OpenPathInput struct {
+	Path   string `json:"path" jsonschema:"Fichero o carpeta a abrir. Admite '~'; las rutas relativas se resuelven respecto al primer directorio permitido"`
+	Reveal bool   `json:"reveal,omitempty" jsonschema:"Si es true, abre la carpeta que lo contiene con el elemento seleccionado en lugar de abrirlo"`
+}
+
+func HandleOpenPath(ctx context.Context, req *mcp.CallToolRequest, input OpenPathInput) (*mcp.CallToolResult, any, error) {
+	result, err := openPath(input.Path, input.Reveal)
+	if err != nil {
+		return nil, nil, fmt.Errorf("❌ Error al abrir la ruta: %w", err)
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: result},
+		},
+	}, nil, nil
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os/exec"
+	"regexp"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// allowAnyScheme permite que open_url abra URLs con cualquier esquema, no
+// solo http y https (configurable con --allow-any-scheme)
+var allowAnyScheme bool
+
+// hostPortRegexp reconoce un servidor con puerto y sin esquema
+// ("localhost:8080/admin", "[::1]:3000"), que url.Parse tomaría como el
+// esquema "localhost"
+var hostPortRegexp = regexp.MustCompile(`^([\w.-]+|\[[0-9a-fA-F:.]+\]):\d+([/?#]|$)`)
+
+// parseOpenURL valida la URL pedida. Sin esquema se asume https
+// ("github.com"), o http si lleva puerto ("localhost:8080"), que suele ser
+// un servidor local; con otro esquema que no sea http o https se rechaza
+// salvo que el servidor se haya iniciado con --allow-any-scheme.
+func parseOpenURL(raw string) (*url.URL, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, errors.New("la URL no puede estar vacía")
+	}
+	if hostPortRegexp.MatchString(raw) {
+		raw = "http://" + raw
+	}
+	u, err := url.Parse(raw)
+	if err == nil && u.Scheme == "" {
+		u, err = url.Parse("https://" + raw)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("URL no válida: %w", err)
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	switch {
+	case u.Scheme == "http" || u.Scheme == "https":
+		if u.Host == "" {
+			return nil, fmt.Errorf("URL no válida %q: falta el servidor", raw)
+		}
+	case !allowAnyScheme:
+		return nil, fmt.Errorf("esquema %q no permitido: solo se abren URLs http y https (el servidor puede iniciarse con --allow-any-scheme)", u.Scheme)
+	}
+	return u, nil
+}
+
+// openURL abre una URL en el navegador por defecto o en el indicado
+func openURL(raw, browser string) (string, error) {
+	u, err := parseOpenURL(raw)
+	if err != nil {
+		return "", err
+	}
+	target := u.String()
+
+	var cmd *exec.Cmd
+	var backend string
+	platform := currentPlatform()
+	switch {
+	case browser != "":
+		if err := validateAppName(browser); err != nil {
+			return "", err
+		}
+		// El navegador se ejecuta como cualquier aplicación, así que se le
+		// aplica la misma lista que a open_app
+		allowedApps, err := loadAllowedApps()
+		if err != nil {
+			return "", err
+		}
+		if !appAllowed(allowedApps, browser) {
+			return "", fmt.Errorf("navegador '%s': %w (%s)", browser, errAppNotAllowed, allowedAppsFile)
+		}
+		backend = browser
+		switch platform {
+		case platformWindows, platformWSL:
+			// Start-Process no pasa por cmd, que interpretaría los '&' de la URL
+			script := fmt.Sprintf("Start-Process -FilePath '%s' -ArgumentList '%s'", browser, strings.ReplaceAll(target, "'", "''"))
+			cmd = exec.Command(powershellCommand(), "-NoProfile", "-NonInteractive", "-Command", script)
+		case platformMac:
+			cmd = exec.Command("open", "-a", browser, target)
+		default:
+			path, err := exec.LookPath(browser)
+			if err != nil {
+				return "", fmt.Errorf("no se encontró el navegador '%s'", browser)
+			}
+			cmd = exec.Command(path, target)
+		}
+	case platform == platformWindows || platform == platformWSL:
+		// FileProtocolHandler abre la URL con el programa asociado sin pasar por cmd
+		backend = "rundll32"
+		if isWSL() {
+			backend = "rundll32.exe"
+		}
+		cmd = exec.Command(backend, "url.dll,FileProtocolHandler", target)
+	case platform == platformMac:
+		backend = "open"
+		cmd = exec.Command("open", target)
+	default:
+		if !commandExists("xdg-open")() {
+			return "", errors.New("xdg-open no está instalado (instala el paquete xdg-utils)")
+		}
+		backend = "xdg-open"
+		cmd = exec.Command("xdg-open", target)
+	}
+	cmd.SysProcAttr = detachedProcAttr()
+
+	if err := cmd.Start(); err != nil {
+		return "", fmt.Errorf("%s: %w", backend, err)
+	}
+	go cmd.Wait()
+	return fmt.Sprintf("🌐 Abierta %s (vía %s)", target, backend), nil
+}
+
+type OpenURLInput struct {
+	URL     string `json:"url" jsonschema:"URL a abrir (http o https; sin esquema se asume https, ej: 'github.com', o http si lleva puerto, ej: 'localhost:8080')"`
+	Browser string `json:"browser,omitempty" jsonschema:"Navegador con el que abrirla (opcional). Ejecutable en Linux y Windows (ej: 'firefox'), nombre de la app en macOS (ej: 'Safari')"`
+}
+
+func HandleOpenURL(ctx context.Context, req *mcp.CallToolRequest, input OpenURLInput) (*mcp.CallToolResult, any, error) {
+	result, err := openURL(input.URL, input.Browser)
+	if err != nil {
+		return nil, nil, fmt.Errorf("❌ Error al abrir la URL: %w", err)
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: result},
+		},
+	}, nil, nil
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseOpenURL(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want string
+	}{
+		{"github.com", "https://github.com"},
+		{"https://example.com/a?b=1", "https://example.com/a?b=1"},
+		{"HTTP://example.com", "http://example.com"},
+		{"localhost:8080", "http://localhost:8080"},
+		{"localhost:3000/admin?x=1", "http://localhost:3000/admin?x=1"},
+		{"192.168.1.10:8443", "http://192.168.1.10:8443"},
+		{"[::1]:8080", "http://[::1]:8080"},
+	}
+	for _, tc := range tests {
+		u, err := parseOpenURL(tc.raw)
+		if err != nil {
+			t.Errorf("parseOpenURL(%q) = %v", tc.raw, err)
+			continue
+		}
+		if u.String() != tc.want {
+			t.Errorf("parseOpenURL(%q) = %q; se esperaba %q", tc.raw, u.String(), tc.want)
+		}
+	}
+	for _, raw := range []string{"", "file:///etc/passwd", "javascript:alert(1)", "mailto:a@example.com", "https://"} {
+		if u, err := parseOpenURL(raw); err == nil {
+			t.Errorf("parseOpenURL(%q) = %q; se esperaba un error", raw, u)
+		}
+	}
+}
+
+func TestOpenURLBrowserNotAllowed(t *testing.T) {
+	setOSType(t, "linux")
+	executed := fakeCommands(t, "firefox", "rm", "xdg-open")
+	writeTestConfig(t, allowedAppsFile, `["firefox"]`)
+	if _, err := openURL("example.com", "rm"); !errors.Is(err, errAppNotAllowed) {
+		t.Errorf("openURL con browser 'rm' = %v; se esperaba errAppNotAllowed", err)
+	}
+	if commands := executed(); len(commands) > 0 {
+		t.Errorf("se ejecutaron órdenes con un navegador no permitido: %q", commands)
+	}
+}
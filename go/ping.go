@@ -0,0 +1,412 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"os"
+	"os/exec"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// pingNetworksFile es el fichero de configuración con la lista JSON de redes
+// (CIDR o direcciones sueltas) a las que ping_host puede llegar, p. ej.
+// ["192.168.1.0/24", "10.0.0.5"]. Sin fichero o con la lista vacía se
+// permite cualquier dirección.
+const pingNetworksFile = "ping_networks.json"
+
+// Límites de ping_host
+const (
+	defaultPingCount   = 3
+	maxPingCount       = 5
+	defaultPingTimeout = 1000 * time.Millisecond
+	maxPingTimeout     = 5000 * time.Millisecond
+)
+
+// pingTCPPorts son los puertos que se prueban cuando no se puede usar ICMP
+var pingTCPPorts = []int{443, 80}
+
+// hostnameRegexp es la gramática de nombres de host (RFC 1123): etiquetas de
+// letras, dígitos y guiones que no empiezan ni terminan por guion. Impide
+// que el host se interprete como una opción de ping.
+var hostnameRegexp = regexp.MustCompile(`^(?i)[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?(\.[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?)*\.?$`)
+
+// validatePingHost comprueba que host sea una dirección IP o un nombre de host
+func validatePingHost(host string) error {
+	if host == "" {
+		return errors.New("indica el host")
+	}
+	if _, err := netip.ParseAddr(host); err == nil {
+		return nil
+	}
+	if len(host) > 253 || !hostnameRegexp.MatchString(host) {
+		return fmt.Errorf("%q no es un nombre de host ni una dirección IP válidos", host)
+	}
+	return nil
+}
+
+// loadPingNetworks lee las redes permitidas de pingNetworksFile; nil
+// significa que se permite cualquier dirección
+func loadPingNetworks() ([]netip.Prefix, error) {
+	var entries []string
+	if err := loadJSONConfig(pingNetworksFile, &entries); err != nil {
+		return nil, fmt.Errorf("error al leer %s: %w", pingNetworksFile, err)
+	}
+	var networks []netip.Prefix
+	for _, entry := range entries {
+		prefix, err := netip.ParsePrefix(entry)
+		if err != nil {
+			addr, addrErr := netip.ParseAddr(entry)
+			if addrErr != nil {
+				return nil, fmt.Errorf("%s: red no válida %q", pingNetworksFile, entry)
+			}
+			prefix = netip.PrefixFrom(addr, addr.BitLen())
+		}
+		networks = append(networks, prefix)
+	}
+	return networks, nil
+}
+
+// resolvePingHost resuelve host y devuelve la primera dirección permitida,
+// prefiriendo IPv4
+func resolvePingHost(ctx context.Context, host string, networks []netip.Prefix) (netip.Addr, error) {
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return netip.Addr{}, fmt.Errorf("no se pudo resolver %s: %w", host, err)
+	}
+	slices.SortStableFunc(addrs, func(a, b netip.Addr) int {
+		return a.BitLen() - b.BitLen()
+	})
+	for _, addr := range addrs {
+		addr = addr.Unmap()
+		if len(networks) == 0 || slices.ContainsFunc(networks, func(p netip.Prefix) bool { return p.Contains(addr) }) {
+			return addr, nil
+		}
+	}
+	configFile, _ := configPath(pingNetworksFile)
+	return netip.Addr{}, fmt.Errorf("%s no está en ninguna de las redes permitidas en %s", host, configFile)
+}
+
+// pinger envía un sondeo y devuelve la latencia. lost indica que no hubo
+// respuesta en el tiempo máximo; err, que el método no se puede usar.
+type pinger interface {
+	Method() string
+	Ping(ctx context.Context, seq int) (latency time.Duration, lost bool, err error)
+	Close()
+}
+
+// icmpPinger envía ecos ICMP con un socket raw, que requiere privilegios
+// (root o CAP_NET_RAW en Linux, administrador en Windows)
+type icmpPinger struct {
+	conn net.PacketConn
+	addr netip.Addr
+	id   int
+}
+
+func newICMPPinger(addr netip.Addr) (*icmpPinger, error) {
+	network := "ip4:icmp"
+	if addr.Is6() {
+		network = "ip6:ipv6-icmp"
+	}
+	conn, err := net.ListenPacket(network, "")
+	if err != nil {
+		return nil, err
+	}
+	return &icmpPinger{conn: conn, addr: addr, id: os.Getpid() & 0xffff}, nil
+}
+
+func (p *icmpPinger) Method() string { return "icmp" }
+func (p *icmpPinger) Close()         { p.conn.Close() }
+
+// icmpChecksum es la suma de complemento a uno de RFC 1071
+func icmpChecksum(data []byte) uint16 {
+	var sum uint32
+	for i := 0; i+1 < len(data); i += 2 {
+		sum += uint32(data[i])<<8 | uint32(data[i+1])
+	}
+	if len(data)%2 == 1 {
+		sum += uint32(data[len(data)-1]) << 8
+	}
+	for sum>>16 != 0 {
+		sum = sum&0xffff + sum>>16
+	}
+	return ^uint16(sum)
+}
+
+func (p *icmpPinger) Ping(ctx context.Context, seq int) (time.Duration, bool, error) {
+	// Eco ICMP: tipo, código, suma, identificador, secuencia y datos. En
+	// IPv6 el núcleo calcula la suma.
+	request, reply := byte(8), byte(0)
+	if p.addr.Is6() {
+		request, reply = 128, 129
+	}
+	packet := []byte{request, 0, 0, 0, byte(p.id >> 8), byte(p.id), byte(seq >> 8), byte(seq), 'm', 'c', 'p', '-', 'p', 'i', 'n', 'g'}
+	if !p.addr.Is6() {
+		sum := icmpChecksum(packet)
+		packet[2], packet[3] = byte(sum>>8), byte(sum)
+	}
+
+	deadline, _ := ctx.Deadline()
+	p.conn.SetReadDeadline(deadline)
+	start := time.Now()
+	if _, err := p.conn.WriteTo(packet, &net.IPAddr{IP: p.addr.AsSlice()}); err != nil {
+		return 0, false, err
+	}
+	buf := make([]byte, 1500)
+	for {
+		n, peer, err := p.conn.ReadFrom(buf)
+		if err != nil {
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				return 0, true, nil
+			}
+			return 0, false, err
+		}
+		// El socket raw recibe todos los ICMP del equipo: solo cuenta la
+		// respuesta a este eco
+		ip, ok := peer.(*net.IPAddr)
+		if n < 8 || !ok || !ip.IP.Equal(p.addr.AsSlice()) || buf[0] != reply {
+			continue
+		}
+		if int(buf[4])<<8|int(buf[5]) == p.id && int(buf[6])<<8|int(buf[7]) == seq {
+			return time.Since(start), false, nil
+		}
+	}
+}
+
+// systemPinger usa la orden ping del sistema, un sondeo por llamada. El host
+// ya está validado y se pasa como argumento, sin shell.
+type systemPinger struct {
+	path    string
+	addr    netip.Addr
+	timeout time.Duration
+}
+
+// pingTimeRegexp reconoce la latencia de una respuesta: "time=12.3 ms" en
+// Linux y macOS, "time=12ms" o "time<1ms" en Windows (también traducido,
+// "tiempo=12ms"); las respuestas llevan TTL en todos los idiomas
+var pingTimeRegexp = regexp.MustCompile(`(?i)ttl=.*?[=<]\s*([\d.]+)\s*ms|[=<]\s*([\d.]+)\s*ms.*ttl=`)
+
+func (p *systemPinger) Method() string { return "ping" }
+func (p *systemPinger) Close()         {}
+
+func (p *systemPinger) Ping(ctx context.Context, seq int) (time.Duration, bool, error) {
+	target := p.addr.String()
+	var args []string
+	switch currentPlatform() {
+	case platformWindows:
+		args = []string{"-n", "1", "-w", strconv.Itoa(int(p.timeout.Milliseconds())), target}
+	case platformMac:
+		// En macOS -W es la espera de la respuesta en milisegundos
+		args = []string{"-n", "-c", "1", "-W", strconv.Itoa(int(p.timeout.Milliseconds())), target}
+	default:
+		// En Linux -W son segundos enteros; el contexto corta antes si hace falta
+		args = []string{"-n", "-c", "1", "-W", strconv.Itoa(max(1, int((p.timeout+time.Second-1)/time.Second))), target}
+	}
+	if p.addr.Is6() && currentPlatform() != platformWindows && currentPlatform() != platformMac {
+		args = append([]string{"-6"}, args...)
+	}
+	cmd := exec.CommandContext(ctx, p.path, args...)
+	cmd.WaitDelay = time.Second
+	output, err := cmd.Output()
+	m := pingTimeRegexp.FindStringSubmatch(string(output))
+	if m == nil {
+		var exitErr *exec.ExitError
+		if err != nil && !errors.As(err, &exitErr) && ctx.Err() == nil {
+			return 0, false, err
+		}
+		return 0, true, nil
+	}
+	value := m[1]
+	if value == "" {
+		value = m[2]
+	}
+	ms, _ := strconv.ParseFloat(value, 64)
+	return time.Duration(ms * float64(time.Millisecond)), false, nil
+}
+
+// tcpPinger mide lo que tarda en establecerse (o rechazarse) una conexión
+// TCP. Una conexión rechazada también demuestra que el host responde.
+type tcpPinger struct {
+	addr netip.Addr
+	port int
+}
+
+func (p *tcpPinger) Method() string { return fmt.Sprintf("tcp:%d", p.port) }
+func (p *tcpPinger) Close()         {}
+
+func (p *tcpPinger) Ping(ctx context.Context, seq int) (time.Duration, bool, error) {
+	var dialer net.Dialer
+	start := time.Now()
+	conn, err := dialer.DialContext(ctx, "tcp", netip.AddrPortFrom(p.addr, uint16(p.port)).String())
+	latency := time.Since(start)
+	if err == nil {
+		conn.Close()
+		return latency, false, nil
+	}
+	// WSAECONNREFUSED (10061) es el equivalente en Windows
+	if errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.Errno(10061)) {
+		return latency, false, nil
+	}
+	return 0, true, nil
+}
+
+// newTCPPinger elige el primer puerto de pingTCPPorts que responde; si
+// ninguno lo hace se usa el primero y los sondeos cuentan como perdidos
+func newTCPPinger(ctx context.Context, addr netip.Addr, timeout time.Duration) *tcpPinger {
+	for _, port := range pingTCPPorts {
+		p := &tcpPinger{addr: addr, port: port}
+		probeCtx, cancel := context.WithTimeout(ctx, timeout)
+		_, lost, _ := p.Ping(probeCtx, 0)
+		cancel()
+		if !lost {
+			return p
+		}
+	}
+	return &tcpPinger{addr: addr, port: pingTCPPorts[0]}
+}
+
+// selectPinger prueba ICMP en Go, después la orden ping del sistema (que
+// suele tener permisos para ICMP aunque el servidor no los tenga) y por
+// último la conexión TCP
+func selectPinger(ctx context.Context, addr netip.Addr, timeout time.Duration) (pinger, string) {
+	if p, err := newICMPPinger(addr); err == nil {
+		return p, ""
+	}
+	command := "ping"
+	if addr.Is6() && currentPlatform() == platformMac {
+		// ping de macOS solo admite IPv4
+		command = "ping6"
+	}
+	if path, err := exec.LookPath(command); err == nil {
+		return &systemPinger{path: path, addr: addr, timeout: timeout}, ""
+	}
+	note := "Sin permisos para ICMP ni orden ping: se mide la conexión TCP, así que un host que no escucha en ese puerto ni lo rechaza aparece como inalcanzable"
+	return newTCPPinger(ctx, addr, timeout), note
+}
+
+type PingAttempt struct {
+	Seq       int      `json:"seq" jsonschema:"Número de intento, desde 1"`
+	LatencyMs *float64 `json:"latency_ms" jsonschema:"Latencia en milisegundos (null si no hubo respuesta)"`
+}
+
+type PingHostOutput struct {
+	Host              string        `json:"host" jsonschema:"Host consultado"`
+	Address           string        `json:"address" jsonschema:"Dirección IP sondeada"`
+	Method            string        `json:"method" jsonschema:"Método: icmp (en Go), ping (orden del sistema) o tcp:<puerto>"`
+	Reachable         bool          `json:"reachable" jsonschema:"Hubo al menos una respuesta"`
+	Attempts          []PingAttempt `json:"attempts" jsonschema:"Resultado de cada intento"`
+	PacketLossPercent float64       `json:"packet_loss_percent" jsonschema:"Porcentaje de intentos sin respuesta"`
+	AvgLatencyMs      *float64      `json:"avg_latency_ms" jsonschema:"Latencia media de las respuestas en milisegundos (null si no hubo ninguna)"`
+	Note              string        `json:"note,omitempty" jsonschema:"Aclaración sobre el método usado"`
+}
+
+type PingHostInput struct {
+	Host      string `json:"host" jsonschema:"Nombre de host o dirección IP (ej: 'nas.local', '192.168.1.10')"`
+	Count     int    `json:"count,omitempty" jsonschema:"Número de intentos (1-5, por defecto 3)"`
+	TimeoutMs int    `json:"timeout_ms,omitempty" jsonschema:"Tiempo máximo de espera de cada intento en milisegundos (por defecto 1000, hasta 5000)"`
+}
+
+func pingHost(ctx context.Context, input PingHostInput) (PingHostOutput, error) {
+	host := strings.TrimSpace(input.Host)
+	if err := validatePingHost(host); err != nil {
+		return PingHostOutput{}, err
+	}
+	count := input.Count
+	if count <= 0 {
+		count = defaultPingCount
+	}
+	timeout := time.Duration(input.TimeoutMs) * time.Millisecond
+	if timeout <= 0 {
+		timeout = defaultPingTimeout
+	}
+	networks, err := loadPingNetworks()
+	if err != nil {
+		return PingHostOutput{}, err
+	}
+	resolveCtx, cancel := context.WithTimeout(ctx, maxPingTimeout)
+	addr, err := resolvePingHost(resolveCtx, strings.TrimSuffix(host, "."), networks)
+	cancel()
+	if err != nil {
+		return PingHostOutput{}, err
+	}
+
+	p, note := selectPinger(ctx, addr, timeout)
+	defer p.Close()
+	out := PingHostOutput{Host: host, Address: addr.String(), Method: p.Method(), Note: note}
+	var total time.Duration
+	received := 0
+	for seq := 1; seq <= count; seq++ {
+		if seq > 1 {
+			// Un segundo entre intentos, como ping, salvo que se cancele la llamada
+			select {
+			case <-ctx.Done():
+				return PingHostOutput{}, ctx.Err()
+			case <-time.After(time.Second):
+			}
+		}
+		attemptCtx, cancel := context.WithTimeout(ctx, timeout)
+		latency, lost, err := p.Ping(attemptCtx, seq)
+		cancel()
+		if err != nil {
+			return PingHostOutput{}, fmt.Errorf("error al sondear %s con %s: %w", addr, p.Method(), err)
+		}
+		attempt := PingAttempt{Seq: seq}
+		if !lost {
+			ms := float64(latency.Microseconds()) / 1000
+			attempt.LatencyMs = &ms
+			total += latency
+			received++
+		}
+		out.Attempts = append(out.Attempts, attempt)
+	}
+	out.Reachable = received > 0
+	out.PacketLossPercent = float64(count-received) * 100 / float64(count)
+	if received > 0 {
+		avg := float64((total / time.Duration(received)).Microseconds()) / 1000
+		out.AvgLatencyMs = &avg
+	}
+	return out, nil
+}
+
+func HandlePingHost(ctx context.Context, req *mcp.CallToolRequest, input PingHostInput) (*mcp.CallToolResult, PingHostOutput, error) {
+	out, err := pingHost(ctx, input)
+	if err != nil {
+		return nil, PingHostOutput{}, fmt.Errorf("❌ Error al hacer ping: %w", err)
+	}
+
+	target := out.Host
+	if out.Address != out.Host {
+		target += " (" + out.Address + ")"
+	}
+	var lines []string
+	if out.Reachable {
+		lines = append(lines, fmt.Sprintf("✅ %s responde (%s): %.0f%% de pérdida, media %.1f ms", target, out.Method, out.PacketLossPercent, *out.AvgLatencyMs))
+	} else {
+		lines = append(lines, fmt.Sprintf("❌ %s no responde (%s)", target, out.Method))
+	}
+	for _, a := range out.Attempts {
+		if a.LatencyMs != nil {
+			lines = append(lines, fmt.Sprintf("  %d. %.1f ms", a.Seq, *a.LatencyMs))
+		} else {
+			lines = append(lines, fmt.Sprintf("  %d. sin respuesta", a.Seq))
+		}
+	}
+	if out.Note != "" {
+		lines = append(lines, "ℹ️ "+out.Note)
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: strings.Join(lines, "\n")},
+		},
+	}, out, nil
+}
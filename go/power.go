@@ -0,0 +1,522 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// powerCommand es una forma de ejecutar una acción de energía: la orden y
+// si está disponible en este sistema
+type powerCommand struct {
+	Name      string // Descripción para el resultado, p. ej. "loginctl lock-session"
+	Argv      []string
+	Available func() bool
+	Detached  bool // Se deja en ejecución (p. ej. slock, que se queda bloqueando)
+}
+
+// runPowerCommands prueba los comandos en orden hasta que uno funciona y
+// devuelve el nombre del que se usó
+func runPowerCommands(commands []powerCommand) (string, error) {
+	var errs []error
+	for _, c := range commands {
+		if c.Available != nil && !c.Available() {
+			continue
+		}
+		cmd := exec.Command(c.Argv[0], c.Argv[1:]...)
+		if c.Detached {
+			cmd.SysProcAttr = detachedProcAttr()
+			if err := cmd.Start(); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", c.Name, err))
+				continue
+			}
+			go cmd.Wait()
+			return c.Name, nil
+		}
+		output, err := cmd.CombinedOutput()
+		if err == nil {
+			return c.Name, nil
+		}
+		if msg := strings.TrimSpace(string(output)); msg != "" {
+			err = fmt.Errorf("%w: %s", err, msg)
+		}
+		log.Printf("⚠️ %s falló: %v", c.Name, err)
+		errs = append(errs, fmt.Errorf("%s: %w", c.Name, err))
+	}
+	if len(errs) == 0 {
+		return "", errors.New("no hay ningún método disponible")
+	}
+	return "", errors.Join(errs...)
+}
+
+// windowsExecutable devuelve la ruta de un programa de System32. En WSL se
+// usa la interoperabilidad con Windows, por el PATH o por /mnt/c si el PATH
+// de Windows no está incluido.
+func windowsExecutable(name string) string {
+	if currentPlatform() != platformWSL {
+		return name
+	}
+	if path, err := exec.LookPath(name); err == nil {
+		return path
+	}
+	return "/mnt/c/Windows/System32/" + name
+}
+
+// macLockScript llama a SACLockScreenImmediate del framework privado login,
+// que bloquea la sesión como la opción "Bloquear pantalla" del menú Apple
+const macLockScript = `ObjC.import('Foundation');
+$.NSBundle.bundleWithPath('/System/Library/PrivateFrameworks/login.framework').load;
+ObjC.bindFunction('SACLockScreenImmediate', ['int', []]);
+$.SACLockScreenImmediate();`
+
+// lockScreen bloquea la sesión del usuario y devuelve el método usado
+func lockScreen() (string, error) {
+	if err := checkGraphicalSession(); err != nil {
+		return "", err
+	}
+	var commands []powerCommand
+	switch currentPlatform() {
+	case platformWindows, platformWSL:
+		commands = []powerCommand{
+			{Name: "LockWorkStation", Argv: []string{windowsExecutable("rundll32.exe"), "user32.dll,LockWorkStation"}},
+		}
+	case platformMac:
+		commands = []powerCommand{
+			{Name: "SACLockScreenImmediate", Argv: []string{"osascript", "-l", "JavaScript", "-e", macLockScript}},
+			// Solo bloquea si se pide la contraseña al salir del reposo
+			{Name: "pmset displaysleepnow", Argv: []string{"pmset", "displaysleepnow"}},
+		}
+	default:
+		commands = []powerCommand{
+			{Name: "loginctl lock-session", Argv: []string{"loginctl", "lock-session"}, Available: commandExists("loginctl")},
+			{Name: "xdg-screensaver lock", Argv: []string{"xdg-screensaver", "lock"}, Available: commandExists("xdg-screensaver")},
+			{Name: "gnome-screensaver-command -l", Argv: []string{"gnome-screensaver-command", "-l"}, Available: commandExists("gnome-screensaver-command")},
+			{Name: "slock", Argv: []string{"slock"}, Available: commandExists("slock"), Detached: true},
+		}
+	}
+	method, err := runPowerCommands(commands)
+	if err != nil {
+		return "", fmt.Errorf("no se pudo bloquear la pantalla: %w", err)
+	}
+	// Con slock se apaga además el monitor, como haría el salvapantallas
+	if method == "slock" && os.Getenv("DISPLAY") != "" && commandExists("xset")() {
+		exec.Command("xset", "dpms", "force", "off").Run()
+	}
+	return method, nil
+}
+
+// windowsDisplayOffScript apaga los monitores con SC_MONITORPOWER. Se usa
+// PostMessage en lugar de SendMessage porque con HWND_BROADCAST SendMessage
+// espera a todas las ventanas y puede quedarse bloqueado.
+const windowsDisplayOffScript = `Add-Type -Name Win -Namespace DisplayOff -MemberDefinition '[DllImport("user32.dll")] public static extern bool PostMessage(System.IntPtr hWnd, uint msg, System.IntPtr wParam, System.IntPtr lParam);'
+# HWND_BROADCAST, WM_SYSCOMMAND, SC_MONITORPOWER, 2 = apagar
+if (-not [DisplayOff.Win]::PostMessage([IntPtr]0xffff, 0x0112, [IntPtr]0xF170, [IntPtr]2)) { exit 1 }`
+
+// errDisplayBusy es el error de display_off mientras cambia el brillo, ya
+// que cada paso de la transición volvería a encender la pantalla
+var errDisplayBusy = errors.New("hay una transición de brillo en curso; espera a que termine")
+
+// displayOff apaga la pantalla sin suspender el equipo y devuelve el método
+// usado
+func displayOff() (string, error) {
+	if fadeInProgress() {
+		return "", errDisplayBusy
+	}
+	if err := checkGraphicalSession(); err != nil {
+		return "", err
+	}
+	var commands []powerCommand
+	switch currentPlatform() {
+	case platformWindows, platformWSL:
+		commands = []powerCommand{
+			{Name: "SC_MONITORPOWER", Argv: []string{powershellCommand(), "-NoProfile", "-NonInteractive", "-Command", windowsDisplayOffScript}},
+		}
+	case platformMac:
+		commands = []powerCommand{
+			{Name: "pmset displaysleepnow", Argv: []string{"pmset", "displaysleepnow"}},
+		}
+	default:
+		session := detectLinuxSession()
+		if !session.Wayland {
+			commands = []powerCommand{
+				{Name: "xset dpms force off", Argv: []string{"xset", "dpms", "force", "off"}, Available: commandExists("xset")},
+			}
+			break
+		}
+		switch {
+		case session.IsDesktop("GNOME"):
+			// PowerSaveMode de Mutter: 0 encendida, 3 apagada
+			commands = []powerCommand{
+				{Name: "Mutter PowerSaveMode", Argv: []string{"gdbus", "call", "--session", "--dest", "org.gnome.Mutter.DisplayConfig",
+					"--object-path", "/org/gnome/Mutter/DisplayConfig", "--method", "org.freedesktop.DBus.Properties.Set",
+					"org.gnome.Mutter.DisplayConfig", "PowerSaveMode", "<int32 3>"}, Available: commandExists("gdbus")},
+			}
+		case session.IsDesktop("KDE"):
+			commands = []powerCommand{
+				{Name: "kscreen-doctor --dpms off", Argv: []string{"kscreen-doctor", "--dpms", "off"}, Available: commandExists("kscreen-doctor")},
+			}
+		default:
+			return "", fmt.Errorf("no se puede apagar la pantalla en Wayland (compositor: %s)", session.Compositor())
+		}
+	}
+	method, err := runPowerCommands(commands)
+	if err != nil {
+		return "", fmt.Errorf("no se pudo apagar la pantalla: %w", err)
+	}
+	return method, nil
+}
+
+// maxPowerDelay limita la espera de las acciones de energía programadas
+const maxPowerDelay = 300 * time.Second
+
+// pendingPowerAction es una acción de energía programada que aún no se ha
+// ejecutado y se puede cancelar
+type pendingPowerAction struct {
+	Name   string // Descripción para los mensajes, p. ej. "suspensión"
+	Due    time.Time
+	cancel func() error
+}
+
+// powerMu protege la acción pendiente y el resultado de la última que se
+// ejecutó con retraso, que solo queda en el log y en lastPowerResult
+var (
+	powerMu         sync.Mutex
+	pendingPower    *pendingPowerAction
+	lastPowerResult string
+)
+
+// disablePowerActions impide suspender, apagar o reiniciar el equipo
+// (configurable con --disable-power-actions)
+var disablePowerActions bool
+
+// errPowerActionsDisabled es el error de las acciones de energía cuando el
+// servidor se inició con --disable-power-actions
+var errPowerActionsDisabled = errors.New("las acciones de energía están desactivadas en este servidor (--disable-power-actions)")
+
+// pendingPowerLocked devuelve la acción pendiente, si no ha vencido ya.
+// Requiere tener powerMu.
+func pendingPowerLocked() *pendingPowerAction {
+	if pendingPower != nil && time.Now().After(pendingPower.Due) {
+		pendingPower = nil
+	}
+	return pendingPower
+}
+
+// schedulePowerAction ejecuta run al cabo de delay salvo que antes se llame
+// a cancelPowerAction. Solo puede haber una acción pendiente a la vez. Sin
+// retraso run se ejecuta en el momento y se devuelve su resultado.
+func schedulePowerAction(name string, delay time.Duration, run func() (string, error)) (string, error) {
+	powerMu.Lock()
+	defer powerMu.Unlock()
+	if err := checkPowerActionLocked(delay); err != nil {
+		return "", err
+	}
+	if delay == 0 {
+		return run()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	action := &pendingPowerAction{Name: name, Due: time.Now().Add(delay), cancel: func() error {
+		cancel()
+		return nil
+	}}
+	pendingPower = action
+	go func() {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+		powerMu.Lock()
+		if pendingPower != action {
+			// Se canceló justo cuando vencía el plazo
+			powerMu.Unlock()
+			return
+		}
+		pendingPower = nil
+		powerMu.Unlock()
+
+		result, err := run()
+		if err != nil {
+			result = fmt.Sprintf("❌ %s: %v", name, err)
+		}
+		log.Printf("⏰ Acción programada ejecutada: %s", result)
+		powerMu.Lock()
+		lastPowerResult = fmt.Sprintf("%s (%s)", result, time.Now().Format("15:04:05"))
+		powerMu.Unlock()
+	}()
+	return fmt.Sprintf("⏳ Acción programada: %s dentro de %v; usa cancel_pending_power_action para cancelarla", name, delay), nil
+}
+
+// scheduleNativePowerAction es como schedulePowerAction para las acciones
+// que el propio sistema sabe retrasar y cancelar (shutdown /t y shutdown /a
+// en Windows, que además avisan al usuario): start se ejecuta en el momento
+// y la acción queda pendiente hasta que vence, o hasta que cancel la anula
+func scheduleNativePowerAction(name string, delay time.Duration, start func() (string, error), cancel func() error) (string, error) {
+	powerMu.Lock()
+	defer powerMu.Unlock()
+	if err := checkPowerActionLocked(delay); err != nil {
+		return "", err
+	}
+	result, err := start()
+	if err != nil || delay == 0 {
+		return result, err
+	}
+	pendingPower = &pendingPowerAction{Name: name, Due: time.Now().Add(delay), cancel: cancel}
+	return result, nil
+}
+
+// checkPowerActionLocked comprueba que se puede programar una acción de
+// energía: que no estén desactivadas, que el retraso sea válido y que no
+// haya otra pendiente. Requiere tener powerMu.
+func checkPowerActionLocked(delay time.Duration) error {
+	if disablePowerActions {
+		return errPowerActionsDisabled
+	}
+	if delay < 0 || delay > maxPowerDelay {
+		return fmt.Errorf("el retraso debe estar entre 0 y %d segundos", int(maxPowerDelay.Seconds()))
+	}
+	if pending := pendingPowerLocked(); pending != nil {
+		return fmt.Errorf("ya hay una acción pendiente (%s dentro de %v); cancélala antes con cancel_pending_power_action", pending.Name, time.Until(pending.Due).Round(time.Second))
+	}
+	return nil
+}
+
+// cancelPowerAction cancela la acción de energía pendiente
+func cancelPowerAction() (string, error) {
+	powerMu.Lock()
+	defer powerMu.Unlock()
+	if pendingPowerLocked() == nil {
+		if lastPowerResult != "" {
+			return "", fmt.Errorf("no hay ninguna acción de energía pendiente; la última ya se ejecutó: %s", lastPowerResult)
+		}
+		return "", errors.New("no hay ninguna acción de energía pendiente")
+	}
+	action := pendingPower
+	if err := action.cancel(); err != nil {
+		return "", fmt.Errorf("error al cancelar %s: %w", action.Name, err)
+	}
+	pendingPower = nil
+	return fmt.Sprintf("⏹️ Acción cancelada: %s (faltaban %v)", action.Name, time.Until(action.Due).Round(time.Second)), nil
+}
+
+// suspendSystem suspende el equipo. Los comandos devuelven error si el
+// sistema rechaza la petición (p. ej. por falta de permisos o porque un
+// inhibidor la bloquea), de modo que el éxito indica que se aceptó.
+func suspendSystem() (string, error) {
+	var commands []powerCommand
+	switch currentPlatform() {
+	case platformWindows, platformWSL:
+		commands = []powerCommand{
+			{Name: "psshutdown -d", Argv: []string{"psshutdown", "-d", "-t", "0", "-accepteula"}, Available: commandExists("psshutdown")},
+			{Name: "psshutdown64 -d", Argv: []string{"psshutdown64", "-d", "-t", "0", "-accepteula"}, Available: commandExists("psshutdown64")},
+			// Hiberna en lugar de suspender si la hibernación está activada
+			{Name: "SetSuspendState", Argv: []string{windowsExecutable("rundll32.exe"), "powrprof.dll,SetSuspendState", "0,1,0"}},
+		}
+	case platformMac:
+		commands = []powerCommand{
+			{Name: "pmset sleepnow", Argv: []string{"pmset", "sleepnow"}},
+		}
+	default:
+		commands = []powerCommand{
+			{Name: "systemctl suspend", Argv: []string{"systemctl", "suspend"}, Available: commandExists("systemctl")},
+			{Name: "loginctl suspend", Argv: []string{"loginctl", "suspend"}, Available: commandExists("loginctl")},
+		}
+	}
+	method, err := runPowerCommands(commands)
+	if err != nil {
+		return "", fmt.Errorf("el sistema no aceptó la suspensión: %w", err)
+	}
+	return fmt.Sprintf("💤 Suspensión aceptada por el sistema (%s)", method), nil
+}
+
+// powerActions describe cada acción de power_action: el nombre para los
+// mensajes, el emoji y la opción de shutdown.exe en Windows
+var powerActions = map[string]struct {
+	Name, Emoji, WindowsFlag string
+}{
+	"shutdown": {Name: "apagado", Emoji: "🔌", WindowsFlag: "/s"},
+	"restart":  {Name: "reinicio", Emoji: "🔄", WindowsFlag: "/r"},
+	"log_out":  {Name: "cierre de sesión", Emoji: "👋", WindowsFlag: "/l"},
+}
+
+// powerAction apaga o reinicia el equipo o cierra la sesión del usuario,
+// opcionalmente con retraso. Solo actúa si confirm es literalmente "yes"; si
+// no, devuelve cómo confirmarlo.
+func powerAction(action, confirm string, delay time.Duration) (string, error) {
+	action = strings.ToLower(strings.TrimSpace(action))
+	a, ok := powerActions[action]
+	if !ok {
+		return "", fmt.Errorf("acción no válida %q: usa shutdown, restart o log_out", action)
+	}
+	if disablePowerActions {
+		return "", errPowerActionsDisabled
+	}
+	if action == "log_out" && currentPlatform() == platformWSL {
+		return "", errors.New("en WSL no hay ninguna sesión de usuario que cerrar; la sesión de Windows no se puede cerrar desde WSL")
+	}
+	if confirm != "yes" {
+		return fmt.Sprintf("⚠️ No se ha hecho nada. El %s cierra todas las aplicaciones y se puede perder el trabajo sin guardar: confírmalo con el usuario y repite la petición con confirm=\"yes\"", a.Name), nil
+	}
+
+	accepted := func(method string) string {
+		return fmt.Sprintf("%s %s aceptado por el sistema (%s)", a.Emoji, strings.ToUpper(a.Name[:1])+a.Name[1:], method)
+	}
+	if action == "log_out" {
+		return logOut(delay, accepted)
+	}
+	switch currentPlatform() {
+	case platformWindows, platformWSL:
+		shutdown := windowsExecutable("shutdown.exe")
+		seconds := strconv.Itoa(int(delay.Seconds()))
+		return scheduleNativePowerAction(a.Name, delay, func() (string, error) {
+			method, err := runPowerCommands([]powerCommand{
+				{Name: "shutdown " + a.WindowsFlag + " /t " + seconds, Argv: []string{shutdown, a.WindowsFlag, "/t", seconds}},
+			})
+			if err != nil {
+				return "", fmt.Errorf("el sistema no aceptó el %s: %w", a.Name, err)
+			}
+			if delay > 0 {
+				return fmt.Sprintf("⏳ Acción programada: %s dentro de %v (%s); usa cancel_pending_power_action para cancelarla", a.Name, delay, method), nil
+			}
+			return accepted(method), nil
+		}, func() error {
+			_, err := runPowerCommands([]powerCommand{{Name: "shutdown /a", Argv: []string{shutdown, "/a"}}})
+			return err
+		})
+	case platformMac:
+		return schedulePowerAction(a.Name, delay, func() (string, error) {
+			command := map[string]string{"shutdown": "shut down", "restart": "restart"}[action]
+			if _, err := runAppleScript(fmt.Sprintf(`tell application "System Events" to %s`, command)); err != nil {
+				return "", fmt.Errorf("el sistema no aceptó el %s: %w", a.Name, err)
+			}
+			return accepted("System Events"), nil
+		})
+	}
+	verb := map[string]string{"shutdown": "poweroff", "restart": "reboot"}[action]
+	return schedulePowerAction(a.Name, delay, func() (string, error) {
+		method, err := runPowerCommands([]powerCommand{
+			{Name: "systemctl " + verb, Argv: []string{"systemctl", verb}, Available: commandExists("systemctl")},
+			{Name: "loginctl " + verb, Argv: []string{"loginctl", verb}, Available: commandExists("loginctl")},
+		})
+		if err != nil {
+			return "", fmt.Errorf("el sistema no aceptó el %s: %w", a.Name, err)
+		}
+		return accepted(method), nil
+	})
+}
+
+// logOut cierra la sesión del usuario. shutdown /l no admite /t, así que
+// en Windows el retraso también lo gestiona el servidor.
+func logOut(delay time.Duration, accepted func(method string) string) (string, error) {
+	name := powerActions["log_out"].Name
+	var commands []powerCommand
+	switch currentPlatform() {
+	case platformWindows:
+		commands = []powerCommand{
+			{Name: "shutdown /l", Argv: []string{windowsExecutable("shutdown.exe"), "/l"}},
+		}
+	case platformMac:
+		commands = []powerCommand{
+			{Name: "System Events", Argv: []string{"osascript", "-e", `tell application "System Events" to log out`}},
+		}
+	default:
+		session := os.Getenv("XDG_SESSION_ID")
+		commands = []powerCommand{
+			{Name: "gnome-session-quit --logout", Argv: []string{"gnome-session-quit", "--logout", "--no-prompt"}, Available: func() bool {
+				return detectLinuxSession().IsDesktop("GNOME") && commandExists("gnome-session-quit")()
+			}},
+			{Name: "loginctl terminate-session", Argv: []string{"loginctl", "terminate-session", session}, Available: func() bool {
+				return session != "" && commandExists("loginctl")()
+			}},
+		}
+	}
+	return schedulePowerAction(name, delay, func() (string, error) {
+		method, err := runPowerCommands(commands)
+		if err != nil {
+			return "", fmt.Errorf("el sistema no aceptó el %s: %w", name, err)
+		}
+		return accepted(method), nil
+	})
+}
+
+func HandleLockScreen(ctx context.Context, req *mcp.CallToolRequest, input struct{}) (*mcp.CallToolResult, any, error) {
+	method, err := lockScreen()
+	if err != nil {
+		return nil, nil, fmt.Errorf("❌ Error al bloquear la pantalla: %w", err)
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: fmt.Sprintf("🔒 Pantalla bloqueada (%s)", method)},
+		},
+	}, nil, nil
+}
+
+func HandleDisplayOff(ctx context.Context, req *mcp.CallToolRequest, input struct{}) (*mcp.CallToolResult, any, error) {
+	method, err := displayOff()
+	if err != nil {
+		return nil, nil, fmt.Errorf("❌ Error al apagar la pantalla: %w", err)
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: fmt.Sprintf("🌑 Pantalla apagada (%s); el equipo sigue en marcha. Se vuelve a encender al mover el ratón o pulsar una tecla", method)},
+		},
+	}, nil, nil
+}
+
+type SleepSystemInput struct {
+	DelaySeconds int `json:"delay_seconds,omitempty" jsonschema:"Segundos de espera antes de suspender (0-300), para cerrar la tapa o alejarse. Se puede cancelar con cancel_pending_power_action"`
+}
+
+func HandleSleepSystem(ctx context.Context, req *mcp.CallToolRequest, input SleepSystemInput) (*mcp.CallToolResult, any, error) {
+	result, err := schedulePowerAction("suspensión", time.Duration(input.DelaySeconds)*time.Second, suspendSystem)
+	if err != nil {
+		return nil, nil, fmt.Errorf("❌ Error al suspender el equipo: %w", err)
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: result},
+		},
+	}, nil, nil
+}
+
+func HandleCancelPendingPowerAction(ctx context.Context, req *mcp.CallToolRequest, input struct{}) (*mcp.CallToolResult, any, error) {
+	result, err := cancelPowerAction()
+	if err != nil {
+		return nil, nil, fmt.Errorf("❌ Error al cancelar: %w", err)
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: result},
+		},
+	}, nil, nil
+}
+
+type PowerActionInput struct {
+	Action       string `json:"action" jsonschema:"Acción: shutdown (apagar), restart (reiniciar) o log_out (cerrar la sesión del usuario)"`
+	Confirm      string `json:"confirm,omitempty" jsonschema:"Debe ser literalmente \"yes\" para que se ejecute; sin él solo se devuelven instrucciones"`
+	DelaySeconds int    `json:"delay_seconds,omitempty" jsonschema:"Segundos de espera antes de actuar (0-300). Se puede cancelar con cancel_pending_power_action"`
+}
+
+func HandlePowerAction(ctx context.Context, req *mcp.CallToolRequest, input PowerActionInput) (*mcp.CallToolResult, any, error) {
+	result, err := powerAction(input.Action, input.Confirm, time.Duration(input.DelaySeconds)*time.Second)
+	if err != nil {
+		return nil, nil, fmt.Errorf("❌ Error al apagar, reiniciar o cerrar la sesión: %w", err)
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: result},
+		},
+	}, nil, nil
+}
@@ -0,0 +1,238 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// PowerPlan es un plan o perfil de energía del sistema
+type PowerPlan struct {
+	Name   string `json:"name" jsonschema:"Nombre del plan"`
+	ID     string `json:"id" jsonschema:"Identificador: GUID en Windows, perfil de power-profiles-daemon en Linux, valor de lowpowermode en macOS"`
+	Active bool   `json:"active,omitempty" jsonschema:"Es el plan activo"`
+}
+
+type PowerPlansOutput struct {
+	Active string      `json:"active" jsonschema:"Nombre del plan activo"`
+	Plans  []PowerPlan `json:"plans,omitempty" jsonschema:"Planes disponibles"`
+}
+
+// powerPlanAliases traduce los nombres habituales de los planes ("High
+// performance", "power saver"...) a un nombre canónico común a todas las
+// plataformas: performance, balanced o power-saver
+var powerPlanAliases = map[string]string{
+	"highperformance": "performance",
+	"performance":     "performance",
+	"balanced":        "balanced",
+	"automatic":       "balanced",
+	"normal":          "balanced",
+	"powersaver":      "power-saver",
+	"powersave":       "power-saver",
+	"lowpower":        "power-saver",
+	"batterysaver":    "power-saver",
+}
+
+// windowsPowerPlanGUIDs son los GUID de los planes predefinidos de Windows,
+// que se pueden pedir en inglés aunque el sistema los muestre traducidos
+var windowsPowerPlanGUIDs = map[string]string{
+	"performance": "8c5e7fda-e8bf-4a96-9a85-a6e23a8c635c",
+	"balanced":    "381b4222-f694-41f0-9685-ff5bb260df2e",
+	"power-saver": "a1841308-3541-4fab-bc81-f71556f20b4a",
+}
+
+// macPowerPlanIDs son los valores de lowpowermode de cada plan en macOS,
+// que no tiene un modo de alto rendimiento
+var macPowerPlanIDs = map[string]string{
+	"balanced":    "0",
+	"power-saver": "1",
+}
+
+// canonicalPowerPlan devuelve el nombre canónico de un plan, o "" si no es
+// uno de los conocidos
+func canonicalPowerPlan(name string) string {
+	key := strings.NewReplacer(" ", "", "-", "", "_", "").Replace(strings.ToLower(name))
+	return powerPlanAliases[key]
+}
+
+// listPowerPlans devuelve los planes de energía disponibles
+func listPowerPlans() ([]PowerPlan, error) {
+	switch currentPlatform() {
+	case platformWindows, platformWSL:
+		return powerPlansWindows()
+	case platformMac:
+		return powerPlansMac()
+	}
+	return powerPlansLinux()
+}
+
+// powercfgSchemeRegexp reconoce las líneas de `powercfg /list`, como
+//
+//	Power Scheme GUID: 381b4222-f694-41f0-9685-ff5bb260df2e  (Balanced) *
+var powercfgSchemeRegexp = regexp.MustCompile(`([0-9a-fA-F-]{36})\s+\((.+)\)(\s*\*)?`)
+
+func powerPlansWindows() ([]PowerPlan, error) {
+	output, err := exec.Command(windowsExecutable("powercfg.exe"), "/list").Output()
+	if err != nil {
+		return nil, fmt.Errorf("error al ejecutar powercfg /list: %w", err)
+	}
+	var plans []PowerPlan
+	for _, m := range powercfgSchemeRegexp.FindAllStringSubmatch(string(output), -1) {
+		plans = append(plans, PowerPlan{Name: m[2], ID: strings.ToLower(m[1]), Active: m[3] != ""})
+	}
+	return plans, nil
+}
+
+// powerprofilesRegexp reconoce los perfiles en `powerprofilesctl list`; el
+// activo lleva un asterisco delante
+var powerprofilesRegexp = regexp.MustCompile(`(?m)^(\*?)\s*([a-z-]+):$`)
+
+func powerPlansLinux() ([]PowerPlan, error) {
+	if !commandExists("powerprofilesctl")() {
+		return nil, errors.New("powerprofilesctl no está instalado (power-profiles-daemon)")
+	}
+	output, err := exec.Command("powerprofilesctl", "list").Output()
+	if err != nil {
+		return nil, fmt.Errorf("error al ejecutar powerprofilesctl list: %w", err)
+	}
+	var plans []PowerPlan
+	for _, m := range powerprofilesRegexp.FindAllStringSubmatch(string(output), -1) {
+		plans = append(plans, PowerPlan{Name: m[2], ID: m[2], Active: m[1] == "*"})
+	}
+	return plans, nil
+}
+
+// powerPlansMac expone el modo de bajo consumo de pmset como dos planes,
+// en los Mac que lo admiten (macOS 12 o posterior)
+func powerPlansMac() ([]PowerPlan, error) {
+	output, err := exec.Command("pmset", "-g").Output()
+	if err != nil {
+		return nil, fmt.Errorf("error al ejecutar pmset -g: %w", err)
+	}
+	for _, line := range strings.Split(string(output), "\n") {
+		if fields := strings.Fields(line); len(fields) == 2 && fields[0] == "lowpowermode" {
+			return []PowerPlan{
+				{Name: "Automatic", ID: "0", Active: fields[1] == "0"},
+				{Name: "Low Power", ID: "1", Active: fields[1] == "1"},
+			}, nil
+		}
+	}
+	return nil, errors.New("este Mac no admite el modo de bajo consumo (lowpowermode)")
+}
+
+// findPowerPlan busca un plan por nombre (sin distinguir mayúsculas), por
+// identificador o por uno de los nombres de powerPlanAliases
+func findPowerPlan(plans []PowerPlan, name string) (PowerPlan, bool) {
+	for _, p := range plans {
+		if strings.EqualFold(p.Name, name) || strings.EqualFold(p.ID, name) {
+			return p, true
+		}
+	}
+	canonical := canonicalPowerPlan(name)
+	if canonical == "" {
+		return PowerPlan{}, false
+	}
+	for _, p := range plans {
+		var match bool
+		switch currentPlatform() {
+		case platformWindows, platformWSL:
+			match = p.ID == windowsPowerPlanGUIDs[canonical]
+		case platformMac:
+			match = p.ID == macPowerPlanIDs[canonical]
+		default:
+			match = p.ID == canonical
+		}
+		if match {
+			return p, true
+		}
+	}
+	return PowerPlan{}, false
+}
+
+// setPowerPlan activa el plan de energía indicado y devuelve el plan
+func setPowerPlan(name string) (PowerPlan, error) {
+	name = strings.TrimSpace(name)
+	plans, err := listPowerPlans()
+	if err != nil {
+		return PowerPlan{}, err
+	}
+	plan, ok := findPowerPlan(plans, name)
+	if !ok {
+		names := make([]string, len(plans))
+		for i, p := range plans {
+			names[i] = p.Name
+		}
+		return PowerPlan{}, fmt.Errorf("no hay ningún plan de energía %q (disponibles: %s)", name, strings.Join(names, ", "))
+	}
+
+	var cmd *exec.Cmd
+	switch currentPlatform() {
+	case platformWindows, platformWSL:
+		cmd = exec.Command(windowsExecutable("powercfg.exe"), "/setactive", plan.ID)
+	case platformMac:
+		// Requiere privilegios de administrador
+		cmd = exec.Command("pmset", "-a", "lowpowermode", plan.ID)
+	default:
+		cmd = exec.Command("powerprofilesctl", "set", plan.ID)
+	}
+	if output, err := cmd.CombinedOutput(); err != nil {
+		if msg := strings.TrimSpace(string(output)); msg != "" {
+			return PowerPlan{}, fmt.Errorf("error al activar el plan %s: %w: %s", plan.Name, err, msg)
+		}
+		return PowerPlan{}, fmt.Errorf("error al activar el plan %s: %w", plan.Name, err)
+	}
+	plan.Active = true
+	return plan, nil
+}
+
+// describePowerPlan muestra el nombre del plan y, si es distinto, su identificador
+func describePowerPlan(plan PowerPlan) string {
+	if plan.ID == plan.Name {
+		return plan.Name
+	}
+	return fmt.Sprintf("%s (%s)", plan.Name, plan.ID)
+}
+
+func HandleGetPowerPlan(ctx context.Context, req *mcp.CallToolRequest, input struct{}) (*mcp.CallToolResult, PowerPlansOutput, error) {
+	plans, err := listPowerPlans()
+	if err != nil {
+		return nil, PowerPlansOutput{}, fmt.Errorf("❌ Error al obtener el plan de energía: %w", err)
+	}
+	out := PowerPlansOutput{Plans: plans}
+	lines := []string{""}
+	for _, p := range plans {
+		marker := " "
+		if p.Active {
+			out.Active = p.Name
+			marker = "*"
+		}
+		lines = append(lines, fmt.Sprintf("  %s %s", marker, describePowerPlan(p)))
+	}
+	lines[0] = fmt.Sprintf("⚡ Plan de energía activo: %s. Disponibles:", out.Active)
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: strings.Join(lines, "\n")},
+		},
+	}, out, nil
+}
+
+type SetPowerPlanInput struct {
+	Plan string `json:"plan" jsonschema:"Plan de energía: nombre (ej: 'High performance', 'Balanced', 'Power saver'), sin distinguir mayúsculas, o identificador"`
+}
+
+func HandleSetPowerPlan(ctx context.Context, req *mcp.CallToolRequest, input SetPowerPlanInput) (*mcp.CallToolResult, any, error) {
+	plan, err := setPowerPlan(input.Plan)
+	if err != nil {
+		return nil, nil, fmt.Errorf("❌ Error al cambiar el plan de energía: %w", err)
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: "⚡ Plan de energía activado: " + describePowerPlan(plan)},
+		},
+	}, nil, nil
+}
@@ -0,0 +1,345 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// PowerStateOutput es el estado de la tapa, de la alimentación y de las
+// pantallas externas. Lo que no se puede determinar queda a null con el
+// motivo en el campo *_error correspondiente.
+type PowerStateOutput struct {
+	LidOpen              *bool    `json:"lid_open" jsonschema:"La tapa está abierta; null si no se puede saber (p. ej. equipos sin tapa)"`
+	LidError             string   `json:"lid_error,omitempty" jsonschema:"Motivo por el que no se pudo saber el estado de la tapa"`
+	OnAC                 *bool    `json:"on_ac" jsonschema:"El equipo está conectado a la corriente (false: funciona con batería); null si no se puede saber"`
+	OnACError            string   `json:"on_ac_error,omitempty" jsonschema:"Motivo por el que no se pudo saber la fuente de alimentación"`
+	ExternalDisplay      *bool    `json:"external_display" jsonschema:"Hay alguna pantalla externa conectada; null si no se puede saber"`
+	ExternalDisplays     []string `json:"external_displays,omitempty" jsonschema:"Nombres de las pantallas externas conectadas"`
+	ExternalDisplayError string   `json:"external_display_error,omitempty" jsonschema:"Motivo por el que no se pudieron consultar las pantallas"`
+}
+
+// setLid, setAC y setExternalDisplays rellenan cada campo o su motivo
+func (s *PowerStateOutput) setLid(open bool, err error) {
+	if err != nil {
+		s.LidError = err.Error()
+		return
+	}
+	s.LidOpen = &open
+}
+
+func (s *PowerStateOutput) setAC(onAC bool, err error) {
+	if err != nil {
+		s.OnACError = err.Error()
+		return
+	}
+	s.OnAC = &onAC
+}
+
+func (s *PowerStateOutput) setExternalDisplays(names []string, err error) {
+	if err != nil {
+		s.ExternalDisplayError = err.Error()
+		return
+	}
+	external := len(names) > 0
+	s.ExternalDisplay, s.ExternalDisplays = &external, names
+}
+
+// getPowerState consulta la tapa, la alimentación y las pantallas externas
+func getPowerState() (PowerStateOutput, error) {
+	var state PowerStateOutput
+	switch currentPlatform() {
+	case platformWindows, platformWSL:
+		return powerStateWindows()
+	case platformMac:
+		state.setLid(lidOpenMac())
+		state.setAC(onACMac())
+		state.setExternalDisplays(externalDisplaysMac())
+	default:
+		state.setLid(lidOpenLinux())
+		state.setAC(onACLinux())
+		state.setExternalDisplays(externalDisplaysLinux())
+	}
+	return state, nil
+}
+
+// lidOpenLinux lee el estado del interruptor de la tapa de ACPI
+// ("state:      open")
+func lidOpenLinux() (bool, error) {
+	files, _ := filepath.Glob("/proc/acpi/button/lid/*/state")
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		switch {
+		case strings.HasSuffix(strings.TrimSpace(string(data)), "open"):
+			return true, nil
+		case strings.HasSuffix(strings.TrimSpace(string(data)), "closed"):
+			return false, nil
+		}
+	}
+	return false, errors.New("no se encontró el interruptor de la tapa en /proc/acpi/button/lid (puede que el equipo no tenga tapa)")
+}
+
+// onACLinux consulta las fuentes de /sys/class/power_supply: las de tipo
+// Mains o USB indican si hay corriente y, si no hay ninguna, se deduce del
+// estado de las baterías
+func onACLinux() (bool, error) {
+	supplies, _ := filepath.Glob("/sys/class/power_supply/*")
+	var mains, batteries []string
+	for _, supply := range supplies {
+		data, err := os.ReadFile(filepath.Join(supply, "type"))
+		if err != nil {
+			continue
+		}
+		switch kind := strings.TrimSpace(string(data)); {
+		case kind == "Mains" || strings.HasPrefix(kind, "USB"):
+			mains = append(mains, supply)
+		case kind == "Battery":
+			batteries = append(batteries, supply)
+		}
+	}
+	if len(mains) > 0 {
+		for _, supply := range mains {
+			if online, err := readSysfsInt(filepath.Join(supply, "online")); err == nil && online == 1 {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+	for _, battery := range batteries {
+		data, err := os.ReadFile(filepath.Join(battery, "status"))
+		if err != nil {
+			continue
+		}
+		switch strings.TrimSpace(string(data)) {
+		case "Discharging":
+			return false, nil
+		case "Charging", "Full", "Not charging":
+			return true, nil
+		}
+	}
+	return false, errors.New("no hay información de la fuente de alimentación en /sys/class/power_supply")
+}
+
+// externalDisplaysLinux devuelve las salidas externas conectadas, con xrandr
+// en X11 o, en Wayland o sin xrandr, con el estado de los conectores DRM
+func externalDisplaysLinux() ([]string, error) {
+	if commandExists("xrandr")() && !detectLinuxSession().Wayland {
+		outputs, err := listXrandrOutputs()
+		if err != nil {
+			return nil, err
+		}
+		var external []string
+		for _, o := range outputs {
+			if !isInternalOutput(o.Name) {
+				external = append(external, o.Name)
+			}
+		}
+		return external, nil
+	}
+
+	// /sys/class/drm/card0-HDMI-A-1/status contiene "connected" o "disconnected"
+	connectors, _ := filepath.Glob("/sys/class/drm/card*-*/status")
+	if len(connectors) == 0 {
+		return nil, errors.New("no se encontraron conectores de vídeo en /sys/class/drm")
+	}
+	var external []string
+	for _, status := range connectors {
+		data, err := os.ReadFile(status)
+		if err != nil || strings.TrimSpace(string(data)) != "connected" {
+			continue
+		}
+		_, name, _ := strings.Cut(filepath.Base(filepath.Dir(status)), "-")
+		if !isInternalOutput(name) {
+			external = append(external, name)
+		}
+	}
+	return external, nil
+}
+
+// macClamshellRegexp reconoce `"AppleClamshellState" = Yes` en ioreg (Yes:
+// tapa cerrada)
+var macClamshellRegexp = regexp.MustCompile(`"AppleClamshellState" = (Yes|No)`)
+
+func lidOpenMac() (bool, error) {
+	output, err := exec.Command("ioreg", "-r", "-k", "AppleClamshellState", "-d", "1").Output()
+	if err != nil {
+		return false, fmt.Errorf("error al ejecutar ioreg: %w", err)
+	}
+	m := macClamshellRegexp.FindStringSubmatch(string(output))
+	if m == nil {
+		return false, errors.New("ioreg no informa de AppleClamshellState (este Mac no tiene tapa)")
+	}
+	return m[1] == "No", nil
+}
+
+// macPowerSourceRegexp reconoce la primera línea de `pmset -g batt`:
+// "Now drawing from 'AC Power'" o "Now drawing from 'Battery Power'"
+var macPowerSourceRegexp = regexp.MustCompile(`drawing from '([^']+)'`)
+
+func onACMac() (bool, error) {
+	output, err := exec.Command("pmset", "-g", "batt").Output()
+	if err != nil {
+		return false, fmt.Errorf("error al ejecutar pmset -g batt: %w", err)
+	}
+	m := macPowerSourceRegexp.FindStringSubmatch(string(output))
+	if m == nil {
+		return false, fmt.Errorf("respuesta de pmset no válida: %q", strings.TrimSpace(string(output)))
+	}
+	return m[1] == "AC Power", nil
+}
+
+// externalDisplaysMac usa system_profiler en lugar de NSScreen (macScreens)
+// porque NSScreen no distingue la pantalla integrada de las externas
+func externalDisplaysMac() ([]string, error) {
+	output, err := exec.Command("system_profiler", "SPDisplaysDataType", "-json").Output()
+	if err != nil {
+		return nil, fmt.Errorf("error al ejecutar system_profiler: %w", err)
+	}
+	var profile struct {
+		SPDisplaysDataType []struct {
+			Displays []struct {
+				Name       string `json:"_name"`
+				Connection string `json:"spdisplays_connection_type"`
+			} `json:"spdisplays_ndrvs"`
+		}
+	}
+	if err := json.Unmarshal(output, &profile); err != nil {
+		return nil, fmt.Errorf("respuesta de system_profiler no válida: %w", err)
+	}
+	var external []string
+	for _, gpu := range profile.SPDisplaysDataType {
+		for _, d := range gpu.Displays {
+			if d.Connection != "spdisplays_internal" {
+				external = append(external, d.Name)
+			}
+		}
+	}
+	return external, nil
+}
+
+// windowsPowerStateScript devuelve el estado en JSON. La tapa solo se puede
+// consultar registrando una ventana para GUID_LIDSWITCH_STATE_CHANGE: Windows
+// le envía el estado actual nada más registrarse (en POWERBROADCAST_SETTING,
+// Data está en el byte 20). Sin tapa no llega ningún aviso y lid queda a -1.
+// Las pantallas externas son las de WmiMonitorConnectionParams cuya
+// VideoOutputTechnology no es interna (LVDS, DisplayPort o UDI integrados).
+const windowsPowerStateScript = `Add-Type -AssemblyName System.Windows.Forms
+Add-Type -ReferencedAssemblies System.Windows.Forms -TypeDefinition @'
+using System;
+using System.Runtime.InteropServices;
+using System.Windows.Forms;
+public class LidSwitchWindow : NativeWindow {
+	[DllImport("user32.dll")] static extern IntPtr RegisterPowerSettingNotification(IntPtr hWnd, ref Guid guid, int flags);
+	[DllImport("user32.dll")] static extern bool UnregisterPowerSettingNotification(IntPtr handle);
+	int state = -1;
+	public int Query() {
+		CreateHandle(new CreateParams());
+		Guid lid = new Guid("BA3E0F4D-B817-4094-A2D1-D56379E6A0F3");
+		IntPtr notification = RegisterPowerSettingNotification(Handle, ref lid, 0);
+		DateTime end = DateTime.Now.AddMilliseconds(500);
+		while (notification != IntPtr.Zero && state < 0 && DateTime.Now < end) { Application.DoEvents(); System.Threading.Thread.Sleep(10); }
+		if (notification != IntPtr.Zero) { UnregisterPowerSettingNotification(notification); }
+		DestroyHandle();
+		return state;
+	}
+	protected override void WndProc(ref Message m) {
+		if (m.Msg == 0x218 && (int)m.WParam == 0x8013) { state = Marshal.ReadByte(m.LParam, 20); }
+		base.WndProc(ref m);
+	}
+}
+'@
+$lid = (New-Object LidSwitchWindow).Query()
+$ac = [System.Windows.Forms.SystemInformation]::PowerStatus.PowerLineStatus
+$displays = $null
+$monitors = Get-CimInstance -Namespace root/wmi -ClassName WmiMonitorConnectionParams -ErrorAction SilentlyContinue
+if ($monitors) {
+	$names = @{}
+	Get-CimInstance -Namespace root/wmi -ClassName WmiMonitorID -ErrorAction SilentlyContinue | ForEach-Object { $names[$_.InstanceName] = -join ($_.UserFriendlyName | Where-Object { $_ } | ForEach-Object { [char]$_ }) }
+	$internal = @(6, 11, 13, 2147483648)
+	$displays = @($monitors | Where-Object { $internal -notcontains $_.VideoOutputTechnology } | ForEach-Object { if ($names[$_.InstanceName]) { $names[$_.InstanceName] } else { ($_.InstanceName -split '\\')[1] } })
+}
+ConvertTo-Json -Compress @{ lid = $lid; ac = "$ac"; displays = $displays }`
+
+func powerStateWindows() (PowerStateOutput, error) {
+	output, err := runPowerShell(windowsPowerStateScript)
+	if err != nil {
+		return PowerStateOutput{}, fmt.Errorf("error al consultar el estado de energía: %w", err)
+	}
+	var result struct {
+		Lid      int
+		AC       string
+		Displays *[]string
+	}
+	if err := json.Unmarshal([]byte(output), &result); err != nil {
+		return PowerStateOutput{}, fmt.Errorf("respuesta de PowerShell no válida: %w", err)
+	}
+
+	var state PowerStateOutput
+	switch result.Lid {
+	case 0, 1:
+		state.setLid(result.Lid == 1, nil)
+	default:
+		state.setLid(false, errors.New("Windows no informó del estado de la tapa (puede que el equipo no tenga tapa)"))
+	}
+	switch result.AC {
+	case "Online", "Offline":
+		state.setAC(result.AC == "Online", nil)
+	default:
+		state.setAC(false, fmt.Errorf("Windows no conoce la fuente de alimentación (PowerLineStatus %s)", result.AC))
+	}
+	if result.Displays == nil {
+		state.setExternalDisplays(nil, errors.New("WmiMonitorConnectionParams no devolvió ninguna pantalla"))
+	} else {
+		state.setExternalDisplays(*result.Displays, nil)
+	}
+	return state, nil
+}
+
+func HandleGetPowerState(ctx context.Context, req *mcp.CallToolRequest, input struct{}) (*mcp.CallToolResult, PowerStateOutput, error) {
+	state, err := getPowerState()
+	if err != nil {
+		return nil, PowerStateOutput{}, fmt.Errorf("❌ Error al obtener el estado de energía: %w", err)
+	}
+
+	lines := []string{"🔋 Estado de energía:"}
+	switch {
+	case state.LidOpen == nil:
+		lines = append(lines, "  Tapa: desconocida ("+state.LidError+")")
+	case *state.LidOpen:
+		lines = append(lines, "  Tapa: abierta")
+	default:
+		lines = append(lines, "  Tapa: cerrada")
+	}
+	switch {
+	case state.OnAC == nil:
+		lines = append(lines, "  Alimentación: desconocida ("+state.OnACError+")")
+	case *state.OnAC:
+		lines = append(lines, "  Alimentación: corriente")
+	default:
+		lines = append(lines, "  Alimentación: batería")
+	}
+	switch {
+	case state.ExternalDisplay == nil:
+		lines = append(lines, "  Pantalla externa: desconocida ("+state.ExternalDisplayError+")")
+	case *state.ExternalDisplay:
+		lines = append(lines, "  Pantalla externa: sí ("+strings.Join(state.ExternalDisplays, ", ")+")")
+	default:
+		lines = append(lines, "  Pantalla externa: no")
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: strings.Join(lines, "\n")},
+		},
+	}, state, nil
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// presetsFile guarda los presets de brillo entre reinicios del servidor
+const presetsFile = "brightness_presets.json"
+
+// presetsMu serializa las lecturas y escrituras del fichero de presets
+var presetsMu sync.Mutex
+
+// loadBrightnessPresets carga los presets guardados (nombre → nivel)
+func loadBrightnessPresets() (map[string]int, error) {
+	presets := map[string]int{}
+	if err := loadJSONConfig(presetsFile, &presets); err != nil {
+		return nil, fmt.Errorf("error al leer %s: %w", presetsFile, err)
+	}
+	return presets, nil
+}
+
+// presetNames devuelve los nombres de los presets ordenados
+func presetNames(presets map[string]int) []string {
+	names := make([]string, 0, len(presets))
+	for name := range presets {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// describePresets lista los presets con su nivel
+func describePresets(presets map[string]int) string {
+	if len(presets) == 0 {
+		return "📋 No hay presets de brillo guardados"
+	}
+	lines := []string{"📋 Presets de brillo:"}
+	for _, name := range presetNames(presets) {
+		lines = append(lines, fmt.Sprintf("  - %s: %d%%", name, presets[name]))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// saveBrightnessPreset guarda un preset con el nivel indicado o, si no se
+// indica, con el brillo actual
+func saveBrightnessPreset(name string, level *int) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", fmt.Errorf("el nombre del preset no puede estar vacío")
+	}
+
+	var value int
+	if level != nil {
+		value = *level
+		if value < 0 || value > 100 {
+			return "", fmt.Errorf("nivel %d fuera de rango (0-100)", value)
+		}
+	} else {
+		current, err := currentBrightness("")
+		if err != nil {
+			return "", fmt.Errorf("%w; indica el nivel explícitamente", err)
+		}
+		value = current
+	}
+
+	presetsMu.Lock()
+	defer presetsMu.Unlock()
+	presets, err := loadBrightnessPresets()
+	if err != nil {
+		return "", err
+	}
+	presets[name] = value
+	if err := saveJSONConfig(presetsFile, presets); err != nil {
+		return "", fmt.Errorf("error al guardar %s: %w", presetsFile, err)
+	}
+	return fmt.Sprintf("💾 Preset '%s' guardado con brillo %d%%", name, value), nil
+}
+
+// applyBrightnessPreset aplica un preset guardado. Sin nombre lista los disponibles.
+func applyBrightnessPreset(name, display string) (string, error) {
+	presetsMu.Lock()
+	presets, err := loadBrightnessPresets()
+	presetsMu.Unlock()
+	if err != nil {
+		return "", err
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return describePresets(presets), nil
+	}
+	level, ok := presets[name]
+	if !ok {
+		if len(presets) == 0 {
+			return "", fmt.Errorf("preset '%s' desconocido: no hay presets guardados", name)
+		}
+		return "", fmt.Errorf("preset '%s' desconocido (disponibles: %s)", name, strings.Join(presetNames(presets), ", "))
+	}
+	return fmt.Sprintf("🎛️ Preset '%s'\n%s", name, setBrightnessWithHistory(level, display, false)), nil
+}
+
+type SaveBrightnessPresetInput struct {
+	Name  string `json:"name" jsonschema:"Nombre del preset (ej: 'presentation', 'coding', 'night')"`
+	Level *int   `json:"level,omitempty" jsonschema:"Nivel de brillo (0-100). Si se omite se guarda el brillo actual"`
+}
+
+type ApplyBrightnessPresetInput struct {
+	Name    string `json:"name,omitempty" jsonschema:"Nombre del preset a aplicar. Vacío para listar los presets guardados"`
+	Display string `json:"display,omitempty" jsonschema:"Pantalla a ajustar (opcional, como en set_brightness)"`
+}
+
+func HandleSaveBrightnessPreset(ctx context.Context, req *mcp.CallToolRequest, input SaveBrightnessPresetInput) (*mcp.CallToolResult, any, error) {
+	result, err := saveBrightnessPreset(input.Name, input.Level)
+	if err != nil {
+		return nil, nil, fmt.Errorf("❌ Error al guardar preset: %w", err)
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: result},
+		},
+	}, nil, nil
+}
+
+func HandleApplyBrightnessPreset(ctx context.Context, req *mcp.CallToolRequest, input ApplyBrightnessPresetInput) (*mcp.CallToolResult, any, error) {
+	result, err := applyBrightnessPreset(input.Name, input.Display)
+	if err != nil {
+		return nil, nil, fmt.Errorf("❌ Error al aplicar preset: %w", err)
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: result},
+		},
+	}, nil, nil
+}
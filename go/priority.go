@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// processPriority es cómo se aplica cada prioridad de set_process_priority:
+// el valor de nice en Linux y macOS y la PriorityClass en Windows
+type processPriority struct {
+	Nice  int
+	Class string
+}
+
+var processPriorities = map[string]processPriority{
+	"low":    {Nice: 10, Class: "BelowNormal"},
+	"normal": {Nice: 0, Class: "Normal"},
+	"high":   {Nice: -10, Class: "High"},
+}
+
+// setProcessPriority cambia la prioridad de un proceso por pid o de todos
+// los procesos con ese nombre. Subirla por encima de normal puede dejar sin
+// CPU al resto del sistema, así que exige confirm.
+func setProcessPriority(name string, pid int, priority string, confirm bool) (string, error) {
+	name = strings.TrimSpace(name)
+	priority = strings.ToLower(strings.TrimSpace(priority))
+	p, ok := processPriorities[priority]
+	if !ok {
+		return "", fmt.Errorf("prioridad no válida %q: usa low, normal o high", priority)
+	}
+	if priority == "high" && !confirm {
+		return "", errors.New("subir la prioridad por encima de normal puede dejar sin CPU al resto del sistema; repite la petición con confirm=true")
+	}
+	if (name == "") == (pid == 0) {
+		return "", errors.New("indica el nombre del proceso o su pid, pero no ambos")
+	}
+	if name != "" {
+		if err := validateAppName(name); err != nil {
+			return "", err
+		}
+	}
+
+	target := name
+	if pid != 0 {
+		target = fmt.Sprintf("pid %d", pid)
+	}
+	var pids []string
+	var err error
+	if platform := currentPlatform(); platform == platformWindows || platform == platformWSL {
+		pids, err = setProcessPriorityWindows(name, pid, p.Class)
+	} else {
+		pids, err = setProcessPriorityUnix(name, pid, p.Nice)
+	}
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("⚙️ Prioridad de %s cambiada a %s en %d procesos (pids: %s)", target, priority, len(pids), strings.Join(pids, ", ")), nil
+}
+
+// setProcessPriorityUnix aplica el valor de nice con renice. Bajar el valor
+// (subir la prioridad) normalmente requiere ser root.
+func setProcessPriorityUnix(name string, pid, nice int) ([]string, error) {
+	var pids []string
+	if pid != 0 {
+		// La señal 0 solo comprueba si el proceso existe
+		process, err := os.FindProcess(pid)
+		if err == nil {
+			err = process.Signal(syscall.Signal(0))
+		}
+		if err != nil {
+			return nil, fmt.Errorf("no hay ningún proceso con pid %d", pid)
+		}
+		pids = []string{strconv.Itoa(pid)}
+	} else {
+		found, err := findProcesses(filepath.Base(name))
+		if err != nil {
+			return nil, err
+		}
+		if len(found) == 0 {
+			return nil, fmt.Errorf("no hay ningún proceso llamado %s en ejecución", name)
+		}
+		for _, pid := range found {
+			pids = append(pids, strconv.Itoa(pid))
+		}
+	}
+
+	args := append([]string{"-n", strconv.Itoa(nice), "-p"}, pids...)
+	if output, err := exec.Command("renice", args...).CombinedOutput(); err != nil {
+		if msg := strings.TrimSpace(string(output)); msg != "" {
+			return nil, fmt.Errorf("error al ejecutar renice: %w: %s", err, msg)
+		}
+		return nil, fmt.Errorf("error al ejecutar renice: %w", err)
+	}
+	return pids, nil
+}
+
+// setProcessPriorityWindows cambia la PriorityClass de los procesos con
+// PowerShell y devuelve sus pids
+func setProcessPriorityWindows(name string, pid int, class string) ([]string, error) {
+	selector := fmt.Sprintf("Get-Process -Id %d -ErrorAction SilentlyContinue", pid)
+	if name != "" {
+		// Get-Process -Name admite comodines; se compara el nombre exacto
+		exe := strings.ReplaceAll(strings.TrimSuffix(name, filepath.Ext(name)), "'", "''")
+		selector = fmt.Sprintf("Get-Process -ErrorAction SilentlyContinue | Where-Object { $_.ProcessName -eq '%s' }", exe)
+	}
+	script := fmt.Sprintf(`$p = @(%s)
+foreach ($proc in $p) { $proc.PriorityClass = '%s' }
+($p | ForEach-Object { $_.Id }) -join ','`, selector, class)
+	output, err := runPowerShell(script)
+	if err != nil {
+		return nil, fmt.Errorf("error al cambiar la prioridad: %w", err)
+	}
+	if output == "" {
+		if name != "" {
+			return nil, fmt.Errorf("no hay ningún proceso %s.exe en ejecución", strings.TrimSuffix(name, filepath.Ext(name)))
+		}
+		return nil, fmt.Errorf("no hay ningún proceso con pid %d", pid)
+	}
+	return strings.Split(output, ","), nil
+}
+
+type SetProcessPriorityInput struct {
+	Name     string `json:"name,omitempty" jsonschema:"Nombre del proceso (ej: 'cargo'); se cambian todos los que tengan ese nombre"`
+	PID      int    `json:"pid,omitempty" jsonschema:"Pid del proceso, en lugar del nombre"`
+	Priority string `json:"priority" jsonschema:"Prioridad: low, normal o high"`
+	Confirm  bool   `json:"confirm,omitempty" jsonschema:"Obligatorio para high, ya que puede dejar sin CPU al resto del sistema"`
+}
+
+func HandleSetProcessPriority(ctx context.Context, req *mcp.CallToolRequest, input SetProcessPriorityInput) (*mcp.CallToolResult, any, error) {
+	result, err := setProcessPriority(input.Name, input.PID, input.Priority, input.Confirm)
+	if err != nil {
+		return nil, nil, fmt.Errorf("❌ Error al cambiar la prioridad: %w", err)
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: result},
+		},
+	}, nil, nil
+}
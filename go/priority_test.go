@@ -0,0 +1,19 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSetProcessPriorityWindowsExactName(t *testing.T) {
+	script := fakePowerShell(t)
+	// Get-Process -Name '*' cambiaría la prioridad de todos los procesos
+	for _, name := range []string{"*", "explor*", "cargo.exe"} {
+		setProcessPriorityWindows(name, 0, "BelowNormal")
+		got := script()
+		if strings.Contains(got, "-Name") || !strings.Contains(got, "$_.ProcessName -eq '") {
+			t.Errorf("setProcessPriorityWindows(%q) no compara el nombre exacto: %s", name, got)
+		}
+		checkPSLiteral(t, got, strings.TrimSuffix(name, ".exe"))
+	}
+}
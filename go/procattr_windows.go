@@ -0,0 +1,9 @@
+package main
+
+import "syscall"
+
+// detachedProcAttr no hace nada en Windows, donde las aplicaciones se abren
+// con start y ya quedan separadas del servidor
+func detachedProcAttr() *syscall.SysProcAttr {
+	return nil
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+// trackedProcess es un proceso lanzado en segundo plano por una herramienta
+type trackedProcess struct {
+	ID      string
+	Kind    string // Herramienta que lo lanzó, p. ej. "sound"
+	Cmd     *exec.Cmd
+	Started time.Time
+}
+
+var (
+	processesMu sync.Mutex
+	processes   = map[string]*trackedProcess{}
+	processSeq  int
+)
+
+// startTracked arranca cmd sin esperar a que termine y lo registra con un
+// id del tipo "sound-3". Una goroutine espera al proceso (para no dejar
+// zombis) y lo retira del registro al terminar. Los errores de arranque se
+// devuelven de inmediato.
+func startTracked(kind string, cmd *exec.Cmd) (string, error) {
+	if err := cmd.Start(); err != nil {
+		return "", err
+	}
+
+	processesMu.Lock()
+	processSeq++
+	p := &trackedProcess{
+		ID:      fmt.Sprintf("%s-%d", kind, processSeq),
+		Kind:    kind,
+		Cmd:     cmd,
+		Started: time.Now(),
+	}
+	processes[p.ID] = p
+	processesMu.Unlock()
+
+	go func() {
+		if err := cmd.Wait(); err != nil && !isKilled(err) {
+			log.Printf("⚠️ El proceso %s (%s) terminó con error: %v", p.ID, cmd.Path, err)
+		}
+		processesMu.Lock()
+		delete(processes, p.ID)
+		processesMu.Unlock()
+	}()
+	return p.ID, nil
+}
+
+// isKilled indica si un proceso terminó porque se le mató desde stopTracked
+func isKilled(err error) bool {
+	var exitErr *exec.ExitError
+	return errors.As(err, &exitErr) && !exitErr.Exited()
+}
+
+// stopTracked detiene los procesos de un tipo: el indicado por id o todos si
+// id está vacío. Devuelve cuántos se detuvieron realmente; los que ya habían
+// terminado (aunque la goroutine aún no los haya retirado) no cuentan.
+func stopTracked(kind, id string) (int, error) {
+	processesMu.Lock()
+	var targets []*trackedProcess
+	for _, p := range processes {
+		if p.Kind == kind && (id == "" || p.ID == id) {
+			targets = append(targets, p)
+		}
+	}
+	processesMu.Unlock()
+
+	if id != "" && len(targets) == 0 {
+		return 0, fmt.Errorf("no hay ningún proceso activo con id %q", id)
+	}
+
+	stopped := 0
+	for _, p := range targets {
+		err := p.Cmd.Process.Kill()
+		if errors.Is(err, os.ErrProcessDone) {
+			continue
+		}
+		if err != nil {
+			return stopped, fmt.Errorf("no se pudo detener %s: %w", p.ID, err)
+		}
+		stopped++
+	}
+	return stopped, nil
+}
+
+// isTracked indica si el proceso con ese id sigue en ejecución
+func isTracked(id string) bool {
+	processesMu.Lock()
+	defer processesMu.Unlock()
+	_, ok := processes[id]
+	return ok
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"time"
+)
+
+// errRadioRequiresAdmin indica que el sistema rechazó encender o apagar la
+// radio (Wi-Fi o Bluetooth) por falta de privilegios, para distinguirlo de
+// un adaptador que no responde
+var errRadioRequiresAdmin = errors.New("el cambio requiere privilegios de administrador")
+
+// radioSettleTimeout es lo que se espera a que el estado leído refleje el
+// cambio; la API de radios de Windows, NetworkManager y BlueZ lo aplican de
+// forma asíncrona
+const radioSettleTimeout = 3 * time.Second
+
+// radioCommandTimeout limita cada orden: bluetoothctl se queda esperando
+// indefinidamente si bluetoothd no está en marcha
+const radioCommandTimeout = 10 * time.Second
+
+// runRadioCommand es runSystemCommand para las órdenes que encienden o
+// apagan una radio: si falla por falta de privilegios el error envuelve
+// errRadioRequiresAdmin.
+func runRadioCommand(name string, args ...string) (string, error) {
+	output, err := runSystemCommandTimeout(radioCommandTimeout, name, args...)
+	if errors.Is(err, errRequiresAdmin) {
+		return output, fmt.Errorf("%s: %w", filepath.Base(name), errRadioRequiresAdmin)
+	}
+	return output, err
+}
+
+// waitForRadioState lee el estado con read hasta que coincide con want o se
+// agota radioSettleTimeout, y devuelve el último leído. Un cambio que no se
+// aplica sin dar error es peor que un error, así que quien llama debe
+// comprobar el resultado.
+func waitForRadioState[T any](want bool, current T, read func() (T, error), enabled func(T) bool) T {
+	deadline := time.Now().Add(radioSettleTimeout)
+	for {
+		if after, err := read(); err == nil {
+			current = after
+		}
+		if enabled(current) == want || time.Now().After(deadline) {
+			return current
+		}
+		time.Sleep(250 * time.Millisecond)
+	}
+}
+
+// linuxRfkill lee los bloqueos de los interruptores rfkill del tipo dado
+// ("wlan", "bluetooth"). found es false si no hay ninguno.
+func linuxRfkill(kind string) (soft, hard, found bool) {
+	dirs, _ := filepath.Glob("/sys/class/rfkill/rfkill*")
+	for _, dir := range dirs {
+		if readTrimmedFile(filepath.Join(dir, "type")) != kind {
+			continue
+		}
+		found = true
+		soft = soft || readTrimmedFile(filepath.Join(dir, "soft")) == "1"
+		hard = hard || readTrimmedFile(filepath.Join(dir, "hard")) == "1"
+	}
+	return soft, hard, found
+}
+
+// windowsWinRTPrelude carga la proyección de WinRT en Windows PowerShell y
+// define Await, que espera una operación asíncrona de WinRT
+const windowsWinRTPrelude = `
+	Add-Type -AssemblyName System.Runtime.WindowsRuntime
+	$asTask = [System.WindowsRuntimeSystemExtensions].GetMethods() | Where-Object { $_.Name -eq 'AsTask' -and $_.GetParameters().Count -eq 1 -and $_.GetParameters()[0].ParameterType.Name -like 'IAsyncOperation*' } | Select-Object -First 1
+	function Await($op, $type) {
+		$task = $asTask.MakeGenericMethod($type).Invoke($null, @($op))
+		$task.Wait(-1) | Out-Null
+		$task.Result
+	}
+`
+
+// windowsRadioPrelude carga la API de radios de WinRT (Windows.Devices.Radios),
+// que enciende y apaga la Wi-Fi y el Bluetooth como los ajustes rápidos y sin
+// privilegios de administrador. $radios queda vacío si la API no está
+// disponible.
+const windowsRadioPrelude = `
+$radios = @()
+try {` + windowsWinRTPrelude + `	[Windows.Devices.Radios.Radio,Windows.System.Devices,ContentType=WindowsRuntime] | Out-Null
+	Await ([Windows.Devices.Radios.Radio]::RequestAccessAsync()) ([Windows.Devices.Radios.RadioAccessStatus]) | Out-Null
+	$radios = Await ([Windows.Devices.Radios.Radio]::GetRadiosAsync()) ([System.Collections.Generic.IReadOnlyList[Windows.Devices.Radios.Radio]])
+} catch {}
+`
+
+// setWindowsRadio enciende o apaga la radio del tipo kind ("WiFi",
+// "Bluetooth") con la API de radios; label es su nombre en los mensajes
+func setWindowsRadio(kind, label string, on bool) error {
+	state := "Off"
+	if on {
+		state = "On"
+	}
+	script := windowsRadioPrelude + fmt.Sprintf(`
+$radio = $radios | Where-Object { $_.Kind -eq '%s' } | Select-Object -First 1
+if (-not $radio) { throw 'La API de radios no encontró la radio %s' }
+[Windows.Devices.Radios.RadioState,Windows.System.Devices,ContentType=WindowsRuntime] | Out-Null
+[string](Await ($radio.SetStateAsync([Windows.Devices.Radios.RadioState]::%s)) ([Windows.Devices.Radios.RadioAccessStatus]))
+`, kind, label, state)
+	output, err := runPowerShell(script)
+	if err != nil {
+		return fmt.Errorf("error al cambiar la radio %s: %w", label, err)
+	}
+	if output != "Allowed" {
+		// DeniedByUser o DeniedBySystem: la privacidad de Windows no deja
+		// controlar las radios a las aplicaciones de escritorio
+		return fmt.Errorf("Windows no permite cambiar la radio %s (%s): revisa Configuración > Privacidad y seguridad > Radios", label, output)
+	}
+	return nil
+}
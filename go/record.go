@@ -0,0 +1,236 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// maxRecordDuration limita la duración de record_audio
+const maxRecordDuration = 60 * time.Second
+
+// recordStopGrace es lo que se espera a que un grabador termine tras pedirle
+// que pare, antes de matarlo
+const recordStopGrace = 5 * time.Second
+
+// recorder es un programa de grabación. Los que tienen duración propia
+// (Timed) paran solos; al resto se les pide parar con SIGINT, o escribiendo
+// una línea en su entrada estándar si StopStdin, para que cierren bien el WAV.
+type recorder struct {
+	Name      string
+	Argv      []string
+	Timed     bool
+	StopStdin bool
+}
+
+// psRecordMCI graba del micrófono por defecto con MCI (winmm.dll) hasta
+// que pasan los milisegundos indicados o llega una línea por stdin
+const psRecordMCI = `Add-Type -Name Mci -Namespace Rec -MemberDefinition '[DllImport("winmm.dll", CharSet = CharSet.Unicode)] public static extern int mciSendString(string cmd, System.Text.StringBuilder ret, int len, System.IntPtr cb);'
+function Mci($c) { $r = [Rec.Mci]::mciSendString($c, $null, 0, [IntPtr]::Zero); if ($r -ne 0) { throw "MCI error ${r}: $c" } }
+Mci 'open new type waveaudio alias rec'
+Mci 'set rec bitspersample 16 channels 1 samplespersec 44100'
+Mci 'record rec'
+[Console]::In.ReadLineAsync().Wait(%d) | Out-Null
+Mci 'stop rec'
+Mci ('save rec "' + '%s' + '"')
+Mci 'close rec'`
+
+// audioRecorder elige el programa de grabación de la plataforma
+func audioRecorder(path string, duration time.Duration) (recorder, error) {
+	seconds := strconv.Itoa(int(duration.Seconds()))
+	switch currentPlatform() {
+	case platformWindows, platformWSL:
+		winPath, err := windowsPath(path)
+		if err != nil {
+			return recorder{}, err
+		}
+		script := fmt.Sprintf(psRecordMCI, duration.Milliseconds(), strings.ReplaceAll(winPath, "'", "''"))
+		return recorder{
+			Name:      "MCI",
+			Argv:      []string{powershellCommand(), "-NoProfile", "-Command", script},
+			Timed:     true,
+			StopStdin: true,
+		}, nil
+	case platformMac:
+		switch {
+		case commandExists("rec")():
+			return recorder{Name: "sox", Argv: []string{"rec", "-q", "-c", "1", path, "trim", "0", seconds}, Timed: true}, nil
+		case commandExists("ffmpeg")():
+			return recorder{Name: "ffmpeg", Argv: []string{"ffmpeg", "-loglevel", "error", "-f", "avfoundation", "-i", ":default", "-t", seconds, "-y", path}, Timed: true}, nil
+		}
+		return recorder{}, errors.New("no se encontró ningún programa de grabación (instala sox con 'brew install sox' o ffmpeg)")
+	}
+
+	switch {
+	case commandExists("arecord")():
+		return recorder{Name: "arecord", Argv: []string{"arecord", "-q", "-f", "cd", "-c", "1", "-t", "wav", "-d", seconds, path}, Timed: true}, nil
+	case commandExists("pw-record")():
+		return recorder{Name: "pw-record", Argv: []string{"pw-record", "--channels", "1", path}}, nil
+	case commandExists("parecord")():
+		return recorder{Name: "parecord", Argv: []string{"parecord", "--channels=1", "--file-format=wav", path}}, nil
+	case commandExists("rec")():
+		return recorder{Name: "sox", Argv: []string{"rec", "-q", "-c", "1", path, "trim", "0", seconds}, Timed: true}, nil
+	}
+	return recorder{}, errors.New("no se encontró ningún programa de grabación (instala alsa-utils para arecord, pipewire para pw-record o sox)")
+}
+
+// recordingPath resuelve la ruta de salida: por defecto un fichero con la
+// fecha en <config>/hardware-control/recordings, y las rutas relativas
+// dentro de ese mismo directorio
+func recordingPath(output string) (string, error) {
+	dir, err := configPath("recordings")
+	if err != nil {
+		return "", err
+	}
+	if output == "" {
+		output = time.Now().Format("recording-20060102-150405.wav")
+	}
+	if !filepath.IsAbs(output) {
+		output = filepath.Join(dir, output)
+	}
+	switch strings.ToLower(filepath.Ext(output)) {
+	case "":
+		output += ".wav"
+	case ".wav":
+	default:
+		return "", fmt.Errorf("solo se graba en WAV: usa la extensión .wav en lugar de %s", filepath.Ext(output))
+	}
+	return output, nil
+}
+
+// recordAudio graba del micrófono por defecto durante duration, o hasta que
+// se cancele la petición, y devuelve la ruta y el tamaño del fichero
+func recordAudio(ctx context.Context, output string, duration time.Duration, overwrite bool) (string, error) {
+	if duration <= 0 || duration > maxRecordDuration {
+		return "", fmt.Errorf("duración %s fuera de rango (1-%d segundos)", duration, int(maxRecordDuration.Seconds()))
+	}
+	path, err := recordingPath(output)
+	if err != nil {
+		return "", err
+	}
+	if _, err := os.Stat(path); err == nil {
+		if !overwrite {
+			return "", fmt.Errorf("%s ya existe (usa overwrite=true para sobrescribirlo)", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return "", err
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", err
+	}
+
+	rec, err := audioRecorder(path, duration)
+	if err != nil {
+		return "", err
+	}
+	start := time.Now()
+	stopped, err := runRecorder(ctx, rec, duration)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", rec.Name, err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("%s no generó el fichero: %w", rec.Name, err)
+	}
+	length := fmt.Sprintf("%d s", int(duration.Seconds()))
+	if stopped {
+		length = fmt.Sprintf("detenida a los %.0f s", time.Since(start).Seconds())
+	}
+	return fmt.Sprintf("🎙️ Grabación guardada en %s (%.1f KB, %s, vía %s)", path, float64(info.Size())/1024, length, rec.Name), nil
+}
+
+// runRecorder ejecuta el grabador y lo detiene de forma ordenada al cumplirse
+// la duración (si no para solo) o al cancelarse ctx. Devuelve si se canceló.
+func runRecorder(ctx context.Context, rec recorder, duration time.Duration) (bool, error) {
+	cmd := exec.Command(rec.Argv[0], rec.Argv[1:]...)
+	var stdin io.WriteCloser
+	if rec.StopStdin {
+		var err error
+		if stdin, err = cmd.StdinPipe(); err != nil {
+			return false, err
+		}
+	}
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	if err := cmd.Start(); err != nil {
+		return false, err
+	}
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+
+	// Los grabadores con duración propia solo se detienen si se retrasan
+	limit := duration
+	if rec.Timed {
+		limit += recordStopGrace
+	}
+	timer := time.NewTimer(limit)
+	defer timer.Stop()
+
+	stop := func() {
+		if stdin != nil {
+			io.WriteString(stdin, "\n")
+			stdin.Close()
+		} else {
+			cmd.Process.Signal(os.Interrupt)
+		}
+	}
+
+	cancelled, stopping := false, true
+	var err error
+	select {
+	case err = <-done:
+		stopping = false
+	case <-ctx.Done():
+		cancelled = true
+		stop()
+	case <-timer.C:
+		stop()
+	}
+	if stopping {
+		select {
+		case err = <-done:
+		case <-time.After(recordStopGrace):
+			cmd.Process.Kill()
+			err = <-done
+		}
+	}
+
+	// Una parada pedida con SIGINT termina con código de salida distinto de 0
+	var exitErr *exec.ExitError
+	if err != nil && !(errors.As(err, &exitErr) && (cancelled || !rec.Timed)) {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return cancelled, fmt.Errorf("%w: %s", err, msg)
+		}
+		return cancelled, err
+	}
+	return cancelled, nil
+}
+
+type RecordAudioInput struct {
+	DurationSeconds int    `json:"duration_seconds" jsonschema:"Duración de la grabación en segundos (1-60)"`
+	OutputPath      string `json:"output_path,omitempty" jsonschema:"Fichero WAV de salida (opcional). Las rutas relativas se guardan en el directorio de grabaciones de la configuración"`
+	Overwrite       bool   `json:"overwrite,omitempty" jsonschema:"Si es true sobrescribe el fichero si ya existe"`
+}
+
+func HandleRecordAudio(ctx context.Context, req *mcp.CallToolRequest, input RecordAudioInput) (*mcp.CallToolResult, any, error) {
+	result, err := recordAudio(ctx, input.OutputPath, time.Duration(input.DurationSeconds)*time.Second, input.Overwrite)
+	if err != nil {
+		return nil, nil, fmt.Errorf("❌ Error al grabar audio: %w", err)
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: result},
+		},
+	}, nil, nil
+}
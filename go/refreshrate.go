@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type SetRefreshRateInput struct {
+	Hz      float64 `json:"hz" jsonschema:"Frecuencia de refresco en Hz (ej: 60, 144 o 59.94)"`
+	Display string  `json:"display,omitempty" jsonschema:"Índice o id de la pantalla según list_displays (por defecto la principal)"`
+}
+
+type SetRefreshRateOutput struct {
+	Display    string  `json:"display" jsonschema:"Id de la pantalla"`
+	Resolution string  `json:"resolution" jsonschema:"Resolución actual, que no cambia"`
+	PreviousHz float64 `json:"previous_hz" jsonschema:"Frecuencia anterior en Hz"`
+	Hz         float64 `json:"hz" jsonschema:"Frecuencia aplicada en Hz"`
+	Changed    bool    `json:"changed" jsonschema:"Se cambió la frecuencia (false si ya era la pedida)"`
+	Method     string  `json:"method,omitempty" jsonschema:"Herramienta usada (xrandr, wlr-randr, displayplacer o ChangeDisplaySettingsEx)"`
+}
+
+// setRefreshRate cambia solo la frecuencia de la pantalla, entre las que
+// admite su resolución actual. A diferencia de set_resolution no se deshace
+// sola y se guarda en el momento: la resolución no cambia y el modo ya está
+// validado.
+func setRefreshRate(input SetRefreshRateInput) (SetRefreshRateOutput, error) {
+	if input.Hz <= 0 {
+		return SetRefreshRateOutput{}, errors.New("indica la frecuencia de refresco en Hz (hz)")
+	}
+
+	resolutionMu.Lock()
+	defer resolutionMu.Unlock()
+	if err := checkPendingResolutionLocked(); err != nil {
+		return SetRefreshRateOutput{}, err
+	}
+
+	target, err := resolveResolutionTarget(strings.TrimSpace(input.Display))
+	if err != nil {
+		return SetRefreshRateOutput{}, err
+	}
+	current := target.current
+	mode, err := chooseDisplayMode(target, current.Width, current.Height, input.Hz)
+	if err != nil {
+		return SetRefreshRateOutput{}, err
+	}
+	out := SetRefreshRateOutput{
+		Display:    target.id,
+		Resolution: fmt.Sprintf("%dx%d", current.Width, current.Height),
+		PreviousHz: round2(current.RefreshRate),
+		Hz:         round2(mode.RefreshRate),
+		Method:     target.method,
+	}
+	if mode == current {
+		return out, nil
+	}
+	if err := applyDisplayMode(target, mode); err != nil {
+		return SetRefreshRateOutput{}, err
+	}
+	if err := persistDisplayMode(target); err != nil {
+		return SetRefreshRateOutput{}, err
+	}
+	out.Changed = true
+	return out, nil
+}
+
+func HandleSetRefreshRate(ctx context.Context, req *mcp.CallToolRequest, input SetRefreshRateInput) (*mcp.CallToolResult, SetRefreshRateOutput, error) {
+	out, err := setRefreshRate(input)
+	if err != nil {
+		return nil, SetRefreshRateOutput{}, fmt.Errorf("❌ Error al cambiar la frecuencia de refresco: %w", err)
+	}
+
+	text := fmt.Sprintf("🖥️ Frecuencia de %s cambiada de %g Hz a %g Hz (%s)", out.Display, out.PreviousHz, out.Hz, out.Resolution)
+	if !out.Changed {
+		text = fmt.Sprintf("🖥️ %s ya estaba a %g Hz (%s)", out.Display, out.Hz, out.Resolution)
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: text},
+		},
+	}, out, nil
+}
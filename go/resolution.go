@@ -0,0 +1,728 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"os/exec"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// resolutionRevertDelay es el tiempo que hay para confirmar una resolución
+// con confirm_resolution antes de que se restaure la anterior, como hacen
+// los propios sistemas: si el monitor no admite el modo y se queda en
+// negro, el usuario no puede confirmarlo
+const resolutionRevertDelay = 15 * time.Second
+
+// refreshRateTolerance es el margen al comparar frecuencias, ya que cada
+// sistema las redondea a su manera (59.94, 59.95 o 60)
+const refreshRateTolerance = 0.5
+
+// Límites del esquema de set_resolution
+const (
+	maxResolutionSize = 16384
+	maxRefreshRate    = 1000
+)
+
+type SetResolutionInput struct {
+	Width       int     `json:"width" jsonschema:"Resolución horizontal en píxeles"`
+	Height      int     `json:"height" jsonschema:"Resolución vertical en píxeles"`
+	RefreshRate float64 `json:"refresh_rate,omitempty" jsonschema:"Frecuencia de refresco en Hz (por defecto la actual si la resolución la admite, o la más alta)"`
+	Display     string  `json:"display,omitempty" jsonschema:"Índice o id de la pantalla según list_displays (por defecto la principal)"`
+}
+
+type SetResolutionOutput struct {
+	Display       string      `json:"display" jsonschema:"Id de la pantalla"`
+	Mode          DisplayMode `json:"mode" jsonschema:"Modo aplicado"`
+	Previous      DisplayMode `json:"previous" jsonschema:"Modo anterior, que se restaura si no se confirma"`
+	Changed       bool        `json:"changed" jsonschema:"Se cambió el modo (false si ya era el pedido)"`
+	RevertSeconds int         `json:"revert_in_seconds,omitempty" jsonschema:"Segundos que quedan para llamar a confirm_resolution antes de que se restaure el modo anterior"`
+	Method        string      `json:"method,omitempty" jsonschema:"Herramienta usada (xrandr, wlr-randr, displayplacer o ChangeDisplaySettingsEx)"`
+}
+
+type ConfirmResolutionOutput struct {
+	Display  string      `json:"display" jsonschema:"Id de la pantalla"`
+	Mode     DisplayMode `json:"mode" jsonschema:"Modo que se mantiene"`
+	Previous DisplayMode `json:"previous" jsonschema:"Modo que había antes del cambio"`
+}
+
+// String escribe el modo como "1920x1080 a 60 Hz"
+func (m DisplayMode) String() string {
+	if m.RefreshRate == 0 {
+		return fmt.Sprintf("%dx%d", m.Width, m.Height)
+	}
+	return fmt.Sprintf("%dx%d a %g Hz", m.Width, m.Height, round2(m.RefreshRate))
+}
+
+// sameDisplayMode indica si dos modos tienen la misma resolución y una
+// frecuencia equivalente. Una frecuencia desconocida (cero) vale por
+// cualquiera.
+func sameDisplayMode(a, b DisplayMode) bool {
+	if a.Width != b.Width || a.Height != b.Height {
+		return false
+	}
+	return a.RefreshRate == 0 || b.RefreshRate == 0 || math.Abs(a.RefreshRate-b.RefreshRate) < refreshRateTolerance
+}
+
+// resolutionTarget es la pantalla cuya resolución o giro se cambia, con los
+// modos que admite y la forma de aplicarlos en esta plataforma
+type resolutionTarget struct {
+	id          string
+	current     DisplayMode
+	modes       []DisplayMode
+	orientation string
+	method      string
+	apply       func(DisplayMode) error
+	rotate      func(orientation string) error
+	// persist guarda el modo actual para que se mantenga al reiniciar; nil
+	// donde los cambios ya se guardan al aplicarlos
+	persist func() error
+}
+
+// selectDisplay elige la pantalla a la que se refiere display: un índice o
+// un id de list_displays. Sin display se elige la principal o, si no hay, el
+// panel interno; con varias pantallas y ninguna de las dos hay que indicarla.
+func selectDisplay(displays []DisplayInfo, display string) (DisplayInfo, error) {
+	if len(displays) == 0 {
+		return DisplayInfo{}, errors.New("no se encontraron pantallas conectadas")
+	}
+	ids := make([]string, len(displays))
+	for i, d := range displays {
+		ids[i] = d.ID
+	}
+
+	if display == "" {
+		for _, d := range displays {
+			if d.Primary {
+				return d, nil
+			}
+		}
+		for _, d := range displays {
+			if d.Internal {
+				return d, nil
+			}
+		}
+		if len(displays) == 1 {
+			return displays[0], nil
+		}
+		return DisplayInfo{}, fmt.Errorf("ninguna pantalla está marcada como principal: indica display (conectadas: %s)", strings.Join(ids, ", "))
+	}
+	if index, err := strconv.Atoi(display); err == nil {
+		if index < 0 || index >= len(displays) {
+			return DisplayInfo{}, fmt.Errorf("índice de display %d fuera de rango (hay %d conectadas: %s)", index, len(displays), strings.Join(ids, ", "))
+		}
+		return displays[index], nil
+	}
+	for _, d := range displays {
+		if strings.EqualFold(d.ID, strings.TrimPrefix(display, `\\.\`)) {
+			return d, nil
+		}
+	}
+	return DisplayInfo{}, fmt.Errorf("no se encontró la pantalla %q (conectadas: %s)", display, strings.Join(ids, ", "))
+}
+
+// currentDisplayMode devuelve el modo actual de d tal como aparece en su
+// lista de modos, que es lo que se necesita para restaurarlo (xrandr lo
+// aplica por nombre). Si no aparece se usa el que informa la pantalla.
+func currentDisplayMode(d DisplayInfo, modes []DisplayMode) DisplayMode {
+	current := DisplayMode{Width: d.Width, Height: d.Height}
+	if d.RefreshRate != nil {
+		current.RefreshRate = *d.RefreshRate
+	}
+	// Girada a un lado, xrandr da el tamaño del escritorio, con el ancho y el
+	// alto intercambiados respecto al modo
+	rotated := DisplayMode{Width: current.Height, Height: current.Width, RefreshRate: current.RefreshRate}
+	for _, want := range []DisplayMode{current, rotated} {
+		for _, m := range modes {
+			if m.Width == want.Width && m.Height == want.Height && math.Abs(m.RefreshRate-want.RefreshRate) < 0.01 {
+				return m
+			}
+		}
+		for _, m := range modes {
+			if sameDisplayMode(m, want) {
+				return m
+			}
+		}
+	}
+	return current
+}
+
+// displayTarget construye el destino a partir de la enumeración de
+// list_displays, que ya trae los modos y el giro de cada pantalla
+func displayTarget(displays []DisplayInfo, display, method string, apply func(id string, mode DisplayMode) error, rotate func(id, orientation string) error, persist func(id string) error) (resolutionTarget, error) {
+	d, err := selectDisplay(displays, display)
+	if err != nil {
+		return resolutionTarget{}, err
+	}
+	if len(d.modes) == 0 {
+		return resolutionTarget{}, fmt.Errorf("no se pudieron obtener los modos que admite la pantalla %s", d.ID)
+	}
+	target := resolutionTarget{
+		id:          d.ID,
+		current:     currentDisplayMode(d, d.modes),
+		modes:       d.modes,
+		orientation: d.orientation,
+		method:      method,
+		apply: func(mode DisplayMode) error {
+			return apply(d.ID, mode)
+		},
+		rotate: func(orientation string) error {
+			return rotate(d.ID, orientation)
+		},
+	}
+	if persist != nil {
+		target.persist = func() error {
+			return persist(d.ID)
+		}
+	}
+	return target, nil
+}
+
+// resolveResolutionTarget localiza la pantalla y la herramienta con la que
+// cambiar su resolución o su giro en esta plataforma
+func resolveResolutionTarget(display string) (resolutionTarget, error) {
+	switch currentPlatform() {
+	case platformWindows, platformWSL:
+		displays, err := listDisplaysWindows()
+		if err != nil {
+			return resolutionTarget{}, err
+		}
+		return displayTarget(displays, display, "ChangeDisplaySettingsEx", setWindowsResolution, rotateWindowsDisplay, persistWindowsDisplay)
+	case platformMac:
+		return macResolutionTarget(display)
+	}
+
+	if err := checkGraphicalSession(); err != nil {
+		return resolutionTarget{}, err
+	}
+	if session := detectLinuxSession(); session.Wayland {
+		// Mutter y KWin no aceptan cambios de modo de herramientas externas
+		// sin pasar por su propia configuración; wlr-randr sí sirve en los
+		// compositores wlroots
+		if !commandExists("wlr-randr")() {
+			return resolutionTarget{}, fmt.Errorf("en Wayland (compositor: %s) no se puede cambiar la resolución ni el giro de la pantalla sin wlr-randr, que solo funciona en compositores wlroots como sway o Hyprland; en GNOME o KDE cámbialos en la configuración de pantalla", session.Compositor())
+		}
+		displays, err := listDisplaysWlrRandr()
+		if err != nil {
+			return resolutionTarget{}, fmt.Errorf("%w (wlr-randr solo funciona en compositores wlroots como sway o Hyprland)", err)
+		}
+		return displayTarget(displays, display, "wlr-randr", setWlrRandrResolution, rotateWlrRandrOutput, nil)
+	}
+	if !commandExists("xrandr")() {
+		return resolutionTarget{}, errors.New("se necesita xrandr para cambiar la resolución o el giro de la pantalla en X11")
+	}
+	invalidateXrandrCache()
+	displays, err := listDisplaysLinux()
+	if err != nil {
+		return resolutionTarget{}, err
+	}
+	return displayTarget(displays, display, "xrandr", setXrandrResolution, rotateXrandrOutput, nil)
+}
+
+// setXrandrResolution aplica el modo por su nombre de xrandr ("1920x1080")
+func setXrandrResolution(output string, mode DisplayMode) error {
+	name := mode.name
+	if name == "" {
+		name = fmt.Sprintf("%dx%d", mode.Width, mode.Height)
+	}
+	args := []string{"--output", output, "--mode", name}
+	if mode.RefreshRate > 0 {
+		args = append(args, "--rate", strconv.FormatFloat(mode.RefreshRate, 'f', 2, 64))
+	}
+	_, err := runSystemCommand("xrandr", args...)
+	invalidateXrandrCache()
+	return err
+}
+
+// setWlrRandrResolution aplica el modo con `wlr-randr --mode 1920x1080@60Hz`
+func setWlrRandrResolution(output string, mode DisplayMode) error {
+	value := fmt.Sprintf("%dx%d", mode.Width, mode.Height)
+	if mode.RefreshRate > 0 {
+		value += "@" + strconv.FormatFloat(mode.RefreshRate, 'f', 3, 64) + "Hz"
+	}
+	_, err := runSystemCommand("wlr-randr", "--output", output, "--mode", value)
+	return err
+}
+
+// psDisplaySettings define DisplaySettings, que cambia el modo o el giro de
+// un dispositivo GDI con ChangeDisplaySettingsEx. Parte del modo actual para
+// conservar la profundidad de color y la posición, y prueba cada cambio con
+// CDS_TEST antes de aplicarlo solo a la sesión, sin tocar el registro: un
+// modo que deja la pantalla en negro no debe sobrevivir a un reinicio.
+// Persist guarda en el registro el modo actual una vez comprobado. Los
+// métodos devuelven el código DISP_CHANGE.
+const psDisplaySettings = `Add-Type -TypeDefinition @'
+using System;
+using System.Runtime.InteropServices;
+public class DisplaySettings {
+	[StructLayout(LayoutKind.Sequential, CharSet = CharSet.Unicode)]
+	public struct DEVMODE {
+		[MarshalAs(UnmanagedType.ByValTStr, SizeConst = 32)] public string dmDeviceName;
+		public short dmSpecVersion, dmDriverVersion, dmSize, dmDriverExtra;
+		public int dmFields, dmPositionX, dmPositionY, dmDisplayOrientation, dmDisplayFixedOutput;
+		public short dmColor, dmDuplex, dmYResolution, dmTTOption, dmCollate;
+		[MarshalAs(UnmanagedType.ByValTStr, SizeConst = 32)] public string dmFormName;
+		public short dmLogPixels;
+		public int dmBitsPerPel, dmPelsWidth, dmPelsHeight, dmDisplayFlags, dmDisplayFrequency;
+		public int dmICMMethod, dmICMIntent, dmMediaType, dmDitherType, dmReserved1, dmReserved2, dmPanningWidth, dmPanningHeight;
+	}
+	[DllImport("user32.dll", CharSet = CharSet.Unicode)] static extern bool EnumDisplaySettings(string device, int mode, ref DEVMODE info);
+	[DllImport("user32.dll", CharSet = CharSet.Unicode)] static extern int ChangeDisplaySettingsEx(string device, ref DEVMODE mode, IntPtr hwnd, uint flags, IntPtr param);
+	static bool Current(string device, ref DEVMODE mode) {
+		mode.dmSize = (short)Marshal.SizeOf(mode);
+		// -1 = ENUM_CURRENT_SETTINGS
+		return EnumDisplaySettings(device, -1, ref mode);
+	}
+	static int Apply(string device, ref DEVMODE mode) {
+		// CDS_TEST y después el cambio dinámico, sin CDS_UPDATEREGISTRY
+		int result = ChangeDisplaySettingsEx(device, ref mode, IntPtr.Zero, 2, IntPtr.Zero);
+		if (result != 0) { return result; }
+		return ChangeDisplaySettingsEx(device, ref mode, IntPtr.Zero, 0, IntPtr.Zero);
+	}
+	public static int Set(string device, int width, int height, int frequency) {
+		var mode = new DEVMODE();
+		if (!Current(device, ref mode)) { return -5; }
+		mode.dmPelsWidth = width;
+		mode.dmPelsHeight = height;
+		// DM_PELSWIDTH | DM_PELSHEIGHT, más DM_DISPLAYFREQUENCY si se indica
+		mode.dmFields = 0x80000 | 0x100000;
+		if (frequency > 0) {
+			mode.dmDisplayFrequency = frequency;
+			mode.dmFields |= 0x400000;
+		}
+		return Apply(device, ref mode);
+	}
+	public static int Rotate(string device, int orientation) {
+		var mode = new DEVMODE();
+		if (!Current(device, ref mode)) { return -5; }
+		// Al pasar de horizontal a vertical o al revés el modo cambia de
+		// ancho y alto; sin intercambiarlos el cambio se rechaza
+		if ((mode.dmDisplayOrientation + orientation) % 2 == 1) {
+			int width = mode.dmPelsWidth;
+			mode.dmPelsWidth = mode.dmPelsHeight;
+			mode.dmPelsHeight = width;
+		}
+		mode.dmDisplayOrientation = orientation;
+		// DM_DISPLAYORIENTATION | DM_PELSWIDTH | DM_PELSHEIGHT
+		mode.dmFields = 0x80 | 0x80000 | 0x100000;
+		return Apply(device, ref mode);
+	}
+	public static int Persist(string device) {
+		var mode = new DEVMODE();
+		if (!Current(device, ref mode)) { return -5; }
+		// DM_DISPLAYORIENTATION | DM_PELSWIDTH | DM_PELSHEIGHT | DM_DISPLAYFREQUENCY
+		mode.dmFields = 0x80 | 0x80000 | 0x100000 | 0x400000;
+		// CDS_UPDATEREGISTRY | CDS_NORESET: solo se escribe en el registro
+		return ChangeDisplaySettingsEx(device, ref mode, IntPtr.Zero, 0x10000001, IntPtr.Zero);
+	}
+}
+'@
+`
+
+// windowsDispChangeErrors traduce los códigos DISP_CHANGE de
+// ChangeDisplaySettingsEx
+var windowsDispChangeErrors = map[string]string{
+	"1":  "el cambio requiere reiniciar el equipo",
+	"-1": "el controlador rechazó el modo",
+	"-2": "el modo no está admitido",
+	"-3": "no se pudo guardar la configuración en el registro",
+	"-4": "opciones no válidas",
+	"-5": "parámetros no válidos",
+	"-6": "el modo no es compatible con la configuración DualView",
+}
+
+// runWindowsDisplaySettings ejecuta una llamada a DisplaySettings y traduce
+// el código que devuelve
+func runWindowsDisplaySettings(call string) error {
+	output, err := runPowerShell(psDisplaySettings + call)
+	if err != nil {
+		return err
+	}
+	code := strings.TrimSpace(output)
+	if code == "0" {
+		return nil
+	}
+	if reason, ok := windowsDispChangeErrors[code]; ok {
+		return fmt.Errorf("ChangeDisplaySettingsEx: %s (código %s)", reason, code)
+	}
+	return fmt.Errorf("ChangeDisplaySettingsEx devolvió %q", code)
+}
+
+// setWindowsResolution aplica el modo a un dispositivo GDI ("DISPLAY1")
+func setWindowsResolution(device string, mode DisplayMode) error {
+	return runWindowsDisplaySettings(fmt.Sprintf(`[DisplaySettings]::Set('\\.\%s', %d, %d, %d)`,
+		strings.ReplaceAll(device, "'", "''"), mode.Width, mode.Height, int(math.Round(mode.RefreshRate))))
+}
+
+// persistWindowsDisplay guarda en el registro el modo y el giro actuales de
+// un dispositivo GDI, para que se mantengan al reiniciar
+func persistWindowsDisplay(device string) error {
+	return runWindowsDisplaySettings(fmt.Sprintf(`[DisplaySettings]::Persist('\\.\%s')`, strings.ReplaceAll(device, "'", "''")))
+}
+
+var (
+	// Cada pantalla de `displayplacer list` empieza con su id persistente
+	displayplacerScreenRegexp = regexp.MustCompile(`(?m)^Persistent screen id: (\S+)`)
+	// "Contextual screen id: 2": el ID de CoreGraphics, en decimal
+	displayplacerContextualRegexp = regexp.MustCompile(`(?m)^Contextual screen id: (\d+)`)
+	// "  mode 12: res:1920x1080 hz:60 color_depth:8 scaling:on <-- current mode"
+	displayplacerModeRegexp = regexp.MustCompile(`(?m)^\s*mode (\d+): res:(\d+)x(\d+)(?: hz:(\d+))?([^\n]*)$`)
+	// "Rotation: 90": el giro en grados, en el sentido de las agujas del reloj
+	displayplacerRotationRegexp = regexp.MustCompile(`(?m)^Rotation: (\d+)`)
+)
+
+// macResolutionTarget obtiene los modos de la pantalla con `displayplacer
+// list`, ya que system_profiler solo informa del actual. Cuando una
+// resolución aparece con y sin escalado se ofrece la escalada (HiDPI), que
+// es la que muestran los Ajustes del Sistema.
+func macResolutionTarget(display string) (resolutionTarget, error) {
+	if !commandExists("displayplacer")() {
+		return resolutionTarget{}, errors.New("en macOS se necesita displayplacer para cambiar la resolución: instálalo con `brew install displayplacer`")
+	}
+	displays, err := listDisplaysMac()
+	if err != nil {
+		return resolutionTarget{}, err
+	}
+	d, err := selectDisplay(displays, display)
+	if err != nil {
+		return resolutionTarget{}, err
+	}
+	id, err := strconv.ParseUint(strings.TrimPrefix(strings.ToLower(d.ID), "0x"), 16, 32)
+	if err != nil {
+		return resolutionTarget{}, fmt.Errorf("id de pantalla no válido %q", d.ID)
+	}
+	output, err := exec.Command("displayplacer", "list").Output()
+	if err != nil {
+		return resolutionTarget{}, fmt.Errorf("error al ejecutar displayplacer list: %w", err)
+	}
+
+	text := string(output)
+	starts := displayplacerScreenRegexp.FindAllStringSubmatchIndex(text, -1)
+	for i, start := range starts {
+		end := len(text)
+		if i+1 < len(starts) {
+			end = starts[i+1][0]
+		}
+		block := text[start[0]:end]
+		contextual := displayplacerContextualRegexp.FindStringSubmatch(block)
+		if contextual == nil {
+			continue
+		}
+		if value, _ := strconv.ParseUint(contextual[1], 10, 32); value != id {
+			continue
+		}
+
+		persistent := text[start[2]:start[3]]
+		target := resolutionTarget{id: d.ID, method: "displayplacer"}
+		if rotation := displayplacerRotationRegexp.FindStringSubmatch(block); rotation != nil {
+			target.orientation = macRotationOrientations[rotation[1]]
+		}
+		scaled := map[int]bool{}
+		for _, m := range displayplacerModeRegexp.FindAllStringSubmatch(block, -1) {
+			mode := DisplayMode{name: m[1]}
+			mode.Width, _ = strconv.Atoi(m[2])
+			mode.Height, _ = strconv.Atoi(m[3])
+			mode.RefreshRate, _ = strconv.ParseFloat(m[4], 64)
+			if strings.Contains(m[5], "current mode") {
+				target.current = mode
+			}
+			isScaled := strings.Contains(m[5], "scaling:on")
+			duplicate := slices.IndexFunc(target.modes, func(o DisplayMode) bool {
+				return o.Width == mode.Width && o.Height == mode.Height && o.RefreshRate == mode.RefreshRate
+			})
+			if duplicate >= 0 {
+				if isScaled && !scaled[duplicate] {
+					target.modes[duplicate], scaled[duplicate] = mode, true
+				}
+				continue
+			}
+			scaled[len(target.modes)] = isScaled
+			target.modes = append(target.modes, mode)
+		}
+		if len(target.modes) == 0 || target.current.Width == 0 {
+			return resolutionTarget{}, fmt.Errorf("displayplacer no informa de los modos de la pantalla %s", d.ID)
+		}
+		target.apply = func(mode DisplayMode) error {
+			_, err := runSystemCommand("displayplacer", fmt.Sprintf("id:%s mode:%s", persistent, mode.name))
+			return err
+		}
+		target.rotate = func(orientation string) error {
+			_, err := runSystemCommand("displayplacer", fmt.Sprintf("id:%s degree:%s", persistent, macOrientationRotations[orientation]))
+			return err
+		}
+		return target, nil
+	}
+	return resolutionTarget{}, fmt.Errorf("displayplacer no muestra la pantalla %s", d.ID)
+}
+
+// chooseDisplayMode busca el modo pedido entre los que admite la pantalla.
+// Sin frecuencia se mantiene la actual si la resolución la admite y si no
+// se elige la más alta.
+func chooseDisplayMode(target resolutionTarget, width, height int, rate float64) (DisplayMode, error) {
+	var candidates []DisplayMode
+	for _, m := range target.modes {
+		if m.Width == width && m.Height == height {
+			candidates = append(candidates, m)
+		}
+	}
+	if len(candidates) == 0 {
+		var sizes []string
+		for _, m := range target.modes {
+			size := fmt.Sprintf("%dx%d", m.Width, m.Height)
+			if !slices.Contains(sizes, size) {
+				sizes = append(sizes, size)
+			}
+		}
+		return DisplayMode{}, fmt.Errorf("la pantalla %s no admite %dx%d (resoluciones disponibles: %s)", target.id, width, height, strings.Join(sizes, ", "))
+	}
+
+	want := rate
+	if want == 0 {
+		want = target.current.RefreshRate
+	}
+	best := -1
+	for i, m := range candidates {
+		if math.Abs(m.RefreshRate-want) < refreshRateTolerance && (best < 0 || math.Abs(m.RefreshRate-want) < math.Abs(candidates[best].RefreshRate-want)) {
+			best = i
+		}
+	}
+	if best >= 0 {
+		return candidates[best], nil
+	}
+	if rate > 0 {
+		rates := make([]string, len(candidates))
+		for i, m := range candidates {
+			rates[i] = fmt.Sprintf("%g", round2(m.RefreshRate))
+		}
+		return DisplayMode{}, fmt.Errorf("la pantalla %s no admite %dx%d a %g Hz (frecuencias disponibles: %s Hz)", target.id, width, height, rate, strings.Join(rates, ", "))
+	}
+	return slices.MaxFunc(candidates, func(a, b DisplayMode) int {
+		return int(math.Round(a.RefreshRate*100) - math.Round(b.RefreshRate*100))
+	}), nil
+}
+
+// pendingResolutionChange es un cambio de resolución que se deshace si no
+// se confirma antes de Due
+type pendingResolutionChange struct {
+	Display        string
+	Mode, Previous DisplayMode
+	Due            time.Time
+	target         resolutionTarget
+	cancel         context.CancelFunc
+}
+
+// resolutionMu protege el cambio pendiente y el resultado de la última
+// restauración automática, que solo queda en el log y en lastResolutionRevert
+var (
+	resolutionMu         sync.Mutex
+	pendingResolution    *pendingResolutionChange
+	lastResolutionRevert string
+)
+
+// waitForDisplay vuelve a leer la pantalla hasta que done se cumple o pasan
+// 3 segundos, ya que algunos sistemas aplican los cambios de forma
+// asíncrona. Devuelve la última lectura y si done llegó a cumplirse.
+func waitForDisplay(id string, done func(resolutionTarget) bool) (resolutionTarget, bool, error) {
+	deadline := time.Now().Add(3 * time.Second)
+	for {
+		target, err := resolveResolutionTarget(id)
+		if err == nil && done(target) {
+			return target, true, nil
+		}
+		if time.Now().After(deadline) {
+			return target, false, err
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+}
+
+// checkPendingResolutionLocked impide cambiar el modo de una pantalla
+// mientras hay un cambio de resolución sin confirmar, cuya restauración lo
+// desharía. Requiere tener resolutionMu.
+func checkPendingResolutionLocked() error {
+	if p := pendingResolution; p != nil {
+		return fmt.Errorf("hay un cambio de resolución sin confirmar en %s (%s; se deshace en %v): confírmalo con confirm_resolution o espera a que se restaure", p.Display, p.Mode, time.Until(p.Due).Round(time.Second))
+	}
+	return nil
+}
+
+// applyDisplayMode aplica mode a la pantalla y comprueba que se refleja. Si
+// no se refleja puede haber dejado la pantalla en un estado intermedio, así
+// que se restaura el modo actual en el momento.
+func applyDisplayMode(target resolutionTarget, mode DisplayMode) error {
+	if err := target.apply(mode); err != nil {
+		return fmt.Errorf("error al aplicar %s con %s: %w", mode, target.method, err)
+	}
+	now, ok, err := waitForDisplay(target.id, func(t resolutionTarget) bool {
+		return sameDisplayMode(t.current, mode)
+	})
+	if err == nil && !ok {
+		err = fmt.Errorf("la pantalla sigue en %s", now.current)
+	}
+	if err != nil {
+		if revertErr := target.apply(target.current); revertErr != nil {
+			return fmt.Errorf("no se pudo comprobar el cambio a %s (%v) ni restaurar %s: %w", mode, err, target.current, revertErr)
+		}
+		return fmt.Errorf("no se pudo comprobar el cambio a %s (%v); se restauró %s", mode, err, target.current)
+	}
+	return nil
+}
+
+// persistDisplayMode guarda el modo actual de la pantalla en los sistemas
+// donde los cambios solo duran la sesión hasta guardarlos
+func persistDisplayMode(target resolutionTarget) error {
+	if target.persist == nil {
+		return nil
+	}
+	if err := target.persist(); err != nil {
+		return fmt.Errorf("el cambio se aplicó, pero no se pudo guardar para que se mantenga al reiniciar: %w", err)
+	}
+	return nil
+}
+
+// setResolution aplica el modo pedido y programa la restauración del
+// anterior al cabo de resolutionRevertDelay, salvo que llegue antes
+// confirm_resolution. Solo puede haber un cambio sin confirmar a la vez.
+func setResolution(input SetResolutionInput) (SetResolutionOutput, error) {
+	if input.Width <= 0 || input.Height <= 0 {
+		return SetResolutionOutput{}, errors.New("indica el ancho y el alto en píxeles (width y height)")
+	}
+	if input.RefreshRate < 0 {
+		return SetResolutionOutput{}, errors.New("la frecuencia de refresco no puede ser negativa")
+	}
+
+	resolutionMu.Lock()
+	defer resolutionMu.Unlock()
+	if err := checkPendingResolutionLocked(); err != nil {
+		return SetResolutionOutput{}, err
+	}
+
+	target, err := resolveResolutionTarget(strings.TrimSpace(input.Display))
+	if err != nil {
+		return SetResolutionOutput{}, err
+	}
+	mode, err := chooseDisplayMode(target, input.Width, input.Height, input.RefreshRate)
+	if err != nil {
+		return SetResolutionOutput{}, err
+	}
+	out := SetResolutionOutput{Display: target.id, Mode: mode, Previous: target.current, Method: target.method}
+	if mode == target.current {
+		return out, nil
+	}
+
+	if err := applyDisplayMode(target, mode); err != nil {
+		return SetResolutionOutput{}, err
+	}
+	out.Changed = true
+
+	ctx, cancel := context.WithCancel(context.Background())
+	change := &pendingResolutionChange{Display: target.id, Mode: mode, Previous: target.current, Due: time.Now().Add(resolutionRevertDelay), target: target, cancel: cancel}
+	pendingResolution = change
+	go func() {
+		timer := time.NewTimer(resolutionRevertDelay)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+		resolutionMu.Lock()
+		defer resolutionMu.Unlock()
+		if pendingResolution != change {
+			// Se confirmó justo cuando vencía el plazo
+			return
+		}
+		revertPendingResolutionLocked("no se confirmó")
+	}()
+	out.RevertSeconds = int(resolutionRevertDelay.Seconds())
+	return out, nil
+}
+
+// revertPendingResolutionLocked restaura el modo anterior al cambio
+// pendiente y deja el resultado en el log y en lastResolutionRevert.
+// Requiere tener resolutionMu.
+func revertPendingResolutionLocked(reason string) {
+	change := pendingResolution
+	if change == nil {
+		return
+	}
+	change.cancel()
+	pendingResolution = nil
+
+	result := fmt.Sprintf("↩️ Resolución de %s restaurada a %s: %s %s", change.Display, change.Previous, reason, change.Mode)
+	if err := change.target.apply(change.Previous); err != nil {
+		result = fmt.Sprintf("❌ No se pudo restaurar la resolución de %s a %s: %v", change.Display, change.Previous, err)
+	}
+	log.Print(result)
+	lastResolutionRevert = fmt.Sprintf("%s (%s)", result, time.Now().Format("15:04:05"))
+}
+
+// revertPendingResolution restaura el cambio de resolución sin confirmar al
+// cerrar el servidor, ya que después nadie podría confirmarlo ni deshacerlo
+func revertPendingResolution() {
+	resolutionMu.Lock()
+	defer resolutionMu.Unlock()
+	revertPendingResolutionLocked("el servidor terminó sin confirmar")
+}
+
+// confirmResolution mantiene el cambio de resolución pendiente y, donde
+// hace falta, lo guarda para que se mantenga al reiniciar
+func confirmResolution() (ConfirmResolutionOutput, error) {
+	resolutionMu.Lock()
+	defer resolutionMu.Unlock()
+	change := pendingResolution
+	if change == nil {
+		if lastResolutionRevert != "" {
+			return ConfirmResolutionOutput{}, fmt.Errorf("no hay ningún cambio de resolución pendiente; el último no se confirmó a tiempo: %s", lastResolutionRevert)
+		}
+		return ConfirmResolutionOutput{}, errors.New("no hay ningún cambio de resolución pendiente de confirmar")
+	}
+	change.cancel()
+	pendingResolution = nil
+	if err := persistDisplayMode(change.target); err != nil {
+		return ConfirmResolutionOutput{}, err
+	}
+	return ConfirmResolutionOutput{Display: change.Display, Mode: change.Mode, Previous: change.Previous}, nil
+}
+
+func HandleSetResolution(ctx context.Context, req *mcp.CallToolRequest, input SetResolutionInput) (*mcp.CallToolResult, SetResolutionOutput, error) {
+	out, err := setResolution(input)
+	if err != nil {
+		return nil, SetResolutionOutput{}, fmt.Errorf("❌ Error al cambiar la resolución: %w", err)
+	}
+
+	var lines []string
+	if out.Changed {
+		lines = append(lines,
+			fmt.Sprintf("🖥️ Resolución de %s cambiada a %s (antes %s)", out.Display, out.Mode, out.Previous),
+			fmt.Sprintf("⏳ Llama a confirm_resolution en los próximos %d segundos para mantenerla; si no, se restaurará %s", out.RevertSeconds, out.Previous))
+	} else {
+		lines = append(lines, fmt.Sprintf("🖥️ %s ya estaba a %s", out.Display, out.Mode))
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: strings.Join(lines, "\n")},
+		},
+	}, out, nil
+}
+
+func HandleConfirmResolution(ctx context.Context, req *mcp.CallToolRequest, input struct{}) (*mcp.CallToolResult, ConfirmResolutionOutput, error) {
+	out, err := confirmResolution()
+	if err != nil {
+		return nil, ConfirmResolutionOutput{}, fmt.Errorf("❌ Error al confirmar la resolución: %w", err)
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: fmt.Sprintf("✅ Resolución de %s confirmada: %s (antes %s)", out.Display, out.Mode, out.Previous)},
+		},
+	}, out, nil
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// displayOrientations son las orientaciones que acepta rotate_display, con
+// los nombres de xrandr
+var displayOrientations = []string{"normal", "left", "right", "inverted"}
+
+// windowsOrientations traduce dmDisplayOrientation (DMDO_DEFAULT, DMDO_90,
+// DMDO_180 y DMDO_270) a los nombres de xrandr. DMDO_90 es el modo
+// "Vertical" de Windows, con el contenido girado a la izquierda.
+var windowsOrientations = []string{"normal", "left", "inverted", "right"}
+
+// wlrTransformOrientations traduce el transform de wlr-randr, que gira en
+// sentido contrario a las agujas del reloj. Los transform volteados
+// ("flipped-90") no tienen equivalente y quedan como desconocidos.
+var wlrTransformOrientations = map[string]string{"normal": "normal", "90": "left", "180": "inverted", "270": "right"}
+
+// macRotationOrientations traduce el giro de displayplacer, en grados en el
+// sentido de las agujas del reloj
+var macRotationOrientations = map[string]string{"0": "normal", "90": "right", "180": "inverted", "270": "left"}
+
+// Los mismos giros en sentido contrario, para aplicarlos
+var (
+	wlrOrientationTransforms = map[string]string{"normal": "normal", "left": "90", "inverted": "180", "right": "270"}
+	macOrientationRotations  = map[string]string{"normal": "0", "right": "90", "inverted": "180", "left": "270"}
+)
+
+// orientationLabels describe cada orientación en los mensajes
+var orientationLabels = map[string]string{
+	"normal":   "horizontal",
+	"left":     "vertical, contenido girado a la izquierda",
+	"right":    "vertical, contenido girado a la derecha",
+	"inverted": "horizontal, cabeza abajo",
+}
+
+// describeOrientation escribe la orientación como "left (vertical, ...)"
+func describeOrientation(orientation string) string {
+	if orientation == "" {
+		return "desconocida"
+	}
+	return fmt.Sprintf("%s (%s)", orientation, orientationLabels[orientation])
+}
+
+// orientationHistorySize es el número de orientaciones anteriores que se
+// recuerdan por pantalla
+const orientationHistorySize = 5
+
+// orientationHistory guarda, por pantalla, la orientación previa a cada giro
+// para poder deshacerlo con orientation "restore". La protege resolutionMu,
+// como al resto de cambios de modo.
+var orientationHistory = map[string][]string{}
+
+// rotateXrandrOutput gira la salida con `xrandr --rotate`
+func rotateXrandrOutput(output, orientation string) error {
+	_, err := runSystemCommand("xrandr", "--output", output, "--rotate", orientation)
+	invalidateXrandrCache()
+	return err
+}
+
+// rotateWlrRandrOutput gira la salida con `wlr-randr --transform`
+func rotateWlrRandrOutput(output, orientation string) error {
+	_, err := runSystemCommand("wlr-randr", "--output", output, "--transform", wlrOrientationTransforms[orientation])
+	return err
+}
+
+// rotateWindowsDisplay gira un dispositivo GDI ("DISPLAY1")
+func rotateWindowsDisplay(device, orientation string) error {
+	return runWindowsDisplaySettings(fmt.Sprintf(`[DisplaySettings]::Rotate('\\.\%s', %d)`,
+		strings.ReplaceAll(device, "'", "''"), slices.Index(windowsOrientations, orientation)))
+}
+
+type RotateDisplayInput struct {
+	Orientation string `json:"orientation" jsonschema:"Orientación: normal, left, right, inverted, o restore para volver a la anterior al último giro"`
+	Display     string `json:"display,omitempty" jsonschema:"Índice o id de la pantalla según list_displays (por defecto la principal)"`
+}
+
+type RotateDisplayOutput struct {
+	Display     string `json:"display" jsonschema:"Id de la pantalla"`
+	Orientation string `json:"orientation" jsonschema:"Orientación aplicada (normal, left, right o inverted)"`
+	Previous    string `json:"previous,omitempty" jsonschema:"Orientación anterior, la que recupera restore"`
+	Changed     bool   `json:"changed" jsonschema:"Se giró la pantalla (false si ya tenía esa orientación)"`
+	Restored    bool   `json:"restored,omitempty" jsonschema:"Se volvió a la orientación anterior con restore"`
+	Method      string `json:"method,omitempty" jsonschema:"Herramienta usada (xrandr, wlr-randr, displayplacer o ChangeDisplaySettingsEx)"`
+}
+
+// rotateDisplay gira la pantalla y recuerda la orientación anterior. Con
+// "restore" vuelve a la orientación previa al último giro de esa pantalla.
+func rotateDisplay(input RotateDisplayInput) (RotateDisplayOutput, error) {
+	orientation := strings.ToLower(strings.TrimSpace(input.Orientation))
+	restore := orientation == "restore"
+	if !restore && !slices.Contains(displayOrientations, orientation) {
+		return RotateDisplayOutput{}, fmt.Errorf("orientación no válida %q: usa normal, left, right, inverted o restore", input.Orientation)
+	}
+
+	resolutionMu.Lock()
+	defer resolutionMu.Unlock()
+	if err := checkPendingResolutionLocked(); err != nil {
+		return RotateDisplayOutput{}, err
+	}
+
+	target, err := resolveResolutionTarget(strings.TrimSpace(input.Display))
+	if err != nil {
+		return RotateDisplayOutput{}, err
+	}
+	key := strings.ToLower(target.id)
+	if restore {
+		history := orientationHistory[key]
+		if len(history) == 0 {
+			return RotateDisplayOutput{}, fmt.Errorf("no hay ninguna orientación anterior que restaurar para la pantalla %s", target.id)
+		}
+		orientation = history[len(history)-1]
+	}
+
+	out := RotateDisplayOutput{Display: target.id, Orientation: orientation, Previous: target.orientation, Restored: restore, Method: target.method}
+	if orientation == target.orientation {
+		if restore {
+			orientationHistory[key] = orientationHistory[key][:len(orientationHistory[key])-1]
+		}
+		return out, nil
+	}
+
+	if err := target.rotate(orientation); err != nil {
+		return RotateDisplayOutput{}, fmt.Errorf("error al girar la pantalla con %s: %w", target.method, err)
+	}
+	now, ok, err := waitForDisplay(target.id, func(t resolutionTarget) bool {
+		return t.orientation == orientation
+	})
+	if err == nil && !ok {
+		err = fmt.Errorf("la orientación sigue siendo %s", describeOrientation(now.orientation))
+	}
+	if err != nil {
+		// Igual que con la resolución, un giro a medias se deshace en el momento
+		if target.orientation != "" {
+			if revertErr := target.rotate(target.orientation); revertErr != nil {
+				return RotateDisplayOutput{}, fmt.Errorf("no se pudo comprobar el giro (%v) ni volver a %s: %w", err, target.orientation, revertErr)
+			}
+			return RotateDisplayOutput{}, fmt.Errorf("no se pudo comprobar el giro (%v); se volvió a %s", err, target.orientation)
+		}
+		return RotateDisplayOutput{}, fmt.Errorf("no se pudo comprobar el giro: %w", err)
+	}
+	out.Changed = true
+
+	if restore {
+		orientationHistory[key] = orientationHistory[key][:len(orientationHistory[key])-1]
+	} else if target.orientation != "" {
+		history := append(orientationHistory[key], target.orientation)
+		if len(history) > orientationHistorySize {
+			history = history[len(history)-orientationHistorySize:]
+		}
+		orientationHistory[key] = history
+	}
+	if err := persistDisplayMode(target); err != nil {
+		return RotateDisplayOutput{}, err
+	}
+	return out, nil
+}
+
+func HandleRotateDisplay(ctx context.Context, req *mcp.CallToolRequest, input RotateDisplayInput) (*mcp.CallToolResult, RotateDisplayOutput, error) {
+	out, err := rotateDisplay(input)
+	if err != nil {
+		return nil, RotateDisplayOutput{}, fmt.Errorf("❌ Error al girar la pantalla: %w", err)
+	}
+
+	var text string
+	switch {
+	case !out.Changed:
+		text = fmt.Sprintf("🖥️ %s ya tenía la orientación %s", out.Display, describeOrientation(out.Orientation))
+	case out.Restored:
+		text = fmt.Sprintf("↩️ Orientación de %s restaurada a %s; antes %s", out.Display, describeOrientation(out.Orientation), describeOrientation(out.Previous))
+	default:
+		text = fmt.Sprintf("🔄 Pantalla %s girada a %s; antes %s", out.Display, describeOrientation(out.Orientation), describeOrientation(out.Previous))
+		if out.Previous != "" {
+			text += "\nℹ️ Usa rotate_display con orientation \"restore\" para volver a la orientación anterior"
+		}
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: text},
+		},
+	}, out, nil
+}
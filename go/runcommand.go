@@ -0,0 +1,169 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// commandsFile es el fichero de configuración con la lista JSON de
+// ejecutables que run_command puede lanzar, por nombre o por ruta absoluta
+const commandsFile = "commands.json"
+
+// Límites de run_command
+const (
+	defaultCommandTimeout = 30 * time.Second
+	maxCommandTimeout     = 300 * time.Second
+	maxCommandOutput      = 64 * 1024
+)
+
+// RunCommandOutput es el resultado estructurado de run_command
+type RunCommandOutput struct {
+	ExitCode  int    `json:"exit_code" jsonschema:"Código de salida del programa (-1 si no terminó por sí mismo)"`
+	Stdout    string `json:"stdout" jsonschema:"Salida estándar, recortada si supera el límite"`
+	Stderr    string `json:"stderr" jsonschema:"Salida de error, recortada si supera el límite"`
+	Truncated bool   `json:"truncated" jsonschema:"Alguna de las salidas se recortó"`
+	TimedOut  bool   `json:"timed_out" jsonschema:"El programa se detuvo por superar el tiempo máximo"`
+}
+
+// limitedBuffer guarda como mucho maxCommandOutput bytes y descarta el resto
+type limitedBuffer struct {
+	data      []byte
+	truncated bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if room := maxCommandOutput - len(b.data); room < len(p) {
+		b.data = append(b.data, p[:max(room, 0)]...)
+		b.truncated = true
+	} else {
+		b.data = append(b.data, p...)
+	}
+	return len(p), nil
+}
+
+// resolveAllowedCommand comprueba que command esté en commandsFile y
+// devuelve la ruta del ejecutable. Se comparan las rutas resueltas, así que
+// permitir "ffmpeg" no permite otro ffmpeg en una ruta distinta.
+func resolveAllowedCommand(command string) (string, error) {
+	configFile, err := configPath(commandsFile)
+	if err != nil {
+		return "", err
+	}
+	var allowed []string
+	if err := loadJSONConfig(commandsFile, &allowed); err != nil {
+		return "", fmt.Errorf("error al leer %s: %w", configFile, err)
+	}
+
+	path, err := exec.LookPath(command)
+	if err != nil {
+		return "", fmt.Errorf("no se encontró el ejecutable '%s'", command)
+	}
+	if path, err = filepath.Abs(path); err != nil {
+		return "", err
+	}
+	for _, entry := range allowed {
+		entryPath, err := exec.LookPath(entry)
+		if err != nil {
+			continue
+		}
+		if entryPath, err = filepath.Abs(entryPath); err == nil && entryPath == path {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("'%s' no está permitido: añádelo a la lista de ejecutables de %s (ej: [\"%s\"])", command, configFile, filepath.Base(command))
+}
+
+// runCommand ejecuta un programa de la lista permitida con sus argumentos,
+// sin shell, y devuelve su código de salida y lo que escribió
+func runCommand(ctx context.Context, command string, args []string, workingDir string, timeout time.Duration) (RunCommandOutput, error) {
+	command = strings.TrimSpace(command)
+	if command == "" {
+		return RunCommandOutput{}, errors.New("indica el programa a ejecutar")
+	}
+	if timeout <= 0 {
+		timeout = defaultCommandTimeout
+	}
+	if timeout > maxCommandTimeout {
+		return RunCommandOutput{}, fmt.Errorf("tiempo máximo %s fuera de rango (máximo %d segundos)", timeout, int(maxCommandTimeout.Seconds()))
+	}
+	path, err := resolveAllowedCommand(command)
+	if err != nil {
+		return RunCommandOutput{}, err
+	}
+	if workingDir != "" {
+		workingDir = expandHome(workingDir)
+		if info, err := os.Stat(workingDir); err != nil || !info.IsDir() {
+			return RunCommandOutput{}, fmt.Errorf("directorio de trabajo no válido: %s", workingDir)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, path, args...)
+	cmd.Dir = workingDir
+	// Si el programa deja hijos con la salida abierta, no esperar por ellos
+	cmd.WaitDelay = 2 * time.Second
+	var stdout, stderr limitedBuffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err = cmd.Run()
+
+	result := RunCommandOutput{
+		ExitCode:  cmd.ProcessState.ExitCode(),
+		Stdout:    string(stdout.data),
+		Stderr:    string(stderr.data),
+		Truncated: stdout.truncated || stderr.truncated,
+		TimedOut:  errors.Is(ctx.Err(), context.DeadlineExceeded),
+	}
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		return RunCommandOutput{}, err
+	}
+	return result, nil
+}
+
+type RunCommandInput struct {
+	Command        string   `json:"command" jsonschema:"Programa a ejecutar; debe estar en la lista de commands.json de la configuración"`
+	Args           []string `json:"args,omitempty" jsonschema:"Argumentos, uno por elemento (no se interpretan con shell)"`
+	WorkingDir     string   `json:"working_dir,omitempty" jsonschema:"Directorio de trabajo (opcional)"`
+	TimeoutSeconds int      `json:"timeout_seconds,omitempty" jsonschema:"Tiempo máximo de ejecución en segundos (hasta 300, por defecto 30)"`
+}
+
+func HandleRunCommand(ctx context.Context, req *mcp.CallToolRequest, input RunCommandInput) (*mcp.CallToolResult, RunCommandOutput, error) {
+	output, err := runCommand(ctx, input.Command, input.Args, input.WorkingDir, time.Duration(input.TimeoutSeconds)*time.Second)
+	if err != nil {
+		return nil, RunCommandOutput{}, fmt.Errorf("❌ Error al ejecutar el programa: %w", err)
+	}
+	line := formatCommandLine(append([]string{input.Command}, input.Args...))
+	var result string
+	switch {
+	case output.TimedOut:
+		result = fmt.Sprintf("⏱️ %s superó el tiempo máximo y se detuvo", line)
+	case output.ExitCode == 0:
+		result = fmt.Sprintf("✅ %s terminó correctamente", line)
+	default:
+		result = fmt.Sprintf("⚠️ %s terminó con código %d", line, output.ExitCode)
+	}
+	if output.Stdout != "" {
+		result += "\n📤 stdout:\n" + output.Stdout
+	}
+	if output.Stderr != "" {
+		result += "\n📥 stderr:\n" + output.Stderr
+	}
+	if output.Truncated {
+		result += fmt.Sprintf("\n✂️ Salida recortada a %d KB", maxCommandOutput/1024)
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: result},
+		},
+	}, output, nil
+}
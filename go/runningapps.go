@@ -0,0 +1,231 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// RunningApp es un proceso en ejecución
+type RunningApp struct {
+	Name     string `json:"name" jsonschema:"Nombre del proceso"`
+	PID      int    `json:"pid" jsonschema:"Identificador del proceso"`
+	MemoryKB int64  `json:"memory_kb" jsonschema:"Memoria residente en KB"`
+}
+
+// ListRunningAppsOutput es la salida estructurada de list_running_apps
+type ListRunningAppsOutput struct {
+	Apps []RunningApp `json:"apps,omitempty" jsonschema:"Procesos en ejecución, ordenados por nombre"`
+}
+
+// listRunningApps devuelve los procesos en ejecución cuyo nombre contiene
+// filter (sin distinguir mayúsculas); con guiOnly, solo los que tienen ventana
+func listRunningApps(filter string, guiOnly bool) ([]RunningApp, error) {
+	var apps []RunningApp
+	var err error
+	switch currentPlatform() {
+	case platformWindows, platformWSL:
+		apps, err = runningAppsWindows(guiOnly)
+	case platformMac:
+		apps, err = runningAppsMac(guiOnly)
+	default:
+		apps, err = runningAppsLinux(guiOnly)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	filter = strings.ToLower(strings.TrimSpace(filter))
+	var result []RunningApp
+	for _, app := range apps {
+		if filter == "" || strings.Contains(strings.ToLower(app.Name), filter) {
+			result = append(result, app)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		a, b := strings.ToLower(result[i].Name), strings.ToLower(result[j].Name)
+		if a != b {
+			return a < b
+		}
+		return result[i].PID < result[j].PID
+	})
+	return result, nil
+}
+
+// runningAppsLinux recorre /proc. Los hilos del kernel no tienen VmRSS y no
+// se listan. Con guiOnly se quedan los procesos con ventana según wmctrl.
+func runningAppsLinux(guiOnly bool) ([]RunningApp, error) {
+	var windowPIDs map[int]bool
+	if guiOnly {
+		if !commandExists("wmctrl")() {
+			return nil, errors.New("gui_only necesita wmctrl en Linux (instala el paquete wmctrl)")
+		}
+		output, err := exec.Command("wmctrl", "-lp").Output()
+		if err != nil {
+			return nil, fmt.Errorf("error al ejecutar wmctrl -lp: %w", err)
+		}
+		// "0x03a00003  0 12345  host Título": la tercera columna es el pid
+		windowPIDs = make(map[int]bool)
+		for _, line := range strings.Split(string(output), "\n") {
+			if fields := strings.Fields(line); len(fields) >= 3 {
+				if pid, err := strconv.Atoi(fields[2]); err == nil && pid > 0 {
+					windowPIDs[pid] = true
+				}
+			}
+		}
+	}
+
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return nil, fmt.Errorf("error al leer /proc: %w", err)
+	}
+	var apps []RunningApp
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil || (guiOnly && !windowPIDs[pid]) {
+			continue
+		}
+		status, err := os.ReadFile(filepath.Join("/proc", entry.Name(), "status"))
+		if err != nil {
+			continue
+		}
+		app := RunningApp{PID: pid, MemoryKB: -1}
+		for _, line := range strings.Split(string(status), "\n") {
+			key, value, ok := strings.Cut(line, ":")
+			if !ok {
+				continue
+			}
+			switch key {
+			case "Name":
+				app.Name = strings.TrimSpace(value)
+			case "VmRSS":
+				// "VmRSS:	   12345 kB"
+				app.MemoryKB, _ = strconv.ParseInt(strings.TrimSuffix(strings.TrimSpace(value), " kB"), 10, 64)
+			}
+		}
+		if app.Name != "" && app.MemoryKB >= 0 {
+			apps = append(apps, app)
+		}
+	}
+	return apps, nil
+}
+
+// runningAppsMac usa ps; con guiOnly se quedan los procesos de las
+// aplicaciones con interfaz según System Events
+func runningAppsMac(guiOnly bool) ([]RunningApp, error) {
+	var guiPIDs map[int]bool
+	if guiOnly {
+		output, err := runAppleScript(`tell application "System Events" to get unix id of every application process whose background only is false`)
+		if err != nil {
+			return nil, fmt.Errorf("error al consultar System Events: %w", err)
+		}
+		guiPIDs = make(map[int]bool)
+		for _, field := range strings.Split(output, ",") {
+			if pid, err := strconv.Atoi(strings.TrimSpace(field)); err == nil {
+				guiPIDs[pid] = true
+			}
+		}
+	}
+
+	output, err := exec.Command("ps", "-axo", "pid=,rss=,comm=").Output()
+	if err != nil {
+		return nil, fmt.Errorf("error al ejecutar ps: %w", err)
+	}
+	var apps []RunningApp
+	for _, line := range strings.Split(string(output), "\n") {
+		// comm es la ruta del ejecutable y puede tener espacios
+		fields := strings.SplitN(strings.TrimSpace(line), " ", 2)
+		if len(fields) != 2 {
+			continue
+		}
+		pid, err := strconv.Atoi(fields[0])
+		if err != nil || (guiOnly && !guiPIDs[pid]) {
+			continue
+		}
+		rssField, comm, _ := strings.Cut(strings.TrimSpace(fields[1]), " ")
+		rss, _ := strconv.ParseInt(rssField, 10, 64)
+		apps = append(apps, RunningApp{Name: filepath.Base(strings.TrimSpace(comm)), PID: pid, MemoryKB: rss})
+	}
+	return apps, nil
+}
+
+// runningAppsWindows interpreta `tasklist /FO CSV /NH`:
+//
+//	"chrome.exe","1234","Console","1","123.456 K"
+//
+// Con guiOnly se filtran los procesos que tienen título de ventana.
+func runningAppsWindows(guiOnly bool) ([]RunningApp, error) {
+	name := "tasklist"
+	if isWSL() {
+		name = "tasklist.exe"
+	}
+	args := []string{"/FO", "CSV", "/NH"}
+	if guiOnly {
+		args = append(args, "/FI", "WINDOWTITLE ne N/A")
+	}
+	output, err := exec.Command(name, args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("error al ejecutar tasklist: %w", err)
+	}
+	records, err := csv.NewReader(strings.NewReader(string(output))).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("salida de tasklist no reconocida: %w", err)
+	}
+	var apps []RunningApp
+	for _, record := range records {
+		// Sin coincidencias tasklist escribe una sola línea "INFO: ..."
+		if len(record) < 5 {
+			continue
+		}
+		pid, err := strconv.Atoi(record[1])
+		if err != nil {
+			continue
+		}
+		// El separador de miles depende del idioma: solo se toman los dígitos
+		digits := strings.Map(func(r rune) rune {
+			if r >= '0' && r <= '9' {
+				return r
+			}
+			return -1
+		}, record[4])
+		memory, _ := strconv.ParseInt(digits, 10, 64)
+		apps = append(apps, RunningApp{Name: record[0], PID: pid, MemoryKB: memory})
+	}
+	return apps, nil
+}
+
+type ListRunningAppsInput struct {
+	Filter  string `json:"filter,omitempty" jsonschema:"Texto que debe contener el nombre del proceso, sin distinguir mayúsculas (opcional)"`
+	GUIOnly bool   `json:"gui_only,omitempty" jsonschema:"Si es true, solo aplicaciones con ventana (en Linux necesita wmctrl)"`
+}
+
+func HandleListRunningApps(ctx context.Context, req *mcp.CallToolRequest, input ListRunningAppsInput) (*mcp.CallToolResult, ListRunningAppsOutput, error) {
+	apps, err := listRunningApps(input.Filter, input.GUIOnly)
+	if err != nil {
+		return nil, ListRunningAppsOutput{}, fmt.Errorf("❌ Error al listar las aplicaciones: %w", err)
+	}
+	var result string
+	if len(apps) == 0 {
+		result = "📋 No hay ningún proceso en ejecución que coincida"
+	} else {
+		lines := []string{fmt.Sprintf("📋 %d procesos en ejecución:", len(apps))}
+		for _, app := range apps {
+			lines = append(lines, fmt.Sprintf("  - %s (pid %d, %.1f MB)", app.Name, app.PID, float64(app.MemoryKB)/1024))
+		}
+		result = strings.Join(lines, "\n")
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: result},
+		},
+	}, ListRunningAppsOutput{Apps: apps}, nil
+}
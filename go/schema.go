@@ -0,0 +1,26 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+// inputSchema infiere el esquema de entrada de T y añade los límites
+// [mínimo, máximo] indicados por propiedad, de forma que el SDK rechace los
+// valores fuera de rango antes de llamar al handler
+func inputSchema[T any](bounds map[string][2]float64) *jsonschema.Schema {
+	schema, err := jsonschema.For[T](nil)
+	if err != nil {
+		panic(fmt.Sprintf("esquema de entrada no válido para %T: %v", *new(T), err))
+	}
+	for name, b := range bounds {
+		prop, ok := schema.Properties[name]
+		if !ok {
+			panic(fmt.Sprintf("el esquema de %T no tiene la propiedad %q", *new(T), name))
+		}
+		prop.Minimum = jsonschema.Ptr(b[0])
+		prop.Maximum = jsonschema.Ptr(b[1])
+	}
+	return schema
+}
@@ -0,0 +1,45 @@
+package main
+
+import "testing"
+
+func TestInputSchemaBounds(t *testing.T) {
+	resolved, err := inputSchema[SetBrightnessInput](map[string][2]float64{"level": {0, 100}, "duration_ms": {0, 30000}}).Resolve(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name  string
+		input map[string]any
+		valid bool
+	}{
+		{"level omitido", map[string]any{"display": "eDP-1"}, false},
+		{"level -1", map[string]any{"level": -1}, false},
+		{"level 101", map[string]any{"level": 101}, false},
+		{"level 0", map[string]any{"level": 0}, true},
+		{"level 100", map[string]any{"level": 100}, true},
+		{"level 50 con display", map[string]any{"level": 50, "display": "all"}, true},
+		{"level decimal", map[string]any{"level": 50.5}, false},
+		{"level como texto", map[string]any{"level": "50"}, false},
+		{"duration_ms omitido", map[string]any{"level": 50}, true},
+		{"duration_ms -1", map[string]any{"level": 50, "duration_ms": -1}, false},
+		{"duration_ms 30001", map[string]any{"level": 50, "duration_ms": 30001}, false},
+	}
+	for _, tc := range tests {
+		err := resolved.Validate(tc.input)
+		if tc.valid && err != nil {
+			t.Errorf("%s: Validate(%v) = %v; se esperaba válido", tc.name, tc.input, err)
+		}
+		if !tc.valid && err == nil {
+			t.Errorf("%s: Validate(%v) = nil; se esperaba un error", tc.name, tc.input)
+		}
+	}
+}
+
+func TestInputSchemaUnknownProperty(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("inputSchema con una propiedad inexistente no entró en pánico")
+		}
+	}()
+	inputSchema[SetBrightnessInput](map[string][2]float64{"nivel": {0, 100}})
+}
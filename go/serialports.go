@@ -0,0 +1,314 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// SerialPort es un puerto serie. Los datos USB quedan vacíos en los puertos
+// que no son USB (p. ej. los de la placa base).
+type SerialPort struct {
+	Path          string `json:"path" jsonschema:"Dispositivo con el que se abre el puerto (ej: '/dev/ttyACM0', '/dev/cu.usbmodem1101', 'COM3')"`
+	ByID          string `json:"by_id,omitempty" jsonschema:"En Linux, ruta estable en /dev/serial/by-id que no cambia al reconectar"`
+	VendorID      string `json:"vendor_id,omitempty" jsonschema:"Identificador USB del fabricante en hexadecimal (ej: '2341')"`
+	ProductID     string `json:"product_id,omitempty" jsonschema:"Identificador USB del producto en hexadecimal"`
+	Vendor        string `json:"vendor,omitempty" jsonschema:"Fabricante"`
+	Product       string `json:"product,omitempty" jsonschema:"Descripción del producto (ej: 'Arduino Uno')"`
+	Serial        string `json:"serial,omitempty" jsonschema:"Número de serie USB"`
+	Driver        string `json:"driver,omitempty" jsonschema:"En Linux, controlador del puerto (cdc_acm, ftdi_sio, ch341...)"`
+	LikelyArduino bool   `json:"likely_arduino" jsonschema:"Suposición por el fabricante USB: placa Arduino, compatible o con un conversor USB-serie habitual en los clones"`
+}
+
+type SerialPortsOutput struct {
+	Ports []SerialPort `json:"ports,omitempty" jsonschema:"Puertos serie disponibles"`
+	Note  string       `json:"note,omitempty" jsonschema:"Aclaración sobre el origen de los puertos (p. ej. en WSL)"`
+}
+
+// arduinoVendors son los fabricantes USB de placas Arduino y compatibles, y
+// de los conversores USB-serie que usan los clones y las placas ESP
+var arduinoVendors = map[string]string{
+	"2341": "Arduino", "2a03": "Arduino (arduino.org)", "1b4f": "SparkFun",
+	"239a": "Adafruit", "1a86": "CH340 (clones)", "0403": "FTDI",
+	"10c4": "CP210x (Silicon Labs)", "303a": "Espressif", "2e8a": "Raspberry Pi (RP2040)",
+	"16c0": "Teensy",
+}
+
+// likelyArduino es la suposición de likely_arduino: un fabricante conocido o
+// "Arduino" en la descripción
+func likelyArduino(p SerialPort) bool {
+	_, known := arduinoVendors[p.VendorID]
+	return known || strings.Contains(strings.ToLower(p.Product+" "+p.Vendor), "arduino")
+}
+
+func listSerialPorts() ([]SerialPort, string, error) {
+	var ports []SerialPort
+	var note string
+	var err error
+	switch currentPlatform() {
+	case platformWindows:
+		ports, err = serialPortsWindows()
+	case platformWSL:
+		// Los puertos COM del anfitrión, más los USB que se hayan conectado a
+		// WSL con usbipd, que aparecen como /dev/ttyACM* o /dev/ttyUSB*
+		ports, err = serialPortsWindows()
+		if linux, linuxErr := serialPortsLinux(); linuxErr == nil {
+			for _, p := range linux {
+				if p.VendorID != "" {
+					ports = append(ports, p)
+				}
+			}
+		}
+		note = "Los puertos COM son del Windows anfitrión; para abrirlos desde WSL hay que conectar el dispositivo con usbipd"
+	case platformMac:
+		ports, err = serialPortsMac()
+	default:
+		ports, err = serialPortsLinux()
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	for i := range ports {
+		ports[i].LikelyArduino = likelyArduino(ports[i])
+	}
+	return ports, note, nil
+}
+
+// serialPortsLinux recorre /sys/class/tty. Los puertos USB (ttyACM, ttyUSB)
+// cuelgan de una interfaz del dispositivo USB, cuyos datos están en el
+// primer directorio superior con idVendor. Los ttyS solo se incluyen si el
+// núcleo detectó una UART (type distinto de 0), ya que existen siempre.
+func serialPortsLinux() ([]SerialPort, error) {
+	entries, err := os.ReadDir("/sys/class/tty")
+	if err != nil {
+		return nil, fmt.Errorf("error al leer /sys/class/tty: %w", err)
+	}
+	byID := map[string]string{}
+	if links, err := os.ReadDir("/dev/serial/by-id"); err == nil {
+		for _, link := range links {
+			path := filepath.Join("/dev/serial/by-id", link.Name())
+			if target, err := filepath.EvalSymlinks(path); err == nil {
+				byID[target] = path
+			}
+		}
+	}
+
+	var ports []SerialPort
+	for _, entry := range entries {
+		name := entry.Name()
+		dir := filepath.Join("/sys/class/tty", name)
+		device, err := filepath.EvalSymlinks(filepath.Join(dir, "device"))
+		if err != nil {
+			continue // Terminales virtuales, sin dispositivo
+		}
+		if strings.HasPrefix(name, "ttyS") && readTrimmedFile(filepath.Join(dir, "type")) == "0" {
+			continue
+		}
+		port := SerialPort{Path: "/dev/" + name}
+		port.ByID = byID[port.Path]
+		if driver, err := filepath.EvalSymlinks(filepath.Join(dir, "device", "driver")); err == nil {
+			port.Driver = filepath.Base(driver)
+		}
+		for usb := device; usb != "/" && usb != "."; usb = filepath.Dir(usb) {
+			if _, err := os.Stat(filepath.Join(usb, "idVendor")); err == nil {
+				port.VendorID = readTrimmedFile(filepath.Join(usb, "idVendor"))
+				port.ProductID = readTrimmedFile(filepath.Join(usb, "idProduct"))
+				port.Vendor = readTrimmedFile(filepath.Join(usb, "manufacturer"))
+				port.Product = readTrimmedFile(filepath.Join(usb, "product"))
+				port.Serial = readTrimmedFile(filepath.Join(usb, "serial"))
+				break
+			}
+		}
+		ports = append(ports, port)
+	}
+	return ports, nil
+}
+
+var (
+	// Nodo del árbol de ioreg: "+-o Arduino Uno@14100000  <class IOUSBHostDevice, id 0x100000a3b, ...>"
+	ioregNodeRegexp = regexp.MustCompile(`\+-o .*<class (\w+)`)
+	// Propiedad de un nodo: `"idVendor" = 9025` o `"USB Product Name" = "Arduino Uno"`
+	ioregPropertyRegexp = regexp.MustCompile(`^[\s|]*"([^"]+)" = (.+)$`)
+)
+
+// serialPortsMac lista /dev/cu.* (los dispositivos de llamada, los que se
+// usan para abrir el puerto) y busca su dispositivo USB en el árbol de
+// `ioreg -r -c IOUSBHostDevice -l`: el puerto es un descendiente del
+// dispositivo con la propiedad IOCalloutDevice.
+func serialPortsMac() ([]SerialPort, error) {
+	paths, err := filepath.Glob("/dev/cu.*")
+	if err != nil {
+		return nil, err
+	}
+	list := make([]SerialPort, len(paths))
+	ports := map[string]*SerialPort{}
+	for i, path := range paths {
+		list[i].Path = path
+		ports[path] = &list[i]
+	}
+
+	output, err := exec.Command("ioreg", "-r", "-c", "IOUSBHostDevice", "-l").Output()
+	if err != nil {
+		return list, nil // Sin ioreg se devuelven los puertos sin datos USB
+	}
+	type usbNode struct {
+		indent int
+		port   SerialPort
+	}
+	var stack []usbNode // Dispositivos USB antecesores del nodo actual
+	inUSBNode := false  // Las propiedades que siguen son de un dispositivo USB
+	scanner := bufio.NewScanner(strings.NewReader(string(output)))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if m := ioregNodeRegexp.FindStringSubmatch(line); m != nil {
+			indent := strings.Index(line, "+-o")
+			for len(stack) > 0 && stack[len(stack)-1].indent >= indent {
+				stack = stack[:len(stack)-1]
+			}
+			inUSBNode = m[1] == "IOUSBHostDevice" || m[1] == "IOUSBDevice"
+			if inUSBNode {
+				stack = append(stack, usbNode{indent: indent})
+			}
+			continue
+		}
+		m := ioregPropertyRegexp.FindStringSubmatch(line)
+		if m == nil || len(stack) == 0 {
+			continue
+		}
+		key, value := m[1], strings.Trim(m[2], `"`)
+		current := &stack[len(stack)-1].port
+		if key == "IOCalloutDevice" {
+			if port, ok := ports[value]; ok {
+				*port = *current
+				port.Path = value
+			}
+			continue
+		}
+		if !inUSBNode {
+			continue
+		}
+		switch key {
+		case "idVendor", "idProduct":
+			if id, err := strconv.Atoi(value); err == nil {
+				if key == "idVendor" {
+					current.VendorID = fmt.Sprintf("%04x", id)
+				} else {
+					current.ProductID = fmt.Sprintf("%04x", id)
+				}
+			}
+		case "USB Vendor Name":
+			current.Vendor = value
+		case "USB Product Name":
+			current.Product = value
+		case "USB Serial Number":
+			current.Serial = value
+		}
+	}
+	return list, nil
+}
+
+// windowsSerialPortsScript combina SerialPort.GetPortNames, que da todos los
+// puertos COM, con las entidades PnP cuyo nombre termina en "(COMn)", que
+// aportan la descripción, el fabricante y el identificador con VID y PID
+const windowsSerialPortsScript = `Add-Type -AssemblyName System.IO.Ports -ErrorAction SilentlyContinue
+$entities = @{}
+Get-CimInstance Win32_PnPEntity -Filter "Name LIKE '%(COM%'" -ErrorAction SilentlyContinue | ForEach-Object {
+	if ($_.Name -match '\((COM\d+)\)') { $entities[$Matches[1]] = $_ }
+}
+ConvertTo-Json -Compress @([System.IO.Ports.SerialPort]::GetPortNames() | Sort-Object -Unique | ForEach-Object {
+	$e = $entities[$_]
+	@{ port = $_; name = [string]$e.Name; manufacturer = [string]$e.Manufacturer; id = [string]$e.PNPDeviceID }
+})`
+
+// windowsSerialIDRegexp reconoce el VID y el PID tanto en los identificadores
+// USB ("USB\VID_2341&PID_0043\75830333238351F0E1D1") como en los de FTDI
+// ("FTDIBUS\VID_0403+PID_6001+A50285BIA\0000")
+var windowsSerialIDRegexp = regexp.MustCompile(`(?i)VID_([0-9A-F]{4})[&+]PID_([0-9A-F]{4})(?:[+\\]([^\\&+]+))?`)
+
+func serialPortsWindows() ([]SerialPort, error) {
+	output, err := runPowerShell(windowsSerialPortsScript)
+	if err != nil {
+		return nil, fmt.Errorf("error al consultar los puertos serie: %w", err)
+	}
+	var entries []struct {
+		Port, Name, Manufacturer, ID string
+	}
+	if err := json.Unmarshal([]byte(output), &entries); err != nil {
+		return nil, fmt.Errorf("respuesta de PowerShell no válida: %w", err)
+	}
+	var ports []SerialPort
+	for _, entry := range entries {
+		port := SerialPort{
+			Path:    entry.Port,
+			Vendor:  entry.Manufacturer,
+			Product: strings.TrimSpace(strings.TrimSuffix(entry.Name, "("+entry.Port+")")),
+		}
+		if m := windowsSerialIDRegexp.FindStringSubmatch(entry.ID); m != nil {
+			port.VendorID, port.ProductID = strings.ToLower(m[1]), strings.ToLower(m[2])
+			port.Serial = m[3]
+		}
+		ports = append(ports, port)
+	}
+	// COM10 después de COM9
+	slices.SortStableFunc(ports, func(a, b SerialPort) int {
+		na, _ := strconv.Atoi(strings.TrimPrefix(a.Path, "COM"))
+		nb, _ := strconv.Atoi(strings.TrimPrefix(b.Path, "COM"))
+		return na - nb
+	})
+	return ports, nil
+}
+
+func HandleListSerialPorts(ctx context.Context, req *mcp.CallToolRequest, input struct{}) (*mcp.CallToolResult, SerialPortsOutput, error) {
+	ports, note, err := listSerialPorts()
+	if err != nil {
+		return nil, SerialPortsOutput{}, fmt.Errorf("❌ Error al listar los puertos serie: %w", err)
+	}
+
+	var lines []string
+	if len(ports) == 0 {
+		lines = append(lines, "🔌 No se encontró ningún puerto serie")
+	} else {
+		lines = append(lines, fmt.Sprintf("🔌 %d puertos serie:", len(ports)))
+	}
+	for _, p := range ports {
+		var details []string
+		if p.Product != "" {
+			details = append(details, p.Product)
+		}
+		if p.VendorID != "" {
+			details = append(details, p.VendorID+":"+p.ProductID)
+		}
+		if p.Driver != "" {
+			details = append(details, p.Driver)
+		}
+		line := "  - " + p.Path
+		if len(details) > 0 {
+			line += " (" + strings.Join(details, ", ") + ")"
+		}
+		if p.LikelyArduino {
+			line += " 🤖 probablemente Arduino o compatible"
+		}
+		lines = append(lines, line)
+		if p.ByID != "" {
+			lines = append(lines, "      "+p.ByID)
+		}
+	}
+	if note != "" {
+		lines = append(lines, "ℹ️ "+note)
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: strings.Join(lines, "\n")},
+		},
+	}, SerialPortsOutput{Ports: ports, Note: note}, nil
+}
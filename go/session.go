@@ -0,0 +1,48 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"sync"
+)
+
+// errNoGraphicalSession indica que el servidor no se ejecuta dentro de una
+// sesión gráfica (p. ej. por SSH sin DISPLAY o como servicio de Windows), por
+// lo que no puede actuar sobre las pantallas del escritorio
+var errNoGraphicalSession = errors.New("no hay ninguna sesión gráfica disponible")
+
+var (
+	graphicalSessionOnce sync.Once
+	graphicalSessionErr  error
+)
+
+// checkGraphicalSession comprueba una sola vez si hay sesión gráfica: en
+// Linux DISPLAY o WAYLAND_DISPLAY, en Windows una sesión distinta de la 0
+// (la de los servicios, que no tiene escritorio). macOS siempre la tiene.
+// El error envuelve errNoGraphicalSession para poder detectarlo con errors.Is.
+func checkGraphicalSession() error {
+	graphicalSessionOnce.Do(func() {
+		switch {
+		case osType == "windows" || isWSL():
+			output, err := runPowerShell("[System.Diagnostics.Process]::GetCurrentProcess().SessionId")
+			if err != nil {
+				log.Printf("⚠️ No se pudo comprobar la sesión de Windows: %v", err)
+				return
+			}
+			log.Printf("🔍 Sesión de Windows: %s", output)
+			if id, err := strconv.Atoi(output); err == nil && id == 0 {
+				graphicalSessionErr = fmt.Errorf("%w (el proceso se ejecuta en la sesión 0 de servicios)", errNoGraphicalSession)
+			}
+		case osType == "linux":
+			display, wayland := os.Getenv("DISPLAY"), os.Getenv("WAYLAND_DISPLAY")
+			log.Printf("🔍 Sesión gráfica: DISPLAY=%q WAYLAND_DISPLAY=%q XDG_SESSION_TYPE=%q", display, wayland, os.Getenv("XDG_SESSION_TYPE"))
+			if display == "" && wayland == "" {
+				graphicalSessionErr = fmt.Errorf("%w (DISPLAY y WAYLAND_DISPLAY no están definidas)", errNoGraphicalSession)
+			}
+		}
+	})
+	return graphicalSessionErr
+}
@@ -0,0 +1,639 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// soundVolumeNote explica que en Windows no se puede aplicar el volumen
+const soundVolumeNote = "⚠️ Windows no permite fijar el volumen de este sonido: se reproduce al volumen del sistema"
+
+// playerVolumeArgs devuelve los argumentos que fijan el volumen (0-100) del
+// reproductor indicado, o nil si no se pidió volumen
+func playerVolumeArgs(player string, volume *int) []string {
+	if volume == nil {
+		return nil
+	}
+	switch player {
+	case "afplay":
+		// afplay usa 1.0 como volumen normal
+		return []string{"-v", fmt.Sprintf("%.2f", float64(*volume)/100)}
+	case "paplay":
+		// paplay usa la escala de PulseAudio, con 65536 como 100%
+		return []string{fmt.Sprintf("--volume=%d", *volume*65536/100)}
+	case "mpv":
+		return []string{fmt.Sprintf("--volume=%d", *volume)}
+	}
+	return nil
+}
+
+// Límites de repeat e interval_ms, y duración máxima de una reproducción
+// repetida para que un agente no pueda crear una sirena imparable
+const (
+	maxSoundRepeat    = 10
+	maxSoundInterval  = 10 * time.Second
+	maxSoundPlaysTime = 60 * time.Second
+)
+
+// soundOptions son las opciones comunes de play_sound
+type soundOptions struct {
+	Volume   *int
+	Async    bool
+	Repeat   int
+	Interval time.Duration
+}
+
+// playSound ejecuta el reproductor argv y espera a que termine. Con Repeat
+// lo repite con una pausa de Interval, deteniéndose si se cancela ctx o se
+// supera maxSoundPlaysTime. Con Async lo deja en segundo plano y devuelve el
+// id con el que se registró. note se antepone al resultado.
+func playSound(ctx context.Context, name string, argv []string, note string, opts soundOptions) (string, error) {
+	if opts.Async {
+		if opts.Repeat > 1 {
+			return "", errors.New("repeat no se puede combinar con async")
+		}
+		id, err := startTracked("sound", exec.Command(argv[0], argv[1:]...))
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s🔔 Sonido '%s' reproduciéndose en segundo plano (id: %s)", note, name, id), nil
+	}
+
+	repeat := max(opts.Repeat, 1)
+	if repeat > 1 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, maxSoundPlaysTime)
+		defer cancel()
+	}
+
+	completed := 0
+	var playErr error
+	for completed < repeat {
+		if completed > 0 && opts.Interval > 0 {
+			select {
+			case <-ctx.Done():
+			case <-time.After(opts.Interval):
+			}
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		if err := exec.CommandContext(ctx, argv[0], argv[1:]...).Run(); err != nil {
+			if ctx.Err() == nil {
+				playErr = err
+			}
+			break
+		}
+		completed++
+	}
+
+	switch {
+	case playErr != nil && completed == 0:
+		return "", playErr
+	case playErr != nil:
+		return "", fmt.Errorf("falló tras %d de %d repeticiones: %w", completed, repeat, playErr)
+	case completed < repeat && errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return fmt.Sprintf("%s⏹️ Sonido '%s' detenido tras %d de %d repeticiones (límite de %s)", note, name, completed, repeat, maxSoundPlaysTime), nil
+	case completed < repeat:
+		return fmt.Sprintf("%s⏹️ Sonido '%s' cancelado tras %d de %d repeticiones", note, name, completed, repeat), nil
+	}
+
+	result := fmt.Sprintf("%s🔔 Sonido '%s' reproducido", note, name)
+	if repeat > 1 {
+		result += fmt.Sprintf(" %d veces", completed)
+	}
+	if opts.Volume != nil && note == "" {
+		result += fmt.Sprintf(" al %d%% de volumen", *opts.Volume)
+	}
+	return result, nil
+}
+
+// beepFrequencies asigna a cada tipo de sonido un tono {frecuencia Hz,
+// duración ms}, usado con [console]::beep y para generar WAV de respaldo
+var beepFrequencies = map[string][2]int{
+	"beep":    {1000, 500},
+	"alert":   {800, 300},
+	"success": {1200, 200},
+	"error":   {400, 500},
+	"default": {1000, 500},
+}
+
+// freedesktopSounds asigna a cada tipo de sonido un evento del tema de
+// sonidos freedesktop (ficheros .oga en freedesktopSoundDir)
+var freedesktopSounds = map[string]string{
+	"beep":    "bell",
+	"alert":   "dialog-warning",
+	"success": "complete",
+	"error":   "dialog-error",
+	"default": "complete",
+}
+
+const freedesktopSoundDir = "/usr/share/sounds/freedesktop/stereo"
+
+// windowsMediaSounds asigna a cada tipo de sonido un fichero de
+// %WINDIR%\Media. Si no existe se recurre a [console]::beep.
+var windowsMediaSounds = map[string]string{
+	"beep":    "Windows Ding.wav",
+	"alert":   "Windows Exclamation.wav",
+	"success": "tada.wav",
+	"error":   "Windows Critical Stop.wav",
+	"default": "Windows Notify.wav",
+}
+
+// macSystemSounds asigna a cada tipo de sonido un sonido de macOS
+var macSystemSounds = map[string]string{
+	"beep":    "/System/Library/Sounds/Ping.aiff",
+	"alert":   "/System/Library/Sounds/Sosumi.aiff",
+	"success": "/System/Library/Sounds/Glass.aiff",
+	"error":   "/System/Library/Sounds/Basso.aiff",
+	"default": "/System/Library/Sounds/Glass.aiff",
+}
+
+// playSystemSound reproduce un sonido del sistema con las opciones indicadas
+// (volumen relativo al del sistema, repeticiones o en segundo plano)
+func playSystemSound(ctx context.Context, soundType string, opts soundOptions) string {
+	if soundType == "" {
+		soundType = "default"
+	}
+	if path, ok := customSounds[soundType]; ok {
+		result, err := playAudioFile(ctx, soundType, path, opts)
+		if err != nil {
+			return fmt.Sprintf("❌ Error al reproducir sonido: %v", err)
+		}
+		return result
+	}
+	if _, ok := beepFrequencies[soundType]; !ok {
+		return fmt.Sprintf("❌ Tipo de sonido desconocido '%s' (disponibles: %s)", soundType, strings.Join(soundNames(), ", "))
+	}
+
+	var argv []string
+	var note string
+	var player string
+
+	switch currentPlatform() {
+	case platformWindows, platformWSL:
+		// Windows (también desde WSL) - sonidos de %WINDIR%\Media con
+		// SoundPlayer, o un pitido si el fichero no existe
+		file, ok := windowsMediaSounds[soundType]
+		if !ok {
+			file = windowsMediaSounds["default"]
+		}
+		freq, ok := beepFrequencies[soundType]
+		if !ok {
+			freq = beepFrequencies["default"]
+		}
+
+		// Ni SoundPlayer ni [console]::beep permiten fijar el volumen
+		if opts.Volume != nil {
+			note = soundVolumeNote + "\n"
+		}
+		script := fmt.Sprintf("$f = Join-Path $env:WINDIR 'Media\\%s'; if (Test-Path $f) { (New-Object System.Media.SoundPlayer $f).PlaySync() } else { [console]::beep(%d,%d) }", file, freq[0], freq[1])
+		argv = []string{powershellCommand(), "-NoProfile", "-Command", script}
+	case platformMac:
+		// macOS - usando afplay
+		soundPath, ok := macSystemSounds[soundType]
+		if !ok {
+			soundPath = macSystemSounds["default"]
+		}
+
+		argv = append(append([]string{"afplay"}, playerVolumeArgs("afplay", opts.Volume)...), soundPath)
+	default:
+		// Linux - el primer reproductor disponible
+		var err error
+		argv, player, err = linuxSoundCommand(soundType, opts.Volume)
+		if errors.Is(err, errNoSoundPlayer) {
+			return ringTerminalBell(soundType)
+		}
+		if err != nil {
+			return fmt.Sprintf("❌ Error al reproducir sonido: %v", err)
+		}
+		if opts.Volume != nil && (argv[0] == "aplay" || argv[0] == "canberra-gtk-play") {
+			note = fmt.Sprintf("⚠️ %s no permite fijar el volumen: se reproduce al volumen del sistema\n", argv[0])
+		}
+	}
+
+	result, err := playSound(ctx, soundType, argv, note, opts)
+	if err != nil {
+		if player != "" {
+			return fmt.Sprintf("❌ Error al reproducir sonido (%s): %v", player, err)
+		}
+		return fmt.Sprintf("❌ Error al reproducir sonido: %v", err)
+	}
+	if player != "" {
+		result += fmt.Sprintf(" (%s)", player)
+	}
+	return result
+}
+
+// errNoSoundPlayer indica que no hay ningún reproductor de audio instalado
+var errNoSoundPlayer = errors.New("no se encontró ningún reproductor de audio")
+
+// linuxSoundCommand elige el primer reproductor disponible entre paplay,
+// pw-play (PipeWire), aplay y canberra-gtk-play. Se usa el fichero del tema
+// freedesktop si existe y, si no (o con aplay, que solo lee WAV), un tono
+// generado. Devuelve también una descripción del reproductor y el fichero.
+func linuxSoundCommand(soundType string, volume *int) (argv []string, player string, err error) {
+	event, ok := freedesktopSounds[soundType]
+	if !ok {
+		event = freedesktopSounds["default"]
+	}
+	freq, ok := beepFrequencies[soundType]
+	if !ok {
+		freq = beepFrequencies["default"]
+	}
+
+	themeFile := filepath.Join(freedesktopSoundDir, event+".oga")
+	if _, err := os.Stat(themeFile); err != nil {
+		themeFile = ""
+	}
+	soundFile := func(wavOnly bool) (string, error) {
+		if themeFile != "" && !wavOnly {
+			return themeFile, nil
+		}
+		return toneFile(freq[0], time.Duration(freq[1])*time.Millisecond)
+	}
+
+	for _, name := range []string{"paplay", "pw-play", "aplay"} {
+		if !commandExists(name)() {
+			continue
+		}
+		path, err := soundFile(name == "aplay")
+		if err != nil {
+			return nil, "", fmt.Errorf("no se pudo generar el sonido: %w", err)
+		}
+		args := []string{name}
+		switch name {
+		case "paplay":
+			args = append(args, playerVolumeArgs("paplay", volume)...)
+		case "pw-play":
+			if volume != nil {
+				args = append(args, fmt.Sprintf("--volume=%.2f", float64(*volume)/100))
+			}
+		case "aplay":
+			args = append(args, "-q")
+		}
+		return append(args, path), fmt.Sprintf("%s: %s", name, path), nil
+	}
+
+	if commandExists("canberra-gtk-play")() {
+		return []string{"canberra-gtk-play", "-i", event}, "canberra-gtk-play: evento " + event, nil
+	}
+	return nil, "", errNoSoundPlayer
+}
+
+// ringTerminalBell es el último recurso sin reproductor de audio: escribe el
+// carácter BEL en el terminal de control. No se usa stdout porque lo ocupa el
+// protocolo MCP.
+func ringTerminalBell(soundType string) string {
+	tty, err := os.OpenFile("/dev/tty", os.O_WRONLY, 0)
+	if err != nil {
+		return fmt.Sprintf("❌ Error al reproducir sonido: %v (paplay, pw-play, aplay, canberra-gtk-play) y no hay terminal para el pitido", errNoSoundPlayer)
+	}
+	defer tty.Close()
+	if _, err := tty.Write([]byte("\a")); err != nil {
+		return fmt.Sprintf("❌ Error al reproducir sonido: %v", err)
+	}
+	return fmt.Sprintf("🔔 Sonido '%s' reproducido (pitido del terminal: no hay reproductor de audio)", soundType)
+}
+
+// soundsFile define sonidos con nombre propios (nombre → ruta del fichero),
+// que se suman a los tipos predefinidos o los sustituyen
+const soundsFile = "sounds.json"
+
+// customSounds son los sonidos cargados de soundsFile al arrancar
+var customSounds = map[string]string{}
+
+// loadCustomSounds lee soundsFile y registra las entradas válidas. Las que
+// apuntan a ficheros inexistentes o con un formato no admitido se descartan
+// con un aviso en el log.
+func loadCustomSounds() error {
+	entries := map[string]string{}
+	if err := loadJSONConfig(soundsFile, &entries); err != nil {
+		return fmt.Errorf("error al leer %s: %w", soundsFile, err)
+	}
+	for name, path := range entries {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			log.Printf("⚠️ %s: se ignora una entrada sin nombre", soundsFile)
+			continue
+		}
+		if ext := strings.ToLower(filepath.Ext(path)); !slices.Contains(soundExtensions, ext) {
+			log.Printf("⚠️ %s: se ignora '%s', extensión %q no permitida (admitidas: %s)", soundsFile, name, ext, strings.Join(soundExtensions, ", "))
+			continue
+		}
+		if info, err := os.Stat(path); err != nil || !info.Mode().IsRegular() {
+			log.Printf("⚠️ %s: se ignora '%s', no existe el fichero %s", soundsFile, name, path)
+			continue
+		}
+		customSounds[name] = path
+	}
+	if len(customSounds) > 0 {
+		names := slices.Sorted(maps.Keys(customSounds))
+		log.Printf("🔔 Sonidos propios registrados: %s", strings.Join(names, ", "))
+	}
+	return nil
+}
+
+// soundNames devuelve los tipos de sonido disponibles, predefinidos y propios
+func soundNames() []string {
+	names := slices.Collect(maps.Keys(beepFrequencies))
+	for name := range customSounds {
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+// soundDir es el único directorio desde el que play_sound puede reproducir
+// ficheros propios (configurable con --sound-dir). Vacío usa el subdirectorio
+// "sounds" del directorio de configuración.
+var soundDir string
+
+// soundExtensions son los formatos de audio admitidos para file_path
+var soundExtensions = []string{".wav", ".aiff", ".oga", ".mp3"}
+
+// resolveSoundFile valida que path sea un fichero de audio existente dentro
+// de soundDir y devuelve su ruta absoluta. Las rutas relativas se resuelven
+// respecto a soundDir; los enlaces simbólicos no pueden salir de él.
+func resolveSoundFile(path string) (string, error) {
+	dir := soundDir
+	if dir == "" {
+		var err error
+		if dir, err = configPath("sounds"); err != nil {
+			return "", err
+		}
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	if resolved, err := filepath.EvalSymlinks(dir); err == nil {
+		dir = resolved
+	}
+
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+	path = filepath.Clean(path)
+
+	ext := strings.ToLower(filepath.Ext(path))
+	if !slices.Contains(soundExtensions, ext) {
+		return "", fmt.Errorf("extensión %q no permitida (admitidas: %s)", ext, strings.Join(soundExtensions, ", "))
+	}
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	if rel, err := filepath.Rel(dir, path); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("solo se pueden reproducir ficheros dentro de %s (configurable con --sound-dir)", dir)
+	}
+	info, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("el fichero %s no existe", path)
+	}
+	if err != nil {
+		return "", err
+	}
+	if !info.Mode().IsRegular() {
+		return "", fmt.Errorf("%s no es un fichero", path)
+	}
+	return path, nil
+}
+
+// playSoundFile reproduce un fichero de audio del directorio permitido con
+// las mismas opciones que playSystemSound
+func playSoundFile(ctx context.Context, path string, opts soundOptions) (string, error) {
+	path, err := resolveSoundFile(path)
+	if err != nil {
+		return "", err
+	}
+	return playAudioFile(ctx, filepath.Base(path), path, opts)
+}
+
+// playAudioFile reproduce un fichero de audio ya validado con el reproductor
+// de la plataforma. name identifica el sonido en el resultado.
+func playAudioFile(ctx context.Context, name, path string, opts soundOptions) (string, error) {
+	var argv []string
+	var note string
+	switch currentPlatform() {
+	case platformWindows, platformWSL:
+		// SoundPlayer solo admite WAV y no tiene control de volumen
+		if strings.ToLower(filepath.Ext(path)) != ".wav" {
+			return "", errors.New("en Windows solo se pueden reproducir ficheros .wav")
+		}
+		winPath, err := windowsPath(path)
+		if err != nil {
+			return "", err
+		}
+		script := fmt.Sprintf("(New-Object System.Media.SoundPlayer '%s').PlaySync()", strings.ReplaceAll(winPath, "'", "''"))
+		argv = []string{powershellCommand(), "-NoProfile", "-Command", script}
+		if opts.Volume != nil {
+			note = soundVolumeNote + "\n"
+		}
+	case platformMac:
+		argv = append(append([]string{"afplay"}, playerVolumeArgs("afplay", opts.Volume)...), path)
+	default:
+		// paplay no decodifica MP3, que se reproduce con mpv
+		if strings.ToLower(filepath.Ext(path)) != ".mp3" && commandExists("paplay")() {
+			argv = append(append([]string{"paplay"}, playerVolumeArgs("paplay", opts.Volume)...), path)
+		} else if commandExists("mpv")() {
+			argv = append(append([]string{"mpv", "--no-video", "--really-quiet"}, playerVolumeArgs("mpv", opts.Volume)...), path)
+		} else {
+			return "", errors.New("no se encontró ningún reproductor (instala pulseaudio-utils o mpv)")
+		}
+	}
+
+	result, err := playSound(ctx, name, argv, note, opts)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", filepath.Base(path), err)
+	}
+	return result, nil
+}
+
+// SoundInfo describe un tipo de sonido tal y como se reproduciría en esta
+// plataforma: desde un fichero o, si no lo hay, como un tono generado
+type SoundInfo struct {
+	Name        string `json:"name" jsonschema:"Nombre para el parámetro sound_type de play_sound"`
+	Custom      bool   `json:"custom" jsonschema:"Es un sonido propio de sounds.json"`
+	File        string `json:"file,omitempty" jsonschema:"Fichero que se reproduce"`
+	FrequencyHz int    `json:"frequency_hz,omitempty" jsonschema:"Frecuencia del tono que se reproduce cuando no hay fichero"`
+	DurationMs  int    `json:"duration_ms,omitempty" jsonschema:"Duración del tono en milisegundos"`
+	Available   bool   `json:"available" jsonschema:"Se puede reproducir en esta máquina (false si falta el fichero)"`
+}
+
+// listSounds devuelve los sonidos predefinidos y propios con lo que los
+// respalda en la plataforma actual, comprobando que los ficheros existan
+func listSounds() []SoundInfo {
+	platform := currentPlatform()
+
+	// En Windows los ficheros de %WINDIR%\Media se comprueban de una vez
+	var windowsMedia map[string]bool
+	if platform == platformWindows || platform == platformWSL {
+		var files []string
+		for _, file := range windowsMediaSounds {
+			files = append(files, "'"+file+"'")
+		}
+		script := fmt.Sprintf("%s | Where-Object { Test-Path (Join-Path $env:WINDIR \"Media\\$_\") }", strings.Join(files, ","))
+		if output, err := runPowerShell(script); err == nil {
+			windowsMedia = map[string]bool{}
+			for _, line := range strings.Split(strings.ReplaceAll(output, "\r\n", "\n"), "\n") {
+				windowsMedia[strings.TrimSpace(line)] = true
+			}
+		}
+	}
+
+	var sounds []SoundInfo
+	for _, name := range soundNames() {
+		if path, ok := customSounds[name]; ok {
+			_, err := os.Stat(path)
+			sounds = append(sounds, SoundInfo{Name: name, Custom: true, File: path, Available: err == nil})
+			continue
+		}
+		freq := beepFrequencies[name]
+		tone := SoundInfo{Name: name, FrequencyHz: freq[0], DurationMs: freq[1], Available: true}
+		switch platform {
+		case platformWindows, platformWSL:
+			// Sin el fichero se oye un pitido; sin poder comprobarlo se da por presente
+			if file := windowsMediaSounds[name]; windowsMedia == nil || windowsMedia[file] {
+				sounds = append(sounds, SoundInfo{Name: name, File: `%WINDIR%\Media\` + file, Available: true})
+				continue
+			}
+			sounds = append(sounds, tone)
+		case platformMac:
+			path := macSystemSounds[name]
+			_, err := os.Stat(path)
+			sounds = append(sounds, SoundInfo{Name: name, File: path, Available: err == nil})
+		default:
+			// Sin el tema freedesktop se genera un tono
+			path := filepath.Join(freedesktopSoundDir, freedesktopSounds[name]+".oga")
+			if _, err := os.Stat(path); err == nil {
+				sounds = append(sounds, SoundInfo{Name: name, File: path, Available: true})
+				continue
+			}
+			sounds = append(sounds, tone)
+		}
+	}
+	return sounds
+}
+
+type ListSoundsOutput struct {
+	Sounds []SoundInfo `json:"sounds,omitempty" jsonschema:"Sonidos disponibles para play_sound"`
+}
+
+type PlaySoundInput struct {
+	SoundType  string `json:"sound_type,omitempty" jsonschema:"Tipo de sonido a reproducir: beep, alert, success, error, default o un sonido propio (consulta list_sounds)"`
+	FilePath   string `json:"file_path,omitempty" jsonschema:"Fichero de audio propio (wav, aiff, oga o mp3) dentro del directorio de sonidos permitido. Si se indica, se ignora sound_type"`
+	Volume     *int   `json:"volume,omitempty" jsonschema:"Volumen de reproducción (0-100) relativo al del sistema. No disponible en Windows"`
+	Async      bool   `json:"async,omitempty" jsonschema:"Si es true responde sin esperar a que termine el sonido y devuelve su id"`
+	Repeat     int    `json:"repeat,omitempty" jsonschema:"Número de veces que se reproduce el sonido (1-10, por defecto 1)"`
+	IntervalMs int    `json:"interval_ms,omitempty" jsonschema:"Pausa entre repeticiones en milisegundos (máximo 10000)"`
+
+	FrequencyHz int `json:"frequency_hz,omitempty" jsonschema:"Frecuencia de un tono a generar en Hz (37-32767). Si se indica, se ignora sound_type"`
+	DurationMs  int `json:"duration_ms,omitempty" jsonschema:"Duración del tono en milisegundos (por defecto 500, máximo 10000)"`
+}
+
+func HandlePlaySound(ctx context.Context, req *mcp.CallToolRequest, input PlaySoundInput) (*mcp.CallToolResult, any, error) {
+	opts := soundOptions{
+		Volume:   input.Volume,
+		Async:    input.Async,
+		Repeat:   input.Repeat,
+		Interval: time.Duration(input.IntervalMs) * time.Millisecond,
+	}
+	if input.FilePath == "" && (input.FrequencyHz != 0 || input.DurationMs != 0) {
+		frequency, duration := input.FrequencyHz, time.Duration(input.DurationMs)*time.Millisecond
+		if frequency == 0 {
+			frequency = 1000
+		}
+		if duration == 0 {
+			duration = 500 * time.Millisecond
+		}
+		result, err := playTone(ctx, frequency, duration, opts)
+		if err != nil {
+			return nil, nil, fmt.Errorf("❌ Error al reproducir tono: %w", err)
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: result},
+			},
+		}, nil, nil
+	}
+	if input.FilePath != "" {
+		result, err := playSoundFile(ctx, input.FilePath, opts)
+		if err != nil {
+			return nil, nil, fmt.Errorf("❌ Error al reproducir sonido: %w", err)
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: result},
+			},
+		}, nil, nil
+	}
+
+	result := playSystemSound(ctx, input.SoundType, opts)
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: result},
+		},
+	}, nil, nil
+}
+
+type StopSoundInput struct {
+	ID string `json:"id,omitempty" jsonschema:"Id del sonido devuelto por play_sound con async (ej: 'sound-3'). Vacío para detener todos"`
+}
+
+func HandleStopSound(ctx context.Context, req *mcp.CallToolRequest, input StopSoundInput) (*mcp.CallToolResult, any, error) {
+	stopped, err := stopTracked("sound", input.ID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("❌ Error al detener sonido: %w", err)
+	}
+
+	var result string
+	switch {
+	case stopped == 0:
+		result = "🔇 No había ningún sonido reproduciéndose"
+	case input.ID != "":
+		result = fmt.Sprintf("🔇 Sonido %s detenido", input.ID)
+	default:
+		result = fmt.Sprintf("🔇 %d sonido(s) detenido(s)", stopped)
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: result},
+		},
+	}, nil, nil
+}
+
+func HandleListSounds(ctx context.Context, req *mcp.CallToolRequest, input struct{}) (*mcp.CallToolResult, ListSoundsOutput, error) {
+	sounds := listSounds()
+	lines := []string{"🔔 Sonidos disponibles para play_sound:"}
+	for _, sound := range sounds {
+		source := sound.File
+		if source == "" {
+			source = fmt.Sprintf("tono de %d Hz, %d ms", sound.FrequencyHz, sound.DurationMs)
+		}
+		if sound.Custom {
+			source += " (propio)"
+		}
+		if !sound.Available {
+			source += " ⚠️ no disponible: falta el fichero"
+		}
+		lines = append(lines, fmt.Sprintf("  - %s: %s", sound.Name, source))
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: strings.Join(lines, "\n")},
+		},
+	}, ListSoundsOutput{Sounds: sounds}, nil
+}
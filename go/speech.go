@@ -0,0 +1,307 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// maxSpeechLength limita el texto de speak, pensado para mensajes breves
+const maxSpeechLength = 500
+
+// Velocidad de habla en palabras por minuto: rango admitido y valor que se
+// toma como velocidad normal al convertir a las escalas relativas
+const (
+	minSpeechRate    = 80
+	maxSpeechRate    = 400
+	normalSpeechRate = 180
+)
+
+// speechEngine devuelve el sintetizador disponible en Linux
+func speechEngine() (string, error) {
+	for _, name := range []string{"espeak-ng", "espeak", "spd-say"} {
+		if commandExists(name)() {
+			return name, nil
+		}
+	}
+	return "", errors.New("no hay ningún sintetizador de voz instalado (instala espeak-ng o speech-dispatcher)")
+}
+
+// speechCommand construye el comando que lee text en voz alta. El texto se
+// pasa por la entrada estándar o como argumento tras "--", nunca a través
+// de un intérprete, para que no pueda inyectar órdenes.
+func speechCommand(ctx context.Context, text, voice string, rate int) (*exec.Cmd, string, error) {
+	var cmd *exec.Cmd
+	var engine string
+	switch currentPlatform() {
+	case platformWindows, platformWSL:
+		engine = "System.Speech"
+		script := "Add-Type -AssemblyName System.Speech; $s = New-Object System.Speech.Synthesis.SpeechSynthesizer; "
+		if voice != "" {
+			script += fmt.Sprintf("$s.SelectVoice('%s'); ", strings.ReplaceAll(voice, "'", "''"))
+		}
+		if rate != 0 {
+			// SpeechSynthesizer.Rate va de -10 a 10, con 0 como velocidad normal
+			script += fmt.Sprintf("$s.Rate = %d; ", min(max((rate-normalSpeechRate)/20, -10), 10))
+		}
+		script += "$s.Speak([Console]::In.ReadToEnd())"
+		cmd = exec.CommandContext(ctx, powershellCommand(), "-NoProfile", "-Command", script)
+		cmd.Stdin = strings.NewReader(text)
+	case platformMac:
+		engine = "say"
+		var args []string
+		if voice != "" {
+			args = append(args, "-v", voice)
+		}
+		if rate != 0 {
+			args = append(args, "-r", strconv.Itoa(rate))
+		}
+		// Sin mensaje en los argumentos say lee la entrada estándar
+		cmd = exec.CommandContext(ctx, "say", args...)
+		cmd.Stdin = strings.NewReader(text)
+	default:
+		var err error
+		if engine, err = speechEngine(); err != nil {
+			return nil, "", err
+		}
+		var args []string
+		switch engine {
+		case "spd-say":
+			args = append(args, "-w")
+			if voice != "" {
+				args = append(args, "-y", voice)
+			}
+			if rate != 0 {
+				// spd-say usa una escala relativa de -100 a 100
+				args = append(args, "-r", strconv.Itoa(min(max((rate-normalSpeechRate)/2, -100), 100)))
+			}
+			args = append(args, "--", text)
+		default:
+			if voice != "" {
+				args = append(args, "-v", voice)
+			}
+			if rate != 0 {
+				args = append(args, "-s", strconv.Itoa(rate))
+			}
+			args = append(args, "--stdin")
+		}
+		cmd = exec.CommandContext(ctx, engine, args...)
+		if engine != "spd-say" {
+			cmd.Stdin = strings.NewReader(text)
+		}
+	}
+	return cmd, engine, nil
+}
+
+// speak lee text en voz alta. Con async responde sin esperar y devuelve el
+// id del proceso, que se puede detener con stop_sound.
+func speak(ctx context.Context, text, voice string, rate int, async bool) (string, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return "", errors.New("el texto no puede estar vacío")
+	}
+	if n := utf8.RuneCountInString(text); n > maxSpeechLength {
+		return "", fmt.Errorf("el texto tiene %d caracteres (máximo %d)", n, maxSpeechLength)
+	}
+
+	if async {
+		// El proceso no debe morir al terminar la petición
+		ctx = context.Background()
+	}
+	cmd, engine, err := speechCommand(ctx, text, voice, rate)
+	if err != nil {
+		return "", err
+	}
+
+	if async {
+		id, err := startTracked("sound", cmd)
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", engine, err)
+		}
+		return fmt.Sprintf("🗣️ Leyendo el texto en segundo plano con %s (id: %s)", engine, id), nil
+	}
+	if output, err := cmd.CombinedOutput(); err != nil {
+		if ctx.Err() != nil {
+			return "⏹️ Lectura cancelada", nil
+		}
+		if msg := strings.TrimSpace(string(output)); msg != "" {
+			return "", fmt.Errorf("%s: %w: %s", engine, err, msg)
+		}
+		return "", fmt.Errorf("%s: %w", engine, err)
+	}
+	return fmt.Sprintf("🗣️ Texto leído con %s (%d caracteres)", engine, utf8.RuneCountInString(text)), nil
+}
+
+// Voice es una voz de síntesis instalada
+type Voice struct {
+	Name     string `json:"name" jsonschema:"Nombre de la voz, para el parámetro voice de speak"`
+	Language string `json:"language,omitempty" jsonschema:"Idioma o locale de la voz (ej: es_ES, en-US)"`
+}
+
+// listVoices devuelve las voces instaladas para el sintetizador de la plataforma
+func listVoices() ([]Voice, string, error) {
+	switch currentPlatform() {
+	case platformWindows, platformWSL:
+		output, err := runPowerShell("Add-Type -AssemblyName System.Speech; (New-Object System.Speech.Synthesis.SpeechSynthesizer).GetInstalledVoices() | ForEach-Object { \"$($_.VoiceInfo.Name)|$($_.VoiceInfo.Culture)\" }")
+		if err != nil {
+			return nil, "", err
+		}
+		var voices []Voice
+		for _, line := range strings.Split(strings.ReplaceAll(output, "\r\n", "\n"), "\n") {
+			if name, lang, ok := strings.Cut(strings.TrimSpace(line), "|"); ok {
+				voices = append(voices, Voice{Name: name, Language: lang})
+			}
+		}
+		return voices, "System.Speech", nil
+	case platformMac:
+		output, err := exec.Command("say", "-v", "?").Output()
+		if err != nil {
+			return nil, "", err
+		}
+		return parseSayVoices(string(output)), "say", nil
+	}
+
+	engine, err := speechEngine()
+	if err != nil {
+		return nil, "", err
+	}
+	if engine == "spd-say" {
+		output, err := exec.Command("spd-say", "-L").Output()
+		if err != nil {
+			return nil, "", err
+		}
+		return parseSpdVoices(string(output)), engine, nil
+	}
+	output, err := exec.Command(engine, "--voices").Output()
+	if err != nil {
+		return nil, "", err
+	}
+	return parseEspeakVoices(string(output)), engine, nil
+}
+
+// parseSayVoices interpreta `say -v '?'`:
+//
+//	Alex                en_US    # Most people recognize me by my voice.
+//	Mónica              es_ES    # Hola, me llamo Mónica y soy una voz española.
+func parseSayVoices(output string) []Voice {
+	var voices []Voice
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		// El nombre puede tener espacios, como "Bad News"; el locale es el último campo
+		voices = append(voices, Voice{
+			Name:     strings.Join(fields[:len(fields)-1], " "),
+			Language: fields[len(fields)-1],
+		})
+	}
+	return voices
+}
+
+// parseEspeakVoices interpreta `espeak-ng --voices`:
+//
+//	Pty Language       Age/Gender VoiceName          File                 Other Languages
+//	 5  es              --/M      Spanish_(Spain)    roa/es
+func parseEspeakVoices(output string) []Voice {
+	var voices []Voice
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 4 || fields[0] == "Pty" {
+			continue
+		}
+		// espeak acepta en -v tanto el nombre como el código de idioma
+		voices = append(voices, Voice{Name: fields[3], Language: fields[1]})
+	}
+	return voices
+}
+
+// parseSpdVoices interpreta `spd-say -L`:
+//
+//	NAME                 LANGUAGE  VARIANT
+//	 Spanish (Spain)     es        none
+func parseSpdVoices(output string) []Voice {
+	var voices []Voice
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 3 || fields[0] == "NAME" {
+			continue
+		}
+		voices = append(voices, Voice{
+			Name:     strings.Join(fields[:len(fields)-2], " "),
+			Language: fields[len(fields)-2],
+		})
+	}
+	return voices
+}
+
+type SpeakInput struct {
+	Text  string `json:"text" jsonschema:"Texto a leer en voz alta (máximo 500 caracteres)"`
+	Voice string `json:"voice,omitempty" jsonschema:"Voz a usar (opcional, consulta list_voices)"`
+	Rate  int    `json:"rate,omitempty" jsonschema:"Velocidad en palabras por minuto (80-400, opcional; 180 es la normal)"`
+	Async bool   `json:"async,omitempty" jsonschema:"Si es true responde sin esperar a que termine la lectura y devuelve su id para stop_sound"`
+}
+
+type ListVoicesInput struct {
+	Language string `json:"language,omitempty" jsonschema:"Filtra por idioma (ej: 'es', 'en_US'). Vacío para todas"`
+}
+
+type ListVoicesOutput struct {
+	Voices []Voice `json:"voices,omitempty" jsonschema:"Voces instaladas"`
+}
+
+func HandleSpeak(ctx context.Context, req *mcp.CallToolRequest, input SpeakInput) (*mcp.CallToolResult, any, error) {
+	result, err := speak(ctx, input.Text, input.Voice, input.Rate, input.Async)
+	if err != nil {
+		return nil, nil, fmt.Errorf("❌ Error al leer el texto: %w", err)
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: result},
+		},
+	}, nil, nil
+}
+
+func HandleListVoices(ctx context.Context, req *mcp.CallToolRequest, input ListVoicesInput) (*mcp.CallToolResult, ListVoicesOutput, error) {
+	voices, engine, err := listVoices()
+	if err != nil {
+		return nil, ListVoicesOutput{}, fmt.Errorf("❌ Error al listar voces: %w", err)
+	}
+	if input.Language != "" {
+		var filtered []Voice
+		for _, v := range voices {
+			lang := strings.ReplaceAll(strings.ToLower(v.Language), "-", "_")
+			if strings.HasPrefix(lang, strings.ReplaceAll(strings.ToLower(input.Language), "-", "_")) {
+				filtered = append(filtered, v)
+			}
+		}
+		voices = filtered
+	}
+
+	var result string
+	if len(voices) == 0 {
+		result = fmt.Sprintf("🗣️ No se encontraron voces (%s)", engine)
+	} else {
+		lines := []string{fmt.Sprintf("🗣️ Voces disponibles (%s):", engine)}
+		for _, v := range voices {
+			lines = append(lines, fmt.Sprintf("  - %s (%s)", v.Name, v.Language))
+		}
+		result = strings.Join(lines, "\n")
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: result},
+		},
+	}, ListVoicesOutput{Voices: voices}, nil
+}
@@ -0,0 +1,423 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// speedTestFile es el fichero de configuración opcional con las URL de la
+// prueba y el límite de datos, p. ej.
+// {"download_url": "https://mi-servidor/100MB.bin", "upload_url": "https://mi-servidor/upload", "max_megabytes": 20}
+const speedTestFile = "speedtest.json"
+
+// Valores por defecto de network_speed_test. Los servidores de Cloudflare
+// sirven bytes={bytes} bytes en la descarga y aceptan cualquier subida.
+const (
+	defaultSpeedTestDownloadURL = "https://speed.cloudflare.com/__down?bytes={bytes}"
+	defaultSpeedTestUploadURL   = "https://speed.cloudflare.com/__up"
+	defaultSpeedTestMegabytes   = 50
+	maxSpeedTestMegabytes       = 1000
+	// speedTestPhaseDuration limita cada fase (descarga y subida): en una
+	// conexión lenta se mide lo transferido hasta entonces
+	speedTestPhaseDuration  = 10 * time.Second
+	speedTestLatencySamples = 5
+	speedTestProgressEvery  = time.Second
+	// speedTestCLITimeout limita speedtest-cli, que elige servidor y mide
+	// durante unos 30 segundos
+	speedTestCLITimeout = 90 * time.Second
+)
+
+// speedTestBytesPlaceholder se sustituye en download_url por los bytes que
+// se quieren descargar
+const speedTestBytesPlaceholder = "{bytes}"
+
+// Backends de network_speed_test
+const (
+	speedTestHTTP = "http"
+	speedTestCLI  = "cli"
+)
+
+// speedTestConfig es el contenido de speedTestFile
+type speedTestConfig struct {
+	DownloadURL  string `json:"download_url"`
+	UploadURL    string `json:"upload_url"`
+	MaxMegabytes int    `json:"max_megabytes"`
+}
+
+// loadSpeedTestConfig lee speedTestFile y completa los valores por defecto
+func loadSpeedTestConfig() (speedTestConfig, error) {
+	var config speedTestConfig
+	if err := loadJSONConfig(speedTestFile, &config); err != nil {
+		return speedTestConfig{}, fmt.Errorf("error al leer %s: %w", speedTestFile, err)
+	}
+	if config.DownloadURL == "" {
+		config.DownloadURL = defaultSpeedTestDownloadURL
+	}
+	if config.UploadURL == "" {
+		config.UploadURL = defaultSpeedTestUploadURL
+	}
+	if config.MaxMegabytes == 0 {
+		config.MaxMegabytes = defaultSpeedTestMegabytes
+	}
+	for _, raw := range []string{config.DownloadURL, config.UploadURL} {
+		u, err := url.Parse(strings.ReplaceAll(raw, speedTestBytesPlaceholder, "0"))
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return speedTestConfig{}, fmt.Errorf("%s: URL no válida %q (debe ser http o https)", speedTestFile, raw)
+		}
+	}
+	if config.MaxMegabytes < 1 || config.MaxMegabytes > maxSpeedTestMegabytes {
+		return speedTestConfig{}, fmt.Errorf("%s: max_megabytes debe estar entre 1 y %d", speedTestFile, maxSpeedTestMegabytes)
+	}
+	return config, nil
+}
+
+type NetworkSpeedTestInput struct {
+	MaxMegabytes int    `json:"max_megabytes,omitempty" jsonschema:"Máximo de datos a transferir en MB, sumando descarga y subida (por defecto 50 o el de speedtest.json, hasta 1000)"`
+	Backend      string `json:"backend,omitempty" jsonschema:"http (por defecto: descarga y subida por HTTP desde el propio servidor) o cli (speedtest-cli, si está instalado; no respeta max_megabytes)"`
+}
+
+type NetworkSpeedTestOutput struct {
+	DownloadMbps float64 `json:"download_mbps" jsonschema:"Velocidad de descarga en Mbps"`
+	UploadMbps   float64 `json:"upload_mbps" jsonschema:"Velocidad de subida en Mbps"`
+	LatencyMs    float64 `json:"latency_ms" jsonschema:"Latencia en milisegundos (mediana de varias peticiones)"`
+	DownloadedMB float64 `json:"downloaded_mb" jsonschema:"Datos descargados en MB"`
+	UploadedMB   float64 `json:"uploaded_mb" jsonschema:"Datos subidos en MB"`
+	Server       string  `json:"server" jsonschema:"Servidor contra el que se midió"`
+	Backend      string  `json:"backend" jsonschema:"Método usado (http o cli)"`
+	Note         string  `json:"note,omitempty" jsonschema:"Aclaración sobre el resultado"`
+}
+
+// round2 redondea a dos decimales
+func round2(x float64) float64 {
+	return math.Round(x*100) / 100
+}
+
+// megabits calcula los Mbps de n bytes transferidos en elapsed
+func megabits(n int64, elapsed time.Duration) float64 {
+	if elapsed <= 0 {
+		return 0
+	}
+	return float64(n) * 8 / elapsed.Seconds() / 1e6
+}
+
+// speedTestProgress envía el progreso de la prueba en porcentaje: la
+// latencia ocupa hasta el 10 %, la descarga hasta el 55 % y la subida el
+// resto. Cada fase avanza según lo que se acerque a su límite de datos o de
+// tiempo.
+type speedTestProgress struct {
+	ctx  context.Context
+	req  *mcp.CallToolRequest
+	last float64
+}
+
+func (p *speedTestProgress) notify(progress float64, message string) {
+	// El progreso nunca retrocede
+	p.last = math.Max(p.last, math.Round(progress))
+	notifyProgress(p.ctx, p.req, p.last, message)
+}
+
+// measureTransfer ejecuta run, que suma a counter los bytes transferidos,
+// y avisa del progreso entre from y to mientras tanto. run termina al
+// llegar a budget o al agotarse speedTestPhaseDuration; el plazo agotado no
+// es un error.
+func measureTransfer(ctx context.Context, progress *speedTestProgress, label string, budget int64, from, to float64, run func(ctx context.Context, counter *atomic.Int64) error) (int64, time.Duration, error) {
+	phaseCtx, cancel := context.WithTimeout(ctx, speedTestPhaseDuration)
+	defer cancel()
+	var counter atomic.Int64
+	done := make(chan error, 1)
+	start := time.Now()
+	go func() { done <- run(phaseCtx, &counter) }()
+
+	ticker := time.NewTicker(speedTestProgressEvery)
+	defer ticker.Stop()
+	for {
+		select {
+		case err := <-done:
+			elapsed := time.Since(start)
+			if ctx.Err() != nil {
+				return 0, 0, ctx.Err()
+			}
+			if err != nil && !errors.Is(phaseCtx.Err(), context.DeadlineExceeded) {
+				return 0, 0, err
+			}
+			return counter.Load(), elapsed, nil
+		case <-ticker.C:
+			n := counter.Load()
+			elapsed := time.Since(start)
+			fraction := math.Max(float64(n)/float64(budget), elapsed.Seconds()/speedTestPhaseDuration.Seconds())
+			progress.notify(from+(to-from)*math.Min(fraction, 1), fmt.Sprintf("%s: %.1f Mbps (%.1f MB)", label, megabits(n, elapsed), float64(n)/1e6))
+		}
+	}
+}
+
+// speedTestUploadBody genera el cuerpo de la subida: un bloque aleatorio
+// repetido hasta budget bytes (o hasta que se cancele la petición)
+type speedTestUploadBody struct {
+	block     []byte
+	remaining int64
+	counter   *atomic.Int64
+}
+
+func (b *speedTestUploadBody) Read(p []byte) (int, error) {
+	if b.remaining <= 0 {
+		return 0, io.EOF
+	}
+	n := copy(p, b.block[:min(int64(len(b.block)), b.remaining)])
+	b.remaining -= int64(n)
+	b.counter.Add(int64(n))
+	return n, nil
+}
+
+// httpSpeedTest mide la latencia, la descarga y la subida con peticiones
+// HTTP, sin órdenes externas
+func httpSpeedTest(ctx context.Context, req *mcp.CallToolRequest, config speedTestConfig, limit int64) (NetworkSpeedTestOutput, error) {
+	// Sin compresión para contar los bytes que realmente cruzan la red
+	client := &http.Client{Transport: &http.Transport{Proxy: http.ProxyFromEnvironment, DisableCompression: true}}
+	defer client.CloseIdleConnections()
+	progress := &speedTestProgress{ctx: ctx, req: req}
+	downloadURL := func(n int64) string {
+		return strings.ReplaceAll(config.DownloadURL, speedTestBytesPlaceholder, strconv.FormatInt(n, 10))
+	}
+	server := config.DownloadURL
+	if u, err := url.Parse(downloadURL(0)); err == nil {
+		server = u.Host
+	}
+	out := NetworkSpeedTestOutput{Server: server, Backend: speedTestHTTP}
+
+	// Latencia: la primera petición abre la conexión (DNS, TCP y TLS) y no
+	// cuenta; las demás reutilizan la conexión y miden el tiempo hasta las
+	// cabeceras de la respuesta
+	progress.notify(0, "Midiendo la latencia con "+server)
+	method := http.MethodGet
+	if !strings.Contains(config.DownloadURL, speedTestBytesPlaceholder) {
+		method = http.MethodHead
+	}
+	var samples []time.Duration
+	for i := 0; i <= speedTestLatencySamples; i++ {
+		r, err := http.NewRequestWithContext(ctx, method, downloadURL(0), nil)
+		if err != nil {
+			return NetworkSpeedTestOutput{}, err
+		}
+		start := time.Now()
+		resp, err := client.Do(r)
+		if err != nil {
+			if ctx.Err() != nil {
+				return NetworkSpeedTestOutput{}, ctx.Err()
+			}
+			return NetworkSpeedTestOutput{}, fmt.Errorf("no se pudo conectar con %s: %w", server, err)
+		}
+		elapsed := time.Since(start)
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode >= 400 {
+			return NetworkSpeedTestOutput{}, fmt.Errorf("%s respondió %s", server, resp.Status)
+		}
+		if i > 0 {
+			samples = append(samples, elapsed)
+		}
+	}
+	slices.Sort(samples)
+	out.LatencyMs = round2(float64(samples[len(samples)/2].Microseconds()) / 1000)
+	progress.notify(10, fmt.Sprintf("Latencia: %.1f ms", out.LatencyMs))
+
+	// La mitad del límite para la descarga y la otra mitad para la subida
+	budget := limit / 2
+	downloaded, elapsed, err := measureTransfer(ctx, progress, "Descarga", budget, 10, 55, func(ctx context.Context, counter *atomic.Int64) error {
+		r, err := http.NewRequestWithContext(ctx, http.MethodGet, downloadURL(budget), nil)
+		if err != nil {
+			return err
+		}
+		resp, err := client.Do(r)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("la descarga respondió %s", resp.Status)
+		}
+		buf := make([]byte, 64*1024)
+		body := io.LimitReader(resp.Body, budget)
+		for {
+			n, err := body.Read(buf)
+			counter.Add(int64(n))
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+		}
+	})
+	if err != nil {
+		return NetworkSpeedTestOutput{}, fmt.Errorf("error en la descarga: %w", err)
+	}
+	if downloaded == 0 {
+		return NetworkSpeedTestOutput{}, fmt.Errorf("no se recibió ningún dato de %s en %s", server, speedTestPhaseDuration)
+	}
+	out.DownloadMbps = round2(megabits(downloaded, elapsed))
+	out.DownloadedMB = round2(float64(downloaded) / 1e6)
+
+	block := make([]byte, 64*1024)
+	for i := range block {
+		block[i] = byte(rand.IntN(256))
+	}
+	uploaded, elapsed, err := measureTransfer(ctx, progress, "Subida", budget, 55, 100, func(ctx context.Context, counter *atomic.Int64) error {
+		// Sin ContentLength la subida va por trozos y se puede cortar al
+		// agotarse el plazo
+		body := io.NopCloser(&speedTestUploadBody{block: block, remaining: budget, counter: counter})
+		r, err := http.NewRequestWithContext(ctx, http.MethodPost, config.UploadURL, body)
+		if err != nil {
+			return err
+		}
+		r.Header.Set("Content-Type", "application/octet-stream")
+		resp, err := client.Do(r)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		io.Copy(io.Discard, resp.Body)
+		if resp.StatusCode >= 400 {
+			return fmt.Errorf("la subida respondió %s", resp.Status)
+		}
+		return nil
+	})
+	if err != nil {
+		return NetworkSpeedTestOutput{}, fmt.Errorf("error en la subida: %w", err)
+	}
+	out.UploadMbps = round2(megabits(uploaded, elapsed))
+	out.UploadedMB = round2(float64(uploaded) / 1e6)
+	progress.notify(100, "Prueba terminada")
+	return out, nil
+}
+
+// cliSpeedTest ejecuta speedtest-cli --json, que no permite limitar los
+// datos ni informa del progreso: mientras tanto se avisa del tiempo
+// transcurrido
+func cliSpeedTest(ctx context.Context, req *mcp.CallToolRequest) (NetworkSpeedTestOutput, error) {
+	if !commandExists("speedtest-cli")() {
+		return NetworkSpeedTestOutput{}, errors.New("speedtest-cli no está instalado (pip install speedtest-cli o el paquete de la distribución); usa backend http")
+	}
+	ctx, cancel := context.WithTimeout(ctx, speedTestCLITimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "speedtest-cli", "--json", "--secure")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Start(); err != nil {
+		return NetworkSpeedTestOutput{}, fmt.Errorf("error al ejecutar speedtest-cli: %w", err)
+	}
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+	ticker := time.NewTicker(5 * speedTestProgressEvery)
+	defer ticker.Stop()
+	start := time.Now()
+	notifyProgress(ctx, req, 0, "Ejecutando speedtest-cli; tarda unos 30 segundos")
+	var err error
+wait:
+	for {
+		select {
+		case err = <-done:
+			break wait
+		case <-ticker.C:
+			elapsed := time.Since(start).Round(time.Second)
+			notifyProgress(ctx, req, elapsed.Seconds(), fmt.Sprintf("Ejecutando speedtest-cli (%s)", elapsed))
+		}
+	}
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return NetworkSpeedTestOutput{}, fmt.Errorf("speedtest-cli no terminó en %s", speedTestCLITimeout)
+	}
+	if ctx.Err() != nil {
+		return NetworkSpeedTestOutput{}, ctx.Err()
+	}
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return NetworkSpeedTestOutput{}, fmt.Errorf("speedtest-cli falló: %s", msg)
+		}
+		return NetworkSpeedTestOutput{}, fmt.Errorf("speedtest-cli falló: %w", err)
+	}
+
+	// download y upload en bits por segundo, ping en milisegundos
+	var result struct {
+		Download      float64 `json:"download"`
+		Upload        float64 `json:"upload"`
+		Ping          float64 `json:"ping"`
+		BytesSent     int64   `json:"bytes_sent"`
+		BytesReceived int64   `json:"bytes_received"`
+		Server        struct {
+			Sponsor string `json:"sponsor"`
+			Name    string `json:"name"`
+		} `json:"server"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &result); err != nil {
+		return NetworkSpeedTestOutput{}, fmt.Errorf("respuesta inesperada de speedtest-cli: %w", err)
+	}
+	return NetworkSpeedTestOutput{
+		DownloadMbps: round2(result.Download / 1e6),
+		UploadMbps:   round2(result.Upload / 1e6),
+		LatencyMs:    round2(result.Ping),
+		DownloadedMB: round2(float64(result.BytesReceived) / 1e6),
+		UploadedMB:   round2(float64(result.BytesSent) / 1e6),
+		Server:       strings.TrimSpace(result.Server.Sponsor + " (" + result.Server.Name + ")"),
+		Backend:      speedTestCLI,
+		Note:         "speedtest-cli no permite limitar los datos transferidos",
+	}, nil
+}
+
+// networkSpeedTest elige el método y aplica el límite de datos
+func networkSpeedTest(ctx context.Context, req *mcp.CallToolRequest, input NetworkSpeedTestInput) (NetworkSpeedTestOutput, error) {
+	backend := strings.ToLower(strings.TrimSpace(input.Backend))
+	switch backend {
+	case "", speedTestHTTP:
+	case speedTestCLI:
+		return cliSpeedTest(ctx, req)
+	default:
+		return NetworkSpeedTestOutput{}, fmt.Errorf("backend no válido %q: usa http o cli", input.Backend)
+	}
+	config, err := loadSpeedTestConfig()
+	if err != nil {
+		return NetworkSpeedTestOutput{}, err
+	}
+	megabytes := config.MaxMegabytes
+	if input.MaxMegabytes > 0 {
+		megabytes = min(input.MaxMegabytes, maxSpeedTestMegabytes)
+	}
+	return httpSpeedTest(ctx, req, config, int64(megabytes)*1_000_000)
+}
+
+func HandleNetworkSpeedTest(ctx context.Context, req *mcp.CallToolRequest, input NetworkSpeedTestInput) (*mcp.CallToolResult, NetworkSpeedTestOutput, error) {
+	out, err := networkSpeedTest(ctx, req, input)
+	if err != nil {
+		return nil, NetworkSpeedTestOutput{}, fmt.Errorf("❌ Error en la prueba de velocidad: %w", err)
+	}
+
+	lines := []string{
+		fmt.Sprintf("🚀 Prueba de velocidad con %s:", out.Server),
+		fmt.Sprintf("  ⬇️ Descarga: %.2f Mbps (%.1f MB)", out.DownloadMbps, out.DownloadedMB),
+		fmt.Sprintf("  ⬆️ Subida: %.2f Mbps (%.1f MB)", out.UploadMbps, out.UploadedMB),
+		fmt.Sprintf("  ⏱️ Latencia: %.1f ms", out.LatencyMs),
+	}
+	if out.Note != "" {
+		lines = append(lines, "ℹ️ "+out.Note)
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: strings.Join(lines, "\n")},
+		},
+	}, out, nil
+}
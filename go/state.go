@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+)
+
+// serverStateFile guarda, en el directorio de configuración, lo que el
+// servidor tiene que recordar entre reinicios para deshacer un cambio
+const serverStateFile = "state.json"
+
+// serverState es el contenido de serverStateFile
+type serverState struct {
+	// AirplaneRadios son las radios que estaban encendidas al activar el
+	// modo avión, las únicas que se vuelven a encender al desactivarlo.
+	// nil si el modo avión no se activó con set_airplane_mode.
+	AirplaneRadios []string `json:"airplane_radios"`
+}
+
+// serverStateMu serializa las lecturas y escrituras de serverStateFile
+var serverStateMu sync.Mutex
+
+// loadServerState lee serverStateFile; sin fichero devuelve el estado vacío
+func loadServerState() (serverState, error) {
+	serverStateMu.Lock()
+	defer serverStateMu.Unlock()
+	var state serverState
+	if err := loadJSONConfig(serverStateFile, &state); err != nil {
+		return serverState{}, fmt.Errorf("error al leer %s: %w", serverStateFile, err)
+	}
+	return state, nil
+}
+
+// updateServerState lee serverStateFile, aplica update y lo guarda
+func updateServerState(update func(*serverState)) error {
+	serverStateMu.Lock()
+	defer serverStateMu.Unlock()
+	var state serverState
+	if err := loadJSONConfig(serverStateFile, &state); err != nil {
+		return fmt.Errorf("error al leer %s: %w", serverStateFile, err)
+	}
+	update(&state)
+	if err := saveJSONConfig(serverStateFile, state); err != nil {
+		return fmt.Errorf("error al guardar %s: %w", serverStateFile, err)
+	}
+	return nil
+}
@@ -0,0 +1,16 @@
+//go:build !windows
+
+package main
+
+import "syscall"
+
+// statfsUsage devuelve el tamaño total, el espacio libre y el disponible
+// para usuarios sin privilegios (en bytes) del sistema de ficheros de path
+func statfsUsage(path string) (total, free, avail uint64, err error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return 0, 0, 0, err
+	}
+	size := uint64(st.Bsize)
+	return uint64(st.Blocks) * size, uint64(st.Bfree) * size, uint64(st.Bavail) * size, nil
+}
@@ -0,0 +1,9 @@
+package main
+
+import "errors"
+
+// statfsUsage no se usa en Windows, donde las unidades se consultan con
+// Win32_LogicalDisk
+func statfsUsage(path string) (total, free, avail uint64, err error) {
+	return 0, 0, 0, errors.New("statfs no está disponible en Windows")
+}
@@ -0,0 +1,357 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// systemInfoResourceURI es el recurso MCP con los mismos datos que get_system_info
+const systemInfoResourceURI = "hardware-control://system-info"
+
+type SystemInfoOutput struct {
+	OS               string `json:"os" jsonschema:"Sistema operativo según Go (linux, darwin, windows)"`
+	Arch             string `json:"arch" jsonschema:"Arquitectura según Go (amd64, arm64...)"`
+	Platform         string `json:"platform" jsonschema:"Plataforma efectiva del servidor: linux, darwin, windows o wsl"`
+	OSName           string `json:"os_name,omitempty" jsonschema:"Nombre de la distribución o edición (ej: 'Ubuntu', 'macOS', 'Microsoft Windows 11 Pro')"`
+	OSVersion        string `json:"os_version,omitempty" jsonschema:"Versión del sistema (ej: '24.04', '14.5', '23H2')"`
+	OSBuild          string `json:"os_build,omitempty" jsonschema:"Compilación del sistema en macOS y Windows"`
+	Kernel           string `json:"kernel,omitempty" jsonschema:"Versión del núcleo"`
+	Hostname         string `json:"hostname,omitempty" jsonschema:"Nombre del equipo"`
+	Username         string `json:"username,omitempty" jsonschema:"Usuario con el que se ejecuta el servidor"`
+	WSL              bool   `json:"wsl" jsonschema:"El servidor se ejecuta en WSL y controla el Windows anfitrión"`
+	Virtualization   string `json:"virtualization,omitempty" jsonschema:"Hipervisor si es una máquina virtual (kvm, qemu, vmware, virtualbox, microsoft, xen, wsl, other...)"`
+	Container        string `json:"container,omitempty" jsonschema:"Tipo de contenedor si se ejecuta en uno (docker, podman, lxc, kubernetes...)"`
+	GraphicalSession bool   `json:"graphical_session" jsonschema:"Hay una sesión gráfica, necesaria para las herramientas de pantallas y ventanas"`
+	SessionType      string `json:"session_type,omitempty" jsonschema:"En Linux, x11 o wayland"`
+	Desktop          string `json:"desktop,omitempty" jsonschema:"En Linux, el escritorio según XDG_CURRENT_DESKTOP"`
+	CPUCount         int    `json:"cpu_count" jsonschema:"Número de CPU lógicas"`
+	MemoryTotalKB    int64  `json:"memory_total_kb,omitempty" jsonschema:"Memoria total en KB"`
+	UptimeSeconds    int64  `json:"uptime_seconds,omitempty" jsonschema:"Segundos desde el arranque"`
+	Note             string `json:"note,omitempty" jsonschema:"Datos que no se pudieron obtener o aclaraciones (p. ej. en WSL)"`
+}
+
+// getSystemInfo reúne los datos del sistema con la biblioteca estándar
+// siempre que se puede; cada dato que falta se deja vacío sin que falle la
+// consulta. En WSL son los de la distribución Linux, no los del anfitrión.
+func getSystemInfo() SystemInfoOutput {
+	info := SystemInfoOutput{
+		OS:               runtime.GOOS,
+		Arch:             runtime.GOARCH,
+		Platform:         currentPlatform(),
+		WSL:              isWSL(),
+		GraphicalSession: checkGraphicalSession() == nil,
+		CPUCount:         runtime.NumCPU(),
+	}
+	info.Hostname, _ = os.Hostname()
+	if u, err := user.Current(); err == nil {
+		info.Username = u.Username
+	}
+
+	switch info.Platform {
+	case platformWindows:
+		systemInfoWindows(&info)
+	case platformMac:
+		systemInfoMac(&info)
+	default:
+		systemInfoLinux(&info)
+	}
+	return info
+}
+
+// readOSRelease lee /etc/os-release (o /usr/lib/os-release), líneas
+// CLAVE=valor con el valor opcionalmente entre comillas
+func readOSRelease() map[string]string {
+	values := map[string]string{}
+	data, err := os.ReadFile("/etc/os-release")
+	if err != nil {
+		data, _ = os.ReadFile("/usr/lib/os-release")
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		if unquoted, err := strconv.Unquote(value); err == nil {
+			value = unquoted
+		}
+		values[key] = strings.Trim(value, `'"`)
+	}
+	return values
+}
+
+// virtualizationVendors reconoce el hipervisor por el fabricante y el modelo
+// que expone el firmware (DMI en Linux, Win32_ComputerSystem en Windows). Se
+// usan los nombres de systemd-detect-virt.
+var virtualizationVendors = []struct{ Match, Name string }{
+	{"kvm", "kvm"}, {"qemu", "qemu"}, {"vmware", "vmware"},
+	{"virtualbox", "virtualbox"}, {"innotek", "virtualbox"}, {"xen", "xen"},
+	{"parallels", "parallels"}, {"bochs", "bochs"}, {"bhyve", "bhyve"},
+	{"amazon ec2", "amazon"}, {"google compute engine", "google"},
+	{"virtual machine", "microsoft"},
+}
+
+func virtualizationFromVendor(vendor, product string) string {
+	text := strings.ToLower(vendor + " " + product)
+	for _, v := range virtualizationVendors {
+		if strings.Contains(text, v.Match) {
+			return v.Name
+		}
+	}
+	return ""
+}
+
+// readTrimmedFile lee un fichero pequeño (sysfs, procfs) sin el salto final
+func readTrimmedFile(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// virtualizationLinux mira primero DMI y, si no dice nada, /proc/xen y la
+// marca hypervisor de /proc/cpuinfo, que indica que hay hipervisor pero no cuál
+func virtualizationLinux() string {
+	if isWSL() {
+		return "wsl"
+	}
+	vendor := readTrimmedFile("/sys/class/dmi/id/sys_vendor")
+	product := readTrimmedFile("/sys/class/dmi/id/product_name")
+	if name := virtualizationFromVendor(vendor, product); name != "" {
+		return name
+	}
+	if _, err := os.Stat("/proc/xen"); err == nil {
+		return "xen"
+	}
+	file, err := os.Open("/proc/cpuinfo")
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), ":")
+		if ok && strings.TrimSpace(key) == "flags" {
+			if strings.Contains(" "+value+" ", " hypervisor ") {
+				return "other"
+			}
+			break
+		}
+	}
+	return ""
+}
+
+// containerLinux detecta contenedores como systemd-detect-virt: la variable
+// container (systemd-nspawn, podman, lxc), los ficheros que crean podman y
+// Docker, y el cgroup del proceso 1
+func containerLinux() string {
+	if name := os.Getenv("container"); name != "" {
+		return name
+	}
+	if _, err := os.Stat("/run/.containerenv"); err == nil {
+		return "podman"
+	}
+	if _, err := os.Stat("/.dockerenv"); err == nil {
+		return "docker"
+	}
+	cgroup := readTrimmedFile("/proc/1/cgroup")
+	for _, c := range []struct{ Match, Name string }{{"kubepods", "kubernetes"}, {"docker", "docker"}, {"lxc", "lxc"}} {
+		if strings.Contains(cgroup, c.Match) {
+			return c.Name
+		}
+	}
+	return ""
+}
+
+func systemInfoLinux(info *SystemInfoOutput) {
+	release := readOSRelease()
+	info.OSName = release["NAME"]
+	info.OSVersion = release["VERSION_ID"]
+	info.Kernel = readTrimmedFile("/proc/sys/kernel/osrelease")
+	info.Virtualization = virtualizationLinux()
+	info.Container = containerLinux()
+
+	session := detectLinuxSession()
+	if info.GraphicalSession {
+		info.SessionType = "x11"
+		if session.Wayland {
+			info.SessionType = "wayland"
+		}
+	}
+	info.Desktop = session.Desktop
+
+	// "MemTotal:       16318504 kB"
+	if file, err := os.Open("/proc/meminfo"); err == nil {
+		defer file.Close()
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			if value, ok := strings.CutPrefix(scanner.Text(), "MemTotal:"); ok {
+				info.MemoryTotalKB, _ = strconv.ParseInt(strings.TrimSuffix(strings.TrimSpace(value), " kB"), 10, 64)
+				break
+			}
+		}
+	}
+	if uptime, err := uptimeLinux(); err == nil {
+		info.UptimeSeconds = int64(uptime / time.Second)
+	}
+	if info.WSL {
+		info.Note = "Datos de la distribución de WSL; las pantallas, el audio y la energía se controlan en el Windows anfitrión"
+	}
+}
+
+// systemInfoMac usa sw_vers y sysctl: la biblioteca estándar solo da acceso
+// a estos datos con syscall.Sysctl, que no existe en las demás plataformas
+func systemInfoMac(info *SystemInfoOutput) {
+	swVers := func(flag string) string {
+		output, err := exec.Command("sw_vers", flag).Output()
+		if err != nil {
+			return ""
+		}
+		return strings.TrimSpace(string(output))
+	}
+	sysctl := func(name string) string {
+		output, err := exec.Command("sysctl", "-n", name).Output()
+		if err != nil {
+			return ""
+		}
+		return strings.TrimSpace(string(output))
+	}
+	info.OSName = swVers("-productName")
+	info.OSVersion = swVers("-productVersion")
+	info.OSBuild = swVers("-buildVersion")
+	info.Kernel = sysctl("kern.osrelease")
+	if memsize, err := strconv.ParseInt(sysctl("hw.memsize"), 10, 64); err == nil {
+		info.MemoryTotalKB = memsize / 1024
+	}
+	// kern.hv_vmm_present vale 1 dentro de una máquina virtual
+	if sysctl("kern.hv_vmm_present") == "1" {
+		info.Virtualization = virtualizationFromVendor(sysctl("hw.model"), "")
+		if info.Virtualization == "" {
+			info.Virtualization = "other"
+		}
+	}
+	if uptime, err := uptimeMac(); err == nil {
+		info.UptimeSeconds = int64(uptime / time.Second)
+	}
+}
+
+// windowsSystemInfoScript consulta Win32_OperatingSystem y
+// Win32_ComputerSystem. DisplayVersion ("23H2") solo está en el registro.
+const windowsSystemInfoScript = `$os = Get-CimInstance Win32_OperatingSystem
+$cs = Get-CimInstance Win32_ComputerSystem
+$release = Get-ItemProperty 'HKLM:\SOFTWARE\Microsoft\Windows NT\CurrentVersion' -ErrorAction SilentlyContinue
+ConvertTo-Json -Compress @{
+	caption = [string]$os.Caption
+	version = [string]$os.Version
+	build = [string]$os.BuildNumber
+	displayVersion = [string]$release.DisplayVersion
+	memory = [long]$cs.TotalPhysicalMemory
+	uptime = [long]((Get-Date) - $os.LastBootUpTime).TotalSeconds
+	manufacturer = [string]$cs.Manufacturer
+	model = [string]$cs.Model
+}`
+
+func systemInfoWindows(info *SystemInfoOutput) {
+	output, err := runPowerShell(windowsSystemInfoScript)
+	var result struct {
+		Caption, Version, Build, DisplayVersion string
+		Memory, Uptime                          int64
+		Manufacturer, Model                     string
+	}
+	if err == nil {
+		err = json.Unmarshal([]byte(output), &result)
+	}
+	if err != nil {
+		info.Note = fmt.Sprintf("No se pudo consultar la versión de Windows: %v", err)
+		return
+	}
+	info.OSName = result.Caption
+	info.OSVersion = result.DisplayVersion
+	info.OSBuild = result.Build
+	info.Kernel = result.Version
+	info.MemoryTotalKB = result.Memory / 1024
+	info.UptimeSeconds = result.Uptime
+	info.Virtualization = virtualizationFromVendor(result.Manufacturer, result.Model)
+}
+
+func HandleGetSystemInfo(ctx context.Context, req *mcp.CallToolRequest, input struct{}) (*mcp.CallToolResult, SystemInfoOutput, error) {
+	info := getSystemInfo()
+
+	system := strings.TrimSpace(info.OSName + " " + info.OSVersion)
+	if system == "" {
+		system = info.OS
+	}
+	if info.OSBuild != "" {
+		system += fmt.Sprintf(" (compilación %s)", info.OSBuild)
+	}
+	lines := []string{fmt.Sprintf("💻 %s, %s", system, info.Arch)}
+	if info.Kernel != "" {
+		lines = append(lines, "🧩 Núcleo: "+info.Kernel)
+	}
+	lines = append(lines, fmt.Sprintf("🏷️ Equipo: %s, usuario: %s", info.Hostname, info.Username))
+	hardware := fmt.Sprintf("⚙️ %d CPU", info.CPUCount)
+	if info.MemoryTotalKB > 0 {
+		hardware += ", " + formatKB(info.MemoryTotalKB) + " de memoria"
+	}
+	lines = append(lines, hardware)
+	if info.UptimeSeconds > 0 {
+		lines = append(lines, "⏱️ Encendido desde hace "+humanizeDuration(time.Duration(info.UptimeSeconds)*time.Second))
+	}
+
+	var environment []string
+	switch info.Virtualization {
+	case "":
+	case "other":
+		environment = append(environment, "máquina virtual (hipervisor desconocido)")
+	default:
+		environment = append(environment, "máquina virtual "+info.Virtualization)
+	}
+	if info.Container != "" {
+		environment = append(environment, "contenedor "+info.Container)
+	}
+	switch {
+	case !info.GraphicalSession:
+		environment = append(environment, "sin sesión gráfica")
+	case info.SessionType != "" && info.Desktop != "":
+		environment = append(environment, fmt.Sprintf("sesión %s (%s)", info.SessionType, info.Desktop))
+	case info.SessionType != "":
+		environment = append(environment, "sesión "+info.SessionType)
+	}
+	if len(environment) > 0 {
+		lines = append(lines, "📦 "+strings.Join(environment, ", "))
+	}
+	if info.Note != "" {
+		lines = append(lines, "ℹ️ "+info.Note)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: strings.Join(lines, "\n")},
+		},
+	}, info, nil
+}
+
+// HandleSystemInfoResource sirve los datos de get_system_info como recurso,
+// para que el cliente los tenga como contexto sin llamar a la herramienta
+func HandleSystemInfoResource(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	data, err := json.MarshalIndent(getSystemInfo(), "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("❌ Error al generar la información del sistema: %w", err)
+	}
+	return &mcp.ReadResourceResult{
+		Contents: []*mcp.ResourceContents{
+			{URI: req.Params.URI, MIMEType: "application/json", Text: string(data)},
+		},
+	}, nil
+}
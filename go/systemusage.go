@@ -0,0 +1,265 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// cpuSampleInterval es el tiempo entre las dos lecturas de /proc/stat con
+// las que se calcula el uso de CPU en Linux
+const cpuSampleInterval = 500 * time.Millisecond
+
+type SystemUsageOutput struct {
+	CPUPercent    float64   `json:"cpu_percent" jsonschema:"Uso de CPU en porcentaje (todos los núcleos)"`
+	LoadAverage   []float64 `json:"load_average,omitempty" jsonschema:"Carga media de 1, 5 y 15 minutos (no existe en Windows)"`
+	MemoryTotalKB int64     `json:"memory_total_kb" jsonschema:"Memoria total en KB"`
+	MemoryUsedKB  int64     `json:"memory_used_kb" jsonschema:"Memoria en uso en KB"`
+	MemoryFreeKB  int64     `json:"memory_free_kb" jsonschema:"Memoria disponible para las aplicaciones en KB"`
+	SwapTotalKB   int64     `json:"swap_total_kb" jsonschema:"Swap o fichero de paginación total en KB"`
+	SwapUsedKB    int64     `json:"swap_used_kb" jsonschema:"Swap en uso en KB"`
+	SwapFreeKB    int64     `json:"swap_free_kb" jsonschema:"Swap libre en KB"`
+	Note          string    `json:"note,omitempty" jsonschema:"Aclaración sobre el origen de los datos (p. ej. en WSL)"`
+}
+
+// formatKB da una cantidad en KB en MB o GB
+func formatKB(kb int64) string {
+	if kb >= 1024*1024 {
+		return fmt.Sprintf("%.1f GB", float64(kb)/(1024*1024))
+	}
+	return fmt.Sprintf("%.1f MB", float64(kb)/1024)
+}
+
+// getSystemUsage consulta el uso de CPU, la carga y la memoria. En WSL se
+// leen los valores de /proc, que son los de la máquina virtual de WSL.
+func getSystemUsage() (SystemUsageOutput, error) {
+	switch currentPlatform() {
+	case platformWindows:
+		return systemUsageWindows()
+	case platformMac:
+		return systemUsageMac()
+	case platformWSL:
+		usage, err := systemUsageLinux()
+		usage.Note = "Valores de la máquina virtual de WSL, no del Windows anfitrión"
+		return usage, err
+	}
+	return systemUsageLinux()
+}
+
+// readCPUTimes devuelve el tiempo total y el ocioso (idle + iowait) de la
+// línea "cpu" de /proc/stat
+func readCPUTimes() (total, idle uint64, err error) {
+	data, err := os.ReadFile("/proc/stat")
+	if err != nil {
+		return 0, 0, fmt.Errorf("error al leer /proc/stat: %w", err)
+	}
+	line, _, _ := strings.Cut(string(data), "\n")
+	fields := strings.Fields(line)
+	if len(fields) < 5 || fields[0] != "cpu" {
+		return 0, 0, errors.New("formato de /proc/stat no válido")
+	}
+	// user nice system idle iowait irq softirq steal (guest ya va incluido en user)
+	for i, field := range fields[1:] {
+		if i >= 8 {
+			break
+		}
+		value, _ := strconv.ParseUint(field, 10, 64)
+		total += value
+		if i == 3 || i == 4 {
+			idle += value
+		}
+	}
+	return total, idle, nil
+}
+
+func systemUsageLinux() (SystemUsageOutput, error) {
+	var usage SystemUsageOutput
+	total1, idle1, err := readCPUTimes()
+	if err != nil {
+		return usage, err
+	}
+	time.Sleep(cpuSampleInterval)
+	total2, idle2, err := readCPUTimes()
+	if err != nil {
+		return usage, err
+	}
+	if total2 > total1 {
+		usage.CPUPercent = 100 * float64((total2-total1)-(idle2-idle1)) / float64(total2-total1)
+	}
+
+	// "0.52 0.58 0.59 1/1234 5678"
+	if data, err := os.ReadFile("/proc/loadavg"); err == nil && len(strings.Fields(string(data))) >= 3 {
+		for _, field := range strings.Fields(string(data))[:3] {
+			load, _ := strconv.ParseFloat(field, 64)
+			usage.LoadAverage = append(usage.LoadAverage, load)
+		}
+	}
+
+	// "MemTotal:       16318504 kB"
+	file, err := os.Open("/proc/meminfo")
+	if err != nil {
+		return usage, fmt.Errorf("error al leer /proc/meminfo: %w", err)
+	}
+	defer file.Close()
+	meminfo := map[string]int64{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		name, value, ok := strings.Cut(scanner.Text(), ":")
+		if ok {
+			meminfo[name], _ = strconv.ParseInt(strings.TrimSuffix(strings.TrimSpace(value), " kB"), 10, 64)
+		}
+	}
+	available, ok := meminfo["MemAvailable"]
+	if !ok {
+		// Núcleos anteriores a 3.14
+		available = meminfo["MemFree"] + meminfo["Buffers"] + meminfo["Cached"]
+	}
+	usage.MemoryTotalKB, usage.MemoryFreeKB = meminfo["MemTotal"], available
+	usage.MemoryUsedKB = usage.MemoryTotalKB - available
+	usage.SwapTotalKB, usage.SwapFreeKB = meminfo["SwapTotal"], meminfo["SwapFree"]
+	usage.SwapUsedKB = usage.SwapTotalKB - usage.SwapFreeKB
+	return usage, nil
+}
+
+var (
+	// "CPU usage: 5.12% user, 10.25% sys, 84.62% idle"
+	macCPUIdleRegexp = regexp.MustCompile(`CPU usage:.*?([\d.]+)% idle`)
+	// "Pages active:                           123456."
+	macVMStatRegexp = regexp.MustCompile(`(?m)^(.+?):\s+(\d+)\.$`)
+	// "(page size of 16384 bytes)"
+	macPageSizeRegexp = regexp.MustCompile(`page size of (\d+) bytes`)
+	// "total = 2048.00M  used = 1024.00M  free = 1024.00M  (encrypted)"
+	macSwapRegexp = regexp.MustCompile(`total = ([\d.]+)M\s+used = ([\d.]+)M\s+free = ([\d.]+)M`)
+)
+
+// systemUsageMac calcula la memoria en uso como en el Monitor de Actividad:
+// páginas activas, fijas y comprimidas
+func systemUsageMac() (SystemUsageOutput, error) {
+	var usage SystemUsageOutput
+	output, err := exec.Command("top", "-l", "1", "-n", "0").Output()
+	if err != nil {
+		return usage, fmt.Errorf("error al ejecutar top: %w", err)
+	}
+	if m := macCPUIdleRegexp.FindStringSubmatch(string(output)); m != nil {
+		idle, _ := strconv.ParseFloat(m[1], 64)
+		usage.CPUPercent = 100 - idle
+	}
+
+	// "{ 1.23 1.45 1.67 }"
+	if output, err := exec.Command("sysctl", "-n", "vm.loadavg").Output(); err == nil {
+		for _, field := range strings.Fields(strings.Trim(strings.TrimSpace(string(output)), "{}")) {
+			load, _ := strconv.ParseFloat(field, 64)
+			usage.LoadAverage = append(usage.LoadAverage, load)
+		}
+	}
+
+	output, err = exec.Command("sysctl", "-n", "hw.memsize").Output()
+	if err != nil {
+		return usage, fmt.Errorf("error al ejecutar sysctl hw.memsize: %w", err)
+	}
+	memsize, _ := strconv.ParseInt(strings.TrimSpace(string(output)), 10, 64)
+	usage.MemoryTotalKB = memsize / 1024
+
+	output, err = exec.Command("vm_stat").Output()
+	if err != nil {
+		return usage, fmt.Errorf("error al ejecutar vm_stat: %w", err)
+	}
+	pageSize := int64(4096)
+	if m := macPageSizeRegexp.FindStringSubmatch(string(output)); m != nil {
+		pageSize, _ = strconv.ParseInt(m[1], 10, 64)
+	}
+	pages := map[string]int64{}
+	for _, m := range macVMStatRegexp.FindAllStringSubmatch(string(output), -1) {
+		pages[m[1]], _ = strconv.ParseInt(m[2], 10, 64)
+	}
+	used := pages["Pages active"] + pages["Pages wired down"] + pages["Pages occupied by compressor"]
+	usage.MemoryUsedKB = used * pageSize / 1024
+	usage.MemoryFreeKB = usage.MemoryTotalKB - usage.MemoryUsedKB
+
+	if output, err := exec.Command("sysctl", "-n", "vm.swapusage").Output(); err == nil {
+		if m := macSwapRegexp.FindStringSubmatch(string(output)); m != nil {
+			values := make([]int64, 3)
+			for i := range values {
+				mb, _ := strconv.ParseFloat(m[i+1], 64)
+				values[i] = int64(mb * 1024)
+			}
+			usage.SwapTotalKB, usage.SwapUsedKB, usage.SwapFreeKB = values[0], values[1], values[2]
+		}
+	}
+	return usage, nil
+}
+
+// windowsSystemUsageScript consulta CIM en lugar de Get-Counter o typeperf,
+// cuyas rutas de contador están traducidas al idioma del sistema
+const windowsSystemUsageScript = `$cpu = (Get-CimInstance Win32_PerfFormattedData_PerfOS_Processor -Filter "Name='_Total'").PercentProcessorTime
+$os = Get-CimInstance Win32_OperatingSystem
+$swap = @(Get-CimInstance Win32_PageFileUsage)
+ConvertTo-Json -Compress @{
+	cpu = [double]$cpu
+	total = [long]$os.TotalVisibleMemorySize
+	free = [long]$os.FreePhysicalMemory
+	swapTotal = [long](($swap | Measure-Object AllocatedBaseSize -Sum).Sum * 1024)
+	swapUsed = [long](($swap | Measure-Object CurrentUsage -Sum).Sum * 1024)
+}`
+
+func systemUsageWindows() (SystemUsageOutput, error) {
+	output, err := runPowerShell(windowsSystemUsageScript)
+	if err != nil {
+		return SystemUsageOutput{}, fmt.Errorf("error al consultar el uso del sistema: %w", err)
+	}
+	var result struct {
+		CPU                 float64
+		Total, Free         int64
+		SwapTotal, SwapUsed int64
+	}
+	if err := json.Unmarshal([]byte(output), &result); err != nil {
+		return SystemUsageOutput{}, fmt.Errorf("respuesta de PowerShell no válida: %w", err)
+	}
+	return SystemUsageOutput{
+		CPUPercent:    result.CPU,
+		MemoryTotalKB: result.Total,
+		MemoryUsedKB:  result.Total - result.Free,
+		MemoryFreeKB:  result.Free,
+		SwapTotalKB:   result.SwapTotal,
+		SwapUsedKB:    result.SwapUsed,
+		SwapFreeKB:    result.SwapTotal - result.SwapUsed,
+	}, nil
+}
+
+func HandleGetSystemUsage(ctx context.Context, req *mcp.CallToolRequest, input struct{}) (*mcp.CallToolResult, SystemUsageOutput, error) {
+	usage, err := getSystemUsage()
+	if err != nil {
+		return nil, SystemUsageOutput{}, fmt.Errorf("❌ Error al obtener el uso del sistema: %w", err)
+	}
+	lines := []string{fmt.Sprintf("📊 CPU: %.1f%%", usage.CPUPercent)}
+	if len(usage.LoadAverage) == 3 {
+		lines[0] += fmt.Sprintf(" (carga %.2f %.2f %.2f)", usage.LoadAverage[0], usage.LoadAverage[1], usage.LoadAverage[2])
+	}
+	if usage.MemoryTotalKB > 0 {
+		lines = append(lines, fmt.Sprintf("🧠 Memoria: %s de %s en uso (%.0f%%), %s disponibles",
+			formatKB(usage.MemoryUsedKB), formatKB(usage.MemoryTotalKB), 100*float64(usage.MemoryUsedKB)/float64(usage.MemoryTotalKB), formatKB(usage.MemoryFreeKB)))
+	}
+	if usage.SwapTotalKB > 0 {
+		lines = append(lines, fmt.Sprintf("💾 Swap: %s de %s en uso", formatKB(usage.SwapUsedKB), formatKB(usage.SwapTotalKB)))
+	} else {
+		lines = append(lines, "💾 Swap: sin swap")
+	}
+	if usage.Note != "" {
+		lines = append(lines, "ℹ️ "+usage.Note)
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: strings.Join(lines, "\n")},
+		},
+	}, usage, nil
+}
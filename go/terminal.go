@@ -0,0 +1,190 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// linuxTerminals son los emuladores de terminal que se prueban en Linux, por
+// orden de preferencia
+var linuxTerminals = []string{"x-terminal-emulator", "gnome-terminal", "konsole", "alacritty"}
+
+// splitCommandLine separa una orden en argumentos por espacios, respetando
+// las comillas simples y dobles. No interpreta nada más: ni variables, ni
+// redirecciones, ni tuberías.
+func splitCommandLine(command string) ([]string, error) {
+	var args []string
+	var current strings.Builder
+	inArg := false
+	var quote rune
+	for _, r := range command {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote, inArg = r, true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("falta cerrar las comillas %c en la orden", quote)
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
+
+// shellQuote entrecomilla un argumento para sh con comillas simples
+func shellQuote(arg string) string {
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
+
+// openTerminal abre una ventana de terminal en workingDir y, si se indica,
+// ejecuta en ella command
+func openTerminal(command, workingDir string) (string, error) {
+	argv, err := splitCommandLine(command)
+	if err != nil {
+		return "", err
+	}
+	if workingDir == "" {
+		if workingDir, err = os.UserHomeDir(); err != nil {
+			return "", fmt.Errorf("no se pudo obtener el directorio personal: %w", err)
+		}
+	}
+	workingDir = expandHome(workingDir)
+	info, err := os.Stat(workingDir)
+	if err != nil {
+		return "", fmt.Errorf("directorio de trabajo no válido: %w", err)
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("directorio de trabajo no válido: %s no es un directorio", workingDir)
+	}
+
+	var cmd *exec.Cmd
+	var terminal string
+	switch currentPlatform() {
+	case platformWindows, platformWSL:
+		for _, arg := range argv {
+			if strings.ContainsAny(arg, cmdMetacharacters) {
+				return "", fmt.Errorf("argumento no válido %q: contiene caracteres que interpreta cmd", arg)
+			}
+		}
+		dir := workingDir
+		if isWSL() {
+			if dir, err = windowsPath(workingDir); err != nil {
+				return "", err
+			}
+		}
+		shell := append([]string{"cmd", "/k"}, argv...)
+		wt := "wt"
+		if isWSL() {
+			wt = "wt.exe"
+		}
+		if commandExists(wt)() {
+			terminal = "Windows Terminal"
+			cmd = exec.Command(wt, append([]string{"-d", dir}, shell...)...)
+		} else {
+			terminal = "cmd"
+			cmd = exec.Command("cmd.exe", append([]string{"/c", "start", "", "/D", dir}, shell...)...)
+		}
+	case platformMac:
+		// Terminal.app e iTerm solo aceptan la orden como texto para el shell,
+		// así que cada argumento va entrecomillado
+		line := "cd " + shellQuote(workingDir)
+		if len(argv) > 0 {
+			quoted := make([]string, len(argv))
+			for i, arg := range argv {
+				quoted[i] = shellQuote(arg)
+			}
+			line += " && " + strings.Join(quoted, " ")
+		}
+		script := fmt.Sprintf("tell application \"Terminal\"\n  activate\n  do script %q\nend tell", line)
+		terminal = "Terminal"
+		if _, err := os.Stat("/Applications/iTerm.app"); err == nil {
+			terminal = "iTerm"
+			script = fmt.Sprintf("tell application \"iTerm\"\n  activate\n  set w to (create window with default profile)\n  tell current session of w to write text %q\nend tell", line)
+		}
+		cmd = exec.Command("osascript", "-e", script)
+	default:
+		for _, name := range linuxTerminals {
+			if commandExists(name)() {
+				terminal = name
+				break
+			}
+		}
+		if terminal == "" {
+			return "", fmt.Errorf("no se encontró ningún emulador de terminal (%s)", strings.Join(linuxTerminals, ", "))
+		}
+		var args []string
+		switch terminal {
+		case "gnome-terminal":
+			args = []string{"--working-directory=" + workingDir}
+			if len(argv) > 0 {
+				args = append(append(args, "--"), argv...)
+			}
+		case "konsole":
+			args = []string{"--workdir", workingDir}
+			if len(argv) > 0 {
+				args = append(append(args, "-e"), argv...)
+			}
+		case "alacritty":
+			args = []string{"--working-directory", workingDir}
+			if len(argv) > 0 {
+				args = append(append(args, "-e"), argv...)
+			}
+		default:
+			if len(argv) > 0 {
+				args = append([]string{"-e"}, argv...)
+			}
+		}
+		cmd = exec.Command(terminal, args...)
+		cmd.SysProcAttr = detachedProcAttr()
+	}
+	cmd.Dir = workingDir
+
+	if err := cmd.Start(); err != nil {
+		return "", fmt.Errorf("%s: %w", terminal, err)
+	}
+	go cmd.Wait()
+
+	result := fmt.Sprintf("💻 Terminal abierta (%s) en %s", terminal, workingDir)
+	if len(argv) > 0 {
+		result += "\n▶️ " + formatCommandLine(argv)
+	}
+	return result, nil
+}
+
+type OpenTerminalInput struct {
+	Command    string `json:"command,omitempty" jsonschema:"Orden a ejecutar en la terminal (opcional), ej: 'npm start'. Se separa en argumentos respetando comillas; no admite tuberías ni redirecciones"`
+	WorkingDir string `json:"working_dir,omitempty" jsonschema:"Directorio en el que abrir la terminal (por defecto el directorio personal)"`
+}
+
+func HandleOpenTerminal(ctx context.Context, req *mcp.CallToolRequest, input OpenTerminalInput) (*mcp.CallToolResult, any, error) {
+	result, err := openTerminal(input.Command, input.WorkingDir)
+	if err != nil {
+		return nil, nil, fmt.Errorf("❌ Error al abrir la terminal: %w", err)
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: result},
+		},
+	}, nil, nil
+}
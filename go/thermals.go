@@ -0,0 +1,309 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ThermalSensor es la lectura de un sensor de temperatura
+type ThermalSensor struct {
+	Chip         string  `json:"chip,omitempty" jsonschema:"Chip o zona térmica del sensor (ej: 'coretemp', 'acpitz')"`
+	Name         string  `json:"name" jsonschema:"Nombre del sensor"`
+	TemperatureC float64 `json:"temperature_c" jsonschema:"Temperatura en grados Celsius"`
+}
+
+// FanReading es la velocidad de un ventilador
+type FanReading struct {
+	Chip string `json:"chip,omitempty" jsonschema:"Chip que controla el ventilador"`
+	Name string `json:"name" jsonschema:"Nombre del ventilador"`
+	RPM  int    `json:"rpm" jsonschema:"Revoluciones por minuto"`
+}
+
+type ThermalsOutput struct {
+	CPUTemperatureC *float64        `json:"cpu_temperature_c" jsonschema:"Temperatura de la CPU en grados Celsius (null si ningún sensor la identifica)"`
+	Sensors         []ThermalSensor `json:"sensors,omitempty" jsonschema:"Sensores de temperatura legibles"`
+	Fans            []FanReading    `json:"fans,omitempty" jsonschema:"Ventiladores legibles"`
+	Source          string          `json:"source" jsonschema:"Herramienta o interfaz que ha proporcionado los datos"`
+}
+
+// validTemperature descarta las lecturas de sensores desconectados, que
+// suelen dar 0, valores negativos o disparatados
+func validTemperature(celsius float64) bool {
+	return celsius > 0 && celsius < 150
+}
+
+// getThermals lee los sensores de temperatura y los ventiladores. En WSL se
+// consultan los de Windows: la máquina virtual no expone ninguno.
+func getThermals() (ThermalsOutput, error) {
+	var out ThermalsOutput
+	var err error
+	switch currentPlatform() {
+	case platformWindows, platformWSL:
+		out, err = thermalsWindows()
+	case platformMac:
+		out, err = thermalsMac()
+	default:
+		out, err = thermalsLinux()
+	}
+	if err != nil {
+		return ThermalsOutput{}, err
+	}
+	if len(out.Sensors) == 0 && len(out.Fans) == 0 {
+		return ThermalsOutput{}, fmt.Errorf("no hay ningún sensor de temperatura ni ventilador legible (fuente: %s)", out.Source)
+	}
+	out.CPUTemperatureC = cpuTemperature(out.Sensors)
+	return out, nil
+}
+
+// cpuTemperature elige el sensor que mejor representa la temperatura de la
+// CPU: el del encapsulado (coretemp, Libre/OpenHardwareMonitor), el de
+// control de AMD (k10temp) o la zona térmica de la CPU, por ese orden
+func cpuTemperature(sensors []ThermalSensor) *float64 {
+	matchers := []func(chip, name string) bool{
+		func(chip, name string) bool { return strings.HasPrefix(name, "package id") || name == "cpu package" },
+		func(chip, name string) bool { return name == "tctl" || name == "tdie" || name == "core (tctl/tdie)" },
+		func(chip, name string) bool {
+			return slices.Contains([]string{"x86_pkg_temp", "cpu_thermal", "cpu-thermal", "soc_thermal", "cpu"}, chip)
+		},
+		func(chip, name string) bool {
+			return strings.Contains(chip, "cpu") || strings.HasPrefix(chip, "coretemp") || strings.HasPrefix(chip, "k10temp")
+		},
+	}
+	for _, matches := range matchers {
+		for _, sensor := range sensors {
+			if matches(strings.ToLower(sensor.Chip), strings.ToLower(sensor.Name)) {
+				celsius := sensor.TemperatureC
+				return &celsius
+			}
+		}
+	}
+	return nil
+}
+
+// thermalsLinux usa `sensors -j` (lm-sensors) cuando está instalado, que
+// etiqueta mejor los sensores, y si no lee hwmon y las zonas térmicas de sysfs
+func thermalsLinux() (ThermalsOutput, error) {
+	if commandExists("sensors")() {
+		if out, err := thermalsLMSensors(); err == nil && (len(out.Sensors) > 0 || len(out.Fans) > 0) {
+			return out, nil
+		}
+	}
+	return thermalsSysfs(), nil
+}
+
+// thermalsLMSensors interpreta `sensors -j`:
+//
+//	{"coretemp-isa-0000": {"Adapter": "ISA adapter",
+//	  "Package id 0": {"temp1_input": 45.000, "temp1_max": 100.000}},
+//	 "thinkpad-isa-0000": {"fan1": {"fan1_input": 2000.000}}}
+func thermalsLMSensors() (ThermalsOutput, error) {
+	out := ThermalsOutput{Source: "lm-sensors (sensors -j)"}
+	// sensors termina con error si algún chip falla, pero escribe el resto
+	output, _ := exec.Command("sensors", "-j").Output()
+	var chips map[string]map[string]any
+	if err := json.Unmarshal(output, &chips); err != nil {
+		return out, fmt.Errorf("respuesta de sensors no válida: %w", err)
+	}
+	for _, chip := range slices.Sorted(maps.Keys(chips)) {
+		// "coretemp-isa-0000" -> "coretemp"
+		chipName, _, _ := strings.Cut(chip, "-")
+		for _, feature := range slices.Sorted(maps.Keys(chips[chip])) {
+			values, ok := chips[chip][feature].(map[string]any)
+			if !ok {
+				continue
+			}
+			for key, value := range values {
+				number, ok := value.(float64)
+				if !ok {
+					continue
+				}
+				switch {
+				case strings.HasPrefix(key, "temp") && strings.HasSuffix(key, "_input") && validTemperature(number):
+					out.Sensors = append(out.Sensors, ThermalSensor{Chip: chipName, Name: feature, TemperatureC: number})
+				case strings.HasPrefix(key, "fan") && strings.HasSuffix(key, "_input"):
+					out.Fans = append(out.Fans, FanReading{Chip: chipName, Name: feature, RPM: int(number)})
+				}
+			}
+		}
+	}
+	return out, nil
+}
+
+// hwmonInputRegexp reconoce los ficheros de lectura de hwmon: temp1_input, fan2_input
+var hwmonInputRegexp = regexp.MustCompile(`^(temp|fan)(\d+)_input$`)
+
+// thermalsSysfs lee /sys/class/hwmon (milésimas de grado y RPM) y las zonas
+// térmicas de /sys/class/thermal que no tienen ya un chip hwmon con su nombre
+// (acpitz, por ejemplo, aparece en los dos sitios)
+func thermalsSysfs() ThermalsOutput {
+	out := ThermalsOutput{Source: "sysfs (/sys/class/hwmon, /sys/class/thermal)"}
+	chips := map[string]bool{}
+	devices, _ := filepath.Glob("/sys/class/hwmon/hwmon*")
+	for _, device := range devices {
+		data, _ := os.ReadFile(filepath.Join(device, "name"))
+		chip := strings.TrimSpace(string(data))
+		chips[chip] = true
+		entries, _ := os.ReadDir(device)
+		for _, entry := range entries {
+			m := hwmonInputRegexp.FindStringSubmatch(entry.Name())
+			if m == nil {
+				continue
+			}
+			value, err := readSysfsInt(filepath.Join(device, entry.Name()))
+			if err != nil {
+				continue
+			}
+			name := m[1] + m[2]
+			if label, err := os.ReadFile(filepath.Join(device, m[1]+m[2]+"_label")); err == nil {
+				name = strings.TrimSpace(string(label))
+			}
+			if m[1] == "fan" {
+				out.Fans = append(out.Fans, FanReading{Chip: chip, Name: name, RPM: value})
+			} else if celsius := float64(value) / 1000; validTemperature(celsius) {
+				out.Sensors = append(out.Sensors, ThermalSensor{Chip: chip, Name: name, TemperatureC: celsius})
+			}
+		}
+	}
+
+	zones, _ := filepath.Glob("/sys/class/thermal/thermal_zone*")
+	for _, zone := range zones {
+		data, _ := os.ReadFile(filepath.Join(zone, "type"))
+		kind := strings.TrimSpace(string(data))
+		if chips[kind] {
+			continue
+		}
+		value, err := readSysfsInt(filepath.Join(zone, "temp"))
+		if celsius := float64(value) / 1000; err == nil && validTemperature(celsius) {
+			out.Sensors = append(out.Sensors, ThermalSensor{Chip: kind, Name: filepath.Base(zone), TemperatureC: celsius})
+		}
+	}
+	return out
+}
+
+var (
+	// "61.8°C"
+	macCPUTempRegexp = regexp.MustCompile(`([\d.]+)\s*°C`)
+	// "Fan 0 - Left side  at 2000 RPM (33%)"
+	macFanRegexp = regexp.MustCompile(`(?m)^Fan \d+ - (.+?)\s+at (\d+) RPM`)
+)
+
+// thermalsMac usa osx-cpu-temp, que lee las claves del SMC sin necesitar
+// sudo como powermetrics. En los Mac con Apple Silicon devuelve 0 °C, que
+// se trata como un sensor ilegible.
+func thermalsMac() (ThermalsOutput, error) {
+	out := ThermalsOutput{Source: "osx-cpu-temp"}
+	if !commandExists("osx-cpu-temp")() {
+		return out, errors.New("se necesita osx-cpu-temp instalado (brew install osx-cpu-temp)")
+	}
+	output, err := exec.Command("osx-cpu-temp", "-c", "-g", "-f").Output()
+	if err != nil {
+		return out, fmt.Errorf("error al ejecutar osx-cpu-temp: %w", err)
+	}
+	// "CPU: 61.8°C" y "GPU: 55.0°C" con -c -g
+	for _, line := range strings.Split(string(output), "\n") {
+		label, value, ok := strings.Cut(line, ":")
+		m := macCPUTempRegexp.FindStringSubmatch(value)
+		if !ok || m == nil {
+			continue
+		}
+		if celsius, _ := strconv.ParseFloat(m[1], 64); validTemperature(celsius) {
+			chip := strings.ToLower(strings.TrimSpace(label))
+			out.Sensors = append(out.Sensors, ThermalSensor{Chip: chip, Name: strings.TrimSpace(label), TemperatureC: celsius})
+		}
+	}
+	for _, m := range macFanRegexp.FindAllStringSubmatch(string(output), -1) {
+		rpm, _ := strconv.Atoi(m[2])
+		out.Fans = append(out.Fans, FanReading{Chip: "smc", Name: m[1], RPM: rpm})
+	}
+	return out, nil
+}
+
+// windowsThermalsScript prefiere los sensores de LibreHardwareMonitor u
+// OpenHardwareMonitor (que publican WMI mientras se ejecutan) y, sin ellos,
+// usa las zonas térmicas ACPI, que dan décimas de kelvin y requieren
+// permisos de administrador
+const windowsThermalsScript = `$sensors = @()
+$source = ''
+foreach ($ns in 'root/LibreHardwareMonitor', 'root/OpenHardwareMonitor') {
+	$found = @(Get-CimInstance -Namespace $ns -ClassName Sensor -ErrorAction SilentlyContinue | Where-Object { $_.SensorType -in 'Temperature', 'Fan' })
+	if ($found.Count -gt 0) {
+		$sensors = @($found | ForEach-Object { @{ chip = "$($_.Parent)"; name = $_.Name; type = "$($_.SensorType)"; value = [double]$_.Value } })
+		$source = $ns
+		break
+	}
+}
+if ($sensors.Count -eq 0) {
+	$source = 'MSAcpi_ThermalZoneTemperature'
+	$sensors = @(Get-CimInstance -Namespace root/wmi -ClassName MSAcpi_ThermalZoneTemperature -ErrorAction SilentlyContinue | ForEach-Object {
+		@{ chip = 'acpi'; name = $_.InstanceName; type = 'Temperature'; value = [math]::Round($_.CurrentTemperature / 10 - 273.15, 1) }
+	})
+}
+ConvertTo-Json -Compress -Depth 3 @{ source = $source; sensors = $sensors }`
+
+func thermalsWindows() (ThermalsOutput, error) {
+	output, err := runPowerShell(windowsThermalsScript)
+	if err != nil {
+		return ThermalsOutput{}, fmt.Errorf("error al consultar los sensores: %w", err)
+	}
+	var result struct {
+		Source  string
+		Sensors []struct {
+			Chip, Name, Type string
+			Value            float64
+		}
+	}
+	if err := json.Unmarshal([]byte(output), &result); err != nil {
+		return ThermalsOutput{}, fmt.Errorf("respuesta de PowerShell no válida: %w", err)
+	}
+	out := ThermalsOutput{Source: result.Source}
+	for _, s := range result.Sensors {
+		switch {
+		case s.Type == "Fan":
+			out.Fans = append(out.Fans, FanReading{Chip: s.Chip, Name: s.Name, RPM: int(s.Value)})
+		case validTemperature(s.Value):
+			out.Sensors = append(out.Sensors, ThermalSensor{Chip: s.Chip, Name: s.Name, TemperatureC: s.Value})
+		}
+	}
+	if len(out.Sensors) == 0 && len(out.Fans) == 0 && result.Source == "MSAcpi_ThermalZoneTemperature" {
+		return out, errors.New("no hay sensores legibles: las zonas térmicas ACPI requieren ejecutar como administrador y no todos los equipos las tienen; " +
+			"instala LibreHardwareMonitor y déjalo abierto para leer la CPU y los ventiladores")
+	}
+	return out, nil
+}
+
+func HandleGetThermals(ctx context.Context, req *mcp.CallToolRequest, input struct{}) (*mcp.CallToolResult, ThermalsOutput, error) {
+	out, err := getThermals()
+	if err != nil {
+		return nil, ThermalsOutput{}, fmt.Errorf("❌ Error al leer los sensores de temperatura: %w", err)
+	}
+	var lines []string
+	if out.CPUTemperatureC != nil {
+		lines = append(lines, fmt.Sprintf("🌡️ CPU: %.0f °C", *out.CPUTemperatureC))
+	} else {
+		lines = append(lines, "🌡️ CPU: ningún sensor identifica su temperatura")
+	}
+	for _, s := range out.Sensors {
+		lines = append(lines, fmt.Sprintf("  - %s / %s: %.1f °C", s.Chip, s.Name, s.TemperatureC))
+	}
+	for _, f := range out.Fans {
+		lines = append(lines, fmt.Sprintf("🌀 %s / %s: %d RPM", f.Chip, f.Name, f.RPM))
+	}
+	lines = append(lines, "ℹ️ Fuente: "+out.Source)
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: strings.Join(lines, "\n")},
+		},
+	}, out, nil
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Límites de los tonos generados. El rango de frecuencias es el que admite
+// [console]::beep en Windows.
+const (
+	minToneFrequency = 37
+	maxToneFrequency = 32767
+	maxToneDuration  = 10 * time.Second
+	toneSampleRate   = 44100
+)
+
+// toneWAV genera un tono senoidal como WAV PCM mono de 16 bits
+func toneWAV(frequency int, duration time.Duration) []byte {
+	return melodyWAV([]melodyNote{{Frequency: frequency, Duration: duration}})
+}
+
+// melodyWAV genera una secuencia de tonos senoidales (o silencios, con
+// frecuencia 0) como un único WAV PCM mono de 16 bits. Los extremos de cada
+// nota se suavizan unos milisegundos para evitar chasquidos.
+func melodyWAV(notes []melodyNote) []byte {
+	total := 0
+	for _, n := range notes {
+		total += int(n.Duration.Seconds() * toneSampleRate)
+	}
+
+	var buf bytes.Buffer
+	dataSize := uint32(total * 2)
+	buf.WriteString("RIFF")
+	binary.Write(&buf, binary.LittleEndian, 36+dataSize)
+	buf.WriteString("WAVEfmt ")
+	binary.Write(&buf, binary.LittleEndian, uint32(16))               // tamaño del bloque fmt
+	binary.Write(&buf, binary.LittleEndian, uint16(1))                // PCM
+	binary.Write(&buf, binary.LittleEndian, uint16(1))                // mono
+	binary.Write(&buf, binary.LittleEndian, uint32(toneSampleRate))   // muestras por segundo
+	binary.Write(&buf, binary.LittleEndian, uint32(toneSampleRate*2)) // bytes por segundo
+	binary.Write(&buf, binary.LittleEndian, uint16(2))                // bytes por muestra
+	binary.Write(&buf, binary.LittleEndian, uint16(16))               // bits por muestra
+	buf.WriteString("data")
+	binary.Write(&buf, binary.LittleEndian, dataSize)
+
+	for _, n := range notes {
+		samples := int(n.Duration.Seconds() * toneSampleRate)
+		fade := min(samples/2, toneSampleRate/200) // 5 ms
+		for i := 0; i < samples; i++ {
+			amplitude := 0.8
+			if n.Frequency == 0 {
+				amplitude = 0
+			} else if i < fade {
+				amplitude *= float64(i) / float64(fade)
+			} else if samples-i < fade {
+				amplitude *= float64(samples-i) / float64(fade)
+			}
+			v := amplitude * math.Sin(2*math.Pi*float64(n.Frequency)*float64(i)/toneSampleRate)
+			binary.Write(&buf, binary.LittleEndian, int16(v*math.MaxInt16))
+		}
+	}
+	return buf.Bytes()
+}
+
+// toneFile devuelve la ruta de un WAV con el tono pedido, generándolo en el
+// directorio temporal la primera vez. Los ficheros se reutilizan entre
+// llamadas, así que no hace falta borrarlos tras una reproducción asíncrona.
+func toneFile(frequency int, duration time.Duration) (string, error) {
+	dir := filepath.Join(os.TempDir(), configDirName+"-tones")
+	path := filepath.Join(dir, fmt.Sprintf("tone-%d-%d.wav", frequency, duration.Milliseconds()))
+	if _, err := os.Stat(path); err == nil {
+		return path, nil
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	// Escribir en un fichero temporal y renombrar para no reproducir uno a medias
+	tmp, err := os.CreateTemp(dir, "tone-*.tmp")
+	if err != nil {
+		return "", err
+	}
+	if _, err := tmp.Write(toneWAV(frequency, duration)); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return "", err
+	}
+	return path, os.Rename(tmp.Name(), path)
+}
+
+// playTone reproduce un tono de la frecuencia y duración indicadas: con
+// [console]::beep en Windows y WSL y con un WAV generado en macOS y Linux
+func playTone(ctx context.Context, frequency int, duration time.Duration, opts soundOptions) (string, error) {
+	if frequency < minToneFrequency || frequency > maxToneFrequency {
+		return "", fmt.Errorf("frecuencia %d Hz fuera de rango (%d-%d)", frequency, minToneFrequency, maxToneFrequency)
+	}
+	if duration <= 0 || duration > maxToneDuration {
+		return "", fmt.Errorf("duración %s fuera de rango (máximo %s)", duration, maxToneDuration)
+	}
+	name := fmt.Sprintf("tono %d Hz, %d ms", frequency, duration.Milliseconds())
+
+	var argv []string
+	var note string
+	switch currentPlatform() {
+	case platformWindows, platformWSL:
+		// [console]::beep no tiene amplitud configurable
+		if opts.Volume != nil {
+			note = soundVolumeNote + "\n"
+		}
+		argv = []string{powershellCommand(), "-Command", fmt.Sprintf("[console]::beep(%d,%d)", frequency, duration.Milliseconds())}
+	case platformMac:
+		path, err := toneFile(frequency, duration)
+		if err != nil {
+			return "", fmt.Errorf("no se pudo generar el tono: %w", err)
+		}
+		argv = append(append([]string{"afplay"}, playerVolumeArgs("afplay", opts.Volume)...), path)
+	default:
+		switch {
+		case commandExists("paplay")():
+			path, err := toneFile(frequency, duration)
+			if err != nil {
+				return "", fmt.Errorf("no se pudo generar el tono: %w", err)
+			}
+			argv = append(append([]string{"paplay"}, playerVolumeArgs("paplay", opts.Volume)...), path)
+		case commandExists("play")():
+			// sox genera el tono directamente
+			argv = []string{"play", "-q", "-n", "synth", fmt.Sprintf("%.3f", duration.Seconds()), "sine", fmt.Sprint(frequency)}
+			if opts.Volume != nil {
+				argv = append(argv, "vol", fmt.Sprintf("%.2f", float64(*opts.Volume)/100))
+			}
+		default:
+			return "", errors.New("no se encontró ningún reproductor (instala pulseaudio-utils o sox)")
+		}
+	}
+	return playSound(ctx, name, argv, note, opts)
+}
@@ -0,0 +1,380 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Límites de check_updates. softwareupdate y Windows Update consultan a
+// los servidores de actualizaciones y pueden tardar varios minutos.
+const (
+	defaultUpdatesTimeout  = 120 * time.Second
+	maxUpdatesTimeout      = 600 * time.Second
+	defaultUpdatesLimit    = 20
+	maxUpdatesLimit        = 500
+	updatesProgressEvery   = 10 * time.Second
+	updatesCommandWaitTime = 2 * time.Second
+)
+
+type PendingUpdate struct {
+	Name           string `json:"name" jsonschema:"Paquete o actualización"`
+	Version        string `json:"version,omitempty" jsonschema:"Versión disponible (o artículo KB en Windows)"`
+	CurrentVersion string `json:"current_version,omitempty" jsonschema:"Versión instalada, si el gestor la indica"`
+}
+
+type CheckUpdatesOutput struct {
+	Count   int             `json:"count" jsonschema:"Número total de actualizaciones pendientes"`
+	Updates []PendingUpdate `json:"updates,omitempty" jsonschema:"Las primeras actualizaciones pendientes (hasta limit)"`
+	Source  string          `json:"source" jsonschema:"Mecanismo consultado (apt, dnf, pacman, softwareupdate, Windows Update)"`
+	Note    string          `json:"note,omitempty" jsonschema:"Aclaración sobre la vigencia de los datos"`
+}
+
+// updateChecker es una forma de consultar las actualizaciones pendientes.
+// Ninguna instala nada: solo se usan las órdenes de consulta de cada gestor.
+type updateChecker struct {
+	Name    string
+	Command func(ctx context.Context) *exec.Cmd
+	// Parse interpreta la salida; exitCode permite distinguir "no hay
+	// actualizaciones" de un error en los gestores que lo señalan así
+	Parse func(output string, exitCode int) ([]PendingUpdate, error)
+	Slow  bool   // Consulta a los servidores y puede tardar (se avisa del progreso)
+	Note  string // Aclaración que acompaña al resultado
+}
+
+// commandC crea una orden con la configuración regional C, para que la
+// salida que se interpreta no esté traducida
+func commandC(ctx context.Context, name string, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Env = append(os.Environ(), "LC_ALL=C")
+	return cmd
+}
+
+// selectUpdateChecker elige el mecanismo de la plataforma. En WSL se
+// consultan los paquetes de la distribución, no las actualizaciones de Windows.
+func selectUpdateChecker() (updateChecker, error) {
+	switch currentPlatform() {
+	case platformWindows:
+		return updateChecker{
+			Name: "Windows Update",
+			Command: func(ctx context.Context) *exec.Cmd {
+				return exec.CommandContext(ctx, powershellCommand(), "-NoProfile", "-NonInteractive", "-Command", windowsUpdatesScript)
+			},
+			Parse: parseWindowsUpdates,
+			Slow:  true,
+		}, nil
+	case platformMac:
+		return updateChecker{
+			Name: "softwareupdate",
+			Command: func(ctx context.Context) *exec.Cmd {
+				return commandC(ctx, "softwareupdate", "--list")
+			},
+			Parse: parseSoftwareUpdate,
+			Slow:  true,
+		}, nil
+	}
+
+	switch {
+	case commandExists("apt")():
+		return updateChecker{
+			Name: "apt",
+			Command: func(ctx context.Context) *exec.Cmd {
+				return commandC(ctx, "apt", "list", "--upgradable")
+			},
+			Parse: parseAptUpgradable,
+			Note:  "Según los índices de la última actualización de apt (apt update), que esta herramienta no refresca",
+		}, nil
+	case commandExists("dnf")():
+		return updateChecker{
+			Name: "dnf",
+			Command: func(ctx context.Context) *exec.Cmd {
+				return commandC(ctx, "dnf", "check-update", "--quiet")
+			},
+			Parse: parseDnfCheckUpdate,
+			Slow:  true,
+		}, nil
+	case commandExists("checkupdates")():
+		// checkupdates (pacman-contrib) sincroniza una copia temporal de las
+		// bases de datos, sin tocar las del sistema
+		return updateChecker{
+			Name: "checkupdates",
+			Command: func(ctx context.Context) *exec.Cmd {
+				return commandC(ctx, "checkupdates")
+			},
+			Parse: pacmanUpdatesParser(2),
+			Slow:  true,
+		}, nil
+	case commandExists("pacman")():
+		return updateChecker{
+			Name: "pacman",
+			Command: func(ctx context.Context) *exec.Cmd {
+				return commandC(ctx, "pacman", "-Qu")
+			},
+			Parse: pacmanUpdatesParser(1),
+			Note:  "Según las bases de datos de la última sincronización de pacman (pacman -Sy); instala pacman-contrib para consultar con checkupdates",
+		}, nil
+	}
+	return updateChecker{}, errors.New("no se encontró ningún gestor de paquetes compatible (apt, dnf o pacman)")
+}
+
+// aptUpgradableRegexp reconoce las líneas de `apt list --upgradable`:
+// "firefox/jammy-updates 120.0+build2 amd64 [upgradable from: 119.0+build2]"
+var aptUpgradableRegexp = regexp.MustCompile(`^([^/\s]+)/\S+ (\S+) \S+(?: \[upgradable from: ([^\]]+)\])?`)
+
+func parseAptUpgradable(output string, exitCode int) ([]PendingUpdate, error) {
+	if exitCode != 0 {
+		return nil, fmt.Errorf("apt terminó con código %d", exitCode)
+	}
+	var updates []PendingUpdate
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		if m := aptUpgradableRegexp.FindStringSubmatch(scanner.Text()); m != nil {
+			updates = append(updates, PendingUpdate{Name: m[1], Version: m[2], CurrentVersion: m[3]})
+		}
+	}
+	return updates, nil
+}
+
+// parseDnfCheckUpdate interpreta `dnf check-update`, que termina con código
+// 100 si hay actualizaciones y 0 si no. Cada línea es "nombre.arquitectura
+// versión repositorio"; la sección "Obsoleting Packages" repite paquetes.
+func parseDnfCheckUpdate(output string, exitCode int) ([]PendingUpdate, error) {
+	switch exitCode {
+	case 0:
+		return nil, nil
+	case 100:
+	default:
+		return nil, fmt.Errorf("dnf terminó con código %d", exitCode)
+	}
+	var updates []PendingUpdate
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(strings.ToLower(line), "obsoleting") {
+			break
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 3 || strings.HasPrefix(line, " ") {
+			continue
+		}
+		name := fields[0]
+		if dot := strings.LastIndex(name, "."); dot > 0 {
+			name = name[:dot]
+		}
+		updates = append(updates, PendingUpdate{Name: name, Version: fields[1]})
+	}
+	return updates, nil
+}
+
+// pacmanUpdatesParser interpreta las líneas "paquete 1.0-1 -> 1.1-1" de
+// `pacman -Qu` y checkupdates, que terminan con un código propio cuando no
+// hay actualizaciones (noUpdatesCode: 1 y 2 respectivamente)
+func pacmanUpdatesParser(noUpdatesCode int) func(string, int) ([]PendingUpdate, error) {
+	return func(output string, exitCode int) ([]PendingUpdate, error) {
+		switch exitCode {
+		case 0:
+		case noUpdatesCode:
+			return nil, nil
+		default:
+			return nil, fmt.Errorf("terminó con código %d", exitCode)
+		}
+		var updates []PendingUpdate
+		scanner := bufio.NewScanner(strings.NewReader(output))
+		for scanner.Scan() {
+			fields := strings.Fields(scanner.Text())
+			if len(fields) == 4 && fields[2] == "->" {
+				updates = append(updates, PendingUpdate{Name: fields[0], Version: fields[3], CurrentVersion: fields[1]})
+			}
+		}
+		return updates, nil
+	}
+}
+
+var (
+	// Formato de macOS 10.15 en adelante:
+	// "* Label: macOS Sonoma 14.5-23F79"
+	// "	Title: macOS Sonoma 14.5, Version: 14.5, Size: 1234567K, Recommended: YES, Action: restart,"
+	softwareUpdateLabelRegexp   = regexp.MustCompile(`^\s*\* Label: (.+)$`)
+	softwareUpdateVersionRegexp = regexp.MustCompile(`Title: (.+?), Version: ([^,]+),`)
+)
+
+// parseSoftwareUpdate interpreta `softwareupdate --list`, que escribe la
+// lista en la salida estándar y los avisos ("No new software available.")
+// en la de error
+func parseSoftwareUpdate(output string, exitCode int) ([]PendingUpdate, error) {
+	var updates []PendingUpdate
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if m := softwareUpdateLabelRegexp.FindStringSubmatch(line); m != nil {
+			updates = append(updates, PendingUpdate{Name: m[1]})
+		} else if m := softwareUpdateVersionRegexp.FindStringSubmatch(line); m != nil && len(updates) > 0 {
+			updates[len(updates)-1].Name, updates[len(updates)-1].Version = m[1], m[2]
+		}
+	}
+	if exitCode != 0 && len(updates) == 0 {
+		return nil, fmt.Errorf("softwareupdate terminó con código %d", exitCode)
+	}
+	return updates, nil
+}
+
+// windowsUpdatesScript busca con la API COM de Windows Update
+// (Microsoft.Update.Session) las actualizaciones no instaladas ni ocultas.
+// Solo se llama a Search: nada se descarga ni se instala.
+const windowsUpdatesScript = `$searcher = (New-Object -ComObject Microsoft.Update.Session).CreateUpdateSearcher()
+$result = $searcher.Search("IsInstalled=0 and IsHidden=0")
+ConvertTo-Json -Compress @(@($result.Updates) | ForEach-Object {
+	@{ title = [string]$_.Title; kb = [string](@($_.KBArticleIDs) -join ', ') }
+})`
+
+func parseWindowsUpdates(output string, exitCode int) ([]PendingUpdate, error) {
+	if exitCode != 0 {
+		return nil, fmt.Errorf("PowerShell terminó con código %d", exitCode)
+	}
+	var result []struct{ Title, KB string }
+	if err := json.Unmarshal([]byte(output), &result); err != nil {
+		return nil, fmt.Errorf("respuesta de PowerShell no válida: %w", err)
+	}
+	updates := make([]PendingUpdate, len(result))
+	for i, u := range result {
+		updates[i] = PendingUpdate{Name: u.Title}
+		if u.KB != "" {
+			updates[i].Version = "KB" + u.KB
+		}
+	}
+	return updates, nil
+}
+
+// notifyProgress envía una notificación de progreso si el cliente la pidió
+// (con un progressToken en la llamada)
+func notifyProgress(ctx context.Context, req *mcp.CallToolRequest, progress float64, message string) {
+	token := req.Params.GetProgressToken()
+	if token == nil {
+		return
+	}
+	if err := req.Session.NotifyProgress(ctx, &mcp.ProgressNotificationParams{
+		ProgressToken: token,
+		Progress:      progress,
+		Message:       message,
+	}); err != nil {
+		log.Printf("⚠️ No se pudo enviar el progreso: %v", err)
+	}
+}
+
+// checkUpdates ejecuta la consulta con un tiempo máximo. Mientras espera a
+// una consulta lenta avisa del tiempo transcurrido cada updatesProgressEvery.
+func checkUpdates(ctx context.Context, req *mcp.CallToolRequest, checker updateChecker, timeout time.Duration) ([]PendingUpdate, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	cmd := checker.Command(ctx)
+	cmd.WaitDelay = updatesCommandWaitTime
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("error al ejecutar %s: %w", checker.Name, err)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+	if checker.Slow {
+		notifyProgress(ctx, req, 0, fmt.Sprintf("Consultando %s; puede tardar más de 30 segundos", checker.Name))
+	}
+	ticker := time.NewTicker(updatesProgressEvery)
+	defer ticker.Stop()
+	start := time.Now()
+	var err error
+wait:
+	for {
+		select {
+		case err = <-done:
+			break wait
+		case <-ticker.C:
+			if checker.Slow {
+				elapsed := time.Since(start).Round(time.Second)
+				notifyProgress(ctx, req, elapsed.Seconds(), fmt.Sprintf("Consultando %s (%s de %s como máximo)", checker.Name, elapsed, timeout))
+			}
+		}
+	}
+
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return nil, fmt.Errorf("%s no respondió en %s; prueba con un timeout_seconds mayor", checker.Name, timeout)
+	}
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		return nil, fmt.Errorf("error al ejecutar %s: %w", checker.Name, err)
+	}
+	updates, err := checker.Parse(stdout.String(), cmd.ProcessState.ExitCode())
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%w: %s", err, msg)
+		}
+		return nil, err
+	}
+	return updates, nil
+}
+
+type CheckUpdatesInput struct {
+	Limit          int `json:"limit,omitempty" jsonschema:"Número máximo de actualizaciones a listar (por defecto 20, hasta 500); count es siempre el total"`
+	TimeoutSeconds int `json:"timeout_seconds,omitempty" jsonschema:"Tiempo máximo de la consulta en segundos (por defecto 120, hasta 600)"`
+}
+
+func HandleCheckUpdates(ctx context.Context, req *mcp.CallToolRequest, input CheckUpdatesInput) (*mcp.CallToolResult, CheckUpdatesOutput, error) {
+	limit := input.Limit
+	if limit <= 0 {
+		limit = defaultUpdatesLimit
+	}
+	timeout := time.Duration(input.TimeoutSeconds) * time.Second
+	if timeout <= 0 {
+		timeout = defaultUpdatesTimeout
+	}
+
+	checker, err := selectUpdateChecker()
+	if err != nil {
+		return nil, CheckUpdatesOutput{}, fmt.Errorf("❌ Error al buscar actualizaciones: %w", err)
+	}
+	updates, err := checkUpdates(ctx, req, checker, timeout)
+	if err != nil {
+		return nil, CheckUpdatesOutput{}, fmt.Errorf("❌ Error al buscar actualizaciones: %w", err)
+	}
+
+	out := CheckUpdatesOutput{Count: len(updates), Updates: updates[:min(limit, len(updates))], Source: checker.Name, Note: checker.Note}
+	var lines []string
+	if out.Count == 0 {
+		lines = append(lines, fmt.Sprintf("✅ No hay actualizaciones pendientes (%s)", out.Source))
+	} else {
+		lines = append(lines, fmt.Sprintf("🔄 %d actualizaciones pendientes (%s):", out.Count, out.Source))
+		for _, u := range out.Updates {
+			line := "  - " + u.Name
+			switch {
+			case u.CurrentVersion != "" && u.Version != "":
+				line += fmt.Sprintf(" %s → %s", u.CurrentVersion, u.Version)
+			case u.Version != "":
+				line += " " + u.Version
+			}
+			lines = append(lines, line)
+		}
+		if rest := out.Count - len(out.Updates); rest > 0 {
+			lines = append(lines, fmt.Sprintf("  … y %d más", rest))
+		}
+	}
+	if out.Note != "" {
+		lines = append(lines, "ℹ️ "+out.Note)
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: strings.Join(lines, "\n")},
+		},
+	}, out, nil
+}
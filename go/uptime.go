@@ -0,0 +1,211 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type UptimeOutput struct {
+	UptimeSeconds int64   `json:"uptime_seconds" jsonschema:"Segundos desde el arranque del sistema"`
+	Uptime        string  `json:"uptime" jsonschema:"Tiempo desde el arranque en formato legible (ej: '2d 3h 15m')"`
+	IdleSeconds   *int64  `json:"idle_seconds" jsonschema:"Segundos sin actividad del usuario (teclado o ratón), null si no se puede saber"`
+	Idle          *string `json:"idle" jsonschema:"Tiempo de inactividad en formato legible, null si no se puede saber"`
+	IdleError     string  `json:"idle_error,omitempty" jsonschema:"Motivo por el que no se pudo obtener el tiempo de inactividad"`
+}
+
+// humanizeDuration da una duración en días, horas, minutos y segundos,
+// omitiendo las unidades menores cuando la duración es larga (ej: '2d 3h 15m')
+func humanizeDuration(d time.Duration) string {
+	seconds := int64(d.Round(time.Second) / time.Second)
+	days, seconds := seconds/86400, seconds%86400
+	hours, seconds := seconds/3600, seconds%3600
+	minutes, seconds := seconds/60, seconds%60
+	switch {
+	case days > 0:
+		return fmt.Sprintf("%dd %dh %dm", days, hours, minutes)
+	case hours > 0:
+		return fmt.Sprintf("%dh %dm", hours, minutes)
+	case minutes > 0:
+		return fmt.Sprintf("%dm %ds", minutes, seconds)
+	}
+	return fmt.Sprintf("%ds", seconds)
+}
+
+// getUptime devuelve el tiempo desde el arranque y el tiempo de inactividad
+// del usuario. El tiempo de inactividad no siempre se puede saber (p. ej. en
+// Wayland fuera de GNOME); en ese caso idle es un error y no falla la consulta.
+func getUptime() (uptime time.Duration, idle time.Duration, idleErr error, err error) {
+	switch currentPlatform() {
+	case platformWindows, platformWSL:
+		return uptimeWindows()
+	case platformMac:
+		uptime, err = uptimeMac()
+		if err != nil {
+			return 0, 0, nil, err
+		}
+		idle, idleErr = idleTimeMac()
+		return uptime, idle, idleErr, nil
+	}
+	uptime, err = uptimeLinux()
+	if err != nil {
+		return 0, 0, nil, err
+	}
+	idle, idleErr = idleTimeLinux()
+	return uptime, idle, idleErr, nil
+}
+
+// windowsUptimeScript devuelve "uptime_ms|idle_ms" con GetTickCount64 y
+// GetLastInputInfo. dwTime es un contador de 32 bits, así que la resta se
+// hace módulo 2^32.
+const windowsUptimeScript = `Add-Type -Name Win -Namespace Uptime -MemberDefinition '[StructLayout(LayoutKind.Sequential)] public struct LASTINPUTINFO { public uint cbSize; public uint dwTime; } [DllImport("user32.dll")] public static extern bool GetLastInputInfo(ref LASTINPUTINFO plii); [DllImport("kernel32.dll")] public static extern ulong GetTickCount64();'
+$info = New-Object Uptime.Win+LASTINPUTINFO
+$info.cbSize = [System.Runtime.InteropServices.Marshal]::SizeOf($info)
+$ticks = [Uptime.Win]::GetTickCount64()
+$idle = -1
+if ([Uptime.Win]::GetLastInputInfo([ref]$info)) { $idle = (($ticks % 4294967296) - $info.dwTime + 4294967296) % 4294967296 }
+"$ticks|$idle"`
+
+func uptimeWindows() (time.Duration, time.Duration, error, error) {
+	output, err := runPowerShell(windowsUptimeScript)
+	if err != nil {
+		return 0, 0, nil, fmt.Errorf("error al consultar el tiempo de actividad: %w", err)
+	}
+	uptimeMs, idleMs, _ := strings.Cut(output, "|")
+	uptime, err := strconv.ParseInt(uptimeMs, 10, 64)
+	if err != nil {
+		return 0, 0, nil, fmt.Errorf("respuesta de PowerShell no válida: %q", output)
+	}
+	idle, err := strconv.ParseInt(idleMs, 10, 64)
+	if err != nil || idle < 0 {
+		return time.Duration(uptime) * time.Millisecond, 0, errors.New("GetLastInputInfo no devolvió el tiempo de inactividad"), nil
+	}
+	return time.Duration(uptime) * time.Millisecond, time.Duration(idle) * time.Millisecond, nil, nil
+}
+
+func uptimeLinux() (time.Duration, error) {
+	// "12345.67 45678.90": segundos desde el arranque y tiempo ocioso de las CPU
+	data, err := os.ReadFile("/proc/uptime")
+	if err != nil {
+		return 0, fmt.Errorf("error al leer /proc/uptime: %w", err)
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 {
+		return 0, errors.New("/proc/uptime está vacío")
+	}
+	seconds, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return 0, fmt.Errorf("valor no válido en /proc/uptime: %q", fields[0])
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
+// mutterIdleRegexp reconoce la respuesta de GetIdletime: "(uint64 12345,)"
+var mutterIdleRegexp = regexp.MustCompile(`\(uint64 (\d+),\)`)
+
+// idleTimeLinux consulta el tiempo de inactividad con xprintidle o xssstate
+// en X11 y con el IdleMonitor de Mutter en GNOME (también en Wayland)
+func idleTimeLinux() (time.Duration, error) {
+	if err := checkGraphicalSession(); err != nil {
+		return 0, err
+	}
+	session := detectLinuxSession()
+	if session.IsDesktop("GNOME") && commandExists("gdbus")() {
+		output, err := exec.Command("gdbus", "call", "--session", "--dest", "org.gnome.Mutter.IdleMonitor",
+			"--object-path", "/org/gnome/Mutter/IdleMonitor/Core", "--method", "org.gnome.Mutter.IdleMonitor.GetIdletime").Output()
+		if m := mutterIdleRegexp.FindStringSubmatch(string(output)); err == nil && m != nil {
+			ms, _ := strconv.ParseInt(m[1], 10, 64)
+			return time.Duration(ms) * time.Millisecond, nil
+		}
+	}
+	if session.Wayland {
+		return 0, fmt.Errorf("el tiempo de inactividad no se puede consultar en Wayland (compositor: %s)", session.Compositor())
+	}
+
+	// Ambos devuelven milisegundos
+	var output []byte
+	var err error
+	switch {
+	case commandExists("xprintidle")():
+		output, err = exec.Command("xprintidle").Output()
+	case commandExists("xssstate")():
+		output, err = exec.Command("xssstate", "-i").Output()
+	default:
+		return 0, errors.New("se necesita xprintidle o xssstate para consultar el tiempo de inactividad")
+	}
+	if err != nil {
+		return 0, fmt.Errorf("error al consultar el tiempo de inactividad: %w", err)
+	}
+	ms, err := strconv.ParseInt(strings.TrimSpace(string(output)), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("tiempo de inactividad no válido: %q", strings.TrimSpace(string(output)))
+	}
+	return time.Duration(ms) * time.Millisecond, nil
+}
+
+// macBootTimeRegexp reconoce la salida de `sysctl -n kern.boottime`:
+// "{ sec = 1700000000, usec = 123456 } Tue Nov 14 22:13:20 2023"
+var macBootTimeRegexp = regexp.MustCompile(`sec = (\d+)`)
+
+func uptimeMac() (time.Duration, error) {
+	output, err := exec.Command("sysctl", "-n", "kern.boottime").Output()
+	if err != nil {
+		return 0, fmt.Errorf("error al ejecutar sysctl kern.boottime: %w", err)
+	}
+	m := macBootTimeRegexp.FindStringSubmatch(string(output))
+	if m == nil {
+		return 0, fmt.Errorf("respuesta de sysctl no válida: %q", strings.TrimSpace(string(output)))
+	}
+	boot, _ := strconv.ParseInt(m[1], 10, 64)
+	return time.Since(time.Unix(boot, 0)), nil
+}
+
+// macIdleRegexp reconoce la propiedad HIDIdleTime de IOHIDSystem, en
+// nanosegundos: `"HIDIdleTime" = 123456789`
+var macIdleRegexp = regexp.MustCompile(`"HIDIdleTime" = (\d+)`)
+
+func idleTimeMac() (time.Duration, error) {
+	output, err := exec.Command("ioreg", "-c", "IOHIDSystem", "-d", "4").Output()
+	if err != nil {
+		return 0, fmt.Errorf("error al ejecutar ioreg: %w", err)
+	}
+	m := macIdleRegexp.FindStringSubmatch(string(output))
+	if m == nil {
+		return 0, errors.New("ioreg no devolvió HIDIdleTime")
+	}
+	ns, _ := strconv.ParseInt(m[1], 10, 64)
+	return time.Duration(ns), nil
+}
+
+func HandleGetUptime(ctx context.Context, req *mcp.CallToolRequest, input struct{}) (*mcp.CallToolResult, UptimeOutput, error) {
+	uptime, idle, idleErr, err := getUptime()
+	if err != nil {
+		return nil, UptimeOutput{}, fmt.Errorf("❌ Error al obtener el tiempo de actividad: %w", err)
+	}
+	out := UptimeOutput{
+		UptimeSeconds: int64(uptime / time.Second),
+		Uptime:        humanizeDuration(uptime),
+	}
+	result := "⏱️ Encendido desde hace " + out.Uptime
+	if idleErr != nil {
+		out.IdleError = idleErr.Error()
+		result += fmt.Sprintf("\n⚠️ Inactividad desconocida: %v", idleErr)
+	} else {
+		seconds, human := int64(idle/time.Second), humanizeDuration(idle)
+		out.IdleSeconds, out.Idle = &seconds, &human
+		result += "\n💤 Sin actividad del usuario desde hace " + human
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: result},
+		},
+	}, out, nil
+}
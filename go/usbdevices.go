@@ -0,0 +1,327 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// USBDevice es un dispositivo USB conectado. Los campos que el sistema no
+// expone (muchos dispositivos no tienen número de serie) quedan vacíos.
+type USBDevice struct {
+	VendorID  string `json:"vendor_id,omitempty" jsonschema:"Identificador USB del fabricante en hexadecimal (ej: '046d')"`
+	ProductID string `json:"product_id,omitempty" jsonschema:"Identificador USB del producto en hexadecimal"`
+	Vendor    string `json:"vendor,omitempty" jsonschema:"Fabricante"`
+	Product   string `json:"product,omitempty" jsonschema:"Nombre del producto"`
+	Bus       string `json:"bus,omitempty" jsonschema:"Bus USB (en Windows, el concentrador)"`
+	Port      string `json:"port,omitempty" jsonschema:"Puerto o ubicación en el bus"`
+	Serial    string `json:"serial,omitempty" jsonschema:"Número de serie, si el dispositivo lo tiene"`
+	Change    string `json:"change,omitempty" jsonschema:"Con changed_since: 'added' si es nuevo o 'removed' si se ha desconectado desde la consulta anterior"`
+}
+
+// key identifica un dispositivo entre consultas: por número de serie si lo
+// tiene, para que cambiarlo de puerto no cuente como desconectarlo, y si no
+// por su ubicación
+func (d USBDevice) key() string {
+	if d.Serial != "" {
+		return d.VendorID + ":" + d.ProductID + ":" + d.Serial
+	}
+	return d.VendorID + ":" + d.ProductID + "@" + d.Bus + "-" + d.Port
+}
+
+// name es el nombre con el que se muestra el dispositivo
+func (d USBDevice) name() string {
+	if d.Product != "" {
+		return d.Product
+	}
+	return "Dispositivo " + d.VendorID + ":" + d.ProductID
+}
+
+type USBDevicesOutput struct {
+	Devices       []USBDevice `json:"devices,omitempty" jsonschema:"Dispositivos USB conectados (y, con changed_since, los desconectados)"`
+	PreviousQuery string      `json:"previous_query,omitempty" jsonschema:"Con changed_since: hora de la consulta con la que se compara (RFC 3339)"`
+}
+
+// usbLastListing es el último listado de dispositivos USB, con el que
+// changed_since compara el actual
+var (
+	usbLastMu      sync.Mutex
+	usbLastListing []USBDevice
+	usbLastTime    time.Time
+)
+
+// listUSBDevices devuelve los dispositivos USB conectados, sin los
+// concentradores raíz ni las controladoras. En WSL se listan los del
+// Windows anfitrión.
+func listUSBDevices() ([]USBDevice, error) {
+	var devices []USBDevice
+	var err error
+	switch currentPlatform() {
+	case platformWindows, platformWSL:
+		devices, err = usbDevicesWindows()
+	case platformMac:
+		devices, err = usbDevicesMac()
+	default:
+		devices, err = usbDevicesLinux()
+	}
+	if err != nil {
+		return nil, err
+	}
+	slices.SortStableFunc(devices, func(a, b USBDevice) int {
+		if c := strings.Compare(a.Bus, b.Bus); c != 0 {
+			return c
+		}
+		if c := strings.Compare(a.Port, b.Port); c != 0 {
+			return c
+		}
+		return strings.Compare(a.name(), b.name())
+	})
+	return devices, nil
+}
+
+// usbDevicesLinux recorre /sys/bus/usb/devices. Los dispositivos se llaman
+// "<bus>-<puertos>" (ej: "1-1.2"); las entradas con ':' son sus interfaces y
+// las "usbN", los concentradores raíz.
+func usbDevicesLinux() ([]USBDevice, error) {
+	entries, err := os.ReadDir("/sys/bus/usb/devices")
+	if err != nil {
+		return nil, fmt.Errorf("error al leer /sys/bus/usb/devices: %w", err)
+	}
+	var devices []USBDevice
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.Contains(name, ":") || strings.HasPrefix(name, "usb") {
+			continue
+		}
+		dir := filepath.Join("/sys/bus/usb/devices", name)
+		read := func(file string) string {
+			data, _ := os.ReadFile(filepath.Join(dir, file))
+			return strings.TrimSpace(string(data))
+		}
+		device := USBDevice{
+			VendorID:  read("idVendor"),
+			ProductID: read("idProduct"),
+			Vendor:    read("manufacturer"),
+			Product:   read("product"),
+			Bus:       read("busnum"),
+			Port:      read("devpath"),
+			Serial:    read("serial"),
+		}
+		if device.VendorID == "" {
+			continue
+		}
+		devices = append(devices, device)
+	}
+	return devices, nil
+}
+
+// macUSBIDRegexp separa el identificador y el nombre en los campos de
+// system_profiler: "0x05ac  (Apple Inc.)"
+var macUSBIDRegexp = regexp.MustCompile(`^0x([0-9a-fA-F]+)(?:\s*\((.+)\))?`)
+
+// usbDevicesMac recorre el árbol de `system_profiler SPUSBDataType -json`,
+// en el que cada controladora y concentrador lleva sus dispositivos en
+// "_items". Solo cuentan como dispositivos las entradas con vendor_id.
+func usbDevicesMac() ([]USBDevice, error) {
+	output, err := exec.Command("system_profiler", "SPUSBDataType", "-json").Output()
+	if err != nil {
+		return nil, fmt.Errorf("error al ejecutar system_profiler: %w", err)
+	}
+	var result struct {
+		SPUSBDataType []map[string]any
+	}
+	if err := json.Unmarshal(output, &result); err != nil {
+		return nil, fmt.Errorf("respuesta de system_profiler no válida: %w", err)
+	}
+	var devices []USBDevice
+	var walk func(items []map[string]any)
+	walk = func(items []map[string]any) {
+		for _, item := range items {
+			value := func(key string) string {
+				s, _ := item[key].(string)
+				return strings.TrimSpace(s)
+			}
+			if m := macUSBIDRegexp.FindStringSubmatch(value("vendor_id")); m != nil {
+				device := USBDevice{
+					VendorID: strings.ToLower(m[1]),
+					Vendor:   value("manufacturer"),
+					Product:  value("_name"),
+					Serial:   value("serial_num"),
+				}
+				if device.Vendor == "" {
+					device.Vendor = m[2]
+				}
+				if m := macUSBIDRegexp.FindStringSubmatch(value("product_id")); m != nil {
+					device.ProductID = strings.ToLower(m[1])
+				}
+				// "0x01100000 / 1": ubicación / dirección en el bus
+				device.Port, _, _ = strings.Cut(value("location_id"), " ")
+				devices = append(devices, device)
+			}
+			if children, ok := item["_items"].([]any); ok {
+				var childItems []map[string]any
+				for _, child := range children {
+					if m, ok := child.(map[string]any); ok {
+						childItems = append(childItems, m)
+					}
+				}
+				walk(childItems)
+			}
+		}
+	}
+	walk(result.SPUSBDataType)
+	return devices, nil
+}
+
+// windowsUSBScript lista los dispositivos de la clase USB presentes con
+// identificador de fabricante (sin controladoras ni concentradores raíz) y
+// su ubicación ("Port_#0002.Hub_#0001")
+const windowsUSBScript = `$devices = @(Get-PnpDevice -Class USB -PresentOnly -ErrorAction SilentlyContinue | Where-Object { $_.InstanceId -like 'USB\VID_*' })
+$location = @{}
+if ($devices.Count -gt 0) {
+	$devices | Get-PnpDeviceProperty -KeyName DEVPKEY_Device_LocationInfo -ErrorAction SilentlyContinue | ForEach-Object { $location[$_.InstanceId] = "$($_.Data)" }
+}
+ConvertTo-Json -Compress @($devices | ForEach-Object {
+	@{ id = $_.InstanceId; name = "$($_.FriendlyName)"; manufacturer = "$($_.Manufacturer)"; location = "$($location[$_.InstanceId])" }
+})`
+
+var (
+	// "USB\VID_046D&PID_C52B\5&2A1B3C4D&0&2"
+	windowsUSBIDRegexp = regexp.MustCompile(`(?i)^USB\\VID_([0-9A-F]{4})&PID_([0-9A-F]{4})[^\\]*\\(.+)$`)
+	// "Port_#0002.Hub_#0001"
+	windowsUSBLocationRegexp = regexp.MustCompile(`Port_#0*(\d+)\.Hub_#0*(\d+)`)
+)
+
+// usbDevicesWindows usa Get-PnpDevice. Windows usa el número de serie como
+// último segmento del identificador de instancia cuando el dispositivo lo
+// tiene; si no, es un identificador generado que contiene '&'.
+func usbDevicesWindows() ([]USBDevice, error) {
+	output, err := runPowerShell(windowsUSBScript)
+	if err != nil {
+		return nil, fmt.Errorf("error al consultar los dispositivos USB: %w", err)
+	}
+	var entries []struct {
+		ID, Name, Manufacturer, Location string
+	}
+	if err := json.Unmarshal([]byte(output), &entries); err != nil {
+		return nil, fmt.Errorf("respuesta de PowerShell no válida: %w", err)
+	}
+	var devices []USBDevice
+	for _, entry := range entries {
+		m := windowsUSBIDRegexp.FindStringSubmatch(entry.ID)
+		if m == nil {
+			continue
+		}
+		device := USBDevice{
+			VendorID:  strings.ToLower(m[1]),
+			ProductID: strings.ToLower(m[2]),
+			Vendor:    entry.Manufacturer,
+			Product:   entry.Name,
+			Port:      entry.Location,
+		}
+		if !strings.Contains(m[3], "&") {
+			device.Serial = m[3]
+		}
+		if l := windowsUSBLocationRegexp.FindStringSubmatch(entry.Location); l != nil {
+			device.Port, device.Bus = l[1], l[2]
+		}
+		devices = append(devices, device)
+	}
+	return devices, nil
+}
+
+// diffUSBDevices marca como 'added' los dispositivos que no estaban en el
+// listado anterior y añade al final, como 'removed', los que ya no están
+func diffUSBDevices(previous, current []USBDevice) []USBDevice {
+	before := map[string]bool{}
+	for _, d := range previous {
+		before[d.key()] = true
+	}
+	now := map[string]bool{}
+	result := make([]USBDevice, 0, len(current))
+	for _, d := range current {
+		now[d.key()] = true
+		if !before[d.key()] {
+			d.Change = "added"
+		}
+		result = append(result, d)
+	}
+	for _, d := range previous {
+		if !now[d.key()] {
+			d.Change = "removed"
+			result = append(result, d)
+		}
+	}
+	return result
+}
+
+type ListUSBDevicesInput struct {
+	ChangedSince bool `json:"changed_since,omitempty" jsonschema:"Marcar los dispositivos conectados o desconectados desde la consulta anterior"`
+}
+
+func HandleListUSBDevices(ctx context.Context, req *mcp.CallToolRequest, input ListUSBDevicesInput) (*mcp.CallToolResult, USBDevicesOutput, error) {
+	devices, err := listUSBDevices()
+	if err != nil {
+		return nil, USBDevicesOutput{}, fmt.Errorf("❌ Error al listar los dispositivos USB: %w", err)
+	}
+
+	usbLastMu.Lock()
+	previous, previousTime := usbLastListing, usbLastTime
+	usbLastListing, usbLastTime = devices, time.Now()
+	usbLastMu.Unlock()
+
+	out := USBDevicesOutput{Devices: devices}
+	note := ""
+	if input.ChangedSince {
+		if previousTime.IsZero() {
+			note = "ℹ️ No hay una consulta anterior con la que comparar; la próxima se comparará con esta"
+		} else {
+			out.Devices = diffUSBDevices(previous, devices)
+			out.PreviousQuery = previousTime.Format(time.RFC3339)
+			note = "ℹ️ Cambios respecto a la consulta de las " + previousTime.Format("15:04:05")
+		}
+	}
+
+	lines := []string{fmt.Sprintf("🔌 %d dispositivos USB conectados", len(devices))}
+	for _, d := range out.Devices {
+		details := []string{d.VendorID + ":" + d.ProductID}
+		if d.Vendor != "" {
+			details = append([]string{d.Vendor}, details...)
+		}
+		if d.Bus != "" {
+			details = append(details, "bus "+d.Bus)
+		}
+		if d.Port != "" {
+			details = append(details, "puerto "+d.Port)
+		}
+		if d.Serial != "" {
+			details = append(details, "serie "+d.Serial)
+		}
+		line := fmt.Sprintf("  - %s (%s)", d.name(), strings.Join(details, ", "))
+		switch d.Change {
+		case "added":
+			line += " ➕ nuevo"
+		case "removed":
+			line += " ➖ desconectado"
+		}
+		lines = append(lines, line)
+	}
+	if note != "" {
+		lines = append(lines, note)
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: strings.Join(lines, "\n")},
+		},
+	}, out, nil
+}
@@ -0,0 +1,430 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// psCoreAudio define la clase [Audio] en PowerShell para acceder al volumen
+// (general y por canal) del dispositivo de salida por defecto mediante la
+// API CoreAudio de Windows
+const psCoreAudio = `Add-Type -TypeDefinition @'
+using System.Runtime.InteropServices;
+[Guid("5CDF2C82-841E-4546-9722-0CF74078229A"), InterfaceType(ComInterfaceType.InterfaceIsIUnknown)]
+interface IAudioEndpointVolume {
+  int f(); int g();
+  int GetChannelCount(out uint pnChannelCount);
+  int i();
+  int SetMasterVolumeLevelScalar(float fLevel, System.Guid pguidEventContext);
+  int j();
+  int GetMasterVolumeLevelScalar(out float pfLevel);
+  int k();
+  int SetChannelVolumeLevelScalar(uint nChannel, float fLevel, System.Guid pguidEventContext);
+  int m();
+  int GetChannelVolumeLevelScalar(uint nChannel, out float pfLevel);
+  int SetMute([MarshalAs(UnmanagedType.Bool)] bool bMute, System.Guid pguidEventContext);
+  int GetMute(out bool pbMute);
+}
+[Guid("D666063F-1587-4E43-81F1-B948E807363F"), InterfaceType(ComInterfaceType.InterfaceIsIUnknown)]
+interface IMMDevice {
+  int Activate(ref System.Guid id, int clsCtx, int activationParams, out IAudioEndpointVolume aev);
+}
+[Guid("A95664D2-9614-4F35-A746-DE8DB63617E6"), InterfaceType(ComInterfaceType.InterfaceIsIUnknown)]
+interface IMMDeviceEnumerator {
+  int f();
+  int GetDefaultAudioEndpoint(int dataFlow, int role, out IMMDevice endpoint);
+}
+[ComImport, Guid("BCDE0395-E52F-467C-8E3D-C4579291692E")] class MMDeviceEnumeratorComObject { }
+public class Audio {
+  static IAudioEndpointVolume Vol() {
+    var enumerator = new MMDeviceEnumeratorComObject() as IMMDeviceEnumerator;
+    IMMDevice dev = null;
+    Marshal.ThrowExceptionForHR(enumerator.GetDefaultAudioEndpoint(0, 1, out dev));
+    IAudioEndpointVolume epv = null;
+    var epvid = typeof(IAudioEndpointVolume).GUID;
+    Marshal.ThrowExceptionForHR(dev.Activate(ref epvid, 23, 0, out epv));
+    return epv;
+  }
+  public static float Volume {
+    get { float v = -1; Marshal.ThrowExceptionForHR(Vol().GetMasterVolumeLevelScalar(out v)); return v; }
+    set { Marshal.ThrowExceptionForHR(Vol().SetMasterVolumeLevelScalar(value, System.Guid.Empty)); }
+  }
+  public static bool Mute {
+    get { bool mute; Marshal.ThrowExceptionForHR(Vol().GetMute(out mute)); return mute; }
+    set { Marshal.ThrowExceptionForHR(Vol().SetMute(value, System.Guid.Empty)); }
+  }
+  public static uint Channels {
+    get { uint n; Marshal.ThrowExceptionForHR(Vol().GetChannelCount(out n)); return n; }
+  }
+  public static float GetChannel(uint channel) {
+    float v; Marshal.ThrowExceptionForHR(Vol().GetChannelVolumeLevelScalar(channel, out v)); return v;
+  }
+  public static void SetChannel(uint channel, float level) {
+    Marshal.ThrowExceptionForHR(Vol().SetChannelVolumeLevelScalar(channel, level, System.Guid.Empty));
+  }
+}
+'@
+`
+
+// nircmdCommand devuelve el ejecutable de NirCmd si está instalado
+func nircmdCommand() (string, bool) {
+	name := "nircmd"
+	if isWSL() {
+		name = "nircmd.exe"
+	}
+	if _, err := exec.LookPath(name); err != nil {
+		return "", false
+	}
+	return name, true
+}
+
+// setVolume ajusta el volumen de salida del sistema (0-100) y devuelve el
+// resultado y el backend con el que se aplicó
+func setVolume(level int) (string, string) {
+	if level < 0 {
+		level = 0
+	}
+	if level > 100 {
+		level = 100
+	}
+
+	var cmd *exec.Cmd
+	var backend string
+
+	if osType == "windows" || isWSL() {
+		// Windows o WSL - NirCmd si está disponible, si no CoreAudio vía PowerShell
+		if nircmd, ok := nircmdCommand(); ok {
+			backend = "nircmd"
+			cmd = exec.Command(nircmd, "setsysvolume", strconv.Itoa(level*65535/100))
+		} else {
+			backend = "PowerShell CoreAudio"
+			script := psCoreAudio + fmt.Sprintf("[Audio]::Volume = %.2f", float64(level)/100.0)
+			cmd = exec.Command(powershellCommand(), "-Command", script)
+		}
+	} else if osType == "darwin" {
+		// macOS - usando AppleScript
+		backend = "osascript"
+		cmd = exec.Command("osascript", "-e", fmt.Sprintf("set volume output volume %d", level))
+	} else {
+		// Linux - pactl (PulseAudio/PipeWire) o amixer (ALSA)
+		if _, err := exec.LookPath("pactl"); err == nil {
+			backend = "pactl"
+			cmd = exec.Command("pactl", "set-sink-volume", "@DEFAULT_SINK@", fmt.Sprintf("%d%%", level))
+		} else if _, err := exec.LookPath("amixer"); err == nil {
+			backend = "amixer"
+			cmd = exec.Command("amixer", "-q", "set", "Master", fmt.Sprintf("%d%%", level))
+		} else {
+			return "❌ No se encontró pactl ni amixer para controlar el volumen", ""
+		}
+	}
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Sprintf("❌ Error al ajustar volumen (%s): %v", backend, err), backend
+	}
+
+	return fmt.Sprintf("🔊 Volumen ajustado a %d%% (vía %s)", level, backend), backend
+}
+
+// volumeFadeStep es el intervalo mínimo entre pasos de una transición de
+// volumen, para que el cambio suene continuo. psVolumeFadeStep es el de
+// PowerShell, que tarda del orden de un segundo en cada paso (arranque y
+// Add-Type de psCoreAudio): con pasos más cortos la transición duraría
+// minutos con volumeFadeMu tomado.
+const (
+	volumeFadeStep   = 50 * time.Millisecond
+	psVolumeFadeStep = time.Second
+)
+
+// volumeFadeInterval devuelve el intervalo mínimo entre pasos para el
+// backend que usará setVolume
+func volumeFadeInterval() time.Duration {
+	if osType == "windows" || isWSL() {
+		if _, ok := nircmdCommand(); !ok {
+			return psVolumeFadeStep
+		}
+	}
+	return volumeFadeStep
+}
+
+// volumeFadeMu serializa las transiciones de volumen: una nueva espera a que
+// termine la anterior en lugar de pelearse con ella
+var volumeFadeMu sync.Mutex
+
+// fadeVolume lleva el volumen al nivel indicado de forma gradual durante
+// duration, partiendo del volumen actual. Se detiene si se cancela ctx.
+func fadeVolume(ctx context.Context, level int, duration time.Duration) string {
+	level = min(max(level, 0), 100)
+	duration = min(duration, maxFadeDuration)
+
+	volumeFadeMu.Lock()
+	defer volumeFadeMu.Unlock()
+
+	state, err := readVolume()
+	if err != nil {
+		// Sin lectura del valor actual no hay desde dónde empezar: salto directo
+		result, _ := setVolume(level)
+		return fmt.Sprintf("⚠️ Sin transición (%v)\n%s", err, result)
+	}
+	start := state.Level
+
+	// Un paso por punto de diferencia como mucho, y no más de los que caben
+	// en duration con el intervalo del backend
+	steps := level - start
+	if steps < 0 {
+		steps = -steps
+	}
+	steps = max(min(steps, int(duration/volumeFadeInterval())), 1)
+	interval := duration / time.Duration(steps)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	// El backend que informa readVolume puede no ser el que ajusta el volumen
+	// (en Windows se lee con CoreAudio y se ajusta con NirCmd si está)
+	current := start
+	var backend string
+	for i := 1; i <= steps; i++ {
+		select {
+		case <-ctx.Done():
+			return fmt.Sprintf("⏹️ Transición de volumen cancelada en %d%% (de %d%% a %d%%)", current, start, level)
+		case <-ticker.C:
+		}
+		next := start + (level-start)*i/steps
+		result, used := setVolume(next)
+		if strings.HasPrefix(result, "❌") {
+			return result
+		}
+		current, backend = next, used
+	}
+	return fmt.Sprintf("🔊 Volumen: %d%% → %d%% en %v (vía %s)", start, level, duration, backend)
+}
+
+// volumeState representa el volumen de salida actual y si está silenciado
+type volumeState struct {
+	Level   int
+	Muted   bool
+	Backend string
+}
+
+// percentPattern extrae porcentajes como "50%" o "[50%]" de la salida de pactl/amixer
+var percentPattern = regexp.MustCompile(`(\d+)%`)
+
+// readVolume obtiene el volumen de salida actual (0-100) y el estado de silencio
+func readVolume() (volumeState, error) {
+	switch {
+	case osType == "windows" || isWSL():
+		// Windows o WSL - CoreAudio vía PowerShell
+		script := psCoreAudio + "[Audio]::Volume; [Audio]::Mute"
+		output, err := exec.Command(powershellCommand(), "-Command", script).Output()
+		if err != nil {
+			return volumeState{}, fmt.Errorf("error al consultar CoreAudio: %w", err)
+		}
+		lines := strings.Fields(string(output))
+		if len(lines) < 2 {
+			return volumeState{}, fmt.Errorf("salida inesperada de CoreAudio: %q", string(output))
+		}
+		val, err := strconv.ParseFloat(strings.Replace(lines[0], ",", ".", 1), 64)
+		if err != nil {
+			return volumeState{}, fmt.Errorf("no se pudo interpretar el volumen %q: %w", lines[0], err)
+		}
+		return volumeState{
+			Level:   int(val*100 + 0.5),
+			Muted:   strings.EqualFold(lines[1], "True"),
+			Backend: "PowerShell CoreAudio",
+		}, nil
+	case osType == "darwin":
+		// macOS - "output volume:50, input volume:75, alert volume:100, output muted:false"
+		output, err := exec.Command("osascript", "-e", "get volume settings").Output()
+		if err != nil {
+			return volumeState{}, fmt.Errorf("error al consultar osascript: %w", err)
+		}
+		state := volumeState{Level: -1, Backend: "osascript"}
+		for _, field := range strings.Split(strings.TrimSpace(string(output)), ",") {
+			key, value, _ := strings.Cut(strings.TrimSpace(field), ":")
+			switch key {
+			case "output volume":
+				if val, err := strconv.Atoi(value); err == nil {
+					state.Level = val
+				}
+			case "output muted":
+				state.Muted = value == "true"
+			}
+		}
+		if state.Level < 0 {
+			return volumeState{}, fmt.Errorf("el dispositivo de salida no informa del volumen: %q", string(output))
+		}
+		return state, nil
+	default:
+		// Linux - pactl (PulseAudio/PipeWire) o amixer (ALSA)
+		if _, err := exec.LookPath("pactl"); err == nil {
+			output, err := exec.Command("pactl", "get-sink-volume", "@DEFAULT_SINK@").Output()
+			if err != nil {
+				return volumeState{}, fmt.Errorf("error al consultar pactl: %w", err)
+			}
+			match := percentPattern.FindStringSubmatch(string(output))
+			if match == nil {
+				return volumeState{}, fmt.Errorf("no se pudo interpretar la salida de pactl: %q", string(output))
+			}
+			level, _ := strconv.Atoi(match[1])
+			mute, err := exec.Command("pactl", "get-sink-mute", "@DEFAULT_SINK@").Output()
+			if err != nil {
+				return volumeState{}, fmt.Errorf("error al consultar el silencio con pactl: %w", err)
+			}
+			return volumeState{
+				Level:   level,
+				Muted:   strings.Contains(string(mute), "yes"),
+				Backend: "pactl",
+			}, nil
+		}
+		if _, err := exec.LookPath("amixer"); err == nil {
+			// "Front Left: Playback 65536 [100%] [on]"
+			output, err := exec.Command("amixer", "get", "Master").Output()
+			if err != nil {
+				return volumeState{}, fmt.Errorf("error al consultar amixer: %w", err)
+			}
+			match := percentPattern.FindStringSubmatch(string(output))
+			if match == nil {
+				return volumeState{}, fmt.Errorf("no se pudo interpretar la salida de amixer: %q", string(output))
+			}
+			level, _ := strconv.Atoi(match[1])
+			return volumeState{
+				Level:   level,
+				Muted:   strings.Contains(string(output), "[off]"),
+				Backend: "amixer",
+			}, nil
+		}
+		return volumeState{}, errors.New("no se encontró pactl ni amixer para consultar el volumen")
+	}
+}
+
+// getVolume describe el volumen actual, indicando si la salida está silenciada
+func getVolume() (string, error) {
+	state, err := readVolume()
+	if err != nil {
+		return "", err
+	}
+	if state.Muted {
+		return fmt.Sprintf("🔇 Volumen actual: %d%% (silenciado, vía %s)", state.Level, state.Backend), nil
+	}
+	return fmt.Sprintf("🔊 Volumen actual: %d%% (vía %s)", state.Level, state.Backend), nil
+}
+
+// setMute silencia ("on"), reactiva ("off") o alterna ("toggle") la salida de audio
+func setMute(state string) (string, error) {
+	var mute bool
+	switch strings.ToLower(strings.TrimSpace(state)) {
+	case "on":
+		mute = true
+	case "off":
+		mute = false
+	case "toggle":
+		// Hay que leer el estado actual para saber cuál será el nuevo
+		current, err := readVolume()
+		if err != nil {
+			return "", fmt.Errorf("no se pudo leer el estado de silencio actual: %w", err)
+		}
+		mute = !current.Muted
+	default:
+		return "", fmt.Errorf("estado no válido %q: usa on, off o toggle", state)
+	}
+
+	var cmd *exec.Cmd
+
+	if osType == "windows" || isWSL() {
+		// Windows o WSL - CoreAudio vía PowerShell
+		script := psCoreAudio + fmt.Sprintf("[Audio]::Mute = $%t", mute)
+		cmd = exec.Command(powershellCommand(), "-Command", script)
+	} else if osType == "darwin" {
+		// macOS - usando AppleScript
+		script := "set volume without output muted"
+		if mute {
+			script = "set volume with output muted"
+		}
+		cmd = exec.Command("osascript", "-e", script)
+	} else {
+		// Linux - pactl (PulseAudio/PipeWire) o amixer (ALSA)
+		if _, err := exec.LookPath("pactl"); err == nil {
+			value := "0"
+			if mute {
+				value = "1"
+			}
+			cmd = exec.Command("pactl", "set-sink-mute", "@DEFAULT_SINK@", value)
+		} else if _, err := exec.LookPath("amixer"); err == nil {
+			action := "unmute"
+			if mute {
+				action = "mute"
+			}
+			cmd = exec.Command("amixer", "-q", "set", "Master", action)
+		} else {
+			return "", errors.New("no se encontró pactl ni amixer para controlar el silencio")
+		}
+	}
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("error al cambiar el silencio: %w", err)
+	}
+
+	// Confirmar el estado final leyéndolo de nuevo cuando sea posible
+	if current, err := readVolume(); err == nil {
+		mute = current.Muted
+	}
+	if mute {
+		return "🔇 Audio silenciado", nil
+	}
+	return "🔊 Audio activado (sin silencio)", nil
+}
+
+type SetVolumeInput struct {
+	Level      int `json:"level" jsonschema:"Nivel de volumen (0-100). 0=silencio, 100=máximo"`
+	DurationMs int `json:"duration_ms,omitempty" jsonschema:"Duración de una transición gradual en milisegundos (opcional, máximo 30000)"`
+}
+
+type SetMuteInput struct {
+	State string `json:"state" jsonschema:"Estado de silencio: on (silenciar), off (activar sonido) o toggle (alternar)"`
+}
+
+func HandleSetVolume(ctx context.Context, req *mcp.CallToolRequest, input SetVolumeInput) (*mcp.CallToolResult, any, error) {
+	var result string
+	if input.DurationMs > 0 {
+		result = fadeVolume(ctx, input.Level, time.Duration(input.DurationMs)*time.Millisecond)
+	} else {
+		result, _ = setVolume(input.Level)
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: result},
+		},
+	}, nil, nil
+}
+
+func HandleGetVolume(ctx context.Context, req *mcp.CallToolRequest, input struct{}) (*mcp.CallToolResult, any, error) {
+	result, err := getVolume()
+	if err != nil {
+		return nil, nil, fmt.Errorf("❌ Error al obtener volumen: %w", err)
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: result},
+		},
+	}, nil, nil
+}
+
+func HandleSetMute(ctx context.Context, req *mcp.CallToolRequest, input SetMuteInput) (*mcp.CallToolResult, any, error) {
+	result, err := setMute(input.State)
+	if err != nil {
+		return nil, nil, fmt.Errorf("❌ Error al cambiar el silencio: %w", err)
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: result},
+		},
+	}, nil, nil
+}
@@ -0,0 +1,432 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// vpnConnectTimeout es lo que set_vpn espera a que la VPN quede conectada o
+// desconectada; la negociación con el servidor puede tardar varios segundos
+const vpnConnectTimeout = 30 * time.Second
+
+// Estados normalizados de una VPN
+const (
+	vpnConnected     = "connected"
+	vpnConnecting    = "connecting"
+	vpnDisconnected  = "disconnected"
+	vpnDisconnecting = "disconnecting"
+)
+
+// vpnCredentialsRegexp reconoce los errores de credenciales que faltan o se
+// rechazan: NetworkManager sin secretos guardados y los errores 691, 703 y
+// 718 de rasdial
+var vpnCredentialsRegexp = regexp.MustCompile(`(?i)secrets were required|no secrets|error (691|703|718)\b`)
+
+type VPNConnection struct {
+	Name      string `json:"name" jsonschema:"Nombre de la conexión VPN"`
+	Type      string `json:"type,omitempty" jsonschema:"Tipo (vpn o wireguard en NetworkManager, [PPP:L2TP] o [IPSec] en macOS, el protocolo del túnel en Windows)"`
+	Status    string `json:"status" jsonschema:"connected, connecting, disconnected o disconnecting"`
+	Connected bool   `json:"connected" jsonschema:"La VPN está conectada"`
+	// External son las interfaces WireGuard levantadas fuera de
+	// NetworkManager (wg-quick, systemd-networkd): se muestran pero
+	// set_vpn no las gestiona
+	External bool `json:"external,omitempty" jsonschema:"Interfaz WireGuard gestionada fuera de NetworkManager; set_vpn no puede cambiarla"`
+
+	id string // UUID de NetworkManager o id del servicio de macOS
+}
+
+type GetVPNStatusOutput struct {
+	Connections []VPNConnection `json:"connections" jsonschema:"VPN configuradas y su estado"`
+	Note        string          `json:"note,omitempty" jsonschema:"Aclaración sobre el resultado"`
+}
+
+// listVPNConnections devuelve las VPN configuradas en el sistema
+func listVPNConnections() (GetVPNStatusOutput, error) {
+	var out GetVPNStatusOutput
+	var err error
+	switch currentPlatform() {
+	case platformWindows, platformWSL:
+		out.Connections, err = listVPNWindows()
+		if currentPlatform() == platformWSL {
+			out.Note = "VPN del Windows anfitrión"
+		}
+	case platformMac:
+		out.Connections, err = listVPNMac()
+	default:
+		out, err = listVPNLinux()
+	}
+	if err != nil {
+		return GetVPNStatusOutput{}, err
+	}
+	if out.Connections == nil {
+		out.Connections = []VPNConnection{}
+	}
+	slices.SortStableFunc(out.Connections, func(a, b VPNConnection) int {
+		return strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+	})
+	return out, nil
+}
+
+// nmcliVPNStates traduce el STATE de `nmcli connection show --active`
+var nmcliVPNStates = map[string]string{
+	"activated":    vpnConnected,
+	"activating":   vpnConnecting,
+	"deactivating": vpnDisconnecting,
+}
+
+// listVPNLinux combina los perfiles vpn y wireguard de NetworkManager con
+// las interfaces WireGuard que este no gestiona
+func listVPNLinux() (GetVPNStatusOutput, error) {
+	var out GetVPNStatusOutput
+	managedDevices := map[string]bool{}
+	if commandExists("nmcli")() {
+		profiles, err := runSystemCommand("nmcli", "-t", "-f", "NAME,UUID,TYPE", "connection", "show")
+		if err != nil {
+			return GetVPNStatusOutput{}, fmt.Errorf("error al consultar NetworkManager: %w", err)
+		}
+		active, err := runSystemCommand("nmcli", "-t", "-f", "UUID,DEVICE,STATE", "connection", "show", "--active")
+		if err != nil {
+			return GetVPNStatusOutput{}, fmt.Errorf("error al consultar NetworkManager: %w", err)
+		}
+		states := map[string]string{}
+		for _, line := range strings.Split(active, "\n") {
+			if fields := splitNmcliFields(line); len(fields) == 3 {
+				states[fields[0]] = fields[2]
+				managedDevices[fields[1]] = true
+			}
+		}
+		for _, line := range strings.Split(profiles, "\n") {
+			fields := splitNmcliFields(line)
+			if len(fields) != 3 || (fields[2] != "vpn" && fields[2] != "wireguard") {
+				continue
+			}
+			status, ok := nmcliVPNStates[states[fields[1]]]
+			if !ok {
+				status = vpnDisconnected
+			}
+			out.Connections = append(out.Connections, VPNConnection{Name: fields[0], Type: fields[2], Status: status, Connected: status == vpnConnected, id: fields[1]})
+		}
+	} else {
+		out.Note = "NetworkManager (nmcli) no está instalado: solo se muestran las interfaces WireGuard"
+	}
+
+	for _, iface := range linuxWireGuardInterfaces() {
+		if !managedDevices[iface] {
+			out.Connections = append(out.Connections, VPNConnection{Name: iface, Type: "wireguard", Status: vpnConnected, Connected: true, External: true})
+		}
+	}
+	return out, nil
+}
+
+// linuxWireGuardInterfaces devuelve las interfaces WireGuard activas según
+// `wg show interfaces` o, si wg no está instalado o no hay permisos, según
+// el DEVTYPE de /sys/class/net
+func linuxWireGuardInterfaces() []string {
+	if commandExists("wg")() {
+		if output, err := runSystemCommand("wg", "show", "interfaces"); err == nil {
+			return strings.Fields(output)
+		}
+	}
+	var ifaces []string
+	matches, _ := filepath.Glob("/sys/class/net/*/uevent")
+	for _, path := range matches {
+		if data, err := os.ReadFile(path); err == nil && strings.Contains(string(data), "DEVTYPE=wireguard") {
+			ifaces = append(ifaces, filepath.Base(filepath.Dir(path)))
+		}
+	}
+	return ifaces
+}
+
+// scutilVPNRegexp reconoce las líneas de `scutil --nc list`:
+// * (Connected)      6B1A...-ID IPSec   "Trabajo"   [IPSec]
+var scutilVPNRegexp = regexp.MustCompile(`^\*?\s*\(([^)]+)\)\s+([0-9A-Fa-f-]{36})\s+.*?"(.*)"\s+\[(.+)\]\s*$`)
+
+func listVPNMac() ([]VPNConnection, error) {
+	output, err := runSystemCommand("scutil", "--nc", "list")
+	if err != nil {
+		return nil, fmt.Errorf("error al consultar scutil: %w", err)
+	}
+	var connections []VPNConnection
+	for _, line := range strings.Split(output, "\n") {
+		m := scutilVPNRegexp.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		// Connected, Connecting, Disconnected, Disconnecting o Invalid
+		status := strings.ToLower(m[1])
+		if status != vpnConnected && status != vpnConnecting && status != vpnDisconnecting {
+			status = vpnDisconnected
+		}
+		connections = append(connections, VPNConnection{Name: m[3], Type: m[4], Status: status, Connected: status == vpnConnected, id: m[2]})
+	}
+	return connections, nil
+}
+
+// windowsVPNScript lista las VPN del usuario y las de todos los usuarios
+const windowsVPNScript = `
+$ErrorActionPreference = 'Stop'
+$vpns = @(Get-VpnConnection) + @(Get-VpnConnection -AllUserConnection -ErrorAction SilentlyContinue)
+ConvertTo-Json -Compress @($vpns | ForEach-Object {
+    @{ name = $_.Name; status = [string]$_.ConnectionStatus; type = [string]$_.TunnelType }
+})`
+
+func listVPNWindows() ([]VPNConnection, error) {
+	output, err := runPowerShell(windowsVPNScript)
+	if err != nil {
+		return nil, fmt.Errorf("error al consultar Get-VpnConnection: %w", err)
+	}
+	if output == "" {
+		return nil, nil
+	}
+	var entries []struct {
+		Name   string `json:"name"`
+		Status string `json:"status"`
+		Type   string `json:"type"`
+	}
+	if err := json.Unmarshal([]byte(output), &entries); err != nil {
+		return nil, fmt.Errorf("respuesta inesperada de Get-VpnConnection: %w", err)
+	}
+	var connections []VPNConnection
+	for _, e := range entries {
+		status := strings.ToLower(e.Status)
+		if status != vpnConnected && status != vpnConnecting {
+			status = vpnDisconnected
+		}
+		connections = append(connections, VPNConnection{Name: e.Name, Type: e.Type, Status: status, Connected: status == vpnConnected})
+	}
+	return connections, nil
+}
+
+func HandleGetVPNStatus(ctx context.Context, req *mcp.CallToolRequest, input struct{}) (*mcp.CallToolResult, GetVPNStatusOutput, error) {
+	out, err := listVPNConnections()
+	if err != nil {
+		return nil, GetVPNStatusOutput{}, fmt.Errorf("❌ Error al consultar las VPN: %w", err)
+	}
+
+	var lines []string
+	if len(out.Connections) == 0 {
+		lines = append(lines, "🔐 No hay ninguna VPN configurada")
+	} else {
+		lines = append(lines, fmt.Sprintf("🔐 %d VPN configuradas:", len(out.Connections)))
+	}
+	for _, c := range out.Connections {
+		line := "  - " + c.Name
+		if c.Type != "" {
+			line += " (" + c.Type + ")"
+		}
+		switch c.Status {
+		case vpnConnected:
+			line += " ✅ conectada"
+		case vpnConnecting:
+			line += " ⏳ conectando"
+		case vpnDisconnecting:
+			line += " ⏳ desconectando"
+		default:
+			line += " desconectada"
+		}
+		if c.External {
+			line += " (fuera de NetworkManager)"
+		}
+		lines = append(lines, line)
+	}
+	if out.Note != "" {
+		lines = append(lines, "ℹ️ "+out.Note)
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: strings.Join(lines, "\n")},
+		},
+	}, out, nil
+}
+
+// findVPNConnection busca una VPN por nombre exacto sin distinguir
+// mayúsculas y, si no, por coincidencia parcial. Si no hay ninguna o hay
+// varias, el error enumera los nombres.
+func findVPNConnection(connections []VPNConnection, query string) (VPNConnection, error) {
+	names := make([]string, len(connections))
+	for i, c := range connections {
+		names[i] = c.Name
+		if strings.EqualFold(c.Name, query) {
+			return c, nil
+		}
+	}
+	if len(connections) == 0 {
+		return VPNConnection{}, errors.New("no hay ninguna VPN configurada: créala desde los ajustes del sistema")
+	}
+
+	q := strings.ToLower(query)
+	var matches []VPNConnection
+	for _, c := range connections {
+		if strings.Contains(strings.ToLower(c.Name), q) {
+			matches = append(matches, c)
+		}
+	}
+	switch len(matches) {
+	case 1:
+		return matches[0], nil
+	case 0:
+		return VPNConnection{}, fmt.Errorf("no hay ninguna VPN que coincida con %q (disponibles: %s)", query, strings.Join(names, ", "))
+	}
+	ambiguous := make([]string, len(matches))
+	for i, c := range matches {
+		ambiguous[i] = c.Name
+	}
+	return VPNConnection{}, fmt.Errorf("%q coincide con varias VPN: %s. Usa el nombre completo", query, strings.Join(ambiguous, ", "))
+}
+
+// requestVPNConnection pide al sistema que conecte o desconecte la VPN.
+// scutil vuelve enseguida y nmcli o rasdial pueden hacerlo antes de que el
+// estado se actualice, así que el resultado se confirma después.
+func requestVPNConnection(c VPNConnection, up bool) error {
+	var err error
+	switch currentPlatform() {
+	case platformWindows, platformWSL:
+		args := []string{c.Name}
+		if !up {
+			args = append(args, "/disconnect")
+		}
+		_, err = runSystemCommandTimeout(vpnConnectTimeout, windowsExecutable("rasdial.exe"), args...)
+	case platformMac:
+		action := "stop"
+		if up {
+			action = "start"
+		}
+		_, err = runSystemCommand("scutil", "--nc", action, c.id)
+	default:
+		action := "down"
+		if up {
+			action = "up"
+		}
+		wait := fmt.Sprint(int(vpnConnectTimeout.Seconds()))
+		_, err = runSystemCommandTimeout(vpnConnectTimeout+5*time.Second, "nmcli", "--wait", wait, "connection", action, "uuid", c.id)
+	}
+	if err != nil && vpnCredentialsRegexp.MatchString(err.Error()) {
+		return fmt.Errorf("la VPN necesita credenciales guardadas en el sistema, o las guardadas no son válidas: %w", err)
+	}
+	return err
+}
+
+type SetVPNInput struct {
+	Name  string `json:"name" jsonschema:"Nombre de la VPN, como lo muestra get_vpn_status (basta una parte si no es ambigua)"`
+	State string `json:"state" jsonschema:"up para conectarla o down para desconectarla"`
+}
+
+type SetVPNOutput struct {
+	Connection VPNConnection `json:"connection" jsonschema:"La VPN con el estado leído tras el cambio"`
+	Changed    bool          `json:"changed" jsonschema:"Se cambió el estado (false si ya estaba así)"`
+	Note       string        `json:"note,omitempty" jsonschema:"Aclaración sobre el resultado"`
+}
+
+// setVPN conecta o desconecta una VPN y espera a que el estado leído lo
+// confirme
+func setVPN(ctx context.Context, input SetVPNInput) (SetVPNOutput, error) {
+	query := strings.TrimSpace(input.Name)
+	if query == "" {
+		return SetVPNOutput{}, errors.New("indica el nombre de la VPN")
+	}
+	state := strings.ToLower(strings.TrimSpace(input.State))
+	if state != "up" && state != "down" {
+		return SetVPNOutput{}, fmt.Errorf("estado no válido %q: usa up o down", input.State)
+	}
+	up := state == "up"
+
+	list, err := listVPNConnections()
+	if err != nil {
+		return SetVPNOutput{}, err
+	}
+	c, err := findVPNConnection(list.Connections, query)
+	if err != nil {
+		return SetVPNOutput{}, err
+	}
+	if c.External {
+		return SetVPNOutput{}, fmt.Errorf("%s es una interfaz WireGuard gestionada fuera de NetworkManager: usa wg-quick %s %s", c.Name, state, c.Name)
+	}
+	out := SetVPNOutput{Connection: c, Note: list.Note}
+	if c.Connected == up && c.Status != vpnDisconnecting {
+		return out, nil
+	}
+
+	if err := requestVPNConnection(c, up); err != nil {
+		if errors.Is(err, errRequiresAdmin) {
+			return SetVPNOutput{}, fmt.Errorf("no se pudo cambiar %s: %w", c.Name, err)
+		}
+		return SetVPNOutput{}, fmt.Errorf("error al cambiar %s: %w", c.Name, err)
+	}
+
+	// Solo cuenta el estado que se lee después; los estados intermedios
+	// (conectando, desconectando) siguen esperando
+	want := vpnDisconnected
+	if up {
+		want = vpnConnected
+	}
+	deadline := time.Now().Add(vpnConnectTimeout)
+	for c.Status != want && time.Now().Before(deadline) {
+		select {
+		case <-ctx.Done():
+			return SetVPNOutput{}, ctx.Err()
+		case <-time.After(time.Second):
+		}
+		after, err := listVPNConnections()
+		if err != nil {
+			continue
+		}
+		for _, a := range after.Connections {
+			if a.Name == c.Name && a.id == c.id && !a.External {
+				c = a
+			}
+		}
+	}
+	if c.Status != want {
+		if up {
+			return SetVPNOutput{}, fmt.Errorf("el sistema aceptó la orden, pero %s sigue %s tras %s: revisa las credenciales guardadas y que el servidor VPN sea accesible", c.Name, vpnStatusText(c.Status), vpnConnectTimeout)
+		}
+		return SetVPNOutput{}, fmt.Errorf("el sistema aceptó la orden, pero %s sigue %s tras %s", c.Name, vpnStatusText(c.Status), vpnConnectTimeout)
+	}
+	out.Connection = c
+	out.Changed = true
+	return out, nil
+}
+
+// vpnStatusText describe un estado normalizado para los mensajes
+func vpnStatusText(status string) string {
+	switch status {
+	case vpnConnected:
+		return "conectada"
+	case vpnConnecting:
+		return "conectando"
+	case vpnDisconnecting:
+		return "desconectando"
+	}
+	return "desconectada"
+}
+
+func HandleSetVPN(ctx context.Context, req *mcp.CallToolRequest, input SetVPNInput) (*mcp.CallToolResult, SetVPNOutput, error) {
+	out, err := setVPN(ctx, input)
+	if err != nil {
+		return nil, SetVPNOutput{}, fmt.Errorf("❌ Error al cambiar la VPN: %w", err)
+	}
+
+	line := fmt.Sprintf("🔐 VPN %s %s", out.Connection.Name, vpnStatusText(out.Connection.Status))
+	if !out.Changed {
+		line = fmt.Sprintf("🔐 La VPN %s ya estaba %s", out.Connection.Name, vpnStatusText(out.Connection.Status))
+	}
+	lines := []string{line}
+	if out.Note != "" {
+		lines = append(lines, "ℹ️ "+out.Note)
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: strings.Join(lines, "\n")},
+		},
+	}, out, nil
+}
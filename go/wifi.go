@@ -0,0 +1,318 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// wifiState es el estado de la Wi-Fi leído del sistema
+type wifiState struct {
+	Enabled     bool
+	HardBlocked bool   // interruptor físico, tecla de modo avión o firmware
+	Interface   string // interfaz (Linux, macOS) o adaptador (Windows)
+	Backend     string
+
+	// Solo en Windows: estado de la radio ("On", "Off", "Disabled"; vacío
+	// si la API de radios no está disponible) y del adaptador
+	radio   string
+	adminUp bool
+}
+
+// readWiFiState obtiene el estado de la Wi-Fi
+func readWiFiState() (wifiState, error) {
+	switch currentPlatform() {
+	case platformWindows, platformWSL:
+		return readWiFiWindows()
+	case platformMac:
+		return readWiFiMac()
+	}
+	return readWiFiLinux()
+}
+
+// writeWiFiState enciende o apaga la Wi-Fi
+func writeWiFiState(on bool, current wifiState) error {
+	switch currentPlatform() {
+	case platformWindows, platformWSL:
+		return writeWiFiWindows(on, current)
+	case platformMac:
+		power := "off"
+		if on {
+			power = "on"
+		}
+		// networksetup informa de algunos errores por la salida estándar y
+		// termina con 0; la lectura posterior lo detecta
+		_, err := runRadioCommand("networksetup", "-setairportpower", current.Interface, power)
+		return err
+	}
+
+	rfkill, hasRfkill := sbinCommand("rfkill")
+	if current.Backend == "nmcli" {
+		// Con el bloqueo de rfkill puesto (p. ej. tras `rfkill block all`)
+		// NetworkManager no puede encender la Wi-Fi
+		if soft, _, _ := linuxRfkill("wlan"); on && soft && hasRfkill {
+			if _, err := runRadioCommand(rfkill, "unblock", "wlan"); err != nil {
+				return err
+			}
+		}
+		power := "off"
+		if on {
+			power = "on"
+		}
+		_, err := runRadioCommand("nmcli", "radio", "wifi", power)
+		return err
+	}
+	if !hasRfkill {
+		return errors.New("no se encontró nmcli (NetworkManager) ni rfkill")
+	}
+	action := "block"
+	if on {
+		action = "unblock"
+	}
+	_, err := runRadioCommand(rfkill, action, "wlan")
+	return err
+}
+
+// linuxWiFiInterface devuelve la primera interfaz inalámbrica, o "" si no hay
+func linuxWiFiInterface() string {
+	matches, _ := filepath.Glob("/sys/class/net/*/wireless")
+	if len(matches) == 0 {
+		return ""
+	}
+	return filepath.Base(filepath.Dir(matches[0]))
+}
+
+// readWiFiLinux usa nmcli si NetworkManager está en marcha y si no los
+// interruptores rfkill de /sys. El bloqueo por hardware solo lo da rfkill.
+func readWiFiLinux() (wifiState, error) {
+	iface := linuxWiFiInterface()
+	soft, hard, found := linuxRfkill("wlan")
+	if iface == "" && !found {
+		return wifiState{}, errors.New("no se encontró ningún adaptador Wi-Fi")
+	}
+	state := wifiState{Interface: iface, HardBlocked: hard}
+	if commandExists("nmcli")() {
+		// "enabled" o "disabled"; falla si NetworkManager no está en marcha.
+		// Con el bloqueo de rfkill puesto la radio está apagada diga lo que
+		// diga NetworkManager.
+		if output, err := runRadioCommand("nmcli", "-t", "radio", "wifi"); err == nil {
+			state.Enabled = output == "enabled" && !soft && !hard
+			state.Backend = "nmcli"
+			return state, nil
+		}
+	}
+	if !found {
+		return wifiState{}, errors.New("NetworkManager no está en marcha y el adaptador Wi-Fi no tiene interruptor rfkill")
+	}
+	state.Enabled = !soft && !hard
+	state.Backend = "rfkill"
+	return state, nil
+}
+
+// macWiFiPowerRegexp reconoce "Wi-Fi Power (en0): On" de
+// `networksetup -getairportpower`
+var macWiFiPowerRegexp = regexp.MustCompile(`\):\s*(On|Off)\b`)
+
+func readWiFiMac() (wifiState, error) {
+	output, err := exec.Command("networksetup", "-listallhardwareports").Output()
+	if err != nil {
+		return wifiState{}, fmt.Errorf("error al consultar networksetup: %w", err)
+	}
+	m := macWiFiPortRegexp.FindStringSubmatch(string(output))
+	if m == nil {
+		return wifiState{}, errors.New("no se encontró ningún adaptador Wi-Fi")
+	}
+	device := m[1]
+	text, err := runRadioCommand("networksetup", "-getairportpower", device)
+	if err != nil {
+		return wifiState{}, fmt.Errorf("error al consultar networksetup: %w", err)
+	}
+	power := macWiFiPowerRegexp.FindStringSubmatch(text)
+	if power == nil {
+		return wifiState{}, fmt.Errorf("respuesta inesperada de networksetup: %q", text)
+	}
+	return wifiState{Enabled: power[1] == "On", Interface: device, Backend: "networksetup"}, nil
+}
+
+// windowsWiFiStateScript devuelve en JSON el adaptador Wi-Fi físico
+// (NdisPhysicalMedium 9 es Native 802.11), si está habilitado y el estado
+// de su radio
+const windowsWiFiStateScript = windowsRadioPrelude + `
+$adapter = Get-NetAdapter -Physical -ErrorAction SilentlyContinue | Where-Object { $_.NdisPhysicalMedium -eq 9 } | Select-Object -First 1
+$wifiRadio = $radios | Where-Object { $_.Kind -eq 'WiFi' } | Select-Object -First 1
+$radio = ''
+if ($wifiRadio) { $radio = [string]$wifiRadio.State }
+if (-not $adapter -and -not $radio) { return }
+@{ adapter = [string]$adapter.Name; admin_up = [bool]($adapter -and $adapter.AdminStatus -eq 'Up'); radio = $radio } | ConvertTo-Json -Compress
+`
+
+func readWiFiWindows() (wifiState, error) {
+	output, err := runPowerShell(windowsWiFiStateScript)
+	if err != nil {
+		return wifiState{}, fmt.Errorf("error al consultar la Wi-Fi: %w", err)
+	}
+	if output == "" {
+		return wifiState{}, errors.New("no se encontró ningún adaptador Wi-Fi")
+	}
+	var result struct {
+		Adapter string `json:"adapter"`
+		AdminUp bool   `json:"admin_up"`
+		Radio   string `json:"radio"`
+	}
+	if err := json.Unmarshal([]byte(output), &result); err != nil {
+		return wifiState{}, fmt.Errorf("respuesta inesperada de PowerShell: %w", err)
+	}
+	state := wifiState{Interface: result.Adapter, Backend: "radio", radio: result.Radio, adminUp: result.AdminUp}
+	switch {
+	case result.Radio == "":
+		// Sin API de radios (o con el adaptador deshabilitado, que la oculta)
+		state.Backend = "netsh"
+		state.Enabled = result.AdminUp
+	default:
+		state.Enabled = result.Radio == "On" && (result.Adapter == "" || result.AdminUp)
+		state.HardBlocked = result.Radio == "Disabled"
+	}
+	return state, nil
+}
+
+// writeWiFiWindows usa la API de radios, que no necesita privilegios. Si el
+// adaptador está deshabilitado (o la API no está disponible) hay que usar
+// netsh, que sí los necesita.
+func writeWiFiWindows(on bool, current wifiState) error {
+	if on && current.Interface != "" && !current.adminUp {
+		if err := setWindowsWiFiAdapter(current.Interface, true); err != nil {
+			return err
+		}
+		// Al habilitar el adaptador su radio vuelve a aparecer, quizá apagada
+		if after, err := readWiFiWindows(); err == nil && after.radio != "" && after.radio != "On" {
+			return setWindowsRadio("WiFi", "Wi-Fi", true)
+		}
+		return nil
+	}
+	if current.radio != "" {
+		return setWindowsRadio("WiFi", "Wi-Fi", on)
+	}
+	if current.Interface == "" {
+		return errors.New("no se encontró el adaptador Wi-Fi para netsh")
+	}
+	return setWindowsWiFiAdapter(current.Interface, on)
+}
+
+// setWindowsWiFiAdapter habilita o deshabilita el adaptador con netsh. El
+// nombre viene de Get-NetAdapter y se pasa como un único argumento.
+func setWindowsWiFiAdapter(adapter string, enable bool) error {
+	admin := "disabled"
+	if enable {
+		admin = "enabled"
+	}
+	_, err := runRadioCommand(windowsExecutable("netsh.exe"), "interface", "set", "interface", "name="+adapter, "admin="+admin)
+	if err != nil {
+		return fmt.Errorf("netsh no pudo cambiar el adaptador %q: %w", adapter, err)
+	}
+	return nil
+}
+
+// onOff describe el estado de la Wi-Fi
+func onOff(on bool) string {
+	if on {
+		return "encendida"
+	}
+	return "apagada"
+}
+
+type SetWiFiInput struct {
+	State string `json:"state" jsonschema:"Estado de la Wi-Fi: on (encender), off (apagar) o toggle (alternar)"`
+}
+
+type SetWiFiOutput struct {
+	Enabled     bool   `json:"enabled" jsonschema:"La Wi-Fi está encendida, según el estado leído tras el cambio"`
+	Previous    bool   `json:"previous" jsonschema:"Estado anterior de la Wi-Fi"`
+	Changed     bool   `json:"changed" jsonschema:"Se cambió el estado (false si ya estaba como se pedía)"`
+	Interface   string `json:"interface,omitempty" jsonschema:"Interfaz o adaptador Wi-Fi"`
+	Method      string `json:"method" jsonschema:"Método usado: nmcli, rfkill, networksetup, radio (API de radios de Windows) o netsh"`
+	HardBlocked bool   `json:"hard_blocked,omitempty" jsonschema:"La Wi-Fi está bloqueada por un interruptor físico o el modo avión del equipo"`
+	Note        string `json:"note,omitempty" jsonschema:"Aclaración sobre el resultado"`
+}
+
+// setWiFi enciende ("on"), apaga ("off") o alterna ("toggle") la Wi-Fi y
+// lee el estado resultante, que es el que se devuelve
+func setWiFi(state string) (SetWiFiOutput, error) {
+	state = strings.ToLower(strings.TrimSpace(state))
+	if state != "on" && state != "off" && state != "toggle" {
+		return SetWiFiOutput{}, fmt.Errorf("estado no válido %q: usa on, off o toggle", state)
+	}
+	current, err := readWiFiState()
+	if err != nil {
+		return SetWiFiOutput{}, fmt.Errorf("no se pudo leer el estado de la Wi-Fi: %w", err)
+	}
+	want := state == "on"
+	if state == "toggle" {
+		want = !current.Enabled
+	}
+	out := SetWiFiOutput{Enabled: current.Enabled, Previous: current.Enabled, Interface: current.Interface, Method: current.Backend, HardBlocked: current.HardBlocked}
+	if want && current.HardBlocked {
+		return SetWiFiOutput{}, errors.New("la Wi-Fi está bloqueada por hardware (interruptor físico o tecla de modo avión) y no se puede encender por software")
+	}
+	if current.Enabled == want {
+		return out, nil
+	}
+
+	if err := writeWiFiState(want, current); err != nil {
+		if errors.Is(err, errRadioRequiresAdmin) {
+			return SetWiFiOutput{}, fmt.Errorf("no se pudo dejar la Wi-Fi %s (%s): %w. Ejecuta el servidor como administrador o, en Linux, con un usuario autorizado en NetworkManager", onOff(want), current.Backend, err)
+		}
+		return SetWiFiOutput{}, fmt.Errorf("error al cambiar la Wi-Fi (%s): %w", current.Backend, err)
+	}
+
+	final := waitForRadioState(want, current, readWiFiState, func(s wifiState) bool { return s.Enabled })
+	if final.Enabled != want {
+		return SetWiFiOutput{}, fmt.Errorf("el sistema aceptó el cambio, pero la Wi-Fi sigue %s", onOff(final.Enabled))
+	}
+	out.Enabled = final.Enabled
+	out.Changed = true
+	out.Method = final.Backend
+	out.HardBlocked = final.HardBlocked
+	if !want && os.Getenv("SSH_CONNECTION") != "" {
+		out.Note = "El servidor se ejecuta en una sesión SSH: si llegaba por Wi-Fi se habrá cortado"
+	}
+	if currentPlatform() == platformWSL {
+		out.Note = strings.TrimSpace("Es la Wi-Fi del Windows anfitrión, de la que también depende la red de WSL. " + out.Note)
+	}
+	return out, nil
+}
+
+func HandleSetWiFi(ctx context.Context, req *mcp.CallToolRequest, input SetWiFiInput) (*mcp.CallToolResult, SetWiFiOutput, error) {
+	out, err := setWiFi(input.State)
+	if err != nil {
+		return nil, SetWiFiOutput{}, fmt.Errorf("❌ Error al cambiar la Wi-Fi: %w", err)
+	}
+
+	icon := "📶"
+	if !out.Enabled {
+		icon = "📴"
+	}
+	line := fmt.Sprintf("%s Wi-Fi %s", icon, onOff(out.Enabled))
+	if out.Interface != "" {
+		line += " (" + out.Interface + ")"
+	}
+	if !out.Changed {
+		line += ": ya estaba así"
+	}
+	lines := []string{line}
+	if out.Note != "" {
+		lines = append(lines, "ℹ️ "+out.Note)
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: strings.Join(lines, "\n")},
+		},
+	}, out, nil
+}
@@ -0,0 +1,565 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os/exec"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// connectWiFiTimeout es lo que se espera a que la red quede asociada y con
+// dirección IPv4 (el DHCP puede tardar varios segundos)
+const connectWiFiTimeout = 20 * time.Second
+
+// errWiFiNotSaved indica que la red no tiene un perfil guardado. connect_wifi
+// nunca recibe contraseñas, así que la red hay que guardarla desde el sistema.
+var errWiFiNotSaved = errors.New("la red no está guardada en este equipo: conéctate a ella una vez desde los ajustes de Wi-Fi del sistema (así se guarda la contraseña) y vuelve a intentarlo")
+
+// validateSSID comprueba que el SSID tenga como mucho 32 bytes y ningún
+// carácter de control
+func validateSSID(ssid string) error {
+	if ssid == "" {
+		return errors.New("indica el SSID de la red")
+	}
+	if len(ssid) > 32 {
+		return fmt.Errorf("el SSID %q es demasiado largo (máximo 32 bytes)", ssid)
+	}
+	if strings.ContainsFunc(ssid, unicode.IsControl) {
+		return fmt.Errorf("el SSID %q contiene caracteres de control", ssid)
+	}
+	return nil
+}
+
+// wifiInterface devuelve la interfaz Wi-Fi (el adaptador en Windows) y
+// comprueba que la Wi-Fi esté encendida
+func wifiInterface() (string, error) {
+	state, err := readWiFiState()
+	if err != nil {
+		return "", err
+	}
+	if !state.Enabled {
+		return "", errors.New("la Wi-Fi está apagada: enciéndela primero (set_wifi)")
+	}
+	if state.Interface == "" {
+		return "", errors.New("no se encontró la interfaz Wi-Fi")
+	}
+	return state.Interface, nil
+}
+
+// savedWiFiNetworks devuelve las redes guardadas, de SSID a nombre del perfil
+// (en NetworkManager el perfil puede llamarse distinto que la red)
+func savedWiFiNetworks(iface string) (map[string]string, error) {
+	saved := map[string]string{}
+	switch currentPlatform() {
+	case platformWindows, platformWSL:
+		// "    All User Profile     : Mi red" (la etiqueta está traducida);
+		// el perfil se llama como la red salvo que se haya renombrado
+		output, err := exec.Command(windowsExecutable("netsh.exe"), "wlan", "show", "profiles").Output()
+		if err != nil {
+			return nil, fmt.Errorf("error al consultar netsh: %w", err)
+		}
+		for _, line := range strings.Split(strings.ReplaceAll(string(output), "\r", ""), "\n") {
+			if _, name, ok := strings.Cut(line, " : "); ok && strings.HasPrefix(line, "    ") {
+				name = strings.TrimSpace(name)
+				saved[name] = name
+			}
+		}
+	case platformMac:
+		// "Preferred networks on en0:\n\tMi red"
+		output, err := exec.Command("networksetup", "-listpreferredwirelessnetworks", iface).Output()
+		if err != nil {
+			return nil, fmt.Errorf("error al consultar networksetup: %w", err)
+		}
+		for _, line := range strings.Split(string(output), "\n") {
+			if name, ok := strings.CutPrefix(line, "\t"); ok && strings.TrimSpace(name) != "" {
+				saved[name] = name
+			}
+		}
+	default:
+		if !commandExists("nmcli")() {
+			return nil, errors.New("requiere nmcli (NetworkManager)")
+		}
+		output, err := exec.Command("nmcli", "-t", "-f", "NAME,TYPE", "connection", "show").Output()
+		if err != nil {
+			return nil, fmt.Errorf("error al consultar nmcli: %w", err)
+		}
+		for _, line := range strings.Split(string(output), "\n") {
+			fields := splitNmcliFields(line)
+			if len(fields) != 2 || fields[1] != "802-11-wireless" {
+				continue
+			}
+			ssid, err := exec.Command("nmcli", "-g", "802-11-wireless.ssid", "connection", "show", "id", fields[0]).Output()
+			if err != nil {
+				continue
+			}
+			// -g también escapa los ':'
+			saved[strings.Join(splitNmcliFields(strings.TrimSpace(string(ssid))), ":")] = fields[0]
+		}
+	}
+	return saved, nil
+}
+
+type WiFiNetwork struct {
+	SSID          string `json:"ssid" jsonschema:"Nombre de la red"`
+	SignalPercent *int   `json:"signal_percent,omitempty" jsonschema:"Calidad de la señal en porcentaje (Linux, Windows)"`
+	SignalDBm     *int   `json:"signal_dbm,omitempty" jsonschema:"Nivel de la señal en dBm (macOS)"`
+	Security      string `json:"security,omitempty" jsonschema:"Seguridad de la red (p. ej. WPA2); vacío si es abierta o no se conoce"`
+	Connected     bool   `json:"connected" jsonschema:"Es la red a la que está conectado el equipo"`
+	Saved         bool   `json:"saved" jsonschema:"Está guardada en el equipo, así que connect_wifi puede conectarse a ella"`
+}
+
+type ListWiFiNetworksOutput struct {
+	Networks []WiFiNetwork `json:"networks" jsonschema:"Redes visibles, de mejor a peor señal"`
+	Note     string        `json:"note,omitempty" jsonschema:"Aclaración sobre el resultado"`
+}
+
+// scanWiFiLinux usa nmcli, que vuelve a escanear si el último escaneo es
+// antiguo. "*:Mi\: red:72:WPA2" (nmcli escapa los ':' del SSID).
+func scanWiFiLinux(iface string) ([]WiFiNetwork, error) {
+	if !commandExists("nmcli")() {
+		return nil, errors.New("requiere nmcli (NetworkManager)")
+	}
+	output, err := exec.Command("nmcli", "-t", "-f", "IN-USE,SSID,SIGNAL,SECURITY", "device", "wifi", "list", "ifname", iface, "--rescan", "auto").Output()
+	if err != nil {
+		return nil, fmt.Errorf("error al consultar nmcli: %w", err)
+	}
+	var networks []WiFiNetwork
+	for _, line := range strings.Split(string(output), "\n") {
+		fields := splitNmcliFields(line)
+		if len(fields) != 4 {
+			continue
+		}
+		network := WiFiNetwork{SSID: fields[1], Connected: fields[0] == "*"}
+		if signal, err := strconv.Atoi(fields[2]); err == nil {
+			network.SignalPercent = &signal
+		}
+		if fields[3] != "--" {
+			network.Security = fields[3]
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
+// macAirportScanRegexp reconoce una línea de `airport -s`, con el SSID
+// alineado a la derecha: "  Mi red a4:2b:..:e1 -56  36  Y  ES WPA2(PSK/AES/AES)".
+// Las versiones recientes ocultan el BSSID.
+var macAirportScanRegexp = regexp.MustCompile(`^\s*(.+?)\s+(?:[0-9a-f]{2}(?::[0-9a-f]{2}){5}\s+)?(-\d+)\s+\S+\s+\S+\s+\S+\s+(.*)$`)
+
+// macProfilerNetworks es la parte de `system_profiler SPAirPortDataType
+// -json` que se usa cuando airport ya no existe (macOS 14.4 o posterior)
+type macProfilerNetworks struct {
+	SPAirPortDataType []struct {
+		Interfaces []struct {
+			Name    string               `json:"_name"`
+			Current *macProfilerNetwork  `json:"spairport_current_network_information"`
+			Others  []macProfilerNetwork `json:"spairport_airport_other_local_wireless_networks"`
+		} `json:"spairport_airport_interfaces"`
+	}
+}
+
+type macProfilerNetwork struct {
+	Name     string `json:"_name"`
+	Signal   string `json:"spairport_signal_noise"`
+	Security string `json:"spairport_security_mode"`
+}
+
+func scanWiFiMac(iface string) ([]WiFiNetwork, error) {
+	var networks []WiFiNetwork
+	if output, err := exec.Command(macAirportPath, "-s").Output(); err == nil {
+		for _, line := range strings.Split(string(output), "\n")[1:] {
+			m := macAirportScanRegexp.FindStringSubmatch(line)
+			if m == nil {
+				continue
+			}
+			dbm, _ := strconv.Atoi(m[2])
+			network := WiFiNetwork{SSID: m[1], SignalDBm: &dbm, Security: strings.TrimSpace(m[3])}
+			if network.Security == "NONE" {
+				network.Security = ""
+			}
+			networks = append(networks, network)
+		}
+		// Desde macOS 14.4 airport sigue existiendo pero solo imprime un aviso
+		if len(networks) > 0 {
+			return networks, nil
+		}
+	}
+
+	output, err := exec.Command("system_profiler", "SPAirPortDataType", "-json").Output()
+	if err != nil {
+		return nil, fmt.Errorf("error al consultar system_profiler: %w", err)
+	}
+	var profile macProfilerNetworks
+	if err := json.Unmarshal(output, &profile); err != nil {
+		return nil, fmt.Errorf("respuesta inesperada de system_profiler: %w", err)
+	}
+	for _, data := range profile.SPAirPortDataType {
+		for _, i := range data.Interfaces {
+			if i.Name != iface {
+				continue
+			}
+			add := func(n macProfilerNetwork, connected bool) {
+				network := WiFiNetwork{SSID: n.Name, Connected: connected}
+				// "-56 dBm / -90 dBm"
+				var dbm int
+				if _, err := fmt.Sscanf(n.Signal, "%d dBm", &dbm); err == nil {
+					network.SignalDBm = &dbm
+				}
+				// "spairport_security_mode_wpa2_personal" -> "wpa2 personal"
+				network.Security = strings.ReplaceAll(strings.TrimPrefix(n.Security, "spairport_security_mode_"), "_", " ")
+				if network.Security == "none" {
+					network.Security = ""
+				}
+				networks = append(networks, network)
+			}
+			if i.Current != nil {
+				add(*i.Current, true)
+			}
+			for _, n := range i.Others {
+				add(n, false)
+			}
+		}
+	}
+	return networks, nil
+}
+
+// scanWiFiWindows interpreta `netsh wlan show networks mode=bssid`, cuyas
+// etiquetas están traducidas: cada red empieza por "SSID n : nombre", la
+// autenticación es la segunda línea del bloque y la señal el primer valor
+// con '%'. netsh muestra el resultado del último escaneo de Windows.
+func scanWiFiWindows(iface string) ([]WiFiNetwork, error) {
+	args := []string{"wlan", "show", "networks", "mode=bssid"}
+	if currentPlatform() == platformWindows {
+		args = append(args, "interface="+iface)
+	}
+	output, err := exec.Command(windowsExecutable("netsh.exe"), args...).CombinedOutput()
+	if err != nil {
+		if strings.Contains(strings.ToLower(string(output)), "location") {
+			return nil, errors.New("Windows requiere permiso de ubicación para ver las redes Wi-Fi: actívalo en Configuración > Privacidad y seguridad > Ubicación")
+		}
+		return nil, fmt.Errorf("error al consultar netsh: %w", err)
+	}
+	var networks []WiFiNetwork
+	values := 0
+	for _, line := range strings.Split(strings.ReplaceAll(string(output), "\r", ""), "\n") {
+		key, value, ok := strings.Cut(line, " : ")
+		if !ok {
+			continue
+		}
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if strings.HasPrefix(key, "SSID ") {
+			networks = append(networks, WiFiNetwork{SSID: value})
+			values = 0
+			continue
+		}
+		if len(networks) == 0 {
+			continue
+		}
+		network := &networks[len(networks)-1]
+		values++
+		if values == 2 && value != "Open" && value != "Abierta" {
+			network.Security = value
+		}
+		if percent, ok := strings.CutSuffix(value, "%"); ok && network.SignalPercent == nil {
+			if signal, err := strconv.Atoi(percent); err == nil {
+				network.SignalPercent = &signal
+			}
+		}
+	}
+	return networks, nil
+}
+
+// wifiSignal ordena las redes por señal, en porcentaje o en dBm según la
+// plataforma
+func wifiSignal(n WiFiNetwork) int {
+	switch {
+	case n.SignalPercent != nil:
+		return *n.SignalPercent
+	case n.SignalDBm != nil:
+		return *n.SignalDBm
+	}
+	return -1000
+}
+
+// listWiFiNetworks devuelve las redes visibles, una vez cada SSID (con la
+// mejor señal de sus puntos de acceso), marcando la conectada y las guardadas
+func listWiFiNetworks() (ListWiFiNetworksOutput, error) {
+	iface, err := wifiInterface()
+	if err != nil {
+		return ListWiFiNetworksOutput{}, err
+	}
+	var networks []WiFiNetwork
+	switch currentPlatform() {
+	case platformWindows, platformWSL:
+		networks, err = scanWiFiWindows(iface)
+	case platformMac:
+		networks, err = scanWiFiMac(iface)
+	default:
+		networks, err = scanWiFiLinux(iface)
+	}
+	if err != nil {
+		return ListWiFiNetworksOutput{}, err
+	}
+	saved, _ := savedWiFiNetworks(iface)
+	current, _ := currentWiFiSSID(iface)
+
+	out := ListWiFiNetworksOutput{Networks: []WiFiNetwork{}}
+	index := map[string]int{}
+	for _, network := range networks {
+		// Las redes ocultas no tienen SSID
+		if network.SSID == "" {
+			continue
+		}
+		network.Connected = network.Connected || network.SSID == current
+		_, network.Saved = saved[network.SSID]
+		if i, ok := index[network.SSID]; ok {
+			if wifiSignal(network) > wifiSignal(out.Networks[i]) {
+				network.Connected = network.Connected || out.Networks[i].Connected
+				out.Networks[i] = network
+			}
+			continue
+		}
+		index[network.SSID] = len(out.Networks)
+		out.Networks = append(out.Networks, network)
+	}
+	slices.SortStableFunc(out.Networks, func(a, b WiFiNetwork) int {
+		return wifiSignal(b) - wifiSignal(a)
+	})
+	if currentPlatform() == platformMac && slices.ContainsFunc(out.Networks, func(n WiFiNetwork) bool { return n.SSID == "<redacted>" }) {
+		out.Note = "macOS oculta los nombres de las redes a las aplicaciones sin permiso de localización: concédelo a la aplicación que ejecuta el servidor en Ajustes del Sistema > Privacidad y seguridad > Localización"
+	}
+	if currentPlatform() == platformWSL {
+		out.Note = "Redes vistas por la Wi-Fi del Windows anfitrión"
+	}
+	return out, nil
+}
+
+func HandleListWiFiNetworks(ctx context.Context, req *mcp.CallToolRequest, input struct{}) (*mcp.CallToolResult, ListWiFiNetworksOutput, error) {
+	out, err := listWiFiNetworks()
+	if err != nil {
+		return nil, ListWiFiNetworksOutput{}, fmt.Errorf("❌ Error al listar las redes Wi-Fi: %w", err)
+	}
+
+	lines := []string{fmt.Sprintf("📶 %d redes Wi-Fi visibles:", len(out.Networks))}
+	for _, n := range out.Networks {
+		line := "  - " + n.SSID
+		switch {
+		case n.SignalPercent != nil:
+			line += fmt.Sprintf(" (%d%%)", *n.SignalPercent)
+		case n.SignalDBm != nil:
+			line += fmt.Sprintf(" (%d dBm)", *n.SignalDBm)
+		}
+		if n.Security == "" {
+			line += " abierta"
+		}
+		if n.Connected {
+			line += " ✅ conectada"
+		} else if n.Saved {
+			line += " 💾 guardada"
+		}
+		lines = append(lines, line)
+	}
+	if out.Note != "" {
+		lines = append(lines, "ℹ️ "+out.Note)
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: strings.Join(lines, "\n")},
+		},
+	}, out, nil
+}
+
+// macAirportNetworkRegexp reconoce "Current Wi-Fi Network: Mi red" de
+// `networksetup -getairportnetwork`
+var macAirportNetworkRegexp = regexp.MustCompile(`Network: (.+)`)
+
+// currentWiFiSSID devuelve la red a la que está asociada la interfaz Wi-Fi,
+// con los mismos métodos que get_network_info
+func currentWiFiSSID(iface string) (string, bool) {
+	info := NetworkInfoOutput{Interface: iface}
+	switch currentPlatform() {
+	case platformWindows, platformWSL:
+		wifiInfoWindows(&info)
+	case platformMac:
+		wifiInfoMac(&info)
+		if info.SSID == nil {
+			// Sin permiso de localización ipconfig oculta el SSID
+			if output, err := exec.Command("networksetup", "-getairportnetwork", iface).Output(); err == nil {
+				if m := macAirportNetworkRegexp.FindStringSubmatch(string(output)); m != nil {
+					ssid := strings.TrimSpace(m[1])
+					info.SSID = &ssid
+				}
+			}
+		}
+	default:
+		wifiInfoLinux(&info)
+	}
+	if info.SSID == nil {
+		return "", false
+	}
+	return *info.SSID, true
+}
+
+// wifiIPv4 devuelve las direcciones IPv4 de la interfaz Wi-Fi, sin las de
+// enlace local (169.254.x.x) que quedan cuando el DHCP no responde
+func wifiIPv4(iface string) []string {
+	var addrs []string
+	if currentPlatform() == platformWindows || currentPlatform() == platformWSL {
+		output, err := runPowerShell(fmt.Sprintf(`Get-NetIPAddress -InterfaceAlias '%s' -AddressFamily IPv4 -ErrorAction SilentlyContinue | ForEach-Object { $_.IPAddress }`,
+			strings.ReplaceAll(iface, "'", "''")))
+		if err != nil {
+			return nil
+		}
+		for _, field := range strings.Fields(output) {
+			if ip := net.ParseIP(field); ip != nil && !ip.IsLinkLocalUnicast() {
+				addrs = append(addrs, field)
+			}
+		}
+		return addrs
+	}
+	i, err := net.InterfaceByName(iface)
+	if err != nil {
+		return nil
+	}
+	list, _ := i.Addrs()
+	for _, addr := range list {
+		if ipnet, ok := addr.(*net.IPNet); ok && ipnet.IP.To4() != nil && !ipnet.IP.IsLinkLocalUnicast() {
+			addrs = append(addrs, ipnet.IP.String())
+		}
+	}
+	return addrs
+}
+
+type ConnectWiFiInput struct {
+	SSID string `json:"ssid" jsonschema:"Nombre de una red Wi-Fi guardada en el equipo (nunca se pide contraseña)"`
+}
+
+type ConnectWiFiOutput struct {
+	SSID      string   `json:"ssid" jsonschema:"Red a la que está conectado el equipo tras el cambio"`
+	Interface string   `json:"interface" jsonschema:"Interfaz o adaptador Wi-Fi"`
+	IPv4      []string `json:"ipv4" jsonschema:"Direcciones IPv4 obtenidas en la red"`
+	Changed   bool     `json:"changed" jsonschema:"Se cambió de red (false si ya estaba conectado a ella)"`
+	Note      string   `json:"note,omitempty" jsonschema:"Aclaración sobre el resultado"`
+}
+
+// joinSavedWiFi pide al sistema que se conecte con el perfil guardado
+func joinSavedWiFi(iface, ssid, profile string) error {
+	var err error
+	switch currentPlatform() {
+	case platformWindows, platformWSL:
+		args := []string{"wlan", "connect", "name=" + profile}
+		if currentPlatform() == platformWindows {
+			args = append(args, "interface="+iface)
+		}
+		_, err = runRadioCommand(windowsExecutable("netsh.exe"), args...)
+	case platformMac:
+		// Sin contraseña networksetup usa la guardada en el llavero. Algunos
+		// fallos ("Could not find network") salen con código 0; la
+		// comprobación posterior los detecta.
+		var output string
+		output, err = runRadioCommand("networksetup", "-setairportnetwork", iface, ssid)
+		if err == nil && output != "" {
+			err = errors.New(output)
+		}
+	default:
+		_, err = runRadioCommand("nmcli", "connection", "up", "id", profile, "ifname", iface)
+	}
+	return err
+}
+
+// connectWiFi se conecta a una red guardada y espera a que quede asociada y
+// con dirección IPv4
+func connectWiFi(ctx context.Context, ssid string) (ConnectWiFiOutput, error) {
+	if err := validateSSID(ssid); err != nil {
+		return ConnectWiFiOutput{}, err
+	}
+	iface, err := wifiInterface()
+	if err != nil {
+		return ConnectWiFiOutput{}, err
+	}
+	out := ConnectWiFiOutput{SSID: ssid, Interface: iface}
+	if currentPlatform() == platformWSL {
+		out.Note = "Es la Wi-Fi del Windows anfitrión; las direcciones son las suyas, no las de WSL"
+	}
+	if current, ok := currentWiFiSSID(iface); ok && current == ssid {
+		if out.IPv4 = wifiIPv4(iface); len(out.IPv4) > 0 {
+			return out, nil
+		}
+	}
+
+	saved, err := savedWiFiNetworks(iface)
+	if err != nil {
+		return ConnectWiFiOutput{}, err
+	}
+	profile, ok := saved[ssid]
+	if !ok {
+		return ConnectWiFiOutput{}, fmt.Errorf("%q: %w", ssid, errWiFiNotSaved)
+	}
+	if err := joinSavedWiFi(iface, ssid, profile); err != nil {
+		if errors.Is(err, errRadioRequiresAdmin) {
+			return ConnectWiFiOutput{}, fmt.Errorf("no se pudo conectar a %q: %w", ssid, err)
+		}
+		return ConnectWiFiOutput{}, fmt.Errorf("error al conectar a %q: %w", ssid, err)
+	}
+
+	// El sistema puede aceptar la orden y no llegar a asociarse (contraseña
+	// cambiada, red fuera de alcance) o quedarse sin DHCP: solo cuenta lo
+	// que se lee después
+	deadline := time.Now().Add(connectWiFiTimeout)
+	current := ""
+	for {
+		current, _ = currentWiFiSSID(iface)
+		if current == ssid {
+			if out.IPv4 = wifiIPv4(iface); len(out.IPv4) > 0 {
+				out.Changed = true
+				return out, nil
+			}
+		}
+		if time.Now().After(deadline) {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return ConnectWiFiOutput{}, ctx.Err()
+		case <-time.After(time.Second):
+		}
+	}
+	switch current {
+	case ssid:
+		return ConnectWiFiOutput{}, fmt.Errorf("conectado a %q, pero sin dirección IPv4 tras %s: el DHCP de la red no responde", ssid, connectWiFiTimeout)
+	case "":
+		return ConnectWiFiOutput{}, fmt.Errorf("el sistema aceptó la orden, pero no está conectado a ninguna red tras %s: revisa la contraseña guardada o si la red está al alcance", connectWiFiTimeout)
+	}
+	return ConnectWiFiOutput{}, fmt.Errorf("el sistema aceptó la orden, pero sigue conectado a %q tras %s", current, connectWiFiTimeout)
+}
+
+func HandleConnectWiFi(ctx context.Context, req *mcp.CallToolRequest, input ConnectWiFiInput) (*mcp.CallToolResult, ConnectWiFiOutput, error) {
+	out, err := connectWiFi(ctx, input.SSID)
+	if err != nil {
+		return nil, ConnectWiFiOutput{}, fmt.Errorf("❌ Error al conectar a la Wi-Fi: %w", err)
+	}
+
+	line := fmt.Sprintf("📶 Conectado a %s (%s), IP %s", out.SSID, out.Interface, strings.Join(out.IPv4, ", "))
+	if !out.Changed {
+		line = fmt.Sprintf("📶 Ya estaba conectado a %s (%s), IP %s", out.SSID, out.Interface, strings.Join(out.IPv4, ", "))
+	}
+	lines := []string{line}
+	if out.Note != "" {
+		lines = append(lines, "ℹ️ "+out.Note)
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: strings.Join(lines, "\n")},
+		},
+	}, out, nil
+}
@@ -0,0 +1,227 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// psDxva2 define la clase [Dxva2] en PowerShell para controlar el brillo de
+// monitores externos por DDC/CI con la API de monitores de bajo nivel
+// (GetMonitorBrightness/SetMonitorBrightness de dxva2.dll)
+const psDxva2 = `Add-Type -TypeDefinition @'
+using System;
+using System.Collections.Generic;
+using System.Runtime.InteropServices;
+public class Dxva2 {
+  [StructLayout(LayoutKind.Sequential, CharSet = CharSet.Unicode)]
+  public struct PHYSICAL_MONITOR {
+    public IntPtr hPhysicalMonitor;
+    [MarshalAs(UnmanagedType.ByValTStr, SizeConst = 128)] public string szPhysicalMonitorDescription;
+  }
+  delegate bool MonitorEnumProc(IntPtr hMonitor, IntPtr hdc, IntPtr rect, IntPtr data);
+  [DllImport("user32.dll")] static extern bool EnumDisplayMonitors(IntPtr hdc, IntPtr clip, MonitorEnumProc proc, IntPtr data);
+  [DllImport("dxva2.dll")] static extern bool GetNumberOfPhysicalMonitorsFromHMONITOR(IntPtr hMonitor, out uint count);
+  [DllImport("dxva2.dll")] static extern bool GetPhysicalMonitorsFromHMONITOR(IntPtr hMonitor, uint count, [Out] PHYSICAL_MONITOR[] monitors);
+  [DllImport("dxva2.dll")] public static extern bool GetMonitorBrightness(IntPtr monitor, out uint min, out uint cur, out uint max);
+  [DllImport("dxva2.dll")] public static extern bool SetMonitorBrightness(IntPtr monitor, uint value);
+  public static List<PHYSICAL_MONITOR> Monitors() {
+    var list = new List<PHYSICAL_MONITOR>();
+    EnumDisplayMonitors(IntPtr.Zero, IntPtr.Zero, (h, dc, r, d) => {
+      uint n;
+      if (GetNumberOfPhysicalMonitorsFromHMONITOR(h, out n) && n > 0) {
+        var monitors = new PHYSICAL_MONITOR[n];
+        if (GetPhysicalMonitorsFromHMONITOR(h, n, monitors)) { list.AddRange(monitors); }
+      }
+      return true;
+    }, IntPtr.Zero);
+    return list;
+  }
+}
+'@
+`
+
+// psWMIBrightnessMethods y psDxva2Monitors son las expresiones de PowerShell
+// que enumeran los monitores controlables por cada mecanismo
+const (
+	psWMIBrightnessMethods = "@(Get-WmiObject -Namespace root/WMI -Class WmiMonitorBrightnessMethods -ErrorAction SilentlyContinue)"
+	psDxva2Monitors        = "@([Dxva2]::Monitors())"
+)
+
+// psNoMonitors es la marca que escriben los scripts cuando no hay monitores
+const psNoMonitors = "NO_MONITORS"
+
+// psBrightnessTargets genera el PowerShell que enumera los monitores con
+// source y deja en $targets los que indica display: todos si está vacío o es
+// "all", uno por índice, o los que contengan el texto en su nombre (nameExpr).
+// Si no hay ningún monitor escribe psNoMonitors y termina.
+func psBrightnessTargets(source, nameExpr, display string) string {
+	script := fmt.Sprintf("$m = %s; if ($m.Count -eq 0) { '%s'; exit }; ", source, psNoMonitors)
+	if display == "" || strings.EqualFold(display, "all") {
+		return script + "$targets = $m; "
+	}
+	if index, err := strconv.Atoi(display); err == nil {
+		return script + fmt.Sprintf("if (%d -ge $m.Count) { throw \"Índice de display fuera de rango (hay $($m.Count))\" }; $targets = @($m[%d]); ", index, index)
+	}
+	// El texto del usuario solo aparece en una cadena entre comillas simples;
+	// en el mensaje del throw, entre comillas dobles, se ejecutaría un $(...)
+	name := strings.ReplaceAll(display, "'", "''")
+	return script + fmt.Sprintf("$name = '%s'; $targets = @($m | Where-Object { %s -like ('*' + $name + '*') }); if ($targets.Count -eq 0) { throw ('No se encontró el display ''' + $name + '''') }; ", name, nameExpr)
+}
+
+// setBrightnessWindows ajusta el brillo en Windows o WSL. Los paneles
+// internos se controlan por WMI; si WMI no expone ningún monitor (equipos de
+// sobremesa con monitores externos) se recurre a DDC/CI mediante Dxva2.
+// Los id de list_displays ("DISPLAY2") se traducen a la instancia WMI del
+// monitor; DDC/CI los busca por la descripción, así que solo admite índices.
+func setBrightnessWindows(level int, display string) string {
+	target := display
+	if instance, ok, err := windowsDisplayInstance(display); err != nil {
+		return fmt.Sprintf("❌ Error al ajustar brillo: %v", err)
+	} else if ok {
+		target = instance
+	}
+	script := psBrightnessTargets(psWMIBrightnessMethods, "$_.InstanceName", target) +
+		fmt.Sprintf("$targets | ForEach-Object { $r = $_.WmiSetBrightness(1,%d); \"$($_.InstanceName)|$($r.ReturnValue)\" }", level)
+	output, err := runPowerShell(script)
+	if err != nil {
+		return fmt.Sprintf("❌ Error al ajustar brillo: %v", err)
+	}
+	if output != psNoMonitors {
+		if err := parseWMISetResults(output); err != nil {
+			return fmt.Sprintf("❌ Error al ajustar brillo: %v", err)
+		}
+		if display != "" {
+			return fmt.Sprintf("✅ Brillo ajustado a %d%% (display %s)", level, display)
+		}
+		return fmt.Sprintf("✅ Brillo ajustado a %d%%", level)
+	}
+
+	// Sin WmiMonitorBrightnessMethods: probar DDC/CI con la API de Dxva2
+	if err := checkGraphicalSession(); err != nil {
+		return fmt.Sprintf("❌ Error al ajustar brillo: WMI no expone ningún monitor y DDC/CI necesita el escritorio: %v", err)
+	}
+	script = psDxva2 + psBrightnessTargets(psDxva2Monitors, "$_.szPhysicalMonitorDescription", display) +
+		fmt.Sprintf(`$targets | ForEach-Object {
+  [uint32]$min = 0; [uint32]$cur = 0; [uint32]$max = 0
+  if (-not [Dxva2]::GetMonitorBrightness($_.hPhysicalMonitor, [ref]$min, [ref]$cur, [ref]$max)) { throw "El monitor '$($_.szPhysicalMonitorDescription)' no soporta DDC/CI" }
+  if (-not [Dxva2]::SetMonitorBrightness($_.hPhysicalMonitor, [uint32]($min + ($max - $min) * %d / 100))) { throw "No se pudo ajustar '$($_.szPhysicalMonitorDescription)'" }
+  $_.szPhysicalMonitorDescription
+}`, level)
+	output, err = runPowerShell(script)
+	if err != nil {
+		return fmt.Sprintf("❌ Error al ajustar brillo: %v", err)
+	}
+	if output == psNoMonitors || output == "" {
+		return "❌ No se encontraron pantallas con brillo controlable (ni WMI ni DDC/CI)"
+	}
+	monitors := strings.Join(strings.Split(strings.ReplaceAll(output, "\r\n", "\n"), "\n"), ", ")
+	return fmt.Sprintf("✅ Brillo ajustado a %d%% en %s (DDC/CI vía Dxva2)", level, monitors)
+}
+
+// listDisplayBrightnessWindows lee el brillo de cada monitor por WMI o, si
+// WMI no expone ninguno, por DDC/CI con la API de Dxva2
+func listDisplayBrightnessWindows() ([]DisplayBrightness, error) {
+	script := "Get-WmiObject -Namespace root/WMI -Class WmiMonitorBrightness -ErrorAction SilentlyContinue | ForEach-Object { \"$($_.InstanceName)|$($_.CurrentBrightness)\" }"
+	output, err := runPowerShell(script)
+	if err != nil {
+		return nil, err
+	}
+	source := ""
+	if output == "" {
+		script = psDxva2 + `foreach ($mon in [Dxva2]::Monitors()) {
+  [uint32]$min = 0; [uint32]$cur = 0; [uint32]$max = 0
+  if ([Dxva2]::GetMonitorBrightness($mon.hPhysicalMonitor, [ref]$min, [ref]$cur, [ref]$max) -and $max -gt $min) {
+    "$($mon.szPhysicalMonitorDescription)|$([int](($cur - $min) * 100 / ($max - $min)))"
+  }
+}`
+		output, err = runPowerShell(script)
+		if err != nil {
+			return nil, err
+		}
+		if output == "" {
+			return nil, errors.New("no se encontraron pantallas con brillo controlable (ni WMI ni DDC/CI)")
+		}
+		source = "DDC/CI"
+	}
+
+	return parseWindowsBrightnessList(output, source)
+}
+
+// wmiReturnCodes traduce los códigos que devuelve WmiSetBrightness. Algunos
+// controladores devuelven errores Win32 y otros códigos WBEM.
+var wmiReturnCodes = map[uint32]string{
+	1:          "error genérico del controlador",
+	2:          "acceso denegado",
+	5:          "acceso denegado",
+	50:         "operación no soportada por el monitor",
+	87:         "parámetro no válido",
+	0x80041001: "error genérico de WMI",
+	0x80041003: "acceso denegado por WMI",
+	0x8004100C: "operación no soportada por WMI",
+}
+
+// describeWMIReturnCode devuelve un mensaje legible para un código de retorno
+func describeWMIReturnCode(code uint32) string {
+	if msg, ok := wmiReturnCodes[code]; ok {
+		return fmt.Sprintf("%s (código %d)", msg, code)
+	}
+	return fmt.Sprintf("código de error desconocido %d (0x%X)", code, code)
+}
+
+// parseWMISetResults interpreta las líneas "InstanceName|ReturnValue" que
+// escribe el script de WmiSetBrightness. PowerShell termina con éxito aunque
+// el método falle, así que hay que comprobar el código de cada monitor.
+func parseWMISetResults(output string) error {
+	var failures []string
+	results := 0
+	for _, line := range strings.Split(strings.ReplaceAll(output, "\r\n", "\n"), "\n") {
+		name, value, ok := strings.Cut(strings.TrimSpace(line), "|")
+		if !ok {
+			continue
+		}
+		results++
+		code, err := strconv.ParseUint(strings.TrimSpace(value), 10, 32)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s: respuesta inesperada de WmiSetBrightness %q", name, value))
+			continue
+		}
+		if code != 0 {
+			failures = append(failures, fmt.Sprintf("%s: %s", name, describeWMIReturnCode(uint32(code))))
+		}
+	}
+	if results == 0 {
+		return fmt.Errorf("WmiSetBrightness no devolvió ningún resultado: %q", output)
+	}
+	if len(failures) > 0 {
+		return errors.New(strings.Join(failures, "; "))
+	}
+	return nil
+}
+
+// parseWindowsBrightnessList interpreta las líneas "nombre|porcentaje" de los
+// scripts de lectura de brillo. Una línea mal formada es un error en lugar de
+// omitirse, para no informar de un brillo que no se ha leído.
+func parseWindowsBrightnessList(output, source string) ([]DisplayBrightness, error) {
+	var displays []DisplayBrightness
+	for _, line := range strings.Split(strings.ReplaceAll(output, "\r\n", "\n"), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(line, "|")
+		if !ok {
+			return nil, fmt.Errorf("salida inesperada al leer el brillo: %q", line)
+		}
+		percent, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || percent < 0 || percent > 100 {
+			return nil, fmt.Errorf("valor de brillo no válido para %s: %q", name, value)
+		}
+		displays = append(displays, DisplayBrightness{Name: name, Percent: percent, Source: source})
+	}
+	if len(displays) == 0 {
+		return nil, errors.New("no se pudo leer el brillo de ninguna pantalla")
+	}
+	return displays, nil
+}
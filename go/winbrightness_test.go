@@ -0,0 +1,103 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseWMISetResults(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		err    string // fragmento del error esperado; vacío si no hay error
+	}{
+		{"un monitor", `DISPLAY\BOE0900\4&2a6d9a5a&0&UID265988_0|0`, ""},
+		{"dos monitores con CRLF", "DISPLAY\\BOE0900\\4&1_0|0\r\nDISPLAY\\DELA0B1\\5&2_0|0\r\n", ""},
+		{"acceso denegado", "DISPLAY\\BOE0900\\4&1_0|0\r\nDISPLAY\\DELA0B1\\5&2_0|5", `DISPLAY\DELA0B1\5&2_0: acceso denegado (código 5)`},
+		{"código WBEM", `DISPLAY\BOE0900\4&1_0|2147749900`, "operación no soportada por WMI (código 2147749900)"},
+		{"código desconocido", `DISPLAY\BOE0900\4&1_0|999`, "código de error desconocido 999 (0x3E7)"},
+		{"valor no numérico", `DISPLAY\BOE0900\4&1_0|True`, `respuesta inesperada de WmiSetBrightness "True"`},
+		{"sin resultados", "", "no devolvió ningún resultado"},
+		{"salida sin separador", "Exception calling WmiSetBrightness", "no devolvió ningún resultado"},
+	}
+	for _, tc := range tests {
+		err := parseWMISetResults(tc.output)
+		switch {
+		case tc.err == "" && err != nil:
+			t.Errorf("%s: parseWMISetResults = %v; se esperaba nil", tc.name, err)
+		case tc.err != "" && (err == nil || !strings.Contains(err.Error(), tc.err)):
+			t.Errorf("%s: parseWMISetResults = %v; se esperaba un error con %q", tc.name, err, tc.err)
+		}
+	}
+}
+
+func TestDescribeWMIReturnCode(t *testing.T) {
+	tests := []struct {
+		code uint32
+		want string
+	}{
+		{1, "error genérico del controlador (código 1)"},
+		{2, "acceso denegado (código 2)"},
+		{5, "acceso denegado (código 5)"},
+		{50, "operación no soportada por el monitor (código 50)"},
+		{87, "parámetro no válido (código 87)"},
+		{0x80041001, "error genérico de WMI (código 2147749889)"},
+		{0x80041003, "acceso denegado por WMI (código 2147749891)"},
+		{0x8004100C, "operación no soportada por WMI (código 2147749900)"},
+		{31, "código de error desconocido 31 (0x1F)"},
+	}
+	for _, tc := range tests {
+		if got := describeWMIReturnCode(tc.code); got != tc.want {
+			t.Errorf("describeWMIReturnCode(%d) = %q; se esperaba %q", tc.code, got, tc.want)
+		}
+	}
+	// Todos los códigos conocidos tienen mensaje propio
+	for code := range wmiReturnCodes {
+		if strings.Contains(describeWMIReturnCode(code), "desconocido") {
+			t.Errorf("describeWMIReturnCode(%d) no usa wmiReturnCodes", code)
+		}
+	}
+}
+
+func TestParseWindowsBrightnessList(t *testing.T) {
+	// Salida de WmiMonitorBrightness en un portátil con un monitor externo
+	output := "DISPLAY\\BOE0900\\4&2a6d9a5a&0&UID265988_0|75\r\nDISPLAY\\DELA0B1\\5&1b2c3d4e&0&UID4352_0|100\r\n\r\n"
+	got, err := parseWindowsBrightnessList(output, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []DisplayBrightness{
+		{Name: `DISPLAY\BOE0900\4&2a6d9a5a&0&UID265988_0`, Percent: 75},
+		{Name: `DISPLAY\DELA0B1\5&1b2c3d4e&0&UID4352_0`, Percent: 100},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseWindowsBrightnessList = %+v; se esperaba %+v", got, want)
+	}
+
+	// Salida del script de DDC/CI, con la descripción del monitor
+	got, err = parseWindowsBrightnessList("Generic PnP Monitor| 40 \n", "DDC/CI")
+	if want := []DisplayBrightness{{Name: "Generic PnP Monitor", Percent: 40, Source: "DDC/CI"}}; err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("parseWindowsBrightnessList (DDC/CI) = %+v, %v; se esperaba %+v", got, err, want)
+	}
+
+	for _, bad := range []string{"", "\r\n", "DISPLAY1", "DISPLAY1|", "DISPLAY1|abc", "DISPLAY1|-1", "DISPLAY1|101", "DISPLAY1|50\r\nbasura"} {
+		if got, err := parseWindowsBrightnessList(bad, ""); err == nil {
+			t.Errorf("parseWindowsBrightnessList(%q) = %+v; se esperaba un error", bad, got)
+		}
+	}
+}
+
+func TestPSBrightnessTargets(t *testing.T) {
+	// Todos, por índice y por nombre; el nombre solo como cadena literal
+	if script := psBrightnessTargets(psWMIBrightnessMethods, "$_.InstanceName", "all"); !strings.Contains(script, "$targets = $m;") {
+		t.Errorf("psBrightnessTargets con all no elige todos: %s", script)
+	}
+	if script := psBrightnessTargets(psWMIBrightnessMethods, "$_.InstanceName", "1"); !strings.Contains(script, "$targets = @($m[1])") {
+		t.Errorf("psBrightnessTargets con un índice no lo usa: %s", script)
+	}
+	for _, display := range append(injectionNames, "Dell'; rm") {
+		checkPSLiteral(t, psBrightnessTargets(psWMIBrightnessMethods, "$_.InstanceName", display), display)
+		checkPSLiteral(t, psBrightnessTargets(psDxva2Monitors, "$_.szPhysicalMonitorDescription", display), display)
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// appsFolderPrefix es el prefijo con el que explorer abre una aplicación
+// por su AppUserModelID (las de la Store, entre otras)
+const appsFolderPrefix = `shell:AppsFolder\`
+
+// resolveWindowsApp decide cómo lanzar appName en Windows y devuelve la
+// orden, a la que se añaden después los argumentos. Prueba por orden:
+// shell:AppsFolder, el registro App Paths, el PATH y los accesos directos
+// del menú Inicio. Cada paso queda en el log para poder diagnosticar fallos.
+func resolveWindowsApp(appName string, hasArgs bool) ([]string, error) {
+	if strings.HasPrefix(strings.ToLower(appName), strings.ToLower(appsFolderPrefix)) {
+		if hasArgs {
+			return nil, errors.New("no se pueden pasar argumentos a una aplicación abierta por su AppUserModelID")
+		}
+		log.Printf("🔍 open_app: '%s' se abre con explorer (shell:AppsFolder)", appName)
+		return []string{"explorer.exe", appName}, nil
+	}
+
+	exe := appName
+	if filepath.Ext(exe) == "" {
+		exe += ".exe"
+	}
+	if !strings.ContainsAny(exe, `\/`) {
+		if path, err := appPathsLookup(exe); err != nil {
+			log.Printf("🔍 open_app: error al consultar App Paths para %s: %v", exe, err)
+		} else if path != "" {
+			log.Printf("🔍 open_app: '%s' encontrada en App Paths: %s", appName, path)
+			return []string{path}, nil
+		}
+		log.Printf("🔍 open_app: '%s' no está en App Paths", appName)
+	}
+
+	if path, err := exec.LookPath(appName); err == nil {
+		log.Printf("🔍 open_app: '%s' encontrada en el PATH: %s", appName, path)
+		return []string{path}, nil
+	}
+	log.Printf("🔍 open_app: '%s' no está en el PATH", appName)
+
+	if link := findStartMenuShortcut(appName); link != "" {
+		log.Printf("🔍 open_app: '%s' encontrada en el menú Inicio: %s", appName, link)
+		// start resuelve el acceso directo; el "" es el título de la ventana
+		return []string{"cmd", "/c", "start", "", link}, nil
+	}
+	log.Printf("🔍 open_app: '%s' no tiene acceso directo en el menú Inicio", appName)
+	return nil, fmt.Errorf("%w: '%s'", errAppNotFound, appName)
+}
+
+// appPathsLookup devuelve la ruta registrada para exe en App Paths (primero
+// la del usuario, luego la del equipo), o "" si no está registrado. El
+// nombre va en una cadena entre comillas simples, donde PowerShell no
+// interpreta $ ni ` como en las de comillas dobles.
+func appPathsLookup(exe string) (string, error) {
+	script := fmt.Sprintf(`$name = '%s'
+foreach ($root in 'HKCU:','HKLM:') {
+  $key = Get-Item -LiteralPath ((Join-Path $root 'SOFTWARE\Microsoft\Windows\CurrentVersion\App Paths') + '\' + $name) -ErrorAction SilentlyContinue
+  if ($key -and $key.GetValue('')) { $key.GetValue(''); break }
+}`, strings.ReplaceAll(exe, "'", "''"))
+	path, err := runPowerShell(script)
+	if err != nil {
+		return "", err
+	}
+	return strings.Trim(path, `"`), nil
+}
+
+// findStartMenuShortcut busca en el menú Inicio (del usuario y común) un
+// acceso directo .lnk con ese nombre, sin distinguir mayúsculas
+func findStartMenuShortcut(appName string) string {
+	var roots []string
+	for _, env := range []string{"APPDATA", "ProgramData"} {
+		if dir := os.Getenv(env); dir != "" {
+			roots = append(roots, filepath.Join(dir, "Microsoft", "Windows", "Start Menu", "Programs"))
+		}
+	}
+	want := strings.ToLower(strings.TrimSuffix(appName, filepath.Ext(appName)))
+	for _, root := range roots {
+		var found string
+		filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() || !strings.EqualFold(filepath.Ext(path), ".lnk") {
+				return nil
+			}
+			if strings.ToLower(strings.TrimSuffix(d.Name(), filepath.Ext(path))) == want {
+				found = path
+				return fs.SkipAll
+			}
+			return nil
+		})
+		if found != "" {
+			return found
+		}
+	}
+	return ""
+}
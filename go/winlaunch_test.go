@@ -0,0 +1,105 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakePowerShell sustituye PATH por un directorio con un powershell falso
+// que guarda el script recibido con -Command y escribe el contenido de
+// FAKE_POWERSHELL_OUTPUT, vacío salvo que el test lo cambie. Devuelve una
+// función que lee el último script.
+func fakePowerShell(t *testing.T) func() string {
+	t.Helper()
+	dir := t.TempDir()
+	scriptFile := filepath.Join(dir, "script.ps1")
+	// -NoProfile -NonInteractive -Command <script>
+	fake := "#!/bin/sh\nprintf '%s' \"$4\" > " + scriptFile + "\nprintf '%s' \"$FAKE_POWERSHELL_OUTPUT\"\n"
+	if err := os.WriteFile(filepath.Join(dir, "powershell"), []byte(fake), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir)
+	t.Setenv("FAKE_POWERSHELL_OUTPUT", "")
+	return func() string {
+		data, err := os.ReadFile(scriptFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+}
+
+// psSingleQuoted separa un script de PowerShell en el contenido de sus
+// cadenas entre comillas simples, ya sin escapar, y el resto del código. Las
+// cadenas entre comillas dobles cuentan como código, ya que PowerShell
+// expande lo que contienen.
+func psSingleQuoted(script string) (literals []string, code string) {
+	var rest, literal strings.Builder
+	inLiteral, inExpandable := false, false
+	for i := 0; i < len(script); i++ {
+		c := script[i]
+		switch {
+		case inExpandable:
+			rest.WriteByte(c)
+			if c == '"' {
+				inExpandable = false
+			}
+		case !inLiteral && c == '"':
+			inExpandable = true
+			rest.WriteByte(c)
+		case !inLiteral && c == '\'':
+			inLiteral = true
+			literal.Reset()
+		case inLiteral && c == '\'' && i+1 < len(script) && script[i+1] == '\'':
+			literal.WriteByte('\'')
+			i++
+		case inLiteral && c == '\'':
+			inLiteral = false
+			literals = append(literals, literal.String())
+		case inLiteral:
+			literal.WriteByte(c)
+		default:
+			rest.WriteByte(c)
+		}
+	}
+	return literals, rest.String()
+}
+
+// injectionNames son nombres que ejecutarían código si llegaran a una cadena
+// de PowerShell entre comillas dobles o fuera de una cadena
+var injectionNames = []string{
+	"$(Start-Process calc)",
+	"app$env:USERNAME",
+	"app`nStart-Process calc",
+	"it's'; Start-Process calc; '",
+}
+
+// checkPSLiteral comprueba que value solo llega al script como el contenido
+// de una cadena entre comillas simples
+func checkPSLiteral(t *testing.T, script, value string) {
+	t.Helper()
+	literals, code := psSingleQuoted(script)
+	found := false
+	for _, l := range literals {
+		found = found || strings.Contains(l, value)
+	}
+	if !found {
+		t.Errorf("%q no aparece como cadena literal en el script:\n%s", value, script)
+	}
+	if strings.Contains(code, "Start-Process") || strings.Contains(code, "$env:USERNAME") || strings.Contains(code, "`") {
+		t.Errorf("%q llega al script fuera de una cadena literal:\n%s", value, script)
+	}
+}
+
+func TestAppPathsLookupQuotesName(t *testing.T) {
+	script := fakePowerShell(t)
+	for _, name := range injectionNames {
+		path, err := appPathsLookup(name + ".exe")
+		if err != nil || path != "" {
+			t.Errorf("appPathsLookup(%q) = %q, %v", name, path, err)
+		}
+		checkPSLiteral(t, script(), name+".exe")
+	}
+}
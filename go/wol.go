@@ -0,0 +1,174 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"net/netip"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// wolMachinesFile es el fichero de configuración con los equipos que se
+// pueden despertar por nombre, p. ej. {"desktop": "AA:BB:CC:DD:EE:FF"}
+const wolMachinesFile = "wol_machines.json"
+
+// Valores de wake_on_lan
+const (
+	defaultWoLBroadcast = "255.255.255.255"
+	defaultWoLPort      = 9
+	maxWoLPort          = 65535
+	// wolPacketCount son los paquetes que se envían: UDP no confirma la
+	// entrega y repetirlo es lo habitual
+	wolPacketCount = 3
+	wolPacketDelay = 100 * time.Millisecond
+)
+
+// wolMACFormats describe los formatos que acepta net.ParseMAC, para los
+// mensajes de error
+const wolMACFormats = "AA:BB:CC:DD:EE:FF, AA-BB-CC-DD-EE-FF o AABB.CCDD.EEFF"
+
+type WakeOnLANInput struct {
+	Target    string `json:"target" jsonschema:"Dirección MAC del equipo (AA:BB:CC:DD:EE:FF, AA-BB-CC-DD-EE-FF o AABB.CCDD.EEFF) o nombre definido en wol_machines.json"`
+	Broadcast string `json:"broadcast,omitempty" jsonschema:"Dirección IPv4 de broadcast a la que enviar el paquete (por defecto 255.255.255.255; p. ej. 192.168.1.255 para una red concreta)"`
+	Port      int    `json:"port,omitempty" jsonschema:"Puerto UDP (por defecto 9)"`
+}
+
+type WakeOnLANOutput struct {
+	MAC         string `json:"mac" jsonschema:"Dirección MAC del equipo"`
+	Machine     string `json:"machine,omitempty" jsonschema:"Nombre del equipo en wol_machines.json, si se usó"`
+	Broadcast   string `json:"broadcast" jsonschema:"Dirección a la que se enviaron los paquetes"`
+	Port        int    `json:"port" jsonschema:"Puerto UDP de destino"`
+	PacketsSent int    `json:"packets_sent" jsonschema:"Paquetes mágicos enviados"`
+	Note        string `json:"note,omitempty" jsonschema:"Aclaración sobre el resultado"`
+}
+
+// parseWoLMAC valida una dirección MAC de 6 bytes en los formatos estándar
+func parseWoLMAC(s string) (net.HardwareAddr, error) {
+	mac, err := net.ParseMAC(s)
+	if err != nil || len(mac) != 6 {
+		return nil, fmt.Errorf("%q no es una dirección MAC válida (formatos: %s)", s, wolMACFormats)
+	}
+	return mac, nil
+}
+
+// resolveWoLTarget devuelve la MAC de target, que puede ser una dirección o
+// el nombre de un equipo de wolMachinesFile (sin distinguir mayúsculas)
+func resolveWoLTarget(target string) (mac net.HardwareAddr, machine string, err error) {
+	var machines map[string]string
+	if err := loadJSONConfig(wolMachinesFile, &machines); err != nil {
+		return nil, "", fmt.Errorf("error al leer %s: %w", wolMachinesFile, err)
+	}
+	names := make([]string, 0, len(machines))
+	for name, address := range machines {
+		names = append(names, name)
+		if strings.EqualFold(name, target) {
+			mac, err := parseWoLMAC(address)
+			if err != nil {
+				return nil, "", fmt.Errorf("%s, equipo %s: %w", wolMachinesFile, name, err)
+			}
+			return mac, name, nil
+		}
+	}
+
+	mac, err = parseWoLMAC(target)
+	if err == nil {
+		return mac, "", nil
+	}
+	if len(names) == 0 {
+		return nil, "", err
+	}
+	slices.Sort(names)
+	return nil, "", fmt.Errorf("%w, ni un equipo de %s (definidos: %s)", err, wolMachinesFile, strings.Join(names, ", "))
+}
+
+// magicPacket construye el paquete mágico: seis bytes 0xFF seguidos de la
+// MAC repetida 16 veces
+func magicPacket(mac net.HardwareAddr) []byte {
+	return append(bytes.Repeat([]byte{0xFF}, 6), bytes.Repeat(mac, 16)...)
+}
+
+// wakeOnLAN envía el paquete mágico por UDP. Go activa SO_BROADCAST en los
+// sockets UDP, así que no hace falta ninguna orden externa.
+func wakeOnLAN(ctx context.Context, input WakeOnLANInput) (WakeOnLANOutput, error) {
+	target := strings.TrimSpace(input.Target)
+	if target == "" {
+		return WakeOnLANOutput{}, fmt.Errorf("indica la dirección MAC (%s) o el nombre del equipo", wolMACFormats)
+	}
+	mac, machine, err := resolveWoLTarget(target)
+	if err != nil {
+		return WakeOnLANOutput{}, err
+	}
+
+	broadcast := strings.TrimSpace(input.Broadcast)
+	if broadcast == "" {
+		broadcast = defaultWoLBroadcast
+	}
+	addr, err := netip.ParseAddr(broadcast)
+	if err != nil || !addr.Is4() {
+		return WakeOnLANOutput{}, fmt.Errorf("dirección de broadcast no válida %q: debe ser una dirección IPv4, p. ej. 192.168.1.255", broadcast)
+	}
+	port := input.Port
+	if port == 0 {
+		port = defaultWoLPort
+	}
+	if port < 1 || port > maxWoLPort {
+		return WakeOnLANOutput{}, fmt.Errorf("puerto no válido %d: debe estar entre 1 y %d", port, maxWoLPort)
+	}
+
+	conn, err := net.DialUDP("udp4", nil, net.UDPAddrFromAddrPort(netip.AddrPortFrom(addr, uint16(port))))
+	if err != nil {
+		return WakeOnLANOutput{}, fmt.Errorf("error al abrir el socket UDP: %w", err)
+	}
+	defer conn.Close()
+
+	out := WakeOnLANOutput{MAC: strings.ToUpper(mac.String()), Machine: machine, Broadcast: addr.String(), Port: port}
+	packet := magicPacket(mac)
+	for i := 0; i < wolPacketCount; i++ {
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				return WakeOnLANOutput{}, ctx.Err()
+			case <-time.After(wolPacketDelay):
+			}
+		}
+		if _, err := conn.Write(packet); err != nil {
+			if out.PacketsSent > 0 {
+				break
+			}
+			return WakeOnLANOutput{}, fmt.Errorf("error al enviar el paquete a %s: %w", conn.RemoteAddr(), err)
+		}
+		out.PacketsSent++
+	}
+
+	out.Note = "Wake-on-LAN no tiene respuesta: el equipo tarda unos segundos en arrancar y solo despierta si lo tiene activado en la BIOS/UEFI y en la tarjeta de red"
+	if currentPlatform() == platformWSL {
+		out.Note = "En WSL2 con la red en modo NAT el broadcast no sale de la red virtual: usa la red en modo mirrored o envíalo desde Windows. " + out.Note
+	}
+	return out, nil
+}
+
+func HandleWakeOnLAN(ctx context.Context, req *mcp.CallToolRequest, input WakeOnLANInput) (*mcp.CallToolResult, WakeOnLANOutput, error) {
+	out, err := wakeOnLAN(ctx, input)
+	if err != nil {
+		return nil, WakeOnLANOutput{}, fmt.Errorf("❌ Error al enviar Wake-on-LAN: %w", err)
+	}
+
+	target := out.MAC
+	if out.Machine != "" {
+		target = fmt.Sprintf("%s (%s)", out.Machine, out.MAC)
+	}
+	lines := []string{
+		fmt.Sprintf("⏰ Enviados %d paquetes mágicos a %s por %s:%d", out.PacketsSent, target, out.Broadcast, out.Port),
+		"ℹ️ " + out.Note,
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: strings.Join(lines, "\n")},
+		},
+	}, out, nil
+}
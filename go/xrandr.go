@@ -0,0 +1,240 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// xrandrOutput representa una salida de vídeo conectada según xrandr
+type xrandrOutput struct {
+	Name    string // Conector, p. ej. eDP-1 o HDMI-2
+	Primary bool   // xrandr la marca como "primary"
+
+	// Posición y tamaño en el escritorio; a cero si la salida está apagada
+	X, Y, Width, Height int
+
+	// Frecuencia del modo actual en Hz; cero si la salida está apagada
+	Refresh float64
+
+	// Giro de la salida: normal, left, inverted o right. Girada a un lado,
+	// Width y Height son los del escritorio, intercambiados respecto al modo.
+	Rotation string
+
+	// Modos que admite la salida, con el nombre que acepta --mode
+	Modes []DisplayMode
+}
+
+// xrandrCacheTTL es el tiempo durante el que se reutiliza la lista de
+// salidas, para no consultar al servidor X en cada ajuste seguido
+const xrandrCacheTTL = 30 * time.Second
+
+var (
+	xrandrCacheMu      sync.Mutex
+	xrandrCacheOutputs []xrandrOutput
+	xrandrCacheTime    time.Time
+)
+
+// listXrandrOutputs devuelve las salidas conectadas según `xrandr --query`,
+// reutilizando la última consulta durante xrandrCacheTTL
+func listXrandrOutputs() ([]xrandrOutput, error) {
+	if err := checkGraphicalSession(); err != nil {
+		return nil, err
+	}
+
+	xrandrCacheMu.Lock()
+	defer xrandrCacheMu.Unlock()
+	if xrandrCacheOutputs != nil && time.Since(xrandrCacheTime) < xrandrCacheTTL {
+		return xrandrCacheOutputs, nil
+	}
+
+	output, err := exec.Command("xrandr", "--query").Output()
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener displays: %w", err)
+	}
+	xrandrCacheOutputs = parseXrandrQuery(string(output))
+	xrandrCacheTime = time.Now()
+	return xrandrCacheOutputs, nil
+}
+
+// invalidateXrandrCache descarta la lista de salidas en caché. Se llama
+// cuando falla un comando sobre una salida (normalmente porque se desconectó
+// un monitor) o cuando el usuario pide refrescarla.
+func invalidateXrandrCache() {
+	xrandrCacheMu.Lock()
+	defer xrandrCacheMu.Unlock()
+	xrandrCacheOutputs = nil
+}
+
+// parseXrandrQuery interpreta las líneas de salida de `xrandr --query`, como
+//
+//	eDP-1 connected primary 1920x1080+0+0 (normal left inverted right x axis y axis) 344mm x 194mm
+//	   1920x1080     60.02*+  59.93    48.00
+//	HDMI-2 disconnected (normal left inverted right x axis y axis)
+//
+// Las líneas sangradas son los modos de la salida; el actual lleva '*'.
+func parseXrandrQuery(output string) []xrandrOutput {
+	var outputs []xrandrOutput
+	current := false
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		line := scanner.Text()
+		fields := strings.Fields(line)
+		if strings.HasPrefix(line, " ") {
+			if !current {
+				continue
+			}
+			// "60.02*+": '*' es el modo actual y '+' el preferido. Los modos
+			// entrelazados ("1920x1080i") no se ofrecen.
+			o := &outputs[len(outputs)-1]
+			var width, height int
+			_, sizeErr := fmt.Sscanf(fields[0], "%dx%d", &width, &height)
+			interlaced := strings.HasSuffix(fields[0], "i")
+			for _, field := range fields[min(1, len(fields)):] {
+				rate, current := strings.CutSuffix(strings.TrimSuffix(field, "+"), "*")
+				value, err := strconv.ParseFloat(rate, 64)
+				if err != nil {
+					continue
+				}
+				if current {
+					o.Refresh = value
+				}
+				if sizeErr == nil && !interlaced {
+					o.Modes = append(o.Modes, DisplayMode{Width: width, Height: height, RefreshRate: value, name: fields[0]})
+				}
+			}
+			continue
+		}
+		current = len(fields) >= 2 && fields[1] == "connected"
+		if !current {
+			continue
+		}
+		o := xrandrOutput{
+			Name:    fields[0],
+			Primary: len(fields) > 2 && fields[2] == "primary",
+		}
+		geometry := 2
+		if o.Primary {
+			geometry = 3
+		}
+		o.Rotation = "normal"
+		// "1080x1920+0+0 left (normal left inverted right x axis y axis)": el
+		// giro va tras la geometría, que falta si la salida está apagada
+		if len(fields) > geometry && !strings.HasPrefix(fields[geometry], "(") {
+			fmt.Sscanf(fields[geometry], "%dx%d+%d+%d", &o.Width, &o.Height, &o.X, &o.Y)
+			if len(fields) > geometry+1 && slices.Contains([]string{"left", "inverted", "right"}, fields[geometry+1]) {
+				o.Rotation = fields[geometry+1]
+			}
+		}
+		outputs = append(outputs, o)
+	}
+	return outputs
+}
+
+// defaultXrandrOutputs elige las salidas a ajustar cuando no se indica
+// display: la marcada como principal o, si no hay, el panel interno. Si
+// tampoco lo hay y están conectadas varias se eligen todas (all=true), ya
+// que la primera por orden puede ser una salida sin uso de un dock.
+func defaultXrandrOutputs(outputs []xrandrOutput) (selected []xrandrOutput, all bool) {
+	for _, o := range outputs {
+		if o.Primary {
+			return []xrandrOutput{o}, false
+		}
+	}
+	for _, o := range outputs {
+		if isInternalOutput(o.Name) {
+			return []xrandrOutput{o}, false
+		}
+	}
+	return outputs, len(outputs) > 1
+}
+
+// selectXrandrOutputs elige las salidas a las que se refiere display: vacío
+// para la predeterminada (ver defaultXrandrOutputs), "all" para todas, un
+// índice o el nombre del conector
+func selectXrandrOutputs(outputs []xrandrOutput, display string) ([]xrandrOutput, error) {
+	if len(outputs) == 0 {
+		return nil, fmt.Errorf("no se encontraron displays conectados")
+	}
+
+	switch {
+	case display == "":
+		selected, _ := defaultXrandrOutputs(outputs)
+		return selected, nil
+	case strings.EqualFold(display, "all"):
+		return outputs, nil
+	}
+
+	if index, err := strconv.Atoi(display); err == nil {
+		if index < 0 || index >= len(outputs) {
+			return nil, fmt.Errorf("índice de display %d fuera de rango (hay %d conectados: %s)", index, len(outputs), xrandrOutputNames(outputs))
+		}
+		return outputs[index : index+1], nil
+	}
+	for _, o := range outputs {
+		if strings.EqualFold(o.Name, display) {
+			return []xrandrOutput{o}, nil
+		}
+	}
+	return nil, fmt.Errorf("no se encontró el display %q (conectados: %s)", display, xrandrOutputNames(outputs))
+}
+
+// xrandrOutputNames une los nombres de las salidas para los mensajes de error
+func xrandrOutputNames(outputs []xrandrOutput) string {
+	names := make([]string, len(outputs))
+	for i, o := range outputs {
+		names[i] = o.Name
+	}
+	return strings.Join(names, ", ")
+}
+
+// isInternalOutput indica si el conector corresponde al panel interno de un portátil
+func isInternalOutput(name string) bool {
+	upper := strings.ToUpper(name)
+	return strings.HasPrefix(upper, "EDP") || strings.HasPrefix(upper, "LVDS") || strings.HasPrefix(upper, "DSI")
+}
+
+// resolveDisplayName traduce un índice de display al nombre de su conector
+// según xrandr o, en Wayland, según listDisplays. Sin índice (o sin forma de
+// enumerar las salidas) devuelve display tal cual. Si display está vacío se
+// resuelve con defaultXrandrOutputs; cuando eso supone ajustar todas las
+// salidas se devuelve "all" y una nota que lo explica.
+func resolveDisplayName(display string) (string, string) {
+	index, err := strconv.Atoi(display)
+	if display != "" && err != nil {
+		return display, ""
+	}
+	if detectLinuxSession().Wayland {
+		if display == "" {
+			return display, ""
+		}
+		displays, err := listDisplays()
+		if err == nil && index >= 0 && index < len(displays) {
+			return displays[index].ID, ""
+		}
+		return display, ""
+	}
+	if _, err := exec.LookPath("xrandr"); err != nil {
+		return display, ""
+	}
+	outputs, err := listXrandrOutputs()
+	if err != nil || len(outputs) == 0 {
+		return display, ""
+	}
+	if display == "" {
+		selected, all := defaultXrandrOutputs(outputs)
+		if all {
+			return "all", fmt.Sprintf("⚠️ Ninguna salida está marcada como principal: se ajustan todas (%s)", xrandrOutputNames(selected))
+		}
+		return selected[0].Name, ""
+	}
+	if index >= 0 && index < len(outputs) {
+		return outputs[index].Name, ""
+	}
+	return display, ""
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+// readXrandrFixture lee una salida de `xrandr --query` capturada en testdata
+func readXrandrFixture(t *testing.T, name string) []xrandrOutput {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return parseXrandrQuery(string(data))
+}
+
+// xrandrModes construye los modos de una línea de xrandr con sus frecuencias
+func xrandrModes(name string, width, height int, rates ...float64) []DisplayMode {
+	modes := make([]DisplayMode, len(rates))
+	for i, rate := range rates {
+		modes[i] = DisplayMode{Width: width, Height: height, RefreshRate: rate, name: name}
+	}
+	return modes
+}
+
+func TestParseXrandrQuery(t *testing.T) {
+	tests := []struct {
+		fixture string
+		want    []xrandrOutput
+	}{
+		{"xrandr_single.txt", []xrandrOutput{
+			{Name: "eDP-1", Primary: true, Width: 1920, Height: 1080, Refresh: 60.02, Rotation: "normal", Modes: slices.Concat(
+				xrandrModes("1920x1080", 1920, 1080, 60.02, 59.93, 48),
+				xrandrModes("1680x1050", 1680, 1050, 59.88),
+				xrandrModes("1280x720", 1280, 720, 60, 59.94),
+				xrandrModes("1024x768", 1024, 768, 60.04),
+			)},
+		}},
+		{"xrandr_dual.txt", []xrandrOutput{
+			{Name: "eDP-1", Y: 360, Width: 1920, Height: 1080, Refresh: 60.02, Rotation: "normal", Modes: slices.Concat(
+				xrandrModes("1920x1080", 1920, 1080, 60.02, 59.93),
+				xrandrModes("1280x720", 1280, 720, 60),
+			)},
+			// El modo entrelazado 1920x1080i no aparece
+			{Name: "HDMI-1", Primary: true, X: 1920, Width: 2560, Height: 1440, Refresh: 143.97, Rotation: "normal", Modes: slices.Concat(
+				xrandrModes("2560x1440", 2560, 1440, 59.95, 143.97, 120),
+				xrandrModes("1920x1080", 1920, 1080, 60, 50, 59.94),
+				xrandrModes("1280x720", 1280, 720, 60, 59.94),
+			)},
+		}},
+		{"xrandr_docked.txt", []xrandrOutput{
+			{Name: "DP-1-1", Y: 240, Width: 2560, Height: 1440, Refresh: 59.95, Rotation: "normal", Modes: slices.Concat(
+				xrandrModes("2560x1440", 2560, 1440, 59.95),
+				xrandrModes("1920x1080", 1920, 1080, 60),
+			)},
+			// Girada a la izquierda: el tamaño es el del escritorio, no el del modo
+			{Name: "DP-1-2", X: 2560, Width: 1080, Height: 1920, Refresh: 60, Rotation: "left", Modes: slices.Concat(
+				xrandrModes("1920x1080", 1920, 1080, 60, 50),
+				xrandrModes("1280x1024", 1280, 1024, 60.02),
+			)},
+			// Conectada pero apagada, sin geometría ni modo actual
+			{Name: "DP-1-3", Rotation: "normal", Modes: xrandrModes("1024x768", 1024, 768, 60)},
+		}},
+	}
+	for _, tc := range tests {
+		got := readXrandrFixture(t, tc.fixture)
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("parseXrandrQuery(%s) =\n%+v\nse esperaba\n%+v", tc.fixture, got, tc.want)
+		}
+	}
+}
+
+func TestDefaultXrandrOutputs(t *testing.T) {
+	tests := []struct {
+		fixture string
+		want    []string
+		all     bool
+	}{
+		// Solo el panel del portátil
+		{"xrandr_single.txt", []string{"eDP-1"}, false},
+		// La principal aunque no sea la primera ni el panel interno
+		{"xrandr_dual.txt", []string{"HDMI-1"}, false},
+		// Sin principal ni panel interno se eligen todas las del dock
+		{"xrandr_docked.txt", []string{"DP-1-1", "DP-1-2", "DP-1-3"}, true},
+	}
+	for _, tc := range tests {
+		selected, all := defaultXrandrOutputs(readXrandrFixture(t, tc.fixture))
+		var names []string
+		for _, o := range selected {
+			names = append(names, o.Name)
+		}
+		if !reflect.DeepEqual(names, tc.want) || all != tc.all {
+			t.Errorf("defaultXrandrOutputs(%s) = %v, %v; se esperaba %v, %v", tc.fixture, names, all, tc.want, tc.all)
+		}
+	}
+}
+
+func TestSelectXrandrOutputs(t *testing.T) {
+	outputs := readXrandrFixture(t, "xrandr_docked.txt")
+	tests := []struct {
+		display string
+		want    []string
+	}{
+		{"1", []string{"DP-1-2"}},
+		{"dp-1-1", []string{"DP-1-1"}},
+		{"all", []string{"DP-1-1", "DP-1-2", "DP-1-3"}},
+	}
+	for _, tc := range tests {
+		selected, err := selectXrandrOutputs(outputs, tc.display)
+		if err != nil {
+			t.Errorf("selectXrandrOutputs(%q) = %v", tc.display, err)
+			continue
+		}
+		var names []string
+		for _, o := range selected {
+			names = append(names, o.Name)
+		}
+		if !reflect.DeepEqual(names, tc.want) {
+			t.Errorf("selectXrandrOutputs(%q) = %v; se esperaba %v", tc.display, names, tc.want)
+		}
+	}
+	for _, display := range []string{"3", "-1", "HDMI-1"} {
+		if _, err := selectXrandrOutputs(outputs, display); err == nil {
+			t.Errorf("selectXrandrOutputs(%q) = nil; se esperaba un error", display)
+		}
+	}
+}